@@ -3,23 +3,37 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/redis/go-redis/v9"
+	"github.com/zizouhuweidi/dahaa/internal/debug"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
 	"github.com/zizouhuweidi/dahaa/internal/handler"
+	"github.com/zizouhuweidi/dahaa/internal/i18n"
+	"github.com/zizouhuweidi/dahaa/internal/llm"
+	"github.com/zizouhuweidi/dahaa/internal/mail"
+	"github.com/zizouhuweidi/dahaa/internal/moderation"
+	"github.com/zizouhuweidi/dahaa/internal/oauth"
+	"github.com/zizouhuweidi/dahaa/internal/repository/cache"
+	"github.com/zizouhuweidi/dahaa/internal/repository/circuit"
 	"github.com/zizouhuweidi/dahaa/internal/repository/postgres"
+	"github.com/zizouhuweidi/dahaa/internal/repository/retry"
+	"github.com/zizouhuweidi/dahaa/internal/rpc"
 	"github.com/zizouhuweidi/dahaa/internal/service"
 	"github.com/zizouhuweidi/dahaa/internal/session"
 	"github.com/zizouhuweidi/dahaa/internal/storage"
 	"github.com/zizouhuweidi/dahaa/internal/websocket"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -56,59 +70,448 @@ func main() {
 	// Initialize repositories
 	userRepo := postgres.NewUserRepository(pool)
 	gameInviteRepo := postgres.NewGameInviteRepository(pool)
-	gameRepo := postgres.NewGameRepository(pool)
-	questionRepo := postgres.NewQuestionRepository(pool)
+	// Repositories games use mid-round retry transient failures, so a
+	// connection reset or failover does not fail a player's action
+	gameRepo := retry.NewGameRepository(postgres.NewGameRepository(pool), retry.DefaultPolicy)
+	gameEventRepo := retry.NewGameEventRepository(postgres.NewGameEventRepository(pool), retry.DefaultPolicy)
+	gameChangeStore := retry.NewGameChangeStore(postgres.NewGameChangeStore(pool), retry.DefaultPolicy)
+	outboxRepo := postgres.NewOutboxRepository(pool)
+	gameResultRepo := postgres.NewGameResultRepository(pool)
+	statsRollupRepo := postgres.NewStatsRollupRepository(pool)
+	categoryStatsRepo := postgres.NewCategoryStatsRepository(pool)
+	presetRepo := postgres.NewSettingsPresetRepository(pool)
+	templateRepo := postgres.NewGameTemplateRepository(pool)
+	partyRepo := postgres.NewPartyRepository(pool)
+	accountSignalRepo := postgres.NewAccountSignalRepository(pool)
+	userBanRepo := postgres.NewUserBanRepository(pool)
+	submissionRepo := postgres.NewQuestionSubmissionRepository(pool)
+	entitlementRepo := postgres.NewEntitlementRepository(pool)
+	promoRepo := postgres.NewPromoRepository(pool)
+	cosmeticRepo := postgres.NewCosmeticRepository(pool)
+	hallOfFameRepo := cache.NewHallOfFameRepository(postgres.NewHallOfFameRepository(pool))
+	tenantRepo := postgres.NewTenantRepository(pool)
+	// Category listings are cached, as every lobby and new game reads them
+	categoryCacheTTL, err := time.ParseDuration(getEnv("CATEGORY_CACHE_TTL", "5m"))
+	if err != nil {
+		log.Fatalf("Invalid CATEGORY_CACHE_TTL: %v", err)
+	}
+	questionRepo := cache.NewQuestionRepository(
+		retry.NewQuestionRepository(postgres.NewQuestionRepository(pool), retry.DefaultPolicy),
+		categoryCacheTTL,
+	)
+
+	// Initialize session manager. After five failures in a row, Redis is
+	// left alone for a cooldown and games are read from Postgres instead.
+	redisBreakerCooldown, err := time.ParseDuration(getEnv("REDIS_BREAKER_COOLDOWN", "10s"))
+	if err != nil {
+		log.Fatalf("Invalid REDIS_BREAKER_COOLDOWN: %v", err)
+	}
+	redisBreaker := circuit.NewBreaker(5, redisBreakerCooldown)
+	sessionManager := circuit.NewGameSessionStore(session.NewManager(redisClient), redisBreaker)
 
-	// Initialize session manager
-	sessionManager := session.NewManager(redisClient)
+	// Fillers are written by a language model when one is configured. After
+	// three failures in a row, it is left alone for a cooldown and fillers
+	// come from templates instead.
+	llmBreakerCooldown, err := time.ParseDuration(getEnv("LLM_BREAKER_COOLDOWN", "30s"))
+	if err != nil {
+		log.Fatalf("Invalid LLM_BREAKER_COOLDOWN: %v", err)
+	}
+	llmBreaker := circuit.NewBreaker(3, llmBreakerCooldown)
+	var fillerGenerator domain.FillerGenerator
+	if url := getEnv("LLM_API_URL", ""); url != "" {
+		fillerGenerator = circuit.NewFillerGenerator(
+			llm.NewFillerGenerator(url, getEnv("LLM_API_KEY", ""), getEnv("LLM_MODEL", "gpt-4o-mini")),
+			llmBreaker,
+		)
+	}
+
+	// Cache catalog, question listing and leaderboard responses briefly,
+	// dropping them whenever questions change or a user hides or shows their
+	// hall of fame answers
+	responseCacheTTL, err := time.ParseDuration(getEnv("RESPONSE_CACHE_TTL", "30s"))
+	if err != nil {
+		log.Fatalf("Invalid RESPONSE_CACHE_TTL: %v", err)
+	}
+	responseCache := session.NewResponseCache(redisClient)
+	questionRepo.OnInvalidate(handler.InvalidateResponses(responseCache, handler.CacheTagCatalog))
+	questionRepo.OnInvalidate(handler.InvalidateResponses(responseCache, handler.CacheTagQuestions))
+	hallOfFameRepo.OnInvalidate(handler.InvalidateResponses(responseCache, handler.CacheTagLeaderboards))
 
 	// Initialize websocket hub
-	hub := websocket.NewHub()
+	hubShards, err := strconv.Atoi(getEnv("HUB_SHARDS", "0"))
+	if err != nil {
+		log.Fatalf("Invalid HUB_SHARDS: %v", err)
+	}
+	hub := websocket.NewHub(websocket.WithShards(hubShards))
 	go hub.Run()
 
+	// Serve runtime diagnostics on a separate port when configured
+	var debugServer *http.Server
+	if debugAddr := getEnv("DEBUG_ADDR", ""); debugAddr != "" {
+		debugToken := getEnv("DEBUG_TOKEN", "")
+		if debugToken == "" {
+			log.Fatal("DEBUG_TOKEN must be set when DEBUG_ADDR is")
+		}
+
+		debug.Publish("hub", func() any { return hub.Stats() })
+		debug.Publish("redis_pool", func() any { return redisClient.PoolStats() })
+		debug.Publish("redis_breaker", func() any { return redisBreaker.Stats() })
+		debug.Publish("llm_breaker", func() any { return llmBreaker.Stats() })
+		debug.Publish("postgres_queries", func() any { return postgres.Stats(pool) })
+		debug.Publish("postgres_pool", func() any {
+			stat := pool.Stat()
+			return map[string]any{
+				"total_conns":    stat.TotalConns(),
+				"idle_conns":     stat.IdleConns(),
+				"acquired_conns": stat.AcquiredConns(),
+				"max_conns":      stat.MaxConns(),
+			}
+		})
+
+		debugServer = debug.NewServer(debugAddr, debugToken)
+		go func() {
+			if err := debugServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("Debug server failed: %v", err)
+			}
+		}()
+	}
+
+	// Inactive games expire after a timeout, with warnings to their players
+	// at each of the given intervals before it
+	expiryTimeout, err := time.ParseDuration(getEnv("GAME_INACTIVITY_TIMEOUT", "24h"))
+	if err != nil {
+		log.Fatalf("Invalid GAME_INACTIVITY_TIMEOUT: %v", err)
+	}
+	expiryWarnings, err := parseDurations(getEnv("GAME_EXPIRY_WARNINGS", "1h,10m,1m"))
+	if err != nil {
+		log.Fatalf("Invalid GAME_EXPIRY_WARNINGS: %v", err)
+	}
+
+	// Players who disconnect mid-game are waited for this long before
+	// rounds go on without them
+	reconnectGrace, err := time.ParseDuration(getEnv("RECONNECT_GRACE", "1m"))
+	if err != nil {
+		log.Fatalf("Invalid RECONNECT_GRACE: %v", err)
+	}
+
+	// Relay game messages committed to the outbox to the hub, coalescing a
+	// game's state updates within a window unless it is zero
+	coalesceWindow, err := time.ParseDuration(getEnv("BROADCAST_COALESCE_WINDOW", "250ms"))
+	if err != nil {
+		log.Fatalf("Invalid BROADCAST_COALESCE_WINDOW: %v", err)
+	}
+	var broadcaster domain.GameBroadcaster = hub
+	if coalesceWindow > 0 {
+		broadcaster = service.NewBroadcastCoalescer(hub, coalesceWindow)
+	}
+	relay := service.NewOutboxRelay(outboxRepo, broadcaster)
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go relay.Run(jobsCtx)
+
 	// Initialize services
-	userService := service.NewUserService(userRepo, gameInviteRepo)
-	gameService := service.NewGameService(gameRepo, questionRepo, hub, sessionManager)
+	banService := service.NewBanService(userBanRepo, userRepo)
+	// Users sign in with the outside accounts whose providers are configured
+	var oauthProviders []domain.OAuthProvider
+	if clientID := getEnv("GOOGLE_CLIENT_ID", ""); clientID != "" {
+		oauthProviders = append(oauthProviders, oauth.NewGoogle(clientID, getEnv("GOOGLE_CLIENT_SECRET", "")))
+	}
+	if clientID := getEnv("APPLE_CLIENT_ID", ""); clientID != "" {
+		oauthProviders = append(oauthProviders, oauth.NewApple(clientID, getEnv("APPLE_CLIENT_SECRET", "")))
+	}
+	// Emails are logged instead of sent unless an SMTP server is configured
+	var mailer mail.Sender = mail.NewLog()
+	if addr := getEnv("SMTP_ADDR", ""); addr != "" {
+		mailer = mail.NewSMTP(addr, getEnv("SMTP_FROM", "Dahaa <no-reply@dahaa.app>"), getEnv("SMTP_USERNAME", ""), getEnv("SMTP_PASSWORD", ""))
+	}
+	userService := service.NewUserService(userRepo, gameInviteRepo, hub,
+		service.WithLoginBans(banService),
+		// User sessions, guests and verification tokens have no Postgres
+		// fallback, so they bypass the breaker
+		service.WithSessions(session.NewManager(redisClient)),
+		service.WithGuests(session.NewManager(redisClient)),
+		service.WithEmailVerification(session.NewManager(redisClient), mailer, getEnv("VERIFY_URL", "http://localhost:3000/verify")),
+		service.WithOAuthProviders(oauthProviders...),
+		// Organizations' members sign in with the identity providers
+		// configured through the admin API
+		service.WithSSO(tenantRepo, oauth.NewOIDC().Provider),
+		service.WithHallOfFameConsent(hallOfFameRepo),
+	)
+	userService.StartInviteCleanupJob(jobsCtx)
+	userService.StartUserPurgeJob(jobsCtx)
+	// Fake answers go through the profanity filter, and through a
+	// moderation API too when one is configured
+	var answerModerator domain.AnswerModerator
+	if url := getEnv("ANSWER_MODERATION_URL", ""); url != "" {
+		answerModerator = moderation.NewWebhook(url, getEnv("ANSWER_MODERATION_TOKEN", ""))
+	}
+	// Premium packs must be owned by the host of a game using them, or by
+	// every player with the "all" policy
+	premiumPackPolicy := domain.PremiumPackPolicy(getEnv("PREMIUM_PACK_POLICY", string(domain.PremiumPackPolicyHost)))
+	if premiumPackPolicy != domain.PremiumPackPolicyHost && premiumPackPolicy != domain.PremiumPackPolicyAll {
+		log.Fatalf("Invalid PREMIUM_PACK_POLICY: %s", premiumPackPolicy)
+	}
+	entitlementService := service.NewEntitlementService(entitlementRepo, userRepo, hub, premiumPackPolicy)
+	cosmeticService := service.NewCosmeticService(cosmeticRepo, gameResultRepo)
+	tenantService := service.NewTenantService(tenantRepo, userRepo)
+	// Games record the region and instance they were created on, for
+	// deployments spanning several regions
+	instance, _ := os.Hostname()
+	gameService := service.NewGameService(gameRepo, gameEventRepo, gameChangeStore, questionRepo, hub,
+		retry.NewGameSessionStore(sessionManager, retry.DefaultPolicy),
+		service.WithOutboxRelay(relay),
+		service.WithExpiry(expiryTimeout, expiryWarnings...),
+		service.WithReconnectGrace(reconnectGrace),
+		service.WithParties(partyRepo),
+		service.WithQuestionHistory(sessionManager),
+		service.WithOrigin(getEnv("REGION", ""), getEnv("INSTANCE_ID", instance)),
+		service.WithBans(banService),
+		service.WithAnswerModerator(answerModerator),
+		service.WithFillerGenerator(fillerGenerator),
+		service.WithEntitlements(entitlementService),
+		service.WithCosmetics(cosmeticService),
+		service.WithHallOfFame(userRepo),
+		service.WithTenants(tenantService),
+	)
+	gameService.StartExpiryJob(jobsCtx)
+	gameService.StartPresenceJob(jobsCtx)
+	gameService.StartCountdownJob(jobsCtx)
+	gameService.StartIntermissionJob(jobsCtx)
+	gameService.StartReminderJob(jobsCtx)
+	importService := service.NewQuestionImportService(questionRepo, hub)
+	submissionService := service.NewSubmissionService(submissionRepo, questionRepo, userRepo)
+	packService := service.NewPackService(questionRepo)
+	promoService := service.NewPromoService(promoRepo, entitlementService, cosmeticService)
+	inviteService := service.NewInviteService(userService, gameService)
+	statsService := service.NewStatsService(userRepo, gameResultRepo, statsRollupRepo, categoryStatsRepo)
+	statsService.StartRollupJob(jobsCtx)
+	presetService := service.NewPresetService(presetRepo)
+	templateService := service.NewTemplateService(templateRepo)
+	abuseService := service.NewAbuseService(accountSignalRepo,
+		service.WithDisposableEmailDomains(strings.Split(getEnv("DISPOSABLE_EMAIL_DOMAINS", ""), ",")...),
+	)
+	streamService := service.NewAudienceStreamService(gameService, getEnv("YOUTUBE_API_KEY", ""))
+
+	// Serve the game service and question bank to internal consumers over
+	// gRPC on a separate port when configured
+	var grpcServer *grpc.Server
+	if grpcAddr := getEnv("GRPC_ADDR", ""); grpcAddr != "" {
+		grpcToken := getEnv("GRPC_TOKEN", "")
+		if grpcToken == "" {
+			log.Fatal("GRPC_TOKEN must be set when GRPC_ADDR is")
+		}
+
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Fatalf("Failed to listen on GRPC_ADDR: %v", err)
+		}
+		grpcServer = rpc.NewServer(grpcToken, gameService, questionRepo)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Printf("gRPC server failed: %v", err)
+			}
+		}()
+	}
 
 	// Initialize handlers
-	userHandler := handler.NewUserHandler(userService)
-	gameHandler := handler.NewGameHandler(gameService, questionRepo)
-	wsHandler := handler.NewWebSocketHandler(hub)
+	userHandler := handler.NewUserHandler(userService, inviteService, statsService, abuseService)
+	gameHandler := handler.NewGameHandler(gameService, questionRepo, importService, presetService, templateService, streamService)
+	statsHandler := handler.NewStatsHandler(statsService)
+	presetHandler := handler.NewPresetHandler(presetService)
+	templateHandler := handler.NewTemplateHandler(templateService)
+	abuseHandler := handler.NewAbuseHandler(abuseService)
+	banHandler := handler.NewBanHandler(banService)
+	submissionHandler := handler.NewSubmissionHandler(submissionService)
+	entitlementHandler := handler.NewEntitlementHandler(entitlementService)
+	promoHandler := handler.NewPromoHandler(promoService)
+	cosmeticHandler := handler.NewCosmeticHandler(cosmeticService)
+	tenantHandler := handler.NewTenantHandler(tenantService)
+	hallOfFameHandler := handler.NewHallOfFameHandler(service.NewHallOfFameService(hallOfFameRepo))
+	packHandler := handler.NewPackHandler(packService)
+	joinHandler := handler.NewJoinHandler(gameService, getEnv("JOIN_URL", "http://localhost:3000/join"))
+	wsHandler := handler.NewWebSocketHandler(hub, gameService, userService, banService)
 	imageHandler := handler.NewImageHandler(imageStorage)
 
 	// Initialize Echo
 	e := echo.New()
 
 	// Register custom validator
-	e.Validator = &handler.CustomValidator{Validator: validator.New()}
+	e.Validator = &handler.CustomValidator{Validator: handler.NewValidator()}
 
 	// Middleware
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORS())
+	e.Use(handler.Localize(i18n.Default()))
+	// Account signals record the country clients are in when a trusted
+	// proxy or CDN geolocates them
+	if header := getEnv("GEOIP_COUNTRY_HEADER", ""); header != "" {
+		e.Use(handler.GeolocateClients(header))
+	}
 
 	// Routes
 	api := e.Group("/api")
 
-	// User routes
-	users := api.Group("/users")
-	users.POST("/register", userHandler.Register)
-	users.POST("/login", userHandler.Login)
-	users.POST("/invites/:game_id/:to_user_id", userHandler.SendGameInvite)
-	users.POST("/invites/:invite_id/accept", userHandler.AcceptGameInvite)
-	users.POST("/invites/:invite_id/decline", userHandler.DeclineGameInvite)
-	users.GET("/invites", userHandler.GetPendingInvites)
+	// Routes acting for a user identify them by the session token they
+	// logged in with. Game routes may also be called by players without an
+	// account, who present their player token instead.
+	authenticate := handler.Authenticate(userService)
+
+	// User routes. Signing in is open to anyone, so a stale token left over
+	// from an expired session does not keep a client from signing in again.
+	signIn := api.Group("/users")
+	signIn.POST("/register", userHandler.Register)
+	signIn.POST("/login", userHandler.Login)
+	signIn.POST("/oauth/:provider", userHandler.LoginWithOAuth)
+	signIn.GET("/sso/:tenant", tenantHandler.GetSSOLogin)
+	signIn.POST("/sso/:tenant", userHandler.LoginWithSSO)
+	signIn.POST("/guest", userHandler.CreateGuest)
+	signIn.POST("/verify", userHandler.VerifyEmail)
+	users := api.Group("/users", authenticate)
+	users.POST("/logout", userHandler.Logout, handler.RequireUser)
+	users.POST("/guest/convert", userHandler.ConvertGuest)
+	users.POST("/verify/resend", userHandler.ResendVerification, handler.RequireUser)
+	invites := users.Group("/invites", handler.RequireUser)
+	invites.POST("/:game_id/:to_user_id", userHandler.SendGameInvite)
+	invites.POST("/:invite_id/accept", userHandler.AcceptGameInvite)
+	invites.POST("/:invite_id/decline", userHandler.DeclineGameInvite)
+	invites.POST("/:invite_id/resend", userHandler.ResendGameInvite)
+	invites.DELETE("/:invite_id", userHandler.CancelGameInvite)
+	invites.GET("", userHandler.GetPendingInvites)
+	users.DELETE("/me", userHandler.DeleteAccount, handler.RequireUser)
+	me := users.Group("/me", handler.RequireUser)
+	me.GET("/preferences", userHandler.GetPreferences)
+	me.PATCH("/preferences", userHandler.UpdatePreferences)
+	me.GET("/profile", statsHandler.GetProfile)
+	me.PATCH("/profile", userHandler.UpdateProfile)
+	me.GET("/history", statsHandler.GetGameHistory)
+	me.GET("/stats", statsHandler.GetStatsRollups)
+	me.POST("/submissions", submissionHandler.SubmitQuestion)
+	me.GET("/submissions", submissionHandler.ListUserSubmissions)
+	me.GET("/reputation", submissionHandler.GetOwnReputation)
+	me.GET("/entitlements", entitlementHandler.ListOwnEntitlements)
+	me.POST("/entitlements/:pack/gift", entitlementHandler.GiftPack)
+	me.POST("/redemptions", promoHandler.Redeem)
+	me.GET("/redemptions", promoHandler.ListRedemptions)
+	me.GET("/cosmetics", cosmeticHandler.ListCosmetics)
+	me.PUT("/cosmetics/:kind", cosmeticHandler.EquipCosmetic)
+	me.GET("/rivals/:user_id", statsHandler.GetHeadToHead)
+	me.GET("/presets", presetHandler.ListPresets)
+	me.PUT("/presets/:key", presetHandler.SavePreset)
+	me.DELETE("/presets/:key", presetHandler.DeletePreset)
+
+	// Catalog routes
+	catalogCache := handler.CacheResponses(responseCache, handler.CacheTagCatalog, responseCacheTTL)
+	api.GET("/categories", gameHandler.GetCategories, catalogCache)
+	api.GET("/difficulties", gameHandler.GetDifficulties, catalogCache)
+	api.GET("/packs/changelog", packHandler.GetChangelog, catalogCache)
+	api.GET("/premium-packs", entitlementHandler.ListPremiumPacks, authenticate)
+	api.GET("/hall-of-fame", hallOfFameHandler.ListHallOfFame,
+		handler.CacheResponses(responseCache, handler.CacheTagLeaderboards, responseCacheTTL))
+
+	// Organization routes, for the members of the user's organization
+	tenant := api.Group("/tenant", authenticate, handler.RequireUser)
+	tenant.GET("", tenantHandler.GetOwnTenant)
+	tenant.GET("/leaderboard", tenantHandler.GetLeaderboard,
+		handler.CacheCallerResponses(responseCache, handler.CacheTagLeaderboards, responseCacheTTL))
+	tenant.GET("/members", tenantHandler.ListMembers)
+	tenant.PUT("/members/:user_id", tenantHandler.SetMemberRole)
+	tenant.DELETE("/members/:user_id", tenantHandler.RemoveMember)
+	tenant.PUT("/premium-packs/:id", entitlementHandler.SaveTenantPack)
 
 	// Game routes
-	games := api.Group("/games")
+	games := api.Group("/games", authenticate)
 	games.POST("", gameHandler.CreateGame)
+	games.GET("/defaults", gameHandler.GetDefaultSettings,
+		handler.CacheResponses(responseCache, handler.CacheTagDefaults, responseCacheTTL))
+	games.GET("/presets", presetHandler.ListPresets)
+	games.GET("/lobbies", gameHandler.ListLobbies)
+	games.POST("/match", gameHandler.FindLobby)
 	games.GET("/:code", gameHandler.GetGame)
+	games.GET("/:code/summary", gameHandler.GetGameSummary)
+	games.GET("/:code/public-results", statsHandler.GetPublicResults)
+	games.GET("/:code/card", statsHandler.GetResultCard)
+	games.GET("/:code/qr", joinHandler.GetJoinQR)
+	games.GET("/:code/preview", joinHandler.GetPreview,
+		handler.CacheResponses(responseCache, handler.CacheTagPreview, responseCacheTTL))
+	games.DELETE("/:code", gameHandler.DeleteGame)
 	games.POST("/:code/join", gameHandler.JoinGame)
 	games.POST("/:code/start", gameHandler.StartGame)
+	games.POST("/:code/ready", gameHandler.SetReady)
+	games.DELETE("/:code/countdown", gameHandler.CancelCountdown)
+	games.GET("/:code/events", gameHandler.GetGameEvents)
+	games.GET("/:code/rounds/:number", gameHandler.GetRound)
+	games.POST("/:code/turn", gameHandler.StartTurn)
+	games.POST("/:code/category", gameHandler.SelectCategory)
 	games.POST("/:code/rounds/:round/answers", gameHandler.SubmitAnswer)
 	games.POST("/:code/rounds/:round/votes", gameHandler.SubmitVote)
 	games.POST("/:code/rounds/:round/end", gameHandler.EndRound)
 	games.POST("/:code/end", gameHandler.EndGame)
+	games.POST("/:code/extend", gameHandler.ExtendGame)
+	games.POST("/:code/phase/skip", gameHandler.SkipPhase)
+	games.POST("/:code/timer/extend", gameHandler.ExtendTimer)
+	games.POST("/:code/overlay-token", gameHandler.IssueOverlayToken)
+	games.POST("/:code/players/:id/mute", gameHandler.MutePlayer)
+	games.DELETE("/:code/players/:id/mute", gameHandler.UnmutePlayer)
+	games.POST("/:code/stream", gameHandler.ConnectStream)
+	games.DELETE("/:code/stream", gameHandler.DisconnectStream)
+
+	// Template routes
+	templates := api.Group("/templates", authenticate)
+	templates.GET("", templateHandler.ListTemplates)
+	templates.POST("", templateHandler.CreateTemplate)
+	templates.GET("/:id", templateHandler.GetTemplate)
+	templates.PUT("/:id", templateHandler.UpdateTemplate)
+	templates.DELETE("/:id", templateHandler.DeleteTemplate)
+
+	// Party routes
+	api.GET("/parties/:id", gameHandler.GetPartyScoreboard)
+
+	// Admin routes, served only when an admin token is configured
+	if adminToken := getEnv("ADMIN_TOKEN", ""); adminToken != "" {
+		admin := api.Group("/admin", handler.RequireAdminToken(adminToken))
+		admin.POST("/categories/:category/rename", gameHandler.RenameCategory)
+		admin.GET("/questions", gameHandler.ListQuestions,
+			handler.CacheResponses(responseCache, handler.CacheTagQuestions, responseCacheTTL))
+		admin.POST("/questions/bulk", gameHandler.BulkCreateQuestions)
+		admin.GET("/questions/imports/:job_id", gameHandler.GetImportJob)
+		admin.DELETE("/questions/:id", gameHandler.DeleteQuestion)
+		admin.POST("/questions/:id/restore", gameHandler.RestoreQuestion)
+		admin.POST("/packs/:language/releases", packHandler.PublishPack)
+		admin.PUT("/premium-packs/:id", entitlementHandler.SavePremiumPack)
+		admin.GET("/tenants", tenantHandler.ListTenants)
+		admin.PUT("/tenants/:id", tenantHandler.SaveTenant)
+		admin.GET("/tenants/:id/members", tenantHandler.ListTenantMembers)
+		admin.PUT("/tenants/:id/members/:user_id", tenantHandler.AssignMember)
+		admin.DELETE("/tenants/:id/members/:user_id", tenantHandler.UnassignMember)
+		admin.GET("/tenants/:id/sso", tenantHandler.GetSSO)
+		admin.PUT("/tenants/:id/sso", tenantHandler.SaveSSO)
+		admin.DELETE("/tenants/:id/sso", tenantHandler.DeleteSSO)
+		admin.GET("/users", userHandler.ListUsers)
+		admin.POST("/users/:id/entitlements", entitlementHandler.GrantEntitlement)
+		admin.GET("/users/:id/entitlements", entitlementHandler.ListEntitlements)
+		admin.DELETE("/users/:id/entitlements/:pack", entitlementHandler.RevokeEntitlement)
+		admin.GET("/users/:id/entitlement-log", entitlementHandler.ListEntitlementLog)
+		admin.POST("/promo-batches", promoHandler.CreateBatch)
+		admin.GET("/promo-batches", promoHandler.ListBatches)
+		admin.GET("/promo-batches/:id/codes", promoHandler.ListCodes)
+		admin.POST("/templates", templateHandler.CreateGlobalTemplate)
+		admin.PUT("/templates/:id", templateHandler.UpdateGlobalTemplate)
+		admin.DELETE("/templates/:id", templateHandler.DeleteGlobalTemplate)
+		admin.GET("/users/:id/signals", abuseHandler.ListUserSignals)
+		admin.GET("/abuse/shared-ips", abuseHandler.ListSharedIPs)
+		admin.POST("/users/:id/bans", banHandler.IssueBan)
+		admin.GET("/users/:id/bans", banHandler.ListBans)
+		admin.POST("/bans/:id/lift", banHandler.LiftBan)
+		admin.PUT("/bans/:id/appeal", banHandler.UpdateAppealNote)
+		admin.GET("/moderation-log", banHandler.ListModerationLog)
+		admin.GET("/submissions", submissionHandler.ListQueue)
+		admin.POST("/submissions/:id/approve", submissionHandler.ApproveSubmission)
+		admin.POST("/submissions/:id/reject", submissionHandler.RejectSubmission)
+		admin.GET("/users/:id/reputation", submissionHandler.GetReputation)
+		admin.GET("/trust-thresholds", submissionHandler.GetTrustThresholds)
+		admin.PUT("/trust-thresholds", submissionHandler.UpdateTrustThresholds)
+	}
 
 	// WebSocket route
 	e.GET("/ws", wsHandler.HandleWebSocket)
@@ -119,8 +522,9 @@ func main() {
 
 	// Health check endpoint
 	e.GET("/health", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, map[string]string{
+		return c.JSON(http.StatusOK, map[string]any{
 			"status": "ok",
+			"hub":    hub.Stats(),
 		})
 	})
 
@@ -139,6 +543,16 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if debugServer != nil {
+		if err := debugServer.Shutdown(ctx); err != nil {
+			log.Printf("Failed to shut down debug server: %v", err)
+		}
+	}
+
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+
 	if err := e.Shutdown(ctx); err != nil {
 		e.Logger.Fatal(err)
 	}
@@ -152,3 +566,19 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// parseDurations parses a comma-separated list of durations
+func parseDurations(value string) ([]time.Duration, error) {
+	var durations []time.Duration
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		d, err := time.ParseDuration(field)
+		if err != nil {
+			return nil, err
+		}
+		durations = append(durations, d)
+	}
+	return durations, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/zizouhuweidi/dahaa/internal/database"
+	"github.com/zizouhuweidi/dahaa/internal/repository/postgres"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+	"github.com/zizouhuweidi/dahaa/internal/session"
+	"github.com/zizouhuweidi/dahaa/internal/websocket"
+)
+
+// deps holds the connections and services a command needs
+type deps struct {
+	pool  *pgxpool.Pool
+	redis *redis.Client
+}
+
+// connectDB connects to PostgreSQL using the same environment as the API
+// server, except that queries may run for as long as they take
+func connectDB() (*deps, error) {
+	pool, err := postgres.NewDB(postgres.WithoutQueryTimeout())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return &deps{pool: pool}, nil
+}
+
+// connectAll connects to PostgreSQL and Redis
+func connectAll() (*deps, error) {
+	d, err := connectDB()
+	if err != nil {
+		return nil, err
+	}
+
+	d.redis, err = database.ConnectRedis(database.NewRedisConfig())
+	if err != nil {
+		d.Close()
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// gameService builds a game service backed by the connected stores. The CLI
+// serves no WebSockets: its messages are queued in the outbox and relayed to
+// clients by the API server, and only game deletions reach its idle hub.
+func (d *deps) gameService() *service.GameService {
+	sessions := session.NewManager(d.redis)
+	return service.NewGameService(
+		postgres.NewGameRepository(d.pool),
+		postgres.NewGameEventRepository(d.pool),
+		postgres.NewGameChangeStore(d.pool),
+		postgres.NewQuestionRepository(d.pool),
+		websocket.NewHub(),
+		sessions,
+		service.WithParties(postgres.NewPartyRepository(d.pool)),
+		service.WithQuestionHistory(sessions),
+	)
+}
+
+// userService builds a user service backed by the connected database.
+// Invite notifications go to a hub with no clients.
+func (d *deps) userService() *service.UserService {
+	return service.NewUserService(
+		postgres.NewUserRepository(d.pool),
+		postgres.NewGameInviteRepository(d.pool),
+		websocket.NewHub(),
+	)
+}
+
+// Close releases all connections
+func (d *deps) Close() {
+	if d.redis != nil {
+		d.redis.Close()
+	}
+	d.pool.Close()
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+	"github.com/zizouhuweidi/dahaa/internal/repository/postgres"
+)
+
+// newGamesCmd builds the games command group
+func newGamesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "games",
+		Short: "Inspect and manage games",
+	}
+	cmd.AddCommand(
+		newGamesListCmd(),
+		newGamesEndCmd(),
+		newGamesEventsCmd(),
+		newGamesReplayCmd(),
+	)
+	return cmd
+}
+
+// newGamesListCmd builds the command that prints a page of games
+func newGamesListCmd() *cobra.Command {
+	var status, cursor string
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List games",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := url.Values{"limit": {strconv.Itoa(limit)}}
+			if status != "" {
+				query.Set("status", status)
+			}
+			if cursor != "" {
+				query.Set("cursor", cursor)
+			}
+
+			params, err := pagination.Parse(query, domain.GameListOptions)
+			if err != nil {
+				return err
+			}
+
+			d, err := connectDB()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			page, err := postgres.NewGameRepository(d.pool).List(cmd.Context(), params)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CODE\tSTATUS\tPLAYERS\tROUNDS\tHOST\tCREATED")
+			for _, game := range page.Items {
+				fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\n",
+					game.Code,
+					game.Status,
+					len(game.Players),
+					len(game.Rounds),
+					game.HostID,
+					game.CreatedAt.Format(time.RFC3339),
+				)
+			}
+			w.Flush()
+
+			if page.HasMore {
+				fmt.Printf("\nMore games available: --cursor %s\n", page.NextCursor)
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&status, "status", "", "only list games with this status (waiting, playing, ended)")
+	cmd.Flags().IntVar(&limit, "limit", pagination.DefaultLimit, "maximum number of games to list")
+	cmd.Flags().StringVar(&cursor, "cursor", "", "cursor returned by a previous listing")
+	return cmd
+}
+
+// newGamesEndCmd builds the command that force-ends a game, notifying its
+// players
+func newGamesEndCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "end <code>",
+		Short: "Force-end a game",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := connectAll()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			if err := d.gameService().ForceEndGame(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+
+			fmt.Printf("Ended game %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+// newGamesEventsCmd builds the command that prints a game's event stream
+func newGamesEventsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "events <game-id>",
+		Short: "Print a game's event stream",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := connectDB()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			events, err := postgres.NewGameEventRepository(d.pool).ListByGame(cmd.Context(), args[0], 0)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SEQ\tTYPE\tOCCURRED\tPAYLOAD")
+			for _, event := range events {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n",
+					event.Sequence,
+					event.Type,
+					event.OccurredAt.Format(time.RFC3339),
+					event.Payload,
+				)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// newGamesReplayCmd builds the command that rebuilds a game from its event
+// stream and prints the result
+func newGamesReplayCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "replay <game-id>",
+		Short: "Rebuild a game from its event stream",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := connectDB()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			events, err := postgres.NewGameEventRepository(d.pool).ListByGame(cmd.Context(), args[0], 0)
+			if err != nil {
+				return err
+			}
+			game, err := domain.ReplayGame(events)
+			if err != nil {
+				return err
+			}
+
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(game)
+		},
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// newRootCmd builds the dahaa-cli command tree
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "dahaa-cli",
+		Short:         "Operate a Dahaa deployment",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.AddCommand(
+		newQuestionsCmd(),
+		newUsersCmd(),
+		newGamesCmd(),
+		newStatsCmd(),
+		newSeedCmd(),
+		newMigrateCmd(),
+	)
+	return root
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := newRootCmd().ExecuteContext(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
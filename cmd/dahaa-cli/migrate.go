@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zizouhuweidi/dahaa/internal/database"
+)
+
+// migrationsDir returns the default migrations directory
+func migrationsDir() string {
+	if dir := os.Getenv("MIGRATIONS_ROOT"); dir != "" {
+		return dir
+	}
+	return "migrations"
+}
+
+// newMigrateCmd builds the migrate command group
+func newMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Run database migrations",
+	}
+	cmd.AddCommand(
+		newMigrateUpCmd(),
+		newMigrateDownCmd(),
+		newMigrateVersionCmd(),
+	)
+	return cmd
+}
+
+// newMigrateUpCmd builds the command that applies all pending migrations
+func newMigrateUpCmd() *cobra.Command {
+	var dir string
+	cmd := &cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := connectDB()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			applied, err := database.MigrateUp(cmd.Context(), d.pool, dir)
+			for _, version := range applied {
+				fmt.Printf("Applied migration %d\n", version)
+			}
+			if err != nil {
+				return err
+			}
+			if len(applied) == 0 {
+				fmt.Println("No pending migrations")
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", migrationsDir(), "migrations directory")
+	return cmd
+}
+
+// newMigrateDownCmd builds the command that reverts the most recent
+// migrations
+func newMigrateDownCmd() *cobra.Command {
+	var dir string
+	var steps int
+	cmd := &cobra.Command{
+		Use:   "down",
+		Short: "Revert migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := connectDB()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			reverted, err := database.MigrateDown(cmd.Context(), d.pool, dir, steps)
+			for _, version := range reverted {
+				fmt.Printf("Reverted migration %d\n", version)
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&dir, "dir", migrationsDir(), "migrations directory")
+	cmd.Flags().IntVar(&steps, "steps", 1, "number of migrations to revert")
+	return cmd
+}
+
+// newMigrateVersionCmd builds the command that prints the current schema
+// version
+func newMigrateVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the current schema version",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := connectDB()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			version, dirty, err := database.MigrationVersion(cmd.Context(), d.pool)
+			if err != nil {
+				return err
+			}
+
+			if dirty {
+				fmt.Printf("%d (dirty)\n", version)
+			} else {
+				fmt.Println(version)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+	"github.com/zizouhuweidi/dahaa/internal/repository/postgres"
+)
+
+// questionFile is the import/export file format, matching the bulk upload request body
+type questionFile struct {
+	Questions []*domain.Question `json:"questions"`
+}
+
+// newQuestionsCmd builds the questions command group
+func newQuestionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "questions",
+		Short: "Import, export and publish questions",
+	}
+	cmd.AddCommand(
+		newQuestionsImportCmd(),
+		newQuestionsExportCmd(),
+		newQuestionsPublishCmd(),
+	)
+	return cmd
+}
+
+// newQuestionsImportCmd builds the command that imports questions from a
+// JSON file in batches
+func newQuestionsImportCmd() *cobra.Command {
+	var batchSize int
+	cmd := &cobra.Command{
+		Use:   "import <file.json|->",
+		Short: "Import questions from a JSON file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if batchSize < 1 {
+				return fmt.Errorf("the batch size must be positive")
+			}
+
+			questions, err := readQuestions(args[0])
+			if err != nil {
+				return err
+			}
+
+			d, err := connectDB()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			ctx := cmd.Context()
+			repo := postgres.NewQuestionRepository(d.pool)
+			for i, q := range questions {
+				if err := repo.ValidateQuestion(ctx, q); err != nil {
+					return fmt.Errorf("question %d: %w", i, err)
+				}
+			}
+
+			for start := 0; start < len(questions); start += batchSize {
+				end := min(start+batchSize, len(questions))
+				if err := repo.BulkCreateQuestions(ctx, questions[start:end]); err != nil {
+					return fmt.Errorf("failed to import questions %d-%d: %w", start, end-1, err)
+				}
+				fmt.Printf("Imported %d/%d questions\n", end, len(questions))
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&batchSize, "batch-size", 100, "number of questions per transaction")
+	return cmd
+}
+
+// newQuestionsPublishCmd builds the command that releases a language's
+// imported questions as the next version of its pack
+func newQuestionsPublishCmd() *cobra.Command {
+	var language, notes string
+	cmd := &cobra.Command{
+		Use:   "publish",
+		Short: "Release a pack's imported questions as its next version",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := connectDB()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			release, err := postgres.NewQuestionRepository(d.pool).PublishPack(cmd.Context(), language, notes, time.Now())
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Released %s pack version %d with %d questions\n", release.Language, release.Version, release.Questions)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&language, "language", domain.DefaultQuestionLanguage, "language of the pack to release")
+	cmd.Flags().StringVar(&notes, "notes", "", "release notes shown in the packs changelog")
+	return cmd
+}
+
+// newQuestionsExportCmd builds the command that exports questions to a JSON
+// file
+func newQuestionsExportCmd() *cobra.Command {
+	var category, output string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export questions to a JSON file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := connectDB()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			query := url.Values{"limit": {strconv.Itoa(pagination.MaxLimit)}, "order": {"asc"}}
+			if category != "" {
+				query.Set("category", category)
+			}
+
+			repo := postgres.NewQuestionRepository(d.pool)
+			file := questionFile{Questions: []*domain.Question{}}
+			for {
+				params, err := pagination.Parse(query, domain.QuestionListOptions)
+				if err != nil {
+					return err
+				}
+
+				page, err := repo.List(cmd.Context(), params)
+				if err != nil {
+					return err
+				}
+				file.Questions = append(file.Questions, page.Items...)
+
+				if !page.HasMore {
+					break
+				}
+				query.Set("cursor", page.NextCursor)
+			}
+
+			data, err := json.MarshalIndent(file, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal questions: %w", err)
+			}
+
+			if output == "-" {
+				_, err = os.Stdout.Write(append(data, '\n'))
+				return err
+			}
+			if err := os.WriteFile(output, data, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+			fmt.Fprintf(os.Stderr, "Exported %d questions to %s\n", len(file.Questions), output)
+
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&category, "category", "", "only export questions in this category")
+	cmd.Flags().StringVarP(&output, "output", "o", "-", "output file, or - for stdout")
+	return cmd
+}
+
+// readQuestions reads questions from a file in either the export format or
+// as a bare JSON array
+func readQuestions(path string) ([]*domain.Question, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var questions []*domain.Question
+		if err := json.Unmarshal(trimmed, &questions); err != nil {
+			return nil, fmt.Errorf("failed to parse questions: %w", err)
+		}
+		return questions, nil
+	}
+
+	var file questionFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse questions: %w", err)
+	}
+	return file.Questions, nil
+}
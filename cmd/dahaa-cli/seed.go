@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zizouhuweidi/dahaa/internal/repository/postgres"
+	"github.com/zizouhuweidi/dahaa/internal/seed"
+)
+
+// newSeedCmd builds the command that populates the database with sample
+// questions, demo users and a demo lobby
+func newSeedCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "seed",
+		Short: "Populate sample questions, demo users and a demo lobby",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := connectAll()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			userService := d.userService()
+			seeder := seed.NewSeeder(postgres.NewQuestionRepository(d.pool), userService, d.gameService())
+
+			result, err := seeder.Run(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Created %d questions and %d demo users\n", result.Questions, result.Users)
+			for _, category := range result.Skipped {
+				fmt.Printf("Skipped category %q, it already has questions\n", category)
+			}
+			for _, code := range result.GameCodes {
+				fmt.Printf("Created demo lobby %s\n", code)
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zizouhuweidi/dahaa/internal/repository/postgres"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// newStatsCmd builds the stats command group
+func newStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Maintain player stats",
+	}
+	cmd.AddCommand(newStatsRollupCmd())
+	return cmd
+}
+
+// newStatsRollupCmd builds the command that refreshes the weekly and monthly
+// rollups around a date, which backfills periods the scheduled job has
+// already moved past
+func newStatsRollupCmd() *cobra.Command {
+	var date string
+	cmd := &cobra.Command{
+		Use:   "rollup",
+		Short: "Refresh weekly and monthly stats rollups",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			at := time.Now()
+			if date != "" {
+				parsed, err := time.Parse("2006-01-02", date)
+				if err != nil {
+					return fmt.Errorf("invalid date: %w", err)
+				}
+				at = parsed
+			}
+
+			d, err := connectDB()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			statsService := service.NewStatsService(
+				postgres.NewUserRepository(d.pool),
+				postgres.NewGameResultRepository(d.pool),
+				postgres.NewStatsRollupRepository(d.pool),
+				postgres.NewCategoryStatsRepository(d.pool),
+			)
+			if err := statsService.RefreshRollups(cmd.Context(), at); err != nil {
+				return err
+			}
+
+			fmt.Printf("Refreshed stats rollups around %s\n", at.Format("2006-01-02"))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&date, "date", "", "refresh the periods containing and preceding this date, as YYYY-MM-DD (defaults to today)")
+	return cmd
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/cobra"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// newUsersCmd builds the users command group
+func newUsersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "users",
+		Short: "Manage users",
+	}
+	cmd.AddCommand(
+		newUsersCreateAdminCmd(),
+		newUsersPurgeInvitesCmd(),
+	)
+	return cmd
+}
+
+// newUsersCreateAdminCmd builds the command that creates a user with
+// administrative access
+func newUsersCreateAdminCmd() *cobra.Command {
+	var req service.RegisterRequest
+	cmd := &cobra.Command{
+		Use:   "create-admin",
+		Short: "Create an admin user",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if req.DisplayName == "" {
+				req.DisplayName = req.Username
+			}
+			if err := validator.New().Struct(req); err != nil {
+				return err
+			}
+
+			d, err := connectDB()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			userService := d.userService()
+
+			user, err := userService.CreateAdmin(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Created admin user %s (%s)\n", user.Username, user.ID)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&req.Username, "username", "", "username (required)")
+	cmd.Flags().StringVar(&req.Email, "email", "", "email address (required)")
+	cmd.Flags().StringVar(&req.DisplayName, "display-name", "", "display name (defaults to username)")
+	cmd.Flags().StringVar(&req.Password, "password", os.Getenv("DAHAA_ADMIN_PASSWORD"), "password (defaults to $DAHAA_ADMIN_PASSWORD)")
+	cmd.MarkFlagRequired("username")
+	cmd.MarkFlagRequired("email")
+	return cmd
+}
+
+// newUsersPurgeInvitesCmd builds the command that runs the invite cleanup
+// job once
+func newUsersPurgeInvitesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "purge-invites",
+		Short: "Expire lapsed invites and purge old ones",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := connectDB()
+			if err != nil {
+				return err
+			}
+			defer d.Close()
+
+			userService := d.userService()
+
+			expired, purged, err := userService.CleanupExpiredInvites(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Expired %d invites, purged %d\n", expired, purged)
+			return nil
+		},
+	}
+}
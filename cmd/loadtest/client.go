@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	ws "github.com/zizouhuweidi/dahaa/internal/websocket"
+)
+
+// client is a thin API client that records the latency of every call
+type client struct {
+	baseURL string
+	http    *http.Client
+	rec     *recorder
+}
+
+// apiError is a non-2xx response from the server
+type apiError struct {
+	Status int
+	Body   string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.Status, strings.TrimSpace(e.Body))
+}
+
+// do performs a JSON request as the player holding token and decodes the response into out
+func (c *client) do(ctx context.Context, op, method, path, token string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("X-Player-Token", token)
+	}
+
+	start := time.Now()
+	err = c.send(req, out)
+	c.rec.record(op, time.Since(start), err)
+	return err
+}
+
+// send executes a request and decodes a successful response
+func (c *client) send(req *http.Request, out any) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &apiError{Status: resp.StatusCode, Body: string(data)}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// createGame creates a game hosted by player and returns it with the host's token
+func (c *client) createGame(ctx context.Context, player domain.Player, settings *domain.GameSettings) (*domain.Game, string, error) {
+	var resp struct {
+		*domain.Game
+		PlayerToken string `json:"player_token"`
+	}
+	body := map[string]any{"player": player, "settings": settings}
+	if err := c.do(ctx, "create_game", http.MethodPost, "/api/games", "", body, &resp); err != nil {
+		return nil, "", err
+	}
+	return resp.Game, resp.PlayerToken, nil
+}
+
+// joinGame joins player to the game and returns their token
+func (c *client) joinGame(ctx context.Context, code string, player domain.Player) (string, error) {
+	var resp struct {
+		PlayerToken string `json:"player_token"`
+	}
+	if err := c.do(ctx, "join_game", http.MethodPost, "/api/games/"+code+"/join", "", player, &resp); err != nil {
+		return "", err
+	}
+	return resp.PlayerToken, nil
+}
+
+// startGame starts the game as the host
+func (c *client) startGame(ctx context.Context, code, token string) error {
+	return c.do(ctx, "start_game", http.MethodPost, "/api/games/"+code+"/start", token, nil, nil)
+}
+
+// getRound fetches the sanitized state of a round
+func (c *client) getRound(ctx context.Context, code string, number int, token string) (*domain.RoundView, error) {
+	var round domain.RoundView
+	path := fmt.Sprintf("/api/games/%s/rounds/%d", code, number)
+	if err := c.do(ctx, "get_round", http.MethodGet, path, token, nil, &round); err != nil {
+		return nil, err
+	}
+	return &round, nil
+}
+
+// submitAnswer submits a fake answer for a round
+func (c *client) submitAnswer(ctx context.Context, code string, number int, token, answer string) error {
+	path := fmt.Sprintf("/api/games/%s/rounds/%d/answers", code, number)
+	return c.do(ctx, "submit_answer", http.MethodPost, path, token, map[string]string{"answer": answer}, nil)
+}
+
+// submitVote votes for an answer option
+func (c *client) submitVote(ctx context.Context, code string, number int, token, answerID string) error {
+	path := fmt.Sprintf("/api/games/%s/rounds/%d/votes", code, number)
+	return c.do(ctx, "submit_vote", http.MethodPost, path, token, map[string]string{"answer_id": answerID}, nil)
+}
+
+// endRound ends a round as the host
+func (c *client) endRound(ctx context.Context, code string, number int, token string) error {
+	path := fmt.Sprintf("/api/games/%s/rounds/%d/end", code, number)
+	return c.do(ctx, "end_round", http.MethodPost, path, token, nil, nil)
+}
+
+// endGame ends the game as the host
+func (c *client) endGame(ctx context.Context, code, token string) error {
+	return c.do(ctx, "end_game", http.MethodPost, "/api/games/"+code+"/end", token, nil, nil)
+}
+
+// hubStats reads the hub counters from the health endpoint
+func (c *client) hubStats(ctx context.Context) (ws.HubStats, error) {
+	var resp struct {
+		Hub ws.HubStats `json:"hub"`
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/health", nil)
+	if err != nil {
+		return ws.HubStats{}, err
+	}
+	err = c.send(req, &resp)
+	return resp.Hub, err
+}
+
+// subscribe opens a WebSocket to the game's room and counts messages until
+// the connection closes. The returned channel is closed once reading stops.
+func (c *client) subscribe(ctx context.Context, gameID string) (*websocket.Conn, <-chan struct{}, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	u.Scheme = strings.Replace(u.Scheme, "http", "ws", 1)
+	u.Path = "/ws"
+	u.RawQuery = url.Values{"game_id": {gameID}}.Encode()
+
+	start := time.Now()
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, u.String(), nil)
+	c.rec.record("ws_connect", time.Since(start), err)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				// A close we did not initiate means the hub dropped us
+				if ctx.Err() == nil && !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+					c.rec.count("ws_unexpected_closes", 1)
+				}
+				return
+			}
+			c.rec.count("ws_messages_received", 1)
+		}
+	}()
+
+	return conn, done, nil
+}
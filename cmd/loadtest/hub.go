@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	ws "github.com/zizouhuweidi/dahaa/internal/websocket"
+)
+
+// hubPayload stands in for a game snapshot broadcast on every change
+var hubPayload = []byte(`{"id":"game","status":"playing","players":[` + strings.Repeat(`{"id":"player","name":"Player","score":100,"is_connected":true},`, 24) + `{}]}`)
+
+// hubResult is the throughput of one in-process hub run
+type hubResult struct {
+	shards     int
+	broadcasts int64
+	deliveries int64
+	churn      int64 // Clients registered and unregistered again
+	shed       int64
+	dropped    int64
+	elapsed    time.Duration
+}
+
+// runHubLoad drives in-process hubs with one shard count after another. Each
+// game gets sockets that drain their messages as fast as they arrive, a
+// broadcaster sending snapshots in a loop and a client repeatedly joining
+// and leaving, so the runs compare how the shard counts hold up under the
+// same load without a server or network in the way.
+func runHubLoad(w io.Writer, games int, sockets int, shardCounts []int, duration time.Duration) {
+	fmt.Fprintf(w, "Driving in-process hubs with %d games of %d sockets for %s per run\n\n", games, sockets, duration)
+
+	results := make([]hubResult, 0, len(shardCounts))
+	for _, n := range shardCounts {
+		results = append(results, runHub(games, sockets, n, duration))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "shards\tbroadcasts/s\tdeliveries/s\tchurn/s\tshed\tdropped\t")
+	for _, r := range results {
+		seconds := r.elapsed.Seconds()
+		fmt.Fprintf(tw, "%d\t%.0f\t%.0f\t%.0f\t%d\t%d\t\n", r.shards,
+			float64(r.broadcasts)/seconds, float64(r.deliveries)/seconds, float64(r.churn)/seconds, r.shed, r.dropped)
+	}
+	tw.Flush()
+}
+
+// runHub runs the load against a hub with n shards. The hub's shards keep
+// running once the run is over, idle.
+func runHub(games int, sockets int, n int, duration time.Duration) hubResult {
+	hub := ws.NewHub(ws.WithShards(n))
+	go hub.Run()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	var broadcasts, deliveries, churn atomic.Int64
+
+	// drain receives a client's messages until the run is over
+	drain := func(client *ws.Client) {
+		defer wg.Done()
+		for {
+			select {
+			case <-client.Ready():
+				deliveries.Add(int64(len(client.Drain())))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	for g := 0; g < games; g++ {
+		gameID := "game-" + strconv.Itoa(g)
+		for i := 0; i < sockets; i++ {
+			client := &ws.Client{Hub: hub, GameID: gameID}
+			hub.Register(client)
+			wg.Add(1)
+			go drain(client)
+		}
+	}
+
+	start := time.Now()
+	for g := 0; g < games; g++ {
+		gameID := "game-" + strconv.Itoa(g)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				hub.BroadcastToGame(gameID, "game_updated", hubPayload)
+				broadcasts.Add(1)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				client := &ws.Client{Hub: hub, GameID: gameID}
+				hub.Register(client)
+				hub.Unregister(client)
+				churn.Add(1)
+			}
+		}()
+	}
+
+	time.Sleep(duration)
+	cancel()
+	elapsed := time.Since(start)
+	wg.Wait()
+
+	stats := hub.Stats()
+	return hubResult{
+		shards:     n,
+		broadcasts: broadcasts.Load(),
+		deliveries: deliveries.Load(),
+		churn:      churn.Load(),
+		shed:       stats.Shed,
+		dropped:    stats.Dropped,
+		elapsed:    elapsed,
+	}
+}
+
+// parseShardCounts parses a comma-separated list of shard counts
+func parseShardCounts(value string) ([]int, error) {
+	var counts []int
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid shard count %q", field)
+		}
+		counts = append(counts, n)
+	}
+	if len(counts) == 0 {
+		return nil, fmt.Errorf("no shard counts given")
+	}
+	return counts, nil
+}
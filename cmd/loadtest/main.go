@@ -0,0 +1,303 @@
+// Command loadtest drives simulated games against a running server. Each game
+// has a host and a number of bot players that join over REST, listen on the
+// game's WebSocket room, answer and vote, while latencies are recorded per
+// operation. The hub's drop counter is sampled before and after the run.
+//
+// With -hub, it instead drives in-process hubs with simulated sockets, once
+// for each of the given shard counts, and compares their throughput.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// config holds the command-line options
+type config struct {
+	addr     string
+	games    int
+	bots     int
+	rate     float64
+	ramp     time.Duration
+	poll     time.Duration
+	timeout  time.Duration
+	category string
+	language string
+	hub      bool
+	shards   string
+	duration time.Duration
+}
+
+func main() {
+	var cfg config
+	flag.StringVar(&cfg.addr, "addr", "http://localhost:8080", "server base URL")
+	flag.IntVar(&cfg.games, "games", 10, "number of concurrent games")
+	flag.IntVar(&cfg.bots, "bots", 4, "bot players per game, in addition to the host")
+	flag.Float64Var(&cfg.rate, "rate", 2, "maximum requests per second per bot")
+	flag.DurationVar(&cfg.ramp, "ramp", 5*time.Second, "period over which games are started")
+	flag.DurationVar(&cfg.poll, "poll", 500*time.Millisecond, "interval between round state polls")
+	flag.DurationVar(&cfg.timeout, "timeout", 2*time.Minute, "maximum duration of the run")
+	flag.StringVar(&cfg.category, "category", "capitals", "question category to play")
+	flag.StringVar(&cfg.language, "language", domain.DefaultQuestionLanguage, "language of the category's questions")
+	flag.BoolVar(&cfg.hub, "hub", false, "drive in-process hubs instead of a server, using -games and -bots")
+	flag.StringVar(&cfg.shards, "shards", "1,"+strconv.Itoa(runtime.GOMAXPROCS(0)), "comma-separated shard counts to compare with -hub")
+	flag.DurationVar(&cfg.duration, "duration", 5*time.Second, "duration of each run with -hub")
+	flag.Parse()
+
+	if cfg.games < 1 || cfg.bots < 1 || cfg.rate <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: -games and -bots must be at least 1 and -rate must be positive")
+		os.Exit(2)
+	}
+
+	if cfg.hub {
+		shardCounts, err := parseShardCounts(cfg.shards)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -shards: %v\n", err)
+			os.Exit(2)
+		}
+		runHubLoad(os.Stdout, cfg.games, cfg.bots+1, shardCounts, cfg.duration)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout)
+	defer cancel()
+
+	rec := newRecorder()
+	c := &client{
+		baseURL: cfg.addr,
+		http:    &http.Client{Timeout: 10 * time.Second},
+		rec:     rec,
+	}
+
+	before, err := c.hubStats(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to reach server: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Running %d games with %d bots each against %s\n", cfg.games, cfg.bots, cfg.addr)
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.games; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// Spread game starts evenly across the ramp period
+			delay := time.Duration(int64(cfg.ramp) * int64(i) / int64(cfg.games))
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+
+			if err := runGame(ctx, c, cfg, i); err != nil {
+				rec.count("games_failed", 1)
+				return
+			}
+			rec.count("games_completed", 1)
+		}(i)
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	after, err := c.hubStats(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to read hub stats: %v\n", err)
+	}
+
+	fmt.Printf("\nCompleted in %s\n\n", elapsed.Round(time.Millisecond))
+	rec.report(os.Stdout)
+	fmt.Printf("hub_messages_sent: %d\n", after.Sent-before.Sent)
+	fmt.Printf("hub_messages_shed: %d\n", after.Shed-before.Shed)
+	fmt.Printf("hub_messages_dropped: %d\n", after.Dropped-before.Dropped)
+	for i, shard := range after.Shards {
+		if i < len(before.Shards) {
+			fmt.Printf("hub_shard_%d: %d games, %d clients, %d sent, %d dropped\n", i, shard.Games, shard.Clients,
+				shard.Sent-before.Shards[i].Sent, shard.Dropped-before.Shards[i].Dropped)
+		}
+	}
+}
+
+// participant is the host or a bot in a simulated game
+type participant struct {
+	player  domain.Player
+	token   string
+	limiter *time.Ticker
+	conn    *websocket.Conn
+	done    <-chan struct{}
+}
+
+// wait blocks until the participant may send its next request
+func (p *participant) wait(ctx context.Context) error {
+	select {
+	case <-p.limiter.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close closes the participant's WebSocket and stops its rate limiter
+func (p *participant) close() {
+	p.limiter.Stop()
+	if p.conn == nil {
+		return
+	}
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	p.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+	select {
+	case <-p.done:
+	case <-time.After(time.Second):
+	}
+	p.conn.Close()
+}
+
+// runGame plays a single game through creation, joining, one round and the end
+func runGame(ctx context.Context, c *client, cfg config, index int) error {
+	interval := time.Duration(float64(time.Second) / cfg.rate)
+	newParticipant := func(name string) *participant {
+		return &participant{
+			player:  domain.Player{ID: fmt.Sprintf("loadtest-%d-%d-%s", time.Now().UnixNano(), index, name), Name: name},
+			limiter: time.NewTicker(interval),
+		}
+	}
+
+	host := newParticipant("host")
+	defer host.close()
+
+	settings := domain.DefaultGameSettings()
+	settings.Rounds = 1
+	settings.MaxPlayers = cfg.bots + 1
+	settings.SelectedCategories = []string{cfg.category}
+	settings.Language = cfg.language
+
+	game, token, err := c.createGame(ctx, host.player, settings)
+	if err != nil {
+		return err
+	}
+	host.token = token
+
+	participants := []*participant{host}
+	for i := 0; i < cfg.bots; i++ {
+		bot := newParticipant(fmt.Sprintf("bot%d", i+1))
+		defer bot.close()
+
+		if err := bot.wait(ctx); err != nil {
+			return err
+		}
+		if bot.token, err = c.joinGame(ctx, game.Code, bot.player); err != nil {
+			return err
+		}
+		participants = append(participants, bot)
+	}
+
+	for _, p := range participants {
+		if p.conn, p.done, err = c.subscribe(ctx, game.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := host.wait(ctx); err != nil {
+		return err
+	}
+	if err := c.startGame(ctx, game.Code, host.token); err != nil {
+		return err
+	}
+
+	// Every participant answers and votes concurrently
+	const round = 1
+	errs := make(chan error, len(participants))
+	for _, p := range participants {
+		go func(p *participant) {
+			errs <- playRound(ctx, c, cfg, game.Code, round, p)
+		}(p)
+	}
+	var roundErr error
+	for range participants {
+		if err := <-errs; err != nil && roundErr == nil {
+			roundErr = err
+		}
+	}
+	if roundErr != nil {
+		return roundErr
+	}
+
+	if err := host.wait(ctx); err != nil {
+		return err
+	}
+	if err := c.endRound(ctx, game.Code, round, host.token); err != nil {
+		return err
+	}
+	if err := host.wait(ctx); err != nil {
+		return err
+	}
+	return c.endGame(ctx, game.Code, host.token)
+}
+
+// playRound submits an answer, waits for voting to open and votes for a random option
+func playRound(ctx context.Context, c *client, cfg config, code string, number int, p *participant) error {
+	if err := p.wait(ctx); err != nil {
+		return err
+	}
+	answer := fmt.Sprintf("%s guess %d", p.player.Name, rand.Intn(1_000_000))
+	if err := c.submitAnswer(ctx, code, number, p.token, answer); err != nil {
+		return err
+	}
+
+	var options []domain.AnswerOption
+	for {
+		if err := p.wait(ctx); err != nil {
+			return err
+		}
+		round, err := c.getRound(ctx, code, number, p.token)
+		if err != nil {
+			return err
+		}
+		if round.Status == domain.RoundStatusCompleted {
+			return nil
+		}
+		if round.Status == domain.RoundStatusVoting {
+			options = round.Options
+			break
+		}
+
+		select {
+		case <-time.After(cfg.poll):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// The correct answer is listed without an ID and cannot be voted for
+	var votable []domain.AnswerOption
+	for _, option := range options {
+		if option.ID != "" {
+			votable = append(votable, option)
+		}
+	}
+	if len(votable) == 0 {
+		return nil
+	}
+
+	if err := p.wait(ctx); err != nil {
+		return err
+	}
+	return c.submitVote(ctx, code, number, p.token, votable[rand.Intn(len(votable))].ID)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// recorder collects per-operation latencies and error counts
+type recorder struct {
+	mu     sync.Mutex
+	ops    map[string]*opStats
+	order  []string
+	events map[string]int
+}
+
+// opStats holds the samples for a single operation
+type opStats struct {
+	latencies []time.Duration
+	errors    int
+	lastError string
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		ops:    make(map[string]*opStats),
+		events: make(map[string]int),
+	}
+}
+
+// record stores the outcome of one operation
+func (r *recorder) record(op string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, ok := r.ops[op]
+	if !ok {
+		stats = &opStats{}
+		r.ops[op] = stats
+		r.order = append(r.order, op)
+	}
+	stats.latencies = append(stats.latencies, latency)
+	if err != nil {
+		stats.errors++
+		stats.lastError = err.Error()
+	}
+}
+
+// count increments a named counter, e.g. WebSocket messages received
+func (r *recorder) count(event string, n int) {
+	r.mu.Lock()
+	r.events[event] += n
+	r.mu.Unlock()
+}
+
+// report writes a latency table followed by the event counters
+func (r *recorder) report(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OPERATION\tCOUNT\tERRORS\tP50\tP90\tP99\tMAX")
+	for _, op := range r.order {
+		stats := r.ops[op]
+		sorted := append([]time.Duration(nil), stats.latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\n",
+			op, len(sorted), stats.errors,
+			percentile(sorted, 50), percentile(sorted, 90), percentile(sorted, 99), percentile(sorted, 100))
+	}
+	tw.Flush()
+
+	for _, op := range r.order {
+		if stats := r.ops[op]; stats.errors > 0 {
+			fmt.Fprintf(w, "last %s error: %s\n", op, stats.lastError)
+		}
+	}
+
+	events := make([]string, 0, len(r.events))
+	for event := range r.events {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+	for _, event := range events {
+		fmt.Fprintf(w, "%s: %d\n", event, r.events[event])
+	}
+}
+
+// percentile returns the p-th percentile of sorted samples using the nearest-rank method
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1].Round(time.Microsecond)
+}
@@ -0,0 +1,170 @@
+// Package card renders the scorecard of a finished game as an image players
+// can share to chat apps: the winner, the podium and a few fun stats. Cards
+// are drawn from public results, so they carry nothing a share link would
+// not. The SVG card shows names as they are; the PNG card is drawn with a
+// built-in bitmap font, which keeps it free of dependencies but only covers
+// ASCII, so other characters are drawn as boxes.
+package card
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// Width and Height are the size of a card in pixels, the aspect ratio link
+// previews are shown at
+const (
+	Width  = 1200
+	Height = 630
+)
+
+// margin is the space left around the card's content
+const margin = 60
+
+// Colors of the card
+var (
+	background = color.RGBA{0x1e, 0x1b, 0x4b, 0xff}
+	foreground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	muted      = color.RGBA{0xa5, 0xb4, 0xfc, 0xff}
+	gold       = color.RGBA{0xfb, 0xbf, 0x24, 0xff}
+	silver     = color.RGBA{0xcb, 0xd5, 0xe1, 0xff}
+	bronze     = color.RGBA{0xd9, 0x77, 0x06, 0xff}
+)
+
+// rect is a filled rectangle
+type rect struct {
+	x, y, w, h int
+	fill       color.RGBA
+}
+
+// text is a line of text whose top left corner is at x, y. Its glyphs are
+// scale pixels per font pixel, so capitals are 7*scale pixels tall.
+type text struct {
+	x, y  int
+	scale int
+	fill  color.RGBA
+	str   string
+}
+
+// layout is what a card is made of, drawn in order
+type layout struct {
+	rects []rect
+	texts []text
+}
+
+// addText adds a line of text, truncated to fit within width pixels
+func (l *layout) addText(x, y, scale int, fill color.RGBA, str string, width int) {
+	l.texts = append(l.texts, text{x: x, y: y, scale: scale, fill: fill, str: truncate(str, width/(advance*scale))})
+}
+
+// newLayout lays out the card of a game's results
+func newLayout(result *domain.PublicResult) *layout {
+	l := &layout{}
+	l.rects = append(l.rects, rect{0, 0, Width, Height, background})
+
+	l.addText(margin, margin, 6, gold, "DAHAA", Width/2)
+	l.addText(margin, 120, 3, muted, fmt.Sprintf("GAME %s - %d ROUNDS - %d PLAYERS", result.Code, result.RoundsPlayed, result.PlayerCount), Width-2*margin)
+
+	if len(result.Players) > 0 {
+		winners := []string{}
+		for _, p := range result.Players {
+			if p.Position == 1 {
+				winners = append(winners, p.Name)
+			}
+		}
+		l.addText(margin, 170, 3, muted, "WINNER", Width/2-margin)
+		l.addText(margin, 200, 5, foreground, strings.Join(winners, " & "), Width/2-margin)
+	}
+
+	l.addPodium(result.Players)
+	l.addStats(result)
+	return l
+}
+
+// podiumWidth is the width of each step of the podium
+const podiumWidth = 160
+
+// addPodium draws the top three players on steps, the winner's in the middle
+func (l *layout) addPodium(players []domain.PublicPlayerResult) {
+	steps := []struct {
+		index  int
+		x      int
+		height int
+		fill   color.RGBA
+	}{
+		{1, margin, 120, silver},
+		{0, margin + podiumWidth + 20, 170, gold},
+		{2, margin + 2*(podiumWidth+20), 80, bronze},
+	}
+
+	base := Height - margin
+	for _, step := range steps {
+		if step.index >= len(players) {
+			continue
+		}
+		p := players[step.index]
+		top := base - step.height
+		l.rects = append(l.rects, rect{step.x, top, podiumWidth, step.height, step.fill})
+		l.addText(step.x, top-40, 3, foreground, p.Name, podiumWidth)
+		l.addText(step.x+12, top+12, 4, background, fmt.Sprintf("#%d", p.Position), podiumWidth-24)
+		l.addText(step.x+12, top+56, 2, background, fmt.Sprintf("%d PTS", p.Points), podiumWidth-24)
+	}
+}
+
+// addStats lists the game's awards and highlights down the right of the card
+func (l *layout) addStats(result *domain.PublicResult) {
+	var lines [][2]string
+	for _, award := range result.Awards {
+		lines = append(lines, [2]string{awardTitles[award.Kind], strings.Join(award.Players, " & ")})
+	}
+	if h := result.Highlights; h != nil {
+		if bluff := h.BestBluff; bluff != nil {
+			lines = append(lines, [2]string{
+				fmt.Sprintf("BEST BLUFF - FOOLED %d", len(bluff.Fooled)),
+				fmt.Sprintf("%q BY %s", bluff.Answer, bluff.Player),
+			})
+		}
+		if vote := h.ClosestVote; vote != nil {
+			lines = append(lines, [2]string{
+				fmt.Sprintf("CLOSEST VOTE - ROUND %d", vote.Round),
+				fmt.Sprintf("%d TO %d", vote.CorrectVotes, vote.FakeAnswerVotes),
+			})
+		}
+	}
+
+	x := Width/2 + margin
+	width := Width - margin - x
+	y := 180
+	for _, line := range lines {
+		if y+60 > Height-margin {
+			break
+		}
+		l.addText(x, y, 2, muted, line[0], width)
+		l.addText(x, y+22, 3, foreground, line[1], width)
+		y += 80
+	}
+}
+
+// awardTitles are the titles awards are shown with
+var awardTitles = map[domain.AwardKind]string{
+	domain.AwardBestLiar:    "BEST LIAR",
+	domain.AwardLieDetector: "LIE DETECTOR",
+	domain.AwardSpeedDemon:  "SPEED DEMON",
+}
+
+// truncate shortens a string to at most n characters, ending it with an
+// ellipsis if it was cut
+func truncate(str string, n int) string {
+	if utf8.RuneCountInString(str) <= n {
+		return str
+	}
+	if n <= 3 {
+		return strings.Repeat(".", max(n, 0))
+	}
+	runes := []rune(str)
+	return string(runes[:n-3]) + "..."
+}
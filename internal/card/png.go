@@ -0,0 +1,49 @@
+package card
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// PNG renders the card of a game's results as a PNG image
+func PNG(result *domain.PublicResult) ([]byte, error) {
+	l := newLayout(result)
+
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	for _, r := range l.rects {
+		draw.Draw(img, image.Rect(r.x, r.y, r.x+r.w, r.y+r.h), image.NewUniform(r.fill), image.Point{}, draw.Src)
+	}
+	for _, t := range l.texts {
+		drawText(img, t)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode card: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// drawText draws a line of text with the bitmap font
+func drawText(img *image.RGBA, t text) {
+	fill := image.NewUniform(t.fill)
+	x := t.x
+	for _, r := range t.str {
+		rows := glyphOf(r)
+		for row, line := range rows {
+			for col := 0; col < glyphWidth; col++ {
+				if line[col] != '#' {
+					continue
+				}
+				px, py := x+col*t.scale, t.y+row*t.scale
+				draw.Draw(img, image.Rect(px, py, px+t.scale, py+t.scale), fill, image.Point{}, draw.Src)
+			}
+		}
+		x += advance * t.scale
+	}
+}
@@ -0,0 +1,35 @@
+package card
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// SVG renders the card of a game's results as an SVG document
+func SVG(result *domain.PublicResult) []byte {
+	l := newLayout(result)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, Width, Height, Width, Height)
+	for _, r := range l.rects {
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`, r.x, r.y, r.w, r.h, hex(r.fill))
+	}
+	for _, t := range l.texts {
+		// Capitals of the bitmap font are 7 pixels of a 10 pixel em
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-family="sans-serif" font-weight="bold" font-size="%d" fill="%s">`,
+			t.x, t.y+7*t.scale, 10*t.scale, hex(t.fill))
+		xml.EscapeText(&b, []byte(t.str))
+		b.WriteString(`</text>`)
+	}
+	b.WriteString(`</svg>`)
+	return b.Bytes()
+}
+
+// hex returns a color in #rrggbb notation
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
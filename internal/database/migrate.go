@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrDirtyDatabase is returned when a previous migration failed part-way
+var ErrDirtyDatabase = errors.New("database is dirty, fix and force the version before migrating")
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version  uint64
+	Name     string
+	UpPath   string
+	DownPath string // Path to the down migration, if any
+}
+
+// LoadMigrations reads NNNNNN_name.up.sql / .down.sql pairs from a directory
+func LoadMigrations(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+
+		prefix, rest, ok := strings.Cut(name, "_")
+		if !ok {
+			continue
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version}
+			byVersion[version] = m
+		}
+
+		path := filepath.Join(dir, name)
+		switch {
+		case strings.HasSuffix(rest, ".up.sql"):
+			m.Name = strings.TrimSuffix(rest, ".up.sql")
+			m.UpPath = path
+		case strings.HasSuffix(rest, ".down.sql"):
+			m.DownPath = path
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.UpPath == "" {
+			return nil, fmt.Errorf("migration %d has no up file", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// MigrationVersion returns the current schema version and whether it is dirty.
+// The bookkeeping table is compatible with golang-migrate.
+func MigrationVersion(ctx context.Context, pool *pgxpool.Pool) (uint64, bool, error) {
+	if _, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT NOT NULL PRIMARY KEY,
+			dirty BOOLEAN NOT NULL
+		)
+	`); err != nil {
+		return 0, false, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var version uint64
+	var dirty bool
+	err := pool.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	return version, dirty, nil
+}
+
+// MigrateUp applies all pending migrations and returns the versions applied
+func MigrateUp(ctx context.Context, pool *pgxpool.Pool, dir string) ([]uint64, error) {
+	migrations, err := LoadMigrations(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	current, dirty, err := MigrationVersion(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, ErrDirtyDatabase
+	}
+
+	var applied []uint64
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if err := runMigration(ctx, pool, m.UpPath, m.Version); err != nil {
+			return applied, fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+		applied = append(applied, m.Version)
+	}
+
+	return applied, nil
+}
+
+// MigrateDown reverts up to steps applied migrations and returns the versions reverted
+func MigrateDown(ctx context.Context, pool *pgxpool.Pool, dir string, steps int) ([]uint64, error) {
+	migrations, err := LoadMigrations(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	current, dirty, err := MigrationVersion(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, ErrDirtyDatabase
+	}
+
+	var reverted []uint64
+	for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		m := migrations[i]
+		if m.Version > current {
+			continue
+		}
+		if m.DownPath == "" {
+			return reverted, fmt.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+		}
+
+		// The version after reverting is that of the previous migration
+		var previous uint64
+		if i > 0 {
+			previous = migrations[i-1].Version
+		}
+		if err := runMigration(ctx, pool, m.DownPath, previous); err != nil {
+			return reverted, fmt.Errorf("reverting migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+		reverted = append(reverted, m.Version)
+	}
+
+	return reverted, nil
+}
+
+// runMigration executes a migration file and records the resulting version,
+// leaving the database marked dirty if the file fails part-way
+func runMigration(ctx context.Context, pool *pgxpool.Pool, path string, version uint64) error {
+	sql, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read migration: %w", err)
+	}
+
+	if err := setMigrationVersion(ctx, pool, version, true); err != nil {
+		return err
+	}
+
+	// Simple protocol allows multiple statements per file
+	if _, err := pool.Exec(ctx, string(sql), pgx.QueryExecModeSimpleProtocol); err != nil {
+		return err
+	}
+
+	return setMigrationVersion(ctx, pool, version, false)
+}
+
+// setMigrationVersion replaces the recorded schema version
+func setMigrationVersion(ctx context.Context, pool *pgxpool.Pool, version uint64, dirty bool) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return fmt.Errorf("failed to clear schema version: %w", err)
+	}
+	if version > 0 || dirty {
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)`, version, dirty); err != nil {
+			return fmt.Errorf("failed to record schema version: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
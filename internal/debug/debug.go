@@ -0,0 +1,63 @@
+// Package debug serves runtime diagnostics (pprof profiles and expvar
+// variables) on a listener separate from the public API.
+package debug
+
+import (
+	"crypto/subtle"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"time"
+)
+
+func init() {
+	// expvar already publishes memstats and cmdline
+	expvar.Publish("goroutines", expvar.Func(func() any {
+		return runtime.NumGoroutine()
+	}))
+}
+
+// Publish exposes a value computed on each request under /debug/vars. Names
+// must be unique; publishing a name twice panics.
+func Publish(name string, value func() any) {
+	expvar.Publish(name, expvar.Func(value))
+}
+
+// NewServer creates the debug server. Every request must present the token,
+// either as a bearer token or as the password of HTTP basic auth, which
+// browsers and `go tool pprof` can supply.
+func NewServer(addr string, token string) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           requireToken(token, mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// requireToken rejects requests that do not carry the token
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		presented, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			_, presented, _ = r.BasicAuth()
+		}
+
+		if presented == "" || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="debug"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
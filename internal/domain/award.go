@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// AwardKind is a superlative given to a player at the end of a game
+type AwardKind string
+
+const (
+	AwardBestLiar    AwardKind = "best_liar"    // Fooled the most players
+	AwardLieDetector AwardKind = "lie_detector" // Found the correct answer most often
+	AwardSpeedDemon  AwardKind = "speed_demon"  // Answered the fastest on average
+)
+
+// Award is a superlative and the players who earned it. Tied players share
+// the award.
+type Award struct {
+	Kind      AwardKind `json:"kind"`
+	PlayerIDs []string  `json:"player_ids"`
+	Value     float64   `json:"value"` // Players fooled, correct votes, or average seconds taken to answer
+}
+
+// newAwards computes a game's awards from its players' results and completed
+// rounds. Awards nobody earned, such as Best Liar in a game where nobody was
+// fooled, are left out.
+func newAwards(game *Game, players []PlayerResult) []Award {
+	awards := []Award{}
+	if award, ok := topAward(AwardBestLiar, players, func(p PlayerResult) (float64, bool) {
+		return float64(p.PlayersFooled), p.PlayersFooled > 0
+	}, false); ok {
+		awards = append(awards, award)
+	}
+	if award, ok := topAward(AwardLieDetector, players, func(p PlayerResult) (float64, bool) {
+		return float64(p.CorrectVotes), p.CorrectVotes > 0
+	}, false); ok {
+		awards = append(awards, award)
+	}
+
+	answerTimes := averageAnswerTimes(game)
+	if award, ok := topAward(AwardSpeedDemon, players, func(p PlayerResult) (float64, bool) {
+		seconds, ok := answerTimes[p.PlayerID]
+		return seconds, ok
+	}, true); ok {
+		awards = append(awards, award)
+	}
+	return awards
+}
+
+// topAward gives an award to the players with the highest value, or the
+// lowest if lowest is set. Players without a value are not in the running.
+func topAward(kind AwardKind, players []PlayerResult, value func(PlayerResult) (float64, bool), lowest bool) (Award, bool) {
+	award := Award{Kind: kind}
+	for _, p := range players {
+		v, ok := value(p)
+		if !ok {
+			continue
+		}
+		switch {
+		case len(award.PlayerIDs) == 0 || (lowest && v < award.Value) || (!lowest && v > award.Value):
+			award.PlayerIDs, award.Value = []string{p.PlayerID}, v
+		case v == award.Value:
+			award.PlayerIDs = append(award.PlayerIDs, p.PlayerID)
+		}
+	}
+	return award, len(award.PlayerIDs) > 0
+}
+
+// averageAnswerTimes returns the average number of seconds each player took
+// to answer the questions of the game's completed rounds, from when the
+// question was asked. Rounds asked before question times were recorded are
+// skipped.
+func averageAnswerTimes(game *Game) map[string]float64 {
+	totals := make(map[string]time.Duration)
+	counts := make(map[string]int)
+	for _, round := range game.Rounds {
+		if round.Status != RoundStatusCompleted || round.AskedAt.IsZero() {
+			continue
+		}
+		for _, answer := range round.AnswerPool.FakeAnswers {
+			totals[answer.PlayerID] += max(answer.CreatedAt.Sub(round.AskedAt), 0)
+			counts[answer.PlayerID]++
+		}
+	}
+
+	averages := make(map[string]float64, len(totals))
+	for playerID, total := range totals {
+		averages[playerID] = (total / time.Duration(counts[playerID])).Round(time.Millisecond).Seconds()
+	}
+	return averages
+}
+
+// AwardsOf returns the kinds of the awards a player earned among a game's
+// awards
+func AwardsOf(awards []Award, playerID string) []AwardKind {
+	var kinds []AwardKind
+	for _, award := range awards {
+		if slices.Contains(award.PlayerIDs, playerID) {
+			kinds = append(kinds, award.Kind)
+		}
+	}
+	return kinds
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrBanNotFound is returned when no ban has the requested ID, or a user has
+// no active ban
+var ErrBanNotFound = errors.New("ban not found")
+
+// BanKind is how long a user is kept out
+type BanKind string
+
+const (
+	// BanKindSuspension keeps a user out until it expires
+	BanKindSuspension BanKind = "suspension"
+
+	// BanKindBan keeps a user out for good, unless it is lifted
+	BanKindBan BanKind = "ban"
+)
+
+// UserBan keeps a user from logging in, joining games and connecting to
+// games while it is active
+type UserBan struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Kind       BanKind    `json:"kind"`
+	Reason     string     `json:"reason"`
+	AppealNote string     `json:"appeal_note,omitempty"` // Moderators' notes on the user's appeal
+	IssuedBy   string     `json:"issued_by"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // Nil for permanent bans
+	CreatedAt  time.Time  `json:"created_at"`
+	LiftedAt   *time.Time `json:"lifted_at,omitempty"`
+	LiftedBy   string     `json:"lifted_by,omitempty"`
+}
+
+// IsActive returns whether the ban is in force at a time
+func (b *UserBan) IsActive(now time.Time) bool {
+	return b.LiftedAt == nil && (b.ExpiresAt == nil || now.Before(*b.ExpiresAt))
+}
+
+// ModerationAction is an action recorded in the moderation log
+type ModerationAction string
+
+const (
+	ModerationActionSuspend    ModerationAction = "suspend"
+	ModerationActionBan        ModerationAction = "ban"
+	ModerationActionLift       ModerationAction = "lift"
+	ModerationActionAppealNote ModerationAction = "appeal_note"
+)
+
+// ModerationLogEntry records a moderator's action on a user
+type ModerationLogEntry struct {
+	ID        string           `json:"id"`
+	Action    ModerationAction `json:"action"`
+	UserID    string           `json:"user_id"`
+	BanID     string           `json:"ban_id,omitempty"`
+	Actor     string           `json:"actor"`
+	Details   string           `json:"details,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// UserBanRepository defines the interface for ban data access. Every change
+// to a ban is saved along with its moderation log entry.
+type UserBanRepository interface {
+	// Create creates a ban
+	Create(ctx context.Context, ban *UserBan, entry *ModerationLogEntry) error
+
+	// Get retrieves a ban by ID
+	Get(ctx context.Context, id string) (*UserBan, error)
+
+	// GetActive retrieves the user's ban in force at a time, preferring
+	// permanent bans and then the one expiring last
+	GetActive(ctx context.Context, userID string, now time.Time) (*UserBan, error)
+
+	// ListByUser retrieves a user's bans, newest first
+	ListByUser(ctx context.Context, userID string) ([]*UserBan, error)
+
+	// Lift ends a ban early
+	Lift(ctx context.Context, id string, liftedBy string, at time.Time, entry *ModerationLogEntry) error
+
+	// UpdateAppealNote replaces a ban's appeal note
+	UpdateAppealNote(ctx context.Context, id string, note string, entry *ModerationLogEntry) error
+
+	// ListLog retrieves the most recent moderation log entries, newest first,
+	// only for a user when userID is set
+	ListLog(ctx context.Context, userID string, limit int) ([]*ModerationLogEntry, error)
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// CategoryStats is a player's running performance in one question category
+type CategoryStats struct {
+	UserID        string    `json:"user_id"`
+	Category      string    `json:"category"`
+	Rounds        int       `json:"rounds"`
+	VotesCast     int       `json:"votes_cast"`
+	CorrectVotes  int       `json:"correct_votes"`
+	PlayersFooled int       `json:"players_fooled"`
+	FooledByHouse int       `json:"fooled_by_house"` // Votes cast for filler answers
+	OpponentVotes int       `json:"opponent_votes"`  // Votes cast by other players in the same rounds
+	Accuracy      float64   `json:"accuracy"`        // Share of votes cast for the correct answer
+	FoolRate      float64   `json:"fool_rate"`       // Share of opponents' votes won by this player's answers
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ComputeRates derives the accuracy and fool rate from the counters
+func (s *CategoryStats) ComputeRates() {
+	s.Accuracy, s.FoolRate = 0, 0
+	if s.VotesCast > 0 {
+		s.Accuracy = float64(s.CorrectVotes) / float64(s.VotesCast)
+	}
+	if s.OpponentVotes > 0 {
+		s.FoolRate = float64(s.PlayersFooled) / float64(s.OpponentVotes)
+	}
+}
+
+// CategoryStatsRepository defines the interface for reading players'
+// per-category stats. Stats are written along with the change that ends each round.
+type CategoryStatsRepository interface {
+	// ListByUser retrieves a user's stats for every category they have played,
+	// most played first
+	ListByUser(ctx context.Context, userID string) ([]*CategoryStats, error)
+}
+
+// NewRoundCategoryStats computes each player's contribution to their stats
+// for the round's category. The returned stats are increments to be added to
+// the stored totals.
+func NewRoundCategoryStats(game *Game, round *Round, now time.Time) []*CategoryStats {
+	if round.Category == "" {
+		return nil
+	}
+
+	voted := make(map[string]bool, len(game.Players))
+	for _, voterID := range round.AnswerPool.Voters() {
+		voted[voterID] = true
+	}
+
+	performances := roundPerformances(round, game.Players)
+	stats := make([]*CategoryStats, 0, len(game.Players))
+	for _, p := range game.Players {
+		perf := performances[p.ID]
+		s := &CategoryStats{
+			UserID:        p.ID,
+			Category:      round.Category,
+			Rounds:        1,
+			PlayersFooled: perf.PlayersFooled,
+			OpponentVotes: len(voted),
+			UpdatedAt:     now,
+		}
+		if voted[p.ID] {
+			s.VotesCast = 1
+			s.OpponentVotes--
+		}
+		if perf.CorrectVote {
+			s.CorrectVotes = 1
+		}
+		if perf.FooledByHouse {
+			s.FooledByHouse = 1
+		}
+		s.ComputeRates()
+		stats = append(stats, s)
+	}
+	return stats
+}
@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Cosmetic errors
+var (
+	ErrCosmeticNotFound = errors.New("cosmetic not found")
+	ErrCosmeticLocked   = errors.New("cosmetic has not been unlocked")
+)
+
+// LevelPoints is the number of career points between player levels
+const LevelPoints = 100
+
+// CosmeticKind is the slot a cosmetic is equipped in. A player wears at most
+// one cosmetic of each kind.
+type CosmeticKind string
+
+const (
+	CosmeticKindNameColor        CosmeticKind = "name_color"        // Color of the player's name
+	CosmeticKindAvatarFrame      CosmeticKind = "avatar_frame"      // Frame around the player's avatar
+	CosmeticKindVictoryAnimation CosmeticKind = "victory_animation" // Animation played when the player wins
+)
+
+// CosmeticKinds lists the kinds of cosmetics
+var CosmeticKinds = []CosmeticKind{CosmeticKindNameColor, CosmeticKindAvatarFrame, CosmeticKindVictoryAnimation}
+
+// CosmeticRequirement is the achievement that unlocks a cosmetic. Every
+// threshold that is set must be reached.
+type CosmeticRequirement struct {
+	Level         int `json:"level,omitempty"`
+	GamesWon      int `json:"games_won,omitempty"`
+	PlayersFooled int `json:"players_fooled,omitempty"`
+	CorrectVotes  int `json:"correct_votes,omitempty"`
+}
+
+// MetBy returns whether a player's career meets the requirement
+func (r CosmeticRequirement) MetBy(career *CareerStats) bool {
+	return career.Level() >= r.Level &&
+		career.GamesWon >= r.GamesWon &&
+		career.PlayersFooled >= r.PlayersFooled &&
+		career.CorrectVotes >= r.CorrectVotes
+}
+
+// Cosmetic is an unlockable decoration, rendered by clients from its ID
+type Cosmetic struct {
+	ID          string               `json:"id"`
+	Kind        CosmeticKind         `json:"kind"`
+	Requirement *CosmeticRequirement `json:"requirement,omitempty"` // Nil for cosmetics only unlocked by promo codes
+}
+
+// Cosmetics is the catalog of cosmetics
+var Cosmetics = []Cosmetic{
+	{ID: "name_color_gold", Kind: CosmeticKindNameColor, Requirement: &CosmeticRequirement{Level: 5}},
+	{ID: "name_color_crimson", Kind: CosmeticKindNameColor, Requirement: &CosmeticRequirement{GamesWon: 10}},
+	{ID: "name_color_emerald", Kind: CosmeticKindNameColor, Requirement: &CosmeticRequirement{Level: 20}},
+	{ID: "name_color_violet", Kind: CosmeticKindNameColor},
+	{ID: "avatar_frame_bronze", Kind: CosmeticKindAvatarFrame, Requirement: &CosmeticRequirement{Level: 3}},
+	{ID: "avatar_frame_silver", Kind: CosmeticKindAvatarFrame, Requirement: &CosmeticRequirement{Level: 15}},
+	{ID: "avatar_frame_trickster", Kind: CosmeticKindAvatarFrame, Requirement: &CosmeticRequirement{PlayersFooled: 100}},
+	{ID: "avatar_frame_oracle", Kind: CosmeticKindAvatarFrame, Requirement: &CosmeticRequirement{CorrectVotes: 100}},
+	{ID: "avatar_frame_launch", Kind: CosmeticKindAvatarFrame},
+	{ID: "victory_confetti", Kind: CosmeticKindVictoryAnimation, Requirement: &CosmeticRequirement{GamesWon: 1}},
+	{ID: "victory_fireworks", Kind: CosmeticKindVictoryAnimation, Requirement: &CosmeticRequirement{GamesWon: 25}},
+	{ID: "victory_crown", Kind: CosmeticKindVictoryAnimation, Requirement: &CosmeticRequirement{Level: 25}},
+}
+
+// FindCosmetic returns the catalog's cosmetic with an ID
+func FindCosmetic(id string) (*Cosmetic, bool) {
+	for i := range Cosmetics {
+		if Cosmetics[i].ID == id {
+			return &Cosmetics[i], true
+		}
+	}
+	return nil, false
+}
+
+// CareerStats are a player's totals over every game they finished
+type CareerStats struct {
+	GamesPlayed   int `json:"games_played"`
+	GamesWon      int `json:"games_won"`
+	TotalPoints   int `json:"total_points"`
+	PlayersFooled int `json:"players_fooled"`
+	CorrectVotes  int `json:"correct_votes"`
+	LikesReceived int `json:"likes_received"` // Likes other players gave to their answers
+}
+
+// Level returns the player level reached with the career's points, starting
+// at 1
+func (c *CareerStats) Level() int {
+	return 1 + c.TotalPoints/LevelPoints
+}
+
+// CosmeticUnlock records a cosmetic a user has unlocked
+type CosmeticUnlock struct {
+	UserID     string    `json:"user_id"`
+	Cosmetic   string    `json:"cosmetic"`
+	Equipped   bool      `json:"equipped"`
+	UnlockedAt time.Time `json:"unlocked_at"`
+}
+
+// EquippedCosmetics are the cosmetics a player wears, shown to everyone in
+// their games
+type EquippedCosmetics struct {
+	NameColor        string `json:"name_color,omitempty"`
+	AvatarFrame      string `json:"avatar_frame,omitempty"`
+	VictoryAnimation string `json:"victory_animation,omitempty"`
+}
+
+// Set wears a cosmetic in the slot of its kind
+func (e *EquippedCosmetics) Set(kind CosmeticKind, id string) {
+	switch kind {
+	case CosmeticKindNameColor:
+		e.NameColor = id
+	case CosmeticKindAvatarFrame:
+		e.AvatarFrame = id
+	case CosmeticKindVictoryAnimation:
+		e.VictoryAnimation = id
+	}
+}
+
+// IsZero returns whether no cosmetic is worn
+func (e EquippedCosmetics) IsZero() bool {
+	return e == EquippedCosmetics{}
+}
+
+// CosmeticRepository defines the interface for users' cosmetics
+type CosmeticRepository interface {
+	// Unlock records cosmetics as unlocked by a user, keeping those already
+	// unlocked as they are
+	Unlock(ctx context.Context, userID string, cosmetics []string, at time.Time) error
+
+	// ListUnlocked retrieves the cosmetics a user has unlocked, ordered by
+	// cosmetic
+	ListUnlocked(ctx context.Context, userID string) ([]*CosmeticUnlock, error)
+
+	// Equip wears a cosmetic, taking off the others of its kind, listed in
+	// kindCosmetics. An empty cosmetic takes them all off. It returns
+	// ErrCosmeticLocked if the user has not unlocked the cosmetic.
+	Equip(ctx context.Context, userID string, cosmetic string, kindCosmetics []string) error
+}
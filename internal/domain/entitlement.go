@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Entitlement errors
+var (
+	ErrPremiumPackNotFound = errors.New("premium pack not found")
+	ErrEntitlementNotFound = errors.New("entitlement not found")
+	ErrEntitlementExists   = errors.New("user already owns this premium pack")
+	ErrGiftLimitReached    = errors.New("too many premium packs gifted today")
+)
+
+// PremiumPack is a set of categories only players entitled to the pack can
+// play. Categories in no premium pack are free. Packs private to an
+// organization are owned by its members and hidden from everyone else.
+type PremiumPack struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Categories []string  `json:"categories"`
+	TenantID   string    `json:"tenant_id,omitempty"` // Organization the pack is private to, if any
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// EntitlementSource is how a user came to own a premium pack
+type EntitlementSource string
+
+const (
+	// EntitlementSourceGrant is a pack granted by an admin
+	EntitlementSourceGrant EntitlementSource = "grant"
+
+	// EntitlementSourceGift is a pack another user gave up to the owner
+	EntitlementSourceGift EntitlementSource = "gift"
+
+	// EntitlementSourcePromo is a pack unlocked by redeeming a promo code
+	EntitlementSourcePromo EntitlementSource = "promo"
+)
+
+// Entitlement gives a user the right to play a premium pack's categories
+type Entitlement struct {
+	UserID    string            `json:"user_id"`
+	PackID    string            `json:"pack_id"`
+	Source    EntitlementSource `json:"source"`
+	GrantedBy string            `json:"granted_by,omitempty"` // Admin who granted it, user who gifted it or promo code redeemed
+	GrantedAt time.Time         `json:"granted_at"`
+}
+
+// PremiumPackPolicy is who in a game must own the premium packs its
+// categories belong to
+type PremiumPackPolicy string
+
+const (
+	// PremiumPackPolicyHost lets anyone join games whose host owns the packs
+	PremiumPackPolicyHost PremiumPackPolicy = "host"
+
+	// PremiumPackPolicyAll requires every player to own the packs, so players
+	// without an account cannot join games using them
+	PremiumPackPolicyAll PremiumPackPolicy = "all"
+)
+
+// EntitlementAction is a change to a user's entitlements recorded in the
+// entitlement log
+type EntitlementAction string
+
+const (
+	EntitlementActionGrant  EntitlementAction = "grant"
+	EntitlementActionRevoke EntitlementAction = "revoke"
+	EntitlementActionGift   EntitlementAction = "gift"
+)
+
+// EntitlementLogEntry records a change to a user's entitlements
+type EntitlementLogEntry struct {
+	ID         string            `json:"id"`
+	Action     EntitlementAction `json:"action"`
+	UserID     string            `json:"user_id"`                // User whose entitlements changed, the recipient of gifts
+	FromUserID string            `json:"from_user_id,omitempty"` // User who gave up a gifted pack
+	PackID     string            `json:"pack_id"`
+	Actor      string            `json:"actor"` // Admin or user who made the change
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// EntitlementRepository defines the interface for premium packs and the
+// entitlements to them. Every change to an entitlement is saved along with
+// its entitlement log entry.
+type EntitlementRepository interface {
+	// SavePack creates or replaces a premium pack
+	SavePack(ctx context.Context, pack *PremiumPack) error
+
+	// GetPack retrieves a premium pack by ID
+	GetPack(ctx context.Context, id string) (*PremiumPack, error)
+
+	// ListPacks retrieves every premium pack, ordered by ID
+	ListPacks(ctx context.Context) ([]*PremiumPack, error)
+
+	// Grant gives a user a premium pack. It returns ErrEntitlementExists if
+	// the user already owns it.
+	Grant(ctx context.Context, entitlement *Entitlement, entry *EntitlementLogEntry) error
+
+	// Revoke takes a premium pack from a user. It returns
+	// ErrEntitlementNotFound if the user does not own it.
+	Revoke(ctx context.Context, userID string, packID string, entry *EntitlementLogEntry) error
+
+	// Transfer moves a user's entitlement to the user of the new
+	// entitlement. It returns ErrEntitlementNotFound if fromUserID does not
+	// own the pack, ErrEntitlementExists if the recipient already does, and
+	// ErrGiftLimitReached if fromUserID has gifted maxGifts packs since a
+	// time. Transfers from the same user are counted one at a time.
+	Transfer(ctx context.Context, fromUserID string, entitlement *Entitlement, entry *EntitlementLogEntry, maxGifts int, since time.Time) error
+
+	// Get retrieves a user's entitlement to a premium pack
+	Get(ctx context.Context, userID string, packID string) (*Entitlement, error)
+
+	// ListByUser retrieves the entitlements of a user, ordered by pack ID
+	ListByUser(ctx context.Context, userID string) ([]*Entitlement, error)
+
+	// ListLog retrieves a user's most recent entitlement log entries, newest
+	// first, including the packs they gifted
+	ListLog(ctx context.Context, userID string, limit int) ([]*EntitlementLogEntry, error)
+}
@@ -0,0 +1,643 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Event errors
+var (
+	ErrEventConflict      = errors.New("game was modified concurrently")
+	ErrUnknownEventType   = errors.New("unknown event type")
+	ErrEventOutOfSequence = errors.New("event is out of sequence")
+)
+
+// GameEventType identifies what happened in a game
+type GameEventType string
+
+const (
+	EventGameCreated         GameEventType = "game_created"
+	EventPlayerJoined        GameEventType = "player_joined"
+	EventGameStarted         GameEventType = "game_started"
+	EventTurnStarted         GameEventType = "turn_started"
+	EventCategorySelected    GameEventType = "category_selected"
+	EventAnswerSubmitted     GameEventType = "answer_submitted"
+	EventAnswerReplaced      GameEventType = "answer_replaced"
+	EventAnswerModerated     GameEventType = "answer_moderated"
+	EventFillersAdded        GameEventType = "fillers_added"
+	EventVotingStarted       GameEventType = "voting_started"
+	EventVoteCast            GameEventType = "vote_cast"
+	EventRoundEnded          GameEventType = "round_ended"
+	EventGameEnded           GameEventType = "game_ended"
+	EventPlayerDisconnected  GameEventType = "player_disconnected"
+	EventPlayerReconnected   GameEventType = "player_reconnected"
+	EventExpiryWarned        GameEventType = "expiry_warned"
+	EventGameExtended        GameEventType = "game_extended"
+	EventPlayerRemoved       GameEventType = "player_removed"
+	EventPlayerReady         GameEventType = "player_ready"
+	EventCountdownStarted    GameEventType = "countdown_started"
+	EventCountdownCanceled   GameEventType = "countdown_canceled"
+	EventRoundStarted        GameEventType = "round_started"
+	EventPlayerEliminated    GameEventType = "player_eliminated"
+	EventPlayerMuted         GameEventType = "player_muted"
+	EventAudienceVoted       GameEventType = "audience_voted"
+	EventIntermissionStarted GameEventType = "intermission_started"
+	EventTimerExtended       GameEventType = "timer_extended"
+	EventLaggardsReminded    GameEventType = "laggards_reminded"
+	EventAnswerLiked         GameEventType = "answer_liked"
+	EventLikesTallied        GameEventType = "likes_tallied"
+)
+
+// GameEvent is an immutable record of a change to a game. A game's state is
+// the result of applying its events in sequence order.
+type GameEvent struct {
+	GameID     string          `json:"game_id"`
+	Sequence   int             `json:"sequence"` // 1-based position in the game's stream
+	Type       GameEventType   `json:"type"`
+	Payload    json.RawMessage `json:"payload"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// Event payloads. Anything derived from randomness or external lookups, such
+// as IDs, questions and filler answers, is recorded so replay is deterministic.
+type (
+	GameCreatedPayload struct {
+		ID           string        `json:"id"`
+		Code         string        `json:"code"`
+		Host         Player        `json:"host"`
+		Settings     *GameSettings `json:"settings"`
+		PartyID      string        `json:"party_id,omitempty"`
+		Origin       *GameOrigin   `json:"origin,omitempty"`
+		PackVersion  int           `json:"pack_version,omitempty"`
+		PremiumPacks []string      `json:"premium_packs,omitempty"`
+		TenantID     string        `json:"tenant_id,omitempty"`
+	}
+
+	PlayerJoinedPayload struct {
+		Player Player `json:"player"`
+	}
+
+	GameStartedPayload struct{}
+
+	RoundStartedPayload struct{}
+
+	IntermissionStartedPayload struct {
+		Timer        *Timer `json:"timer"`
+		NextPlayerID string `json:"next_player_id,omitempty"`
+	}
+
+	TimerExtendedPayload struct {
+		Type    TimerType `json:"type"`    // Timer of the phase extended
+		Seconds int       `json:"seconds"` // Time added
+	}
+
+	AnswerModeratedPayload struct {
+		PlayerID string `json:"player_id"`
+		Strikes  int    `json:"strikes"` // The player's moderated answers this game, this one included
+	}
+
+	AnswerLikedPayload struct {
+		PlayerID string `json:"player_id"`
+		AnswerID string `json:"answer_id"`
+	}
+
+	LikesTalliedPayload struct {
+		Bonuses map[string]int `json:"bonuses,omitempty"` // Bonus points per player ID for writing the most-liked answer
+	}
+
+	LaggardsRemindedPayload struct {
+		Phase     TimerType `json:"phase"`
+		PlayerIDs []string  `json:"player_ids"` // Players yet to play their part
+	}
+
+	TurnStartedPayload struct {
+		PlayerID string `json:"player_id"`
+		Timer    *Timer `json:"timer"`
+	}
+
+	CategorySelectedPayload struct {
+		Category      string `json:"category"`
+		QuestionID    string `json:"question_id"`
+		Question      string `json:"question"`
+		CorrectAnswer string `json:"correct_answer"`
+		Timer         *Timer `json:"timer"`
+	}
+
+	AnswerSubmittedPayload struct {
+		Answer Answer `json:"answer"`
+	}
+
+	AnswerReplacedPayload struct {
+		Answer Answer `json:"answer"` // Takes the place of the answer with the same ID
+	}
+
+	FillersAddedPayload struct {
+		Answers []Answer `json:"answers"`
+	}
+
+	VotingStartedPayload struct {
+		Timer           *Timer         `json:"timer"`
+		CorrectAnswerID string         `json:"correct_answer_id,omitempty"`
+		Options         []AnswerOption `json:"options,omitempty"`
+	}
+
+	VoteCastPayload struct {
+		PlayerID string `json:"player_id"`
+		AnswerID string `json:"answer_id"`
+	}
+
+	AudienceVotedPayload struct {
+		Votes map[string]int `json:"votes"` // Votes to add to each option by ID
+	}
+
+	RoundEndedPayload struct {
+		Points       map[string]int `json:"points,omitempty"`        // Points awarded per player ID
+		SpeedBonuses map[string]int `json:"speed_bonuses,omitempty"` // Speed bonuses per player ID, included in Points
+	}
+
+	GameEndedPayload struct {
+		Highlights *Highlights `json:"highlights,omitempty"` // Compiled from the completed rounds, if any
+	}
+
+	PlayerConnectionPayload struct {
+		PlayerID string `json:"player_id"`
+	}
+
+	PlayerEliminatedPayload struct {
+		PlayerID string `json:"player_id"`
+		Round    int    `json:"round"`
+	}
+
+	PlayerReadyPayload struct {
+		PlayerID string `json:"player_id"`
+		Ready    bool   `json:"ready"`
+	}
+
+	PlayerMutedPayload struct {
+		PlayerID string `json:"player_id"`
+		Muted    bool   `json:"muted"`
+	}
+
+	ExpiryWarnedPayload struct {
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	GameExtendedPayload struct{}
+
+	CountdownStartedPayload struct {
+		Timer *Timer `json:"timer"`
+	}
+
+	CountdownCanceledPayload struct{}
+)
+
+// NewGameEvent builds an event with an encoded payload. The sequence is
+// assigned when the event is applied.
+func NewGameEvent(gameID string, eventType GameEventType, payload any, occurredAt time.Time) (*GameEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+	return &GameEvent{
+		GameID:     gameID,
+		Type:       eventType,
+		Payload:    data,
+		OccurredAt: occurredAt,
+	}, nil
+}
+
+// GameEventRepository persists game event streams
+type GameEventRepository interface {
+	// Append stores events, which must continue the game's stream without
+	// gaps. ErrEventConflict is returned if a sequence number is already taken.
+	Append(ctx context.Context, events ...*GameEvent) error
+
+	// ListByGame retrieves a game's events with a sequence greater than afterSequence
+	ListByGame(ctx context.Context, gameID string, afterSequence int) ([]*GameEvent, error)
+}
+
+// ReplayGame rebuilds a game's state from its full event stream
+func ReplayGame(events []*GameEvent) (*Game, error) {
+	if len(events) == 0 || events[0].Type != EventGameCreated {
+		return nil, fmt.Errorf("%w: stream must start with %s", ErrEventOutOfSequence, EventGameCreated)
+	}
+
+	game := &Game{}
+	for _, event := range events {
+		if event.Sequence != game.Version+1 {
+			return nil, fmt.Errorf("%w: expected %d, got %d", ErrEventOutOfSequence, game.Version+1, event.Sequence)
+		}
+		if err := game.Apply(event); err != nil {
+			return nil, err
+		}
+	}
+	return game, nil
+}
+
+// Apply advances the game's state by a single event. It performs no
+// validation of the command that produced the event; that is the service's job.
+func (g *Game) Apply(event *GameEvent) error {
+	at := event.OccurredAt
+
+	switch event.Type {
+	case EventGameCreated:
+		var p GameCreatedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		*g = Game{
+			ID:           p.ID,
+			Code:         p.Code,
+			Status:       GameStatusWaiting,
+			Players:      []Player{p.Host},
+			Rounds:       []Round{},
+			Settings:     p.Settings,
+			CreatedAt:    at,
+			HostID:       p.Host.ID,
+			PartyID:      p.PartyID,
+			Origin:       p.Origin,
+			PackVersion:  p.PackVersion,
+			PremiumPacks: p.PremiumPacks,
+			TenantID:     p.TenantID,
+		}
+
+	case EventPlayerJoined:
+		var p PlayerJoinedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		g.Players = append(g.Players, p.Player)
+
+	case EventGameStarted:
+		g.Status = GameStatusPlaying
+		g.Countdown = nil
+		g.startRound(at)
+
+	case EventRoundStarted:
+		if _, err := g.currentRound(event); err != nil {
+			return err
+		}
+		g.Intermission = nil
+		g.startRound(at)
+
+	case EventIntermissionStarted:
+		var p IntermissionStartedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		g.Intermission = &Intermission{Timer: p.Timer, NextPlayerID: p.NextPlayerID}
+
+	case EventTurnStarted:
+		var p TurnStartedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		round, err := g.currentRound(event)
+		if err != nil {
+			return err
+		}
+		round.CurrentTurn = &Turn{
+			PlayerID:  p.PlayerID,
+			StartTime: at,
+			Status:    TurnStatusActive,
+			Timer:     p.Timer,
+		}
+
+	case EventCategorySelected:
+		var p CategorySelectedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		round, err := g.currentRound(event)
+		if err != nil {
+			return err
+		}
+		round.Category = p.Category
+		round.Question = p.Question
+		round.QuestionID = p.QuestionID
+		round.AnswerPool.CorrectAnswer = p.CorrectAnswer
+		round.Timer = p.Timer
+		round.AskedAt = at
+
+	case EventAnswerSubmitted:
+		var p AnswerSubmittedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		round, err := g.currentRound(event)
+		if err != nil {
+			return err
+		}
+		round.AnswerPool.FakeAnswers = append(round.AnswerPool.FakeAnswers, p.Answer)
+
+	case EventAnswerReplaced:
+		var p AnswerReplacedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		round, err := g.currentRound(event)
+		if err != nil {
+			return err
+		}
+		for i := range round.AnswerPool.FakeAnswers {
+			if round.AnswerPool.FakeAnswers[i].ID == p.Answer.ID {
+				round.AnswerPool.FakeAnswers[i] = p.Answer
+				break
+			}
+		}
+
+	case EventAnswerModerated:
+		var p AnswerModeratedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		round, err := g.currentRound(event)
+		if err != nil {
+			return err
+		}
+		// The answer is withheld, leaving a filler to take its place
+		round.AnswerPool.FakeAnswers = slices.DeleteFunc(round.AnswerPool.FakeAnswers, func(a Answer) bool {
+			return a.PlayerID == p.PlayerID
+		})
+		if !slices.Contains(round.ModeratedPlayers, p.PlayerID) {
+			round.ModeratedPlayers = append(round.ModeratedPlayers, p.PlayerID)
+		}
+		for i := range g.Players {
+			if g.Players[i].ID == p.PlayerID {
+				g.Players[i].AnswerStrikes = p.Strikes
+				break
+			}
+		}
+
+	case EventFillersAdded:
+		var p FillersAddedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		round, err := g.currentRound(event)
+		if err != nil {
+			return err
+		}
+		round.AnswerPool.FillerAnswers = append(round.AnswerPool.FillerAnswers, p.Answers...)
+
+	case EventVotingStarted:
+		var p VotingStartedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		round, err := g.currentRound(event)
+		if err != nil {
+			return err
+		}
+		round.Status = RoundStatusVoting
+		round.Timer = p.Timer
+		round.AnswerPool.CorrectAnswerID = p.CorrectAnswerID
+		round.AnswerPool.VotingPool = p.Options
+
+	case EventVoteCast:
+		var p VoteCastPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		round, err := g.currentRound(event)
+		if err != nil {
+			return err
+		}
+		if round.VotedAt == nil {
+			round.VotedAt = make(map[string]time.Time)
+		}
+		round.VotedAt[p.PlayerID] = at
+		pool := &round.AnswerPool
+		if p.AnswerID != "" && p.AnswerID == pool.CorrectAnswerID {
+			pool.CorrectVotes = append(pool.CorrectVotes, p.PlayerID)
+			break
+		}
+		for _, answers := range [][]Answer{pool.FakeAnswers, pool.FillerAnswers} {
+			if i := slices.IndexFunc(answers, func(a Answer) bool { return a.ID == p.AnswerID }); i >= 0 {
+				answers[i].Votes = append(answers[i].Votes, p.PlayerID)
+				break
+			}
+		}
+
+	case EventAudienceVoted:
+		var p AudienceVotedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		round, err := g.currentRound(event)
+		if err != nil {
+			return err
+		}
+		if round.AudienceVotes == nil {
+			round.AudienceVotes = make(map[string]int, len(p.Votes))
+		}
+		for optionID, votes := range p.Votes {
+			round.AudienceVotes[optionID] += votes
+		}
+
+	case EventRoundEnded:
+		var p RoundEndedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		round, err := g.currentRound(event)
+		if err != nil {
+			return err
+		}
+		for i := range g.Players {
+			g.Players[i].Score += p.Points[g.Players[i].ID]
+		}
+		round.Points = p.Points
+		round.SpeedBonuses = p.SpeedBonuses
+		round.Status = RoundStatusCompleted
+		round.EndTime = at
+
+	case EventAnswerLiked:
+		var p AnswerLikedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		round, err := g.currentRound(event)
+		if err != nil {
+			return err
+		}
+		if round.Likes == nil {
+			round.Likes = make(map[string]string)
+		}
+		round.Likes[p.PlayerID] = p.AnswerID
+
+	case EventLikesTallied:
+		var p LikesTalliedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		round, err := g.currentRound(event)
+		if err != nil {
+			return err
+		}
+		for i := range g.Players {
+			g.Players[i].Score += p.Bonuses[g.Players[i].ID]
+		}
+		round.LikeBonuses = p.Bonuses
+
+	case EventLaggardsReminded:
+		var p LaggardsRemindedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		round, err := g.currentRound(event)
+		if err != nil {
+			return err
+		}
+		round.RemindedPhase = p.Phase
+
+	case EventTimerExtended:
+		var p TimerExtendedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		timer := g.CurrentTimer()
+		if timer == nil || timer.Type != p.Type {
+			return fmt.Errorf("%w: %s without a running %s timer", ErrEventOutOfSequence, event.Type, p.Type)
+		}
+		timer.Duration += p.Seconds
+		timer.EndTime = timer.EndTime.Add(time.Duration(p.Seconds) * time.Second)
+
+	case EventGameEnded:
+		var p GameEndedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		g.Status = GameStatusEnded
+		g.Intermission = nil
+		g.Highlights = p.Highlights
+
+	case EventPlayerDisconnected, EventPlayerReconnected:
+		var p PlayerConnectionPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		for i := range g.Players {
+			if g.Players[i].ID == p.PlayerID {
+				g.Players[i].IsConnected = event.Type == EventPlayerReconnected
+				g.Players[i].LastSeen = at
+				if event.Type == EventPlayerReconnected {
+					g.Players[i].Removed = false
+				}
+				break
+			}
+		}
+
+	case EventPlayerRemoved:
+		var p PlayerConnectionPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		for i := range g.Players {
+			if g.Players[i].ID == p.PlayerID {
+				g.Players[i].Removed = true
+				break
+			}
+		}
+
+	case EventPlayerEliminated:
+		var p PlayerEliminatedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		for i := range g.Players {
+			if g.Players[i].ID == p.PlayerID {
+				g.Players[i].EliminatedInRound = p.Round
+				break
+			}
+		}
+
+	case EventPlayerReady:
+		var p PlayerReadyPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		for i := range g.Players {
+			if g.Players[i].ID == p.PlayerID {
+				g.Players[i].Ready = p.Ready
+				break
+			}
+		}
+
+	case EventPlayerMuted:
+		var p PlayerMutedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		for i := range g.Players {
+			if g.Players[i].ID == p.PlayerID {
+				g.Players[i].Muted = p.Muted
+				break
+			}
+		}
+
+	case EventCountdownStarted:
+		var p CountdownStartedPayload
+		if err := decodePayload(event, &p); err != nil {
+			return err
+		}
+		g.Countdown = p.Timer
+
+	case EventCountdownCanceled:
+		g.Countdown = nil
+
+	case EventExpiryWarned:
+		g.ExpiryWarnedAt = &at
+
+	case EventGameExtended:
+		g.ExpiryWarnedAt = nil
+
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownEventType, event.Type)
+	}
+
+	g.Version = event.Sequence
+	g.UpdatedAt = at
+	// Connection changes, expiry warnings and reminders alone do not keep a
+	// game alive
+	switch event.Type {
+	case EventPlayerDisconnected, EventPlayerReconnected, EventPlayerRemoved, EventExpiryWarned, EventLaggardsReminded:
+	default:
+		g.LastActivity = at
+	}
+	return nil
+}
+
+// startRound adds a new round waiting for its question
+func (g *Game) startRound(at time.Time) {
+	g.Rounds = append(g.Rounds, Round{
+		Number:    len(g.Rounds) + 1,
+		Status:    RoundStatusWaiting,
+		StartTime: at,
+		AnswerPool: AnswerPool{
+			FakeAnswers:   make([]Answer, 0),
+			FillerAnswers: make([]Answer, 0),
+		},
+	})
+}
+
+// currentRound returns the latest round, which events other than lifecycle
+// events apply to
+func (g *Game) currentRound(event *GameEvent) (*Round, error) {
+	if len(g.Rounds) == 0 {
+		return nil, fmt.Errorf("%w: %s before the game started", ErrGameNotStarted, event.Type)
+	}
+	return &g.Rounds[len(g.Rounds)-1], nil
+}
+
+// decodePayload unmarshals an event's payload
+func decodePayload(event *GameEvent, payload any) error {
+	if err := json.Unmarshal(event.Payload, payload); err != nil {
+		return fmt.Errorf("failed to decode %s payload: %w", event.Type, err)
+	}
+	return nil
+}
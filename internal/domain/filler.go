@@ -0,0 +1,11 @@
+package domain
+
+import "context"
+
+// FillerGenerator writes filler answers with an outside language model, for
+// rounds whose question does not come with enough fillers of its own
+type FillerGenerator interface {
+	// Generate returns up to n plausible but wrong answers to a question,
+	// written in a game's question language
+	Generate(ctx context.Context, question string, correctAnswer string, category string, language string, n int) ([]string, error)
+}
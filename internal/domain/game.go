@@ -3,15 +3,170 @@ package domain
 import (
 	"context"
 	"errors"
+	"math"
+	"slices"
 	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
 )
 
 // GameSettings defines the configuration for a game
 type GameSettings struct {
+	Mode               GameMode   `json:"mode,omitempty"`      // How rounds are played, classic when empty
 	Rounds             int        `json:"rounds"`              // Number of rounds in the game
 	TimeLimits         TimeLimits `json:"time_limits"`         // Time limits for different phases
 	SelectedCategories []string   `json:"selected_categories"` // Categories to include in the game
 	MaxPlayers         int        `json:"max_players"`         // Maximum number of players
+	MinPlayers         int        `json:"min_players"`         // Connected players needed to start
+	RequireReady       bool       `json:"require_ready"`       // Whether every connected player must be ready before the host can start
+
+	// Listed games show in the lobby browser and are matched with players
+	// looking for a game; the rest are only joined by their code
+	Listed bool `json:"listed,omitempty"`
+
+	// TurnPlayerIsLiar has the player who chose a round's category play it
+	// as the liar: they are never shown its correct answer and cannot vote
+	TurnPlayerIsLiar bool `json:"turn_player_is_liar,omitempty"`
+
+	// The game starts itself after a countdown once AutoStartPlayers players
+	// are connected, or once every player is ready if AutoStartWhenReady is
+	// set. Zero players and false leave starting to the host.
+	AutoStartPlayers    int           `json:"auto_start_players,omitempty"`
+	AutoStartWhenReady  bool          `json:"auto_start_when_ready,omitempty"`
+	StartCountdown      int           `json:"start_countdown,omitempty"`      // Length of the countdown (seconds)
+	EliminationInterval int           `json:"elimination_interval,omitempty"` // Rounds between eliminations in elimination mode
+	Language            string        `json:"language,omitempty"`             // Language of the question pool, fillers and answer matching
+	MaxRating           ContentRating `json:"max_rating,omitempty"`           // Edgiest content rating of the game's questions, any when empty
+
+	// AnswerQuorum and VoteQuorum are the fractions of active players whose
+	// answers and votes end a phase before its timer runs out
+	AnswerQuorum float64 `json:"answer_quorum"`
+	VoteQuorum   float64 `json:"vote_quorum"`
+
+	// SpeedBonus is the most bonus points a correct vote earns, for a vote
+	// cast the moment voting opens. The bonus decays with the time left on
+	// the voting timer, down to nothing as it runs out. Zero turns it off.
+	SpeedBonus int `json:"speed_bonus,omitempty"`
+}
+
+// GameMode is a variant of how a game's rounds are played
+type GameMode string
+
+const (
+	GameModeClassic GameMode = "classic"
+
+	// GameModeLightning skips category selection, drawing each round's
+	// category at random, halves every round timer and plays LightningRounds
+	// rounds
+	GameModeLightning GameMode = "lightning"
+
+	// GameModeElimination plays on regardless of the round count, eliminating
+	// the lowest scorer every EliminationInterval rounds until two players
+	// remain, who then play a final duel of as many rounds
+	GameModeElimination GameMode = "elimination"
+)
+
+// DefaultEliminationInterval is the number of rounds between eliminations
+const DefaultEliminationInterval = 3
+
+// IsElimination reports whether the game is played in elimination mode
+func (s *GameSettings) IsElimination() bool {
+	return s.Mode == GameModeElimination
+}
+
+// EliminationRounds returns the number of rounds between eliminations
+func (s *GameSettings) EliminationRounds() int {
+	if s.EliminationInterval <= 0 {
+		return DefaultEliminationInterval
+	}
+	return s.EliminationInterval
+}
+
+// LightningRounds is the number of rounds in a lightning game
+const LightningRounds = 5
+
+// IsLightning reports whether the game is played in lightning mode
+func (s *GameSettings) IsLightning() bool {
+	return s.Mode == GameModeLightning
+}
+
+// RoundCount returns the number of rounds the game is played for
+func (s *GameSettings) RoundCount() int {
+	if s.IsLightning() {
+		return LightningRounds
+	}
+	return s.Rounds
+}
+
+// PhaseSeconds returns the length of a round timer configured as seconds,
+// which lightning mode halves
+func (s *GameSettings) PhaseSeconds(seconds int) int {
+	if s.IsLightning() {
+		return max(seconds/2, 1)
+	}
+	return seconds
+}
+
+// MaxSpeedBonus is the largest speed bonus a game can be configured with
+const MaxSpeedBonus = 10
+
+// DefaultMinPlayers is the fewest players a game can be played with
+const DefaultMinPlayers = 2
+
+// DefaultStartCountdown is the length of the start countdown in seconds
+const DefaultStartCountdown = 10
+
+// AutoStarts reports whether the game starts itself once its lobby fills up
+// or every player is ready
+func (s *GameSettings) AutoStarts() bool {
+	return s.AutoStartPlayers > 0 || s.AutoStartWhenReady
+}
+
+// CountdownSeconds returns the length of the start countdown
+func (s *GameSettings) CountdownSeconds() int {
+	if s.StartCountdown <= 0 {
+		return DefaultStartCountdown
+	}
+	return s.StartCountdown
+}
+
+// MinimumPlayers returns the number of connected players needed to start.
+// Games created before it was configurable need two.
+func (s *GameSettings) MinimumPlayers() int {
+	if s.MinPlayers == 0 {
+		return DefaultMinPlayers
+	}
+	return s.MinPlayers
+}
+
+// AnswersNeeded returns how many of the given number of answering players
+// must answer before voting starts
+func (s *GameSettings) AnswersNeeded(players int) int {
+	return quorum(s.AnswerQuorum, players)
+}
+
+// VotesNeeded returns how many of the given number of voting players must
+// vote before the round ends
+func (s *GameSettings) VotesNeeded(players int) int {
+	return quorum(s.VoteQuorum, players)
+}
+
+// quorum returns the number of players making up a fraction of them. An
+// unset fraction, as in games created before quorums, requires everyone.
+func quorum(fraction float64, players int) int {
+	if fraction <= 0 || fraction > 1 {
+		return players
+	}
+	return int(math.Ceil(fraction * float64(players)))
+}
+
+// QuestionLanguage returns the language of the game's questions. Games
+// created before languages were introduced play in the default language.
+func (s *GameSettings) QuestionLanguage() string {
+	if s.Language == "" {
+		return DefaultQuestionLanguage
+	}
+	return s.Language
 }
 
 // TimeLimits defines the time limits for different game phases
@@ -19,8 +174,16 @@ type TimeLimits struct {
 	CategorySelection int `json:"category_selection"` // Time for selecting category (seconds)
 	AnswerWriting     int `json:"answer_writing"`     // Time for writing answers (seconds)
 	Voting            int `json:"voting"`             // Time for voting (seconds)
+
+	// Intermission is the pause between a round's reveal and the next round,
+	// during which the recap is shown and the next turn is announced
+	// (seconds). Zero starts the next round straight away.
+	Intermission int `json:"intermission,omitempty"`
 }
 
+// DefaultIntermission is the length of the pause between rounds in seconds
+const DefaultIntermission = 8
+
 // DefaultGameSettings returns the default game settings
 func DefaultGameSettings() *GameSettings {
 	return &GameSettings{
@@ -29,8 +192,13 @@ func DefaultGameSettings() *GameSettings {
 			CategorySelection: 30,
 			AnswerWriting:     30,
 			Voting:            15,
+			Intermission:      DefaultIntermission,
 		},
-		MaxPlayers: 8,
+		MaxPlayers:   8,
+		MinPlayers:   DefaultMinPlayers,
+		Language:     DefaultQuestionLanguage,
+		AnswerQuorum: 1,
+		VoteQuorum:   1,
 	}
 }
 
@@ -44,8 +212,168 @@ type Game struct {
 	Settings     *GameSettings `json:"settings"` // Game settings
 	CreatedAt    time.Time     `json:"created_at"`
 	UpdatedAt    time.Time     `json:"updated_at"`
-	LastActivity time.Time     `json:"last_activity"` // Added for cleanup
-	HostID       string        `json:"host_id"`       // ID of the host player
+	LastActivity time.Time     `json:"last_activity"`       // Added for cleanup
+	HostID       string        `json:"host_id"`             // ID of the host player
+	PartyID      string        `json:"party_id,omitempty"`  // Party the game is played in, if any
+	Origin       *GameOrigin   `json:"origin,omitempty"`    // Where the game was created, if known
+	TenantID     string        `json:"tenant_id,omitempty"` // Organization the game is private to, if any
+	Version      int           `json:"version"`             // Sequence of the last event applied
+
+	// PackVersion is the version of its language's question pack the game
+	// was created with. Questions are only drawn from it and earlier
+	// releases, so publishing a pack never changes a game under way.
+	PackVersion int `json:"pack_version,omitempty"`
+
+	// PremiumPacks are the premium packs the game's categories belong to,
+	// which its host owned when creating it
+	PremiumPacks []string `json:"premium_packs,omitempty"`
+
+	// Countdown is the running countdown to the game starting itself
+	Countdown *Timer `json:"countdown,omitempty"`
+
+	// Intermission is the running pause before the next round starts itself
+	Intermission *Intermission `json:"intermission,omitempty"`
+
+	// ExpiryWarnedAt is when players were last warned that the game is about
+	// to expire from inactivity. Activity since then makes the warning stale.
+	ExpiryWarnedAt *time.Time `json:"expiry_warned_at,omitempty"`
+
+	// Highlights are the standout moments of the game, once it has ended
+	Highlights *Highlights `json:"highlights,omitempty"`
+}
+
+// GameOrigin records the deployment a game was created on, so clients and
+// other instances can tell how close it is hosted to a player
+type GameOrigin struct {
+	Region   string `json:"region,omitempty"`   // Region of the deployment, such as eu-west-1
+	Instance string `json:"instance,omitempty"` // Instance within the region
+}
+
+// ExpiresAt returns when the game expires if it stays inactive for timeout
+func (g *Game) ExpiresAt(timeout time.Duration) time.Time {
+	return g.LastActivity.Add(timeout)
+}
+
+// ActivePlayers returns the players that have not been removed, whose
+// answers and votes count towards a round's quorums. Players who lose
+// connection stay active until their reconnect grace period runs out.
+func (g *Game) ActivePlayers() []Player {
+	active := make([]Player, 0, len(g.Players))
+	for _, p := range g.Players {
+		if !p.Removed {
+			active = append(active, p)
+		}
+	}
+	return active
+}
+
+// Contestants returns the active players that have not been eliminated, who
+// answer questions and score points
+func (g *Game) Contestants() []Player {
+	contestants := make([]Player, 0, len(g.Players))
+	for _, p := range g.ActivePlayers() {
+		if p.EliminatedInRound == 0 {
+			contestants = append(contestants, p)
+		}
+	}
+	return contestants
+}
+
+// CurrentTimer returns the timer of the phase a game in play is in, or nil
+// if the phase is not timed
+func (g *Game) CurrentTimer() *Timer {
+	if g.Status != GameStatusPlaying || len(g.Rounds) == 0 {
+		return nil
+	}
+	if g.Intermission != nil {
+		return g.Intermission.Timer
+	}
+
+	round := &g.Rounds[len(g.Rounds)-1]
+	switch {
+	case round.Status == RoundStatusVoting, round.Status == RoundStatusWaiting && round.QuestionID != "":
+		return round.Timer
+	case round.Status == RoundStatusWaiting && round.CurrentTurn != nil && round.CurrentTurn.Status == TurnStatusActive:
+		return round.CurrentTurn.Timer
+	}
+	return nil
+}
+
+// Liar returns the ID of the player who plays a round as the liar, if the
+// game has one: the player whose turn chose the round's category
+func (g *Game) Liar(round *Round) string {
+	if !g.Settings.TurnPlayerIsLiar || round.CurrentTurn == nil {
+		return ""
+	}
+	return round.CurrentTurn.PlayerID
+}
+
+// PlayerView returns the game as a player may see it. Until a round is
+// revealed, its pool only shows who has answered and the anonymous voting
+// pool, withholding the correct answer and anything it could be told apart
+// by. Revealed rounds with a liar withhold the correct answer from the liar,
+// or from every player when the viewer is unknown, as a broadcast reaches the
+// liar too. The game itself is returned when nothing needs withholding.
+func (g *Game) PlayerView(viewerID string) *Game {
+	var view *Game
+	for i := range g.Rounds {
+		round := &g.Rounds[i]
+		pool, withheld := round.AnswerPool.forPlayers(round.Status == RoundStatusCompleted, g.hidesAnswerFrom(round, viewerID))
+		if !withheld {
+			continue
+		}
+		if view == nil {
+			clone := *g
+			clone.Rounds = slices.Clone(g.Rounds)
+			view = &clone
+		}
+		view.Rounds[i].AnswerPool = pool
+	}
+	if view == nil {
+		return g
+	}
+	return view
+}
+
+// hidesAnswerFrom reports whether a revealed round's correct answer is kept
+// from a viewer, who is unknown when empty
+func (g *Game) hidesAnswerFrom(round *Round, viewerID string) bool {
+	liar := g.Liar(round)
+	return liar != "" && (viewerID == "" || viewerID == liar)
+}
+
+// forPlayers returns the pool as players may see it, and whether anything
+// was withheld from it
+func (p AnswerPool) forPlayers(revealed bool, hideAnswer bool) (AnswerPool, bool) {
+	if !revealed {
+		if p.CorrectAnswer == "" && len(p.FakeAnswers) == 0 {
+			return p, false
+		}
+		answered := make([]Answer, len(p.FakeAnswers))
+		for i, answer := range p.FakeAnswers {
+			answered[i] = Answer{PlayerID: answer.PlayerID, CreatedAt: answer.CreatedAt}
+		}
+		return AnswerPool{FakeAnswers: answered, VotingPool: p.VotingPool}, true
+	}
+
+	if !hideAnswer || p.CorrectAnswer == "" {
+		return p, false
+	}
+	p.CorrectAnswer = ""
+	p.VotingPool = slices.DeleteFunc(slices.Clone(p.VotingPool), func(o AnswerOption) bool { return o.ID == p.CorrectAnswerID })
+	p.CorrectAnswerID = ""
+	return p, true
+}
+
+// ConnectedPlayers returns the active players that are connected
+func (g *Game) ConnectedPlayers() []Player {
+	connected := make([]Player, 0, len(g.Players))
+	for _, p := range g.ActivePlayers() {
+		if p.IsConnected {
+			connected = append(connected, p)
+		}
+	}
+	return connected
 }
 
 // GameStatus represents the current status of a game
@@ -65,20 +393,100 @@ type Player struct {
 	IsConnected bool      `json:"is_connected"`
 	LastSeen    time.Time `json:"last_seen"`
 	IsActive    bool      `json:"is_active"`
+	Slot        int       `json:"slot"`  // Seat in the game, assigned by the server in join order
+	Color       string    `json:"color"` // Color of the player's slot, shown with their name and avatar
+	Ready       bool      `json:"ready"` // Whether the player is ready for the game to start
+
+	// Removed is set once a player has been disconnected for longer than the
+	// reconnect grace period. Removed players keep their seat and score but
+	// are no longer waited for; reconnecting restores them.
+	Removed bool `json:"removed,omitempty"`
+
+	// EliminatedInRound is the round after which the player was eliminated
+	// in elimination mode, 0 while they are still in contention. Eliminated
+	// players stay on as audience voters.
+	EliminatedInRound int `json:"eliminated_in_round,omitempty"`
+
+	// Muted is set while the host has muted the player. Muted players keep
+	// playing, but their chat and reactions are not relayed to the game.
+	Muted bool `json:"muted,omitempty"`
+
+	// AnswerStrikes counts the player's answers withheld by moderation this
+	// game; repeat offenders are muted
+	AnswerStrikes int `json:"answer_strikes,omitempty"`
+
+	// Cosmetics are the cosmetics the player wore when they joined, which
+	// every client renders with their name and avatar
+	Cosmetics *EquippedCosmetics `json:"cosmetics,omitempty"`
 }
 
 // Round represents a single round in the game
 type Round struct {
-	Number      int         `json:"number"`
-	Category    string      `json:"category"`
-	Question    string      `json:"question"`
-	QuestionID  string      `json:"question_id"`
-	Status      RoundStatus `json:"status"`
-	StartTime   time.Time   `json:"start_time"`
-	EndTime     time.Time   `json:"end_time"`
-	CurrentTurn *Turn       `json:"current_turn"`
-	AnswerPool  AnswerPool  `json:"answer_pool"`
-	Timer       *Timer      `json:"timer,omitempty"`
+	Number      int            `json:"number"`
+	Category    string         `json:"category"`
+	Question    string         `json:"question"`
+	QuestionID  string         `json:"question_id"`
+	Status      RoundStatus    `json:"status"`
+	StartTime   time.Time      `json:"start_time"`
+	EndTime     time.Time      `json:"end_time"`
+	AskedAt     time.Time      `json:"asked_at,omitempty"` // When the round's question was asked
+	CurrentTurn *Turn          `json:"current_turn"`
+	AnswerPool  AnswerPool     `json:"answer_pool"`
+	Timer       *Timer         `json:"timer,omitempty"`
+	Points      map[string]int `json:"points,omitempty"` // Points awarded to each player when the round ended
+
+	// VotedAt records when each player voted, by player ID
+	VotedAt map[string]time.Time `json:"voted_at,omitempty"`
+
+	// SpeedBonuses are the bonus points each player earned for a fast
+	// correct vote, included in their points
+	SpeedBonuses map[string]int `json:"speed_bonuses,omitempty"`
+
+	// Likes records the answer each player liked while the round was
+	// revealed, by player ID
+	Likes map[string]string `json:"likes,omitempty"`
+
+	// LikeBonuses are the bonus points the authors of the round's most-liked
+	// answer earned once its reveal was over. Unlike speed bonuses, they are
+	// not included in the round's points.
+	LikeBonuses map[string]int `json:"like_bonuses,omitempty"`
+
+	// AudienceVotes counts the votes for each option by ID from viewers
+	// watching the game, such as a streamer's chat. They score no points.
+	AudienceVotes map[string]int `json:"audience_votes,omitempty"`
+
+	// RemindedPhase is the last phase of the round whose laggards were
+	// reminded that its time is running out
+	RemindedPhase TimerType `json:"reminded_phase,omitempty"`
+
+	// ModeratedPlayers answered with an answer moderation withheld. They
+	// count as having answered, and fillers take their answers' place.
+	ModeratedPlayers []string `json:"moderated_players,omitempty"`
+}
+
+// Answered returns the players who have answered the round, including those
+// whose answer moderation withheld
+func (r *Round) Answered() []string {
+	answered := slices.Clone(r.ModeratedPlayers)
+	for _, answer := range r.AnswerPool.FakeAnswers {
+		if !slices.Contains(answered, answer.PlayerID) {
+			answered = append(answered, answer.PlayerID)
+		}
+	}
+	return answered
+}
+
+// LikeCounts returns the number of likes each answer of the round received,
+// by answer ID
+func (r *Round) LikeCounts() map[string]int {
+	if len(r.Likes) == 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, answerID := range r.Likes {
+		counts[answerID]++
+	}
+	return counts
 }
 
 // RoundStatus represents the current status of a round
@@ -91,6 +499,36 @@ const (
 	RoundStatusCompleted RoundStatus = "completed"
 )
 
+// RoundView is the sanitized state of a round exposed to clients. Answers
+// and votes are only revealed once the phase allows it.
+type RoundView struct {
+	Number        int                  `json:"number"`
+	Category      string               `json:"category"`
+	Question      string               `json:"question"`
+	Status        RoundStatus          `json:"status"`
+	StartTime     time.Time            `json:"start_time"`
+	EndTime       time.Time            `json:"end_time"`
+	CurrentTurn   *Turn                `json:"current_turn,omitempty"`
+	Timer         *Timer               `json:"timer,omitempty"`
+	AnswerCount   int                  `json:"answer_count"`              // Number of answers submitted so far
+	Options       []AnswerOption       `json:"options,omitempty"`         // Answers to vote on, during voting
+	CorrectAnswer string               `json:"correct_answer,omitempty"`  // Revealed once the round is completed
+	Answers       []Answer             `json:"answers,omitempty"`         // Answers with authors and votes, once completed
+	CorrectVotes  []string             `json:"correct_votes,omitempty"`   // Players who found the correct answer, once completed
+	FooledByHouse []string             `json:"fooled_by_house,omitempty"` // Players who voted for a filler, once completed
+	VotedAt       map[string]time.Time `json:"voted_at,omitempty"`        // When each player voted, once completed
+	SpeedBonuses  map[string]int       `json:"speed_bonuses,omitempty"`   // Bonus points for fast correct votes, once completed
+	Likes         map[string]int       `json:"likes,omitempty"`           // Likes each answer received by ID, once completed
+	LikeBonuses   map[string]int       `json:"like_bonuses,omitempty"`    // Bonus points for writing the most-liked answer, once tallied
+	AudienceVotes map[string]int       `json:"audience_votes,omitempty"`  // Viewers' votes for each option by ID
+}
+
+// AnswerOption is an answer presented for voting without revealing its author
+type AnswerOption struct {
+	ID   string `json:"id,omitempty"`
+	Text string `json:"text"`
+}
+
 // Answer represents a player's answer
 type Answer struct {
 	ID        string    `json:"id"`
@@ -108,30 +546,152 @@ type GameRepository interface {
 	// GetByCode retrieves a game by its code
 	GetByCode(ctx context.Context, code string) (*Game, error)
 
+	// GetByID retrieves a game by its ID
+	GetByID(ctx context.Context, id string) (*Game, error)
+
 	// Update updates a game
 	Update(ctx context.Context, game *Game) error
 
 	// Delete deletes a game
 	Delete(ctx context.Context, code string) error
+
+	// List retrieves a page of games, optionally filtered by status
+	List(ctx context.Context, params *pagination.Params) (pagination.Page[*Game], error)
+}
+
+// GameSessionStore holds the state of active games and their players'
+// sessions for fast access between requests
+type GameSessionStore interface {
+	// StoreGame stores an active game
+	StoreGame(ctx context.Context, game *Game) error
+
+	// StoreGameRounds stores an active game's state other than its rounds,
+	// and its rounds from index from on, which are all a change can touch.
+	// Earlier rounds must already be stored.
+	StoreGameRounds(ctx context.Context, game *Game, from int) error
+
+	// GetGame retrieves an active game by its ID, with all of its rounds
+	GetGame(ctx context.Context, gameID string) (*Game, error)
+
+	// GetGameHead retrieves an active game by its ID with only its current
+	// round, for commands that validate against it before loading the rest
+	GetGameHead(ctx context.Context, gameID string) (*GameHead, error)
+
+	// GetGameHeads retrieves every active game with only its current round
+	GetGameHeads(ctx context.Context) ([]*GameHead, error)
+
+	// DeleteGame removes an active game
+	DeleteGame(ctx context.Context, gameID string) error
+
+	// StorePlayerSession stores a player's session along with their token
+	StorePlayerSession(ctx context.Context, gameID string, player *Player, token string) error
+
+	// ResolvePlayerToken returns the ID of the player a token was issued to
+	ResolvePlayerToken(ctx context.Context, gameID string, token string) (string, error)
+
+	// DeletePlayerSession removes a player's session and token
+	DeletePlayerSession(ctx context.Context, gameID string, playerID string) error
+
+	// StoreOverlayToken stores a game's overlay token, replacing its last one
+	StoreOverlayToken(ctx context.Context, gameID string, token string) error
+
+	// ResolveOverlayToken returns the ID of the game an overlay token was issued for
+	ResolveOverlayToken(ctx context.Context, token string) (string, error)
+
+	// StoreResumeSession stores what a WebSocket client is bound to under its
+	// resume token, restarting the token's expiry
+	StoreResumeSession(ctx context.Context, token string, session *ResumeSession) error
+
+	// ResolveResumeToken returns what the client holding a resume token was bound to
+	ResolveResumeToken(ctx context.Context, token string) (*ResumeSession, error)
+}
+
+// GameHead is an active game with at most its current round loaded, which
+// background jobs scan every game for each tick and player commands validate
+// against. Games must be loaded in full before they are changed, since
+// changes save every round.
+type GameHead struct {
+	*Game
+	RoundCount int // Number of rounds the full game has
+}
+
+// ResumeSession is what a WebSocket client was bound to, kept so that it can
+// reconnect with its resume token after its socket drops
+type ResumeSession struct {
+	GameID   string `json:"game_id"`
+	PlayerID string `json:"player_id,omitempty"`
+	Display  bool   `json:"display,omitempty"`
+	LastAck  int    `json:"last_ack"` // Version of the last game state the client acknowledged
+}
+
+// GameBroadcaster delivers game events to connected clients
+type GameBroadcaster interface {
+	// BroadcastToGame sends an event to every client in a game
+	BroadcastToGame(gameID string, messageType string, payload []byte)
+
+	// BroadcastToAudience sends an event only to one kind of client in a game
+	BroadcastToAudience(gameID string, audience MessageAudience, messageType string, payload []byte)
+
+	// SendToPlayer sends an event only to one player's clients in a game
+	SendToPlayer(gameID string, playerID string, messageType string, payload []byte)
+
+	// CloseGame disconnects every client in a game
+	CloseGame(gameID string)
+
+	// MutePlayer stops or resumes relaying a player's chat and reactions to
+	// the other clients in a game
+	MutePlayer(gameID string, playerID string, muted bool)
+}
+
+// GameListOptions describes how game collections may be paginated
+var GameListOptions = pagination.Options{
+	SortFields: []string{"created_at", "updated_at"},
+	Filters:    []string{"status"},
 }
 
 // GameService defines the interface for game-related operations
 type GameService interface {
 	// Game management
-	CreateGame(ctx context.Context, code string, player Player, settings *GameSettings) (*Game, error)
+	CreateGame(ctx context.Context, code string, player Player, settings *GameSettings) (*Game, string, error)
 	GetGame(ctx context.Context, code string) (*Game, error)
-	JoinGame(ctx context.Context, code string, player Player) error
-	StartGame(ctx context.Context, code string) error
-	EndGame(ctx context.Context, code string) error
+	GetRound(ctx context.Context, code string, number int, viewerID string) (*RoundView, error)
+	GetDisplayView(ctx context.Context, code string) (*DisplayView, error)
+	ListLobbies(ctx context.Context, callerID string, region string) ([]*GameSummary, error)
+	FindLobby(ctx context.Context, callerID string, region string) (*GameSummary, error)
+	JoinGame(ctx context.Context, code string, player Player) (string, error)
+	StartGame(ctx context.Context, code string, callerID string, force bool) error
+	CancelCountdown(ctx context.Context, code string, callerID string) error
+	SetReady(ctx context.Context, code string, playerID string, ready bool) error
+	EndGame(ctx context.Context, code string, callerID string) error
+	DeleteGame(ctx context.Context, code string, callerID string) error
+	ExtendGame(ctx context.Context, code string, callerID string) (time.Time, error)
+	SkipPhase(ctx context.Context, code string, callerID string) error
+	ExtendTimer(ctx context.Context, code string, callerID string) (*Timer, error)
+	GetGameEvents(ctx context.Context, code string, callerID string) ([]*GameEvent, error)
+	MutePlayer(ctx context.Context, code string, callerID string, playerID string, muted bool) error
+
+	// Party management
+	CreatePartyGame(ctx context.Context, code string, player Player, settings *GameSettings, partyID string) (*Game, string, error)
+	GetPartyScoreboard(ctx context.Context, partyID string) (*PartyScoreboard, error)
 
 	// Turn management
 	StartTurn(ctx context.Context, gameID string, playerID string) error
-	SelectCategory(ctx context.Context, gameID string, category string) error
+	SelectCategory(ctx context.Context, gameID string, playerID string, category string) error
 	SubmitAnswer(ctx context.Context, gameID string, playerID string, answer string) error
 	SubmitVote(ctx context.Context, gameID string, playerID string, answerID string) error
-	EndRound(ctx context.Context, gameID string) error
+	EndRound(ctx context.Context, gameID string, callerID string) error
+	LikeAnswer(ctx context.Context, code string, playerID string, answerID string) error
+
+	// Audience participation
+	CastAudienceVotes(ctx context.Context, code string, round int, votes map[int]int) error
 
 	// Session management
+	AuthenticatePlayer(ctx context.Context, code string, token string) (string, error)
+	IssueOverlayToken(ctx context.Context, code string, callerID string) (string, error)
+	AuthenticateOverlay(ctx context.Context, token string) (string, error)
+	IssueResumeToken(ctx context.Context, session *ResumeSession) (string, error)
+	ResumeSession(ctx context.Context, token string) (*ResumeSession, error)
+	UpdateResumeSession(ctx context.Context, token string, session *ResumeSession) error
 	HandlePlayerReconnection(ctx context.Context, gameID string, playerID string) error
 	CleanupInactiveGames(ctx context.Context) error
 }
@@ -154,11 +714,69 @@ const (
 	TurnStatusEnded   TurnStatus = "ended"
 )
 
+// FillerPlayerID is the author of the filler answers the system adds to a pool
+const FillerPlayerID = "system"
+
 // AnswerPool represents the pool of answers for a round
 type AnswerPool struct {
-	CorrectAnswer string   `json:"correct_answer"`
-	FakeAnswers   []Answer `json:"fake_answers"`   // Player-submitted answers
-	FillerAnswers []Answer `json:"filler_answers"` // System-generated filler answers
+	CorrectAnswer   string         `json:"correct_answer"`
+	CorrectAnswerID string         `json:"correct_answer_id,omitempty"` // Option ID of the correct answer, once voting starts
+	CorrectVotes    []string       `json:"correct_votes,omitempty"`     // Players who voted for the correct answer
+	FakeAnswers     []Answer       `json:"fake_answers"`                // Player-submitted answers
+	FillerAnswers   []Answer       `json:"filler_answers"`              // System-generated filler answers
+	VotingPool      []AnswerOption `json:"voting_pool,omitempty"`       // Options presented for voting, fixed when voting starts
+}
+
+// HasOption reports whether an answer ID is one of the options in the voting pool
+func (p *AnswerPool) HasOption(answerID string) bool {
+	return answerID != "" && slices.ContainsFunc(p.VotingPool, func(o AnswerOption) bool {
+		return o.ID == answerID
+	})
+}
+
+// Voters returns the players who have voted, whichever option they chose
+func (p *AnswerPool) Voters() []string {
+	voters := slices.Clone(p.CorrectVotes)
+	for _, answers := range [][]Answer{p.FakeAnswers, p.FillerAnswers} {
+		for _, answer := range answers {
+			voters = append(voters, answer.Votes...)
+		}
+	}
+	return voters
+}
+
+// FillerVoters returns the players who voted for a filler answer
+func (p *AnswerPool) FillerVoters() []string {
+	var voters []string
+	for _, answer := range p.FillerAnswers {
+		voters = append(voters, answer.Votes...)
+	}
+	return voters
+}
+
+// Tally returns the number of votes each option in the voting pool has
+// received, by option ID
+func (p *AnswerPool) Tally() map[string]int {
+	tally := make(map[string]int, len(p.VotingPool))
+	for _, option := range p.VotingPool {
+		tally[option.ID] = 0
+	}
+	if p.CorrectAnswerID != "" {
+		tally[p.CorrectAnswerID] = len(p.CorrectVotes)
+	}
+	for _, answers := range [][]Answer{p.FakeAnswers, p.FillerAnswers} {
+		for _, answer := range answers {
+			if _, ok := tally[answer.ID]; ok {
+				tally[answer.ID] = len(answer.Votes)
+			}
+		}
+	}
+	return tally
+}
+
+// HasVoted reports whether a player has voted for any option
+func (p *AnswerPool) HasVoted(playerID string) bool {
+	return slices.Contains(p.Voters(), playerID)
 }
 
 // Timer represents a game timer
@@ -169,28 +787,41 @@ type Timer struct {
 	EndTime   time.Time `json:"end_time"`
 }
 
+// Intermission is a pause between rounds, once the last round's answers
+// have been revealed
+type Intermission struct {
+	Timer        *Timer `json:"timer"`
+	NextPlayerID string `json:"next_player_id,omitempty"` // Player whose turn starts the next round, if rounds have turns
+}
+
 type TimerType string
 
 const (
 	TimerTypeCategorySelection TimerType = "category_selection"
 	TimerTypeAnswerWriting     TimerType = "answer_writing"
 	TimerTypeVoting            TimerType = "voting"
+	TimerTypeStartCountdown    TimerType = "start_countdown"
+	TimerTypeIntermission      TimerType = "intermission"
 )
 
 // Common errors
 var (
-	ErrGameNotFound    = errors.New("game not found")
-	ErrGameNotStarted  = errors.New("game has not started")
-	ErrGameInProgress  = errors.New("game is already in progress")
-	ErrGameEnded       = errors.New("game has ended")
-	ErrInvalidRound    = errors.New("invalid round number")
-	ErrAnswerSubmitted = errors.New("answer already submitted")
-	ErrVoteSubmitted   = errors.New("vote already submitted")
-	ErrInvalidVote     = errors.New("invalid vote")
-	ErrPlayerNotFound  = errors.New("player not found")
-	ErrPlayerNotInGame = errors.New("player not in game")
-	ErrInvalidCategory = errors.New("invalid category")
-	ErrInvalidQuestion = errors.New("invalid question")
-	ErrInvalidAnswer   = errors.New("invalid answer")
-	ErrInvalidSettings = errors.New("invalid game settings")
+	ErrGameNotFound        = errors.New("game not found")
+	ErrGameCodeTaken       = errors.New("game code already exists")
+	ErrGameNotStarted      = errors.New("game has not started")
+	ErrGameInProgress      = errors.New("game is already in progress")
+	ErrGameEnded           = errors.New("game has ended")
+	ErrInvalidRound        = errors.New("invalid round number")
+	ErrAnswerSubmitted     = errors.New("answer already submitted")
+	ErrVoteSubmitted       = errors.New("vote already submitted")
+	ErrInvalidVote         = errors.New("invalid vote")
+	ErrPlayerNotFound      = errors.New("player not found")
+	ErrPlayerNotInGame     = errors.New("player not in game")
+	ErrInvalidCategory     = errors.New("invalid category")
+	ErrInvalidQuestion     = errors.New("invalid question")
+	ErrInvalidAnswer       = errors.New("invalid answer")
+	ErrInvalidSettings     = errors.New("invalid game settings")
+	ErrInvalidPlayerToken  = errors.New("invalid player token")
+	ErrInvalidOverlayToken = errors.New("invalid overlay token")
+	ErrInvalidResumeToken  = errors.New("invalid resume token")
 )
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// GuestExpiration is how long a guest identity lasts
+const GuestExpiration = 24 * time.Hour
+
+// Guest is a temporary identity for playing without an account. A guest
+// that converts to an account keeps its ID, and with it the games it played.
+type Guest struct {
+	ID          string    `json:"id"`
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// GuestStore holds guest identities by the session token they were minted
+// with, until they expire
+type GuestStore interface {
+	// StoreGuest stores a guest under its session token until it expires
+	StoreGuest(ctx context.Context, token string, guest *Guest) error
+
+	// ResolveGuest returns the guest a session token was minted for. It
+	// returns ErrInvalidSession if the token is unknown or has expired.
+	ResolveGuest(ctx context.Context, token string) (*Guest, error)
+
+	// DeleteGuest ends a guest identity
+	DeleteGuest(ctx context.Context, token string) error
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+)
+
+// HallOfFameMinLikes is how many likes a fake answer needs to enter the
+// hall of fame
+const HallOfFameMinLikes = 3
+
+// HallOfFameEntry is a fake answer players liked enough to keep, a bluff
+// for the community to browse. Entries are only shown while their author
+// consents.
+type HallOfFameEntry struct {
+	ID            string    `json:"id"`
+	GameID        string    `json:"-"`
+	Category      string    `json:"category"`
+	Question      string    `json:"question"`
+	CorrectAnswer string    `json:"correct_answer"`
+	Answer        string    `json:"answer"`
+	AuthorID      string    `json:"author_id"`
+	AuthorName    string    `json:"author_name"`
+	Likes         int       `json:"likes"`
+	Consent       bool      `json:"-"` // Whether the author lets the answer be shown
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// HallOfFameOptions describes how the hall of fame may be paginated
+var HallOfFameOptions = pagination.Options{
+	SortFields: []string{"created_at"},
+}
+
+// HallOfFameRepository defines the interface for reading the hall of fame.
+// Entries are written along with the change that tallies their round's likes.
+type HallOfFameRepository interface {
+	// List retrieves a page of the entries whose authors consent to them
+	// being shown
+	List(ctx context.Context, params *pagination.Params) (pagination.Page[*HallOfFameEntry], error)
+
+	// SetConsent records whether a user lets their entries be shown
+	SetConsent(ctx context.Context, userID string, consent bool) error
+}
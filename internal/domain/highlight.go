@@ -0,0 +1,183 @@
+package domain
+
+import "slices"
+
+// Highlights are the standout moments of a game, compiled when it ends.
+// Moments that never happened, such as a bluff in a game where nobody was
+// fooled, are left out.
+type Highlights struct {
+	BiggestSwing *SwingHighlight `json:"biggest_swing,omitempty"`
+	BestBluff    *BluffHighlight `json:"best_bluff,omitempty"`
+	ClosestVote  *VoteHighlight  `json:"closest_vote,omitempty"`
+}
+
+// SwingHighlight is the round in which a player climbed the most places in
+// the standings
+type SwingHighlight struct {
+	Round        int    `json:"round"`
+	PlayerID     string `json:"player_id"`
+	FromPosition int    `json:"from_position"`
+	ToPosition   int    `json:"to_position"`
+	Points       int    `json:"points"` // Points the player earned in the round
+}
+
+// BluffHighlight is the fake answer that fooled the most players
+type BluffHighlight struct {
+	Round    int      `json:"round"`
+	Question string   `json:"question"`
+	Answer   string   `json:"answer"`
+	PlayerID string   `json:"player_id"`
+	Fooled   []string `json:"fooled"` // IDs of the players who voted for the answer
+}
+
+// VoteHighlight is the round in which the correct answer and the most-voted
+// fake answer were closest in votes
+type VoteHighlight struct {
+	Round           int    `json:"round"`
+	Question        string `json:"question"`
+	CorrectAnswer   string `json:"correct_answer"`
+	CorrectVotes    int    `json:"correct_votes"`
+	FakeAnswer      string `json:"fake_answer"`
+	FakeAnswerVotes int    `json:"fake_answer_votes"`
+}
+
+// NewHighlights compiles a game's highlights from its completed rounds. Ties
+// go to the earliest round.
+func NewHighlights(game *Game) *Highlights {
+	return &Highlights{
+		BiggestSwing: biggestSwing(game),
+		BestBluff:    bestBluff(game),
+		ClosestVote:  closestVote(game),
+	}
+}
+
+// biggestSwing finds the round in which a player climbed the most places,
+// preferring the player who earned the most points in it among equal climbs
+func biggestSwing(game *Game) *SwingHighlight {
+	var best *SwingHighlight
+	totals := make(map[string]int, len(game.Players))
+	for _, round := range game.Rounds {
+		if round.Status != RoundStatusCompleted {
+			continue
+		}
+
+		before := standings(game.Players, totals)
+		earned := make(map[string]int, len(game.Players))
+		for _, p := range game.Players {
+			earned[p.ID] = round.Points[p.ID] + round.LikeBonuses[p.ID]
+			totals[p.ID] += earned[p.ID]
+		}
+		after := standings(game.Players, totals)
+
+		for _, p := range game.Players {
+			climb := before[p.ID] - after[p.ID]
+			if climb <= 0 {
+				continue
+			}
+			if best == nil || climb > best.FromPosition-best.ToPosition ||
+				(climb == best.FromPosition-best.ToPosition && earned[p.ID] > best.Points) {
+				best = &SwingHighlight{
+					Round:        round.Number,
+					PlayerID:     p.ID,
+					FromPosition: before[p.ID],
+					ToPosition:   after[p.ID],
+					Points:       earned[p.ID],
+				}
+			}
+		}
+	}
+	return best
+}
+
+// standings returns each player's position by points, with tied players
+// sharing a position
+func standings(players []Player, totals map[string]int) map[string]int {
+	positions := make(map[string]int, len(players))
+	for _, p := range players {
+		positions[p.ID] = 1
+		for _, other := range players {
+			if totals[other.ID] > totals[p.ID] {
+				positions[p.ID]++
+			}
+		}
+	}
+	return positions
+}
+
+// bestBluff finds the fake answer that fooled the most other players
+func bestBluff(game *Game) *BluffHighlight {
+	var best *BluffHighlight
+	for _, round := range game.Rounds {
+		if round.Status != RoundStatusCompleted {
+			continue
+		}
+		for _, answer := range round.AnswerPool.FakeAnswers {
+			if isCorrectAnswer(answer.Text, round.AnswerPool.CorrectAnswer) {
+				continue
+			}
+			fooled := slices.DeleteFunc(slices.Clone(answer.Votes), func(voterID string) bool {
+				return voterID == answer.PlayerID
+			})
+			if len(fooled) == 0 || (best != nil && len(fooled) <= len(best.Fooled)) {
+				continue
+			}
+			best = &BluffHighlight{
+				Round:    round.Number,
+				Question: round.Question,
+				Answer:   answer.Text,
+				PlayerID: answer.PlayerID,
+				Fooled:   fooled,
+			}
+		}
+	}
+	return best
+}
+
+// closestVote finds the round in which the correct answer won or lost by the
+// fewest votes against the most-voted fake answer. Rounds in which either
+// went without votes are not close calls.
+func closestVote(game *Game) *VoteHighlight {
+	var best *VoteHighlight
+	for _, round := range game.Rounds {
+		if round.Status != RoundStatusCompleted {
+			continue
+		}
+
+		pool := &round.AnswerPool
+		correctVotes := len(pool.CorrectVotes)
+		var fake *Answer
+		for i := range pool.FakeAnswers {
+			answer := &pool.FakeAnswers[i]
+			if isCorrectAnswer(answer.Text, pool.CorrectAnswer) {
+				correctVotes += len(answer.Votes)
+				continue
+			}
+			if fake == nil || len(answer.Votes) > len(fake.Votes) {
+				fake = answer
+			}
+		}
+		if fake == nil || correctVotes == 0 || len(fake.Votes) == 0 {
+			continue
+		}
+
+		if best == nil || voteMargin(correctVotes, len(fake.Votes)) < voteMargin(best.CorrectVotes, best.FakeAnswerVotes) {
+			best = &VoteHighlight{
+				Round:           round.Number,
+				Question:        round.Question,
+				CorrectAnswer:   pool.CorrectAnswer,
+				CorrectVotes:    correctVotes,
+				FakeAnswer:      fake.Text,
+				FakeAnswerVotes: len(fake.Votes),
+			}
+		}
+	}
+	return best
+}
+
+// voteMargin returns the difference between two vote counts
+func voteMargin(a, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
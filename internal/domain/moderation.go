@@ -0,0 +1,11 @@
+package domain
+
+import "context"
+
+// AnswerModerator checks players' fake answers with an outside moderation
+// service before they enter the voting pool
+type AnswerModerator interface {
+	// Flag reports whether an answer, written in a game's question language,
+	// should be withheld from the other players
+	Flag(ctx context.Context, text string, language string) (bool, error)
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrOAuthExchange is returned when a provider rejects an authorization code
+var ErrOAuthExchange = errors.New("failed to sign in with the provider")
+
+// OAuthIdentity is who a provider says signed in
+type OAuthIdentity struct {
+	Provider      string
+	Subject       string // The provider's stable ID for the account
+	Email         string
+	EmailVerified bool // Whether the provider vouches for the email, which accounts are only linked by if so
+	Name          string
+	Groups        []string // Values of the role claim of an organization's identity provider, if it has one
+}
+
+// OAuthProvider signs users in with an outside account, such as a Google or
+// Apple account, by the OAuth2 authorization code flow
+type OAuthProvider interface {
+	// Name returns the name the provider is chosen by, such as "google"
+	Name() string
+
+	// Exchange redeems an authorization code the client was given for the
+	// identity that signed in. It returns ErrOAuthExchange if the provider
+	// rejects the code.
+	Exchange(ctx context.Context, code string, redirectURI string) (*OAuthIdentity, error)
+}
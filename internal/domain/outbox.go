@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OutboxMessage is a game broadcast recorded in the same transaction as the
+// change that produced it. A relay publishes it once the change is committed.
+type OutboxMessage struct {
+	ID        int64           `json:"id"`
+	GameID    string          `json:"game_id"`
+	PlayerID  string          `json:"player_id,omitempty"` // Recipient of a private message; empty for messages to the whole game
+	Audience  MessageAudience `json:"audience,omitempty"`  // Kind of client a message to the whole game is for
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// MessageAudience is the kind of client a game message is meant for
+type MessageAudience string
+
+const (
+	// AudienceEveryone messages reach every client in the game
+	AudienceEveryone MessageAudience = ""
+
+	// AudiencePlayers messages reach only the clients of the game's players,
+	// authenticated with their player token, such as those carrying the game
+	AudiencePlayers MessageAudience = "players"
+
+	// AudienceDisplays messages reach only display clients, the shared
+	// screens that show a game to the room while players use their phones
+	AudienceDisplays MessageAudience = "displays"
+)
+
+// GameChange is everything a single game command writes
+type GameChange struct {
+	Game          *Game
+	Created       bool // Whether the game is new and must be inserted
+	Events        []*GameEvent
+	Messages      []*OutboxMessage   // Messages without a payload carry the saved game as players see it
+	Result        *GameResult        // Set when the change ends the game
+	CategoryStats []*CategoryStats   // Increments to players' category stats from rounds the change ends
+	Party         *PartyScoreboard   // Set when the change ends one of a party's games
+	HallOfFame    []*HallOfFameEntry // Answers liked into the hall of fame as the change tallied their round's likes
+
+	// Snapshot is the game encoded as JSON once it is saved, set by
+	// EncodeSnapshots for whatever else stores or sends the saved game
+	Snapshot json.RawMessage
+}
+
+// AssignGameID sets the ID of a new game, propagating it to the change's
+// events and messages
+func (c *GameChange) AssignGameID(id string) {
+	c.Game.ID = id
+	for _, event := range c.Events {
+		event.GameID = id
+	}
+	for _, msg := range c.Messages {
+		msg.GameID = id
+	}
+}
+
+// EncodeSnapshots encodes the saved game once, setting the change's Snapshot
+// and the payload of messages that carry the game. Those messages reach
+// players, so they carry the game's player view, which is only encoded apart
+// from the snapshot when something is withheld from it. Stores call it once
+// the game's ID is final.
+func (c *GameChange) EncodeSnapshots() error {
+	snapshot, err := json.Marshal(c.Game)
+	if err != nil {
+		return fmt.Errorf("failed to marshal game: %w", err)
+	}
+	c.Snapshot = snapshot
+
+	var players []byte
+	for _, msg := range c.Messages {
+		if msg.Payload != nil {
+			continue
+		}
+		if players == nil {
+			players = snapshot
+			if view := c.Game.PlayerView(""); view != c.Game {
+				if players, err = json.Marshal(view); err != nil {
+					return fmt.Errorf("failed to marshal player view: %w", err)
+				}
+			}
+		}
+		msg.Payload = players
+	}
+	return nil
+}
+
+// GameChangeStore persists game changes atomically
+type GameChangeStore interface {
+	// SaveChange appends the change's events, saves the game snapshot and
+	// queues its messages in a single transaction. A new game is assigned its
+	// stored ID, which is propagated to the events and messages.
+	SaveChange(ctx context.Context, change *GameChange) error
+}
+
+// OutboxRepository provides access to queued messages for relaying
+type OutboxRepository interface {
+	// ProcessPending locks up to limit queued messages in the order they were
+	// queued and passes them to publish, removing them from the queue if it
+	// succeeds. It returns the number of messages published.
+	ProcessPending(ctx context.Context, limit int, publish func(messages []*OutboxMessage) error) (int, error)
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// benchGame generates a game of 8 players in its last of 10 rounds, with
+// every round answered and voted on by every player
+func benchGame() *Game {
+	now := time.Now()
+	game := &Game{
+		ID:           "00000000-0000-0000-0000-000000000000",
+		Code:         "BENCH",
+		Status:       GameStatusPlaying,
+		Settings:     DefaultGameSettings(),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		LastActivity: now,
+		HostID:       "player-1",
+		Version:      160,
+	}
+	for i := 1; i <= 8; i++ {
+		game.Players = append(game.Players, Player{
+			ID:          fmt.Sprintf("player-%d", i),
+			Name:        fmt.Sprintf("Player %d", i),
+			Score:       i * 100,
+			IsConnected: true,
+			LastSeen:    now,
+			IsActive:    true,
+			Slot:        i,
+			Color:       "#3366ff",
+			Ready:       true,
+		})
+	}
+	for r := 1; r <= 10; r++ {
+		round := Round{
+			Number:     r,
+			Category:   "general",
+			Question:   fmt.Sprintf("What is the answer to benchmark question %d?", r),
+			QuestionID: fmt.Sprintf("question-%d", r),
+			Status:     RoundStatusCompleted,
+			StartTime:  now,
+			EndTime:    now,
+			AnswerPool: AnswerPool{CorrectAnswer: fmt.Sprintf("Answer %d", r)},
+			Points:     make(map[string]int),
+		}
+		for _, player := range game.Players {
+			answerID := fmt.Sprintf("answer-%d-%s", r, player.ID)
+			text := fmt.Sprintf("%s's made-up answer", player.Name)
+			round.AnswerPool.FakeAnswers = append(round.AnswerPool.FakeAnswers, Answer{
+				ID:        answerID,
+				PlayerID:  player.ID,
+				Text:      text,
+				Votes:     []string{player.ID},
+				CreatedAt: now,
+			})
+			round.AnswerPool.VotingPool = append(round.AnswerPool.VotingPool, AnswerOption{ID: answerID, Text: text})
+			round.Points[player.ID] = 100
+		}
+		game.Rounds = append(game.Rounds, round)
+	}
+	return game
+}
+
+// benchChange returns a change to the game carrying it in two messages, as
+// game_updated and a published game event do
+func benchChange(game *Game) *GameChange {
+	return &GameChange{
+		Game: game,
+		Messages: []*OutboxMessage{
+			{GameID: game.ID, Audience: AudiencePlayers, Type: "game_updated"},
+			{GameID: game.ID, Audience: AudiencePlayers, Type: "round_ended"},
+		},
+	}
+}
+
+// BenchmarkGameChangeEncodePerConsumer encodes the game for the snapshot,
+// for Redis and for every message carrying it, as each consumer once did
+func BenchmarkGameChangeEncodePerConsumer(b *testing.B) {
+	game := benchGame()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		change := benchChange(game)
+		snapshot, err := json.Marshal(change.Game)
+		if err != nil {
+			b.Fatal(err)
+		}
+		change.Snapshot = snapshot
+		if _, err := json.Marshal(change.Game); err != nil {
+			b.Fatal(err)
+		}
+		for _, msg := range change.Messages {
+			if msg.Payload, err = json.Marshal(change.Game); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkGameChangeEncodeSnapshots encodes the game once for every
+// consumer of the change
+func BenchmarkGameChangeEncodeSnapshots(b *testing.B) {
+	game := benchGame()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := benchChange(game).EncodeSnapshots(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
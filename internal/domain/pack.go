@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNothingToRelease is returned when a pack is published without any
+// questions added since its last release
+var ErrNothingToRelease = errors.New("pack has no unreleased questions")
+
+// PackRelease is a published version of a language's question pack. New
+// questions wait unreleased until admins publish the next version, and games
+// only draw from the version they were created with, so a release never
+// changes a game mid-way.
+type PackRelease struct {
+	Language    string    `json:"language"`
+	Version     int       `json:"version"`   // Increases by one with each release of the language's pack
+	Notes       string    `json:"notes"`     // What the release brings, shown in the changelog
+	Questions   int       `json:"questions"` // Questions the release added
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// PackChangelog lists pack releases for clients, which compare the latest
+// versions with the ones they last showed to announce new packs
+type PackChangelog struct {
+	Latest   map[string]int `json:"latest"`   // Latest version of each language's pack
+	Releases []*PackRelease `json:"releases"` // Releases newer than the requested version, newest first
+}
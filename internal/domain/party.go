@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// ErrPartyNotFound is returned when no party has the requested ID
+var ErrPartyNotFound = errors.New("party not found")
+
+// Party is a group of players who play a series of games together, such as
+// a game night, keeping a running scoreboard across them. The party's host
+// creates each of its games.
+type Party struct {
+	ID        string    `json:"id"`
+	HostID    string    `json:"host_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PartyStanding is a player's record over a party's games
+type PartyStanding struct {
+	PlayerID   string `json:"player_id"`
+	Name       string `json:"name"`
+	Games      int    `json:"games"`
+	Wins       int    `json:"wins"`
+	Points     int    `json:"points"`      // Points over all the party's games
+	Streak     int    `json:"streak"`      // Games won in a row, up to the latest
+	BestStreak int    `json:"best_streak"` // Longest run of games won in a row
+}
+
+// PartyScoreboard is a party along with its players' standings, best first
+type PartyScoreboard struct {
+	Party
+	Games     int             `json:"games"`
+	Champion  string          `json:"champion,omitempty"` // ID of the leading player
+	Standings []PartyStanding `json:"standings"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Record adds a finished game to the scoreboard. Every player who finished
+// first wins, extending their streak; everyone else in the game loses theirs.
+func (b *PartyScoreboard) Record(result *GameResult, at time.Time) {
+	b.Games++
+	b.UpdatedAt = at
+
+	for _, player := range result.Players {
+		i := slices.IndexFunc(b.Standings, func(s PartyStanding) bool { return s.PlayerID == player.PlayerID })
+		if i < 0 {
+			b.Standings = append(b.Standings, PartyStanding{PlayerID: player.PlayerID})
+			i = len(b.Standings) - 1
+		}
+
+		standing := &b.Standings[i]
+		standing.Name = player.Name
+		standing.Games++
+		standing.Points += player.Points
+		if player.Position == 1 {
+			standing.Wins++
+			standing.Streak++
+			standing.BestStreak = max(standing.BestStreak, standing.Streak)
+		} else {
+			standing.Streak = 0
+		}
+	}
+
+	b.Rank()
+}
+
+// Rank orders the standings by wins, then points, and names the champion
+func (b *PartyScoreboard) Rank() {
+	slices.SortStableFunc(b.Standings, func(a, b PartyStanding) int {
+		if a.Wins != b.Wins {
+			return b.Wins - a.Wins
+		}
+		if a.Points != b.Points {
+			return b.Points - a.Points
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	b.Champion = ""
+	if len(b.Standings) > 0 && b.Standings[0].Wins > 0 {
+		b.Champion = b.Standings[0].PlayerID
+	}
+}
+
+// PartyRepository defines the interface for party operations. Scoreboards
+// are written along with the change that ends each of the party's games.
+type PartyRepository interface {
+	// Create creates a party with an empty scoreboard
+	Create(ctx context.Context, party *Party) error
+
+	// Get retrieves a party's scoreboard
+	Get(ctx context.Context, id string) (*PartyScoreboard, error)
+}
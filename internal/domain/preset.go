@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrPresetNotFound is returned when no preset has the requested key
+var ErrPresetNotFound = errors.New("settings preset not found")
+
+// Keys of the built-in presets
+const (
+	PresetClassic     = "classic"
+	PresetSpeedRound  = "speed_round"
+	PresetMarathon    = "marathon"
+	PresetLightning   = "lightning"
+	PresetElimination = "elimination"
+)
+
+// SettingsPreset is a named set of game settings that hosts create games
+// from, so they don't enter timers and round counts every time. Built-in
+// presets are available to everyone; users save their own under their account.
+type SettingsPreset struct {
+	Key       string       `json:"key"`
+	Name      string       `json:"name"`
+	UserID    string       `json:"user_id,omitempty"` // Owner of a user-defined preset, empty for built-in presets
+	Settings  GameSettings `json:"settings"`
+	CreatedAt time.Time    `json:"created_at,omitempty"`
+	UpdatedAt time.Time    `json:"updated_at,omitempty"`
+}
+
+// BuiltinPresets returns the presets available to every host. Their
+// settings leave categories to be chosen when the game is created.
+func BuiltinPresets() []*SettingsPreset {
+	speed := DefaultGameSettings()
+	speed.Rounds = 5
+	speed.TimeLimits = TimeLimits{CategorySelection: 10, AnswerWriting: 15, Voting: 10, Intermission: 5}
+
+	marathon := DefaultGameSettings()
+	marathon.Rounds = 25
+	marathon.TimeLimits = TimeLimits{CategorySelection: 45, AnswerWriting: 60, Voting: 30, Intermission: 12}
+
+	lightning := DefaultGameSettings()
+	lightning.Mode = GameModeLightning
+	lightning.Rounds = LightningRounds
+
+	elimination := DefaultGameSettings()
+	elimination.Mode = GameModeElimination
+	elimination.EliminationInterval = DefaultEliminationInterval
+
+	return []*SettingsPreset{
+		{Key: PresetClassic, Name: "Classic", Settings: *DefaultGameSettings()},
+		{Key: PresetSpeedRound, Name: "Speed Round", Settings: *speed},
+		{Key: PresetMarathon, Name: "Marathon", Settings: *marathon},
+		{Key: PresetLightning, Name: "Lightning", Settings: *lightning},
+		{Key: PresetElimination, Name: "Elimination", Settings: *elimination},
+	}
+}
+
+// BuiltinPreset returns the built-in preset with a key
+func BuiltinPreset(key string) (*SettingsPreset, bool) {
+	for _, preset := range BuiltinPresets() {
+		if preset.Key == key {
+			return preset, true
+		}
+	}
+	return nil, false
+}
+
+// SettingsPresetRepository defines the interface for users' saved presets
+type SettingsPresetRepository interface {
+	// ListByUser retrieves a user's presets ordered by name
+	ListByUser(ctx context.Context, userID string) ([]*SettingsPreset, error)
+
+	// Get retrieves one of a user's presets by key
+	Get(ctx context.Context, userID string, key string) (*SettingsPreset, error)
+
+	// Save creates a preset or replaces the user's preset with the same key
+	Save(ctx context.Context, preset *SettingsPreset) error
+
+	// Delete removes one of a user's presets
+	Delete(ctx context.Context, userID string, key string) error
+}
@@ -0,0 +1,35 @@
+package domain
+
+// GamePreview is the open-graph-style metadata of a game, shown by chat apps
+// when they unfurl its join link. It carries no more than the lobby shows
+// anyone holding the link.
+type GamePreview struct {
+	Code        string     `json:"code"`
+	Title       string     `json:"title"`       // Headline of the unfurled link
+	Description string     `json:"description"` // Line shown under the headline
+	URL         string     `json:"url"`         // Join link the preview is for
+	HostName    string     `json:"host_name"`
+	PlayerCount int        `json:"player_count"`
+	MaxPlayers  int        `json:"max_players"`
+	Categories  []string   `json:"categories"` // Display names of the selected categories
+	Status      GameStatus `json:"status"`
+}
+
+// Preview returns the game's preview, leaving its title, description and
+// link to be written in the reader's language
+func (g *Game) Preview() *GamePreview {
+	preview := &GamePreview{
+		Code:        g.Code,
+		PlayerCount: len(g.Players),
+		MaxPlayers:  g.Settings.MaxPlayers,
+		Categories:  append([]string{}, g.Settings.SelectedCategories...),
+		Status:      g.Status,
+	}
+	for _, p := range g.Players {
+		if p.ID == g.HostID {
+			preview.HostName = p.Name
+			break
+		}
+	}
+	return preview
+}
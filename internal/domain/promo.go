@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Promo code errors
+var (
+	ErrPromoCodeNotFound    = errors.New("promo code not found")
+	ErrPromoCodeExists      = errors.New("promo code already exists")
+	ErrPromoBatchNotFound   = errors.New("promo batch not found")
+	ErrPromoCodeExhausted   = errors.New("promo code has been redeemed too many times")
+	ErrPromoAlreadyRedeemed = errors.New("a code of this promotion was already redeemed")
+)
+
+// PromoReward is what redeeming a promo code unlocks: a premium pack or a
+// cosmetic of the catalog
+type PromoReward struct {
+	PackID   string `json:"pack_id,omitempty"`
+	Cosmetic string `json:"cosmetic,omitempty"`
+}
+
+// PromoBatch is a set of promo codes an admin created for a promotion. Each
+// user may redeem one code of a batch.
+type PromoBatch struct {
+	ID             string      `json:"id"`
+	Name           string      `json:"name"`
+	Reward         PromoReward `json:"reward"`
+	Codes          int         `json:"codes"`           // Number of codes in the batch
+	MaxRedemptions int         `json:"max_redemptions"` // Times each code may be redeemed
+	ExpiresAt      *time.Time  `json:"expires_at,omitempty"`
+	CreatedBy      string      `json:"created_by"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// IsExpired returns whether the batch's codes can no longer be redeemed at
+// a time
+func (b *PromoBatch) IsExpired(now time.Time) bool {
+	return b.ExpiresAt != nil && !now.Before(*b.ExpiresAt)
+}
+
+// PromoCode is a code of a promo batch
+type PromoCode struct {
+	Code        string `json:"code"`
+	BatchID     string `json:"batch_id"`
+	Redemptions int    `json:"redemptions"`
+}
+
+// PromoRedemption records a user redeeming a promo code
+type PromoRedemption struct {
+	BatchID    string      `json:"batch_id"`
+	Code       string      `json:"code"`
+	UserID     string      `json:"user_id"`
+	Reward     PromoReward `json:"reward"`
+	RedeemedAt time.Time   `json:"redeemed_at"`
+}
+
+// PromoRepository defines the interface for promo codes and their
+// redemptions
+type PromoRepository interface {
+	// CreateBatch creates a batch with its codes. It returns
+	// ErrPromoCodeExists if any of the codes is taken.
+	CreateBatch(ctx context.Context, batch *PromoBatch, codes []string) error
+
+	// GetBatch retrieves a batch by ID
+	GetBatch(ctx context.Context, id string) (*PromoBatch, error)
+
+	// ListBatches retrieves every batch, newest first
+	ListBatches(ctx context.Context) ([]*PromoBatch, error)
+
+	// GetCode retrieves a promo code
+	GetCode(ctx context.Context, code string) (*PromoCode, error)
+
+	// ListCodes retrieves a batch's codes
+	ListCodes(ctx context.Context, batchID string) ([]*PromoCode, error)
+
+	// Redeem records a redemption and counts it against its code. It returns
+	// ErrPromoCodeExhausted once the code has been redeemed maxRedemptions
+	// times, and ErrPromoAlreadyRedeemed if the user redeemed a code of the
+	// batch.
+	Redeem(ctx context.Context, redemption *PromoRedemption, maxRedemptions int) error
+
+	// CancelRedemption removes a redemption whose reward could not be given,
+	// handing its use of the code back. It does nothing if there is no such
+	// redemption.
+	CancelRedemption(ctx context.Context, redemption *PromoRedemption) error
+
+	// ListRedemptionsByUser retrieves a user's redemptions, newest first
+	ListRedemptionsByUser(ctx context.Context, userID string) ([]*PromoRedemption, error)
+}
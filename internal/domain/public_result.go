@@ -0,0 +1,124 @@
+package domain
+
+import "time"
+
+// PublicResult is a game's result as anyone with its share link sees it.
+// Players are only known by their names: it holds no IDs or emails.
+type PublicResult struct {
+	Code         string               `json:"code"`
+	PlayerCount  int                  `json:"player_count"`
+	RoundsPlayed int                  `json:"rounds_played"`
+	EndedAt      time.Time            `json:"ended_at"`
+	Players      []PublicPlayerResult `json:"players"`
+	Awards       []PublicAward        `json:"awards"`
+	Highlights   *PublicHighlights    `json:"highlights,omitempty"`
+}
+
+// PublicPlayerResult is a player's standing in a shared game result
+type PublicPlayerResult struct {
+	Name          string      `json:"name"`
+	Position      int         `json:"position"`
+	Points        int         `json:"points"`
+	PlayersFooled int         `json:"players_fooled"`
+	CorrectVotes  int         `json:"correct_votes"`
+	LikesReceived int         `json:"likes_received"`
+	Awards        []AwardKind `json:"awards,omitempty"`
+}
+
+// PublicAward is an award and the names of the players who earned it
+type PublicAward struct {
+	Kind    AwardKind `json:"kind"`
+	Players []string  `json:"players"`
+	Value   float64   `json:"value"`
+}
+
+// PublicHighlights are a game's highlights with players known by their names
+type PublicHighlights struct {
+	BiggestSwing *PublicSwingHighlight `json:"biggest_swing,omitempty"`
+	BestBluff    *PublicBluffHighlight `json:"best_bluff,omitempty"`
+	ClosestVote  *VoteHighlight        `json:"closest_vote,omitempty"`
+}
+
+// PublicSwingHighlight is a SwingHighlight naming its player
+type PublicSwingHighlight struct {
+	Round        int    `json:"round"`
+	Player       string `json:"player"`
+	FromPosition int    `json:"from_position"`
+	ToPosition   int    `json:"to_position"`
+	Points       int    `json:"points"`
+}
+
+// PublicBluffHighlight is a BluffHighlight naming its author and the players
+// it fooled
+type PublicBluffHighlight struct {
+	Round    int      `json:"round"`
+	Question string   `json:"question"`
+	Answer   string   `json:"answer"`
+	Player   string   `json:"player"`
+	Fooled   []string `json:"fooled"`
+}
+
+// NewPublicResult strips a game's result down to what can be shared
+func NewPublicResult(result *GameResult) *PublicResult {
+	names := make(map[string]string, len(result.Players))
+	for _, p := range result.Players {
+		names[p.PlayerID] = p.Name
+	}
+	nameAll := func(ids []string) []string {
+		named := make([]string, 0, len(ids))
+		for _, id := range ids {
+			named = append(named, names[id])
+		}
+		return named
+	}
+
+	public := &PublicResult{
+		Code:         result.Code,
+		PlayerCount:  result.PlayerCount,
+		RoundsPlayed: result.RoundsPlayed,
+		EndedAt:      result.EndedAt,
+		Players:      make([]PublicPlayerResult, 0, len(result.Players)),
+		Awards:       make([]PublicAward, 0, len(result.Awards)),
+	}
+	for _, p := range result.Players {
+		public.Players = append(public.Players, PublicPlayerResult{
+			Name:          p.Name,
+			Position:      p.Position,
+			Points:        p.Points,
+			PlayersFooled: p.PlayersFooled,
+			CorrectVotes:  p.CorrectVotes,
+			LikesReceived: p.LikesReceived,
+			Awards:        AwardsOf(result.Awards, p.PlayerID),
+		})
+	}
+	for _, award := range result.Awards {
+		public.Awards = append(public.Awards, PublicAward{
+			Kind:    award.Kind,
+			Players: nameAll(award.PlayerIDs),
+			Value:   award.Value,
+		})
+	}
+
+	if h := result.Highlights; h != nil {
+		public.Highlights = &PublicHighlights{ClosestVote: h.ClosestVote}
+		if swing := h.BiggestSwing; swing != nil {
+			public.Highlights.BiggestSwing = &PublicSwingHighlight{
+				Round:        swing.Round,
+				Player:       names[swing.PlayerID],
+				FromPosition: swing.FromPosition,
+				ToPosition:   swing.ToPosition,
+				Points:       swing.Points,
+			}
+		}
+		if bluff := h.BestBluff; bluff != nil {
+			public.Highlights.BestBluff = &PublicBluffHighlight{
+				Round:    bluff.Round,
+				Question: bluff.Question,
+				Answer:   bluff.Answer,
+				Player:   names[bluff.PlayerID],
+				Fooled:   nameAll(bluff.Fooled),
+			}
+		}
+	}
+	return public
+}
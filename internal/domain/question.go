@@ -3,23 +3,76 @@ package domain
 import (
 	"context"
 	"errors"
+	"slices"
 	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
 )
 
 // Common errors
 var (
-	ErrQuestionNotFound = errors.New("question not found")
+	ErrQuestionNotFound  = errors.New("question not found")
+	ErrImportJobNotFound = errors.New("import job not found")
+	ErrCategoryNotFound  = errors.New("category has no questions")
+	ErrCategoryExists    = errors.New("category already has questions")
+)
+
+// DefaultQuestionLanguage is the language of questions created without one
+const DefaultQuestionLanguage = "en"
+
+// ContentRating is the audience a question's content is suitable for
+type ContentRating string
+
+const (
+	ContentRatingFamily ContentRating = "family"
+	ContentRatingTeen   ContentRating = "teen"
+	ContentRatingAdult  ContentRating = "adult"
 )
 
+// DefaultContentRating is the rating of questions created without one
+const DefaultContentRating = ContentRatingFamily
+
+// ContentRatings lists the content ratings from mildest to edgiest
+var ContentRatings = []ContentRating{ContentRatingFamily, ContentRatingTeen, ContentRatingAdult}
+
+// RatingsUpTo returns the ratings no edgier than a cap. An empty cap allows
+// every rating.
+func RatingsUpTo(limit ContentRating) []ContentRating {
+	i := slices.Index(ContentRatings, limit)
+	if i < 0 {
+		return ContentRatings
+	}
+	return ContentRatings[:i+1]
+}
+
+// Allows reports whether a question with the given rating may be asked in a
+// game capped at this rating
+func (limit ContentRating) Allows(rating ContentRating) bool {
+	return slices.Contains(RatingsUpTo(limit), rating)
+}
+
 // QuestionRepository defines the interface for question-related operations
 type QuestionRepository interface {
-	// GetRandomQuestion retrieves a random question from a category
-	GetRandomQuestion(ctx context.Context, category string) (*Question, error)
+	// GetRandomQuestion retrieves a random question from a category in a
+	// language, released in packVersion or earlier, rated no edgier than
+	// maxRating and other than the excluded questions. A packVersion of 0
+	// allows every released question. It returns ErrQuestionNotFound if no
+	// question qualifies.
+	GetRandomQuestion(ctx context.Context, category string, language string, packVersion int, maxRating ContentRating, exclude []string) (*Question, error)
 
-	// GetCategories retrieves all available categories
+	// GetCategories retrieves all categories with released questions
 	GetCategories(ctx context.Context) ([]string, error)
 
-	// GetByID retrieves a question by its ID
+	// GetCategoryCounts retrieves all categories with their counts of
+	// released questions rated no edgier than maxRating, once per language
+	// the category has such questions in
+	GetCategoryCounts(ctx context.Context, maxRating ContentRating) ([]CategoryCount, error)
+
+	// GetDifficulties retrieves all available difficulty levels
+	GetDifficulties(ctx context.Context) ([]string, error)
+
+	// GetByID retrieves a question by its ID, even if it was deleted, so
+	// rounds that asked it before its deletion can still be played out
 	GetByID(ctx context.Context, id string) (*Question, error)
 
 	// CreateQuestion creates a new question
@@ -28,23 +81,112 @@ type QuestionRepository interface {
 	// UpdateQuestion updates an existing question
 	UpdateQuestion(ctx context.Context, question *Question) error
 
-	// DeleteQuestion deletes a question
+	// DeleteQuestion soft-deletes a question, which keeps it out of draws,
+	// listings and category counts until it is restored
 	DeleteQuestion(ctx context.Context, id string) error
 
+	// RestoreQuestion restores a deleted question. It returns
+	// ErrQuestionNotFound if no deleted question has the ID.
+	RestoreQuestion(ctx context.Context, id string) error
+
 	// BulkCreateQuestions creates multiple questions in a single transaction
 	BulkCreateQuestions(ctx context.Context, questions []*Question) error
 
+	// RenameCategory moves every question in a category to another in a
+	// single transaction, returning how many were moved. Moving them into a
+	// category that already has questions merges the two, and fails with
+	// ErrCategoryExists unless merge is set.
+	RenameCategory(ctx context.Context, from string, to string, merge bool) (int, error)
+
 	// ValidateQuestion validates a question's data
 	ValidateQuestion(ctx context.Context, question *Question) error
+
+	// List retrieves a page of questions, optionally filtered by category, difficulty, language and rating
+	List(ctx context.Context, params *pagination.Params) (pagination.Page[*Question], error)
+
+	// LatestPackVersion returns the latest released version of a language's
+	// pack, or 0 if it has never been released
+	LatestPackVersion(ctx context.Context, language string) (int, error)
+
+	// PublishPack releases a language's unreleased live questions as the
+	// next version of its pack in a single transaction. It returns
+	// ErrNothingToRelease if there are none.
+	PublishPack(ctx context.Context, language string, notes string, at time.Time) (*PackRelease, error)
+
+	// ListPackReleases retrieves the releases, newest first, of one
+	// language's pack or of every pack when language is empty
+	ListPackReleases(ctx context.Context, language string) ([]*PackRelease, error)
+}
+
+// QuestionHistory remembers which questions players were asked recently, so
+// that regulars are not asked the same questions every game night. Players
+// are tracked by ID, which is the user ID for registered players.
+type QuestionHistory interface {
+	// RecordAsked records that players were asked a question
+	RecordAsked(ctx context.Context, playerIDs []string, questionID string) error
+
+	// RecentlyAsked returns the IDs of the questions any of the players were
+	// asked recently
+	RecentlyAsked(ctx context.Context, playerIDs []string) ([]string, error)
+}
+
+// QuestionListOptions describes how question collections may be paginated
+var QuestionListOptions = pagination.Options{
+	SortFields: []string{"created_at", "updated_at"},
+	Filters:    []string{"category", "difficulty", "language", "rating"},
 }
 
 // Question represents a game question
 type Question struct {
-	ID            string    `json:"id"`
-	Text          string    `json:"text"`
-	Answer        string    `json:"answer"`
-	Category      string    `json:"category"`
-	FillerAnswers []string  `json:"filler_answers"` // Pre-defined plausible but incorrect answers
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            string        `json:"id"`
+	Text          string        `json:"text"`
+	Answer        string        `json:"answer"`
+	Category      string        `json:"category"`
+	Difficulty    string        `json:"difficulty,omitempty"`
+	Language      string        `json:"language,omitempty"` // BCP 47 tag of the question's content pack
+	Rating        ContentRating `json:"rating,omitempty"`   // Audience the question's content is suitable for
+	PackVersion   int           `json:"pack_version"`       // Version of its pack it was released in; 0 until released
+	FillerAnswers []string      `json:"filler_answers"`     // Pre-defined plausible but incorrect answers
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	DeletedAt     *time.Time    `json:"deleted_at,omitempty"`
+}
+
+// CategoryCount represents a category and the number of questions in it
+// in one language, with metadata for displaying it
+type CategoryCount struct {
+	Category    string `json:"category"`
+	Language    string `json:"language"`
+	Count       int    `json:"count"`
+	DisplayName string `json:"display_name,omitempty"` // Name in the client's language
+	Direction   string `json:"direction,omitempty"`    // Text direction of the category's content: ltr or rtl
+}
+
+// ImportJobStatus represents the current status of a question import job
+type ImportJobStatus string
+
+const (
+	ImportJobStatusPending   ImportJobStatus = "pending"
+	ImportJobStatusRunning   ImportJobStatus = "running"
+	ImportJobStatusCompleted ImportJobStatus = "completed"
+	ImportJobStatusFailed    ImportJobStatus = "failed" // Every batch failed
+)
+
+// ImportJob tracks the progress of an asynchronous bulk question import
+type ImportJob struct {
+	ID        string          `json:"id"`
+	Status    ImportJobStatus `json:"status"`
+	Total     int             `json:"total"`     // Number of questions submitted
+	Processed int             `json:"processed"` // Number of questions attempted so far
+	Created   int             `json:"created"`   // Number of questions successfully created
+	Batches   int             `json:"batches"`   // Total number of batches
+	Errors    []ImportError   `json:"errors"`    // Errors for failed batches
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// ImportError describes a batch that failed to import
+type ImportError struct {
+	Batch int    `json:"batch"` // Zero-based batch index
+	Error string `json:"error"`
 }
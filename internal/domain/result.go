@@ -0,0 +1,253 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+)
+
+var (
+	// ErrInvalidDateFilter is returned when a date filter cannot be parsed
+	ErrInvalidDateFilter = errors.New("invalid date filter")
+
+	// ErrResultNotFound is returned when no game result matches a share link
+	ErrResultNotFound = errors.New("game results not found")
+)
+
+// GameResult is the final outcome of a game, recorded when it ends
+type GameResult struct {
+	GameID       string         `json:"game_id"`
+	Code         string         `json:"code"`
+	PartyID      string         `json:"party_id,omitempty"`
+	PlayerCount  int            `json:"player_count"`
+	RoundsPlayed int            `json:"rounds_played"`
+	EndedAt      time.Time      `json:"ended_at"`
+	Players      []PlayerResult `json:"players"`
+	Awards       []Award        `json:"awards"`
+	Highlights   *Highlights    `json:"highlights,omitempty"`
+	ShareToken   string         `json:"share_token,omitempty"` // Lets anyone view the public results of the game
+}
+
+// PlayerResult is a player's performance over a whole game
+type PlayerResult struct {
+	PlayerID          string             `json:"player_id"`
+	Name              string             `json:"name"`
+	Position          int                `json:"position"` // 1-based final standing; tied players share a position
+	Points            int                `json:"points"`
+	PlayersFooled     int                `json:"players_fooled"`                // Votes other players gave to this player's answers
+	CorrectVotes      int                `json:"correct_votes"`                 // Votes this player gave to the correct answer
+	FooledByHouse     int                `json:"fooled_by_house"`               // Votes this player gave to filler answers
+	LikesReceived     int                `json:"likes_received"`                // Likes other players gave to this player's answers
+	BestRound         int                `json:"best_round"`                    // Number of the highest-scoring round, 0 if none scored
+	EliminatedInRound int                `json:"eliminated_in_round,omitempty"` // Round after which the player was eliminated, 0 if never
+	Rounds            []RoundPerformance `json:"rounds"`
+}
+
+// RoundPerformance is a player's performance in a single round
+type RoundPerformance struct {
+	Number        int      `json:"number"`
+	Category      string   `json:"category"`
+	Points        int      `json:"points"`
+	PlayersFooled int      `json:"players_fooled"`
+	Fooled        []string `json:"fooled,omitempty"` // IDs of the players who voted for this player's answer
+	CorrectVote   bool     `json:"correct_vote"`
+	SpeedBonus    int      `json:"speed_bonus,omitempty"` // Bonus points for voting for the correct answer fast
+	Likes         int      `json:"likes,omitempty"`       // Likes other players gave to this player's answer
+	LikeBonus     int      `json:"like_bonus,omitempty"`  // Bonus points for writing the most-liked answer
+	FooledByHouse bool     `json:"fooled_by_house"`       // Whether this player voted for a filler answer
+}
+
+// GameHistoryEntry is a past game from one player's point of view
+type GameHistoryEntry struct {
+	GameID       string      `json:"game_id"`
+	Code         string      `json:"code"`
+	PlayerCount  int         `json:"player_count"`
+	RoundsPlayed int         `json:"rounds_played"`
+	EndedAt      time.Time   `json:"ended_at"`
+	Awards       []AwardKind `json:"awards,omitempty"` // Awards the player earned in the game
+	PlayerResult
+}
+
+// GameHistoryFilter restricts game history to games ended within a period.
+// Zero times leave that side of the period open.
+type GameHistoryFilter struct {
+	From time.Time // Inclusive
+	To   time.Time // Exclusive
+}
+
+// Matches reports whether a game that ended at the given time is in the period
+func (f GameHistoryFilter) Matches(endedAt time.Time) bool {
+	return (f.From.IsZero() || !endedAt.Before(f.From)) && (f.To.IsZero() || endedAt.Before(f.To))
+}
+
+// GameHistoryOptions describes how game history may be paginated
+var GameHistoryOptions = pagination.Options{
+	SortFields: []string{"ended_at"},
+	Filters:    []string{"from", "to"},
+}
+
+// GameResultRepository defines the interface for reading recorded game results.
+// Results are written along with the change that ends a game.
+type GameResultRepository interface {
+	// ListByUser retrieves a page of a user's past games
+	ListByUser(ctx context.Context, userID string, filter GameHistoryFilter, params *pagination.Params) (pagination.Page[*GameHistoryEntry], error)
+
+	// ListEnded retrieves the results of games that ended within the period
+	ListEnded(ctx context.Context, filter GameHistoryFilter) ([]*GameResult, error)
+
+	// ListShared retrieves the results of games both users played, oldest
+	// first, with only the two users' player results
+	ListShared(ctx context.Context, userID string, opponentID string) ([]*GameResult, error)
+
+	// GetCareerStats totals a user's results over every game they finished
+	GetCareerStats(ctx context.Context, userID string) (*CareerStats, error)
+
+	// GetShared retrieves the result of the game with the given code that a
+	// share token was issued for. It returns ErrResultNotFound if there is
+	// none.
+	GetShared(ctx context.Context, code string, shareToken string) (*GameResult, error)
+}
+
+// NewGameResult summarizes a game's completed rounds into its result
+func NewGameResult(game *Game, endedAt time.Time) *GameResult {
+	result := &GameResult{
+		GameID:      game.ID,
+		Code:        game.Code,
+		PartyID:     game.PartyID,
+		PlayerCount: len(game.Players),
+		EndedAt:     endedAt,
+		Players:     make([]PlayerResult, 0, len(game.Players)),
+	}
+
+	byPlayer := make(map[string]*PlayerResult, len(game.Players))
+	for _, p := range game.Players {
+		result.Players = append(result.Players, PlayerResult{
+			PlayerID:          p.ID,
+			Name:              p.Name,
+			Points:            p.Score,
+			EliminatedInRound: p.EliminatedInRound,
+			Rounds:            []RoundPerformance{},
+		})
+	}
+	for i := range result.Players {
+		byPlayer[result.Players[i].PlayerID] = &result.Players[i]
+	}
+
+	for _, round := range game.Rounds {
+		if round.Status != RoundStatusCompleted {
+			continue
+		}
+		result.RoundsPlayed++
+
+		performances := roundPerformances(&round, game.Players)
+		for id, player := range byPlayer {
+			perf := performances[id]
+			player.PlayersFooled += perf.PlayersFooled
+			if perf.CorrectVote {
+				player.CorrectVotes++
+			}
+			if perf.FooledByHouse {
+				player.FooledByHouse++
+			}
+			player.LikesReceived += perf.Likes
+			if perf.Points > 0 && (player.BestRound == 0 || perf.Points > player.Rounds[bestRoundIndex(player)].Points) {
+				player.BestRound = perf.Number
+			}
+			player.Rounds = append(player.Rounds, *perf)
+		}
+	}
+
+	// Rank by points, with tied players sharing a position. Players who
+	// were eliminated rank below those who outlasted them.
+	slices.SortStableFunc(result.Players, compareStanding)
+	for i := range result.Players {
+		result.Players[i].Position = i + 1
+		if i > 0 && compareStanding(result.Players[i], result.Players[i-1]) == 0 {
+			result.Players[i].Position = result.Players[i-1].Position
+		}
+	}
+	result.Awards = newAwards(game, result.Players)
+	result.Highlights = game.Highlights
+
+	return result
+}
+
+// compareStanding orders players by how long they lasted, then by points
+func compareStanding(a, b PlayerResult) int {
+	if a.EliminatedInRound != b.EliminatedInRound {
+		// Players never eliminated outlast everyone
+		if a.EliminatedInRound == 0 {
+			return -1
+		}
+		if b.EliminatedInRound == 0 {
+			return 1
+		}
+		return b.EliminatedInRound - a.EliminatedInRound
+	}
+	return b.Points - a.Points
+}
+
+// roundPerformances computes each player's performance in a completed round
+func roundPerformances(round *Round, players []Player) map[string]*RoundPerformance {
+	performances := make(map[string]*RoundPerformance, len(players))
+	for _, p := range players {
+		performances[p.ID] = &RoundPerformance{
+			Number:     round.Number,
+			Category:   round.Category,
+			Points:     round.Points[p.ID],
+			SpeedBonus: round.SpeedBonuses[p.ID],
+			LikeBonus:  round.LikeBonuses[p.ID],
+		}
+	}
+
+	likes := round.LikeCounts()
+	for _, answer := range round.AnswerPool.FakeAnswers {
+		if perf, ok := performances[answer.PlayerID]; ok {
+			perf.Likes += likes[answer.ID]
+		}
+	}
+
+	for _, voterID := range round.AnswerPool.CorrectVotes {
+		if perf, ok := performances[voterID]; ok {
+			perf.CorrectVote = true
+		}
+	}
+	for _, voterID := range round.AnswerPool.FillerVoters() {
+		if perf, ok := performances[voterID]; ok {
+			perf.FooledByHouse = true
+		}
+	}
+	for _, answer := range round.AnswerPool.FakeAnswers {
+		correct := isCorrectAnswer(answer.Text, round.AnswerPool.CorrectAnswer)
+		for _, voterID := range answer.Votes {
+			if correct {
+				if perf, ok := performances[voterID]; ok {
+					perf.CorrectVote = true
+				}
+			} else if voterID != answer.PlayerID {
+				if perf, ok := performances[answer.PlayerID]; ok {
+					perf.PlayersFooled++
+					perf.Fooled = append(perf.Fooled, voterID)
+				}
+			}
+		}
+	}
+
+	return performances
+}
+
+// bestRoundIndex returns the index of a player's best round within their rounds
+func bestRoundIndex(player *PlayerResult) int {
+	return slices.IndexFunc(player.Rounds, func(r RoundPerformance) bool {
+		return r.Number == player.BestRound
+	})
+}
+
+// isCorrectAnswer reports whether a submitted answer matches the correct one
+func isCorrectAnswer(text string, correct string) bool {
+	return correct != "" && strings.EqualFold(strings.TrimSpace(text), strings.TrimSpace(correct))
+}
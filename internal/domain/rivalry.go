@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrSelfRivalry is returned when a user asks for their record against themselves
+var ErrSelfRivalry = errors.New("cannot compare a user with themselves")
+
+// HeadToHead is one user's record against another over the games they both played
+type HeadToHead struct {
+	UserID         string     `json:"user_id"`
+	OpponentID     string     `json:"opponent_id"`
+	Games          int        `json:"games"`
+	Wins           int        `json:"wins"`   // Games the user finished ahead of the opponent
+	Losses         int        `json:"losses"` // Games the opponent finished ahead of the user
+	Draws          int        `json:"draws"`
+	Fooled         int        `json:"fooled"`    // Times the opponent voted for the user's answer
+	FooledBy       int        `json:"fooled_by"` // Times the user voted for the opponent's answer
+	BiggestBlowout *Blowout   `json:"biggest_blowout,omitempty"`
+	LastPlayedAt   *time.Time `json:"last_played_at,omitempty"`
+}
+
+// Blowout is the game with the widest points margin between two players
+type Blowout struct {
+	GameID   string    `json:"game_id"`
+	Code     string    `json:"code"`
+	WinnerID string    `json:"winner_id"`
+	Margin   int       `json:"margin"`
+	EndedAt  time.Time `json:"ended_at"`
+}
+
+// NewHeadToHead compares two users over the results of games they both
+// played. Games recorded before per-voter results were kept count towards the
+// record but not towards the fool counts.
+func NewHeadToHead(userID string, opponentID string, results []*GameResult) *HeadToHead {
+	h := &HeadToHead{UserID: userID, OpponentID: opponentID}
+
+	for _, result := range results {
+		user, opponent := findPlayerResult(result, userID), findPlayerResult(result, opponentID)
+		if user == nil || opponent == nil {
+			continue
+		}
+
+		h.Games++
+		switch {
+		case user.Position < opponent.Position:
+			h.Wins++
+		case user.Position > opponent.Position:
+			h.Losses++
+		default:
+			h.Draws++
+		}
+
+		h.Fooled += countFooled(user, opponentID)
+		h.FooledBy += countFooled(opponent, userID)
+
+		margin, winner := user.Points-opponent.Points, userID
+		if margin < 0 {
+			margin, winner = -margin, opponentID
+		}
+		// Later games win ties, so the most recent blowout is reported
+		if margin > 0 && (h.BiggestBlowout == nil || margin > h.BiggestBlowout.Margin ||
+			(margin == h.BiggestBlowout.Margin && result.EndedAt.After(h.BiggestBlowout.EndedAt))) {
+			h.BiggestBlowout = &Blowout{
+				GameID:   result.GameID,
+				Code:     result.Code,
+				WinnerID: winner,
+				Margin:   margin,
+				EndedAt:  result.EndedAt,
+			}
+		}
+
+		if h.LastPlayedAt == nil || result.EndedAt.After(*h.LastPlayedAt) {
+			endedAt := result.EndedAt
+			h.LastPlayedAt = &endedAt
+		}
+	}
+
+	return h
+}
+
+// findPlayerResult returns a player's result within a game, or nil
+func findPlayerResult(result *GameResult, playerID string) *PlayerResult {
+	for i := range result.Players {
+		if result.Players[i].PlayerID == playerID {
+			return &result.Players[i]
+		}
+	}
+	return nil
+}
+
+// countFooled counts the rounds in which a voter picked the player's answer
+func countFooled(player *PlayerResult, voterID string) int {
+	count := 0
+	for _, round := range player.Rounds {
+		for _, id := range round.Fooled {
+			if id == voterID {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AccountSignalKind is the account action an account signal was recorded on
+type AccountSignalKind string
+
+const (
+	AccountSignalRegistration AccountSignalKind = "registration"
+	AccountSignalLogin        AccountSignalKind = "login"
+)
+
+// AbuseFlag marks an account signal that abuse heuristics found suspicious
+type AbuseFlag string
+
+const (
+	// AbuseFlagDisposableEmail marks an account with a throwaway email address
+	AbuseFlagDisposableEmail AbuseFlag = "disposable_email"
+
+	// AbuseFlagSharedIP marks an IP address that many accounts registered or
+	// logged in from recently
+	AbuseFlagSharedIP AbuseFlag = "shared_ip"
+)
+
+// AccountSignal records where an account registered or logged in from, with
+// the abuse heuristics it tripped, for moderators to act on
+type AccountSignal struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	Kind      AccountSignalKind `json:"kind"`
+	IP        string            `json:"ip"`
+	Country   string            `json:"country,omitempty"` // ISO 3166-1 alpha-2 code, when geolocation is enabled
+	Flags     []AbuseFlag       `json:"flags"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// SharedIP is an IP address that several accounts registered or logged in
+// from
+type SharedIP struct {
+	IP         string    `json:"ip"`
+	Country    string    `json:"country,omitempty"`
+	UserIDs    []string  `json:"user_ids"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// AccountSignalRepository defines the interface for account signal data access
+type AccountSignalRepository interface {
+	// Create records a signal
+	Create(ctx context.Context, signal *AccountSignal) error
+
+	// ListByUser retrieves a user's most recent signals, newest first
+	ListByUser(ctx context.Context, userID string, limit int) ([]*AccountSignal, error)
+
+	// CountAccountsByIP counts the accounts other than excludeUserID with
+	// signals from an IP address since a time
+	CountAccountsByIP(ctx context.Context, ip string, since time.Time, excludeUserID string) (int, error)
+
+	// ListSharedIPs retrieves the IP addresses that at least minAccounts
+	// accounts have signals from since a time, most accounts first
+	ListSharedIPs(ctx context.Context, since time.Time, minAccounts int, limit int) ([]*SharedIP, error)
+}
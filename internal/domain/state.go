@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrIllegalTransition is returned for an event the game's current state
+// does not allow
+var ErrIllegalTransition = errors.New("the game cannot do this in its current state")
+
+// GameState is the step of its flow the game engine is in, derived from the
+// game. Games only move between states by the transitions of
+// stateTransitions, and events that keep a game in its state only happen in
+// the states eventGuards allows them in. Summaries show clients a coarser
+// GamePhase.
+type GameState string
+
+const (
+	GameStateLobby             GameState = "lobby"              // Waiting for players to join
+	GameStateCategorySelection GameState = "category_selection" // A round waits for its category
+	GameStateAnswering         GameState = "answering"          // Players write fake answers to the question
+	GameStateVoting            GameState = "voting"             // Players vote for the answer they think is correct
+	GameStateReveal            GameState = "reveal"             // The round's answers and points are revealed
+	GameStateIntermission      GameState = "intermission"       // Pause before the next round starts itself
+	GameStateFinished          GameState = "finished"           // The game has ended
+)
+
+// stateTransitions lists the states each state can move on to. Any state
+// but the last can end the game, and the host can end a round before it
+// reaches voting.
+var stateTransitions = map[GameState][]GameState{
+	GameStateLobby:             {GameStateCategorySelection, GameStateFinished},
+	GameStateCategorySelection: {GameStateAnswering, GameStateReveal, GameStateFinished},
+	GameStateAnswering:         {GameStateVoting, GameStateReveal, GameStateFinished},
+	GameStateVoting:            {GameStateReveal, GameStateFinished},
+	GameStateReveal:            {GameStateIntermission, GameStateCategorySelection, GameStateFinished},
+	GameStateIntermission:      {GameStateCategorySelection, GameStateFinished},
+	GameStateFinished:          {},
+}
+
+// stateEvents are the events that move a game into a state
+var stateEvents = map[GameEventType]GameState{
+	EventGameStarted:         GameStateCategorySelection,
+	EventRoundStarted:        GameStateCategorySelection,
+	EventCategorySelected:    GameStateAnswering,
+	EventVotingStarted:       GameStateVoting,
+	EventRoundEnded:          GameStateReveal,
+	EventIntermissionStarted: GameStateIntermission,
+	EventGameEnded:           GameStateFinished,
+}
+
+// eventGuards are the states the events that keep a game in its state can
+// happen in. Events missing from both tables, such as connection changes,
+// can happen in any state.
+var eventGuards = map[GameEventType][]GameState{
+	EventPlayerJoined:      {GameStateLobby},
+	EventPlayerReady:       {GameStateLobby},
+	EventCountdownStarted:  {GameStateLobby},
+	EventCountdownCanceled: {GameStateLobby},
+	EventTurnStarted:       {GameStateCategorySelection},
+	EventAnswerSubmitted:   {GameStateAnswering},
+	EventAnswerReplaced:    {GameStateAnswering},
+	EventAnswerModerated:   {GameStateAnswering},
+	EventFillersAdded:      {GameStateAnswering},
+	EventVoteCast:          {GameStateVoting},
+	EventAudienceVoted:     {GameStateVoting},
+	EventPlayerEliminated:  {GameStateReveal},
+	EventAnswerLiked:       {GameStateIntermission},
+	EventLikesTallied:      {GameStateReveal, GameStateIntermission},
+}
+
+// State returns the state the game is in
+func (g *Game) State() GameState {
+	switch g.Status {
+	case GameStatusWaiting:
+		return GameStateLobby
+	case GameStatusEnded:
+		return GameStateFinished
+	}
+	if g.Intermission != nil {
+		return GameStateIntermission
+	}
+	if len(g.Rounds) == 0 {
+		return GameStateCategorySelection
+	}
+
+	round := g.Rounds[len(g.Rounds)-1]
+	switch {
+	case round.Status == RoundStatusCompleted:
+		return GameStateReveal
+	case round.Status == RoundStatusVoting:
+		return GameStateVoting
+	case round.Status == RoundStatusActive, round.QuestionID != "":
+		return GameStateAnswering
+	default:
+		return GameStateCategorySelection
+	}
+}
+
+// CanTransition reports whether a game can move from the state to another
+func (p GameState) CanTransition(to GameState) bool {
+	return slices.Contains(stateTransitions[p], to)
+}
+
+// Transition checks that an event can happen in the game's current state,
+// returning the state it is in and the state the event leaves it in. Events
+// that do not change the state leave it where it is.
+func (g *Game) Transition(eventType GameEventType) (from GameState, to GameState, err error) {
+	// A game's creation is where its flow starts
+	if eventType == EventGameCreated {
+		return "", GameStateLobby, nil
+	}
+
+	from = g.State()
+	if next, ok := stateEvents[eventType]; ok {
+		if !from.CanTransition(next) {
+			return from, from, fmt.Errorf("%w: %s in %s", ErrIllegalTransition, eventType, from)
+		}
+		return from, next, nil
+	}
+	if states, ok := eventGuards[eventType]; ok && !slices.Contains(states, from) {
+		return from, from, fmt.Errorf("%w: %s in %s", ErrIllegalTransition, eventType, from)
+	}
+	return from, from, nil
+}
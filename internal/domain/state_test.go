@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+// gameIn returns a game in the given state
+func gameIn(state GameState) *Game {
+	game := &Game{Status: GameStatusPlaying}
+	switch state {
+	case GameStateLobby:
+		game.Status = GameStatusWaiting
+	case GameStateFinished:
+		game.Status = GameStatusEnded
+	case GameStateCategorySelection:
+		game.Rounds = []Round{{Number: 1, Status: RoundStatusWaiting}}
+	case GameStateAnswering:
+		game.Rounds = []Round{{Number: 1, Status: RoundStatusActive, QuestionID: "question-1"}}
+	case GameStateVoting:
+		game.Rounds = []Round{{Number: 1, Status: RoundStatusVoting, QuestionID: "question-1"}}
+	case GameStateReveal:
+		game.Rounds = []Round{{Number: 1, Status: RoundStatusCompleted, QuestionID: "question-1"}}
+	case GameStateIntermission:
+		game.Rounds = []Round{{Number: 1, Status: RoundStatusCompleted, QuestionID: "question-1"}}
+		game.Intermission = &Intermission{}
+	}
+	return game
+}
+
+func TestGameState(t *testing.T) {
+	for state := range stateTransitions {
+		if got := gameIn(state).State(); got != state {
+			t.Errorf("game in %s: State() = %s", state, got)
+		}
+	}
+
+	tests := []struct {
+		name string
+		game *Game
+		want GameState
+	}{
+		{"started without rounds", &Game{Status: GameStatusPlaying}, GameStateCategorySelection},
+		{"question drawn before the round starts", &Game{Status: GameStatusPlaying, Rounds: []Round{{Status: RoundStatusWaiting, QuestionID: "question-1"}}}, GameStateAnswering},
+		{"ended during an intermission", &Game{Status: GameStatusEnded, Intermission: &Intermission{}}, GameStateFinished},
+		{"later round waiting for its category", &Game{Status: GameStatusPlaying, Rounds: []Round{{Status: RoundStatusCompleted}, {Status: RoundStatusWaiting}}}, GameStateCategorySelection},
+	}
+	for _, tt := range tests {
+		if got := tt.game.State(); got != tt.want {
+			t.Errorf("%s: State() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTransitionAllowed(t *testing.T) {
+	tests := []struct {
+		from  GameState
+		event GameEventType
+		to    GameState
+	}{
+		{GameStateLobby, EventPlayerJoined, GameStateLobby},
+		{GameStateLobby, EventPlayerReady, GameStateLobby},
+		{GameStateLobby, EventCountdownStarted, GameStateLobby},
+		{GameStateLobby, EventCountdownCanceled, GameStateLobby},
+		{GameStateLobby, EventGameStarted, GameStateCategorySelection},
+		{GameStateLobby, EventGameEnded, GameStateFinished},
+		{GameStateCategorySelection, EventTurnStarted, GameStateCategorySelection},
+		{GameStateCategorySelection, EventCategorySelected, GameStateAnswering},
+		{GameStateCategorySelection, EventRoundEnded, GameStateReveal},
+		{GameStateAnswering, EventAnswerSubmitted, GameStateAnswering},
+		{GameStateAnswering, EventAnswerReplaced, GameStateAnswering},
+		{GameStateAnswering, EventAnswerModerated, GameStateAnswering},
+		{GameStateAnswering, EventFillersAdded, GameStateAnswering},
+		{GameStateAnswering, EventVotingStarted, GameStateVoting},
+		{GameStateAnswering, EventRoundEnded, GameStateReveal},
+		{GameStateVoting, EventVoteCast, GameStateVoting},
+		{GameStateVoting, EventAudienceVoted, GameStateVoting},
+		{GameStateVoting, EventRoundEnded, GameStateReveal},
+		{GameStateReveal, EventPlayerEliminated, GameStateReveal},
+		{GameStateReveal, EventLikesTallied, GameStateReveal},
+		{GameStateReveal, EventIntermissionStarted, GameStateIntermission},
+		{GameStateReveal, EventRoundStarted, GameStateCategorySelection},
+		{GameStateReveal, EventGameEnded, GameStateFinished},
+		{GameStateIntermission, EventAnswerLiked, GameStateIntermission},
+		{GameStateIntermission, EventLikesTallied, GameStateIntermission},
+		{GameStateIntermission, EventRoundStarted, GameStateCategorySelection},
+		{GameStateIntermission, EventGameEnded, GameStateFinished},
+		{GameStateFinished, EventPlayerDisconnected, GameStateFinished},
+		{GameStateVoting, EventPlayerReconnected, GameStateVoting},
+	}
+	for _, tt := range tests {
+		from, to, err := gameIn(tt.from).Transition(tt.event)
+		if err != nil {
+			t.Errorf("%s in %s: unexpected error %v", tt.event, tt.from, err)
+			continue
+		}
+		if from != tt.from || to != tt.to {
+			t.Errorf("%s in %s: Transition() = %s -> %s, want %s -> %s", tt.event, tt.from, from, to, tt.from, tt.to)
+		}
+	}
+}
+
+func TestTransitionRejected(t *testing.T) {
+	tests := []struct {
+		from  GameState
+		event GameEventType
+	}{
+		{GameStateLobby, EventCategorySelected},
+		{GameStateLobby, EventRoundEnded},
+		{GameStateLobby, EventAnswerSubmitted},
+		{GameStateCategorySelection, EventGameStarted},
+		{GameStateCategorySelection, EventVotingStarted},
+		{GameStateCategorySelection, EventPlayerJoined},
+		{GameStateAnswering, EventVoteCast},
+		{GameStateAnswering, EventIntermissionStarted},
+		{GameStateAnswering, EventTurnStarted},
+		{GameStateVoting, EventAnswerSubmitted},
+		{GameStateVoting, EventCategorySelected},
+		{GameStateVoting, EventRoundStarted},
+		{GameStateReveal, EventVoteCast},
+		{GameStateReveal, EventAnswerLiked},
+		{GameStateReveal, EventVotingStarted},
+		{GameStateIntermission, EventRoundEnded},
+		{GameStateIntermission, EventPlayerEliminated},
+		{GameStateIntermission, EventIntermissionStarted},
+		{GameStateFinished, EventGameStarted},
+		{GameStateFinished, EventGameEnded},
+		{GameStateFinished, EventPlayerReady},
+	}
+	for _, tt := range tests {
+		from, to, err := gameIn(tt.from).Transition(tt.event)
+		if !errors.Is(err, ErrIllegalTransition) {
+			t.Errorf("%s in %s: error = %v, want ErrIllegalTransition", tt.event, tt.from, err)
+		}
+		if from != tt.from || to != tt.from {
+			t.Errorf("%s in %s: Transition() = %s -> %s, want the game left in %s", tt.event, tt.from, from, to, tt.from)
+		}
+	}
+}
+
+func TestTransitionFromFinished(t *testing.T) {
+	game := gameIn(GameStateFinished)
+	for event := range stateEvents {
+		if _, _, err := game.Transition(event); !errors.Is(err, ErrIllegalTransition) {
+			t.Errorf("%s in finished: error = %v, want ErrIllegalTransition", event, err)
+		}
+	}
+}
+
+func TestTransitionGameCreated(t *testing.T) {
+	from, to, err := gameIn(GameStateFinished).Transition(EventGameCreated)
+	if err != nil || from != "" || to != GameStateLobby {
+		t.Errorf("Transition(%s) = %q -> %q, %v, want the lobby", EventGameCreated, from, to, err)
+	}
+}
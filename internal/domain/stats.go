@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInvalidStatsPeriod is returned for an unknown rollup period
+var ErrInvalidStatsPeriod = errors.New("invalid stats period")
+
+// StatsPeriod is the length of time a stats rollup covers
+type StatsPeriod string
+
+const (
+	StatsPeriodWeek  StatsPeriod = "week"  // Monday to Sunday, UTC
+	StatsPeriodMonth StatsPeriod = "month" // Calendar month, UTC
+)
+
+// StatsPeriods lists the periods rollups are kept for
+var StatsPeriods = []StatsPeriod{StatsPeriodWeek, StatsPeriodMonth}
+
+// ParseStatsPeriod validates a period name
+func ParseStatsPeriod(s string) (StatsPeriod, error) {
+	switch period := StatsPeriod(s); period {
+	case StatsPeriodWeek, StatsPeriodMonth:
+		return period, nil
+	default:
+		return "", ErrInvalidStatsPeriod
+	}
+}
+
+// Start returns the start of the period containing t
+func (p StatsPeriod) Start(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if p == StatsPeriodMonth {
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	// Weeks start on Monday
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// Next returns the start of the period following the one starting at start
+func (p StatsPeriod) Next(start time.Time) time.Time {
+	if p == StatsPeriodMonth {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 7)
+}
+
+// StatsRollup summarizes a user's games over a period
+type StatsRollup struct {
+	UserID           string      `json:"user_id"`
+	Period           StatsPeriod `json:"period"`
+	PeriodStart      time.Time   `json:"period_start"`
+	Games            int         `json:"games"`
+	Wins             int         `json:"wins"`
+	TotalPoints      int         `json:"total_points"`
+	AverageScore     float64     `json:"average_score"`
+	FavoriteCategory string      `json:"favorite_category,omitempty"` // Category of the most rounds played
+	UpdatedAt        time.Time   `json:"updated_at"`
+}
+
+// StatsRollupRepository defines the interface for stats rollup operations
+type StatsRollupRepository interface {
+	// Upsert creates or replaces rollups
+	Upsert(ctx context.Context, rollups []*StatsRollup) error
+
+	// ListByUser retrieves a user's most recent rollups for a period, newest first
+	ListByUser(ctx context.Context, userID string, period StatsPeriod, limit int) ([]*StatsRollup, error)
+}
+
+// NewStatsRollups summarizes the results of games that ended in the period
+// starting at start into a rollup per player
+func NewStatsRollups(period StatsPeriod, start time.Time, results []*GameResult, now time.Time) []*StatsRollup {
+	byUser := make(map[string]*StatsRollup)
+	categories := make(map[string]map[string]int)
+	var order []string
+
+	for _, result := range results {
+		for _, player := range result.Players {
+			rollup, ok := byUser[player.PlayerID]
+			if !ok {
+				rollup = &StatsRollup{
+					UserID:      player.PlayerID,
+					Period:      period,
+					PeriodStart: start,
+					UpdatedAt:   now,
+				}
+				byUser[player.PlayerID] = rollup
+				categories[player.PlayerID] = make(map[string]int)
+				order = append(order, player.PlayerID)
+			}
+
+			rollup.Games++
+			rollup.TotalPoints += player.Points
+			if player.Position == 1 {
+				rollup.Wins++
+			}
+			for _, round := range player.Rounds {
+				if round.Category != "" {
+					categories[player.PlayerID][round.Category]++
+				}
+			}
+		}
+	}
+
+	rollups := make([]*StatsRollup, 0, len(order))
+	for _, userID := range order {
+		rollup := byUser[userID]
+		rollup.AverageScore = float64(rollup.TotalPoints) / float64(rollup.Games)
+		rollup.FavoriteCategory = favoriteCategory(categories[userID])
+		rollups = append(rollups, rollup)
+	}
+	return rollups
+}
+
+// favoriteCategory returns the most played category, breaking ties alphabetically
+func favoriteCategory(counts map[string]int) string {
+	favorite, best := "", 0
+	for category, n := range counts {
+		if n > best || (n == best && category < favorite) {
+			favorite, best = category, n
+		}
+	}
+	return favorite
+}
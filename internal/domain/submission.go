@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrSubmissionNotFound is returned when a question submission does not exist
+var ErrSubmissionNotFound = errors.New("question submission not found")
+
+// SubmissionStatus is where a question submission is in review
+type SubmissionStatus string
+
+const (
+	SubmissionStatusPending  SubmissionStatus = "pending"  // Waiting in the moderation queue
+	SubmissionStatusApproved SubmissionStatus = "approved" // Added to the questions
+	SubmissionStatusRejected SubmissionStatus = "rejected"
+)
+
+// QuestionSubmission is a question a user contributed. Submissions from
+// trusted contributors are approved as they are made; the others wait in
+// the moderation queue for a moderator to review them.
+type QuestionSubmission struct {
+	ID         string           `json:"id"`
+	UserID     string           `json:"user_id"`
+	Question   Question         `json:"question"` // Its ID is set once the submission is approved
+	Status     SubmissionStatus `json:"status"`
+	ReviewedBy string           `json:"reviewed_by,omitempty"` // Moderator who reviewed it; empty when approved on trust
+	ReviewNote string           `json:"review_note,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	ReviewedAt *time.Time       `json:"reviewed_at,omitempty"`
+}
+
+// TrustLevel is how far a contributor's submissions are trusted
+type TrustLevel string
+
+const (
+	TrustLevelNew         TrustLevel = "new"         // No approved submissions yet
+	TrustLevelContributor TrustLevel = "contributor" // Approved submissions, but not enough to be trusted
+	TrustLevelTrusted     TrustLevel = "trusted"     // Submissions skip the moderation queue
+)
+
+// TrustThresholds are what a contributor needs to be trusted. They are
+// configured by admins.
+type TrustThresholds struct {
+	MinApproved        int       `json:"min_approved"`         // Approved submissions needed
+	MaxRejectedPercent int       `json:"max_rejected_percent"` // Largest share of reviewed submissions that may have been rejected
+	MinAccountDays     int       `json:"min_account_days"`     // Age the contributor's account needs, in days
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// DefaultTrustThresholds returns the thresholds in force until admins
+// configure their own
+func DefaultTrustThresholds() TrustThresholds {
+	return TrustThresholds{
+		MinApproved:        10,
+		MaxRejectedPercent: 20,
+		MinAccountDays:     14,
+	}
+}
+
+// ContributorReputation is a user's record as a contributor of questions
+type ContributorReputation struct {
+	UserID   string     `json:"user_id"`
+	Approved int        `json:"approved"`
+	Rejected int        `json:"rejected"`
+	Pending  int        `json:"pending"`
+	Level    TrustLevel `json:"level"`
+}
+
+// Level returns the trust level earned with a record of submissions on an
+// account created at a time
+func (t TrustThresholds) Level(approved int, rejected int, accountCreated time.Time, now time.Time) TrustLevel {
+	if approved == 0 {
+		return TrustLevelNew
+	}
+	if approved < t.MinApproved ||
+		rejected*100 > t.MaxRejectedPercent*(approved+rejected) ||
+		now.Sub(accountCreated) < time.Duration(t.MinAccountDays)*24*time.Hour {
+		return TrustLevelContributor
+	}
+	return TrustLevelTrusted
+}
+
+// QuestionSubmissionRepository defines the interface for question
+// submission operations
+type QuestionSubmissionRepository interface {
+	// Create creates a submission
+	Create(ctx context.Context, submission *QuestionSubmission) error
+
+	// Get retrieves a submission by ID
+	Get(ctx context.Context, id string) (*QuestionSubmission, error)
+
+	// ListByStatus retrieves the oldest submissions with a status first
+	ListByStatus(ctx context.Context, status SubmissionStatus, limit int) ([]*QuestionSubmission, error)
+
+	// ListByUser retrieves a user's submissions, newest first
+	ListByUser(ctx context.Context, userID string, limit int) ([]*QuestionSubmission, error)
+
+	// Review records a moderator's review of a pending submission. It
+	// returns ErrSubmissionNotFound if no pending submission has its ID.
+	Review(ctx context.Context, submission *QuestionSubmission) error
+
+	// CountByUser counts a user's submissions by status
+	CountByUser(ctx context.Context, userID string) (map[SubmissionStatus]int, error)
+
+	// GetTrustThresholds retrieves the thresholds for trusting contributors
+	GetTrustThresholds(ctx context.Context) (*TrustThresholds, error)
+
+	// SaveTrustThresholds replaces the thresholds for trusting contributors
+	SaveTrustThresholds(ctx context.Context, thresholds *TrustThresholds) error
+}
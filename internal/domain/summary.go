@@ -0,0 +1,99 @@
+package domain
+
+// GamePhase is the stage of play a game is in, as shown on lobby screens
+type GamePhase string
+
+const (
+	GamePhaseLobby             GamePhase = "lobby"              // Waiting for the game to start
+	GamePhaseCountdown         GamePhase = "countdown"          // Counting down to the game starting itself
+	GamePhaseCategorySelection GamePhase = "category_selection" // A player is choosing the round's category
+	GamePhaseAnswering         GamePhase = "answering"          // Players are writing answers to the question
+	GamePhaseVoting            GamePhase = "voting"             // Players are voting on the answers
+	GamePhaseRoundResults      GamePhase = "round_results"      // The round is over and its scores are in
+	GamePhaseEnded             GamePhase = "ended"              // The game is over
+)
+
+// GameSummary is a small read model of a game for clients that poll it
+// instead of holding a WebSocket connection, such as lobby screens and
+// smart TVs
+type GameSummary struct {
+	Code    string          `json:"code"`
+	Status  GameStatus      `json:"status"`
+	Phase   GamePhase       `json:"phase"`
+	Round   int             `json:"round,omitempty"`  // Number of the current round, once playing
+	Rounds  int             `json:"rounds"`           // Number of rounds the game is played for
+	Timer   *Timer          `json:"timer,omitempty"`  // Timer of the current phase, if it is timed
+	Region  string          `json:"region,omitempty"` // Region the game is hosted in, if known
+	Players []PlayerSummary `json:"players"`
+}
+
+// PlayerSummary is a player's entry in a game summary's roster
+type PlayerSummary struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Connected bool   `json:"connected"`
+	Score     int    `json:"score"`
+}
+
+// Summary returns the game's summary
+func (g *Game) Summary() *GameSummary {
+	summary := &GameSummary{
+		Code:    g.Code,
+		Status:  g.Status,
+		Rounds:  g.Settings.RoundCount(),
+		Players: make([]PlayerSummary, 0, len(g.Players)),
+	}
+	if g.Origin != nil {
+		summary.Region = g.Origin.Region
+	}
+	for _, p := range g.Players {
+		summary.Players = append(summary.Players, PlayerSummary{
+			ID:        p.ID,
+			Name:      p.Name,
+			Connected: p.IsConnected,
+			Score:     p.Score,
+		})
+	}
+
+	switch {
+	case g.Status == GameStatusEnded:
+		summary.Phase = GamePhaseEnded
+	case g.Status == GameStatusWaiting && g.Countdown != nil:
+		summary.Phase = GamePhaseCountdown
+		summary.Timer = g.Countdown
+	case g.Status == GameStatusWaiting || len(g.Rounds) == 0:
+		summary.Phase = GamePhaseLobby
+	default:
+		round := g.Rounds[len(g.Rounds)-1]
+		summary.Round = round.Number
+		summary.Phase, summary.Timer = roundPhase(round)
+	}
+
+	return summary
+}
+
+// roundPhase returns the phase a round is in and the timer running it
+func roundPhase(round Round) (GamePhase, *Timer) {
+	switch {
+	case round.Status == RoundStatusCompleted:
+		return GamePhaseRoundResults, nil
+	case round.Status == RoundStatusVoting:
+		return GamePhaseVoting, round.Timer
+	case round.QuestionID != "":
+		return GamePhaseAnswering, round.Timer
+	case round.CurrentTurn != nil:
+		return GamePhaseCategorySelection, round.CurrentTurn.Timer
+	default:
+		return GamePhaseCategorySelection, nil
+	}
+}
+
+// DisplayView is the state of a game shown on a shared screen in display
+// mode. Answers stay anonymous, and the correct answer hidden, until the
+// round's recap.
+type DisplayView struct {
+	*GameSummary
+	CurrentRound *RoundView     `json:"current_round,omitempty"`
+	Tally        map[string]int `json:"tally,omitempty"` // Votes for each option by ID, once voting starts
+	Intermission *Intermission  `json:"intermission,omitempty"`
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTemplateNotFound is returned when no template has the requested ID
+var ErrTemplateNotFound = errors.New("game template not found")
+
+// GameTemplate is a shareable game configuration: its settings, including
+// the category packs and mode flags, under a name and description. Unlike
+// presets, templates are looked up by ID, so anyone given the ID can create a
+// game from one. Users own the templates they create; global templates are
+// curated by administrators and listed for everyone.
+type GameTemplate struct {
+	ID          string       `json:"id"`
+	OwnerID     string       `json:"owner_id,omitempty"` // Empty for global templates
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Settings    GameSettings `json:"settings"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// IsGlobal returns whether the template is curated for everyone rather than
+// owned by a user
+func (t *GameTemplate) IsGlobal() bool {
+	return t.OwnerID == ""
+}
+
+// GameTemplateRepository defines the interface for game template data access
+type GameTemplateRepository interface {
+	// Create creates a template
+	Create(ctx context.Context, template *GameTemplate) error
+
+	// Get retrieves a template by ID
+	Get(ctx context.Context, id string) (*GameTemplate, error)
+
+	// ListVisible retrieves the global templates followed by the user's own,
+	// each ordered by name
+	ListVisible(ctx context.Context, userID string) ([]*GameTemplate, error)
+
+	// Update replaces a template's name, description and settings
+	Update(ctx context.Context, template *GameTemplate) error
+
+	// Delete removes a template
+	Delete(ctx context.Context, id string) error
+}
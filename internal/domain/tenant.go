@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Tenant errors
+var (
+	ErrTenantNotFound   = errors.New("organization not found")
+	ErrSSONotConfigured = errors.New("organization has no single sign-on configured")
+)
+
+// Tenant is an organization, such as a company running team-building events,
+// whose members play among themselves. Games hosted by members are private to
+// the organization, which can also have premium packs of its own and a
+// leaderboard only its members see. Users outside any organization play as
+// they always have.
+type Tenant struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TenantRole is a member's role in their organization
+type TenantRole string
+
+const (
+	TenantRoleMember TenantRole = "member"
+	TenantRoleAdmin  TenantRole = "admin" // Manages the organization's members and private packs
+)
+
+// TenantRoles lists the valid tenant roles
+var TenantRoles = []TenantRole{TenantRoleMember, TenantRoleAdmin}
+
+// SSOConfig is how an organization's members sign in with the organization's
+// OpenID Connect identity provider. Members signing in for the first time
+// get an account on the spot, and their role can follow a claim the provider
+// sends, such as the groups they are in. SAML is not supported: providers
+// that only speak SAML, such as older ADFS setups, need an OpenID Connect
+// bridge in front of them.
+type SSOConfig struct {
+	TenantID     string                `json:"tenant_id"`
+	Issuer       string                `json:"issuer"` // URL the provider's endpoints are discovered from
+	ClientID     string                `json:"client_id"`
+	ClientSecret string                `json:"-"`                      // Never returned once saved
+	RoleClaim    string                `json:"role_claim,omitempty"`   // ID token claim roles follow, such as "groups"; none leaves roles to the API
+	RoleMapping  map[string]TenantRole `json:"role_mapping,omitempty"` // Roles granted by values of the role claim
+	Enabled      bool                  `json:"enabled"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+}
+
+// MapRole returns the role the role claim's values grant: admin if any of
+// them maps to it, member otherwise
+func (c *SSOConfig) MapRole(values []string) TenantRole {
+	for _, value := range values {
+		if c.RoleMapping[value] == TenantRoleAdmin {
+			return TenantRoleAdmin
+		}
+	}
+	return TenantRoleMember
+}
+
+// SSOLogin is what a client needs to send an organization's members to
+// their identity provider: it discovers the provider's endpoints from the
+// issuer and requests a code for the client ID
+type SSOLogin struct {
+	TenantID   string `json:"tenant_id"`
+	TenantName string `json:"tenant_name"`
+	Issuer     string `json:"issuer"`
+	ClientID   string `json:"client_id"`
+}
+
+// SSOProvider returns the provider name the outside accounts of an
+// organization's identity provider are linked under, which keeps subjects of
+// different organizations apart
+func SSOProvider(tenantID string) string {
+	return "sso:" + tenantID
+}
+
+// LeaderboardEntry is a member's standing on their organization's
+// leaderboard. Members with the same wins and points share a position.
+type LeaderboardEntry struct {
+	Position    int    `json:"position"`
+	UserID      string `json:"user_id"`
+	DisplayName string `json:"display_name"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+	GamesPlayed int    `json:"games_played"`
+	GamesWon    int    `json:"games_won"`
+	TotalPoints int    `json:"total_points"`
+}
+
+// TenantRepository defines the interface for organizations. Their members
+// are kept on the users themselves.
+type TenantRepository interface {
+	// Save creates or replaces an organization
+	Save(ctx context.Context, tenant *Tenant) error
+
+	// Get retrieves an organization by ID
+	Get(ctx context.Context, id string) (*Tenant, error)
+
+	// List retrieves every organization, ordered by ID
+	List(ctx context.Context) ([]*Tenant, error)
+
+	// SaveSSO creates or replaces an organization's single sign-on
+	// configuration
+	SaveSSO(ctx context.Context, config *SSOConfig) error
+
+	// GetSSO retrieves an organization's single sign-on configuration. It
+	// returns ErrSSONotConfigured if it has none.
+	GetSSO(ctx context.Context, tenantID string) (*SSOConfig, error)
+
+	// DeleteSSO removes an organization's single sign-on configuration. It
+	// returns ErrSSONotConfigured if it has none.
+	DeleteSSO(ctx context.Context, tenantID string) error
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
 )
 
 // Common errors
@@ -11,20 +13,86 @@ var (
 	ErrUserNotFound       = errors.New("user not found")
 	ErrUserAlreadyExists  = errors.New("user already exists")
 	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInviteNotFound     = errors.New("invitation not found")
+	ErrInviteNotPending   = errors.New("invitation is no longer pending")
+	ErrInvalidSession     = errors.New("invalid session token")
 )
 
+// DeletedPlayerName replaces the display name of deleted users, including in
+// the game history they leave behind
+const DeletedPlayerName = "Deleted player"
+
 // User represents a registered user
 type User struct {
-	ID           string    `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"` // Never expose password hash
-	DisplayName  string    `json:"display_name"`
-	AvatarURL    string    `json:"avatar_url,omitempty"`
-	Stats        UserStats `json:"stats"`
-	LastLoginAt  time.Time `json:"last_login_at"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           string          `json:"id"`
+	Username     string          `json:"username"`
+	Email        string          `json:"email"`
+	PasswordHash string          `json:"-"` // Never expose password hash
+	DisplayName  string          `json:"display_name"`
+	Role         UserRole        `json:"role"`
+	AvatarURL    string          `json:"avatar_url,omitempty"`
+	Verified     bool            `json:"verified"`              // Whether the user has verified their email address
+	TenantID     string          `json:"tenant_id,omitempty"`   // Organization the user belongs to, if any
+	TenantRole   TenantRole      `json:"tenant_role,omitempty"` // The user's role in their organization
+	Stats        UserStats       `json:"stats"`
+	Preferences  UserPreferences `json:"-"` // Only returned to the user themselves
+	LastLoginAt  time.Time       `json:"last_login_at"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// UserRole represents a user's access level
+type UserRole string
+
+const (
+	UserRoleUser  UserRole = "user"
+	UserRoleAdmin UserRole = "admin"
+)
+
+// IsTenantAdmin reports whether the user administers an organization
+func (u *User) IsTenantAdmin() bool {
+	return u.TenantID != "" && u.TenantRole == TenantRoleAdmin
+}
+
+// ColorTheme is the color scheme a user's clients are shown in
+type ColorTheme string
+
+const (
+	ColorThemeSystem ColorTheme = "system" // Follows the device's setting
+	ColorThemeLight  ColorTheme = "light"
+	ColorThemeDark   ColorTheme = "dark"
+)
+
+// ColorThemes lists the valid color themes
+var ColorThemes = []ColorTheme{ColorThemeSystem, ColorThemeLight, ColorThemeDark}
+
+// UserPreferences are a user's client settings, kept on their account so
+// that they follow the user across devices
+type UserPreferences struct {
+	Sound         bool                    `json:"sound"`                   // Whether game sounds play
+	Language      string                  `json:"language,omitempty"`      // Preferred language as a BCP 47 tag, the client's own when empty
+	Theme         ColorTheme              `json:"theme"`                   // Color theme of the user's clients
+	GameSettings  *GameSettings           `json:"game_settings,omitempty"` // Settings the user's new games start from, the defaults when nil
+	Notifications NotificationPreferences `json:"notifications"`
+	HallOfFame    bool                    `json:"hall_of_fame"`     // Whether the user's well-liked answers may be shown in the hall of fame
+	Region        string                  `json:"region,omitempty"` // Region the user prefers games hosted in, such as eu-west-1
+}
+
+// NotificationPreferences are the notifications a user has opted in to
+type NotificationPreferences struct {
+	GameInvites bool `json:"game_invites"` // Invitations to games, pushed as they are sent
+}
+
+// DefaultUserPreferences returns the preferences of users who have not
+// changed any
+func DefaultUserPreferences() UserPreferences {
+	return UserPreferences{
+		Sound: true,
+		Theme: ColorThemeSystem,
+		Notifications: NotificationPreferences{
+			GameInvites: true,
+		},
+	}
 }
 
 // UserStats represents user's game statistics
@@ -39,6 +107,25 @@ type UserStats struct {
 	CorrectVotes  int `json:"correct_votes"`
 }
 
+// UserProfile is a user together with their per-category performance and
+// career totals
+type UserProfile struct {
+	*User
+	Categories []*CategoryStats `json:"categories"` // Most played first
+	Career     *CareerStats     `json:"career"`     // Totals over every game they finished, likes received included
+}
+
+// UserListOptions describes how user collections may be paginated
+var UserListOptions = pagination.Options{
+	SortFields: []string{"created_at", "updated_at"},
+	Filters:    []string{"role", "tenant_id"},
+}
+
+// InviteListOptions describes how invitation collections may be paginated
+var InviteListOptions = pagination.Options{
+	SortFields: []string{"created_at", "expires_at"},
+}
+
 // UserRepository defines the interface for user-related operations
 type UserRepository interface {
 	// Create creates a new user
@@ -53,23 +140,61 @@ type UserRepository interface {
 	// GetByEmail retrieves a user by their email
 	GetByEmail(ctx context.Context, email string) (*User, error)
 
+	// GetByIdentity retrieves the user an outside account is linked to
+	GetByIdentity(ctx context.Context, provider string, subject string) (*User, error)
+
+	// LinkIdentity links an outside account to a user, so they can sign in
+	// with it
+	LinkIdentity(ctx context.Context, userID string, provider string, subject string) error
+
 	// Update updates a user's information
 	Update(ctx context.Context, user *User) error
 
-	// Delete deletes a user
+	// Delete soft-deletes a user, anonymizing their account and the names
+	// they played under. Deleted users are not found by lookups. It returns
+	// ErrUserNotFound if no user has the ID.
 	Delete(ctx context.Context, id string) error
 
+	// PurgeDeleted permanently removes up to limit users deleted before the
+	// cutoff, along with their invitations, stats and presets, returning how
+	// many were removed
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error)
+
 	// UpdateStats updates a user's game statistics
 	UpdateStats(ctx context.Context, id string, stats UserStats) error
+
+	// UpdatePreferences replaces a user's preferences
+	UpdatePreferences(ctx context.Context, id string, preferences UserPreferences) error
+
+	// SetTenant moves a user into an organization with a role, or out of
+	// theirs when tenantID is empty. Update leaves a user's organization as
+	// it is. It returns ErrUserNotFound if no user has the ID.
+	SetTenant(ctx context.Context, id string, tenantID string, role TenantRole) error
+
+	// ListByTenant retrieves the members of an organization, ordered by
+	// display name
+	ListByTenant(ctx context.Context, tenantID string) ([]*User, error)
+
+	// List retrieves a page of users, optionally filtered by role or
+	// organization
+	List(ctx context.Context, params *pagination.Params) (pagination.Page[*User], error)
 }
 
+// Game invitation statuses
+const (
+	InviteStatusPending  = "pending"
+	InviteStatusAccepted = "accepted"
+	InviteStatusDeclined = "declined"
+	InviteStatusExpired  = "expired" // Set by the cleanup job once an invite lapses unanswered
+)
+
 // GameInvite represents a game invitation
 type GameInvite struct {
 	ID        string    `json:"id"`
 	GameID    string    `json:"game_id"`
 	FromUser  string    `json:"from_user"` // User ID of the sender
 	ToUser    string    `json:"to_user"`   // User ID of the recipient
-	Status    string    `json:"status"`    // pending, accepted, declined
+	Status    string    `json:"status"`    // pending, accepted, declined, expired
 	CreatedAt time.Time `json:"created_at"`
 	ExpiresAt time.Time `json:"expires_at"`
 }
@@ -82,12 +207,46 @@ type GameInviteRepository interface {
 	// GetByID retrieves an invitation by its ID
 	GetByID(ctx context.Context, id string) (*GameInvite, error)
 
-	// GetPendingInvites retrieves all pending invitations for a user
-	GetPendingInvites(ctx context.Context, userID string) ([]*GameInvite, error)
+	// GetPendingInvites retrieves a page of a user's unexpired pending
+	// invitations
+	GetPendingInvites(ctx context.Context, userID string, params *pagination.Params) (pagination.Page[*GameInvite], error)
 
 	// UpdateStatus updates an invitation's status
 	UpdateStatus(ctx context.Context, id string, status string) error
 
+	// TransitionStatus updates an invitation's status only if it is still
+	// from, returning ErrInviteNotPending if it is not
+	TransitionStatus(ctx context.Context, id string, from string, to string) error
+
+	// Renew makes an invitation pending again with a new expiry
+	Renew(ctx context.Context, id string, expiresAt time.Time) error
+
 	// Delete deletes an invitation
 	Delete(ctx context.Context, id string) error
+
+	// MarkExpired sets pending invitations that expired before now to
+	// expired, returning how many were updated
+	MarkExpired(ctx context.Context, now time.Time) (int64, error)
+
+	// DeleteExpired deletes up to limit invitations that expired before the
+	// cutoff, whatever their status, returning how many were deleted
+	DeleteExpired(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// UserSessionStore holds the sessions users sign in with. Session tokens are
+// opaque and expire once left unused for a while.
+type UserSessionStore interface {
+	// StoreUserSession stores the user a session token was issued to
+	StoreUserSession(ctx context.Context, token string, userID string) error
+
+	// ResolveUserSession returns the ID of the user a session token was
+	// issued to, restarting its expiry. It returns ErrInvalidSession if the
+	// token is unknown or has expired.
+	ResolveUserSession(ctx context.Context, token string) (string, error)
+
+	// DeleteUserSession ends a session
+	DeleteUserSession(ctx context.Context, token string) error
+
+	// DeleteUserSessions ends every session of a user
+	DeleteUserSessions(ctx context.Context, userID string) error
 }
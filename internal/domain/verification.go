@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// VerificationExpiration is how long an email verification link works
+const VerificationExpiration = 24 * time.Hour
+
+// ErrInvalidVerificationToken is returned for a verification token that is
+// unknown, used or expired
+var ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+
+// VerificationStore holds the tokens emailed to users to verify their
+// addresses, until they are used or expire
+type VerificationStore interface {
+	// StoreVerification stores the user a verification token was emailed to
+	// for VerificationExpiration
+	StoreVerification(ctx context.Context, token string, userID string) error
+
+	// ConsumeVerification returns the ID of the user a verification token
+	// was emailed to, and removes the token so it only works once. It
+	// returns ErrInvalidVerificationToken if the token is unknown or has
+	// expired.
+	ConsumeVerification(ctx context.Context, token string) (string, error)
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// clientCountryKey is the context key of the country a request comes from
+const clientCountryKey = "client_country"
+
+// GeolocateClients reads the country each request comes from off a header
+// set by a trusted proxy or CDN, such as Cloudflare's CF-IPCountry, so that
+// account signals record it
+func GeolocateClients(header string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// Proxies use XX for unknown clients and T1 for Tor
+			if country := c.Request().Header.Get(header); len(country) == 2 && country != "XX" && country != "T1" {
+				c.Set(clientCountryKey, country)
+			}
+			return next(c)
+		}
+	}
+}
+
+// recordSignal records where a user registered or logged in from. Failures
+// are logged rather than failing the sign-in.
+func recordSignal(c echo.Context, abuseService *service.AbuseService, kind domain.AccountSignalKind, user *domain.User) {
+	country, _ := c.Get(clientCountryKey).(string)
+	if _, err := abuseService.RecordSignal(c.Request().Context(), kind, user, c.RealIP(), country); err != nil {
+		log.Printf("Failed to record %s signal for user %s: %v", kind, user.ID, err)
+	}
+}
+
+// AbuseHandler handles moderation requests for account signals
+type AbuseHandler struct {
+	abuseService *service.AbuseService
+}
+
+// NewAbuseHandler creates a new abuse handler
+func NewAbuseHandler(abuseService *service.AbuseService) *AbuseHandler {
+	return &AbuseHandler{
+		abuseService: abuseService,
+	}
+}
+
+// ListUserSignals godoc
+// @Summary List a user's account signals
+// @Description List where a user registered and logged in from, newest first, with the abuse heuristics each sign-in tripped
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Param limit query int false "Number of signals (default 50, at most 500)"
+// @Success 200 {array} domain.AccountSignal
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/users/{id}/signals [get]
+func (h *AbuseHandler) ListUserSignals(c echo.Context) error {
+	limit, ok := parseLimit(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_limit"),
+		})
+	}
+
+	signals, err := h.abuseService.ListUserSignals(c.Request().Context(), c.Param("id"), limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.get_signals_failed"),
+		})
+	}
+
+	return c.JSON(http.StatusOK, signals)
+}
+
+// ListSharedIPs godoc
+// @Summary List shared IP addresses
+// @Description List the IP addresses that many accounts registered or logged in from, most accounts first
+// @Tags admin
+// @Produce json
+// @Param since query string false "RFC 3339 time to count sign-ins from (default: the shared IP window)"
+// @Param limit query int false "Number of addresses (default 50, at most 500)"
+// @Success 200 {array} domain.SharedIP
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/abuse/shared-ips [get]
+func (h *AbuseHandler) ListSharedIPs(c echo.Context) error {
+	limit, ok := parseLimit(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_limit"),
+		})
+	}
+
+	var since time.Time
+	if raw := c.QueryParam("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: t(c, "error.invalid_date_filter"),
+			})
+		}
+		since = parsed
+	}
+
+	shared, err := h.abuseService.ListSharedIPs(c.Request().Context(), since, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.get_signals_failed"),
+		})
+	}
+
+	return c.JSON(http.StatusOK, shared)
+}
+
+// parseLimit parses the optional positive limit query parameter, returning
+// zero when it is absent
+func parseLimit(c echo.Context) (int, bool) {
+	raw := c.QueryParam("limit")
+	if raw == "" {
+		return 0, true
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		return 0, false
+	}
+	return limit, true
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireAdminToken guards administrative routes, rejecting requests that do
+// not present the admin token as a bearer token
+func RequireAdminToken(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			presented, ok := bearerToken(c)
+			if !ok || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error": t(c, "error.admin_token_required"),
+				})
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// Authenticate identifies the user behind the session token presented as a
+// bearer token, setting their ID as user_id for the handlers. Guests'
+// tokens set the guest as guest instead, which RequireUser does not accept.
+// Requests without a token go through anonymously, for the handlers to
+// decide whether they need a user; requests with an unknown or expired
+// token are rejected so clients know to log in again.
+func Authenticate(userService *service.UserService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := bearerToken(c)
+			if !ok {
+				return next(c)
+			}
+
+			userID, err := userService.Authenticate(c.Request().Context(), token)
+			if errors.Is(err, service.ErrInvalidSession) {
+				var guest *domain.Guest
+				if guest, err = userService.AuthenticateGuest(c.Request().Context(), token); err == nil {
+					c.Set("guest", guest)
+					return next(c)
+				}
+			}
+			if err != nil {
+				if errors.Is(err, service.ErrInvalidSession) {
+					return c.JSON(http.StatusUnauthorized, ErrorResponse{
+						Error: t(c, "error.invalid_session"),
+					})
+				}
+				return c.JSON(http.StatusInternalServerError, ErrorResponse{
+					Error: t(c, "error.authenticate_failed"),
+				})
+			}
+
+			c.Set("user_id", userID)
+			return next(c)
+		}
+	}
+}
+
+// RequireUser rejects requests that Authenticate did not identify a user for
+func RequireUser(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if userID, _ := c.Get("user_id").(string); userID == "" {
+			return c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error: t(c, "error.authentication_required"),
+			})
+		}
+		return next(c)
+	}
+}
+
+// bearerToken returns the bearer token of a request's Authorization header
+func bearerToken(c echo.Context) (string, bool) {
+	token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	return token, ok && token != ""
+}
+
+// callerGuest returns the guest Authenticate identified, if any
+func callerGuest(c echo.Context) (*domain.Guest, bool) {
+	guest, ok := c.Get("guest").(*domain.Guest)
+	return guest, ok
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// BannedResponse is the error returned to a suspended or banned user, with
+// the reason and when the suspension ends
+type BannedResponse struct {
+	ErrorResponse
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// respondBanned tells a suspended or banned user why they are kept out
+func respondBanned(c echo.Context, banErr *service.BanError) error {
+	code := "user_banned"
+	if banErr.Ban.Kind == domain.BanKindSuspension {
+		code = "user_suspended"
+	}
+	return c.JSON(http.StatusForbidden, BannedResponse{
+		ErrorResponse: ErrorResponse{
+			Error: localizeError(c, banErr),
+			Code:  code,
+		},
+		Reason:    banErr.Ban.Reason,
+		ExpiresAt: banErr.Ban.ExpiresAt,
+	})
+}
+
+// BanHandler handles moderators' requests to suspend and ban users
+type BanHandler struct {
+	banService *service.BanService
+}
+
+// NewBanHandler creates a new ban handler
+func NewBanHandler(banService *service.BanService) *BanHandler {
+	return &BanHandler{
+		banService: banService,
+	}
+}
+
+// ModerateBanRequest represents a moderator lifting a ban or noting an
+// appeal of it
+type ModerateBanRequest struct {
+	Moderator string `json:"moderator" validate:"required,max=100"`
+	Note      string `json:"note" validate:"max=2000"`
+}
+
+// IssueBan godoc
+// @Summary Suspend or ban a user
+// @Description Suspend a user until expires_at, or ban them permanently without one. Suspended and banned users cannot log in, join games or connect to them.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param ban body service.IssueBanRequest true "Kind, reason, expiry and acting moderator"
+// @Success 201 {object} domain.UserBan
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/users/{id}/bans [post]
+func (h *BanHandler) IssueBan(c echo.Context) error {
+	var req service.IssueBanRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	ban, err := h.banService.IssueBan(c.Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return banError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, ban)
+}
+
+// ListBans godoc
+// @Summary List a user's bans
+// @Description List a user's suspensions and bans, newest first, including expired and lifted ones
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {array} domain.UserBan
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/users/{id}/bans [get]
+func (h *BanHandler) ListBans(c echo.Context) error {
+	bans, err := h.banService.ListBans(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.get_bans_failed"),
+		})
+	}
+
+	return c.JSON(http.StatusOK, bans)
+}
+
+// LiftBan godoc
+// @Summary Lift a ban
+// @Description End a suspension or ban early, noting why
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Ban ID"
+// @Param request body ModerateBanRequest true "Acting moderator and note"
+// @Success 200 {object} domain.UserBan
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/bans/{id}/lift [post]
+func (h *BanHandler) LiftBan(c echo.Context) error {
+	return moderateBan(c, func(req *ModerateBanRequest) (*domain.UserBan, error) {
+		return h.banService.LiftBan(c.Request().Context(), c.Param("id"), req.Moderator, req.Note)
+	})
+}
+
+// UpdateAppealNote godoc
+// @Summary Note an appeal
+// @Description Replace the notes on a user's appeal of a suspension or ban
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Ban ID"
+// @Param request body ModerateBanRequest true "Acting moderator and appeal note"
+// @Success 200 {object} domain.UserBan
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/bans/{id}/appeal [put]
+func (h *BanHandler) UpdateAppealNote(c echo.Context) error {
+	return moderateBan(c, func(req *ModerateBanRequest) (*domain.UserBan, error) {
+		return h.banService.UpdateAppealNote(c.Request().Context(), c.Param("id"), req.Moderator, req.Note)
+	})
+}
+
+// ListModerationLog godoc
+// @Summary List the moderation log
+// @Description List moderators' actions, newest first, optionally on one user
+// @Tags admin
+// @Produce json
+// @Param user_id query string false "Only actions on this user"
+// @Param limit query int false "Number of entries (default 50, at most 500)"
+// @Success 200 {array} domain.ModerationLogEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/moderation-log [get]
+func (h *BanHandler) ListModerationLog(c echo.Context) error {
+	limit, ok := parseLimit(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_limit"),
+		})
+	}
+
+	entries, err := h.banService.ListModerationLog(c.Request().Context(), c.QueryParam("user_id"), limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.get_moderation_log_failed"),
+		})
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
+
+// moderateBan binds and validates a moderation request, applies it with
+// moderate and responds with the ban
+func moderateBan(c echo.Context, moderate func(req *ModerateBanRequest) (*domain.UserBan, error)) error {
+	var req ModerateBanRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	ban, err := moderate(&req)
+	if err != nil {
+		return banError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, ban)
+}
+
+// banError writes the response for a ban service error
+func banError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidBan):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, service.ErrBanNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, domain.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: t(c, "error.user_not_found"),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.save_ban_failed"),
+		})
+	}
+}
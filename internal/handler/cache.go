@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/i18n"
+)
+
+// ResponseCache stores rendered responses under keys grouped by tag
+type ResponseCache interface {
+	// Get returns a cached response's content type and body, reporting
+	// whether one was found
+	Get(ctx context.Context, tag string, key string) (string, []byte, bool, error)
+
+	// Set caches a response for the given time
+	Set(ctx context.Context, tag string, key string, contentType string, body []byte, ttl time.Duration) error
+
+	// Invalidate drops every response cached under a tag
+	Invalidate(ctx context.Context, tag string) error
+}
+
+// Tags under which cached responses are invalidated together
+const (
+	CacheTagCatalog   = "catalog"   // Categories and difficulties, changed by question writes
+	CacheTagQuestions = "questions" // Question listings, changed by question writes
+	CacheTagDefaults  = "defaults"  // Default game settings, changed only by deploys
+	CacheTagPreview   = "preview"   // Game previews, left to expire as lobbies fill up
+
+	// CacheTagLeaderboards holds the hall of fame and organization
+	// leaderboards, the only leaderboards served, left to expire as games
+	// end and dropped when a user changes whether their answers may be shown
+	CacheTagLeaderboards = "leaderboards"
+)
+
+// CacheResponses serves successful GET responses from a cache for the given
+// time. Responses are cached per path, query and language. When the cache
+// fails, requests are served as if nothing was cached.
+func CacheResponses(cache ResponseCache, tag string, ttl time.Duration) echo.MiddlewareFunc {
+	return cacheResponses(cache, tag, ttl, nil)
+}
+
+// CacheCallerResponses caches responses like CacheResponses, but separately
+// for each user, for responses that depend on who is asking. Requests
+// without a user are not cached.
+func CacheCallerResponses(cache ResponseCache, tag string, ttl time.Duration) echo.MiddlewareFunc {
+	return cacheResponses(cache, tag, ttl, func(c echo.Context) string {
+		userID, _ := c.Get("user_id").(string)
+		return userID
+	})
+}
+
+// cacheResponses caches responses, keyed by the caller scope returns as well
+// when it is given. Requests it returns no scope for are not cached.
+func cacheResponses(cache ResponseCache, tag string, ttl time.Duration, scope func(c echo.Context) string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Method != http.MethodGet {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			key := i18n.FromContext(ctx).Language() + ":" + c.Request().URL.Path + "?" + c.QueryParams().Encode()
+			if scope != nil {
+				caller := scope(c)
+				if caller == "" {
+					return next(c)
+				}
+				key = caller + ":" + key
+			}
+			contentType, body, ok, err := cache.Get(ctx, tag, key)
+			if err != nil {
+				log.Printf("Failed to read cached response: %v", err)
+			}
+			if ok {
+				c.Response().Header().Set("X-Cache", "HIT")
+				return c.Blob(http.StatusOK, contentType, body)
+			}
+
+			c.Response().Header().Set("X-Cache", "MISS")
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			if err := next(c); err != nil {
+				return err
+			}
+
+			if c.Response().Status == http.StatusOK {
+				contentType := c.Response().Header().Get(echo.HeaderContentType)
+				if err := cache.Set(ctx, tag, key, contentType, recorder.body.Bytes(), ttl); err != nil {
+					log.Printf("Failed to cache response: %v", err)
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// InvalidateResponses returns a hook that drops the responses cached under
+// a tag, for write paths to call once they change what the responses show
+func InvalidateResponses(cache ResponseCache, tag string) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		if err := cache.Invalidate(ctx, tag); err != nil {
+			log.Printf("Failed to invalidate cached responses: %v", err)
+		}
+	}
+}
+
+// responseRecorder copies a response's body as it is written
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write writes to the response and its copy
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// mapCache is a response cache kept in a map, by tag and key
+type mapCache map[string]map[string][]byte
+
+func (m mapCache) Get(ctx context.Context, tag string, key string) (string, []byte, bool, error) {
+	body, ok := m[tag][key]
+	return echo.MIMEApplicationJSON, body, ok, nil
+}
+
+func (m mapCache) Set(ctx context.Context, tag string, key string, contentType string, body []byte, ttl time.Duration) error {
+	if m[tag] == nil {
+		m[tag] = make(map[string][]byte)
+	}
+	m[tag][key] = body
+	return nil
+}
+
+func (m mapCache) Invalidate(ctx context.Context, tag string) error {
+	delete(m, tag)
+	return nil
+}
+
+func TestCacheResponses(t *testing.T) {
+	cache := mapCache{}
+	served := 0
+	e := echo.New()
+	e.GET("/questions", func(c echo.Context) error {
+		served++
+		return c.JSON(http.StatusOK, map[string]int{"served": served})
+	}, CacheResponses(cache, CacheTagQuestions, time.Minute))
+	e.GET("/leaderboard", func(c echo.Context) error {
+		served++
+		return c.JSON(http.StatusOK, map[string]int{"served": served})
+	}, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", c.Request().Header.Get("X-User"))
+			return next(c)
+		}
+	}, CacheCallerResponses(cache, CacheTagLeaderboards, time.Minute))
+
+	invalidate := InvalidateResponses(cache, CacheTagQuestions)
+	tests := []struct {
+		name       string
+		path       string
+		user       string
+		before     func()
+		wantCache  string
+		wantServed int
+	}{
+		{"first request", "/questions", "", nil, "MISS", 1},
+		{"repeated request", "/questions", "", nil, "HIT", 1},
+		{"other query", "/questions?limit=5", "", nil, "MISS", 2},
+		{"after invalidation", "/questions", "", func() { invalidate(context.Background()) }, "MISS", 3},
+		{"caller's first request", "/leaderboard", "alice", nil, "MISS", 4},
+		{"caller's repeated request", "/leaderboard", "alice", nil, "HIT", 4},
+		{"other caller", "/leaderboard", "bob", nil, "MISS", 5},
+		{"anonymous caller", "/leaderboard", "", nil, "", 6},
+		{"anonymous caller again", "/leaderboard", "", nil, "", 7},
+	}
+	for _, tt := range tests {
+		if tt.before != nil {
+			tt.before()
+		}
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("X-User", tt.user)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, http.StatusOK)
+		}
+		if got := rec.Header().Get("X-Cache"); got != tt.wantCache {
+			t.Errorf("%s: X-Cache = %q, want %q", tt.name, got, tt.wantCache)
+		}
+		if served != tt.wantServed {
+			t.Errorf("%s: handler served %d requests, want %d", tt.name, served, tt.wantServed)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// CosmeticHandler handles users' cosmetics
+type CosmeticHandler struct {
+	cosmeticService *service.CosmeticService
+}
+
+// NewCosmeticHandler creates a new cosmetic handler
+func NewCosmeticHandler(cosmeticService *service.CosmeticService) *CosmeticHandler {
+	return &CosmeticHandler{
+		cosmeticService: cosmeticService,
+	}
+}
+
+// EquipCosmeticRequest represents a user choosing the cosmetic they wear in
+// a slot
+type EquipCosmeticRequest struct {
+	Cosmetic string `json:"cosmetic" validate:"max=50"` // Empty to take off the slot's cosmetic
+}
+
+// ListCosmetics godoc
+// @Summary List my cosmetics
+// @Description List the cosmetics catalog with those the user has unlocked and equipped, along with their level. Cosmetics earned by reaching a level or an achievement are unlocked as they are listed.
+// @Tags users
+// @Produce json
+// @Success 200 {object} service.CosmeticCollection
+// @Failure 401 {object} ErrorResponse
+// @Router /users/me/cosmetics [get]
+func (h *CosmeticHandler) ListCosmetics(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	collection, err := h.cosmeticService.GetCollection(c.Request().Context(), userID)
+	if err != nil {
+		return cosmeticError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, collection)
+}
+
+// EquipCosmetic godoc
+// @Summary Equip a cosmetic
+// @Description Wear an unlocked cosmetic in the slot of its kind (name_color, avatar_frame or victory_animation), or take off the slot's cosmetic with an empty cosmetic. Games joined from then on show it to every player.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param kind path string true "Cosmetic kind"
+// @Param request body EquipCosmeticRequest true "Cosmetic to wear"
+// @Success 200 {object} domain.EquippedCosmetics
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/me/cosmetics/{kind} [put]
+func (h *CosmeticHandler) EquipCosmetic(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	var req EquipCosmeticRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	equipped, err := h.cosmeticService.Equip(c.Request().Context(), userID, domain.CosmeticKind(c.Param("kind")), req.Cosmetic)
+	if err != nil {
+		return cosmeticError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, equipped)
+}
+
+// cosmeticError writes the response for a cosmetic service error
+func cosmeticError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidCosmetic):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, service.ErrUnauthenticated):
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: t(c, "error.authentication_required"),
+		})
+	case errors.Is(err, service.ErrCosmeticLocked):
+		return c.JSON(http.StatusForbidden, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, service.ErrCosmeticNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.cosmetic_failed"),
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// EntitlementHandler handles premium packs and users' entitlements to them
+type EntitlementHandler struct {
+	entitlementService *service.EntitlementService
+}
+
+// NewEntitlementHandler creates a new entitlement handler
+func NewEntitlementHandler(entitlementService *service.EntitlementService) *EntitlementHandler {
+	return &EntitlementHandler{
+		entitlementService: entitlementService,
+	}
+}
+
+// GrantEntitlementRequest represents an admin granting a user a premium pack
+type GrantEntitlementRequest struct {
+	PackID    string `json:"pack_id" validate:"required,max=50"`
+	Moderator string `json:"moderator" validate:"required,max=100"`
+}
+
+// RevokeEntitlementRequest represents an admin taking a premium pack from a
+// user
+type RevokeEntitlementRequest struct {
+	Moderator string `query:"moderator" validate:"required,max=100"`
+}
+
+// GiftPackRequest represents a user giving up a premium pack to another user
+type GiftPackRequest struct {
+	ToUserID string `json:"to_user_id" validate:"required"`
+}
+
+// ListPremiumPacks godoc
+// @Summary List premium packs
+// @Description List the premium packs and their categories, which only players owning the pack can play. Packs private to the user's organization follow the others.
+// @Tags packs
+// @Produce json
+// @Success 200 {array} domain.PremiumPack
+// @Router /premium-packs [get]
+func (h *EntitlementHandler) ListPremiumPacks(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+	packs, err := h.entitlementService.ListVisiblePacks(c.Request().Context(), userID)
+	if err != nil {
+		return entitlementError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, packs)
+}
+
+// ListOwnEntitlements godoc
+// @Summary List my premium packs
+// @Description List the premium packs the user owns
+// @Tags users
+// @Produce json
+// @Success 200 {array} domain.Entitlement
+// @Failure 401 {object} ErrorResponse
+// @Router /users/me/entitlements [get]
+func (h *EntitlementHandler) ListOwnEntitlements(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+	return h.entitlements(c, userID)
+}
+
+// GiftPack godoc
+// @Summary Gift a premium pack
+// @Description Give up one of the user's premium packs to another user, who is notified of it. Users may gift 3 packs a day, and packs received as a gift can only be gifted again after a week.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param pack path string true "Pack ID"
+// @Param request body GiftPackRequest true "Recipient"
+// @Success 201 {object} domain.Entitlement
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /users/me/entitlements/{pack}/gift [post]
+func (h *EntitlementHandler) GiftPack(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	var req GiftPackRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	entitlement, err := h.entitlementService.Gift(c.Request().Context(), userID, req.ToUserID, c.Param("pack"))
+	if err != nil {
+		return entitlementError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, entitlement)
+}
+
+// SavePremiumPack godoc
+// @Summary Create or replace a premium pack
+// @Description Make a set of categories a premium pack, which only players owning it can play. A category can only be in one premium pack.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Pack ID"
+// @Param pack body service.SavePackRequest true "Name and categories"
+// @Success 200 {object} domain.PremiumPack
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/premium-packs/{id} [put]
+func (h *EntitlementHandler) SavePremiumPack(c echo.Context) error {
+	var req service.SavePackRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	pack, err := h.entitlementService.SavePack(c.Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return entitlementError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, pack)
+}
+
+// SaveTenantPack godoc
+// @Summary Create or replace a private premium pack
+// @Description Make a set of the organization's categories a premium pack only its members can play. Categories must be named after the organization, as in acme/onboarding. Only organization admins can save packs.
+// @Tags tenant
+// @Accept json
+// @Produce json
+// @Param id path string true "Pack ID"
+// @Param pack body service.SavePackRequest true "Name and categories"
+// @Success 200 {object} domain.PremiumPack
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /tenant/premium-packs/{id} [put]
+func (h *EntitlementHandler) SaveTenantPack(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	var req service.SavePackRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	pack, err := h.entitlementService.SaveTenantPack(c.Request().Context(), userID, c.Param("id"), req)
+	if err != nil {
+		return entitlementError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, pack)
+}
+
+// GrantEntitlement godoc
+// @Summary Grant a premium pack
+// @Description Give a user a premium pack. Granting a pack the user already owns changes nothing.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body GrantEntitlementRequest true "Pack and acting moderator"
+// @Success 201 {object} domain.Entitlement
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/users/{id}/entitlements [post]
+func (h *EntitlementHandler) GrantEntitlement(c echo.Context) error {
+	var req GrantEntitlementRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	entitlement, err := h.entitlementService.Grant(c.Request().Context(), c.Param("id"), req.PackID, req.Moderator)
+	if err != nil {
+		return entitlementError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, entitlement)
+}
+
+// ListEntitlements godoc
+// @Summary List a user's premium packs
+// @Description List the premium packs a user owns
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {array} domain.Entitlement
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/users/{id}/entitlements [get]
+func (h *EntitlementHandler) ListEntitlements(c echo.Context) error {
+	return h.entitlements(c, c.Param("id"))
+}
+
+// RevokeEntitlement godoc
+// @Summary Revoke a premium pack
+// @Description Take a premium pack from a user. Games they already host or play are not affected.
+// @Tags admin
+// @Param id path string true "User ID"
+// @Param pack path string true "Pack ID"
+// @Param moderator query string true "Acting moderator"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/users/{id}/entitlements/{pack} [delete]
+func (h *EntitlementHandler) RevokeEntitlement(c echo.Context) error {
+	var req RevokeEntitlementRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	if err := h.entitlementService.Revoke(c.Request().Context(), c.Param("id"), c.Param("pack"), req.Moderator); err != nil {
+		return entitlementError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListEntitlementLog godoc
+// @Summary List a user's entitlement log
+// @Description List the most recent changes to a user's premium packs, newest first, including the packs they gifted
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Param limit query int false "Number of entries (default 50, at most 500)"
+// @Success 200 {array} domain.EntitlementLogEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/users/{id}/entitlement-log [get]
+func (h *EntitlementHandler) ListEntitlementLog(c echo.Context) error {
+	limit, ok := parseLimit(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_limit"),
+		})
+	}
+
+	entries, err := h.entitlementService.ListLog(c.Request().Context(), c.Param("id"), limit)
+	if err != nil {
+		return entitlementError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
+
+// entitlements responds with a user's entitlements
+func (h *EntitlementHandler) entitlements(c echo.Context, userID string) error {
+	entitlements, err := h.entitlementService.ListEntitlements(c.Request().Context(), userID)
+	if err != nil {
+		return entitlementError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, entitlements)
+}
+
+// entitlementError writes the response for an entitlement service error
+func entitlementError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidPremiumPack), errors.Is(err, service.ErrGiftToSelf):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, service.ErrUnauthenticated):
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: t(c, "error.authentication_required"),
+		})
+	case errors.Is(err, service.ErrNotTenantAdmin):
+		return c.JSON(http.StatusForbidden, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, service.ErrPremiumPackNotFound), errors.Is(err, service.ErrEntitlementNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, domain.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: t(c, "error.user_not_found"),
+		})
+	case errors.Is(err, service.ErrEntitlementExists), errors.Is(err, service.ErrGiftTooRecent):
+		return c.JSON(http.StatusConflict, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, service.ErrGiftLimitReached):
+		return c.JSON(http.StatusTooManyRequests, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.save_entitlement_failed"),
+		})
+	}
+}
@@ -1,51 +1,64 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/i18n"
+	"github.com/zizouhuweidi/dahaa/internal/id"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
 	"github.com/zizouhuweidi/dahaa/internal/service"
 )
 
+// playerTokenHeader carries the per-game token issued to a player on join
+const playerTokenHeader = "X-Player-Token"
+
 // GameHandler handles game-related HTTP requests
 type GameHandler struct {
-	gameService  domain.GameService
-	questionRepo domain.QuestionRepository
-	validate     *validator.Validate
+	gameService     domain.GameService
+	questionRepo    domain.QuestionRepository
+	importService   *service.QuestionImportService
+	presetService   *service.PresetService
+	templateService *service.TemplateService
+	streamService   *service.AudienceStreamService
+	validate        *validator.Validate
 }
 
 // NewGameHandler creates a new game handler
-func NewGameHandler(gameService domain.GameService, questionRepo domain.QuestionRepository) *GameHandler {
+func NewGameHandler(gameService domain.GameService, questionRepo domain.QuestionRepository, importService *service.QuestionImportService, presetService *service.PresetService, templateService *service.TemplateService, streamService *service.AudienceStreamService) *GameHandler {
 	return &GameHandler{
-		gameService:  gameService,
-		questionRepo: questionRepo,
-		validate:     validator.New(),
+		gameService:     gameService,
+		questionRepo:    questionRepo,
+		importService:   importService,
+		presetService:   presetService,
+		templateService: templateService,
+		streamService:   streamService,
+		validate:        NewValidator(),
 	}
 }
 
-// Register registers the game routes
-func (h *GameHandler) Register(e *echo.Echo) {
-	g := e.Group("/api/games")
-	g.POST("", h.CreateGame)
-	g.POST("/join", h.JoinGame)
-	g.POST("/:id/start", h.StartGame)
-	g.POST("/:id/turns", h.StartTurn)
-	g.POST("/:id/turns/category", h.SelectCategory)
-	g.POST("/:id/rounds/:round/answers", h.SubmitAnswer)
-	g.POST("/:id/rounds/:round/votes", h.SubmitVote)
-	g.POST("/:id/rounds/:round/end", h.EndRound)
-	g.POST("/:id/end", h.EndGame)
-	g.GET("/:code", h.GetGame)
-	g.POST("/questions/bulk", h.BulkCreateQuestions)
-}
-
-// CreateGameRequest represents the request to create a new game
+// CreateGameRequest represents the request to create a new game. A preset or
+// a template supplies the settings, with categories and language taken from
+// Settings when given. Setting Party starts a new party with the game; setting PartyID
+// plays it as the next of that party's games.
 type CreateGameRequest struct {
-	Code     string               `json:"code" validate:"omitempty,min=4,max=6"`
-	Player   domain.Player        `json:"player" validate:"required"`
-	Settings *domain.GameSettings `json:"settings"`
+	Code       string               `json:"code" validate:"omitempty,min=4,max=6"`
+	Player     domain.Player        `json:"player" validate:"required"`
+	Preset     string               `json:"preset" validate:"omitempty,max=32,excluded_with=TemplateID"`
+	TemplateID string               `json:"template_id" validate:"omitempty,uuid"`
+	Settings   *domain.GameSettings `json:"settings"`
+	Party      bool                 `json:"party"`
+	PartyID    string               `json:"party_id" validate:"omitempty,uuid"`
 }
 
 // CreateQuestionRequest represents the request to create a new question
@@ -53,6 +66,9 @@ type CreateQuestionRequest struct {
 	Category      string   `json:"category" validate:"required"`
 	Text          string   `json:"text" validate:"required"`
 	Answer        string   `json:"answer" validate:"required"`
+	Difficulty    string   `json:"difficulty" validate:"omitempty,oneof=easy medium hard"`
+	Language      string   `json:"language" validate:"omitempty,bcp47_language_tag"`
+	Rating        string   `json:"rating" validate:"omitempty,oneof=family teen adult"`
 	FillerAnswers []string `json:"filler_answers" validate:"required,min=3"`
 }
 
@@ -61,25 +77,92 @@ func (h *GameHandler) CreateGame(c echo.Context) error {
 	var req CreateGameRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid request body",
+			"error": t(c, "error.invalid_request_body"),
 		})
 	}
 
 	if err := c.Validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
+			"error": localizeError(c, err),
 		})
 	}
 
+	settings := req.Settings
+	if req.Preset != "" {
+		userID, _ := c.Get("user_id").(string)
+		resolved, err := h.presetService.ResolveSettings(c.Request().Context(), userID, req.Preset, req.Settings)
+		if err != nil {
+			if errors.Is(err, service.ErrPresetNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": localizeError(c, err),
+				})
+			}
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
+		settings = resolved
+	}
+	if req.TemplateID != "" {
+		resolved, err := h.templateService.ResolveSettings(c.Request().Context(), req.TemplateID, req.Settings)
+		if err != nil {
+			if errors.Is(err, service.ErrTemplateNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": localizeError(c, err),
+				})
+			}
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
+		settings = resolved
+	}
+
+	identifyPlayer(c, &req.Player)
+
 	// Use empty string to trigger auto-generation in service layer
-	game, err := h.gameService.CreateGame(c.Request().Context(), req.Code, req.Player, req.Settings)
+	var game *domain.Game
+	var token string
+	var err error
+	if req.Party || req.PartyID != "" {
+		game, token, err = h.gameService.CreatePartyGame(c.Request().Context(), req.Code, req.Player, settings, req.PartyID)
+	} else {
+		game, token, err = h.gameService.CreateGame(c.Request().Context(), req.Code, req.Player, settings)
+	}
 	if err != nil {
+		var banErr *service.BanError
+		if errors.As(err, &banErr) {
+			return respondBanned(c, banErr)
+		}
+		switch {
+		case errors.Is(err, service.ErrInvalidSettings):
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": localizeError(c, err),
+			})
+		case errors.Is(err, service.ErrPartyNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": localizeError(c, err),
+			})
+		case errors.Is(err, service.ErrNotPartyHost), errors.Is(err, service.ErrPremiumPackNotOwned):
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
+			"error": localizeError(c, err),
 		})
 	}
 
-	return c.JSON(http.StatusCreated, game)
+	return c.JSON(http.StatusCreated, CreateGameResponse{
+		Game:        game,
+		PlayerToken: token,
+	})
+}
+
+// CreateGameResponse represents the created game along with the host's player token
+type CreateGameResponse struct {
+	*domain.Game
+	PlayerToken string `json:"player_token"`
 }
 
 // JoinGameRequest represents the request body for joining a game
@@ -93,106 +176,218 @@ func (h *GameHandler) JoinGame(c echo.Context) error {
 	var player domain.Player
 	if err := c.Bind(&player); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
+			"error": localizeError(c, err),
 		})
 	}
 
-	if err := h.gameService.JoinGame(c.Request().Context(), code, player); err != nil {
+	identifyPlayer(c, &player)
+
+	token, err := h.gameService.JoinGame(c.Request().Context(), code, player)
+	if err != nil {
+		var banErr *service.BanError
+		if errors.As(err, &banErr) {
+			return respondBanned(c, banErr)
+		}
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": localizeError(c, err),
+			})
+		case errors.Is(err, service.ErrPremiumPackNotOwned), errors.Is(err, service.ErrTenantGame):
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": localizeError(c, err),
+			})
+		case errors.Is(err, service.ErrGameFull), errors.Is(err, service.ErrGameInProgress), errors.Is(err, service.ErrPlayerInGame):
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
+			"error": localizeError(c, err),
 		})
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{
-		"message": "Successfully joined game",
+		"message":      t(c, "message.game_joined"),
+		"player_id":    player.ID,
+		"player_token": token,
 	})
 }
 
-// StartGame starts a game
+// StartGame starts a game. The host may pass force=true to start before
+// every player is ready.
 func (h *GameHandler) StartGame(c echo.Context) error {
-	gameID := c.Param("id")
+	gameID := c.Param("code")
 	if gameID == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "game ID is required")
+		return echo.NewHTTPError(http.StatusBadRequest, t(c, "error.game_id_required"))
+	}
+
+	force := c.QueryParam("force") == "true"
+	if err := h.gameService.StartGame(c.Request().Context(), gameID, h.callerID(c, gameID), force); err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+		case errors.Is(err, service.ErrGameInProgress):
+			return echo.NewHTTPError(http.StatusConflict, t(c, "error.game_in_progress"))
+		case errors.Is(err, service.ErrPlayersNotReady):
+			return echo.NewHTTPError(http.StatusConflict, localizeError(c, err))
+		case errors.Is(err, service.ErrUnauthenticated):
+			return echo.NewHTTPError(http.StatusUnauthorized, localizeError(c, err))
+		case errors.Is(err, service.ErrNotHost):
+			return echo.NewHTTPError(http.StatusForbidden, localizeError(c, err))
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
+		}
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// CancelCountdown stops a game from starting itself
+func (h *GameHandler) CancelCountdown(c echo.Context) error {
+	code := c.Param("code")
+	if err := h.gameService.CancelCountdown(c.Request().Context(), code, h.callerID(c, code)); err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+		case errors.Is(err, service.ErrUnauthenticated):
+			return echo.NewHTTPError(http.StatusUnauthorized, localizeError(c, err))
+		case errors.Is(err, service.ErrNotHost):
+			return echo.NewHTTPError(http.StatusForbidden, localizeError(c, err))
+		case errors.Is(err, service.ErrGameStarted), errors.Is(err, service.ErrNoCountdown):
+			return echo.NewHTTPError(http.StatusConflict, localizeError(c, err))
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SetReadyRequest represents the request body for toggling a player's
+// readiness. The player is identified by the X-Player-Token header.
+type SetReadyRequest struct {
+	PlayerID string `json:"player_id"`
+	Ready    bool   `json:"ready"`
+}
+
+// SetReady marks the calling player as ready to start or not
+func (h *GameHandler) SetReady(c echo.Context) error {
+	code := c.Param("code")
+
+	var req SetReadyRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, t(c, "error.invalid_request_body"))
+	}
+
+	playerID, err := h.authenticatePlayer(c, code, req.PlayerID)
+	if err != nil {
+		return err
 	}
 
-	if err := h.gameService.StartGame(c.Request().Context(), gameID); err != nil {
-		switch err {
-		case service.ErrGameNotFound:
-			return echo.NewHTTPError(http.StatusNotFound, "game not found")
-		case service.ErrGameInProgress:
-			return echo.NewHTTPError(http.StatusConflict, "game is already in progress")
+	if err := h.gameService.SetReady(c.Request().Context(), code, playerID, req.Ready); err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+		case errors.Is(err, service.ErrPlayerNotInGame):
+			return echo.NewHTTPError(http.StatusNotFound, localizeError(c, err))
+		case errors.Is(err, service.ErrGameStarted):
+			return echo.NewHTTPError(http.StatusConflict, localizeError(c, err))
 		default:
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
 		}
 	}
 
 	return c.NoContent(http.StatusOK)
 }
 
-// StartTurnRequest represents the request body for starting a turn
+// StartTurnRequest represents the request body for starting a turn. The
+// player is identified by the X-Player-Token header; PlayerID is optional
+// and must match it when provided.
 type StartTurnRequest struct {
-	PlayerID string `json:"player_id" validate:"required"`
+	PlayerID string `json:"player_id"`
 }
 
 // StartTurn starts a new turn for a player
 func (h *GameHandler) StartTurn(c echo.Context) error {
-	gameID := c.Param("id")
+	gameID := c.Param("code")
 	if gameID == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "game ID is required")
+		return echo.NewHTTPError(http.StatusBadRequest, t(c, "error.game_id_required"))
 	}
 
 	var req StartTurnRequest
 	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+		return echo.NewHTTPError(http.StatusBadRequest, t(c, "error.invalid_request_body"))
 	}
 
 	if err := c.Validate(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return echo.NewHTTPError(http.StatusBadRequest, localizeError(c, err))
 	}
 
-	if err := h.gameService.StartTurn(c.Request().Context(), gameID, req.PlayerID); err != nil {
-		switch err {
-		case service.ErrGameNotFound:
-			return echo.NewHTTPError(http.StatusNotFound, "game not found")
-		case service.ErrGameNotStarted:
-			return echo.NewHTTPError(http.StatusConflict, "game has not started")
+	playerID, err := h.authenticatePlayer(c, gameID, req.PlayerID)
+	if err != nil {
+		return err
+	}
+
+	if err := h.gameService.StartTurn(c.Request().Context(), gameID, playerID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+		case errors.Is(err, service.ErrGameNotStarted):
+			return echo.NewHTTPError(http.StatusConflict, t(c, "error.game_not_started"))
+		case errors.Is(err, service.ErrCategoriesDrawn):
+			return echo.NewHTTPError(http.StatusConflict, t(c, "error.categories_drawn"))
+		case errors.Is(err, service.ErrPlayerEliminated), errors.Is(err, service.ErrRoundNotWaiting):
+			return echo.NewHTTPError(http.StatusConflict, localizeError(c, err))
 		default:
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
 		}
 	}
 
 	return c.NoContent(http.StatusOK)
 }
 
-// SelectCategoryRequest represents the request body for selecting a category
+// SelectCategoryRequest represents the request body for selecting a
+// category. The player is identified by the X-Player-Token header; PlayerID
+// is optional and must match it when provided.
 type SelectCategoryRequest struct {
+	PlayerID string `json:"player_id"`
 	Category string `json:"category" validate:"required"`
 }
 
-// SelectCategory handles category selection during a turn
+// SelectCategory selects the round's category for the player holding the turn
 func (h *GameHandler) SelectCategory(c echo.Context) error {
-	gameID := c.Param("id")
+	gameID := c.Param("code")
 	if gameID == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "game ID is required")
+		return echo.NewHTTPError(http.StatusBadRequest, t(c, "error.game_id_required"))
 	}
 
 	var req SelectCategoryRequest
 	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+		return echo.NewHTTPError(http.StatusBadRequest, t(c, "error.invalid_request_body"))
 	}
 
 	if err := c.Validate(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		return echo.NewHTTPError(http.StatusBadRequest, localizeError(c, err))
 	}
 
-	if err := h.gameService.SelectCategory(c.Request().Context(), gameID, req.Category); err != nil {
-		switch err {
-		case service.ErrGameNotFound:
-			return echo.NewHTTPError(http.StatusNotFound, "game not found")
-		case service.ErrGameNotStarted:
-			return echo.NewHTTPError(http.StatusConflict, "game has not started")
+	playerID, err := h.authenticatePlayer(c, gameID, req.PlayerID)
+	if err != nil {
+		return err
+	}
+
+	if err := h.gameService.SelectCategory(c.Request().Context(), gameID, playerID, req.Category); err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+		case errors.Is(err, service.ErrNotYourTurn):
+			return echo.NewHTTPError(http.StatusForbidden, t(c, "error.not_your_turn"))
+		case errors.Is(err, service.ErrInvalidCategory):
+			return echo.NewHTTPError(http.StatusBadRequest, t(c, "error.invalid_category"))
+		case errors.Is(err, service.ErrCategoriesDrawn), errors.Is(err, service.ErrNoActiveTurn):
+			return echo.NewHTTPError(http.StatusConflict, localizeError(c, err))
 		default:
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
 		}
 	}
 
@@ -203,30 +398,40 @@ func (h *GameHandler) SelectCategory(c echo.Context) error {
 func (h *GameHandler) SubmitAnswer(c echo.Context) error {
 	code := c.Param("code")
 	var req struct {
-		PlayerID string `json:"player_id" validate:"required"`
+		PlayerID string `json:"player_id"`
 		Answer   string `json:"answer" validate:"required"`
 	}
 
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
+			"error": localizeError(c, err),
 		})
 	}
 
 	if err := h.validate.Struct(req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
+			"error": localizeError(c, err),
 		})
 	}
 
-	if err := h.gameService.SubmitAnswer(c.Request().Context(), code, req.PlayerID, req.Answer); err != nil {
+	playerID, err := h.authenticatePlayer(c, code, req.PlayerID)
+	if err != nil {
+		return err
+	}
+
+	if err := h.gameService.SubmitAnswer(c.Request().Context(), code, playerID, req.Answer); err != nil {
+		if errors.Is(err, domain.ErrEventConflict) || errors.Is(err, service.ErrSubmissionTooLate) || errors.Is(err, service.ErrPlayerEliminated) {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
+			"error": localizeError(c, err),
 		})
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{
-		"message": "Answer submitted successfully",
+		"message": t(c, "message.answer_submitted"),
 	})
 }
 
@@ -234,66 +439,322 @@ func (h *GameHandler) SubmitAnswer(c echo.Context) error {
 func (h *GameHandler) SubmitVote(c echo.Context) error {
 	code := c.Param("code")
 	var req struct {
-		PlayerID string `json:"player_id" validate:"required"`
+		PlayerID string `json:"player_id"`
 		AnswerID string `json:"answer_id" validate:"required"`
 	}
 
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
+			"error": localizeError(c, err),
 		})
 	}
 
 	if err := h.validate.Struct(req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
+			"error": localizeError(c, err),
 		})
 	}
 
-	if err := h.gameService.SubmitVote(c.Request().Context(), code, req.PlayerID, req.AnswerID); err != nil {
+	playerID, err := h.authenticatePlayer(c, code, req.PlayerID)
+	if err != nil {
+		return err
+	}
+
+	if err := h.gameService.SubmitVote(c.Request().Context(), code, playerID, req.AnswerID); err != nil {
+		if errors.Is(err, domain.ErrEventConflict) || errors.Is(err, service.ErrSubmissionTooLate) {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
+		if errors.Is(err, service.ErrLiarCannotVote) {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
+			"error": localizeError(c, err),
 		})
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{
-		"message": "Vote submitted successfully",
+		"message": t(c, "message.vote_submitted"),
 	})
 }
 
 // EndRound handles ending the current round
 func (h *GameHandler) EndRound(c echo.Context) error {
 	code := c.Param("code")
-	if err := h.gameService.EndRound(c.Request().Context(), code); err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+	if err := h.gameService.EndRound(c.Request().Context(), code, h.callerID(c, code)); err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnauthenticated):
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error": localizeError(c, err),
+			})
+		case errors.Is(err, service.ErrNotHost):
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": localizeError(c, err),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{
-		"message": "Round ended successfully",
+		"message": t(c, "message.round_ended"),
 	})
 }
 
 // EndGame ends the game session
 func (h *GameHandler) EndGame(c echo.Context) error {
-	gameID := c.Param("id")
+	gameID := c.Param("code")
 	if gameID == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "game ID is required")
+		return echo.NewHTTPError(http.StatusBadRequest, t(c, "error.game_id_required"))
 	}
 
-	if err := h.gameService.EndGame(c.Request().Context(), gameID); err != nil {
-		switch err {
-		case service.ErrGameNotFound:
-			return echo.NewHTTPError(http.StatusNotFound, "game not found")
+	if err := h.gameService.EndGame(c.Request().Context(), gameID, h.callerID(c, gameID)); err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+		case errors.Is(err, service.ErrUnauthenticated):
+			return echo.NewHTTPError(http.StatusUnauthorized, localizeError(c, err))
+		case errors.Is(err, service.ErrNotHost):
+			return echo.NewHTTPError(http.StatusForbidden, localizeError(c, err))
 		default:
-			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
 		}
 	}
 
 	return c.NoContent(http.StatusOK)
 }
 
+// ExtendGame keeps a game from expiring from inactivity
+func (h *GameHandler) ExtendGame(c echo.Context) error {
+	code := c.Param("code")
+	expiresAt, err := h.gameService.ExtendGame(c.Request().Context(), code, h.callerID(c, code))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+		case errors.Is(err, service.ErrUnauthenticated):
+			return echo.NewHTTPError(http.StatusUnauthorized, localizeError(c, err))
+		case errors.Is(err, service.ErrNotHost):
+			return echo.NewHTTPError(http.StatusForbidden, localizeError(c, err))
+		case errors.Is(err, service.ErrGameEnded):
+			return echo.NewHTTPError(http.StatusConflict, localizeError(c, err))
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"expires_at": expiresAt,
+	})
+}
+
+// SkipPhase ends the phase the game is in early
+func (h *GameHandler) SkipPhase(c echo.Context) error {
+	code := c.Param("code")
+	if err := h.gameService.SkipPhase(c.Request().Context(), code, h.callerID(c, code)); err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+		case errors.Is(err, service.ErrUnauthenticated):
+			return echo.NewHTTPError(http.StatusUnauthorized, localizeError(c, err))
+		case errors.Is(err, service.ErrNotHost):
+			return echo.NewHTTPError(http.StatusForbidden, localizeError(c, err))
+		case errors.Is(err, service.ErrNoTimedPhase):
+			return echo.NewHTTPError(http.StatusConflict, localizeError(c, err))
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ExtendTimer gives the phase the game is in more time
+func (h *GameHandler) ExtendTimer(c echo.Context) error {
+	code := c.Param("code")
+	timer, err := h.gameService.ExtendTimer(c.Request().Context(), code, h.callerID(c, code))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+		case errors.Is(err, service.ErrUnauthenticated):
+			return echo.NewHTTPError(http.StatusUnauthorized, localizeError(c, err))
+		case errors.Is(err, service.ErrNotHost):
+			return echo.NewHTTPError(http.StatusForbidden, localizeError(c, err))
+		case errors.Is(err, service.ErrNoTimedPhase):
+			return echo.NewHTTPError(http.StatusConflict, localizeError(c, err))
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
+		}
+	}
+
+	return c.JSON(http.StatusOK, timer)
+}
+
+// IssueOverlayToken issues the token stream overlays present to follow the
+// game over WebSocket, revoking the last one
+func (h *GameHandler) IssueOverlayToken(c echo.Context) error {
+	code := c.Param("code")
+	token, err := h.gameService.IssueOverlayToken(c.Request().Context(), code, h.callerID(c, code))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+		case errors.Is(err, service.ErrUnauthenticated):
+			return echo.NewHTTPError(http.StatusUnauthorized, localizeError(c, err))
+		case errors.Is(err, service.ErrNotHost):
+			return echo.NewHTTPError(http.StatusForbidden, localizeError(c, err))
+		case errors.Is(err, service.ErrGameEnded):
+			return echo.NewHTTPError(http.StatusConflict, localizeError(c, err))
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
+		}
+	}
+
+	return c.JSON(http.StatusCreated, map[string]string{
+		"overlay_token": token,
+	})
+}
+
+// MutePlayer stops relaying a player's chat and reactions to the game
+func (h *GameHandler) MutePlayer(c echo.Context) error {
+	return h.setMuted(c, true)
+}
+
+// UnmutePlayer resumes relaying a player's chat and reactions to the game
+func (h *GameHandler) UnmutePlayer(c echo.Context) error {
+	return h.setMuted(c, false)
+}
+
+// setMuted mutes or unmutes the player in the path on behalf of the host
+func (h *GameHandler) setMuted(c echo.Context, muted bool) error {
+	code := c.Param("code")
+	if err := h.gameService.MutePlayer(c.Request().Context(), code, h.callerID(c, code), c.Param("id"), muted); err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+		case errors.Is(err, service.ErrPlayerNotInGame):
+			return echo.NewHTTPError(http.StatusNotFound, localizeError(c, err))
+		case errors.Is(err, service.ErrUnauthenticated):
+			return echo.NewHTTPError(http.StatusUnauthorized, localizeError(c, err))
+		case errors.Is(err, service.ErrNotHost):
+			return echo.NewHTTPError(http.StatusForbidden, localizeError(c, err))
+		case errors.Is(err, service.ErrCannotMuteHost):
+			return echo.NewHTTPError(http.StatusBadRequest, localizeError(c, err))
+		case errors.Is(err, service.ErrGameEnded):
+			return echo.NewHTTPError(http.StatusConflict, localizeError(c, err))
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ConnectStreamRequest represents the request to connect the host's live
+// stream to a game. Channel is the Twitch channel name or YouTube video ID.
+type ConnectStreamRequest struct {
+	Platform string `json:"platform" validate:"required,oneof=twitch youtube"`
+	Channel  string `json:"channel" validate:"required,max=64"`
+}
+
+// ConnectStream starts counting votes from the chat of the host's live
+// stream as audience votes
+func (h *GameHandler) ConnectStream(c echo.Context) error {
+	var req ConnectStreamRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, t(c, "error.invalid_request_body"))
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, localizeError(c, err))
+	}
+
+	code := c.Param("code")
+	if err := h.streamService.Connect(c.Request().Context(), code, h.callerID(c, code), req.Platform, req.Channel); err != nil {
+		return streamError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DisconnectStream stops counting votes from the host's live stream
+func (h *GameHandler) DisconnectStream(c echo.Context) error {
+	code := c.Param("code")
+	if err := h.streamService.Disconnect(c.Request().Context(), code, h.callerID(c, code)); err != nil {
+		return streamError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// streamError maps an error connecting or disconnecting a stream to a response
+func streamError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrGameNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+	case errors.Is(err, service.ErrStreamNotConnected):
+		return echo.NewHTTPError(http.StatusNotFound, localizeError(c, err))
+	case errors.Is(err, service.ErrUnauthenticated):
+		return echo.NewHTTPError(http.StatusUnauthorized, localizeError(c, err))
+	case errors.Is(err, service.ErrNotHost):
+		return echo.NewHTTPError(http.StatusForbidden, localizeError(c, err))
+	case errors.Is(err, service.ErrInvalidStreamChannel), errors.Is(err, service.ErrPlatformUnavailable):
+		return echo.NewHTTPError(http.StatusBadRequest, localizeError(c, err))
+	case errors.Is(err, service.ErrGameEnded):
+		return echo.NewHTTPError(http.StatusConflict, localizeError(c, err))
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
+	}
+}
+
+// DeleteGame deletes an abandoned game
+func (h *GameHandler) DeleteGame(c echo.Context) error {
+	code := c.Param("code")
+	if code == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, t(c, "error.game_code_required"))
+	}
+
+	if err := h.gameService.DeleteGame(c.Request().Context(), code, h.callerID(c, code)); err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+		case errors.Is(err, service.ErrUnauthenticated):
+			return echo.NewHTTPError(http.StatusUnauthorized, localizeError(c, err))
+		case errors.Is(err, service.ErrNotHost):
+			return echo.NewHTTPError(http.StatusForbidden, localizeError(c, err))
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetGameEvents returns a game's event stream for auditing
+func (h *GameHandler) GetGameEvents(c echo.Context) error {
+	code := c.Param("code")
+	events, err := h.gameService.GetGameEvents(c.Request().Context(), code, h.callerID(c, code))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+		case errors.Is(err, service.ErrUnauthenticated):
+			return echo.NewHTTPError(http.StatusUnauthorized, localizeError(c, err))
+		case errors.Is(err, service.ErrNotHost):
+			return echo.NewHTTPError(http.StatusForbidden, localizeError(c, err))
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
+		}
+	}
+
+	return c.JSON(http.StatusOK, events)
+}
+
 // BulkCreateQuestionsRequest represents the request body for bulk creating questions
 type BulkCreateQuestionsRequest struct {
 	Questions []CreateQuestionRequest `json:"questions" validate:"required,min=1,dive"`
@@ -304,14 +765,14 @@ func (h *GameHandler) BulkCreateQuestions(c echo.Context) error {
 	var req BulkCreateQuestionsRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid request body",
+			"error": t(c, "error.invalid_request_body"),
 		})
 	}
 
 	// Validate the request
 	if err := h.validate.Struct(req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
+			"error": localizeError(c, err),
 		})
 	}
 
@@ -322,38 +783,420 @@ func (h *GameHandler) BulkCreateQuestions(c echo.Context) error {
 			Text:          q.Text,
 			Answer:        q.Answer,
 			Category:      q.Category,
+			Difficulty:    q.Difficulty,
+			Language:      q.Language,
+			Rating:        domain.ContentRating(q.Rating),
 			FillerAnswers: q.FillerAnswers,
 		})
 	}
 
-	// Create questions in bulk
-	if err := h.questionRepo.BulkCreateQuestions(c.Request().Context(), questions); err != nil {
+	// Import in the background; progress is streamed over the job's WS channel
+	job, err := h.importService.StartImport(questions)
+	if err != nil {
+		return c.JSON(http.StatusTooManyRequests, map[string]string{
+			"error": localizeError(c, err),
+		})
+	}
+
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// RenameCategoryRequest represents the request body for renaming a category.
+// Merge must be set to move its questions into a category that already has some.
+type RenameCategoryRequest struct {
+	To    string `json:"to" validate:"required,min=3,max=50"`
+	Merge bool   `json:"merge"`
+}
+
+// RenameCategory moves every question in a category to a new category, or
+// merges it into an existing one
+func (h *GameHandler) RenameCategory(c echo.Context) error {
+	var req RenameCategoryRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": localizeError(c, err),
+		})
+	}
+
+	from := c.Param("category")
+	if req.To == from {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": t(c, "error.category_unchanged"),
+		})
+	}
+
+	moved, err := h.questionRepo.RenameCategory(c.Request().Context(), from, req.To, req.Merge)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrCategoryNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": localizeError(c, err),
+			})
+		case errors.Is(err, domain.ErrCategoryExists):
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": localizeError(c, err),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"category": req.To,
+		"moved":    moved,
+	})
+}
+
+// ListQuestions lists a page of live questions, optionally filtered by
+// category, difficulty, language or rating
+func (h *GameHandler) ListQuestions(c echo.Context) error {
+	params, err := pagination.Parse(c.QueryParams(), domain.QuestionListOptions)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": localizeError(c, err),
+		})
+	}
+
+	page, err := h.questionRepo.List(c.Request().Context(), params)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) || errors.Is(err, pagination.ErrInvalidSort) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to create questions: " + err.Error(),
+			"error": t(c, "error.list_questions_failed"),
 		})
 	}
 
-	return c.JSON(http.StatusCreated, map[string]any{
-		"message": "Questions created successfully",
-		"count":   len(questions),
+	return c.JSON(http.StatusOK, page)
+}
+
+// DeleteQuestion soft-deletes a question, taking it out of play until it
+// is restored
+func (h *GameHandler) DeleteQuestion(c echo.Context) error {
+	if err := h.questionRepo.DeleteQuestion(c.Request().Context(), c.Param("id")); err != nil {
+		return questionError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RestoreQuestion restores a deleted question
+func (h *GameHandler) RestoreQuestion(c echo.Context) error {
+	if err := h.questionRepo.RestoreQuestion(c.Request().Context(), c.Param("id")); err != nil {
+		return questionError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// questionError maps an error deleting or restoring a question to a response
+func questionError(c echo.Context, err error) error {
+	if errors.Is(err, domain.ErrQuestionNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": localizeError(c, err),
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": localizeError(c, err),
 	})
 }
 
+// GetImportJob returns the progress of a bulk question import
+func (h *GameHandler) GetImportJob(c echo.Context) error {
+	job, err := h.importService.GetJob(c.Param("job_id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": t(c, "error.import_job_not_found"),
+		})
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
+
+// GetRound returns the sanitized state of a specific round
+func (h *GameHandler) GetRound(c echo.Context) error {
+	code := c.Param("code")
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil || number < 1 {
+		return echo.NewHTTPError(http.StatusBadRequest, t(c, "error.invalid_round"))
+	}
+
+	round, err := h.gameService.GetRound(c.Request().Context(), code, number, h.callerID(c, code))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGameNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+		case errors.Is(err, service.ErrInvalidRound):
+			return echo.NewHTTPError(http.StatusNotFound, t(c, "error.round_not_found"))
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
+		}
+	}
+
+	return c.JSON(http.StatusOK, round)
+}
+
+// GetCategories returns the available question categories with their question
+// counts, display names in the request's language and the text direction of
+// their content. The language query parameter restricts them to one content
+// pack, and the max_rating parameter counts only questions rated no edgier.
+func (h *GameHandler) GetCategories(c echo.Context) error {
+	maxRating := domain.ContentRating(c.QueryParam("max_rating"))
+	if maxRating != "" && !slices.Contains(domain.ContentRatings, maxRating) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": t(c, "error.invalid_rating"),
+		})
+	}
+
+	counts, err := h.questionRepo.GetCategoryCounts(c.Request().Context(), maxRating)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": t(c, "error.get_categories_failed"),
+		})
+	}
+
+	language := c.QueryParam("language")
+	localizer := i18n.FromContext(c.Request().Context())
+	categories := make([]domain.CategoryCount, 0, len(counts))
+	for _, category := range counts {
+		if language != "" && category.Language != language {
+			continue
+		}
+		category.DisplayName = category.Category
+		if id := "category." + category.Category; localizer.Has(id) {
+			category.DisplayName = localizer.T(id)
+		}
+		category.Direction = i18n.Direction(category.Language)
+		categories = append(categories, category)
+	}
+
+	return c.JSON(http.StatusOK, categories)
+}
+
+// GetDifficulties returns the available question difficulty levels
+func (h *GameHandler) GetDifficulties(c echo.Context) error {
+	difficulties, err := h.questionRepo.GetDifficulties(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": t(c, "error.get_difficulties_failed"),
+		})
+	}
+
+	if difficulties == nil {
+		difficulties = []string{}
+	}
+
+	return c.JSON(http.StatusOK, difficulties)
+}
+
+// GetDefaultSettings returns the server's default game settings
+func (h *GameHandler) GetDefaultSettings(c echo.Context) error {
+	return c.JSON(http.StatusOK, domain.DefaultGameSettings())
+}
+
 // GetGame handles retrieving a game by code
 func (h *GameHandler) GetGame(c echo.Context) error {
 	code := c.Param("code")
 	if code == "" {
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Game code is required",
+			"error": t(c, "error.game_code_required"),
 		})
 	}
 
 	game, err := h.gameService.GetGame(c.Request().Context(), code)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
-			"error": "Game not found",
+			"error": t(c, "error.game_not_found"),
+		})
+	}
+
+	// Players only see what the game lets them see yet, which depends on who
+	// is asking
+	body, err := json.Marshal(game.PlayerView(h.callerID(c, code)))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
+	}
+
+	// Let polling clients revalidate instead of re-downloading unchanged state
+	etag := gameETag(body)
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-cache")
+	c.Response().Header().Set("ETag", etag)
+	c.Response().Header().Set("Vary", "Authorization, "+playerTokenHeader)
+	if etagMatches(c.Request().Header.Get("If-None-Match"), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.JSONBlob(http.StatusOK, body)
+}
+
+// GetGameSummary returns a small summary of a game's status, roster and
+// current phase, for clients that poll instead of holding a WebSocket
+func (h *GameHandler) GetGameSummary(c echo.Context) error {
+	game, err := h.gameService.GetGame(c.Request().Context(), c.Param("code"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": t(c, "error.game_not_found"),
+		})
+	}
+
+	etag := summaryETag(game)
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-cache")
+	c.Response().Header().Set("ETag", etag)
+	if etagMatches(c.Request().Header.Get("If-None-Match"), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.JSON(http.StatusOK, game.Summary())
+}
+
+// ListLobbies returns the listed games waiting for players that the caller
+// may join, those hosted in the region query parameter first. Without it,
+// the region the caller prefers is used.
+func (h *GameHandler) ListLobbies(c echo.Context) error {
+	lobbies, err := h.gameService.ListLobbies(c.Request().Context(), h.callerID(c, ""), c.QueryParam("region"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": localizeError(c, err),
+		})
+	}
+	return c.JSON(http.StatusOK, lobbies)
+}
+
+// FindLobby matches the caller with the listed game they are best joining,
+// preferring games hosted in the region query parameter, or in the region
+// the caller prefers. The caller then joins it by its code.
+func (h *GameHandler) FindLobby(c echo.Context) error {
+	lobby, err := h.gameService.FindLobby(c.Request().Context(), h.callerID(c, ""), c.QueryParam("region"))
+	if err != nil {
+		if errors.Is(err, service.ErrNoOpenLobby) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": localizeError(c, err),
+		})
+	}
+	return c.JSON(http.StatusOK, lobby)
+}
+
+// GetPartyScoreboard returns a party's running scoreboard across its games
+func (h *GameHandler) GetPartyScoreboard(c echo.Context) error {
+	board, err := h.gameService.GetPartyScoreboard(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrPartyNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": localizeError(c, err),
 		})
 	}
 
-	return c.JSON(http.StatusOK, game)
+	return c.JSON(http.StatusOK, board)
+}
+
+// callerID returns the identity of the caller, preferring the authenticated
+// user or guest and falling back to the player the X-Player-Token was issued
+// to
+func (h *GameHandler) callerID(c echo.Context, code string) string {
+	if userID, ok := c.Get("user_id").(string); ok && userID != "" {
+		return userID
+	}
+	if guest, ok := callerGuest(c); ok {
+		return guest.ID
+	}
+
+	token := c.Request().Header.Get(playerTokenHeader)
+	if token == "" {
+		return ""
+	}
+
+	playerID, err := h.gameService.AuthenticatePlayer(c.Request().Context(), code, token)
+	if err != nil {
+		return ""
+	}
+	return playerID
+}
+
+// identifyPlayer binds the player a caller creates or joins a game as to the
+// caller's identity, so that bans, entitlements and organizations are checked
+// against it rather than an ID from the request body. Signed-in users play as
+// their account and guests as their guest identity, under their guest name
+// unless they chose another. Anonymous callers play under a new ID.
+func identifyPlayer(c echo.Context, player *domain.Player) {
+	if userID, ok := c.Get("user_id").(string); ok && userID != "" {
+		player.ID = userID
+		return
+	}
+	guest, ok := callerGuest(c)
+	if !ok {
+		player.ID = id.New()
+		return
+	}
+	player.ID = guest.ID
+	if player.Name == "" {
+		player.Name = guest.DisplayName
+	}
+}
+
+// authenticatePlayer resolves the X-Player-Token header to a player ID and
+// rejects requests claiming to act for a different player
+func (h *GameHandler) authenticatePlayer(c echo.Context, code string, claimedPlayerID string) (string, error) {
+	token := c.Request().Header.Get(playerTokenHeader)
+	if token == "" {
+		return "", echo.NewHTTPError(http.StatusUnauthorized, t(c, "error.player_token_required"))
+	}
+
+	playerID, err := h.gameService.AuthenticatePlayer(c.Request().Context(), code, token)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPlayerToken):
+			return "", echo.NewHTTPError(http.StatusUnauthorized, localizeError(c, err))
+		case errors.Is(err, service.ErrGameNotFound):
+			return "", echo.NewHTTPError(http.StatusNotFound, t(c, "error.game_not_found"))
+		default:
+			return "", echo.NewHTTPError(http.StatusInternalServerError, localizeError(c, err))
+		}
+	}
+
+	if claimedPlayerID != "" && claimedPlayerID != playerID {
+		return "", echo.NewHTTPError(http.StatusForbidden, t(c, "error.player_token_mismatch"))
+	}
+
+	return playerID, nil
+}
+
+// gameETag computes a strong ETag from a rendered view of a game, so callers
+// who see different parts of the same game never share one
+func gameETag(view []byte) string {
+	sum := sha256.Sum256(view)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// summaryETag identifies the version of a game's summary, distinct from the
+// ETag of the full game
+func summaryETag(game *domain.Game) string {
+	return fmt.Sprintf(`"%s-%d-summary"`, game.ID, game.UpdatedAt.UnixNano())
+}
+
+// etagMatches reports whether an If-None-Match header matches the given ETag
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// failingGames is a game service whose commands fail with err
+type failingGames struct {
+	domain.GameService
+	err error
+}
+
+func (g failingGames) JoinGame(ctx context.Context, code string, player domain.Player) (string, error) {
+	return "", g.err
+}
+
+func (g failingGames) StartGame(ctx context.Context, code string, callerID string, force bool) error {
+	return g.err
+}
+
+// fixedGames is a game service that always returns the same game
+type fixedGames struct {
+	domain.GameService
+	game *domain.Game
+}
+
+func (g fixedGames) GetGame(ctx context.Context, code string) (*domain.Game, error) {
+	return g.game, nil
+}
+
+func TestGetGameETag(t *testing.T) {
+	// A revealed round withholds its correct answer from the liar only
+	game := &domain.Game{
+		ID:       "game-1",
+		Settings: &domain.GameSettings{TurnPlayerIsLiar: true},
+		Rounds: []domain.Round{{
+			Number:      1,
+			Status:      domain.RoundStatusCompleted,
+			CurrentTurn: &domain.Turn{PlayerID: "alice"},
+			AnswerPool:  domain.AnswerPool{CorrectAnswer: "truth", CorrectAnswerID: "a1"},
+		}},
+	}
+	h := NewGameHandler(fixedGames{game: game}, nil, nil, nil, nil, nil)
+
+	get := func(userID, ifNoneMatch string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("code")
+		c.SetParamValues("game-1")
+		c.Set("user_id", userID)
+		if err := h.GetGame(c); err != nil {
+			t.Fatalf("%s: GetGame: %v", userID, err)
+		}
+		return rec
+	}
+
+	liar, player := get("alice", ""), get("bob", "")
+	if liar.Header().Get("ETag") == player.Header().Get("ETag") {
+		t.Errorf("liar and player share ETag %s for different views", liar.Header().Get("ETag"))
+	}
+	if strings.Contains(liar.Body.String(), "truth") || !strings.Contains(player.Body.String(), "truth") {
+		t.Errorf("correct answer shown to liar = %t, to player = %t, want only the player", strings.Contains(liar.Body.String(), "truth"), strings.Contains(player.Body.String(), "truth"))
+	}
+
+	tests := []struct {
+		userID string
+		etag   string
+		want   int
+	}{
+		{"alice", liar.Header().Get("ETag"), http.StatusNotModified},
+		{"bob", player.Header().Get("ETag"), http.StatusNotModified},
+		{"alice", player.Header().Get("ETag"), http.StatusOK},
+		{"bob", liar.Header().Get("ETag"), http.StatusOK},
+	}
+	for _, tt := range tests {
+		if rec := get(tt.userID, tt.etag); rec.Code != tt.want {
+			t.Errorf("%s revalidating %s: status = %d, want %d", tt.userID, tt.etag, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestIdentifyPlayer(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   string
+		guest    *domain.Guest
+		player   domain.Player
+		wantID   string
+		wantName string
+	}{
+		{
+			name:     "user",
+			userID:   "user-1",
+			player:   domain.Player{ID: "someone-else", Name: "Alice"},
+			wantID:   "user-1",
+			wantName: "Alice",
+		},
+		{
+			name:     "guest",
+			guest:    &domain.Guest{ID: "guest-1", DisplayName: "Guest 0042"},
+			player:   domain.Player{ID: "someone-else"},
+			wantID:   "guest-1",
+			wantName: "Guest 0042",
+		},
+		{
+			name:     "guest with a name",
+			guest:    &domain.Guest{ID: "guest-1", DisplayName: "Guest 0042"},
+			player:   domain.Player{Name: "Bob"},
+			wantID:   "guest-1",
+			wantName: "Bob",
+		},
+	}
+	for _, tt := range tests {
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+		if tt.userID != "" {
+			c.Set("user_id", tt.userID)
+		}
+		if tt.guest != nil {
+			c.Set("guest", tt.guest)
+		}
+
+		player := tt.player
+		identifyPlayer(c, &player)
+		if player.ID != tt.wantID || player.Name != tt.wantName {
+			t.Errorf("%s: player = %q %q, want %q %q", tt.name, player.ID, player.Name, tt.wantID, tt.wantName)
+		}
+	}
+
+	// Anonymous callers never play under the ID they sent
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+	player := domain.Player{ID: "user-1", Name: "Mallory"}
+	identifyPlayer(c, &player)
+	if player.ID == "" || player.ID == "user-1" {
+		t.Errorf("anonymous: player ID = %q, want a new ID", player.ID)
+	}
+}
+
+func TestGameErrorStatus(t *testing.T) {
+	tests := []struct {
+		err       error
+		wantJoin  int
+		wantStart int
+	}{
+		{fmt.Errorf("%w: ABCD", domain.ErrGameNotFound), http.StatusNotFound, http.StatusNotFound},
+		{service.ErrGameFull, http.StatusConflict, http.StatusInternalServerError},
+		{service.ErrGameInProgress, http.StatusConflict, http.StatusConflict},
+		{service.ErrPlayerInGame, http.StatusConflict, http.StatusInternalServerError},
+		{service.ErrNotHost, http.StatusInternalServerError, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		h := NewGameHandler(failingGames{err: tt.err}, nil, nil, nil, nil, nil)
+
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"Alice"}`)), rec)
+		c.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		c.SetParamNames("code")
+		c.SetParamValues("game-1")
+		c.Set("user_id", "user-1")
+		if err := h.JoinGame(c); err != nil {
+			t.Fatalf("%v: JoinGame: %v", tt.err, err)
+		}
+		if rec.Code != tt.wantJoin {
+			t.Errorf("%v: JoinGame status = %d, want %d", tt.err, rec.Code, tt.wantJoin)
+		}
+
+		c = echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), httptest.NewRecorder())
+		c.SetParamNames("code")
+		c.SetParamValues("game-1")
+		c.Set("user_id", "user-1")
+		var httpErr *echo.HTTPError
+		if err := h.StartGame(c); !errors.As(err, &httpErr) || httpErr.Code != tt.wantStart {
+			t.Errorf("%v: StartGame error = %v, want status %d", tt.err, err, tt.wantStart)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// HallOfFameHandler handles the hall of fame of funny fake answers
+type HallOfFameHandler struct {
+	hallOfFameService *service.HallOfFameService
+}
+
+// NewHallOfFameHandler creates a new hall of fame handler
+func NewHallOfFameHandler(hallOfFameService *service.HallOfFameService) *HallOfFameHandler {
+	return &HallOfFameHandler{
+		hallOfFameService: hallOfFameService,
+	}
+}
+
+// ListHallOfFame godoc
+// @Summary List the hall of fame
+// @Description List the fake answers liked the most during reveals, with the question they answered. Answers are only listed while their authors have opted in with their hall_of_fame preference.
+// @Tags games
+// @Produce json
+// @Param limit query int false "Page size"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param order query string false "asc or desc (default)"
+// @Success 200 {object} pagination.Page[domain.HallOfFameEntry]
+// @Failure 400 {object} ErrorResponse
+// @Router /hall-of-fame [get]
+func (h *HallOfFameHandler) ListHallOfFame(c echo.Context) error {
+	params, err := pagination.Parse(c.QueryParams(), domain.HallOfFameOptions)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	page, err := h.hallOfFameService.List(c.Request().Context(), params)
+	if err != nil {
+		switch {
+		case errors.Is(err, pagination.ErrInvalidCursor), errors.Is(err, pagination.ErrInvalidSort):
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: localizeError(c, err),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: t(c, "error.hall_of_fame_failed"),
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/i18n"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+	"github.com/zizouhuweidi/dahaa/internal/validation"
+)
+
+// languageParam lets clients choose a language without setting headers
+const languageParam = "lang"
+
+// errorMessages maps errors to the IDs of their translated messages. Errors
+// are matched with errors.Is in order.
+var errorMessages = []struct {
+	err error
+	id  string
+}{
+	{domain.ErrGameNotFound, "error.game_not_found"},
+	{service.ErrGameFull, "error.game_full"},
+	{service.ErrGameCodeTaken, "error.game_code_taken"},
+	{service.ErrGameInProgress, "error.game_in_progress"},
+	{domain.ErrGameInProgress, "error.game_in_progress"},
+	{service.ErrGameStarted, "error.game_started"},
+	{service.ErrGameNotStarted, "error.game_not_started"},
+	{domain.ErrGameNotStarted, "error.game_not_started"},
+	{service.ErrGameEnded, "error.game_ended"},
+	{domain.ErrEventConflict, "error.game_conflict"},
+	{domain.ErrIllegalTransition, "error.illegal_transition"},
+	{service.ErrNotEnoughPlayers, "error.not_enough_players"},
+	{service.ErrPlayersNotReady, "error.players_not_ready"},
+	{service.ErrNoCountdown, "error.no_countdown"},
+	{service.ErrNoTimedPhase, "error.no_timed_phase"},
+	{service.ErrNoOpenLobby, "error.no_open_lobby"},
+	{service.ErrLiarCannotVote, "error.liar_cannot_vote"},
+	{service.ErrNotRevealing, "error.not_revealing"},
+	{service.ErrUnknownOAuthProvider, "error.unknown_oauth_provider"},
+	{service.ErrOAuthExchange, "error.oauth_exchange_failed"},
+	{service.ErrInvalidLike, "error.invalid_like"},
+	{service.ErrNoCategories, "error.no_categories"},
+	{service.ErrInvalidSettings, "error.invalid_settings"},
+	{service.ErrInvalidPreferences, "error.invalid_preferences"},
+	{service.ErrUserBanned, "error.user_banned"},
+	{service.ErrUserSuspended, "error.user_suspended"},
+	{service.ErrInvalidBan, "error.invalid_ban"},
+	{service.ErrBanNotFound, "error.ban_not_found"},
+	{service.ErrInvalidSubmission, "error.invalid_submission"},
+	{service.ErrSubmissionNotFound, "error.submission_not_found"},
+	{service.ErrInvalidTrustThresholds, "error.invalid_trust_thresholds"},
+	{service.ErrInvalidPremiumPack, "error.invalid_premium_pack"},
+	{service.ErrPremiumPackNotFound, "error.premium_pack_not_found"},
+	{service.ErrEntitlementNotFound, "error.entitlement_not_found"},
+	{service.ErrPremiumPackNotOwned, "error.premium_pack_not_owned"},
+	{service.ErrEntitlementExists, "error.entitlement_exists"},
+	{service.ErrGiftToSelf, "error.gift_to_self"},
+	{service.ErrGiftLimitReached, "error.gift_limit_reached"},
+	{service.ErrGiftTooRecent, "error.gift_too_recent"},
+	{service.ErrPromoCodeNotFound, "error.promo_code_not_found"},
+	{service.ErrPromoCodeExists, "error.promo_code_exists"},
+	{service.ErrPromoBatchNotFound, "error.promo_batch_not_found"},
+	{service.ErrPromoCodeExhausted, "error.promo_code_exhausted"},
+	{service.ErrPromoAlreadyRedeemed, "error.promo_already_redeemed"},
+	{service.ErrPromoCodeExpired, "error.promo_code_expired"},
+	{service.ErrInvalidPromoBatch, "error.invalid_promo_batch"},
+	{service.ErrCosmeticNotFound, "error.cosmetic_not_found"},
+	{service.ErrCosmeticLocked, "error.cosmetic_locked"},
+	{service.ErrInvalidCosmetic, "error.invalid_cosmetic"},
+	{service.ErrTenantNotFound, "error.tenant_not_found"},
+	{service.ErrInvalidTenant, "error.invalid_tenant"},
+	{service.ErrInvalidTenantRole, "error.invalid_tenant_role"},
+	{service.ErrNotTenantMember, "error.not_tenant_member"},
+	{service.ErrNotTenantAdmin, "error.not_tenant_admin"},
+	{service.ErrTenantSelf, "error.tenant_self"},
+	{service.ErrTenantGame, "error.tenant_game"},
+	{service.ErrSSONotConfigured, "error.sso_not_configured"},
+	{service.ErrInvalidSSOConfig, "error.invalid_sso_config"},
+	{service.ErrOtherTenant, "error.other_tenant"},
+	{validation.ErrNameTooShort, "error.name_too_short"},
+	{validation.ErrNameTooLong, "error.name_too_long"},
+	{validation.ErrNameInvalidCharacters, "error.name_invalid_characters"},
+	{validation.ErrNameReserved, "error.name_reserved"},
+	{validation.ErrNameProfane, "error.name_profane"},
+	{validation.ErrNameConfusable, "error.name_confusable"},
+	{service.ErrPresetNotFound, "error.preset_not_found"},
+	{service.ErrPresetKeyReserved, "error.preset_key_reserved"},
+	{service.ErrTemplateNotFound, "error.template_not_found"},
+	{service.ErrNotTemplateOwner, "error.not_template_owner"},
+	{service.ErrInvalidCategory, "error.invalid_category"},
+	{service.ErrCategoriesDrawn, "error.categories_drawn"},
+	{service.ErrPlayerEliminated, "error.player_eliminated"},
+	{service.ErrInvalidRound, "error.invalid_round"},
+	{domain.ErrInvalidRound, "error.invalid_round"},
+	{service.ErrRoundNotWaiting, "error.round_not_waiting"},
+	{service.ErrRoundNotAnswering, "error.round_not_answering"},
+	{service.ErrRoundNotVoting, "error.round_not_voting"},
+	{service.ErrRoundCompleted, "error.round_completed"},
+	{service.ErrSubmissionTooLate, "error.submission_too_late"},
+	{service.ErrNoRounds, "error.no_rounds"},
+	{service.ErrNoActiveTurn, "error.no_active_turn"},
+	{service.ErrNotYourTurn, "error.not_your_turn"},
+	{service.ErrPlayerNotFound, "error.player_not_found"},
+	{domain.ErrPlayerNotFound, "error.player_not_found"},
+	{service.ErrPlayerNotInGame, "error.player_not_in_game"},
+	{service.ErrPlayerInGame, "error.player_in_game"},
+	{service.ErrAnswerSubmitted, "error.answer_submitted"},
+	{domain.ErrAnswerSubmitted, "error.answer_submitted"},
+	{service.ErrAnswerTooSimilar, "error.answer_too_similar"},
+	{service.ErrAnswerIsCorrect, "error.answer_is_correct"},
+	{service.ErrInvalidAnswer, "error.invalid_answer"},
+	{domain.ErrInvalidAnswer, "error.invalid_answer"},
+	{service.ErrVoteSubmitted, "error.vote_submitted"},
+	{domain.ErrVoteSubmitted, "error.vote_submitted"},
+	{service.ErrInvalidVote, "error.invalid_vote"},
+	{domain.ErrInvalidVote, "error.invalid_vote"},
+	{service.ErrNotHost, "error.not_host"},
+	{service.ErrCannotMuteHost, "error.cannot_mute_host"},
+	{service.ErrPartyNotFound, "error.party_not_found"},
+	{service.ErrNotPartyHost, "error.not_party_host"},
+	{service.ErrPlatformUnavailable, "error.platform_unavailable"},
+	{service.ErrInvalidStreamChannel, "error.invalid_stream_channel"},
+	{service.ErrStreamNotConnected, "error.stream_not_connected"},
+	{service.ErrUnauthenticated, "error.unauthenticated"},
+	{service.ErrInvalidPlayerToken, "error.invalid_player_token"},
+	{domain.ErrInvalidPlayerToken, "error.invalid_player_token"},
+	{service.ErrInvalidOverlayToken, "error.invalid_overlay_token"},
+	{domain.ErrInvalidOverlayToken, "error.invalid_overlay_token"},
+	{service.ErrInvalidResumeToken, "error.invalid_resume_token"},
+	{domain.ErrInvalidResumeToken, "error.invalid_resume_token"},
+	{service.ErrUserNotFound, "error.user_not_found"},
+	{domain.ErrUserNotFound, "error.user_not_found"},
+	{service.ErrInviteNotFound, "error.invite_not_found"},
+	{service.ErrInviteExpired, "error.invite_expired"},
+	{service.ErrInviteNotPending, "error.invite_not_pending"},
+	{service.ErrNotInviteSender, "error.not_invite_sender"},
+	{service.ErrNotInviteRecipient, "error.not_invite_recipient"},
+	{service.ErrInvalidVerificationToken, "error.invalid_verification_token"},
+	{service.ErrAlreadyVerified, "error.already_verified"},
+	{service.ErrEmailNotVerified, "error.email_not_verified"},
+	{domain.ErrSelfRivalry, "error.self_rivalry"},
+	{domain.ErrInvalidDateFilter, "error.invalid_date_filter"},
+	{domain.ErrResultNotFound, "error.results_not_found"},
+	{domain.ErrImportJobNotFound, "error.import_job_not_found"},
+	{service.ErrTooManyImports, "error.too_many_imports"},
+	{domain.ErrCategoryNotFound, "error.category_not_found"},
+	{domain.ErrQuestionNotFound, "error.question_not_found"},
+	{domain.ErrCategoryExists, "error.category_exists"},
+	{service.ErrNothingToRelease, "error.nothing_to_release"},
+	{pagination.ErrInvalidCursor, "error.invalid_cursor"},
+	{pagination.ErrInvalidSort, "error.invalid_sort"},
+	{pagination.ErrInvalidLimit, "error.invalid_limit"},
+	{pagination.ErrInvalidOrder, "error.invalid_order"},
+}
+
+// Localize picks the language of each request from the lang query parameter
+// or the Accept-Language header, and makes its localizer available to
+// handlers through the request context
+func Localize(bundle *i18n.Bundle) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			localizer := bundle.Localizer(c.QueryParam(languageParam), c.Request().Header.Get("Accept-Language"))
+
+			ctx := i18n.WithLocalizer(c.Request().Context(), localizer)
+			c.SetRequest(c.Request().WithContext(ctx))
+			c.Response().Header().Set("Content-Language", localizer.Language())
+
+			return next(c)
+		}
+	}
+}
+
+// t renders a message in the request's language
+func t(c echo.Context, id string, data ...any) string {
+	return i18n.FromContext(c.Request().Context()).T(id, data...)
+}
+
+// localizeError renders an error in the request's language. Validation
+// errors describe their first failing field; unknown errors are passed
+// through untranslated.
+func localizeError(c echo.Context, err error) string {
+	var fieldErrors validator.ValidationErrors
+	if errors.As(err, &fieldErrors) && len(fieldErrors) > 0 {
+		return localizeFieldError(c, fieldErrors[0])
+	}
+
+	return localizeErrorIn(i18n.FromContext(c.Request().Context()), err)
+}
+
+// localizeErrorIn renders an error in a localizer's language, for errors
+// reported outside a request such as those of WebSocket commands
+func localizeErrorIn(localizer *i18n.Localizer, err error) string {
+	for _, m := range errorMessages {
+		if errors.Is(err, m.err) {
+			return localizer.T(m.id)
+		}
+	}
+	return err.Error()
+}
+
+// localizeFieldError describes a failed validation rule
+func localizeFieldError(c echo.Context, fe validator.FieldError) string {
+	id := "validation." + fe.Tag()
+	localizer := i18n.FromContext(c.Request().Context())
+	if !localizer.Has(id) {
+		id = "validation.invalid"
+	}
+	return localizer.T(id, map[string]string{
+		"Field": fe.Field(),
+		"Param": fe.Param(),
+	})
+}
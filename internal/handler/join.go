@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/i18n"
+	"github.com/zizouhuweidi/dahaa/internal/qrcode"
+)
+
+const (
+	// defaultQRSize is the width of a join QR code in pixels unless the size
+	// query parameter asks otherwise
+	defaultQRSize = 256
+
+	// minQRSize and maxQRSize bound the width clients may ask for
+	minQRSize = 64
+	maxQRSize = 1024
+)
+
+// JoinHandler serves the links and QR codes players use to join games
+type JoinHandler struct {
+	gameService domain.GameService
+	joinURL     string
+}
+
+// NewJoinHandler creates a new join handler. A game's join link is its join
+// code appended to joinURL, the page of the web client that joins games.
+func NewJoinHandler(gameService domain.GameService, joinURL string) *JoinHandler {
+	return &JoinHandler{
+		gameService: gameService,
+		joinURL:     strings.TrimSuffix(joinURL, "/"),
+	}
+}
+
+// joinLink returns the shareable link that joins a game
+func (h *JoinHandler) joinLink(game *domain.Game) string {
+	return h.joinURL + "/" + url.PathEscape(game.Code)
+}
+
+// GetJoinQR renders a PNG QR code of a game's join link, so a shared screen
+// can show players a code to scan. The size query parameter sets its width
+// in pixels.
+func (h *JoinHandler) GetJoinQR(c echo.Context) error {
+	size := defaultQRSize
+	if raw := c.QueryParam("size"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < minQRSize || n > maxQRSize {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": t(c, "error.invalid_qr_size", map[string]int{"Min": minQRSize, "Max": maxQRSize}),
+			})
+		}
+		size = n
+	}
+
+	game, err := h.gameService.GetGame(c.Request().Context(), c.Param("code"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": t(c, "error.game_not_found"),
+		})
+	}
+	if game.Status == domain.GameStatusEnded {
+		return c.JSON(http.StatusGone, map[string]string{
+			"error": t(c, "error.game_ended"),
+		})
+	}
+
+	code, err := qrcode.Encode(h.joinLink(game))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": localizeError(c, err),
+		})
+	}
+
+	png, err := code.PNG(size)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": localizeError(c, err),
+		})
+	}
+
+	// A game's join link never changes
+	c.Response().Header().Set(echo.HeaderCacheControl, "private, max-age=3600")
+	return c.Blob(http.StatusOK, "image/png", png)
+}
+
+// GetPreview returns the metadata chat apps show when unfurling a game's join
+// link: its host, player count, categories and status, with a title and
+// description written in the request's language
+func (h *JoinHandler) GetPreview(c echo.Context) error {
+	game, err := h.gameService.GetGame(c.Request().Context(), c.Param("code"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": t(c, "error.game_not_found"),
+		})
+	}
+
+	preview := game.Preview()
+	preview.URL = h.joinLink(game)
+
+	localizer := i18n.FromContext(c.Request().Context())
+	for i, category := range preview.Categories {
+		if id := "category." + category; localizer.Has(id) {
+			preview.Categories[i] = localizer.T(id)
+		}
+	}
+
+	preview.Title = t(c, "preview.title", map[string]any{"host": preview.HostName})
+	preview.Description = t(c, "preview.description."+string(preview.Status), map[string]any{
+		"players":     preview.PlayerCount,
+		"max_players": preview.MaxPlayers,
+		"categories":  strings.Join(preview.Categories, ", "),
+	})
+
+	// Unfurls are fetched once per share, so a short lifetime keeps them
+	// close to the lobby without refetching on every view
+	c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=60")
+	return c.JSON(http.StatusOK, preview)
+}
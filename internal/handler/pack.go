@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// PackHandler handles question pack releases
+type PackHandler struct {
+	packService *service.PackService
+}
+
+// NewPackHandler creates a new pack handler
+func NewPackHandler(packService *service.PackService) *PackHandler {
+	return &PackHandler{
+		packService: packService,
+	}
+}
+
+// PublishPackRequest represents an admin releasing a pack's new questions
+type PublishPackRequest struct {
+	Notes string `json:"notes" validate:"max=2000"`
+}
+
+// GetChangelog godoc
+// @Summary Get the packs changelog
+// @Description Get the latest version of each question pack and the releases newer than a version, newest first. Clients compare the latest versions with the ones they last showed to announce new packs.
+// @Tags packs
+// @Produce json
+// @Param language query string false "Only this language's pack"
+// @Param since query int false "Only releases newer than this version"
+// @Success 200 {object} domain.PackChangelog
+// @Failure 400 {object} ErrorResponse
+// @Router /packs/changelog [get]
+func (h *PackHandler) GetChangelog(c echo.Context) error {
+	since := 0
+	if raw := c.QueryParam("since"); raw != "" {
+		var err error
+		if since, err = strconv.Atoi(raw); err != nil || since < 0 {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: t(c, "error.invalid_pack_version"),
+			})
+		}
+	}
+
+	changelog, err := h.packService.GetChangelog(c.Request().Context(), c.QueryParam("language"), since)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.get_pack_changelog_failed"),
+		})
+	}
+
+	return c.JSON(http.StatusOK, changelog)
+}
+
+// PublishPack godoc
+// @Summary Publish a pack version
+// @Description Release the questions added to a language's pack since its last release as its next version. Games already created keep drawing from the version they were created with.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param language path string true "Language of the pack"
+// @Param request body PublishPackRequest true "Release notes"
+// @Success 201 {object} domain.PackRelease
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/packs/{language}/releases [post]
+func (h *PackHandler) PublishPack(c echo.Context) error {
+	var req PublishPackRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	release, err := h.packService.PublishPack(c.Request().Context(), c.Param("language"), req.Notes)
+	if err != nil {
+		if errors.Is(err, service.ErrNothingToRelease) {
+			return c.JSON(http.StatusConflict, ErrorResponse{
+				Error: localizeError(c, err),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.publish_pack_failed"),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, release)
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// PresetHandler handles requests for game settings presets
+type PresetHandler struct {
+	presetService *service.PresetService
+}
+
+// NewPresetHandler creates a new preset handler
+func NewPresetHandler(presetService *service.PresetService) *PresetHandler {
+	return &PresetHandler{
+		presetService: presetService,
+	}
+}
+
+// SavePresetRequest represents the request body for saving a preset
+type SavePresetRequest struct {
+	Name     string              `json:"name" validate:"required,max=100"`
+	Settings domain.GameSettings `json:"settings"`
+}
+
+// ListPresets godoc
+// @Summary List settings presets
+// @Description List the built-in game settings presets, followed by the current user's own when signed in
+// @Tags games
+// @Produce json
+// @Success 200 {array} domain.SettingsPreset
+// @Router /games/presets [get]
+func (h *PresetHandler) ListPresets(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	presets, err := h.presetService.ListPresets(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.get_presets_failed"),
+		})
+	}
+
+	return c.JSON(http.StatusOK, presets)
+}
+
+// SavePreset godoc
+// @Summary Save a settings preset
+// @Description Save game settings under a key in the current user's account, replacing any preset with the same key
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param key path string true "Preset key"
+// @Param preset body SavePresetRequest true "Preset name and settings"
+// @Success 200 {object} domain.SettingsPreset
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/me/presets/{key} [put]
+func (h *PresetHandler) SavePreset(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+	key := c.Param("key")
+	if len(key) > 32 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_preset_key"),
+		})
+	}
+
+	var req SavePresetRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	preset, err := h.presetService.SavePreset(c.Request().Context(), userID, key, req.Name, req.Settings)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnauthenticated):
+			return c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error: t(c, "error.authentication_required"),
+			})
+		case errors.Is(err, service.ErrInvalidSettings):
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: localizeError(c, err),
+			})
+		case errors.Is(err, service.ErrPresetKeyReserved):
+			return c.JSON(http.StatusConflict, ErrorResponse{
+				Error: localizeError(c, err),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: t(c, "error.save_preset_failed"),
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, preset)
+}
+
+// DeletePreset godoc
+// @Summary Delete a settings preset
+// @Description Delete one of the current user's settings presets
+// @Tags users
+// @Param key path string true "Preset key"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/me/presets/{key} [delete]
+func (h *PresetHandler) DeletePreset(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	if err := h.presetService.DeletePreset(c.Request().Context(), userID, c.Param("key")); err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnauthenticated):
+			return c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error: t(c, "error.authentication_required"),
+			})
+		case errors.Is(err, service.ErrPresetNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: localizeError(c, err),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: t(c, "error.delete_preset_failed"),
+			})
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
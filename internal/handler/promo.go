@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// PromoHandler handles promo codes and their redemption
+type PromoHandler struct {
+	promoService *service.PromoService
+}
+
+// NewPromoHandler creates a new promo handler
+func NewPromoHandler(promoService *service.PromoService) *PromoHandler {
+	return &PromoHandler{
+		promoService: promoService,
+	}
+}
+
+// PromoBatchResponse represents a created batch along with its codes
+type PromoBatchResponse struct {
+	*domain.PromoBatch
+	PromoCodes []string `json:"promo_codes"`
+}
+
+// RedeemPromoCodeRequest represents a user redeeming a promo code
+type RedeemPromoCodeRequest struct {
+	Code string `json:"code" validate:"required,max=64"`
+}
+
+// CreateBatch godoc
+// @Summary Create a batch of promo codes
+// @Description Create promo codes unlocking a premium pack or a cosmetic, generating count codes or using a single custom code. Each code may be redeemed max_redemptions times until expires_at, and each user may redeem one code of a batch.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param batch body service.CreatePromoBatchRequest true "Reward, codes, limits and acting moderator"
+// @Success 201 {object} PromoBatchResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/promo-batches [post]
+func (h *PromoHandler) CreateBatch(c echo.Context) error {
+	var req service.CreatePromoBatchRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	batch, codes, err := h.promoService.CreateBatch(c.Request().Context(), req)
+	if err != nil {
+		return promoError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, PromoBatchResponse{
+		PromoBatch: batch,
+		PromoCodes: codes,
+	})
+}
+
+// ListBatches godoc
+// @Summary List promo batches
+// @Description List every batch of promo codes, newest first
+// @Tags admin
+// @Produce json
+// @Success 200 {array} domain.PromoBatch
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/promo-batches [get]
+func (h *PromoHandler) ListBatches(c echo.Context) error {
+	batches, err := h.promoService.ListBatches(c.Request().Context())
+	if err != nil {
+		return promoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, batches)
+}
+
+// ListCodes godoc
+// @Summary List a batch's promo codes
+// @Description List a batch's codes and how often each was redeemed
+// @Tags admin
+// @Produce json
+// @Param id path string true "Batch ID"
+// @Success 200 {array} domain.PromoCode
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/promo-batches/{id}/codes [get]
+func (h *PromoHandler) ListCodes(c echo.Context) error {
+	codes, err := h.promoService.ListCodes(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return promoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, codes)
+}
+
+// Redeem godoc
+// @Summary Redeem a promo code
+// @Description Redeem a promo code for its premium pack or cosmetic. Codes may be typed in any case, with spaces or dashes.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body RedeemPromoCodeRequest true "Promo code"
+// @Success 201 {object} domain.PromoRedemption
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /users/me/redemptions [post]
+func (h *PromoHandler) Redeem(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	var req RedeemPromoCodeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	redemption, err := h.promoService.Redeem(c.Request().Context(), userID, req.Code)
+	if err != nil {
+		return promoError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, redemption)
+}
+
+// ListRedemptions godoc
+// @Summary List my redeemed promo codes
+// @Description List the promo codes the user has redeemed, newest first
+// @Tags users
+// @Produce json
+// @Success 200 {array} domain.PromoRedemption
+// @Failure 401 {object} ErrorResponse
+// @Router /users/me/redemptions [get]
+func (h *PromoHandler) ListRedemptions(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	redemptions, err := h.promoService.ListRedemptions(c.Request().Context(), userID)
+	if err != nil {
+		return promoError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, redemptions)
+}
+
+// promoError writes the response for a promo service error
+func promoError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidPromoBatch):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, service.ErrUnauthenticated):
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: t(c, "error.authentication_required"),
+		})
+	case errors.Is(err, service.ErrPromoCodeNotFound), errors.Is(err, service.ErrPromoBatchNotFound),
+		errors.Is(err, service.ErrPremiumPackNotFound), errors.Is(err, service.ErrCosmeticNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, service.ErrPromoCodeExists), errors.Is(err, service.ErrPromoAlreadyRedeemed),
+		errors.Is(err, service.ErrEntitlementExists):
+		return c.JSON(http.StatusConflict, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, service.ErrPromoCodeExpired), errors.Is(err, service.ErrPromoCodeExhausted):
+		return c.JSON(http.StatusGone, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.promo_failed"),
+		})
+	}
+}
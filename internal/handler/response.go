@@ -3,4 +3,5 @@ package handler
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error string `json:"error"`
+	Code  string `json:"code,omitempty"` // Machine-readable reason, for errors clients act on
 }
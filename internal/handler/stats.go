@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/card"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// StatsHandler handles requests for players' records
+type StatsHandler struct {
+	statsService *service.StatsService
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(statsService *service.StatsService) *StatsHandler {
+	return &StatsHandler{
+		statsService: statsService,
+	}
+}
+
+// GetProfile godoc
+// @Summary Get profile
+// @Description Get the current user's profile, including their guessing accuracy and fool rate in each category they have played
+// @Tags users
+// @Produce json
+// @Success 200 {object} domain.UserProfile
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/me/profile [get]
+func (h *StatsHandler) GetProfile(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	profile, err := h.statsService.GetProfile(c.Request().Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnauthenticated):
+			return c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error: t(c, "error.authentication_required"),
+			})
+		case errors.Is(err, domain.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: t(c, "error.user_not_found"),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: t(c, "error.get_profile_failed"),
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// GetPublicResults godoc
+// @Summary Get public game results
+// @Description Get the results of a finished game through its share link, without signing in. Players are only known by their names; no IDs or emails are included. The share token is in the results sent to players when the game ends.
+// @Tags games
+// @Produce json
+// @Param code path string true "Game code"
+// @Param token query string true "Share token"
+// @Success 200 {object} domain.PublicResult
+// @Failure 404 {object} ErrorResponse
+// @Router /games/{code}/public-results [get]
+func (h *StatsHandler) GetPublicResults(c echo.Context) error {
+	result, err := h.statsService.GetPublicResults(c.Request().Context(), c.Param("code"), c.QueryParam("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrResultNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: t(c, "error.results_not_found"),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: t(c, "error.get_results_failed"),
+			})
+		}
+	}
+
+	// Results never change once a game has ended
+	c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=3600")
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetResultCard godoc
+// @Summary Get game result card
+// @Description Render a scorecard image of a finished game through its share link, showing the winner, the podium, awards and highlights, for players to share to chat apps. PNG cards draw names with a built-in font covering ASCII; SVG cards show them as they are.
+// @Tags games
+// @Produce png
+// @Produce image/svg+xml
+// @Param code path string true "Game code"
+// @Param token query string true "Share token"
+// @Param format query string false "png (default) or svg"
+// @Success 200 {file} binary
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /games/{code}/card [get]
+func (h *StatsHandler) GetResultCard(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format != "" && format != "png" && format != "svg" {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_card_format"),
+		})
+	}
+
+	result, err := h.statsService.GetPublicResults(c.Request().Context(), c.Param("code"), c.QueryParam("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrResultNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: t(c, "error.results_not_found"),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: t(c, "error.get_results_failed"),
+			})
+		}
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=3600")
+	if format == "svg" {
+		return c.Blob(http.StatusOK, "image/svg+xml", card.SVG(result))
+	}
+
+	png, err := card.PNG(result)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.get_results_failed"),
+		})
+	}
+	return c.Blob(http.StatusOK, "image/png", png)
+}
+
+// GetHeadToHead godoc
+// @Summary Get head-to-head record
+// @Description Get the current user's wins, losses, times fooled each way and biggest blowout against another user
+// @Tags users
+// @Produce json
+// @Param user_id path string true "Opponent user ID"
+// @Success 200 {object} domain.HeadToHead
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/me/rivals/{user_id} [get]
+func (h *StatsHandler) GetHeadToHead(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	record, err := h.statsService.GetHeadToHead(c.Request().Context(), userID, c.Param("user_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnauthenticated):
+			return c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error: t(c, "error.authentication_required"),
+			})
+		case errors.Is(err, domain.ErrSelfRivalry):
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: localizeError(c, err),
+			})
+		case errors.Is(err, domain.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: t(c, "error.user_not_found"),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: t(c, "error.get_head_to_head_failed"),
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, record)
+}
+
+// GetGameHistory godoc
+// @Summary Get game history
+// @Description Get the current user's past games with their final position, points and per-round performance
+// @Tags users
+// @Produce json
+// @Param limit query int false "Page size"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param order query string false "asc or desc (default)"
+// @Param from query string false "Only games ended on or after this date or RFC 3339 time"
+// @Param to query string false "Only games ended on or before this date, or before this RFC 3339 time"
+// @Success 200 {object} pagination.Page[domain.GameHistoryEntry]
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /users/me/history [get]
+func (h *StatsHandler) GetGameHistory(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	params, err := pagination.Parse(c.QueryParams(), domain.GameHistoryOptions)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	page, err := h.statsService.GetGameHistory(c.Request().Context(), userID, params)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnauthenticated):
+			return c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error: t(c, "error.authentication_required"),
+			})
+		case errors.Is(err, domain.ErrInvalidDateFilter), errors.Is(err, pagination.ErrInvalidCursor), errors.Is(err, pagination.ErrInvalidSort):
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: localizeError(c, err),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: t(c, "error.get_history_failed"),
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// GetStatsRollups godoc
+// @Summary Get stats summaries
+// @Description Get the current user's games, wins, average score and favorite category for recent weeks or months
+// @Tags users
+// @Produce json
+// @Param period query string false "week (default) or month"
+// @Param limit query int false "Number of periods, newest first (default 1)"
+// @Success 200 {array} domain.StatsRollup
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /users/me/stats [get]
+func (h *StatsHandler) GetStatsRollups(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	period := domain.StatsPeriodWeek
+	if raw := c.QueryParam("period"); raw != "" {
+		parsed, err := domain.ParseStatsPeriod(raw)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: t(c, "error.invalid_stats_period"),
+			})
+		}
+		period = parsed
+	}
+
+	var limit int
+	if raw := c.QueryParam("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: t(c, "error.invalid_limit"),
+			})
+		}
+		limit = parsed
+	}
+
+	rollups, err := h.statsService.GetStatsRollups(c.Request().Context(), userID, period, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrUnauthenticated) {
+			return c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error: t(c, "error.authentication_required"),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.get_stats_failed"),
+		})
+	}
+
+	return c.JSON(http.StatusOK, rollups)
+}
@@ -0,0 +1,301 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// SubmissionHandler handles users' question submissions and moderators'
+// review of them
+type SubmissionHandler struct {
+	submissionService *service.SubmissionService
+}
+
+// NewSubmissionHandler creates a new submission handler
+func NewSubmissionHandler(submissionService *service.SubmissionService) *SubmissionHandler {
+	return &SubmissionHandler{
+		submissionService: submissionService,
+	}
+}
+
+// ReviewSubmissionRequest represents a moderator approving or rejecting a
+// submission
+type ReviewSubmissionRequest struct {
+	Moderator string `json:"moderator" validate:"required,max=100"`
+	Note      string `json:"note" validate:"max=2000"`
+}
+
+// SubmitQuestion godoc
+// @Summary Submit a question
+// @Description Contribute a question. Trusted contributors' questions are added straight away; the others wait for a moderator's review.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param question body CreateQuestionRequest true "Question"
+// @Success 201 {object} domain.QuestionSubmission
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /users/me/submissions [post]
+func (h *SubmissionHandler) SubmitQuestion(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	var req CreateQuestionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	submission, err := h.submissionService.SubmitQuestion(c.Request().Context(), userID, domain.Question{
+		Text:          req.Text,
+		Answer:        req.Answer,
+		Category:      req.Category,
+		Difficulty:    req.Difficulty,
+		Language:      req.Language,
+		Rating:        domain.ContentRating(req.Rating),
+		FillerAnswers: req.FillerAnswers,
+	})
+	if err != nil {
+		return submissionError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, submission)
+}
+
+// ListUserSubmissions godoc
+// @Summary List my submissions
+// @Description List the questions the user submitted, newest first, with their review
+// @Tags users
+// @Produce json
+// @Param limit query int false "Number of submissions (default 50, at most 500)"
+// @Success 200 {array} domain.QuestionSubmission
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /users/me/submissions [get]
+func (h *SubmissionHandler) ListUserSubmissions(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	limit, ok := parseLimit(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_limit"),
+		})
+	}
+
+	submissions, err := h.submissionService.ListUserSubmissions(c.Request().Context(), userID, limit)
+	if err != nil {
+		return submissionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, submissions)
+}
+
+// GetOwnReputation godoc
+// @Summary Get my contributor reputation
+// @Description Get the user's approved, rejected and pending submissions and the trust level they earn
+// @Tags users
+// @Produce json
+// @Success 200 {object} domain.ContributorReputation
+// @Failure 401 {object} ErrorResponse
+// @Router /users/me/reputation [get]
+func (h *SubmissionHandler) GetOwnReputation(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+	if userID == "" {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: t(c, "error.authentication_required"),
+		})
+	}
+	return h.reputation(c, userID)
+}
+
+// GetReputation godoc
+// @Summary Get a contributor's reputation
+// @Description Get a user's approved, rejected and pending submissions and the trust level they earn
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} domain.ContributorReputation
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/users/{id}/reputation [get]
+func (h *SubmissionHandler) GetReputation(c echo.Context) error {
+	return h.reputation(c, c.Param("id"))
+}
+
+// ListQueue godoc
+// @Summary List the moderation queue
+// @Description List the submissions waiting for review, oldest first
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Number of submissions (default 50, at most 500)"
+// @Success 200 {array} domain.QuestionSubmission
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/submissions [get]
+func (h *SubmissionHandler) ListQueue(c echo.Context) error {
+	limit, ok := parseLimit(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_limit"),
+		})
+	}
+
+	submissions, err := h.submissionService.ListQueue(c.Request().Context(), limit)
+	if err != nil {
+		return submissionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, submissions)
+}
+
+// ApproveSubmission godoc
+// @Summary Approve a submission
+// @Description Add a queued submission's question to the questions
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Submission ID"
+// @Param request body ReviewSubmissionRequest true "Acting moderator and note"
+// @Success 200 {object} domain.QuestionSubmission
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/submissions/{id}/approve [post]
+func (h *SubmissionHandler) ApproveSubmission(c echo.Context) error {
+	return reviewSubmission(c, func(req *ReviewSubmissionRequest) (*domain.QuestionSubmission, error) {
+		return h.submissionService.ApproveSubmission(c.Request().Context(), c.Param("id"), req.Moderator, req.Note)
+	})
+}
+
+// RejectSubmission godoc
+// @Summary Reject a submission
+// @Description Turn down a queued submission, noting why
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Submission ID"
+// @Param request body ReviewSubmissionRequest true "Acting moderator and note"
+// @Success 200 {object} domain.QuestionSubmission
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/submissions/{id}/reject [post]
+func (h *SubmissionHandler) RejectSubmission(c echo.Context) error {
+	return reviewSubmission(c, func(req *ReviewSubmissionRequest) (*domain.QuestionSubmission, error) {
+		return h.submissionService.RejectSubmission(c.Request().Context(), c.Param("id"), req.Moderator, req.Note)
+	})
+}
+
+// GetTrustThresholds godoc
+// @Summary Get the trust thresholds
+// @Description Get what contributors need for their submissions to skip the moderation queue
+// @Tags admin
+// @Produce json
+// @Success 200 {object} domain.TrustThresholds
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/trust-thresholds [get]
+func (h *SubmissionHandler) GetTrustThresholds(c echo.Context) error {
+	thresholds, err := h.submissionService.GetTrustThresholds(c.Request().Context())
+	if err != nil {
+		return submissionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, thresholds)
+}
+
+// UpdateTrustThresholds godoc
+// @Summary Update the trust thresholds
+// @Description Replace what contributors need for their submissions to skip the moderation queue
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param thresholds body domain.TrustThresholds true "Thresholds"
+// @Success 200 {object} domain.TrustThresholds
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/trust-thresholds [put]
+func (h *SubmissionHandler) UpdateTrustThresholds(c echo.Context) error {
+	var req domain.TrustThresholds
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	thresholds, err := h.submissionService.UpdateTrustThresholds(c.Request().Context(), req)
+	if err != nil {
+		return submissionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, thresholds)
+}
+
+// reputation responds with a user's contributor reputation
+func (h *SubmissionHandler) reputation(c echo.Context, userID string) error {
+	reputation, err := h.submissionService.GetReputation(c.Request().Context(), userID)
+	if err != nil {
+		return submissionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, reputation)
+}
+
+// reviewSubmission binds and validates a review request, applies it with
+// review and responds with the submission
+func reviewSubmission(c echo.Context, review func(req *ReviewSubmissionRequest) (*domain.QuestionSubmission, error)) error {
+	var req ReviewSubmissionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	submission, err := review(&req)
+	if err != nil {
+		return submissionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, submission)
+}
+
+// submissionError writes the response for a submission service error
+func submissionError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidSubmission), errors.Is(err, service.ErrInvalidTrustThresholds):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, service.ErrUnauthenticated):
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: t(c, "error.authentication_required"),
+		})
+	case errors.Is(err, service.ErrSubmissionNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, domain.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: t(c, "error.user_not_found"),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.save_submission_failed"),
+		})
+	}
+}
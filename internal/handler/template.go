@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// TemplateHandler handles requests for game templates
+type TemplateHandler struct {
+	templateService *service.TemplateService
+}
+
+// NewTemplateHandler creates a new template handler
+func NewTemplateHandler(templateService *service.TemplateService) *TemplateHandler {
+	return &TemplateHandler{
+		templateService: templateService,
+	}
+}
+
+// SaveTemplateRequest represents the request body for creating or updating a template
+type SaveTemplateRequest struct {
+	Name        string              `json:"name" validate:"required,max=100"`
+	Description string              `json:"description" validate:"max=500"`
+	Settings    domain.GameSettings `json:"settings"`
+}
+
+// ListTemplates godoc
+// @Summary List game templates
+// @Description List the global game templates, followed by the current user's own when signed in
+// @Tags templates
+// @Produce json
+// @Success 200 {array} domain.GameTemplate
+// @Router /templates [get]
+func (h *TemplateHandler) ListTemplates(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	templates, err := h.templateService.ListTemplates(c.Request().Context(), userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.get_templates_failed"),
+		})
+	}
+
+	return c.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate godoc
+// @Summary Get a game template
+// @Description Get a game template by its ID, such as one shared by another user
+// @Tags templates
+// @Produce json
+// @Param id path string true "Template ID"
+// @Success 200 {object} domain.GameTemplate
+// @Failure 404 {object} ErrorResponse
+// @Router /templates/{id} [get]
+func (h *TemplateHandler) GetTemplate(c echo.Context) error {
+	template, err := h.templateService.GetTemplate(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return templateError(c, err, "error.get_templates_failed")
+	}
+
+	return c.JSON(http.StatusOK, template)
+}
+
+// CreateTemplate godoc
+// @Summary Create a game template
+// @Description Create a game template owned by the current user
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param template body SaveTemplateRequest true "Template name, description and settings"
+// @Success 201 {object} domain.GameTemplate
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /templates [post]
+func (h *TemplateHandler) CreateTemplate(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	return saveTemplate(c, http.StatusCreated, func(req *SaveTemplateRequest) (*domain.GameTemplate, error) {
+		return h.templateService.CreateTemplate(c.Request().Context(), userID, req.Name, req.Description, req.Settings)
+	})
+}
+
+// UpdateTemplate godoc
+// @Summary Update a game template
+// @Description Replace the name, description and settings of one of the current user's templates
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param template body SaveTemplateRequest true "Template name, description and settings"
+// @Success 200 {object} domain.GameTemplate
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /templates/{id} [put]
+func (h *TemplateHandler) UpdateTemplate(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	return saveTemplate(c, http.StatusOK, func(req *SaveTemplateRequest) (*domain.GameTemplate, error) {
+		return h.templateService.UpdateTemplate(c.Request().Context(), userID, c.Param("id"), req.Name, req.Description, req.Settings)
+	})
+}
+
+// DeleteTemplate godoc
+// @Summary Delete a game template
+// @Description Delete one of the current user's templates
+// @Tags templates
+// @Param id path string true "Template ID"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /templates/{id} [delete]
+func (h *TemplateHandler) DeleteTemplate(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	if err := h.templateService.DeleteTemplate(c.Request().Context(), userID, c.Param("id")); err != nil {
+		return templateError(c, err, "error.delete_template_failed")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CreateGlobalTemplate godoc
+// @Summary Create a global game template
+// @Description Create a game template listed for everyone
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param template body SaveTemplateRequest true "Template name, description and settings"
+// @Success 201 {object} domain.GameTemplate
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/templates [post]
+func (h *TemplateHandler) CreateGlobalTemplate(c echo.Context) error {
+	return saveTemplate(c, http.StatusCreated, func(req *SaveTemplateRequest) (*domain.GameTemplate, error) {
+		return h.templateService.CreateGlobalTemplate(c.Request().Context(), req.Name, req.Description, req.Settings)
+	})
+}
+
+// UpdateGlobalTemplate godoc
+// @Summary Update a global game template
+// @Description Replace the name, description and settings of a global template
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param template body SaveTemplateRequest true "Template name, description and settings"
+// @Success 200 {object} domain.GameTemplate
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/templates/{id} [put]
+func (h *TemplateHandler) UpdateGlobalTemplate(c echo.Context) error {
+	return saveTemplate(c, http.StatusOK, func(req *SaveTemplateRequest) (*domain.GameTemplate, error) {
+		return h.templateService.UpdateGlobalTemplate(c.Request().Context(), c.Param("id"), req.Name, req.Description, req.Settings)
+	})
+}
+
+// DeleteGlobalTemplate godoc
+// @Summary Delete a global game template
+// @Description Delete a global template
+// @Tags admin
+// @Param id path string true "Template ID"
+// @Success 204
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/templates/{id} [delete]
+func (h *TemplateHandler) DeleteGlobalTemplate(c echo.Context) error {
+	if err := h.templateService.DeleteGlobalTemplate(c.Request().Context(), c.Param("id")); err != nil {
+		return templateError(c, err, "error.delete_template_failed")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// saveTemplate binds and validates a template request body, saves it with
+// save and responds with the saved template
+func saveTemplate(c echo.Context, status int, save func(req *SaveTemplateRequest) (*domain.GameTemplate, error)) error {
+	var req SaveTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	template, err := save(&req)
+	if err != nil {
+		return templateError(c, err, "error.save_template_failed")
+	}
+
+	return c.JSON(status, template)
+}
+
+// templateError writes the response for a template service error, falling
+// back to the failedID message for unexpected errors
+func templateError(c echo.Context, err error, failedID string) error {
+	switch {
+	case errors.Is(err, service.ErrUnauthenticated):
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: t(c, "error.authentication_required"),
+		})
+	case errors.Is(err, service.ErrInvalidSettings):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, service.ErrNotTemplateOwner):
+		return c.JSON(http.StatusForbidden, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, service.ErrTemplateNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, failedID),
+		})
+	}
+}
@@ -0,0 +1,394 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// TenantHandler handles organizations, their members and their leaderboards
+type TenantHandler struct {
+	tenantService *service.TenantService
+}
+
+// NewTenantHandler creates a new tenant handler
+func NewTenantHandler(tenantService *service.TenantService) *TenantHandler {
+	return &TenantHandler{
+		tenantService: tenantService,
+	}
+}
+
+// SaveTenant godoc
+// @Summary Create or rename an organization
+// @Description Create an organization, whose members play among themselves, or rename one. IDs are at most 30 letters, digits, dashes and underscores, and prefix the categories of the organization's private packs.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param tenant body service.SaveTenantRequest true "Name"
+// @Success 200 {object} domain.Tenant
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/tenants/{id} [put]
+func (h *TenantHandler) SaveTenant(c echo.Context) error {
+	var req service.SaveTenantRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	tenant, err := h.tenantService.SaveTenant(c.Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return tenantError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, tenant)
+}
+
+// ListTenants godoc
+// @Summary List organizations
+// @Description List every organization
+// @Tags admin
+// @Produce json
+// @Success 200 {array} domain.Tenant
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/tenants [get]
+func (h *TenantHandler) ListTenants(c echo.Context) error {
+	tenants, err := h.tenantService.ListTenants(c.Request().Context())
+	if err != nil {
+		return tenantError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, tenants)
+}
+
+// ListTenantMembers godoc
+// @Summary List an organization's members
+// @Description List the members of an organization
+// @Tags admin
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {array} domain.User
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/tenants/{id}/members [get]
+func (h *TenantHandler) ListTenantMembers(c echo.Context) error {
+	members, err := h.tenantService.ListTenantMembers(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return tenantError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, members)
+}
+
+// AssignMember godoc
+// @Summary Add a user to an organization
+// @Description Move a user into an organization with a role, taking them out of any other. This is how organizations get their first admins.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Param request body service.SetMemberRequest true "Role"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/tenants/{id}/members/{user_id} [put]
+func (h *TenantHandler) AssignMember(c echo.Context) error {
+	var req service.SetMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	user, err := h.tenantService.AssignMember(c.Request().Context(), c.Param("id"), c.Param("user_id"), req.Role)
+	if err != nil {
+		return tenantError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// UnassignMember godoc
+// @Summary Remove a user from an organization
+// @Description Take a user out of an organization. Games they already play are not affected.
+// @Tags admin
+// @Param id path string true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/tenants/{id}/members/{user_id} [delete]
+func (h *TenantHandler) UnassignMember(c echo.Context) error {
+	if err := h.tenantService.UnassignMember(c.Request().Context(), c.Param("id"), c.Param("user_id")); err != nil {
+		return tenantError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SaveSSO godoc
+// @Summary Configure an organization's single sign-on
+// @Description Let an organization's members sign in with its OpenID Connect identity provider. Members signing in for the first time get an account and join the organization. With a role claim, such as groups, the claim's values map to roles on every sign-in; values mapping to admin make the member an admin, and anyone else is a member. The client secret is never returned, and can be left out to keep the saved one.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param config body service.SaveSSORequest true "Identity provider"
+// @Success 200 {object} domain.SSOConfig
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/tenants/{id}/sso [put]
+func (h *TenantHandler) SaveSSO(c echo.Context) error {
+	var req service.SaveSSORequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	config, err := h.tenantService.SaveSSO(c.Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return tenantError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, config)
+}
+
+// GetSSO godoc
+// @Summary Get an organization's single sign-on
+// @Description Get an organization's single sign-on configuration, without its client secret
+// @Tags admin
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} domain.SSOConfig
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/tenants/{id}/sso [get]
+func (h *TenantHandler) GetSSO(c echo.Context) error {
+	config, err := h.tenantService.GetSSO(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return tenantError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, config)
+}
+
+// DeleteSSO godoc
+// @Summary Remove an organization's single sign-on
+// @Description Stop an organization's members from signing in with its identity provider. Their accounts are kept.
+// @Tags admin
+// @Param id path string true "Organization ID"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/tenants/{id}/sso [delete]
+func (h *TenantHandler) DeleteSSO(c echo.Context) error {
+	if err := h.tenantService.DeleteSSO(c.Request().Context(), c.Param("id")); err != nil {
+		return tenantError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetSSOLogin godoc
+// @Summary Get how to sign in to an organization
+// @Description Get the issuer and client ID a client sends an organization's members to their identity provider with. The code the provider gives back is redeemed at POST /users/sso/{tenant}.
+// @Tags users
+// @Produce json
+// @Param tenant path string true "Organization ID"
+// @Success 200 {object} domain.SSOLogin
+// @Failure 404 {object} ErrorResponse
+// @Router /users/sso/{tenant} [get]
+func (h *TenantHandler) GetSSOLogin(c echo.Context) error {
+	login, err := h.tenantService.SSOLogin(c.Request().Context(), c.Param("tenant"))
+	if err != nil {
+		return tenantError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, login)
+}
+
+// GetOwnTenant godoc
+// @Summary Get my organization
+// @Description Get the organization the user belongs to
+// @Tags tenant
+// @Produce json
+// @Success 200 {object} domain.Tenant
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /tenant [get]
+func (h *TenantHandler) GetOwnTenant(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	tenant, err := h.tenantService.GetOwnTenant(c.Request().Context(), userID)
+	if err != nil {
+		return tenantError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, tenant)
+}
+
+// ListMembers godoc
+// @Summary List my organization's members
+// @Description List the members of the organization the user administers
+// @Tags tenant
+// @Produce json
+// @Success 200 {array} domain.User
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /tenant/members [get]
+func (h *TenantHandler) ListMembers(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	members, err := h.tenantService.ListMembers(c.Request().Context(), userID)
+	if err != nil {
+		return tenantError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, members)
+}
+
+// SetMemberRole godoc
+// @Summary Change a member's role
+// @Description Make a member of the organization the user administers an admin or a plain member. Admins cannot change their own role.
+// @Tags tenant
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param request body service.SetMemberRequest true "Role"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /tenant/members/{user_id} [put]
+func (h *TenantHandler) SetMemberRole(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	var req service.SetMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	member, err := h.tenantService.SetMemberRole(c.Request().Context(), userID, c.Param("user_id"), req.Role)
+	if err != nil {
+		return tenantError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember godoc
+// @Summary Remove a member
+// @Description Take a member out of the organization the user administers. Admins cannot remove themselves.
+// @Tags tenant
+// @Param user_id path string true "User ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /tenant/members/{user_id} [delete]
+func (h *TenantHandler) RemoveMember(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	if err := h.tenantService.RemoveMember(c.Request().Context(), userID, c.Param("user_id")); err != nil {
+		return tenantError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetLeaderboard godoc
+// @Summary Get my organization's leaderboard
+// @Description Rank the members of the user's organization by games won, then by points. Only members see it.
+// @Tags tenant
+// @Produce json
+// @Param limit query int false "Number of entries (default 50, at most 500)"
+// @Success 200 {array} domain.LeaderboardEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /tenant/leaderboard [get]
+func (h *TenantHandler) GetLeaderboard(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	limit, ok := parseLimit(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_limit"),
+		})
+	}
+
+	entries, err := h.tenantService.Leaderboard(c.Request().Context(), userID, limit)
+	if err != nil {
+		return tenantError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
+
+// tenantError writes the response for a tenant service error
+func tenantError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidTenant), errors.Is(err, service.ErrInvalidTenantRole), errors.Is(err, service.ErrTenantSelf), errors.Is(err, service.ErrInvalidSSOConfig):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, service.ErrUnauthenticated):
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: t(c, "error.authentication_required"),
+		})
+	case errors.Is(err, service.ErrNotTenantAdmin):
+		return c.JSON(http.StatusForbidden, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, service.ErrTenantNotFound), errors.Is(err, service.ErrNotTenantMember), errors.Is(err, service.ErrSSONotConfigured):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, domain.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: t(c, "error.user_not_found"),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.tenant_failed"),
+		})
+	}
+}
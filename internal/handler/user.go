@@ -1,24 +1,51 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
 	"github.com/zizouhuweidi/dahaa/internal/service"
+	"github.com/zizouhuweidi/dahaa/internal/validation"
 )
 
 // UserHandler handles user-related HTTP requests
 type UserHandler struct {
-	userService *service.UserService
+	userService   *service.UserService
+	inviteService *service.InviteService
+	statsService  *service.StatsService
+	abuseService  *service.AbuseService
 }
 
-// NewUserHandler creates a new user handler
-func NewUserHandler(userService *service.UserService) *UserHandler {
+// NewUserHandler creates a new user handler. Registrations and logins are
+// recorded as account signals with the abuse service.
+func NewUserHandler(userService *service.UserService, inviteService *service.InviteService, statsService *service.StatsService, abuseService *service.AbuseService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:   userService,
+		inviteService: inviteService,
+		statsService:  statsService,
+		abuseService:  abuseService,
 	}
 }
 
+// SendGameInviteResponse is a sent invitation with the sender's record
+// against the recipient, for offering a rematch
+type SendGameInviteResponse struct {
+	*domain.GameInvite
+	HeadToHead *domain.HeadToHead `json:"head_to_head,omitempty"`
+}
+
+// LoginResponse is a session token with the user's preferences, for clients
+// to apply on signing in
+type LoginResponse struct {
+	Token       string                 `json:"token"`
+	Preferences domain.UserPreferences `json:"preferences"`
+}
+
 // Register godoc
 // @Summary Register a new user
 // @Description Register a new user with username, email, and password
@@ -27,48 +54,55 @@ func NewUserHandler(userService *service.UserService) *UserHandler {
 // @Produce json
 // @Param user body service.RegisterRequest true "User registration data"
 // @Success 201 {object} domain.User
-// @Failure 400 {object} ErrorResponse
+// @Failure 400 {object} ErrorResponse "Invalid request, or a name breaking the naming policy with a code such as username_reserved"
 // @Failure 409 {object} ErrorResponse
 // @Router /users/register [post]
 func (h *UserHandler) Register(c echo.Context) error {
 	var req service.RegisterRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error: "Invalid request body",
+			Error: t(c, "error.invalid_request_body"),
 		})
 	}
 
 	if err := c.Validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error: err.Error(),
+			Error: localizeError(c, err),
 		})
 	}
 
 	user, err := h.userService.Register(c.Request().Context(), req)
 	if err != nil {
+		if code, ok := nameErrorCode(err); ok {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: localizeError(c, err),
+				Code:  code,
+			})
+		}
 		switch err {
 		case service.ErrUserAlreadyExists:
 			return c.JSON(http.StatusConflict, ErrorResponse{
-				Error: "Username or email already exists",
+				Error: t(c, "error.user_already_exists"),
 			})
 		default:
 			return c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error: "Failed to register user",
+				Error: t(c, "error.register_failed"),
 			})
 		}
 	}
 
+	recordSignal(c, h.abuseService, domain.AccountSignalRegistration, user)
 	return c.JSON(http.StatusCreated, user)
 }
 
 // Login godoc
 // @Summary Login user
-// @Description Authenticate user and return session token
+// @Description Authenticate user and return a session token with the user's preferences. The token is presented as a bearer token to the routes that need a user, and expires after 30 days without use.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param credentials body service.LoginRequest true "Login credentials"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} LoginResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Router /users/login [post]
@@ -76,102 +110,644 @@ func (h *UserHandler) Login(c echo.Context) error {
 	var req service.LoginRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error: "Invalid request body",
+			Error: t(c, "error.invalid_request_body"),
 		})
 	}
 
 	if err := c.Validate(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error: err.Error(),
+			Error: localizeError(c, err),
 		})
 	}
 
-	token, err := h.userService.Login(c.Request().Context(), req)
+	token, user, err := h.userService.Login(c.Request().Context(), req)
 	if err != nil {
+		var banErr *service.BanError
+		if errors.As(err, &banErr) {
+			return respondBanned(c, banErr)
+		}
 		switch err {
 		case service.ErrInvalidCredentials:
 			return c.JSON(http.StatusUnauthorized, ErrorResponse{
-				Error: "Invalid username or password",
+				Error: t(c, "error.invalid_credentials"),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: t(c, "error.login_failed"),
+			})
+		}
+	}
+
+	recordSignal(c, h.abuseService, domain.AccountSignalLogin, user)
+	return c.JSON(http.StatusOK, LoginResponse{
+		Token:       token,
+		Preferences: user.Preferences,
+	})
+}
+
+// OAuthLoginResponse is a session token with the user's preferences, and
+// whether signing in created their account
+type OAuthLoginResponse struct {
+	LoginResponse
+	Created bool `json:"created"`
+}
+
+// LoginWithOAuth godoc
+// @Summary Login with an outside account
+// @Description Sign in with a Google or Apple account by redeeming the authorization code the provider's consent screen gave the client. An account signing in for the first time is linked to the user with the same email if the provider has verified it, or otherwise gets a new account without a password.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider, such as google or apple"
+// @Param request body service.OAuthLoginRequest true "Authorization code"
+// @Success 200 {object} OAuthLoginResponse
+// @Success 201 {object} OAuthLoginResponse "Account created"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "An account has the email, which the provider has not verified"
+// @Router /users/oauth/{provider} [post]
+func (h *UserHandler) LoginWithOAuth(c echo.Context) error {
+	var req service.OAuthLoginRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	token, user, created, err := h.userService.LoginWithOAuth(c.Request().Context(), c.Param("provider"), req)
+	return h.respondOAuthLogin(c, token, user, created, err)
+}
+
+// LoginWithSSO godoc
+// @Summary Login with an organization's identity provider
+// @Description Sign in with an organization's OpenID Connect identity provider by redeeming the authorization code it gave the client. Members signing in for the first time get an account and join the organization, with the role the provider's role claim maps to.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param tenant path string true "Organization ID"
+// @Param request body service.OAuthLoginRequest true "Authorization code"
+// @Success 200 {object} OAuthLoginResponse
+// @Success 201 {object} OAuthLoginResponse "Account created"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse "An account has the email, which the provider has not verified, or belongs to another organization"
+// @Router /users/sso/{tenant} [post]
+func (h *UserHandler) LoginWithSSO(c echo.Context) error {
+	var req service.OAuthLoginRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	token, user, created, err := h.userService.LoginWithSSO(c.Request().Context(), c.Param("tenant"), req)
+	return h.respondOAuthLogin(c, token, user, created, err)
+}
+
+// respondOAuthLogin writes the response for a user signing in with an
+// outside account
+func (h *UserHandler) respondOAuthLogin(c echo.Context, token string, user *domain.User, created bool, err error) error {
+	if err != nil {
+		var banErr *service.BanError
+		if errors.As(err, &banErr) {
+			return respondBanned(c, banErr)
+		}
+		switch {
+		case errors.Is(err, service.ErrUnknownOAuthProvider), errors.Is(err, service.ErrSSONotConfigured):
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: localizeError(c, err),
+			})
+		case errors.Is(err, service.ErrOAuthExchange):
+			return c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error: localizeError(c, err),
+			})
+		case errors.Is(err, domain.ErrUserAlreadyExists):
+			return c.JSON(http.StatusConflict, ErrorResponse{
+				Error: t(c, "error.user_already_exists"),
+			})
+		case errors.Is(err, service.ErrOtherTenant):
+			return c.JSON(http.StatusConflict, ErrorResponse{
+				Error: localizeError(c, err),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: t(c, "error.login_failed"),
+			})
+		}
+	}
+
+	status, signal := http.StatusOK, domain.AccountSignalLogin
+	if created {
+		status, signal = http.StatusCreated, domain.AccountSignalRegistration
+	}
+	recordSignal(c, h.abuseService, signal, user)
+	return c.JSON(status, OAuthLoginResponse{
+		LoginResponse: LoginResponse{
+			Token:       token,
+			Preferences: user.Preferences,
+		},
+		Created: created,
+	})
+}
+
+// Logout godoc
+// @Summary Logout user
+// @Description End the session of the presented session token
+// @Tags users
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Router /users/logout [post]
+func (h *UserHandler) Logout(c echo.Context) error {
+	token, _ := bearerToken(c)
+	if err := h.userService.Logout(c.Request().Context(), token); err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.logout_failed"),
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GuestResponse is a guest identity with the session token it is presented
+// with
+type GuestResponse struct {
+	Token string        `json:"token"`
+	Guest *domain.Guest `json:"guest"`
+}
+
+// ConvertGuestResponse is the account a guest converted to, signed in
+type ConvertGuestResponse struct {
+	Token string       `json:"token"`
+	User  *domain.User `json:"user"`
+}
+
+// CreateGuest godoc
+// @Summary Play as a guest
+// @Description Mint a temporary guest identity to create and join games without an account. The token is presented as a bearer token, which makes the guest play under its ID and name, and expires with the guest after 24 hours.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param guest body service.CreateGuestRequest false "Guest name"
+// @Success 201 {object} GuestResponse
+// @Failure 400 {object} ErrorResponse "Invalid request, or a name breaking the naming policy with a code such as display_name_profane"
+// @Router /users/guest [post]
+func (h *UserHandler) CreateGuest(c echo.Context) error {
+	var req service.CreateGuestRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	token, guest, err := h.userService.CreateGuest(c.Request().Context(), req)
+	if err != nil {
+		if code, ok := nameErrorCode(err); ok {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: localizeError(c, err),
+				Code:  code,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.guest_failed"),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, GuestResponse{
+		Token: token,
+		Guest: guest,
+	})
+}
+
+// ConvertGuest godoc
+// @Summary Convert a guest to an account
+// @Description Register an account for the guest whose token is presented, keeping the guest's ID so the games it played count towards the account's history and stats. The guest token stops working, and a session token for the new user is returned.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user body service.RegisterRequest true "User registration data"
+// @Success 201 {object} ConvertGuestResponse
+// @Failure 400 {object} ErrorResponse "Invalid request, or a name breaking the naming policy with a code such as username_reserved"
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/guest/convert [post]
+func (h *UserHandler) ConvertGuest(c echo.Context) error {
+	if _, ok := callerGuest(c); !ok {
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: t(c, "error.guest_required"),
+		})
+	}
+
+	var req service.RegisterRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	token, _ := bearerToken(c)
+	session, user, err := h.userService.ConvertGuest(c.Request().Context(), token, req)
+	if err != nil {
+		if code, ok := nameErrorCode(err); ok {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: localizeError(c, err),
+				Code:  code,
+			})
+		}
+		switch {
+		case errors.Is(err, service.ErrInvalidSession):
+			return c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error: t(c, "error.invalid_session"),
+			})
+		case errors.Is(err, domain.ErrUserAlreadyExists):
+			return c.JSON(http.StatusConflict, ErrorResponse{
+				Error: t(c, "error.user_already_exists"),
 			})
 		default:
 			return c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error: "Failed to login",
+				Error: t(c, "error.register_failed"),
 			})
 		}
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{
-		"token": token,
+	recordSignal(c, h.abuseService, domain.AccountSignalRegistration, user)
+	return c.JSON(http.StatusCreated, ConvertGuestResponse{
+		Token: session,
+		User:  user,
 	})
 }
 
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Verify the email address of the user a verification link was sent to, with the token from the link. Users must verify their address before they can send game invitations. Tokens work once and expire after 24 hours.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body service.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} ErrorResponse
+// @Router /users/verify [post]
+func (h *UserHandler) VerifyEmail(c echo.Context) error {
+	var req service.VerifyEmailRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	user, err := h.userService.VerifyEmail(c.Request().Context(), req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidVerificationToken):
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: t(c, "error.invalid_verification_token"),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: t(c, "error.verification_failed"),
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description Email the current user a new link to verify their email address with
+// @Tags users
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/verify/resend [post]
+func (h *UserHandler) ResendVerification(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	if err := h.userService.ResendVerification(c.Request().Context(), userID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnauthenticated):
+			return c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error: t(c, "error.authentication_required"),
+			})
+		case errors.Is(err, service.ErrAlreadyVerified):
+			return c.JSON(http.StatusConflict, ErrorResponse{
+				Error: t(c, "error.already_verified"),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: t(c, "error.send_verification_failed"),
+			})
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// UpdateProfile godoc
+// @Summary Update profile
+// @Description Change the current user's display name
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param profile body service.UpdateProfileRequest true "Profile changes"
+// @Success 200 {object} domain.User
+// @Failure 400 {object} ErrorResponse "Invalid request, or a name breaking the naming policy with a code such as display_name_profane"
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/me/profile [patch]
+func (h *UserHandler) UpdateProfile(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	var req service.UpdateProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	user, err := h.userService.UpdateProfile(c.Request().Context(), userID, req)
+	if err != nil {
+		if code, ok := nameErrorCode(err); ok {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: localizeError(c, err),
+				Code:  code,
+			})
+		}
+		switch {
+		case errors.Is(err, service.ErrUnauthenticated):
+			return c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error: t(c, "error.authentication_required"),
+			})
+		case errors.Is(err, domain.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: t(c, "error.user_not_found"),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: t(c, "error.update_profile_failed"),
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// nameReasons name the naming policy errors in error codes
+var nameReasons = []struct {
+	err    error
+	reason string
+}{
+	{validation.ErrNameTooShort, "too_short"},
+	{validation.ErrNameTooLong, "too_long"},
+	{validation.ErrNameInvalidCharacters, "invalid_characters"},
+	{validation.ErrNameReserved, "reserved"},
+	{validation.ErrNameProfane, "profane"},
+	{validation.ErrNameConfusable, "confusable"},
+}
+
+// nameErrorCode returns the code of a name that breaks the naming policy,
+// such as "username_reserved", naming the field and the reason
+func nameErrorCode(err error) (string, bool) {
+	var field string
+	switch {
+	case errors.Is(err, service.ErrInvalidUsername):
+		field = "username"
+	case errors.Is(err, service.ErrInvalidDisplayName):
+		field = "display_name"
+	default:
+		return "", false
+	}
+
+	for _, r := range nameReasons {
+		if errors.Is(err, r.err) {
+			return field + "_" + r.reason, true
+		}
+	}
+	return field + "_invalid", true
+}
+
+// GetPreferences godoc
+// @Summary Get preferences
+// @Description Get the current user's preferences
+// @Tags users
+// @Produce json
+// @Success 200 {object} domain.UserPreferences
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/me/preferences [get]
+func (h *UserHandler) GetPreferences(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	preferences, err := h.userService.GetPreferences(c.Request().Context(), userID)
+	if err != nil {
+		return preferencesError(c, err, "error.get_preferences_failed")
+	}
+
+	return c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences godoc
+// @Summary Update preferences
+// @Description Change the given preferences of the current user, leaving the others as they are. A null game_settings clears the default game settings.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param preferences body domain.UserPreferences true "Preferences to change"
+// @Success 200 {object} domain.UserPreferences
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/me/preferences [patch]
+func (h *UserHandler) UpdatePreferences(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	var patch json.RawMessage
+	if err := json.NewDecoder(c.Request().Body).Decode(&patch); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: t(c, "error.invalid_request_body"),
+		})
+	}
+
+	preferences, err := h.userService.UpdatePreferences(c.Request().Context(), userID, patch)
+	if err != nil {
+		return preferencesError(c, err, "error.update_preferences_failed")
+	}
+
+	return c.JSON(http.StatusOK, preferences)
+}
+
+// preferencesError writes the response for a preferences service error,
+// falling back to the failedID message for unexpected errors
+func preferencesError(c echo.Context, err error, failedID string) error {
+	switch {
+	case errors.Is(err, service.ErrUnauthenticated):
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: t(c, "error.authentication_required"),
+		})
+	case errors.Is(err, service.ErrInvalidPreferences), errors.Is(err, service.ErrInvalidSettings):
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	case errors.Is(err, domain.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: t(c, "error.user_not_found"),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, failedID),
+		})
+	}
+}
+
 // SendGameInvite godoc
 // @Summary Send game invitation
-// @Description Send a game invitation to another user
+// @Description Send a game invitation to another user, returning the sender's head-to-head record against them
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param game_id path string true "Game ID"
 // @Param to_user_id path string true "Recipient User ID"
-// @Success 200 {object} domain.GameInvite
+// @Success 200 {object} SendGameInviteResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse "The sender has not verified their email address"
 // @Failure 404 {object} ErrorResponse
 // @Router /users/invites/{game_id}/{to_user_id} [post]
 func (h *UserHandler) SendGameInvite(c echo.Context) error {
 	gameID := c.Param("game_id")
 	toUserID := c.Param("to_user_id")
-	fromUserID := c.Get("user_id").(string)
+	fromUserID, _ := c.Get("user_id").(string)
 
-	err := h.userService.SendGameInvite(c.Request().Context(), gameID, fromUserID, toUserID)
+	invite, err := h.userService.SendGameInvite(c.Request().Context(), gameID, fromUserID, toUserID)
 	if err != nil {
 		switch err {
 		case service.ErrUserNotFound:
 			return c.JSON(http.StatusNotFound, ErrorResponse{
-				Error: "User not found",
+				Error: t(c, "error.user_not_found"),
+			})
+		case service.ErrEmailNotVerified:
+			return c.JSON(http.StatusForbidden, ErrorResponse{
+				Error: t(c, "error.email_not_verified"),
 			})
 		default:
 			return c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error: "Failed to send invitation",
+				Error: t(c, "error.send_invite_failed"),
 			})
 		}
 	}
 
-	return c.NoContent(http.StatusOK)
+	// The invitation is sent either way, so a missing record is not an error
+	record, err := h.statsService.GetHeadToHead(c.Request().Context(), fromUserID, toUserID)
+	if err != nil {
+		fmt.Printf("Failed to get head-to-head record: %v\n", err)
+	}
+
+	return c.JSON(http.StatusOK, SendGameInviteResponse{
+		GameInvite: invite,
+		HeadToHead: record,
+	})
 }
 
 // AcceptGameInvite godoc
 // @Summary Accept game invitation
-// @Description Accept a pending game invitation
+// @Description Accept a pending game invitation, joining the game it is for
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param invite_id path string true "Invitation ID"
-// @Success 200 {object} domain.GameInvite
+// @Success 200 {object} service.AcceptedInvite
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Router /users/invites/{invite_id}/accept [post]
 func (h *UserHandler) AcceptGameInvite(c echo.Context) error {
 	inviteID := c.Param("invite_id")
+	userID, _ := c.Get("user_id").(string)
 
-	err := h.userService.AcceptGameInvite(c.Request().Context(), inviteID)
+	accepted, err := h.inviteService.AcceptInvite(c.Request().Context(), inviteID, userID)
 	if err != nil {
-		switch err {
-		case service.ErrInviteNotFound:
+		var banErr *service.BanError
+		if errors.As(err, &banErr) {
+			return respondBanned(c, banErr)
+		}
+		switch {
+		case errors.Is(err, service.ErrInviteNotFound):
 			return c.JSON(http.StatusNotFound, ErrorResponse{
-				Error: "Invitation not found",
+				Error: t(c, "error.invite_not_found"),
+			})
+		case errors.Is(err, service.ErrNotInviteRecipient):
+			return c.JSON(http.StatusForbidden, ErrorResponse{
+				Error: t(c, "error.not_invite_recipient"),
 			})
-		case service.ErrInviteExpired:
+		case errors.Is(err, service.ErrInviteExpired):
 			return c.JSON(http.StatusBadRequest, ErrorResponse{
-				Error: "Invitation has expired",
+				Error: t(c, "error.invite_expired"),
+			})
+		case errors.Is(err, service.ErrInviteNotPending):
+			return c.JSON(http.StatusConflict, ErrorResponse{
+				Error: t(c, "error.invite_not_pending"),
+			})
+		case errors.Is(err, service.ErrGameFull):
+			return c.JSON(http.StatusConflict, ErrorResponse{
+				Error: t(c, "error.invite_game_full"),
+			})
+		case errors.Is(err, service.ErrGameInProgress):
+			return c.JSON(http.StatusConflict, ErrorResponse{
+				Error: t(c, "error.invite_game_started"),
+			})
+		case errors.Is(err, domain.ErrGameNotFound):
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: t(c, "error.invite_game_gone"),
+			})
+		case errors.Is(err, service.ErrPlayerInGame):
+			return c.JSON(http.StatusConflict, ErrorResponse{
+				Error: t(c, "error.invite_already_in_game"),
 			})
 		default:
 			return c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error: "Failed to accept invitation",
+				Error: t(c, "error.accept_invite_failed"),
 			})
 		}
 	}
 
-	return c.NoContent(http.StatusOK)
+	return c.JSON(http.StatusOK, accepted)
 }
 
 // DeclineGameInvite godoc
@@ -183,21 +759,36 @@ func (h *UserHandler) AcceptGameInvite(c echo.Context) error {
 // @Param invite_id path string true "Invitation ID"
 // @Success 200 {object} domain.GameInvite
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Router /users/invites/{invite_id}/decline [post]
 func (h *UserHandler) DeclineGameInvite(c echo.Context) error {
 	inviteID := c.Param("invite_id")
+	userID, _ := c.Get("user_id").(string)
 
-	err := h.userService.DeclineGameInvite(c.Request().Context(), inviteID)
+	err := h.userService.DeclineGameInvite(c.Request().Context(), inviteID, userID)
 	if err != nil {
-		switch err {
-		case service.ErrInviteNotFound:
+		switch {
+		case errors.Is(err, service.ErrInviteNotFound):
 			return c.JSON(http.StatusNotFound, ErrorResponse{
-				Error: "Invitation not found",
+				Error: t(c, "error.invite_not_found"),
+			})
+		case errors.Is(err, service.ErrNotInviteRecipient):
+			return c.JSON(http.StatusForbidden, ErrorResponse{
+				Error: t(c, "error.not_invite_recipient"),
+			})
+		case errors.Is(err, service.ErrInviteExpired):
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: t(c, "error.invite_expired"),
+			})
+		case errors.Is(err, service.ErrInviteNotPending):
+			return c.JSON(http.StatusConflict, ErrorResponse{
+				Error: t(c, "error.invite_not_pending"),
 			})
 		default:
 			return c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Error: "Failed to decline invitation",
+				Error: t(c, "error.decline_invite_failed"),
 			})
 		}
 	}
@@ -205,24 +796,179 @@ func (h *UserHandler) DeclineGameInvite(c echo.Context) error {
 	return c.NoContent(http.StatusOK)
 }
 
+// CancelGameInvite godoc
+// @Summary Cancel game invitation
+// @Description Withdraw a pending game invitation. Only the sender may cancel it.
+// @Tags users
+// @Produce json
+// @Param invite_id path string true "Invitation ID"
+// @Success 204
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/invites/{invite_id} [delete]
+func (h *UserHandler) CancelGameInvite(c echo.Context) error {
+	inviteID := c.Param("invite_id")
+	userID, _ := c.Get("user_id").(string)
+
+	err := h.userService.CancelGameInvite(c.Request().Context(), inviteID, userID)
+	if err != nil {
+		return inviteError(c, err, "error.cancel_invite_failed")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ResendGameInvite godoc
+// @Summary Resend game invitation
+// @Description Renew an unanswered invitation's expiry and notify the recipient again. Only the sender may resend it, once they have verified their email address.
+// @Tags users
+// @Produce json
+// @Param invite_id path string true "Invitation ID"
+// @Success 200 {object} domain.GameInvite
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /users/invites/{invite_id}/resend [post]
+func (h *UserHandler) ResendGameInvite(c echo.Context) error {
+	inviteID := c.Param("invite_id")
+	userID, _ := c.Get("user_id").(string)
+
+	invite, err := h.userService.ResendGameInvite(c.Request().Context(), inviteID, userID)
+	if err != nil {
+		return inviteError(c, err, "error.resend_invite_failed")
+	}
+
+	return c.JSON(http.StatusOK, invite)
+}
+
+// inviteError maps errors from managing an invitation to responses
+func inviteError(c echo.Context, err error, fallbackID string) error {
+	switch err {
+	case service.ErrInviteNotFound:
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: t(c, "error.invite_not_found"),
+		})
+	case service.ErrNotInviteSender:
+		return c.JSON(http.StatusForbidden, ErrorResponse{
+			Error: t(c, "error.not_invite_sender"),
+		})
+	case service.ErrEmailNotVerified:
+		return c.JSON(http.StatusForbidden, ErrorResponse{
+			Error: t(c, "error.email_not_verified"),
+		})
+	case service.ErrInviteNotPending:
+		return c.JSON(http.StatusConflict, ErrorResponse{
+			Error: t(c, "error.invite_not_pending"),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, fallbackID),
+		})
+	}
+}
+
+// DeleteAccount godoc
+// @Summary Delete account
+// @Description Delete the current user's account. It is anonymized at once, leaving past games under "Deleted player", and purged after a retention window.
+// @Tags users
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/me [delete]
+func (h *UserHandler) DeleteAccount(c echo.Context) error {
+	userID, _ := c.Get("user_id").(string)
+
+	if err := h.userService.DeleteAccount(c.Request().Context(), userID); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Error: t(c, "error.user_not_found"),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error: t(c, "error.delete_account_failed"),
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
 // GetPendingInvites godoc
 // @Summary Get pending invitations
-// @Description Get all pending game invitations for the current user
+// @Description Get a page of pending game invitations for the current user
 // @Tags users
 // @Accept json
 // @Produce json
-// @Success 200 {array} domain.GameInvite
+// @Param limit query int false "Page size"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param sort query string false "created_at (default) or expires_at, prefixed with - for descending"
+// @Param order query string false "asc or desc (default)"
+// @Success 200 {object} pagination.Page[domain.GameInvite]
+// @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /users/invites [get]
 func (h *UserHandler) GetPendingInvites(c echo.Context) error {
-	userID := c.Get("user_id").(string)
+	userID, _ := c.Get("user_id").(string)
 
-	invites, err := h.userService.GetPendingInvites(c.Request().Context(), userID)
+	params, err := pagination.Parse(c.QueryParams(), domain.InviteListOptions)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error: "Failed to get pending invitations",
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
 		})
 	}
 
-	return c.JSON(http.StatusOK, invites)
+	page, err := h.userService.GetPendingInvites(c.Request().Context(), userID, params)
+	if err != nil {
+		switch {
+		case errors.Is(err, pagination.ErrInvalidCursor), errors.Is(err, pagination.ErrInvalidSort):
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: localizeError(c, err),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: t(c, "error.get_invites_failed"),
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// ListUsers godoc
+// @Summary List users
+// @Description List registered users for administrators, optionally filtered by role or organization
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Page size"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param sort query string false "created_at (default) or updated_at, prefixed with - for descending"
+// @Param order query string false "asc or desc (default)"
+// @Param role query string false "Only users with this role"
+// @Param tenant_id query string false "Only members of this organization"
+// @Success 200 {object} pagination.Page[domain.User]
+// @Failure 400 {object} ErrorResponse
+// @Router /admin/users [get]
+func (h *UserHandler) ListUsers(c echo.Context) error {
+	params, err := pagination.Parse(c.QueryParams(), domain.UserListOptions)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: localizeError(c, err),
+		})
+	}
+
+	page, err := h.userService.ListUsers(c.Request().Context(), params)
+	if err != nil {
+		switch {
+		case errors.Is(err, pagination.ErrInvalidCursor), errors.Is(err, pagination.ErrInvalidSort):
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: localizeError(c, err),
+			})
+		default:
+			return c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error: t(c, "error.list_users_failed"),
+			})
+		}
+	}
+
+	return c.JSON(http.StatusOK, page)
 }
@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"reflect"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
 )
 
@@ -11,3 +14,20 @@ type CustomValidator struct {
 func (cv *CustomValidator) Validate(i any) error {
 	return cv.Validator.Struct(i)
 }
+
+// NewValidator creates a validator that reports fields by their JSON names,
+// as clients know them
+func NewValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
@@ -1,10 +1,19 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/i18n"
+	"github.com/zizouhuweidi/dahaa/internal/service"
 	ws "github.com/zizouhuweidi/dahaa/internal/websocket"
 )
 
@@ -16,28 +25,206 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// resumeSaveTimeout bounds saving a session for resumption once its socket drops
+const resumeSaveTimeout = 5 * time.Second
+
 // WebSocketHandler handles WebSocket connections
 type WebSocketHandler struct {
-	hub *ws.Hub
+	hub         *ws.Hub
+	gameService domain.GameService
+	userService *service.UserService
+	banService  *service.BanService
 }
 
-// NewWebSocketHandler creates a new WebSocket handler
-func NewWebSocketHandler(hub *ws.Hub) *WebSocketHandler {
+// NewWebSocketHandler creates a new WebSocket handler. Game clients that
+// present a player token are identified with their player, so they also
+// receive the player's private messages. Clients that join with display=true
+// are shared screens, sent the game's sanitized view instead of its state.
+// Stream overlays join as displays with the game's overlay token alone.
+// Players and displays are sent a resume token when they connect; a client
+// whose socket drops reconnects with it alone, and is resent the game's state
+// if it changed since the last version the client acknowledged. Players
+// like answers during a round's reveal with like_answer commands, and hosts
+// skip_phase or extend_timer the phase the game is in. Users
+// follow their notifications with their session token alone. Suspended and
+// banned users cannot connect as players or to their notifications.
+func NewWebSocketHandler(hub *ws.Hub, gameService domain.GameService, userService *service.UserService, banService *service.BanService) *WebSocketHandler {
 	return &WebSocketHandler{
-		hub: hub,
+		hub:         hub,
+		gameService: gameService,
+		userService: userService,
+		banService:  banService,
+	}
+}
+
+// sessionUser returns the user whose session token a client connected with,
+// if any. Browsers cannot set headers on sockets, so the token can also be
+// sent as the token query parameter.
+func (h *WebSocketHandler) sessionUser(c echo.Context) (string, error) {
+	token, ok := bearerToken(c)
+	if !ok {
+		token = c.QueryParam("token")
+	}
+	if token == "" {
+		return "", nil
 	}
+	return h.userService.Authenticate(c.Request().Context(), token)
 }
 
 // HandleWebSocket handles incoming WebSocket connections
 func (h *WebSocketHandler) HandleWebSocket(c echo.Context) error {
-	// Get game ID from query parameter
+	// Get game ID from query parameter, or subscribe to an import job's
+	// progress or, for clients with a session, their user's notifications
 	gameID := c.QueryParam("game_id")
+	display := c.QueryParam("display") == "true"
+
+	// Clients whose socket dropped resume their session with its token,
+	// rejoining as who they were without authenticating again
+	resumeToken := c.QueryParam("resume_token")
+	var resumed *domain.ResumeSession
+	if resumeToken != "" {
+		var err error
+		if resumed, err = h.gameService.ResumeSession(c.Request().Context(), resumeToken); err != nil {
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
+		gameID, display = resumed.GameID, resumed.Display
+	} else if token := c.QueryParam("overlay_token"); token != "" {
+		var err error
+		if gameID, err = h.gameService.AuthenticateOverlay(c.Request().Context(), token); err != nil {
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
+		display = true
+	}
+	if jobID := c.QueryParam("job_id"); gameID == "" && jobID != "" {
+		gameID = service.ImportChannel(jobID)
+	}
+	var channelUserID string
+	if gameID == "" {
+		userID, err := h.sessionUser(c)
+		if errors.Is(err, service.ErrInvalidSession) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error": t(c, "error.invalid_session"),
+			})
+		}
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": t(c, "error.authenticate_failed"),
+			})
+		}
+		if userID != "" {
+			gameID, channelUserID = service.UserChannel(userID), userID
+		}
+	}
 	if gameID == "" {
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "game_id is required",
+			"error": t(c, "error.channel_required"),
 		})
 	}
 
+	// Displays are shared screens that show a game to the room. They join
+	// without a player and start from the game's current view.
+	var initial []byte
+	if display {
+		view, err := h.gameService.GetDisplayView(c.Request().Context(), gameID)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": t(c, "error.game_not_found"),
+			})
+		}
+		payload, err := json.Marshal(view)
+		if err != nil {
+			return err
+		}
+		if initial, err = ws.EncodeMessage(ws.Message{Type: "display_updated", Payload: payload}); err != nil {
+			return err
+		}
+	}
+
+	var playerID string
+	if resumed != nil {
+		playerID = resumed.PlayerID
+	} else if token := c.QueryParam("player_token"); token != "" && !display {
+		var err error
+		playerID, err = h.gameService.AuthenticatePlayer(c.Request().Context(), gameID, token)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
+	}
+
+	// Keep out suspended and banned users, including players resuming a
+	// session from before their ban. Only identities the client proved are
+	// checked: the player its token resolved to, whose ID is bound to the
+	// account that joined, and the user whose session it presented. Players may join without a session, so one that is no
+	// longer valid is no reason to refuse them.
+	accounts := []string{playerID}
+	if channelUserID != "" {
+		accounts = append(accounts, channelUserID)
+	} else if userID, err := h.sessionUser(c); err == nil && userID != playerID {
+		accounts = append(accounts, userID)
+	}
+	for _, account := range accounts {
+		if account == "" || h.banService == nil {
+			continue
+		}
+		if err := h.banService.Check(c.Request().Context(), account); err != nil {
+			var banErr *service.BanError
+			if errors.As(err, &banErr) {
+				return respondBanned(c, banErr)
+			}
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": localizeError(c, err),
+			})
+		}
+	}
+
+	var missed []byte
+	if playerID != "" {
+		if game, err := h.gameService.GetGame(c.Request().Context(), gameID); err == nil {
+			// Restore the player's mute, which the hub forgets when it restarts
+			if i := slices.IndexFunc(game.Players, func(p domain.Player) bool { return p.ID == playerID }); i >= 0 && game.Players[i].Muted {
+				h.hub.MutePlayer(gameID, playerID, true)
+			}
+
+			// Resumed players that missed changes start from the game's state
+			if resumed != nil && game.Version > resumed.LastAck {
+				payload, err := json.Marshal(game.PlayerView(playerID))
+				if err != nil {
+					return err
+				}
+				if missed, err = ws.EncodeMessage(ws.Message{Type: "game_updated", Payload: payload}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Players and displays are issued a token to resume their session with.
+	// Everyone else can simply connect again, as can clients whose token
+	// could not be issued.
+	var session []byte
+	if resumed == nil && (playerID != "" || display) {
+		issued := &domain.ResumeSession{GameID: gameID, PlayerID: playerID, Display: display}
+		token, err := h.gameService.IssueResumeToken(c.Request().Context(), issued)
+		if err != nil {
+			log.Printf("Failed to issue resume token for game %s: %v", gameID, err)
+		} else {
+			payload, err := json.Marshal(map[string]string{"token": token})
+			if err != nil {
+				return err
+			}
+			if session, err = ws.EncodeMessage(ws.Message{Type: "session_resumable", Payload: payload}); err != nil {
+				return err
+			}
+			resumed, resumeToken = issued, token
+		}
+	}
+
 	// Upgrade HTTP connection to WebSocket
 	conn, err := upgrader.Upgrade(c.Response().Writer, c.Request(), nil)
 	if err != nil {
@@ -46,14 +233,30 @@ func (h *WebSocketHandler) HandleWebSocket(c echo.Context) error {
 
 	// Create new client
 	client := &ws.Client{
-		Hub:    h.hub,
-		Conn:   conn,
-		GameID: gameID,
-		Send:   make(chan []byte, 256),
+		Hub:       h.hub,
+		Conn:      conn,
+		GameID:    gameID,
+		PlayerID:  playerID,
+		Display:   display,
+		Localizer: i18n.FromContext(c.Request().Context()),
+	}
+	if playerID != "" {
+		client.Commands = h.playerCommands()
+	}
+	for _, message := range [][]byte{session, initial, missed} {
+		if message != nil {
+			client.Queue(ws.PriorityPhase, message)
+		}
+	}
+	if resumed != nil {
+		client.Ack(resumed.LastAck)
 	}
 
 	// Register client
 	h.hub.Register(client)
+	if resumed != nil {
+		go h.saveResumeSession(client, resumeToken, resumed)
+	}
 
 	// Start goroutines for reading and writing
 	go client.ReadPump()
@@ -61,3 +264,52 @@ func (h *WebSocketHandler) HandleWebSocket(c echo.Context) error {
 
 	return nil
 }
+
+// saveResumeSession waits for a client to be let go, then records the last
+// game state it acknowledged so that resuming its session only resends what
+// it missed. Saving also gives the client a fresh window to resume in.
+func (h *WebSocketHandler) saveResumeSession(client *ws.Client, token string, session *domain.ResumeSession) {
+	<-client.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), resumeSaveTimeout)
+	defer cancel()
+	saved := *session
+	saved.LastAck = client.LastAck()
+	if err := h.gameService.UpdateResumeSession(ctx, token, &saved); err != nil {
+		log.Printf("Failed to save resume session for game %s: %v", session.GameID, err)
+	}
+}
+
+// likeAnswerCommand is the payload of a player's like_answer command
+type likeAnswerCommand struct {
+	AnswerID string `json:"answer_id"`
+}
+
+// playerCommands returns the commands players send over their socket,
+// whose errors are described in the client's language
+func (h *WebSocketHandler) playerCommands() map[string]ws.CommandFunc {
+	return map[string]ws.CommandFunc{
+		"like_answer": func(ctx context.Context, client *ws.Client, payload json.RawMessage) error {
+			var command likeAnswerCommand
+			if err := json.Unmarshal(payload, &command); err != nil {
+				return errors.New(client.Localizer.T("error.invalid_request_body"))
+			}
+			if err := h.gameService.LikeAnswer(ctx, client.GameID, client.PlayerID, command.AnswerID); err != nil {
+				return errors.New(localizeErrorIn(client.Localizer, err))
+			}
+			return nil
+		},
+		"skip_phase": func(ctx context.Context, client *ws.Client, _ json.RawMessage) error {
+			if err := h.gameService.SkipPhase(ctx, client.GameID, client.PlayerID); err != nil {
+				return errors.New(localizeErrorIn(client.Localizer, err))
+			}
+			return nil
+		},
+		"extend_timer": func(ctx context.Context, client *ws.Client, _ json.RawMessage) error {
+			if _, err := h.gameService.ExtendTimer(ctx, client.GameID, client.PlayerID); err != nil {
+				return errors.New(localizeErrorIn(client.Localizer, err))
+			}
+			return nil
+		},
+	}
+}
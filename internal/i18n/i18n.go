@@ -0,0 +1,213 @@
+// Package i18n translates API responses and WebSocket messages. Messages are
+// looked up by ID in per-language bundles embedded from locales/, using the
+// flat JSON format of go-i18n, and fall back to English when a translation is
+// missing. Messages are Go templates, so they may refer to fields of the data
+// they are rendered with, such as {{.Field}}.
+package i18n
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLanguage is the language used when none of the requested ones are supported
+const DefaultLanguage = "en"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Bundle holds the messages of every supported language
+type Bundle struct {
+	tags     []language.Tag
+	matcher  language.Matcher
+	messages map[string]map[string]*template.Template // By language, then message ID
+}
+
+// defaultBundle is loaded from the embedded locales
+var defaultBundle = mustLoadBundle()
+
+// Default returns the bundle of the embedded locales
+func Default() *Bundle {
+	return defaultBundle
+}
+
+// mustLoadBundle loads the embedded locales, which are known to be valid
+func mustLoadBundle() *Bundle {
+	bundle, err := LoadBundle()
+	if err != nil {
+		panic(err)
+	}
+	return bundle
+}
+
+// LoadBundle parses the embedded locales. The default language is always
+// listed first so that it wins when nothing matches.
+func LoadBundle() (*Bundle, error) {
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read locales: %w", err)
+	}
+
+	bundle := &Bundle{
+		tags:     []language.Tag{language.Make(DefaultLanguage)},
+		messages: make(map[string]map[string]*template.Template),
+	}
+	for _, file := range files {
+		lang := strings.TrimSuffix(file.Name(), path.Ext(file.Name()))
+		data, err := locales.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s locale: %w", lang, err)
+		}
+
+		var raw map[string]string
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse %s locale: %w", lang, err)
+		}
+
+		messages := make(map[string]*template.Template, len(raw))
+		for id, text := range raw {
+			tmpl, err := template.New(id).Option("missingkey=error").Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s message %s: %w", lang, id, err)
+			}
+			messages[id] = tmpl
+		}
+		bundle.messages[lang] = messages
+
+		if lang != DefaultLanguage {
+			bundle.tags = append(bundle.tags, language.Make(lang))
+		}
+	}
+
+	if _, ok := bundle.messages[DefaultLanguage]; !ok {
+		return nil, fmt.Errorf("missing %s locale", DefaultLanguage)
+	}
+	bundle.matcher = language.NewMatcher(bundle.tags)
+	return bundle, nil
+}
+
+// Languages returns the supported languages, the default first
+func (b *Bundle) Languages() []string {
+	langs := make([]string, len(b.tags))
+	for i, tag := range b.tags {
+		langs[i] = tag.String()
+	}
+	return langs
+}
+
+// Localizer picks the best supported language for the given preferences, in
+// order of priority. Each preference may be a language tag or an
+// Accept-Language header value; empty and malformed ones are ignored.
+func (b *Bundle) Localizer(preferences ...string) *Localizer {
+	var tags []language.Tag
+	for _, preference := range preferences {
+		parsed, _, err := language.ParseAcceptLanguage(preference)
+		if err != nil {
+			continue
+		}
+		tags = append(tags, parsed...)
+	}
+
+	_, index, _ := b.matcher.Match(tags...)
+	lang := b.tags[index].String()
+	return &Localizer{
+		lang:     lang,
+		messages: b.messages[lang],
+		fallback: b.messages[DefaultLanguage],
+	}
+}
+
+// Localizer renders messages in a single language
+type Localizer struct {
+	lang     string
+	messages map[string]*template.Template
+	fallback map[string]*template.Template
+}
+
+// Language returns the localizer's language tag
+func (l *Localizer) Language() string {
+	return l.lang
+}
+
+// Has reports whether a message exists
+func (l *Localizer) Has(id string) bool {
+	_, ok := l.lookup(id)
+	return ok
+}
+
+// T renders a message with optional template data. Unknown messages render
+// as their ID, so a missing translation never produces an empty response.
+func (l *Localizer) T(id string, data ...any) string {
+	tmpl, ok := l.lookup(id)
+	if !ok {
+		return id
+	}
+
+	var value any
+	if len(data) > 0 {
+		value = data[0]
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, value); err != nil {
+		return id
+	}
+	return buf.String()
+}
+
+// lookup finds a message in the localizer's language or the default one
+func (l *Localizer) lookup(id string) (*template.Template, bool) {
+	if tmpl, ok := l.messages[id]; ok {
+		return tmpl, true
+	}
+	tmpl, ok := l.fallback[id]
+	return tmpl, ok
+}
+
+// localizerKey is the context key of the request's localizer
+type localizerKey struct{}
+
+// WithLocalizer returns a context carrying a localizer
+func WithLocalizer(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, localizerKey{}, l)
+}
+
+// FromContext returns the context's localizer, or a default language one
+func FromContext(ctx context.Context) *Localizer {
+	if l, ok := ctx.Value(localizerKey{}).(*Localizer); ok {
+		return l
+	}
+	return defaultBundle.Localizer()
+}
+
+// rtlScripts are the scripts written right to left
+var rtlScripts = map[string]bool{
+	"Adlm": true,
+	"Arab": true,
+	"Hebr": true,
+	"Nkoo": true,
+	"Rohg": true,
+	"Syrc": true,
+	"Thaa": true,
+}
+
+// Direction returns the text direction of a language, rtl or ltr. Malformed
+// tags are treated as left to right.
+func Direction(lang string) string {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return "ltr"
+	}
+	if script, _ := tag.Script(); rtlScripts[script.String()] {
+		return "rtl"
+	}
+	return "ltr"
+}
@@ -0,0 +1,117 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+	"text/template"
+)
+
+func TestLocalizerLanguage(t *testing.T) {
+	tests := []struct {
+		name        string
+		preferences []string
+		want        string
+	}{
+		{"none", nil, "en"},
+		{"empty", []string{""}, "en"},
+		{"malformed", []string{"not a language!"}, "en"},
+		{"unsupported", []string{"fr"}, "en"},
+		{"arabic", []string{"ar"}, "ar"},
+		{"arabic region", []string{"ar-EG"}, "ar"},
+		{"accept-language", []string{"fr-FR, ar;q=0.8, en;q=0.5"}, "ar"},
+		{"preference order", []string{"en", "ar"}, "en"},
+		{"first preference malformed", []string{"!!", "ar"}, "ar"},
+	}
+	for _, tt := range tests {
+		if got := Default().Localizer(tt.preferences...).Language(); got != tt.want {
+			t.Errorf("%s: language = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLocalizerMessages(t *testing.T) {
+	en, ar := Default().Localizer("en"), Default().Localizer("ar")
+
+	tests := []struct {
+		name      string
+		localizer *Localizer
+		id        string
+		data      any
+		want      string
+	}{
+		{"english", en, "error.game_not_found", nil, "game not found"},
+		{"arabic", ar, "error.game_not_found", nil, "اللعبة غير موجودة"},
+		{"template data", en, "validation.required", map[string]string{"Field": "name"}, "name is required"},
+		{"missing template data", en, "validation.required", nil, "validation.required"},
+		{"unknown message", ar, "error.no_such_message", nil, "error.no_such_message"},
+	}
+	for _, tt := range tests {
+		var got string
+		if tt.data != nil {
+			got = tt.localizer.T(tt.id, tt.data)
+		} else {
+			got = tt.localizer.T(tt.id)
+		}
+		if got != tt.want {
+			t.Errorf("%s: T(%s) = %q, want %q", tt.name, tt.id, got, tt.want)
+		}
+	}
+
+	if !ar.Has("error.game_not_found") || ar.Has("error.no_such_message") {
+		t.Error("Has does not match the messages in the bundle")
+	}
+}
+
+func TestLocalizerFallback(t *testing.T) {
+	// A message missing from a language falls back to the default one
+	partial := Default().Localizer("ar")
+	partial.messages = map[string]*template.Template{}
+	if got := partial.T("error.game_not_found"); got != "game not found" {
+		t.Errorf("untranslated message = %q, want the English one", got)
+	}
+	if !partial.Has("error.game_not_found") {
+		t.Error("untranslated message reported missing")
+	}
+	if partial.Language() != "ar" {
+		t.Errorf("language = %s, want ar", partial.Language())
+	}
+
+	// Requests without a localizer get the default language
+	if got := FromContext(context.Background()).Language(); got != DefaultLanguage {
+		t.Errorf("language without a localizer = %s, want %s", got, DefaultLanguage)
+	}
+	ctx := WithLocalizer(context.Background(), Default().Localizer("ar"))
+	if got := FromContext(ctx).Language(); got != "ar" {
+		t.Errorf("language from context = %s, want ar", got)
+	}
+}
+
+func TestLocalesComplete(t *testing.T) {
+	bundle := Default()
+	for _, lang := range bundle.Languages() {
+		for id := range bundle.messages[DefaultLanguage] {
+			if _, ok := bundle.messages[lang][id]; !ok {
+				t.Errorf("%s locale is missing %s", lang, id)
+			}
+		}
+	}
+}
+
+func TestDirection(t *testing.T) {
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"en", "ltr"},
+		{"ar", "rtl"},
+		{"ar-EG", "rtl"},
+		{"he", "rtl"},
+		{"fr", "ltr"},
+		{"not a language!", "ltr"},
+	}
+	for _, tt := range tests {
+		if got := Direction(tt.lang); got != tt.want {
+			t.Errorf("Direction(%s) = %s, want %s", tt.lang, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Package llm writes filler answers with a language model served over an
+// OpenAI-compatible chat completions API
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// requestTimeout bounds a request to the API. Fillers are generated as
+// voting opens, so the API is given little time.
+const requestTimeout = 3 * time.Second
+
+// FillerGenerator asks a language model for plausible but wrong answers:
+//
+//	POST {url}/chat/completions {"model": "...", "messages": [...]}
+type FillerGenerator struct {
+	url    string
+	token  string
+	model  string
+	client *http.Client
+}
+
+// NewFillerGenerator creates a generator calling the chat completions API
+// under url with a model, authenticating with token as a bearer token unless
+// it is empty
+func NewFillerGenerator(url string, token string, model string) *FillerGenerator {
+	return &FillerGenerator{
+		url:    strings.TrimSuffix(url, "/"),
+		token:  token,
+		model:  model,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// chatMessage is a message of a chat completion request or response
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Generate asks the model for up to n wrong answers to a question, returned
+// as a JSON array of strings
+func (g *FillerGenerator) Generate(ctx context.Context, question string, correctAnswer string, category string, language string, n int) ([]string, error) {
+	prompt := fmt.Sprintf(
+		"Category: %s\nQuestion: %s\nCorrect answer: %s\n\n"+
+			"Write %d short, plausible but wrong answers to this trivia question, in the language with BCP 47 tag %q. "+
+			"Reply with a JSON array of strings and nothing else.",
+		category, question, correctAnswer, n, language,
+	)
+	body, err := json.Marshal(map[string]any{
+		"model": g.model,
+		"messages": []chatMessage{
+			{Role: "system", Content: "You write decoy answers for a trivia game in which players try to fool each other."},
+			{Role: "user", Content: prompt},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode filler request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.url+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create filler request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach language model API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("language model API returned status %d", resp.StatusCode)
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message chatMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return nil, fmt.Errorf("failed to decode completion: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("language model API returned no completion")
+	}
+	return parseAnswers(completion.Choices[0].Message.Content, n)
+}
+
+// parseAnswers reads the JSON array of answers in a completion, ignoring any
+// text the model put around it, and keeps at most n non-empty answers
+func parseAnswers(content string, n int) ([]string, error) {
+	start, end := strings.Index(content, "["), strings.LastIndex(content, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("completion has no answer list")
+	}
+
+	var answers []string
+	if err := json.Unmarshal([]byte(content[start:end+1]), &answers); err != nil {
+		return nil, fmt.Errorf("failed to decode answers: %w", err)
+	}
+
+	kept := make([]string, 0, min(len(answers), n))
+	for _, answer := range answers {
+		if answer = strings.TrimSpace(answer); answer != "" && len(kept) < n {
+			kept = append(kept, answer)
+		}
+	}
+	return kept, nil
+}
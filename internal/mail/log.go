@@ -0,0 +1,21 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+)
+
+// Log writes emails to standard output instead of sending them, for
+// development without an SMTP server
+type Log struct{}
+
+// NewLog creates a sender logging emails
+func NewLog() *Log {
+	return &Log{}
+}
+
+// Send logs a message
+func (l *Log) Send(ctx context.Context, msg Message) error {
+	fmt.Printf("Email to %s: %s\n%s\n", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,19 @@
+// Package mail sends the emails the service writes to its users, such as
+// the links that verify their addresses.
+package mail
+
+import "context"
+
+// Message is a plain-text email to a single recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers emails
+type Sender interface {
+	// Send delivers a message, returning once it has been handed over for
+	// delivery
+	Send(ctx context.Context, msg Message) error
+}
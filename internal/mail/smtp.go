@@ -0,0 +1,73 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTP sends emails through an SMTP server, authenticating with PLAIN auth
+// when it is given a username
+type SMTP struct {
+	addr     string
+	from     string
+	username string
+	password string
+}
+
+// NewSMTP creates a sender relaying emails from the given address through
+// the SMTP server at addr (host:port)
+func NewSMTP(addr string, from string, username string, password string) *SMTP {
+	return &SMTP{
+		addr:     addr,
+		from:     from,
+		username: username,
+		password: password,
+	}
+}
+
+// Send delivers a message through the SMTP server
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if s.username != "" {
+		host, _, err := net.SplitHostPort(s.addr)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP address %q: %w", s.addr, err)
+		}
+		auth = smtp.PlainAuth("", s.username, s.password, host)
+	}
+
+	// smtp.SendMail cannot be cancelled, so the context only keeps it from
+	// starting late
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// The envelope takes the bare address of a sender such as
+	// "Dahaa <no-reply@dahaa.app>"
+	envelope := s.from
+	if from, err := netmail.ParseAddress(s.from); err == nil {
+		envelope = from.Address
+	}
+	if err := smtp.SendMail(s.addr, auth, envelope, []string{msg.To}, s.compose(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// compose writes a message with its headers
+func (s *SMTP) compose(msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
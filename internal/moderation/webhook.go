@@ -0,0 +1,69 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Webhook moderates answers by posting them to a moderation API, which
+// answers whether to flag them:
+//
+//	POST {"text": "...", "language": "en"} -> {"flagged": true}
+type Webhook struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewWebhook creates a moderator posting answers to url, authenticating with
+// token as a bearer token unless it is empty. Answers wait on the API, so it
+// is given little time.
+func NewWebhook(url string, token string) *Webhook {
+	return &Webhook{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: 2 * time.Second},
+	}
+}
+
+// Flag asks the moderation API whether to withhold an answer
+func (w *Webhook) Flag(ctx context.Context, text string, language string) (bool, error) {
+	body, err := json.Marshal(map[string]string{
+		"text":     text,
+		"language": language,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to encode answer: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create moderation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.token)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to reach moderation API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("moderation API returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Flagged bool `json:"flagged"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("failed to decode moderation result: %w", err)
+	}
+	return result.Flagged, nil
+}
@@ -0,0 +1,83 @@
+package oauth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+const appleTokenURL = "https://appleid.apple.com/auth/token"
+
+// Apple signs users in with their Apple ID. Apple's client secret is a JWT
+// signed with the team's key, which is generated outside the API and lasts
+// up to six months.
+type Apple struct {
+	clientID     string
+	clientSecret string
+	client       *http.Client
+}
+
+// NewApple creates an Apple provider for a Services ID registered with Apple
+func NewApple(clientID string, clientSecret string) *Apple {
+	return &Apple{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		client:       &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Name returns "apple"
+func (a *Apple) Name() string {
+	return "apple"
+}
+
+// Exchange redeems an authorization code for the Apple ID that signed in,
+// read from the ID token Apple returns. The token comes straight from
+// Apple's token endpoint over TLS, so its signature is not checked again.
+// Apple only tells the client the user's name, so identities have none.
+func (a *Apple) Exchange(ctx context.Context, code string, redirectURI string) (*domain.OAuthIdentity, error) {
+	token, err := exchangeCode(ctx, a.client, appleTokenURL, url.Values{
+		"code":          {code},
+		"client_id":     {a.clientID},
+		"client_secret": {a.clientSecret},
+		"redirect_uri":  {redirectURI},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(token.IDToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed ID token", domain.ErrOAuthExchange)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ID token: %w", err)
+	}
+
+	var claims struct {
+		Audience      string       `json:"aud"`
+		Subject       string       `json:"sub"`
+		Email         string       `json:"email"`
+		EmailVerified flexibleBool `json:"email_verified"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("failed to decode ID token claims: %w", err)
+	}
+	if claims.Subject == "" || claims.Audience != a.clientID {
+		return nil, fmt.Errorf("%w: ID token is not for this client", domain.ErrOAuthExchange)
+	}
+
+	return &domain.OAuthIdentity{
+		Provider:      a.Name(),
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+	}, nil
+}
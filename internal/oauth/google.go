@@ -0,0 +1,90 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+const (
+	googleTokenURL    = "https://oauth2.googleapis.com/token"
+	googleUserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+)
+
+// Google signs users in with their Google account, reading who signed in
+// from Google's OpenID Connect userinfo endpoint
+type Google struct {
+	clientID     string
+	clientSecret string
+	client       *http.Client
+}
+
+// NewGoogle creates a Google provider for an OAuth client registered with
+// Google
+func NewGoogle(clientID string, clientSecret string) *Google {
+	return &Google{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		client:       &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Name returns "google"
+func (g *Google) Name() string {
+	return "google"
+}
+
+// Exchange redeems an authorization code for the Google account that
+// signed in
+func (g *Google) Exchange(ctx context.Context, code string, redirectURI string) (*domain.OAuthIdentity, error) {
+	token, err := exchangeCode(ctx, g.client, googleTokenURL, url.Values{
+		"code":          {code},
+		"client_id":     {g.clientID},
+		"client_secret": {g.clientSecret},
+		"redirect_uri":  {redirectURI},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, googleUserInfoURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create userinfo request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach userinfo endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("userinfo endpoint returned status %d", resp.StatusCode)
+	}
+
+	var info struct {
+		Subject       string       `json:"sub"`
+		Email         string       `json:"email"`
+		EmailVerified flexibleBool `json:"email_verified"`
+		Name          string       `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode userinfo: %w", err)
+	}
+	if info.Subject == "" {
+		return nil, fmt.Errorf("%w: userinfo has no subject", domain.ErrOAuthExchange)
+	}
+
+	return &domain.OAuthIdentity{
+		Provider:      g.Name(),
+		Subject:       info.Subject,
+		Email:         info.Email,
+		EmailVerified: bool(info.EmailVerified),
+		Name:          info.Name,
+	}, nil
+}
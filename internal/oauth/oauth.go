@@ -0,0 +1,72 @@
+// Package oauth signs users in with outside accounts by the OAuth2
+// authorization code flow. Clients run the provider's consent screen and
+// pass the code they are given to the API, which redeems it here.
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// requestTimeout bounds each request to a provider
+const requestTimeout = 10 * time.Second
+
+// tokenResponse is a provider's answer to redeeming an authorization code
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+}
+
+// exchangeCode redeems an authorization code at a provider's token endpoint.
+// Codes the provider rejects are reported as domain.ErrOAuthExchange.
+func exchangeCode(ctx context.Context, client *http.Client, tokenURL string, form url.Values) (*tokenResponse, error) {
+	form.Set("grant_type", "authorization_code")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return nil, fmt.Errorf("%w: token endpoint returned status %d", domain.ErrOAuthExchange, resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	return &token, nil
+}
+
+// flexibleBool decodes a boolean claim that some providers send as a string
+type flexibleBool bool
+
+// UnmarshalJSON accepts true, false, "true" and "false"
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	case "false", "null", "":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package oauth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// discoveryTTL is how long an identity provider's discovered endpoints are
+// used before they are looked up again
+const discoveryTTL = time.Hour
+
+// OIDC signs organizations' members in with their organization's OpenID
+// Connect identity provider, whose endpoints it discovers from the issuer
+type OIDC struct {
+	client *http.Client
+
+	mu         sync.Mutex
+	discovered map[string]*discovery // By issuer
+}
+
+// discovery is the part of an identity provider's discovery document the
+// sign-in needs
+type discovery struct {
+	Issuer        string `json:"issuer"`
+	TokenEndpoint string `json:"token_endpoint"`
+	fetchedAt     time.Time
+}
+
+// NewOIDC creates a client for organizations' identity providers
+func NewOIDC() *OIDC {
+	return &OIDC{
+		client:     &http.Client{Timeout: requestTimeout},
+		discovered: make(map[string]*discovery),
+	}
+}
+
+// Provider returns the provider an organization's members sign in with
+func (o *OIDC) Provider(config *domain.SSOConfig) domain.OAuthProvider {
+	return &oidcProvider{oidc: o, config: config}
+}
+
+// discover returns an issuer's endpoints, looking them up unless they were
+// looked up recently
+func (o *OIDC) discover(ctx context.Context, issuer string) (*discovery, error) {
+	o.mu.Lock()
+	cached, ok := o.discovered[issuer]
+	o.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < discoveryTTL {
+		return cached, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach discovery endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery endpoint returned status %d", resp.StatusCode)
+	}
+
+	var doc discovery
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode discovery document: %w", err)
+	}
+	if doc.Issuer != issuer || doc.TokenEndpoint == "" {
+		return nil, fmt.Errorf("discovery document of %s is for issuer %s", issuer, doc.Issuer)
+	}
+	doc.fetchedAt = time.Now()
+
+	o.mu.Lock()
+	o.discovered[issuer] = &doc
+	o.mu.Unlock()
+	return &doc, nil
+}
+
+// oidcProvider signs in the members of one organization
+type oidcProvider struct {
+	oidc   *OIDC
+	config *domain.SSOConfig
+}
+
+// Name returns the name the organization's outside accounts are linked under
+func (p *oidcProvider) Name() string {
+	return domain.SSOProvider(p.config.TenantID)
+}
+
+// Exchange redeems an authorization code for the member who signed in, read
+// from the ID token the provider returns. The token comes straight from the
+// provider's token endpoint over TLS, so its signature is not checked again,
+// but its issuer, audience and expiry are.
+func (p *oidcProvider) Exchange(ctx context.Context, code string, redirectURI string) (*domain.OAuthIdentity, error) {
+	endpoints, err := p.oidc.discover(ctx, p.config.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := exchangeCode(ctx, p.oidc.client, endpoints.TokenEndpoint, url.Values{
+		"code":          {code},
+		"client_id":     {p.config.ClientID},
+		"client_secret": {p.config.ClientSecret},
+		"redirect_uri":  {redirectURI},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(token.IDToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed ID token", domain.ErrOAuthExchange)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ID token: %w", err)
+	}
+
+	var claims struct {
+		Issuer        string       `json:"iss"`
+		Audience      stringList   `json:"aud"`
+		Expiry        int64        `json:"exp"`
+		Subject       string       `json:"sub"`
+		Email         string       `json:"email"`
+		EmailVerified flexibleBool `json:"email_verified"`
+		Name          string       `json:"name"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("failed to decode ID token claims: %w", err)
+	}
+	switch {
+	case claims.Subject == "" || claims.Issuer != endpoints.Issuer || !slices.Contains(claims.Audience, p.config.ClientID):
+		return nil, fmt.Errorf("%w: ID token is not for this client", domain.ErrOAuthExchange)
+	case time.Unix(claims.Expiry, 0).Before(time.Now()):
+		return nil, fmt.Errorf("%w: ID token has expired", domain.ErrOAuthExchange)
+	}
+
+	identity := &domain.OAuthIdentity{
+		Provider:      p.Name(),
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+	}
+	if p.config.RoleClaim != "" {
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(payload, &all); err != nil {
+			return nil, fmt.Errorf("failed to decode ID token claims: %w", err)
+		}
+		var groups stringList
+		if raw, ok := all[p.config.RoleClaim]; ok {
+			if err := json.Unmarshal(raw, &groups); err != nil {
+				return nil, fmt.Errorf("failed to decode role claim %s: %w", p.config.RoleClaim, err)
+			}
+		}
+		identity.Groups = groups
+	}
+	return identity, nil
+}
+
+// stringList decodes a claim that is either a string or a list of strings,
+// such as an audience
+type stringList []string
+
+// UnmarshalJSON accepts a string, a list of strings or null
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*l = nil
+		return nil
+	}
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*l = stringList{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("invalid string list %s", data)
+	}
+	*l = many
+	return nil
+}
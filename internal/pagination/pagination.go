@@ -0,0 +1,195 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultLimit is used when the client does not request a page size
+	DefaultLimit = 20
+
+	// MaxLimit is the largest page size a client may request
+	MaxLimit = 100
+)
+
+// Common errors
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidLimit  = errors.New("invalid limit")
+	ErrInvalidSort   = errors.New("invalid sort field")
+	ErrInvalidOrder  = errors.New("invalid sort order")
+)
+
+// Order is the direction results are sorted in
+type Order string
+
+const (
+	OrderAsc  Order = "asc"
+	OrderDesc Order = "desc"
+)
+
+// Options describes how a collection may be paginated and sorted
+type Options struct {
+	DefaultLimit int      // Page size when none is requested (defaults to DefaultLimit)
+	MaxLimit     int      // Largest allowed page size (defaults to MaxLimit)
+	SortFields   []string // Whitelisted sort fields; the first is the default
+	DefaultOrder Order    // Sort order when none is requested (defaults to OrderDesc)
+	Filters      []string // Whitelisted filter query parameters
+}
+
+// Params holds the parsed pagination, sorting and filtering parameters
+type Params struct {
+	Limit   int               `json:"limit"`
+	Cursor  *Cursor           `json:"-"`
+	Sort    string            `json:"sort"`
+	Order   Order             `json:"order"`
+	Filters map[string]string `json:"filters,omitempty"`
+}
+
+// Cursor marks the position of the last item on the previous page
+type Cursor struct {
+	Value string `json:"v"`  // Value of the sort field for the last item
+	ID    string `json:"id"` // ID of the last item, used as a tie-breaker
+}
+
+// Page is the response envelope for paginated collections
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// Parse extracts pagination parameters from query values using the given options
+func Parse(query url.Values, opts Options) (*Params, error) {
+	opts = opts.withDefaults()
+
+	params := &Params{
+		Limit: opts.DefaultLimit,
+		Order: opts.DefaultOrder,
+	}
+
+	// Clamp limit to the allowed range
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return nil, ErrInvalidLimit
+		}
+		params.Limit = min(limit, opts.MaxLimit)
+	}
+
+	// Only whitelisted fields may be sorted on
+	if len(opts.SortFields) > 0 {
+		params.Sort = opts.SortFields[0]
+	}
+	if raw := query.Get("sort"); raw != "" {
+		field, order := raw, Order("")
+		if strings.HasPrefix(raw, "-") {
+			field, order = raw[1:], OrderDesc
+		}
+		if !slices.Contains(opts.SortFields, field) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSort, field)
+		}
+		params.Sort = field
+		if order != "" {
+			params.Order = order
+		}
+	}
+	if raw := query.Get("order"); raw != "" {
+		order := Order(strings.ToLower(raw))
+		if order != OrderAsc && order != OrderDesc {
+			return nil, ErrInvalidOrder
+		}
+		params.Order = order
+	}
+
+	if raw := query.Get("cursor"); raw != "" {
+		cursor, err := DecodeCursor(raw)
+		if err != nil {
+			return nil, err
+		}
+		params.Cursor = cursor
+	}
+
+	// Unknown filters are ignored rather than rejected
+	for _, name := range opts.Filters {
+		if value := query.Get(name); value != "" {
+			if params.Filters == nil {
+				params.Filters = make(map[string]string)
+			}
+			params.Filters[name] = value
+		}
+	}
+
+	return params, nil
+}
+
+// EncodeCursor encodes a cursor into an opaque URL-safe token
+func EncodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor decodes an opaque token produced by EncodeCursor
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// NewPage builds a page from up to Limit+1 fetched items. Repositories should
+// fetch one extra row so HasMore can be determined without a count query.
+func NewPage[T any](items []T, params *Params, cursorFor func(T) Cursor) Page[T] {
+	page := Page[T]{Items: items}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	if len(items) > params.Limit {
+		page.Items = items[:params.Limit]
+		page.HasMore = true
+		page.NextCursor = EncodeCursor(cursorFor(page.Items[len(page.Items)-1]))
+	}
+
+	return page
+}
+
+// FetchLimit returns the number of rows a repository should request
+func (p *Params) FetchLimit() int {
+	return p.Limit + 1
+}
+
+// Filter returns the value of a whitelisted filter, if present
+func (p *Params) Filter(name string) (string, bool) {
+	value, ok := p.Filters[name]
+	return value, ok
+}
+
+// withDefaults fills in zero-valued options
+func (o Options) withDefaults() Options {
+	if o.MaxLimit <= 0 {
+		o.MaxLimit = MaxLimit
+	}
+	if o.DefaultLimit <= 0 {
+		o.DefaultLimit = DefaultLimit
+	}
+	o.DefaultLimit = min(o.DefaultLimit, o.MaxLimit)
+	if o.DefaultOrder == "" {
+		o.DefaultOrder = OrderDesc
+	}
+	return o
+}
@@ -0,0 +1,144 @@
+package pagination
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   string
+		opts    Options
+		want    int
+		wantErr error
+	}{
+		{"default", "", Options{}, DefaultLimit, nil},
+		{"requested", "5", Options{}, 5, nil},
+		{"clamped to max", "1000", Options{}, MaxLimit, nil},
+		{"clamped to custom max", "50", Options{MaxLimit: 25}, 25, nil},
+		{"custom default", "", Options{DefaultLimit: 10}, 10, nil},
+		{"default above max", "", Options{DefaultLimit: 50, MaxLimit: 25}, 25, nil},
+		{"zero", "0", Options{}, 0, ErrInvalidLimit},
+		{"negative", "-1", Options{}, 0, ErrInvalidLimit},
+		{"not a number", "ten", Options{}, 0, ErrInvalidLimit},
+	}
+	for _, tt := range tests {
+		query := url.Values{}
+		if tt.limit != "" {
+			query.Set("limit", tt.limit)
+		}
+		params, err := Parse(query, tt.opts)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && params.Limit != tt.want {
+			t.Errorf("%s: limit = %d, want %d", tt.name, params.Limit, tt.want)
+		}
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	opts := Options{SortFields: []string{"created_at", "updated_at"}, Filters: []string{"category"}}
+	tests := []struct {
+		name      string
+		query     url.Values
+		wantSort  string
+		wantOrder Order
+		wantErr   error
+	}{
+		{"default", url.Values{}, "created_at", OrderDesc, nil},
+		{"field", url.Values{"sort": {"updated_at"}}, "updated_at", OrderDesc, nil},
+		{"ascending", url.Values{"sort": {"updated_at"}, "order": {"ASC"}}, "updated_at", OrderAsc, nil},
+		{"descending prefix", url.Values{"sort": {"-updated_at"}, "order": {"asc"}}, "updated_at", OrderAsc, nil},
+		{"unknown field", url.Values{"sort": {"password"}}, "", "", ErrInvalidSort},
+		{"unknown order", url.Values{"order": {"up"}}, "", "", ErrInvalidOrder},
+	}
+	for _, tt := range tests {
+		params, err := Parse(tt.query, opts)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (params.Sort != tt.wantSort || params.Order != tt.wantOrder) {
+			t.Errorf("%s: sort = %s %s, want %s %s", tt.name, params.Sort, params.Order, tt.wantSort, tt.wantOrder)
+		}
+	}
+
+	// Only whitelisted filters are kept
+	params, err := Parse(url.Values{"category": {"history"}, "owner": {"alice"}}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := params.Filter("category"); !ok || value != "history" {
+		t.Errorf("category filter = %q, %t, want history", value, ok)
+	}
+	if _, ok := params.Filter("owner"); ok {
+		t.Error("unlisted owner filter kept")
+	}
+}
+
+func TestCursor(t *testing.T) {
+	cursor := Cursor{Value: "2024-01-02T03:04:05.123456789Z", ID: "question-1"}
+	token := EncodeCursor(cursor)
+
+	decoded, err := DecodeCursor(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *decoded != cursor {
+		t.Errorf("decoded cursor = %+v, want %+v", *decoded, cursor)
+	}
+
+	params, err := Parse(url.Values{"cursor": {token}}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Cursor == nil || *params.Cursor != cursor {
+		t.Errorf("parsed cursor = %+v, want %+v", params.Cursor, cursor)
+	}
+
+	for _, token := range []string{"not base64!", "bm90IGpzb24"} {
+		if _, err := DecodeCursor(token); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decoding %q: error = %v, want ErrInvalidCursor", token, err)
+		}
+		if _, err := Parse(url.Values{"cursor": {token}}, Options{}); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("parsing cursor %q: error = %v, want ErrInvalidCursor", token, err)
+		}
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	params := &Params{Limit: 2}
+	cursorFor := func(id string) Cursor { return Cursor{ID: id} }
+
+	tests := []struct {
+		name     string
+		items    []string
+		want     int
+		wantMore bool
+	}{
+		{"empty", nil, 0, false},
+		{"partial", []string{"a"}, 1, false},
+		{"full", []string{"a", "b"}, 2, false},
+		{"more", []string{"a", "b", "c"}, 2, true},
+	}
+	for _, tt := range tests {
+		page := NewPage(tt.items, params, cursorFor)
+		if page.Items == nil || len(page.Items) != tt.want || page.HasMore != tt.wantMore {
+			t.Errorf("%s: page has %d items, more %t, want %d, more %t", tt.name, len(page.Items), page.HasMore, tt.want, tt.wantMore)
+		}
+		if !tt.wantMore {
+			if page.NextCursor != "" {
+				t.Errorf("%s: next cursor = %q, want none", tt.name, page.NextCursor)
+			}
+			continue
+		}
+		next, err := DecodeCursor(page.NextCursor)
+		if err != nil || next.ID != "b" {
+			t.Errorf("%s: next cursor = %+v, %v, want the last item on the page", tt.name, next, err)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package qrcode
+
+// version describes the error correction blocks of a version at level M.
+// Data codewords are split into blocks of blockData codewords followed by
+// longBlocks blocks of one more, and each block gets ecPerBlock error
+// correction codewords.
+type version struct {
+	blocks     int // Blocks of blockData codewords
+	longBlocks int
+	blockData  int
+	ecPerBlock int
+	alignments []int // Centers of the alignment patterns on each axis
+}
+
+// versions are the supported versions at level M, by version number
+var versions = []version{
+	{},
+	{1, 0, 16, 10, nil},
+	{1, 0, 28, 16, []int{6, 18}},
+	{1, 0, 44, 26, []int{6, 22}},
+	{2, 0, 32, 18, []int{6, 26}},
+	{2, 0, 43, 24, []int{6, 30}},
+	{4, 0, 27, 16, []int{6, 34}},
+	{4, 0, 31, 18, []int{6, 22, 38}},
+	{2, 2, 38, 22, []int{6, 24, 42}},
+	{3, 2, 36, 22, []int{6, 26, 46}},
+	{4, 1, 43, 26, []int{6, 28, 50}},
+}
+
+// dataCodewords returns the number of data codewords the version holds
+func (v version) dataCodewords() int {
+	return (v.blocks+v.longBlocks)*v.blockData + v.longBlocks
+}
+
+// byteMode is the mode indicator of data encoded as bytes
+const byteMode = 0b0100
+
+// countBits returns the length of the character count of byte mode data
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// dataBits returns the number of bits n bytes take up in a version
+func dataBits(version int, n int) int {
+	return 4 + countBits(version) + 8*n
+}
+
+// bitBuffer accumulates bits, most significant first
+type bitBuffer struct {
+	bytes []byte
+	n     int
+}
+
+// write appends the low length bits of value
+func (b *bitBuffer) write(value int, length int) {
+	for i := length - 1; i >= 0; i-- {
+		if b.n%8 == 0 {
+			b.bytes = append(b.bytes, 0)
+		}
+		if value>>i&1 == 1 {
+			b.bytes[b.n/8] |= 0x80 >> (b.n % 8)
+		}
+		b.n++
+	}
+}
+
+// encodeData encodes data in byte mode, terminated and padded to fill the
+// version's data codewords
+func encodeData(version int, data []byte) []byte {
+	capacity := versions[version].dataCodewords()
+
+	var buf bitBuffer
+	buf.write(byteMode, 4)
+	buf.write(len(data), countBits(version))
+	for _, b := range data {
+		buf.write(int(b), 8)
+	}
+	buf.write(0, min(4, capacity*8-buf.n))
+
+	// Pad to a whole codeword, then fill with alternating pad codewords
+	for pad := 0xec; len(buf.bytes) < capacity; pad ^= 0xec ^ 0x11 {
+		buf.bytes = append(buf.bytes, byte(pad))
+	}
+	return buf.bytes
+}
+
+// addErrorCorrection splits data codewords into blocks, computes each
+// block's error correction codewords and interleaves them all
+func addErrorCorrection(version int, data []byte) []byte {
+	v := versions[version]
+	generator := rsGenerator(v.ecPerBlock)
+
+	blocks := make([][]byte, 0, v.blocks+v.longBlocks)
+	ecBlocks := make([][]byte, 0, v.blocks+v.longBlocks)
+	for i := 0; i < v.blocks+v.longBlocks; i++ {
+		n := v.blockData
+		if i >= v.blocks {
+			n++
+		}
+		blocks = append(blocks, data[:n])
+		ecBlocks = append(ecBlocks, rsRemainder(data[:n], generator))
+		data = data[n:]
+	}
+
+	var result []byte
+	for i := 0; i <= v.blockData; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < v.ecPerBlock; i++ {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies two elements of GF(2^8) modulo the QR polynomial
+// x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(a, b byte) byte {
+	var product byte
+	for ; b > 0; b >>= 1 {
+		if b&1 == 1 {
+			product ^= a
+		}
+		carry := a&0x80 != 0
+		a <<= 1
+		if carry {
+			a ^= 0x1d
+		}
+	}
+	return product
+}
+
+// rsGenerator returns the coefficients of the Reed-Solomon generator
+// polynomial of the given degree, highest power first without its leading 1
+func rsGenerator(degree int) []byte {
+	generator := make([]byte, degree)
+	generator[degree-1] = 1
+
+	// Multiply by (x - 2^i) for each i, keeping the monic term implicit
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := 0; j < degree; j++ {
+			generator[j] = gfMultiply(generator[j], root)
+			if j+1 < degree {
+				generator[j] ^= generator[j+1]
+			}
+		}
+		root = gfMultiply(root, 2)
+	}
+	return generator
+}
+
+// rsRemainder returns the error correction codewords of a block
+func rsRemainder(data []byte, generator []byte) []byte {
+	remainder := make([]byte, len(generator))
+	for _, b := range data {
+		factor := b ^ remainder[0]
+		copy(remainder, remainder[1:])
+		remainder[len(remainder)-1] = 0
+		for i, coef := range generator {
+			remainder[i] ^= gfMultiply(coef, factor)
+		}
+	}
+	return remainder
+}
@@ -0,0 +1,277 @@
+package qrcode
+
+// newCode creates a light code of a version, ready for drawing
+func newCode(version int) *Code {
+	size := 17 + 4*version
+	c := &Code{Version: version, size: size}
+	c.modules = make([][]bool, size)
+	c.function = make([][]bool, size)
+	for i := range c.modules {
+		c.modules[i] = make([]bool, size)
+		c.function[i] = make([]bool, size)
+	}
+	return c
+}
+
+// setFunction draws a module of a function pattern, which data and masks skip
+func (c *Code) setFunction(row, col int, dark bool) {
+	c.modules[row][col] = dark
+	c.function[row][col] = true
+}
+
+// drawFunctionPatterns draws the finder, timing and alignment patterns, the
+// version information, and reserves the format information modules
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(3, c.size-4)
+	c.drawFinder(c.size-4, 3)
+
+	alignments := versions[c.Version].alignments
+	last := len(alignments) - 1
+	for i, row := range alignments {
+		for j, col := range alignments {
+			// Alignment patterns never overlap the finders
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			c.drawAlignment(row, col)
+		}
+	}
+
+	// Reserve the format information, drawn once the mask is chosen
+	c.drawFormat(0)
+	c.drawVersion()
+}
+
+// drawFinder draws a finder pattern and its separator centered on a module
+func (c *Code) drawFinder(row, col int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			y, x := row+dy, col+dx
+			if y < 0 || y >= c.size || x < 0 || x >= c.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.setFunction(y, x, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawAlignment draws an alignment pattern centered on a module
+func (c *Code) drawAlignment(row, col int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunction(row+dy, col+dx, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormat draws both copies of the format information for level M and a
+// mask, along with the dark module
+func (c *Code) drawFormat(mask int) {
+	// Level M is encoded as 00
+	data := mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	// Around the top left finder
+	for i := 0; i <= 5; i++ {
+		c.setFunction(i, 8, bit(i))
+	}
+	c.setFunction(7, 8, bit(6))
+	c.setFunction(8, 8, bit(7))
+	c.setFunction(8, 7, bit(8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(8, 14-i, bit(i))
+	}
+
+	// Split between the other two finders
+	for i := 0; i < 8; i++ {
+		c.setFunction(8, c.size-1-i, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(c.size-15+i, 8, bit(i))
+	}
+	c.setFunction(c.size-8, 8, true)
+}
+
+// drawVersion draws both copies of the version information of versions 7
+// and up
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+
+	rem := c.Version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1f25
+	}
+	bits := c.Version<<12 | rem
+
+	for i := 0; i < 18; i++ {
+		dark := bits>>i&1 == 1
+		a, b := c.size-11+i%3, i/3
+		c.setFunction(b, a, dark)
+		c.setFunction(a, b, dark)
+	}
+}
+
+// drawCodewords places the codewords' bits in the zigzag order of the data
+// area, from the bottom right upwards in pairs of columns
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := c.size - 1; right >= 1; right -= 2 {
+		// The vertical timing pattern shifts the columns to its left
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.size; vert++ {
+			row := vert
+			if upward {
+				row = c.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				col := right - j
+				if c.function[row][col] {
+					continue
+				}
+				// Modules past the codewords are remainder bits, left light
+				if i < len(codewords)*8 {
+					c.modules[row][col] = codewords[i/8]>>(7-i%8)&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by a mask pattern
+func (c *Code) applyMask(mask int) {
+	for row := 0; row < c.size; row++ {
+		for col := 0; col < c.size; col++ {
+			if !c.function[row][col] && masked(mask, row, col) {
+				c.modules[row][col] = !c.modules[row][col]
+			}
+		}
+	}
+}
+
+// masked reports whether a mask pattern inverts a module
+func masked(mask, row, col int) bool {
+	switch mask {
+	case 0:
+		return (row+col)%2 == 0
+	case 1:
+		return row%2 == 0
+	case 2:
+		return col%3 == 0
+	case 3:
+		return (row+col)%3 == 0
+	case 4:
+		return (row/2+col/3)%2 == 0
+	case 5:
+		return row*col%2+row*col%3 == 0
+	case 6:
+		return (row*col%2+row*col%3)%2 == 0
+	default:
+		return ((row+col)%2+row*col%3)%2 == 0
+	}
+}
+
+// penalty scores how hard a masked code is to scan, lower being better
+func (c *Code) penalty() int {
+	penalty := 0
+	dark := 0
+
+	line := make([]bool, c.size)
+	for _, columns := range []bool{false, true} {
+		for i := 0; i < c.size; i++ {
+			for j := 0; j < c.size; j++ {
+				if columns {
+					line[j] = c.modules[j][i]
+				} else {
+					line[j] = c.modules[i][j]
+				}
+			}
+			penalty += linePenalty(line)
+		}
+	}
+
+	for row := 0; row < c.size; row++ {
+		for col := 0; col < c.size; col++ {
+			if c.modules[row][col] {
+				dark++
+			}
+			if row+1 < c.size && col+1 < c.size {
+				m := c.modules[row][col]
+				if m == c.modules[row+1][col] && m == c.modules[row][col+1] && m == c.modules[row+1][col+1] {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	// Penalize every 5% the dark modules stray from half
+	total := c.size * c.size
+	penalty += abs(dark*100/total-50) / 5 * 10
+	return penalty
+}
+
+// finderLike are runs resembling a finder pattern, which scanners could mistake for one
+var finderLike = [][]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+// linePenalty scores the runs of same colored modules and the finder-like
+// patterns in a row or column
+func linePenalty(line []bool) int {
+	penalty := 0
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			penalty += 3 + run - 5
+		}
+		run = 1
+	}
+
+	for i := 0; i+len(finderLike[0]) <= len(line); i++ {
+		for _, pattern := range finderLike {
+			if matches(line[i:i+len(pattern)], pattern) {
+				penalty += 40
+			}
+		}
+	}
+	return penalty
+}
+
+// matches reports whether modules equal a pattern
+func matches(modules []bool, pattern []bool) bool {
+	for i := range pattern {
+		if modules[i] != pattern[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,106 @@
+// Package qrcode encodes short texts, such as links, as QR codes. It supports
+// byte mode at error correction level M in versions 1 to 10, enough for
+// links of up to 213 bytes, which keeps it small enough to carry without a
+// dependency.
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// ErrTooLong is returned when a text does not fit in the largest supported version
+var ErrTooLong = errors.New("text too long for a QR code")
+
+// quietZone is the width of the light border required around a code, in modules
+const quietZone = 4
+
+// Code is an encoded QR code
+type Code struct {
+	Version int
+	size    int
+	modules [][]bool // Dark modules, by row then column
+
+	// function marks the modules of function patterns while the code is drawn
+	function [][]bool
+}
+
+// Encode encodes a text in the smallest version it fits in, with the mask
+// pattern that is easiest to scan
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+	version := 0
+	for v := 1; v < len(versions); v++ {
+		if dataBits(v, len(data)) <= versions[v].dataCodewords()*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	codewords := addErrorCorrection(version, encodeData(version, data))
+
+	var best *Code
+	bestPenalty := 0
+	for mask := 0; mask < 8; mask++ {
+		code := draw(version, codewords, mask)
+		if penalty := code.penalty(); best == nil || penalty < bestPenalty {
+			best, bestPenalty = code, penalty
+		}
+	}
+	best.function = nil
+	return best, nil
+}
+
+// draw draws a code of a version from its codewords, masked with a mask pattern
+func draw(version int, codewords []byte, mask int) *Code {
+	code := newCode(version)
+	code.drawFunctionPatterns()
+	code.drawCodewords(codewords)
+	code.applyMask(mask)
+	code.drawFormat(mask)
+	return code
+}
+
+// Image renders the code with each module scale pixels wide, surrounded by
+// its quiet zone
+func (c *Code) Image(scale int) image.Image {
+	scale = max(scale, 1)
+	width := (c.size + 2*quietZone) * scale
+	img := image.NewGray(image.Rect(0, 0, width, width))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+
+	for row := 0; row < c.size; row++ {
+		for col := 0; col < c.size; col++ {
+			if !c.modules[row][col] {
+				continue
+			}
+			x, y := (col+quietZone)*scale, (row+quietZone)*scale
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetGray(x+dx, y+dy, color.Gray{})
+				}
+			}
+		}
+	}
+	return img
+}
+
+// PNG renders the code as a PNG image at most width pixels wide, with each
+// module the largest whole number of pixels that fits, but at least one
+func (c *Code) PNG(width int) ([]byte, error) {
+	scale := width / (c.size + 2*quietZone)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.Image(scale)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,305 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"rsc.io/qr/coding"
+)
+
+// capacities are the most bytes each version holds at level M, by version number
+var capacities = []int{0, 14, 26, 42, 62, 84, 106, 122, 152, 180, 213}
+
+func TestEncodeMatchesReference(t *testing.T) {
+	for v := 1; v < len(versions); v++ {
+		for _, n := range []int{capacities[v-1] + 1, capacities[v]} {
+			text := strings.Repeat("https://dahaa.example/join/", 8)[:n]
+			codewords := addErrorCorrection(v, encodeData(v, []byte(text)))
+			for mask := 0; mask < 8; mask++ {
+				plan, err := coding.NewPlan(coding.Version(v), coding.M, coding.Mask(mask))
+				if err != nil {
+					t.Fatalf("version %d mask %d: plan error = %v", v, mask, err)
+				}
+				want, err := plan.Encode(coding.String(text))
+				if err != nil {
+					t.Fatalf("version %d mask %d: reference error = %v", v, mask, err)
+				}
+
+				got := draw(v, codewords, mask)
+				if got.size != want.Size {
+					t.Fatalf("version %d mask %d: size = %d, want %d", v, mask, got.size, want.Size)
+				}
+				for row := 0; row < got.size; row++ {
+					for col := 0; col < got.size; col++ {
+						if got.modules[row][col] != want.Black(col, row) {
+							t.Fatalf("%d bytes in version %d mask %d: module (%d, %d) differs from the reference", n, v, mask, row, col)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestEncodeVersion(t *testing.T) {
+	for v := 1; v < len(versions); v++ {
+		for _, tt := range []struct {
+			n       int
+			version int
+		}{
+			{capacities[v-1] + 1, v},
+			{capacities[v], v},
+		} {
+			code, err := Encode(strings.Repeat("a", tt.n))
+			if err != nil {
+				t.Fatalf("%d bytes: error = %v", tt.n, err)
+			}
+			if code.Version != tt.version {
+				t.Errorf("%d bytes: version = %d, want %d", tt.n, code.Version, tt.version)
+			}
+			if code.size != 17+4*tt.version {
+				t.Errorf("%d bytes: size = %d, want %d", tt.n, code.size, 17+4*tt.version)
+			}
+		}
+	}
+
+	if _, err := Encode(strings.Repeat("a", capacities[len(capacities)-1]+1)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("%d bytes: error = %v, want ErrTooLong", capacities[len(capacities)-1]+1, err)
+	}
+}
+
+func TestEncodeChoosesReferenceMask(t *testing.T) {
+	text := "https://dahaa.example/join/ABCD"
+	code, err := Encode(text)
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	// The chosen mask is read back from the format information
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if readFormat(code) == formatBits[m] {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("format information %015b is not level M", readFormat(code))
+	}
+
+	plan, err := coding.NewPlan(coding.Version(code.Version), coding.M, coding.Mask(mask))
+	if err != nil {
+		t.Fatalf("plan error = %v", err)
+	}
+	want, err := plan.Encode(coding.String(text))
+	if err != nil {
+		t.Fatalf("reference error = %v", err)
+	}
+	for row := 0; row < code.size; row++ {
+		for col := 0; col < code.size; col++ {
+			if code.modules[row][col] != want.Black(col, row) {
+				t.Fatalf("module (%d, %d) differs from the reference with mask %d", row, col, mask)
+			}
+		}
+	}
+}
+
+func TestEncodeData(t *testing.T) {
+	got := encodeData(1, []byte("dahaa"))
+	want := []byte{
+		0x40, 0x56, 0x46, 0x16, 0x86, 0x16, 0x10, // Mode, count, data and terminator
+		0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec,
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeData = % x, want % x", got, want)
+	}
+
+	// Version 10 counts bytes in 16 bits
+	got = encodeData(10, []byte("a"))
+	if got[0] != 0x40 || got[1] != 0x00 || got[2] != 0x16 || got[3] != 0x10 {
+		t.Errorf("encodeData in version 10 = % x, want 40 00 16 10 ...", got[:4])
+	}
+	if len(got) != versions[10].dataCodewords() {
+		t.Errorf("encodeData in version 10: %d codewords, want %d", len(got), versions[10].dataCodewords())
+	}
+}
+
+func TestErrorCorrection(t *testing.T) {
+	// "HELLO WORLD" in alphanumeric mode, version 1-M
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	if got := rsRemainder(data, rsGenerator(10)); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder = %v, want %v", got, want)
+	}
+	if got := addErrorCorrection(1, data); !bytes.Equal(got, append(data, want...)) {
+		t.Errorf("addErrorCorrection = %v, want %v", got, append(data, want...))
+	}
+}
+
+func TestGFMultiply(t *testing.T) {
+	tests := []struct {
+		a, b, want byte
+	}{
+		{0, 0x53, 0},
+		{1, 0x53, 0x53},
+		{2, 0x80, 0x1d},
+		{0x80, 2, 0x1d},
+		{0x8e, 2, 0x01}, // 2 * 2^254 = 2^255 = 1
+		{0x02, 0x8e, 0x01},
+	}
+	for _, tt := range tests {
+		if got := gfMultiply(tt.a, tt.b); got != tt.want {
+			t.Errorf("gfMultiply(%#x, %#x) = %#x, want %#x", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// formatBits are the format information strings of level M, by mask
+var formatBits = []int{
+	0b101010000010010,
+	0b101000100100101,
+	0b101111001111100,
+	0b101101101001011,
+	0b100010111111001,
+	0b100000011001110,
+	0b100111110010111,
+	0b100101010100000,
+}
+
+// readFormat reads the format information around the top left finder,
+// checking that the copy split between the other two finders matches it
+func readFormat(c *Code) int {
+	bits, split := 0, 0
+	read := func(bits *int, i, row, col int) {
+		if c.modules[row][col] {
+			*bits |= 1 << i
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		read(&bits, i, i, 8)
+	}
+	read(&bits, 6, 7, 8)
+	read(&bits, 7, 8, 8)
+	read(&bits, 8, 8, 7)
+	for i := 9; i < 15; i++ {
+		read(&bits, i, 8, 14-i)
+	}
+
+	for i := 0; i < 8; i++ {
+		read(&split, i, 8, c.size-1-i)
+	}
+	for i := 8; i < 15; i++ {
+		read(&split, i, c.size-15+i, 8)
+	}
+	if split != bits {
+		return -1
+	}
+	return bits
+}
+
+func TestFormat(t *testing.T) {
+	for mask, want := range formatBits {
+		code := newCode(1)
+		code.drawFormat(mask)
+		if got := readFormat(code); got != want {
+			t.Errorf("mask %d: format information = %015b, want %015b", mask, got, want)
+		}
+		if !code.modules[code.size-8][8] {
+			t.Errorf("mask %d: dark module is light", mask)
+		}
+	}
+}
+
+func TestVersionInformation(t *testing.T) {
+	tests := []struct {
+		version int
+		want    int
+	}{
+		{7, 0b000111110010010100},
+		{8, 0b001000010110111100},
+		{9, 0b001001101010011001},
+		{10, 0b001010010011010011},
+	}
+	for _, tt := range tests {
+		code := newCode(tt.version)
+		code.drawVersion()
+
+		below, right := 0, 0
+		for i := 0; i < 18; i++ {
+			a, b := code.size-11+i%3, i/3
+			if code.modules[a][b] {
+				below |= 1 << i
+			}
+			if code.modules[b][a] {
+				right |= 1 << i
+			}
+		}
+		if below != tt.want || right != tt.want {
+			t.Errorf("version %d: version information = %018b and %018b, want %018b", tt.version, below, right, tt.want)
+		}
+	}
+
+	code := newCode(6)
+	code.drawVersion()
+	for row := range code.function {
+		for col, function := range code.function[row] {
+			if function {
+				t.Fatalf("version 6: version information drawn at (%d, %d)", row, col)
+			}
+		}
+	}
+}
+
+func TestFunctionPatterns(t *testing.T) {
+	code, err := Encode("dahaa")
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+
+	finder := []string{
+		"#######",
+		"#.....#",
+		"#.###.#",
+		"#.###.#",
+		"#.###.#",
+		"#.....#",
+		"#######",
+	}
+	for _, corner := range [][2]int{{0, 0}, {0, code.size - 7}, {code.size - 7, 0}} {
+		for dy, line := range finder {
+			for dx, module := range line {
+				row, col := corner[0]+dy, corner[1]+dx
+				if code.modules[row][col] != (module == '#') {
+					t.Errorf("finder at (%d, %d): module (%d, %d) is wrong", corner[0], corner[1], row, col)
+				}
+			}
+		}
+	}
+
+	for i := 8; i < code.size-8; i++ {
+		if code.modules[6][i] != (i%2 == 0) || code.modules[i][6] != (i%2 == 0) {
+			t.Errorf("timing pattern: module %d is wrong", i)
+		}
+	}
+}
+
+func TestPNG(t *testing.T) {
+	code, err := Encode("dahaa")
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	png, err := code.PNG(290)
+	if err != nil {
+		t.Fatalf("PNG error = %v", err)
+	}
+	if !bytes.HasPrefix(png, []byte("\x89PNG\r\n\x1a\n")) {
+		t.Errorf("PNG does not start with the PNG signature")
+	}
+
+	// 21 modules and the quiet zone at 10 pixels each
+	if got := code.Image(10).Bounds().Dx(); got != 290 {
+		t.Errorf("image width = %d, want 290", got)
+	}
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// Compile-time check that the decorator satisfies the domain interface
+var _ domain.HallOfFameRepository = (*HallOfFameRepository)(nil)
+
+// HallOfFameRepository decorates a domain.HallOfFameRepository, running the
+// invalidation hooks after every change of consent, so that cached listings
+// stop showing the answers of users who withdrew it
+type HallOfFameRepository struct {
+	domain.HallOfFameRepository
+
+	mu    sync.Mutex
+	hooks []func(ctx context.Context)
+}
+
+// NewHallOfFameRepository creates a decorator around a hall of fame repository
+func NewHallOfFameRepository(repo domain.HallOfFameRepository) *HallOfFameRepository {
+	return &HallOfFameRepository{HallOfFameRepository: repo}
+}
+
+// SetConsent records whether a user lets their entries be shown
+func (r *HallOfFameRepository) SetConsent(ctx context.Context, userID string, consent bool) error {
+	if err := r.HallOfFameRepository.SetConsent(ctx, userID, consent); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	hooks := slices.Clone(r.hooks)
+	r.mu.Unlock()
+	for _, hook := range hooks {
+		hook(ctx)
+	}
+	return nil
+}
+
+// OnInvalidate adds a hook run after every change of consent
+func (r *HallOfFameRepository) OnInvalidate(hook func(ctx context.Context)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hooks = append(r.hooks, hook)
+}
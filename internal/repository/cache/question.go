@@ -0,0 +1,167 @@
+// Package cache provides caching decorators for the domain repositories
+package cache
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// Compile-time check that the decorator satisfies the domain interface
+var _ domain.QuestionRepository = (*QuestionRepository)(nil)
+
+// QuestionRepository decorates a domain.QuestionRepository, caching the
+// category list and per-category counts that lobbies and game creation read
+// on every request. Writes through the decorator invalidate the cache and run
+// the invalidation hooks; the TTL bounds how long writes made elsewhere,
+// such as by the CLI, go unseen.
+type QuestionRepository struct {
+	domain.QuestionRepository
+	ttl time.Duration
+
+	mu         sync.Mutex
+	generation int // Incremented by every invalidation
+	categories *entry[[]string]
+	counts     map[domain.ContentRating]*entry[[]domain.CategoryCount]
+	hooks      []func(ctx context.Context)
+}
+
+// entry is a cached value along with when it was loaded
+type entry[T any] struct {
+	value    T
+	loadedAt time.Time
+}
+
+// NewQuestionRepository creates a caching decorator around a question
+// repository, keeping category listings for at most ttl
+func NewQuestionRepository(repo domain.QuestionRepository, ttl time.Duration) *QuestionRepository {
+	return &QuestionRepository{
+		QuestionRepository: repo,
+		ttl:                ttl,
+		counts:             make(map[domain.ContentRating]*entry[[]domain.CategoryCount]),
+	}
+}
+
+// GetCategories retrieves all available categories
+func (r *QuestionRepository) GetCategories(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	if fresh(r.categories, r.ttl) {
+		categories := slices.Clone(r.categories.value)
+		r.mu.Unlock()
+		return categories, nil
+	}
+	generation := r.generation
+	r.mu.Unlock()
+
+	categories, err := r.QuestionRepository.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.generation == generation {
+		r.categories = &entry[[]string]{value: slices.Clone(categories), loadedAt: time.Now()}
+	}
+	r.mu.Unlock()
+	return categories, nil
+}
+
+// GetCategoryCounts retrieves all available categories with their counts of
+// questions rated no edgier than maxRating
+func (r *QuestionRepository) GetCategoryCounts(ctx context.Context, maxRating domain.ContentRating) ([]domain.CategoryCount, error) {
+	r.mu.Lock()
+	if cached := r.counts[maxRating]; fresh(cached, r.ttl) {
+		counts := slices.Clone(cached.value)
+		r.mu.Unlock()
+		return counts, nil
+	}
+	generation := r.generation
+	r.mu.Unlock()
+
+	counts, err := r.QuestionRepository.GetCategoryCounts(ctx, maxRating)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.generation == generation {
+		r.counts[maxRating] = &entry[[]domain.CategoryCount]{value: slices.Clone(counts), loadedAt: time.Now()}
+	}
+	r.mu.Unlock()
+	return counts, nil
+}
+
+// CreateQuestion creates a new question
+func (r *QuestionRepository) CreateQuestion(ctx context.Context, question *domain.Question) error {
+	defer r.invalidate(ctx)
+	return r.QuestionRepository.CreateQuestion(ctx, question)
+}
+
+// UpdateQuestion updates an existing question
+func (r *QuestionRepository) UpdateQuestion(ctx context.Context, question *domain.Question) error {
+	defer r.invalidate(ctx)
+	return r.QuestionRepository.UpdateQuestion(ctx, question)
+}
+
+// DeleteQuestion soft-deletes a question
+func (r *QuestionRepository) DeleteQuestion(ctx context.Context, id string) error {
+	defer r.invalidate(ctx)
+	return r.QuestionRepository.DeleteQuestion(ctx, id)
+}
+
+// RestoreQuestion restores a deleted question
+func (r *QuestionRepository) RestoreQuestion(ctx context.Context, id string) error {
+	defer r.invalidate(ctx)
+	return r.QuestionRepository.RestoreQuestion(ctx, id)
+}
+
+// BulkCreateQuestions creates multiple questions in a single transaction
+func (r *QuestionRepository) BulkCreateQuestions(ctx context.Context, questions []*domain.Question) error {
+	defer r.invalidate(ctx)
+	return r.QuestionRepository.BulkCreateQuestions(ctx, questions)
+}
+
+// RenameCategory moves every question in a category to another
+func (r *QuestionRepository) RenameCategory(ctx context.Context, from string, to string, merge bool) (int, error) {
+	defer r.invalidate(ctx)
+	return r.QuestionRepository.RenameCategory(ctx, from, to, merge)
+}
+
+// PublishPack releases a language's unreleased questions as the next
+// version of its pack
+func (r *QuestionRepository) PublishPack(ctx context.Context, language string, notes string, at time.Time) (*domain.PackRelease, error) {
+	defer r.invalidate(ctx)
+	return r.QuestionRepository.PublishPack(ctx, language, notes, at)
+}
+
+// OnInvalidate adds a hook run after every write through the decorator, so
+// caches of what the write may have changed can be invalidated as well
+func (r *QuestionRepository) OnInvalidate(hook func(ctx context.Context)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hooks = append(r.hooks, hook)
+}
+
+// invalidate drops every cached listing and runs the invalidation hooks.
+// Loads that began before it are not cached, as they may predate the write.
+func (r *QuestionRepository) invalidate(ctx context.Context) {
+	r.mu.Lock()
+	r.generation++
+	r.categories = nil
+	clear(r.counts)
+	hooks := slices.Clone(r.hooks)
+	r.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook(ctx)
+	}
+}
+
+// fresh reports whether a cached entry exists and was loaded within the TTL
+func fresh[T any](e *entry[T], ttl time.Duration) bool {
+	return e != nil && time.Since(e.loadedAt) < ttl
+}
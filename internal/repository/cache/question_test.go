@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/random"
+	"github.com/zizouhuweidi/dahaa/internal/repository/memory"
+)
+
+// countingQuestions counts the category listings loaded from a repository
+type countingQuestions struct {
+	*memory.QuestionRepository
+	seeded string // ID of the released history question
+	loads  int
+}
+
+func (q *countingQuestions) GetCategories(ctx context.Context) ([]string, error) {
+	q.loads++
+	return q.QuestionRepository.GetCategories(ctx)
+}
+
+// newCountingQuestions creates a repository with a released history question
+func newCountingQuestions(t *testing.T) *countingQuestions {
+	t.Helper()
+
+	repo := memory.NewQuestionRepository(random.New(1))
+	question := &domain.Question{Text: "history question", Answer: "answer", Category: "history", Language: "en"}
+	if err := repo.SeedQuestions(context.Background(), question); err != nil {
+		t.Fatal(err)
+	}
+	return &countingQuestions{QuestionRepository: repo, seeded: question.ID}
+}
+
+func TestQuestionRepositoryInvalidation(t *testing.T) {
+	ctx := context.Background()
+	science := func() *domain.Question {
+		return &domain.Question{Text: "science question", Answer: "answer", Category: "science", Language: "en"}
+	}
+
+	tests := []struct {
+		name  string
+		setup func(inner *countingQuestions) error // Changes made before the listing is cached
+		write func(repo *QuestionRepository, id string) error
+		want  []string
+	}{
+		{
+			name:  "create",
+			write: func(repo *QuestionRepository, id string) error { return repo.CreateQuestion(ctx, science()) },
+			want:  []string{"history"}, // Unreleased until the pack is published
+		},
+		{
+			name: "bulk create",
+			write: func(repo *QuestionRepository, id string) error {
+				return repo.BulkCreateQuestions(ctx, []*domain.Question{science()})
+			},
+			want: []string{"history"},
+		},
+		{
+			name:  "publish",
+			setup: func(inner *countingQuestions) error { return inner.CreateQuestion(ctx, science()) },
+			write: func(repo *QuestionRepository, id string) error {
+				_, err := repo.PublishPack(ctx, "en", "", time.Now())
+				return err
+			},
+			want: []string{"history", "science"},
+		},
+		{
+			name: "update",
+			write: func(repo *QuestionRepository, id string) error {
+				return repo.UpdateQuestion(ctx, &domain.Question{ID: id, Text: "art question", Answer: "answer", Category: "art"})
+			},
+			want: []string{"art"},
+		},
+		{
+			name:  "delete",
+			write: func(repo *QuestionRepository, id string) error { return repo.DeleteQuestion(ctx, id) },
+			want:  []string{},
+		},
+		{
+			name:  "restore",
+			setup: func(inner *countingQuestions) error { return inner.DeleteQuestion(ctx, inner.seeded) },
+			write: func(repo *QuestionRepository, id string) error { return repo.RestoreQuestion(ctx, id) },
+			want:  []string{"history"},
+		},
+		{
+			name: "rename",
+			write: func(repo *QuestionRepository, id string) error {
+				_, err := repo.RenameCategory(ctx, "history", "geography", false)
+				return err
+			},
+			want: []string{"geography"},
+		},
+	}
+	for _, tt := range tests {
+		inner := newCountingQuestions(t)
+		if tt.setup != nil {
+			if err := tt.setup(inner); err != nil {
+				t.Fatalf("%s: setup: %v", tt.name, err)
+			}
+		}
+		repo := NewQuestionRepository(inner, time.Hour)
+		hooks := 0
+		repo.OnInvalidate(func(ctx context.Context) { hooks++ })
+
+		// Listings are loaded once while fresh
+		for i := 0; i < 2; i++ {
+			if _, err := repo.GetCategories(ctx); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if inner.loads != 1 {
+			t.Errorf("%s: loaded categories %d times before the write, want 1", tt.name, inner.loads)
+		}
+
+		if err := tt.write(repo, inner.seeded); err != nil {
+			t.Fatalf("%s: write: %v", tt.name, err)
+		}
+		if hooks != 1 {
+			t.Errorf("%s: hooks ran %d times, want 1", tt.name, hooks)
+		}
+		categories, err := repo.GetCategories(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if inner.loads != 2 || !slices.Equal(categories, tt.want) {
+			t.Errorf("%s: categories after the write = %v after %d loads, want %v after 2", tt.name, categories, inner.loads, tt.want)
+		}
+	}
+}
+
+func TestQuestionRepositoryTTL(t *testing.T) {
+	ctx := context.Background()
+	inner := newCountingQuestions(t)
+	repo := NewQuestionRepository(inner, time.Hour)
+	if _, err := repo.GetCategories(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes made around the decorator go unseen until the listing expires
+	if err := inner.DeleteQuestion(ctx, inner.seeded); err != nil {
+		t.Fatal(err)
+	}
+	categories, err := repo.GetCategories(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(categories, []string{"history"}) {
+		t.Errorf("categories before expiry = %v, want the cached history", categories)
+	}
+
+	repo.mu.Lock()
+	repo.categories.loadedAt = time.Now().Add(-time.Hour)
+	repo.mu.Unlock()
+	if categories, err = repo.GetCategories(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(categories) != 0 || inner.loads != 2 {
+		t.Errorf("categories after expiry = %v after %d loads, want none after 2", categories, inner.loads)
+	}
+}
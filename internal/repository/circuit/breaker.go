@@ -0,0 +1,128 @@
+// Package circuit provides circuit breakers and decorators that put them in
+// front of dependencies the game can do without for a while, so that when
+// one starts failing, requests fall back at once instead of each waiting
+// out a timeout in the middle of a timed round.
+package circuit
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned instead of calling a dependency whose breaker is open
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a circuit breaker
+type State string
+
+const (
+	StateClosed   State = "closed"    // Calls go through
+	StateOpen     State = "open"      // Calls are rejected until the cooldown passes
+	StateHalfOpen State = "half_open" // A trial call is deciding whether to close again
+)
+
+// Breaker stops calling a dependency after it fails a number of times in a
+// row. Once a cooldown has passed, it lets a single trial call through,
+// closing again if it succeeds and staying open for another cooldown if not.
+type Breaker struct {
+	threshold int           // Consecutive failures that open the breaker
+	cooldown  time.Duration // How long the breaker stays open before a trial call
+
+	mu       sync.Mutex
+	state    State
+	failures int       // Consecutive failures while closed
+	openedAt time.Time // When the breaker last opened
+	trial    bool      // Whether a trial call is in flight while half open
+	opened   int64     // Times the breaker has opened
+	rejected int64     // Calls rejected while open
+}
+
+// Stats describes a breaker for metrics
+type Stats struct {
+	State    State `json:"state"`
+	Failures int   `json:"failures"` // Consecutive failures while closed
+	Opened   int64 `json:"opened"`   // Times the breaker has opened
+	Rejected int64 `json:"rejected"` // Calls rejected while open
+}
+
+// NewBreaker creates a closed breaker that opens after threshold failures
+// in a row and tries again after the cooldown
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     StateClosed,
+	}
+}
+
+// Allow reports whether a call may go through, returning ErrOpen if not.
+// Every allowed call must be followed by Record with its outcome.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			b.rejected++
+			return ErrOpen
+		}
+		b.state = StateHalfOpen
+		b.trial = true
+		return nil
+	case StateHalfOpen:
+		if b.trial {
+			b.rejected++
+			return ErrOpen
+		}
+		b.trial = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record records the outcome of an allowed call
+func (b *Breaker) Record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case b.state == StateHalfOpen && failed:
+		b.open()
+	case b.state == StateHalfOpen:
+		b.state = StateClosed
+		b.failures = 0
+		b.trial = false
+	case failed:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.open()
+		}
+	default:
+		b.failures = 0
+	}
+}
+
+// Stats returns the breaker's state and counters
+func (b *Breaker) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return Stats{
+		State:    b.state,
+		Failures: b.failures,
+		Opened:   b.opened,
+		Rejected: b.rejected,
+	}
+}
+
+// open opens the breaker. The caller must hold the lock.
+func (b *Breaker) open() {
+	b.state = StateOpen
+	b.openedAt = time.Now()
+	b.failures = 0
+	b.trial = false
+	b.opened++
+}
@@ -0,0 +1,97 @@
+package circuit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	// Steps are applied in order to a breaker opening after two failures
+	type step struct {
+		action    string // allow, fail, succeed or cool down
+		wantErr   error  // For allow
+		wantState State
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"stays closed on success", []step{
+			{"allow", nil, StateClosed},
+			{"succeed", nil, StateClosed},
+		}},
+		{"failures must be consecutive", []step{
+			{"allow", nil, StateClosed},
+			{"fail", nil, StateClosed},
+			{"allow", nil, StateClosed},
+			{"succeed", nil, StateClosed},
+			{"allow", nil, StateClosed},
+			{"fail", nil, StateClosed},
+		}},
+		{"opens at the threshold", []step{
+			{"allow", nil, StateClosed},
+			{"fail", nil, StateClosed},
+			{"allow", nil, StateClosed},
+			{"fail", nil, StateOpen},
+			{"allow", ErrOpen, StateOpen},
+		}},
+		{"half opens after the cooldown", []step{
+			{"fail", nil, StateClosed},
+			{"fail", nil, StateOpen},
+			{"cool down", nil, StateOpen},
+			{"allow", nil, StateHalfOpen},
+			{"allow", ErrOpen, StateHalfOpen},
+		}},
+		{"closes after a successful trial", []step{
+			{"fail", nil, StateClosed},
+			{"fail", nil, StateOpen},
+			{"cool down", nil, StateOpen},
+			{"allow", nil, StateHalfOpen},
+			{"succeed", nil, StateClosed},
+			{"allow", nil, StateClosed},
+			{"fail", nil, StateClosed},
+		}},
+		{"reopens after a failed trial", []step{
+			{"fail", nil, StateClosed},
+			{"fail", nil, StateOpen},
+			{"cool down", nil, StateOpen},
+			{"allow", nil, StateHalfOpen},
+			{"fail", nil, StateOpen},
+			{"allow", ErrOpen, StateOpen},
+		}},
+	}
+	for _, tt := range tests {
+		b := NewBreaker(2, time.Minute)
+		for i, s := range tt.steps {
+			switch s.action {
+			case "allow":
+				if err := b.Allow(); !errors.Is(err, s.wantErr) {
+					t.Errorf("%s: step %d: Allow = %v, want %v", tt.name, i, err, s.wantErr)
+				}
+			case "fail":
+				b.Record(true)
+			case "succeed":
+				b.Record(false)
+			case "cool down":
+				b.mu.Lock()
+				b.openedAt = b.openedAt.Add(-b.cooldown)
+				b.mu.Unlock()
+			}
+			if state := b.Stats().State; state != s.wantState {
+				t.Errorf("%s: step %d (%s): state = %s, want %s", tt.name, i, s.action, state, s.wantState)
+			}
+		}
+	}
+}
+
+func TestBreakerStats(t *testing.T) {
+	b := NewBreaker(1, time.Minute)
+	b.Record(true)
+	for i := 0; i < 3; i++ {
+		b.Allow()
+	}
+	if stats := b.Stats(); stats.Opened != 1 || stats.Rejected != 3 || stats.Failures != 0 {
+		t.Errorf("stats = %+v, want opened once with 3 rejected calls", stats)
+	}
+}
@@ -0,0 +1,37 @@
+package circuit
+
+import (
+	"context"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// Compile-time check that the decorator satisfies the domain interface
+var _ domain.FillerGenerator = (*FillerGenerator)(nil)
+
+// FillerGenerator decorates a filler generator with a circuit breaker. While
+// the breaker is open, generating fails with ErrOpen at once, and the game
+// service falls back to filling the voting pool from templates.
+type FillerGenerator struct {
+	generator domain.FillerGenerator
+	breaker   *Breaker
+}
+
+// NewFillerGenerator creates a decorator guarding a filler generator with a
+// breaker
+func NewFillerGenerator(generator domain.FillerGenerator, breaker *Breaker) *FillerGenerator {
+	return &FillerGenerator{
+		generator: generator,
+		breaker:   breaker,
+	}
+}
+
+// Generate returns up to n wrong answers to a question
+func (g *FillerGenerator) Generate(ctx context.Context, question string, correctAnswer string, category string, language string, n int) ([]string, error) {
+	if err := g.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	answers, err := g.generator.Generate(ctx, question, correctAnswer, category, language, n)
+	g.breaker.Record(failed(err))
+	return answers, err
+}
@@ -0,0 +1,256 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// Compile-time checks that the decorator satisfies the domain interfaces
+var (
+	_ domain.GameSessionStore = (*GameSessionStore)(nil)
+	_ domain.QuestionHistory  = (*GameSessionStore)(nil)
+)
+
+// SessionStore is a session store that also keeps players' question history,
+// as the Redis session manager does
+type SessionStore interface {
+	domain.GameSessionStore
+	domain.QuestionHistory
+}
+
+// GameSessionStore decorates a session store with a circuit breaker. While
+// the breaker is open, operations fail with ErrOpen at once, and the game
+// service falls back to reading games from Postgres.
+//
+// A game whose snapshot could not be written while the store was failing is
+// stale in the store once it recovers. The decorator remembers such games
+// and evicts their snapshots before serving them again, so they are read
+// back from Postgres.
+type GameSessionStore struct {
+	store   SessionStore
+	breaker *Breaker
+
+	mu    sync.Mutex
+	stale map[string]bool // IDs of games whose stored snapshots may be stale
+}
+
+// NewGameSessionStore creates a decorator guarding a session store with a breaker
+func NewGameSessionStore(store SessionStore, breaker *Breaker) *GameSessionStore {
+	return &GameSessionStore{
+		store:   store,
+		breaker: breaker,
+		stale:   make(map[string]bool),
+	}
+}
+
+// StoreGame stores an active game
+func (s *GameSessionStore) StoreGame(ctx context.Context, game *domain.Game) error {
+	if err := s.call(func() error { return s.store.StoreGame(ctx, game) }); err != nil {
+		s.markStale(game.ID, true)
+		return err
+	}
+	s.markStale(game.ID, false)
+	return nil
+}
+
+// StoreGameRounds stores an active game's header and its rounds from index
+// from on. A game marked stale is stored whole instead, since its earlier
+// rounds may be missing or out of date in the store.
+func (s *GameSessionStore) StoreGameRounds(ctx context.Context, game *domain.Game, from int) error {
+	if s.isStale(game.ID) {
+		return s.StoreGame(ctx, game)
+	}
+	if err := s.call(func() error { return s.store.StoreGameRounds(ctx, game, from) }); err != nil {
+		s.markStale(game.ID, true)
+		return err
+	}
+	return nil
+}
+
+// GetGame retrieves an active game by its ID. A game marked stale is evicted
+// and reported as not found, so it is read back from Postgres.
+func (s *GameSessionStore) GetGame(ctx context.Context, gameID string) (*domain.Game, error) {
+	if s.isStale(gameID) {
+		if err := s.DeleteGame(ctx, gameID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrGameNotFound
+	}
+
+	var game *domain.Game
+	err := s.call(func() error {
+		var err error
+		game, err = s.store.GetGame(ctx, gameID)
+		return err
+	})
+	return game, err
+}
+
+// GetGameHead retrieves an active game by its ID with only its current
+// round. A game marked stale is evicted and reported as not found.
+func (s *GameSessionStore) GetGameHead(ctx context.Context, gameID string) (*domain.GameHead, error) {
+	if s.isStale(gameID) {
+		if err := s.DeleteGame(ctx, gameID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrGameNotFound
+	}
+
+	var head *domain.GameHead
+	err := s.call(func() error {
+		var err error
+		head, err = s.store.GetGameHead(ctx, gameID)
+		return err
+	})
+	return head, err
+}
+
+// GetGameHeads retrieves every active game with only its current round.
+// Games marked stale are evicted and left out.
+func (s *GameSessionStore) GetGameHeads(ctx context.Context) ([]*domain.GameHead, error) {
+	var games []*domain.GameHead
+	err := s.call(func() error {
+		var err error
+		games, err = s.store.GetGameHeads(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fresh := games[:0]
+	for _, game := range games {
+		if !s.isStale(game.ID) {
+			fresh = append(fresh, game)
+			continue
+		}
+		// A game that cannot be evicted stays marked until it can be
+		_ = s.DeleteGame(ctx, game.ID)
+	}
+	return fresh, nil
+}
+
+// DeleteGame removes an active game
+func (s *GameSessionStore) DeleteGame(ctx context.Context, gameID string) error {
+	if err := s.call(func() error { return s.store.DeleteGame(ctx, gameID) }); err != nil {
+		s.markStale(gameID, true)
+		return err
+	}
+	s.markStale(gameID, false)
+	return nil
+}
+
+// StorePlayerSession stores a player's session along with their token
+func (s *GameSessionStore) StorePlayerSession(ctx context.Context, gameID string, player *domain.Player, token string) error {
+	return s.call(func() error { return s.store.StorePlayerSession(ctx, gameID, player, token) })
+}
+
+// ResolvePlayerToken returns the ID of the player a token was issued to
+func (s *GameSessionStore) ResolvePlayerToken(ctx context.Context, gameID string, token string) (string, error) {
+	var playerID string
+	err := s.call(func() error {
+		var err error
+		playerID, err = s.store.ResolvePlayerToken(ctx, gameID, token)
+		return err
+	})
+	return playerID, err
+}
+
+// DeletePlayerSession removes a player's session and token
+func (s *GameSessionStore) DeletePlayerSession(ctx context.Context, gameID string, playerID string) error {
+	return s.call(func() error { return s.store.DeletePlayerSession(ctx, gameID, playerID) })
+}
+
+// StoreOverlayToken stores a game's overlay token
+func (s *GameSessionStore) StoreOverlayToken(ctx context.Context, gameID string, token string) error {
+	return s.call(func() error { return s.store.StoreOverlayToken(ctx, gameID, token) })
+}
+
+// ResolveOverlayToken returns the ID of the game an overlay token was issued for
+func (s *GameSessionStore) ResolveOverlayToken(ctx context.Context, token string) (string, error) {
+	var gameID string
+	err := s.call(func() error {
+		var err error
+		gameID, err = s.store.ResolveOverlayToken(ctx, token)
+		return err
+	})
+	return gameID, err
+}
+
+// StoreResumeSession stores what a WebSocket client is bound to
+func (s *GameSessionStore) StoreResumeSession(ctx context.Context, token string, session *domain.ResumeSession) error {
+	return s.call(func() error { return s.store.StoreResumeSession(ctx, token, session) })
+}
+
+// ResolveResumeToken returns what the client holding a resume token was bound to
+func (s *GameSessionStore) ResolveResumeToken(ctx context.Context, token string) (*domain.ResumeSession, error) {
+	var session *domain.ResumeSession
+	err := s.call(func() error {
+		var err error
+		session, err = s.store.ResolveResumeToken(ctx, token)
+		return err
+	})
+	return session, err
+}
+
+// RecordAsked records that players were asked a question
+func (s *GameSessionStore) RecordAsked(ctx context.Context, playerIDs []string, questionID string) error {
+	return s.call(func() error { return s.store.RecordAsked(ctx, playerIDs, questionID) })
+}
+
+// RecentlyAsked returns the IDs of the questions any of the players were
+// asked recently
+func (s *GameSessionStore) RecentlyAsked(ctx context.Context, playerIDs []string) ([]string, error) {
+	var questionIDs []string
+	err := s.call(func() error {
+		var err error
+		questionIDs, err = s.store.RecentlyAsked(ctx, playerIDs)
+		return err
+	})
+	return questionIDs, err
+}
+
+// call runs an operation through the breaker
+func (s *GameSessionStore) call(op func() error) error {
+	if err := s.breaker.Allow(); err != nil {
+		return err
+	}
+	err := op()
+	s.breaker.Record(failed(err))
+	return err
+}
+
+// markStale records whether a game's stored snapshot may be stale
+func (s *GameSessionStore) markStale(gameID string, stale bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stale {
+		s.stale[gameID] = true
+	} else {
+		delete(s.stale, gameID)
+	}
+}
+
+// isStale reports whether a game's stored snapshot may be stale
+func (s *GameSessionStore) isStale(gameID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stale[gameID]
+}
+
+// failed reports whether an error means the store is failing. Missing keys
+// and callers giving up say nothing about the store's health.
+func failed(err error) bool {
+	return err != nil &&
+		!errors.Is(err, domain.ErrGameNotFound) &&
+		!errors.Is(err, domain.ErrPlayerNotFound) &&
+		!errors.Is(err, domain.ErrInvalidPlayerToken) &&
+		!errors.Is(err, domain.ErrInvalidOverlayToken) &&
+		!errors.Is(err, domain.ErrInvalidResumeToken) &&
+		!errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// AccountSignalRepository implements the domain.AccountSignalRepository interface in memory
+type AccountSignalRepository struct {
+	mu      sync.RWMutex
+	signals []*domain.AccountSignal
+}
+
+// NewAccountSignalRepository creates a new in-memory account signal repository
+func NewAccountSignalRepository() *AccountSignalRepository {
+	return &AccountSignalRepository{}
+}
+
+// Create records a signal
+func (r *AccountSignalRepository) Create(ctx context.Context, signal *domain.AccountSignal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.signals {
+		if existing.ID == signal.ID {
+			return fmt.Errorf("account signal already exists: %s", signal.ID)
+		}
+	}
+	r.signals = append(r.signals, cloneSignal(signal))
+	return nil
+}
+
+// ListByUser retrieves a user's most recent signals, newest first
+func (r *AccountSignalRepository) ListByUser(ctx context.Context, userID string, limit int) ([]*domain.AccountSignal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	signals := []*domain.AccountSignal{}
+	for _, signal := range r.signals {
+		if signal.UserID == userID {
+			signals = append(signals, cloneSignal(signal))
+		}
+	}
+	slices.SortFunc(signals, func(a, b *domain.AccountSignal) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	if len(signals) > limit {
+		signals = signals[:limit]
+	}
+	return signals, nil
+}
+
+// CountAccountsByIP counts the accounts other than excludeUserID with
+// signals from an IP address since a time
+func (r *AccountSignalRepository) CountAccountsByIP(ctx context.Context, ip string, since time.Time, excludeUserID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := make(map[string]bool)
+	for _, signal := range r.signals {
+		if signal.IP == ip && !signal.CreatedAt.Before(since) && signal.UserID != excludeUserID {
+			accounts[signal.UserID] = true
+		}
+	}
+	return len(accounts), nil
+}
+
+// ListSharedIPs retrieves the IP addresses that at least minAccounts
+// accounts have signals from since a time, most accounts first
+func (r *AccountSignalRepository) ListSharedIPs(ctx context.Context, since time.Time, minAccounts int, limit int) ([]*domain.SharedIP, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byIP := make(map[string]*domain.SharedIP)
+	for _, signal := range r.signals {
+		if signal.CreatedAt.Before(since) {
+			continue
+		}
+		ip, ok := byIP[signal.IP]
+		if !ok {
+			ip = &domain.SharedIP{IP: signal.IP}
+			byIP[signal.IP] = ip
+		}
+		if !slices.Contains(ip.UserIDs, signal.UserID) {
+			ip.UserIDs = append(ip.UserIDs, signal.UserID)
+		}
+		if signal.Country > ip.Country {
+			ip.Country = signal.Country
+		}
+		if signal.CreatedAt.After(ip.LastSeenAt) {
+			ip.LastSeenAt = signal.CreatedAt
+		}
+	}
+
+	shared := []*domain.SharedIP{}
+	for _, ip := range byIP {
+		if len(ip.UserIDs) >= minAccounts {
+			slices.Sort(ip.UserIDs)
+			shared = append(shared, ip)
+		}
+	}
+	slices.SortFunc(shared, func(a, b *domain.SharedIP) int {
+		if c := len(b.UserIDs) - len(a.UserIDs); c != 0 {
+			return c
+		}
+		if c := b.LastSeenAt.Compare(a.LastSeenAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.IP, b.IP)
+	})
+	if len(shared) > limit {
+		shared = shared[:limit]
+	}
+	return shared, nil
+}
+
+// cloneSignal copies a signal, including its flags
+func cloneSignal(signal *domain.AccountSignal) *domain.AccountSignal {
+	clone := *signal
+	clone.Flags = slices.Clone(signal.Flags)
+	return &clone
+}
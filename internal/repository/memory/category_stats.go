@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// CategoryStatsRepository implements the domain.CategoryStatsRepository interface in memory
+type CategoryStatsRepository struct {
+	mu    sync.RWMutex
+	stats map[categoryStatsKey]*domain.CategoryStats
+}
+
+// categoryStatsKey identifies a user's stats for one category
+type categoryStatsKey struct {
+	userID   string
+	category string
+}
+
+// NewCategoryStatsRepository creates a new in-memory category stats repository
+func NewCategoryStatsRepository() *CategoryStatsRepository {
+	return &CategoryStatsRepository{
+		stats: make(map[categoryStatsKey]*domain.CategoryStats),
+	}
+}
+
+// add adds rounds' increments to the players' category totals
+func (r *CategoryStatsRepository) add(stats []*domain.CategoryStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range stats {
+		key := categoryStatsKey{s.UserID, s.Category}
+		stored, ok := r.stats[key]
+		if !ok {
+			stored = &domain.CategoryStats{UserID: s.UserID, Category: s.Category}
+			r.stats[key] = stored
+		}
+		stored.Rounds += s.Rounds
+		stored.VotesCast += s.VotesCast
+		stored.CorrectVotes += s.CorrectVotes
+		stored.PlayersFooled += s.PlayersFooled
+		stored.FooledByHouse += s.FooledByHouse
+		stored.OpponentVotes += s.OpponentVotes
+		stored.UpdatedAt = s.UpdatedAt
+		stored.ComputeRates()
+	}
+}
+
+// ListByUser retrieves a user's stats for every category, most played first
+func (r *CategoryStatsRepository) ListByUser(ctx context.Context, userID string) ([]*domain.CategoryStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := []*domain.CategoryStats{}
+	for key, s := range r.stats {
+		if key.userID == userID {
+			clone := *s
+			stats = append(stats, &clone)
+		}
+	}
+	slices.SortFunc(stats, func(a, b *domain.CategoryStats) int {
+		if a.Rounds != b.Rounds {
+			return b.Rounds - a.Rounds
+		}
+		return strings.Compare(a.Category, b.Category)
+	})
+	return stats, nil
+}
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// CosmeticRepository implements the domain.CosmeticRepository interface in memory
+type CosmeticRepository struct {
+	mu      sync.RWMutex
+	unlocks map[string]map[string]*domain.CosmeticUnlock // By user ID, then cosmetic
+}
+
+// NewCosmeticRepository creates a new in-memory cosmetic repository
+func NewCosmeticRepository() *CosmeticRepository {
+	return &CosmeticRepository{
+		unlocks: make(map[string]map[string]*domain.CosmeticUnlock),
+	}
+}
+
+// Unlock records cosmetics as unlocked by a user, keeping those already
+// unlocked as they are
+func (r *CosmeticRepository) Unlock(ctx context.Context, userID string, cosmetics []string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unlocked := r.unlocks[userID]
+	if unlocked == nil {
+		unlocked = make(map[string]*domain.CosmeticUnlock)
+		r.unlocks[userID] = unlocked
+	}
+	for _, cosmetic := range cosmetics {
+		if _, ok := unlocked[cosmetic]; !ok {
+			unlocked[cosmetic] = &domain.CosmeticUnlock{UserID: userID, Cosmetic: cosmetic, UnlockedAt: at}
+		}
+	}
+	return nil
+}
+
+// ListUnlocked retrieves the cosmetics a user has unlocked, ordered by
+// cosmetic
+func (r *CosmeticRepository) ListUnlocked(ctx context.Context, userID string) ([]*domain.CosmeticUnlock, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	unlocks := []*domain.CosmeticUnlock{}
+	for _, unlock := range r.unlocks[userID] {
+		clone := *unlock
+		unlocks = append(unlocks, &clone)
+	}
+	slices.SortFunc(unlocks, func(a, b *domain.CosmeticUnlock) int { return strings.Compare(a.Cosmetic, b.Cosmetic) })
+	return unlocks, nil
+}
+
+// Equip wears a cosmetic, taking off the others of its kind
+func (r *CosmeticRepository) Equip(ctx context.Context, userID string, cosmetic string, kindCosmetics []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unlocked := r.unlocks[userID]
+	if _, ok := unlocked[cosmetic]; cosmetic != "" && !ok {
+		return domain.ErrCosmeticLocked
+	}
+	for _, other := range kindCosmetics {
+		if unlock, ok := unlocked[other]; ok {
+			unlock.Equipped = other == cosmetic
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// EntitlementRepository implements the domain.EntitlementRepository interface in memory
+type EntitlementRepository struct {
+	mu           sync.RWMutex
+	packs        map[string]*domain.PremiumPack
+	entitlements map[string]map[string]*domain.Entitlement // By user ID, then pack ID
+	log          []*domain.EntitlementLogEntry
+}
+
+// NewEntitlementRepository creates a new in-memory entitlement repository
+func NewEntitlementRepository() *EntitlementRepository {
+	return &EntitlementRepository{
+		packs:        make(map[string]*domain.PremiumPack),
+		entitlements: make(map[string]map[string]*domain.Entitlement),
+	}
+}
+
+// SavePack creates or replaces a premium pack
+func (r *EntitlementRepository) SavePack(ctx context.Context, pack *domain.PremiumPack) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.packs[pack.ID]; ok {
+		pack.CreatedAt = existing.CreatedAt
+	}
+	r.packs[pack.ID] = clonePremiumPack(pack)
+	return nil
+}
+
+// GetPack retrieves a premium pack by ID
+func (r *EntitlementRepository) GetPack(ctx context.Context, id string) (*domain.PremiumPack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pack, ok := r.packs[id]
+	if !ok {
+		return nil, domain.ErrPremiumPackNotFound
+	}
+	return clonePremiumPack(pack), nil
+}
+
+// ListPacks retrieves every premium pack, ordered by ID
+func (r *EntitlementRepository) ListPacks(ctx context.Context) ([]*domain.PremiumPack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	packs := make([]*domain.PremiumPack, 0, len(r.packs))
+	for _, pack := range r.packs {
+		packs = append(packs, clonePremiumPack(pack))
+	}
+	slices.SortFunc(packs, func(a, b *domain.PremiumPack) int { return strings.Compare(a.ID, b.ID) })
+	return packs, nil
+}
+
+// Grant gives a user a premium pack
+func (r *EntitlementRepository) Grant(ctx context.Context, entitlement *domain.Entitlement, entry *domain.EntitlementLogEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entitlements[entitlement.UserID][entitlement.PackID]; ok {
+		return domain.ErrEntitlementExists
+	}
+	r.insert(entitlement)
+	r.appendLog(entry)
+	return nil
+}
+
+// Revoke takes a premium pack from a user
+func (r *EntitlementRepository) Revoke(ctx context.Context, userID string, packID string, entry *domain.EntitlementLogEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entitlements[userID][packID]; !ok {
+		return domain.ErrEntitlementNotFound
+	}
+	delete(r.entitlements[userID], packID)
+	r.appendLog(entry)
+	return nil
+}
+
+// Transfer moves a user's entitlement to the user of the new entitlement,
+// unless the user has gifted maxGifts packs since a time
+func (r *EntitlementRepository) Transfer(ctx context.Context, fromUserID string, entitlement *domain.Entitlement, entry *domain.EntitlementLogEntry, maxGifts int, since time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	gifted := 0
+	for _, logged := range r.log {
+		if logged.Action == domain.EntitlementActionGift && logged.FromUserID == fromUserID && !logged.CreatedAt.Before(since) {
+			gifted++
+		}
+	}
+	if gifted >= maxGifts {
+		return domain.ErrGiftLimitReached
+	}
+
+	if _, ok := r.entitlements[fromUserID][entitlement.PackID]; !ok {
+		return domain.ErrEntitlementNotFound
+	}
+	if _, ok := r.entitlements[entitlement.UserID][entitlement.PackID]; ok {
+		return domain.ErrEntitlementExists
+	}
+	delete(r.entitlements[fromUserID], entitlement.PackID)
+	r.insert(entitlement)
+	r.appendLog(entry)
+	return nil
+}
+
+// Get retrieves a user's entitlement to a premium pack
+func (r *EntitlementRepository) Get(ctx context.Context, userID string, packID string) (*domain.Entitlement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entitlement, ok := r.entitlements[userID][packID]
+	if !ok {
+		return nil, domain.ErrEntitlementNotFound
+	}
+	clone := *entitlement
+	return &clone, nil
+}
+
+// ListByUser retrieves the entitlements of a user, ordered by pack ID
+func (r *EntitlementRepository) ListByUser(ctx context.Context, userID string) ([]*domain.Entitlement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entitlements := []*domain.Entitlement{}
+	for _, entitlement := range r.entitlements[userID] {
+		clone := *entitlement
+		entitlements = append(entitlements, &clone)
+	}
+	slices.SortFunc(entitlements, func(a, b *domain.Entitlement) int { return strings.Compare(a.PackID, b.PackID) })
+	return entitlements, nil
+}
+
+// ListLog retrieves a user's most recent entitlement log entries, newest
+// first, including the packs they gifted
+func (r *EntitlementRepository) ListLog(ctx context.Context, userID string, limit int) ([]*domain.EntitlementLogEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := []*domain.EntitlementLogEntry{}
+	for _, entry := range r.log {
+		if entry.UserID == userID || entry.FromUserID == userID {
+			clone := *entry
+			entries = append(entries, &clone)
+		}
+	}
+	slices.SortFunc(entries, func(a, b *domain.EntitlementLogEntry) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// insert records a copy of an entitlement. The caller must hold the lock.
+func (r *EntitlementRepository) insert(entitlement *domain.Entitlement) {
+	owned := r.entitlements[entitlement.UserID]
+	if owned == nil {
+		owned = make(map[string]*domain.Entitlement)
+		r.entitlements[entitlement.UserID] = owned
+	}
+	clone := *entitlement
+	owned[entitlement.PackID] = &clone
+}
+
+// appendLog records a copy of an entitlement log entry. The caller must hold
+// the lock.
+func (r *EntitlementRepository) appendLog(entry *domain.EntitlementLogEntry) {
+	clone := *entry
+	r.log = append(r.log, &clone)
+}
+
+// clonePremiumPack copies a premium pack, including its categories
+func clonePremiumPack(pack *domain.PremiumPack) *domain.PremiumPack {
+	clone := *pack
+	clone.Categories = slices.Clone(pack.Categories)
+	return &clone
+}
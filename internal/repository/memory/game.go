@@ -0,0 +1,163 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+)
+
+// gameSortFields maps sortable fields to their values
+var gameSortFields = map[string]sortField[*domain.Game]{
+	"created_at": func(game *domain.Game) time.Time { return game.CreatedAt },
+	"updated_at": func(game *domain.Game) time.Time { return game.UpdatedAt },
+}
+
+// GameRepository implements the domain.GameRepository interface in memory
+type GameRepository struct {
+	mu     sync.RWMutex
+	games  map[string]*domain.Game // Keyed by ID
+	nextID int
+}
+
+// NewGameRepository creates a new in-memory game repository
+func NewGameRepository() *GameRepository {
+	return &GameRepository{
+		games: make(map[string]*domain.Game),
+	}
+}
+
+// Create creates a new game, assigning an ID if it has none
+func (r *GameRepository) Create(ctx context.Context, game *domain.Game) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.findByCode(game.Code) != nil {
+		return fmt.Errorf("%w: %s", domain.ErrGameCodeTaken, game.Code)
+	}
+
+	if game.ID == "" {
+		r.nextID++
+		game.ID = fmt.Sprintf("game-%d", r.nextID)
+	}
+	if _, ok := r.games[game.ID]; ok {
+		return fmt.Errorf("game already exists: %s", game.ID)
+	}
+	if game.CreatedAt.IsZero() {
+		game.CreatedAt = time.Now().UTC()
+	}
+	if game.UpdatedAt.IsZero() {
+		game.UpdatedAt = game.CreatedAt
+	}
+
+	stored, err := cloneGame(game)
+	if err != nil {
+		return err
+	}
+	r.games[game.ID] = stored
+	return nil
+}
+
+// GetByCode retrieves a game by its code
+func (r *GameRepository) GetByCode(ctx context.Context, code string) (*domain.Game, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	game := r.findByCode(code)
+	if game == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrGameNotFound, code)
+	}
+	return cloneGame(game)
+}
+
+// GetByID retrieves a game by its ID
+func (r *GameRepository) GetByID(ctx context.Context, id string) (*domain.Game, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	game, ok := r.games[id]
+	if !ok {
+		return nil, domain.ErrGameNotFound
+	}
+	return cloneGame(game)
+}
+
+// Update updates a game, matched by its code
+func (r *GameRepository) Update(ctx context.Context, game *domain.Game) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing := r.findByCode(game.Code)
+	if existing == nil {
+		return fmt.Errorf("%w: %s", domain.ErrGameNotFound, game.Code)
+	}
+
+	stored, err := cloneGame(game)
+	if err != nil {
+		return err
+	}
+	stored.ID = existing.ID
+	stored.CreatedAt = existing.CreatedAt
+	r.games[existing.ID] = stored
+	return nil
+}
+
+// Delete deletes a game by its code
+func (r *GameRepository) Delete(ctx context.Context, code string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if game := r.findByCode(code); game != nil {
+		delete(r.games, game.ID)
+	}
+	return nil
+}
+
+// List retrieves a page of games, optionally filtered by status
+func (r *GameRepository) List(ctx context.Context, params *pagination.Params) (pagination.Page[*domain.Game], error) {
+	r.mu.RLock()
+	status, filtered := params.Filter("status")
+	var games []*domain.Game
+	for _, game := range r.games {
+		if filtered && string(game.Status) != status {
+			continue
+		}
+		clone, err := cloneGame(game)
+		if err != nil {
+			r.mu.RUnlock()
+			return pagination.Page[*domain.Game]{}, err
+		}
+		games = append(games, clone)
+	}
+	r.mu.RUnlock()
+
+	return paginate(games, params, gameSortFields, func(game *domain.Game) string { return game.ID })
+}
+
+// findByCode returns the stored game with the given code. The caller must hold the lock.
+func (r *GameRepository) findByCode(code string) *domain.Game {
+	for _, game := range r.games {
+		if game.Code == code {
+			return game
+		}
+	}
+	return nil
+}
+
+// cloneGame deep-copies a game through JSON, as the Postgres repository
+// stores players and rounds
+func cloneGame(game *domain.Game) (*domain.Game, error) {
+	data, err := json.Marshal(game)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal game: %w", err)
+	}
+	var clone domain.Game
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal game: %w", err)
+	}
+	return &clone, nil
+}
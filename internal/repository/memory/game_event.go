@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// GameEventRepository implements the domain.GameEventRepository interface in memory
+type GameEventRepository struct {
+	mu      sync.RWMutex
+	streams map[string][]*domain.GameEvent // Keyed by game ID
+}
+
+// NewGameEventRepository creates a new in-memory game event repository
+func NewGameEventRepository() *GameEventRepository {
+	return &GameEventRepository{
+		streams: make(map[string][]*domain.GameEvent),
+	}
+}
+
+// Append stores a game's events atomically
+func (r *GameEventRepository) Append(ctx context.Context, events ...*domain.GameEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	gameID := events[0].GameID
+	stream := r.streams[gameID]
+	for i, event := range events {
+		if event.GameID != gameID {
+			return fmt.Errorf("cannot append events for multiple games at once")
+		}
+		if event.Sequence != len(stream)+i+1 {
+			return domain.ErrEventConflict
+		}
+	}
+
+	for _, event := range events {
+		stored := *event
+		stored.Payload = slices.Clone(event.Payload)
+		stream = append(stream, &stored)
+	}
+	r.streams[gameID] = stream
+	return nil
+}
+
+// ListByGame retrieves a game's events after the given sequence, in order
+func (r *GameEventRepository) ListByGame(ctx context.Context, gameID string, afterSequence int) ([]*domain.GameEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := []*domain.GameEvent{}
+	for _, event := range r.streams[gameID] {
+		if event.Sequence > afterSequence {
+			clone := *event
+			clone.Payload = slices.Clone(event.Payload)
+			events = append(events, &clone)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+)
+
+// inviteSortFields maps sortable fields to their values
+var inviteSortFields = map[string]sortField[*domain.GameInvite]{
+	"created_at": func(invite *domain.GameInvite) time.Time { return invite.CreatedAt },
+	"expires_at": func(invite *domain.GameInvite) time.Time { return invite.ExpiresAt },
+}
+
+// GameInviteRepository implements the domain.GameInviteRepository interface in memory
+type GameInviteRepository struct {
+	mu      sync.RWMutex
+	invites map[string]*domain.GameInvite
+}
+
+// NewGameInviteRepository creates a new in-memory game invite repository
+func NewGameInviteRepository() *GameInviteRepository {
+	return &GameInviteRepository{
+		invites: make(map[string]*domain.GameInvite),
+	}
+}
+
+// Create creates a new game invitation
+func (r *GameInviteRepository) Create(ctx context.Context, invite *domain.GameInvite) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.invites[invite.ID]; ok {
+		return errors.New("invitation already exists")
+	}
+	stored := *invite
+	r.invites[invite.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves a game invitation by ID
+func (r *GameInviteRepository) GetByID(ctx context.Context, id string) (*domain.GameInvite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	invite, ok := r.invites[id]
+	if !ok {
+		return nil, domain.ErrInviteNotFound
+	}
+	clone := *invite
+	return &clone, nil
+}
+
+// GetPendingInvites retrieves a page of a user's unexpired pending
+// invitations
+func (r *GameInviteRepository) GetPendingInvites(ctx context.Context, userID string, params *pagination.Params) (pagination.Page[*domain.GameInvite], error) {
+	r.mu.RLock()
+	now := time.Now()
+	var invites []*domain.GameInvite
+	for _, invite := range r.invites {
+		if invite.ToUser == userID && invite.Status == domain.InviteStatusPending && invite.ExpiresAt.After(now) {
+			clone := *invite
+			invites = append(invites, &clone)
+		}
+	}
+	r.mu.RUnlock()
+
+	return paginate(invites, params, inviteSortFields, func(invite *domain.GameInvite) string { return invite.ID })
+}
+
+// UpdateStatus updates the status of a game invitation
+func (r *GameInviteRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if invite, ok := r.invites[id]; ok {
+		invite.Status = status
+	}
+	return nil
+}
+
+// TransitionStatus updates the status of a game invitation only if it is
+// still from
+func (r *GameInviteRepository) TransitionStatus(ctx context.Context, id string, from string, to string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invite, ok := r.invites[id]
+	if !ok || invite.Status != from {
+		return domain.ErrInviteNotPending
+	}
+	invite.Status = to
+	return nil
+}
+
+// Renew makes a game invitation pending again with a new expiry
+func (r *GameInviteRepository) Renew(ctx context.Context, id string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invite, ok := r.invites[id]
+	if !ok {
+		return domain.ErrInviteNotFound
+	}
+	invite.Status = domain.InviteStatusPending
+	invite.ExpiresAt = expiresAt
+	return nil
+}
+
+// Delete deletes a game invitation
+func (r *GameInviteRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.invites, id)
+	return nil
+}
+
+// MarkExpired sets pending invitations that expired before now to expired
+func (r *GameInviteRepository) MarkExpired(ctx context.Context, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var n int64
+	for _, invite := range r.invites {
+		if invite.Status == domain.InviteStatusPending && invite.ExpiresAt.Before(now) {
+			invite.Status = domain.InviteStatusExpired
+			n++
+		}
+	}
+	return n, nil
+}
+
+// DeleteExpired deletes up to limit invitations that expired before the
+// cutoff, oldest first
+func (r *GameInviteRepository) DeleteExpired(ctx context.Context, before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired []*domain.GameInvite
+	for _, invite := range r.invites {
+		if invite.ExpiresAt.Before(before) {
+			expired = append(expired, invite)
+		}
+	}
+	slices.SortFunc(expired, func(a, b *domain.GameInvite) int {
+		return a.ExpiresAt.Compare(b.ExpiresAt)
+	})
+
+	expired = expired[:min(limit, len(expired))]
+	for _, invite := range expired {
+		delete(r.invites, invite.ID)
+	}
+	return int64(len(expired)), nil
+}
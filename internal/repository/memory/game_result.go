@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+)
+
+// gameHistorySortFields maps sortable fields to their values
+var gameHistorySortFields = map[string]sortField[*domain.GameHistoryEntry]{
+	"ended_at": func(entry *domain.GameHistoryEntry) time.Time { return entry.EndedAt },
+}
+
+// GameResultRepository implements the domain.GameResultRepository interface in memory
+type GameResultRepository struct {
+	mu      sync.RWMutex
+	results map[string]*domain.GameResult // Keyed by game ID
+}
+
+// NewGameResultRepository creates a new in-memory game result repository
+func NewGameResultRepository() *GameResultRepository {
+	return &GameResultRepository{
+		results: make(map[string]*domain.GameResult),
+	}
+}
+
+// save stores a copy of a game's result
+func (r *GameResultRepository) save(result *domain.GameResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.results[result.GameID]; ok {
+		return fmt.Errorf("game result already exists: %s", result.GameID)
+	}
+
+	stored, err := cloneGameResult(result)
+	if err != nil {
+		return err
+	}
+	r.results[result.GameID] = stored
+	return nil
+}
+
+// ListByUser retrieves a page of a user's past games
+func (r *GameResultRepository) ListByUser(ctx context.Context, userID string, filter domain.GameHistoryFilter, params *pagination.Params) (pagination.Page[*domain.GameHistoryEntry], error) {
+	r.mu.RLock()
+	var entries []*domain.GameHistoryEntry
+	for _, result := range r.results {
+		if !filter.Matches(result.EndedAt) {
+			continue
+		}
+		for _, player := range result.Players {
+			if player.PlayerID != userID {
+				continue
+			}
+			entry := &domain.GameHistoryEntry{
+				GameID:       result.GameID,
+				Code:         result.Code,
+				PlayerCount:  result.PlayerCount,
+				RoundsPlayed: result.RoundsPlayed,
+				EndedAt:      result.EndedAt,
+				Awards:       domain.AwardsOf(result.Awards, userID),
+				PlayerResult: player,
+			}
+			entry.Rounds = append([]domain.RoundPerformance{}, player.Rounds...)
+			entries = append(entries, entry)
+		}
+	}
+	r.mu.RUnlock()
+
+	return paginate(entries, params, gameHistorySortFields, func(entry *domain.GameHistoryEntry) string {
+		return entry.GameID
+	})
+}
+
+// ListEnded retrieves the results of games that ended within the period,
+// oldest first
+func (r *GameResultRepository) ListEnded(ctx context.Context, filter domain.GameHistoryFilter) ([]*domain.GameResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []*domain.GameResult
+	for _, result := range r.results {
+		if !filter.Matches(result.EndedAt) {
+			continue
+		}
+		clone, err := cloneGameResult(result)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, clone)
+	}
+	slices.SortFunc(results, func(a, b *domain.GameResult) int {
+		if c := a.EndedAt.Compare(b.EndedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.GameID, b.GameID)
+	})
+	return results, nil
+}
+
+// ListShared retrieves the results of games both users played, oldest first,
+// with only the two users' player results
+func (r *GameResultRepository) ListShared(ctx context.Context, userID string, opponentID string) ([]*domain.GameResult, error) {
+	results, err := r.ListEnded(ctx, domain.GameHistoryFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	shared := []*domain.GameResult{}
+	for _, result := range results {
+		result.Players = slices.DeleteFunc(result.Players, func(p domain.PlayerResult) bool {
+			return p.PlayerID != userID && p.PlayerID != opponentID
+		})
+		if len(result.Players) == 2 {
+			shared = append(shared, result)
+		}
+	}
+	return shared, nil
+}
+
+// GetCareerStats totals a user's results over every game they finished
+func (r *GameResultRepository) GetCareerStats(ctx context.Context, userID string) (*domain.CareerStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var career domain.CareerStats
+	for _, result := range r.results {
+		for _, player := range result.Players {
+			if player.PlayerID != userID {
+				continue
+			}
+			career.GamesPlayed++
+			if player.Position == 1 {
+				career.GamesWon++
+			}
+			career.TotalPoints += player.Points
+			career.PlayersFooled += player.PlayersFooled
+			career.CorrectVotes += player.CorrectVotes
+			career.LikesReceived += player.LikesReceived
+		}
+	}
+	return &career, nil
+}
+
+// GetShared retrieves the result of the game with the given code that a
+// share token was issued for
+func (r *GameResultRepository) GetShared(ctx context.Context, code string, shareToken string) (*domain.GameResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, result := range r.results {
+		if shareToken != "" && result.Code == code && result.ShareToken == shareToken {
+			return cloneGameResult(result)
+		}
+	}
+	return nil, domain.ErrResultNotFound
+}
+
+// cloneGameResult deep-copies a result through JSON
+func cloneGameResult(result *domain.GameResult) (*domain.GameResult, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal game result: %w", err)
+	}
+	var clone domain.GameResult
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal game result: %w", err)
+	}
+	return &clone, nil
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// GameTemplateRepository implements the domain.GameTemplateRepository interface in memory
+type GameTemplateRepository struct {
+	mu        sync.RWMutex
+	templates map[string]*domain.GameTemplate
+}
+
+// NewGameTemplateRepository creates a new in-memory game template repository
+func NewGameTemplateRepository() *GameTemplateRepository {
+	return &GameTemplateRepository{
+		templates: make(map[string]*domain.GameTemplate),
+	}
+}
+
+// Create creates a template
+func (r *GameTemplateRepository) Create(ctx context.Context, template *domain.GameTemplate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.templates[template.ID]; ok {
+		return fmt.Errorf("game template already exists: %s", template.ID)
+	}
+	r.templates[template.ID] = cloneTemplate(template)
+	return nil
+}
+
+// Get retrieves a template by ID
+func (r *GameTemplateRepository) Get(ctx context.Context, id string) (*domain.GameTemplate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	template, ok := r.templates[id]
+	if !ok {
+		return nil, domain.ErrTemplateNotFound
+	}
+	return cloneTemplate(template), nil
+}
+
+// ListVisible retrieves the global templates followed by the user's own,
+// each ordered by name
+func (r *GameTemplateRepository) ListVisible(ctx context.Context, userID string) ([]*domain.GameTemplate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	templates := []*domain.GameTemplate{}
+	for _, template := range r.templates {
+		if template.IsGlobal() || (userID != "" && template.OwnerID == userID) {
+			templates = append(templates, cloneTemplate(template))
+		}
+	}
+	slices.SortFunc(templates, func(a, b *domain.GameTemplate) int {
+		if c := strings.Compare(a.OwnerID, b.OwnerID); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return templates, nil
+}
+
+// Update replaces a template's name, description and settings
+func (r *GameTemplateRepository) Update(ctx context.Context, template *domain.GameTemplate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.templates[template.ID]
+	if !ok {
+		return domain.ErrTemplateNotFound
+	}
+	updated := cloneTemplate(existing)
+	updated.Name = template.Name
+	updated.Description = template.Description
+	updated.Settings = template.Settings
+	updated.Settings.SelectedCategories = slices.Clone(template.Settings.SelectedCategories)
+	updated.UpdatedAt = template.UpdatedAt
+	r.templates[template.ID] = updated
+	return nil
+}
+
+// Delete removes a template
+func (r *GameTemplateRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.templates[id]; !ok {
+		return domain.ErrTemplateNotFound
+	}
+	delete(r.templates, id)
+	return nil
+}
+
+// cloneTemplate copies a template, including its category list
+func cloneTemplate(template *domain.GameTemplate) *domain.GameTemplate {
+	clone := *template
+	clone.Settings.SelectedCategories = slices.Clone(template.Settings.SelectedCategories)
+	return &clone
+}
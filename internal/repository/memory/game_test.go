@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+func TestGameRepositoryCodes(t *testing.T) {
+	ctx := context.Background()
+	repo := NewGameRepository()
+
+	game := &domain.Game{Code: "ABCD", Status: domain.GameStatusWaiting}
+	if err := repo.Create(ctx, game); err != nil {
+		t.Fatal(err)
+	}
+	if game.ID == "" || game.CreatedAt.IsZero() {
+		t.Errorf("created game = %+v, want an ID and creation time", game)
+	}
+	if err := repo.Create(ctx, &domain.Game{Code: "ABCD"}); !errors.Is(err, domain.ErrGameCodeTaken) {
+		t.Errorf("reusing a code: error = %v, want ErrGameCodeTaken", err)
+	}
+	if _, err := repo.GetByCode(ctx, "WXYZ"); !errors.Is(err, domain.ErrGameNotFound) {
+		t.Errorf("getting an unknown code: error = %v, want ErrGameNotFound", err)
+	}
+	if err := repo.Update(ctx, &domain.Game{Code: "WXYZ"}); !errors.Is(err, domain.ErrGameNotFound) {
+		t.Errorf("updating an unknown code: error = %v, want ErrGameNotFound", err)
+	}
+}
+
+func TestGameRepositoryStoresCopies(t *testing.T) {
+	ctx := context.Background()
+	repo := NewGameRepository()
+
+	game := &domain.Game{Code: "ABCD", Players: []domain.Player{{ID: "alice", Score: 1}}}
+	if err := repo.Create(ctx, game); err != nil {
+		t.Fatal(err)
+	}
+	game.Players[0].Score = 100
+
+	stored, err := repo.GetByID(ctx, game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Players[0].Score != 1 {
+		t.Errorf("stored score = %d after changing the created game, want 1", stored.Players[0].Score)
+	}
+	stored.Players[0].Score = 50
+
+	again, err := repo.GetByCode(ctx, "ABCD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Players[0].Score != 1 {
+		t.Errorf("stored score = %d after changing a read game, want 1", again.Players[0].Score)
+	}
+}
+
+func TestGameEventRepositoryConflicts(t *testing.T) {
+	ctx := context.Background()
+	repo := NewGameEventRepository()
+
+	first := []*domain.GameEvent{
+		{GameID: "game-1", Sequence: 1, Type: domain.EventGameCreated},
+		{GameID: "game-1", Sequence: 2, Type: domain.EventPlayerJoined},
+	}
+	if err := repo.Append(ctx, first...); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Append(ctx, &domain.GameEvent{GameID: "game-1", Sequence: 2, Type: domain.EventPlayerJoined}); !errors.Is(err, domain.ErrEventConflict) {
+		t.Errorf("appending a taken sequence: error = %v, want ErrEventConflict", err)
+	}
+	if err := repo.Append(ctx, &domain.GameEvent{GameID: "game-1", Sequence: 4, Type: domain.EventPlayerJoined}); !errors.Is(err, domain.ErrEventConflict) {
+		t.Errorf("appending past a gap: error = %v, want ErrEventConflict", err)
+	}
+
+	events, err := repo.ListByGame(ctx, "game-1", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Sequence != 2 {
+		t.Errorf("events after 1 = %v, want only the second", events)
+	}
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+)
+
+// hallOfFameSortFields maps sortable fields to their values
+var hallOfFameSortFields = map[string]sortField[*domain.HallOfFameEntry]{
+	"created_at": func(entry *domain.HallOfFameEntry) time.Time { return entry.CreatedAt },
+}
+
+// HallOfFameRepository implements the domain.HallOfFameRepository interface in memory
+type HallOfFameRepository struct {
+	mu      sync.RWMutex
+	entries []*domain.HallOfFameEntry
+}
+
+// NewHallOfFameRepository creates a new in-memory hall of fame repository
+func NewHallOfFameRepository() *HallOfFameRepository {
+	return &HallOfFameRepository{}
+}
+
+// add stores copies of entries liked into the hall of fame
+func (r *HallOfFameRepository) add(entries []*domain.HallOfFameEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range entries {
+		stored := *entry
+		r.entries = append(r.entries, &stored)
+	}
+}
+
+// List retrieves a page of the entries whose authors consent to them being shown
+func (r *HallOfFameRepository) List(ctx context.Context, params *pagination.Params) (pagination.Page[*domain.HallOfFameEntry], error) {
+	r.mu.RLock()
+	var entries []*domain.HallOfFameEntry
+	for _, entry := range r.entries {
+		if entry.Consent {
+			clone := *entry
+			entries = append(entries, &clone)
+		}
+	}
+	r.mu.RUnlock()
+
+	return paginate(entries, params, hallOfFameSortFields, func(entry *domain.HallOfFameEntry) string {
+		return entry.ID
+	})
+}
+
+// SetConsent records whether a user lets their entries be shown
+func (r *HallOfFameRepository) SetConsent(ctx context.Context, userID string, consent bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range r.entries {
+		if entry.AuthorID == userID {
+			entry.Consent = consent
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Package memory provides in-memory implementations of the domain
+// repositories, for service tests and simulations that run without Postgres.
+// Every repository is safe for concurrent use and stores copies, so callers
+// cannot mutate stored entities without going through the repository.
+//
+// The repositories behave as the Postgres ones do. In particular, questions
+// are created unreleased and games only draw released questions, so tests
+// seed the questions games play with through QuestionRepository.SeedQuestions.
+package memory
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+)
+
+// sortField returns the timestamp an entity is sorted by
+type sortField[T any] func(item T) time.Time
+
+// paginate sorts items by the requested field, with the ID as a tie-breaker,
+// and returns the page following the cursor, matching the keyset pagination
+// of the Postgres repositories
+func paginate[T any](items []T, params *pagination.Params, fields map[string]sortField[T], idOf func(T) string) (pagination.Page[T], error) {
+	valueOf, ok := fields[params.Sort]
+	if !ok {
+		return pagination.Page[T]{}, fmt.Errorf("%w: %s", pagination.ErrInvalidSort, params.Sort)
+	}
+
+	compare := func(a, b T) int {
+		if c := valueOf(a).Compare(valueOf(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(idOf(a), idOf(b))
+	}
+	if params.Order != pagination.OrderAsc {
+		ascending := compare
+		compare = func(a, b T) int { return ascending(b, a) }
+	}
+	slices.SortFunc(items, compare)
+
+	if params.Cursor != nil {
+		value, err := time.Parse(time.RFC3339Nano, params.Cursor.Value)
+		if err != nil {
+			return pagination.Page[T]{}, pagination.ErrInvalidCursor
+		}
+		// Skip everything up to and including the cursor position
+		start := len(items)
+		for i, item := range items {
+			c := valueOf(item).Compare(value)
+			if c == 0 {
+				c = strings.Compare(idOf(item), params.Cursor.ID)
+			}
+			if params.Order != pagination.OrderAsc {
+				c = -c
+			}
+			if c > 0 {
+				start = i
+				break
+			}
+		}
+		items = items[start:]
+	}
+
+	items = items[:min(len(items), params.FetchLimit())]
+	return pagination.NewPage(items, params, func(item T) pagination.Cursor {
+		return pagination.Cursor{Value: valueOf(item).Format(time.RFC3339Nano), ID: idOf(item)}
+	}), nil
+}
+
+// Compile-time checks that the repositories satisfy the domain interfaces
+var (
+	_ domain.GameRepository          = (*GameRepository)(nil)
+	_ domain.QuestionRepository      = (*QuestionRepository)(nil)
+	_ domain.UserRepository          = (*UserRepository)(nil)
+	_ domain.GameInviteRepository    = (*GameInviteRepository)(nil)
+	_ domain.GameEventRepository     = (*GameEventRepository)(nil)
+	_ domain.GameChangeStore         = (*GameChangeStore)(nil)
+	_ domain.OutboxRepository        = (*OutboxRepository)(nil)
+	_ domain.GameResultRepository    = (*GameResultRepository)(nil)
+	_ domain.StatsRollupRepository   = (*StatsRollupRepository)(nil)
+	_ domain.CategoryStatsRepository = (*CategoryStatsRepository)(nil)
+	_ domain.GameSessionStore        = (*GameSessionStore)(nil)
+)
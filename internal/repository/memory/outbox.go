@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// GameChangeStore implements the domain.GameChangeStore interface over the
+// in-memory repositories. Changes are serialized, and events are appended
+// before the snapshot is saved, so a conflicting change leaves no trace.
+type GameChangeStore struct {
+	mu         sync.Mutex
+	games      *GameRepository
+	events     *GameEventRepository
+	outbox     *OutboxRepository
+	results    *GameResultRepository
+	categories *CategoryStatsRepository
+	parties    *PartyRepository
+	hallOfFame *HallOfFameRepository
+}
+
+// NewGameChangeStore creates a new in-memory game change store
+func NewGameChangeStore(games *GameRepository, events *GameEventRepository, outbox *OutboxRepository, results *GameResultRepository, categories *CategoryStatsRepository, parties *PartyRepository, hallOfFame *HallOfFameRepository) *GameChangeStore {
+	return &GameChangeStore{
+		games:      games,
+		events:     events,
+		outbox:     outbox,
+		results:    results,
+		categories: categories,
+		parties:    parties,
+		hallOfFame: hallOfFame,
+	}
+}
+
+// SaveChange writes a game's snapshot, events and outbox messages
+func (s *GameChangeStore) SaveChange(ctx context.Context, change *domain.GameChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if change.Created {
+		if err := s.games.Create(ctx, change.Game); err != nil {
+			return err
+		}
+		change.AssignGameID(change.Game.ID)
+		if err := s.events.Append(ctx, change.Events...); err != nil {
+			return err
+		}
+	} else {
+		if err := s.events.Append(ctx, change.Events...); err != nil {
+			return err
+		}
+		if err := s.games.Update(ctx, change.Game); err != nil {
+			return err
+		}
+	}
+
+	if change.Result != nil {
+		if err := s.results.save(change.Result); err != nil {
+			return err
+		}
+	}
+
+	s.categories.add(change.CategoryStats)
+	s.hallOfFame.add(change.HallOfFame)
+
+	if change.Party != nil {
+		if err := s.parties.save(change.Party); err != nil {
+			return err
+		}
+	}
+
+	if err := change.EncodeSnapshots(); err != nil {
+		return err
+	}
+	s.outbox.enqueue(change.Messages)
+	return nil
+}
+
+// OutboxRepository implements the domain.OutboxRepository interface in memory
+type OutboxRepository struct {
+	mu       sync.Mutex
+	messages []*domain.OutboxMessage
+	nextID   int64
+
+	// relayMu is held while messages are published, like the row locks of
+	// the Postgres outbox
+	relayMu sync.Mutex
+}
+
+// NewOutboxRepository creates a new in-memory outbox repository
+func NewOutboxRepository() *OutboxRepository {
+	return &OutboxRepository{}
+}
+
+// enqueue stores copies of messages, assigning their IDs
+func (r *OutboxRepository) enqueue(messages []*domain.OutboxMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, msg := range messages {
+		r.nextID++
+		msg.ID = r.nextID
+		stored := *msg
+		stored.Payload = slices.Clone(msg.Payload)
+		r.messages = append(r.messages, &stored)
+	}
+}
+
+// ProcessPending publishes the oldest queued messages, removing them if publish succeeds
+func (r *OutboxRepository) ProcessPending(ctx context.Context, limit int, publish func(messages []*domain.OutboxMessage) error) (int, error) {
+	r.relayMu.Lock()
+	defer r.relayMu.Unlock()
+
+	r.mu.Lock()
+	batch := make([]*domain.OutboxMessage, 0, min(limit, len(r.messages)))
+	for _, msg := range r.messages[:min(limit, len(r.messages))] {
+		clone := *msg
+		clone.Payload = slices.Clone(msg.Payload)
+		batch = append(batch, &clone)
+	}
+	r.mu.Unlock()
+
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	if err := publish(batch); err != nil {
+		return 0, err
+	}
+
+	// Only the relay removes messages, so the batch is still at the front
+	r.mu.Lock()
+	r.messages = slices.Delete(r.messages, 0, len(batch))
+	r.mu.Unlock()
+
+	return len(batch), nil
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// PartyRepository implements the domain.PartyRepository interface in memory
+type PartyRepository struct {
+	mu      sync.RWMutex
+	parties map[string]*domain.PartyScoreboard
+}
+
+// NewPartyRepository creates a new in-memory party repository
+func NewPartyRepository() *PartyRepository {
+	return &PartyRepository{
+		parties: make(map[string]*domain.PartyScoreboard),
+	}
+}
+
+// Create creates a party with an empty scoreboard
+func (r *PartyRepository) Create(ctx context.Context, party *domain.Party) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.parties[party.ID]; ok {
+		return fmt.Errorf("party already exists: %s", party.ID)
+	}
+	r.parties[party.ID] = &domain.PartyScoreboard{
+		Party:     *party,
+		Standings: []domain.PartyStanding{},
+		UpdatedAt: party.CreatedAt,
+	}
+	return nil
+}
+
+// Get retrieves a party's scoreboard
+func (r *PartyRepository) Get(ctx context.Context, id string) (*domain.PartyScoreboard, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	board, ok := r.parties[id]
+	if !ok {
+		return nil, domain.ErrPartyNotFound
+	}
+	return cloneScoreboard(board), nil
+}
+
+// save replaces a party's scoreboard with a copy of the given one
+func (r *PartyRepository) save(board *domain.PartyScoreboard) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.parties[board.ID]; !ok {
+		return domain.ErrPartyNotFound
+	}
+	r.parties[board.ID] = cloneScoreboard(board)
+	return nil
+}
+
+// cloneScoreboard copies a scoreboard so callers cannot modify stored standings
+func cloneScoreboard(board *domain.PartyScoreboard) *domain.PartyScoreboard {
+	clone := *board
+	clone.Standings = slices.Clone(board.Standings)
+	return &clone
+}
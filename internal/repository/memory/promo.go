@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// PromoRepository implements the domain.PromoRepository interface in memory
+type PromoRepository struct {
+	mu          sync.RWMutex
+	batches     map[string]*domain.PromoBatch
+	codes       map[string]*domain.PromoCode
+	redemptions []*domain.PromoRedemption
+}
+
+// NewPromoRepository creates a new in-memory promo repository
+func NewPromoRepository() *PromoRepository {
+	return &PromoRepository{
+		batches: make(map[string]*domain.PromoBatch),
+		codes:   make(map[string]*domain.PromoCode),
+	}
+}
+
+// CreateBatch creates a batch with its codes
+func (r *PromoRepository) CreateBatch(ctx context.Context, batch *domain.PromoBatch, codes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, code := range codes {
+		if _, ok := r.codes[code]; ok || slices.Contains(codes[:i], code) {
+			return domain.ErrPromoCodeExists
+		}
+	}
+	r.batches[batch.ID] = clonePromoBatch(batch)
+	for _, code := range codes {
+		r.codes[code] = &domain.PromoCode{Code: code, BatchID: batch.ID}
+	}
+	return nil
+}
+
+// GetBatch retrieves a batch by ID
+func (r *PromoRepository) GetBatch(ctx context.Context, id string) (*domain.PromoBatch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	batch, ok := r.batches[id]
+	if !ok {
+		return nil, domain.ErrPromoBatchNotFound
+	}
+	return clonePromoBatch(batch), nil
+}
+
+// ListBatches retrieves every batch, newest first
+func (r *PromoRepository) ListBatches(ctx context.Context) ([]*domain.PromoBatch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	batches := make([]*domain.PromoBatch, 0, len(r.batches))
+	for _, batch := range r.batches {
+		batches = append(batches, clonePromoBatch(batch))
+	}
+	slices.SortFunc(batches, func(a, b *domain.PromoBatch) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return batches, nil
+}
+
+// GetCode retrieves a promo code
+func (r *PromoRepository) GetCode(ctx context.Context, code string) (*domain.PromoCode, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	promo, ok := r.codes[code]
+	if !ok {
+		return nil, domain.ErrPromoCodeNotFound
+	}
+	clone := *promo
+	return &clone, nil
+}
+
+// ListCodes retrieves a batch's codes
+func (r *PromoRepository) ListCodes(ctx context.Context, batchID string) ([]*domain.PromoCode, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	codes := []*domain.PromoCode{}
+	for _, promo := range r.codes {
+		if promo.BatchID == batchID {
+			clone := *promo
+			codes = append(codes, &clone)
+		}
+	}
+	slices.SortFunc(codes, func(a, b *domain.PromoCode) int { return strings.Compare(a.Code, b.Code) })
+	return codes, nil
+}
+
+// Redeem records a redemption and counts it against its code
+func (r *PromoRepository) Redeem(ctx context.Context, redemption *domain.PromoRedemption, maxRedemptions int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if slices.ContainsFunc(r.redemptions, func(existing *domain.PromoRedemption) bool {
+		return existing.BatchID == redemption.BatchID && existing.UserID == redemption.UserID
+	}) {
+		return domain.ErrPromoAlreadyRedeemed
+	}
+	promo, ok := r.codes[redemption.Code]
+	if !ok || promo.Redemptions >= maxRedemptions {
+		return domain.ErrPromoCodeExhausted
+	}
+
+	promo.Redemptions++
+	clone := *redemption
+	r.redemptions = append(r.redemptions, &clone)
+	return nil
+}
+
+// CancelRedemption removes a redemption and hands its use of the code back
+func (r *PromoRepository) CancelRedemption(ctx context.Context, redemption *domain.PromoRedemption) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.IndexFunc(r.redemptions, func(existing *domain.PromoRedemption) bool {
+		return existing.BatchID == redemption.BatchID && existing.UserID == redemption.UserID
+	})
+	if i < 0 {
+		return nil
+	}
+	r.redemptions = slices.Delete(r.redemptions, i, i+1)
+	if promo, ok := r.codes[redemption.Code]; ok && promo.Redemptions > 0 {
+		promo.Redemptions--
+	}
+	return nil
+}
+
+// ListRedemptionsByUser retrieves a user's redemptions, newest first
+func (r *PromoRepository) ListRedemptionsByUser(ctx context.Context, userID string) ([]*domain.PromoRedemption, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	redemptions := []*domain.PromoRedemption{}
+	for _, redemption := range r.redemptions {
+		if redemption.UserID == userID {
+			clone := *redemption
+			redemptions = append(redemptions, &clone)
+		}
+	}
+	slices.SortFunc(redemptions, func(a, b *domain.PromoRedemption) int {
+		if c := b.RedeemedAt.Compare(a.RedeemedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.BatchID, b.BatchID)
+	})
+	return redemptions, nil
+}
+
+// clonePromoBatch copies a promo batch, including its expiry
+func clonePromoBatch(batch *domain.PromoBatch) *domain.PromoBatch {
+	clone := *batch
+	if batch.ExpiresAt != nil {
+		expiresAt := *batch.ExpiresAt
+		clone.ExpiresAt = &expiresAt
+	}
+	return &clone
+}
@@ -0,0 +1,451 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+	"github.com/zizouhuweidi/dahaa/internal/random"
+)
+
+// defaultDifficulty is applied to questions created without a difficulty
+const defaultDifficulty = "medium"
+
+// questionSortFields maps sortable fields to their values
+var questionSortFields = map[string]sortField[*domain.Question]{
+	"created_at": func(q *domain.Question) time.Time { return q.CreatedAt },
+	"updated_at": func(q *domain.Question) time.Time { return q.UpdatedAt },
+}
+
+// QuestionRepository implements the domain.QuestionRepository interface in memory
+type QuestionRepository struct {
+	rng random.Source
+
+	mu        sync.RWMutex
+	questions map[string]*domain.Question
+	releases  []*domain.PackRelease
+	nextID    int
+}
+
+// NewQuestionRepository creates a new in-memory question repository. Random
+// questions are drawn from rng, so a seeded source makes draws reproducible.
+func NewQuestionRepository(rng random.Source) *QuestionRepository {
+	return &QuestionRepository{
+		rng:       rng,
+		questions: make(map[string]*domain.Question),
+	}
+}
+
+// GetRandomQuestion retrieves a random question from a category in a
+// language, released in packVersion or earlier, rated no edgier than
+// maxRating and other than the excluded questions
+func (r *QuestionRepository) GetRandomQuestion(ctx context.Context, category string, language string, packVersion int, maxRating domain.ContentRating, exclude []string) (*domain.Question, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var candidates []*domain.Question
+	for _, q := range r.questions {
+		if q.DeletedAt == nil && q.Category == category && q.Language == language && released(q, packVersion) && maxRating.Allows(q.Rating) && !slices.Contains(exclude, q.ID) {
+			candidates = append(candidates, q)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: no %s questions for category %s", domain.ErrQuestionNotFound, language, category)
+	}
+
+	// Map iteration order is random, so sort before drawing
+	slices.SortFunc(candidates, compareQuestionIDs)
+	return cloneQuestion(candidates[r.rng.Intn(len(candidates))]), nil
+}
+
+// GetCategories retrieves all categories with released questions
+func (r *QuestionRepository) GetCategories(ctx context.Context) ([]string, error) {
+	counts, err := r.GetCategoryCounts(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]string, 0, len(counts))
+	for _, c := range counts {
+		categories = append(categories, c.Category)
+	}
+	return categories, nil
+}
+
+// GetCategoryCounts retrieves all categories with their counts of released
+// questions rated no edgier than maxRating, once per language the category
+// has such questions in
+func (r *QuestionRepository) GetCategoryCounts(ctx context.Context, maxRating domain.ContentRating) ([]domain.CategoryCount, error) {
+	r.mu.RLock()
+	byCategory := make(map[[2]string]int)
+	for _, q := range r.questions {
+		if q.DeletedAt != nil || !released(q, 0) || !maxRating.Allows(q.Rating) {
+			continue
+		}
+		byCategory[[2]string{q.Category, q.Language}]++
+	}
+	r.mu.RUnlock()
+
+	counts := make([]domain.CategoryCount, 0, len(byCategory))
+	for key, count := range byCategory {
+		counts = append(counts, domain.CategoryCount{Category: key[0], Language: key[1], Count: count})
+	}
+	slices.SortFunc(counts, func(a, b domain.CategoryCount) int {
+		if c := strings.Compare(a.Category, b.Category); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Language, b.Language)
+	})
+	return counts, nil
+}
+
+// GetDifficulties retrieves all available difficulty levels
+func (r *QuestionRepository) GetDifficulties(ctx context.Context) ([]string, error) {
+	r.mu.RLock()
+	seen := make(map[string]bool)
+	var difficulties []string
+	for _, q := range r.questions {
+		if q.DeletedAt == nil && !seen[q.Difficulty] {
+			seen[q.Difficulty] = true
+			difficulties = append(difficulties, q.Difficulty)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.Sort(difficulties)
+	return difficulties, nil
+}
+
+// GetByID retrieves a question by its ID, even if it was deleted
+func (r *QuestionRepository) GetByID(ctx context.Context, id string) (*domain.Question, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	q, ok := r.questions[id]
+	if !ok {
+		return nil, domain.ErrQuestionNotFound
+	}
+	return cloneQuestion(q), nil
+}
+
+// CreateQuestion creates a new question. As in Postgres, it is unreleased,
+// with a PackVersion of 0, until its language's pack is next published, so
+// games cannot draw it before then. SeedQuestions creates released questions.
+func (r *QuestionRepository) CreateQuestion(ctx context.Context, question *domain.Question) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.create(question)
+	return nil
+}
+
+// UpdateQuestion updates an existing question
+func (r *QuestionRepository) UpdateQuestion(ctx context.Context, question *domain.Question) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.questions[question.ID]
+	if !ok {
+		return domain.ErrQuestionNotFound
+	}
+
+	if question.Difficulty == "" {
+		question.Difficulty = existing.Difficulty
+	}
+	if question.Language == "" {
+		question.Language = existing.Language
+	}
+	if question.Rating == "" {
+		question.Rating = existing.Rating
+	}
+	question.PackVersion = existing.PackVersion
+	question.CreatedAt = existing.CreatedAt
+	question.UpdatedAt = time.Now().UTC()
+	question.DeletedAt = existing.DeletedAt
+	r.questions[question.ID] = cloneQuestion(question)
+	return nil
+}
+
+// DeleteQuestion soft-deletes a question
+func (r *QuestionRepository) DeleteQuestion(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	q, ok := r.questions[id]
+	if !ok || q.DeletedAt != nil {
+		return domain.ErrQuestionNotFound
+	}
+	now := time.Now().UTC()
+	q.DeletedAt = &now
+	q.UpdatedAt = now
+	return nil
+}
+
+// RestoreQuestion restores a deleted question
+func (r *QuestionRepository) RestoreQuestion(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	q, ok := r.questions[id]
+	if !ok || q.DeletedAt == nil {
+		return domain.ErrQuestionNotFound
+	}
+	q.DeletedAt = nil
+	q.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// BulkCreateQuestions creates multiple questions atomically, unreleased as
+// CreateQuestion leaves them
+func (r *QuestionRepository) BulkCreateQuestions(ctx context.Context, questions []*domain.Question) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, q := range questions {
+		r.create(q)
+	}
+	return nil
+}
+
+// RenameCategory moves every question in a category to another, merging the
+// two if merge is set. Deleted questions move too but are not counted.
+func (r *QuestionRepository) RenameCategory(ctx context.Context, from string, to string, merge bool) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var moving []*domain.Question
+	live := 0
+	targetExists := false
+	for _, q := range r.questions {
+		switch {
+		case q.Category == from:
+			moving = append(moving, q)
+			if q.DeletedAt == nil {
+				live++
+			}
+		case q.Category == to && q.DeletedAt == nil:
+			targetExists = true
+		}
+	}
+	if live == 0 {
+		return 0, domain.ErrCategoryNotFound
+	}
+	if targetExists && !merge {
+		return 0, domain.ErrCategoryExists
+	}
+
+	now := time.Now().UTC()
+	for _, q := range moving {
+		q.Category = to
+		q.UpdatedAt = now
+	}
+	return live, nil
+}
+
+// ValidateQuestion validates a question's data
+func (r *QuestionRepository) ValidateQuestion(ctx context.Context, question *domain.Question) error {
+	if question.Text == "" {
+		return fmt.Errorf("question text cannot be empty")
+	}
+	if question.Answer == "" {
+		return fmt.Errorf("question answer cannot be empty")
+	}
+	if question.Category == "" {
+		return fmt.Errorf("question category cannot be empty")
+	}
+	if len(question.Category) < 3 {
+		return fmt.Errorf("category must be at least 3 characters long")
+	}
+	if len(question.Category) > 50 {
+		return fmt.Errorf("category cannot be longer than 50 characters")
+	}
+	return nil
+}
+
+// List retrieves a page of questions, optionally filtered by category, difficulty, language and rating
+func (r *QuestionRepository) List(ctx context.Context, params *pagination.Params) (pagination.Page[*domain.Question], error) {
+	category, byCategory := params.Filter("category")
+	difficulty, byDifficulty := params.Filter("difficulty")
+	language, byLanguage := params.Filter("language")
+	rating, byRating := params.Filter("rating")
+
+	r.mu.RLock()
+	var questions []*domain.Question
+	for _, q := range r.questions {
+		if q.DeletedAt != nil {
+			continue
+		}
+		if byCategory && q.Category != category {
+			continue
+		}
+		if byDifficulty && q.Difficulty != difficulty {
+			continue
+		}
+		if byLanguage && q.Language != language {
+			continue
+		}
+		if byRating && string(q.Rating) != rating {
+			continue
+		}
+		questions = append(questions, cloneQuestion(q))
+	}
+	r.mu.RUnlock()
+
+	return paginate(questions, params, questionSortFields, func(q *domain.Question) string { return q.ID })
+}
+
+// LatestPackVersion returns the latest released version of a language's pack
+func (r *QuestionRepository) LatestPackVersion(ctx context.Context, language string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.latestPackVersion(language), nil
+}
+
+// PublishPack releases a language's unreleased live questions as the next
+// version of its pack
+func (r *QuestionRepository) PublishPack(ctx context.Context, language string, notes string, at time.Time) (*domain.PackRelease, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.publish(language, notes, at)
+}
+
+// SeedQuestions creates questions and releases them at once, publishing the
+// packs of their languages, for tests and simulations that need questions
+// games can draw. The questions are given their IDs and pack versions.
+func (r *QuestionRepository) SeedQuestions(ctx context.Context, questions ...*domain.Question) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var languages []string
+	for _, q := range questions {
+		r.create(q)
+		if !slices.Contains(languages, q.Language) {
+			languages = append(languages, q.Language)
+		}
+	}
+	now := time.Now().UTC()
+	for _, language := range languages {
+		if _, err := r.publish(language, "", now); err != nil {
+			return err
+		}
+	}
+	for _, q := range questions {
+		q.PackVersion = r.questions[q.ID].PackVersion
+	}
+	return nil
+}
+
+// ListPackReleases retrieves the releases, newest first, of one language's
+// pack or of every pack when language is empty
+func (r *QuestionRepository) ListPackReleases(ctx context.Context, language string) ([]*domain.PackRelease, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	releases := []*domain.PackRelease{}
+	for _, release := range r.releases {
+		if language == "" || release.Language == language {
+			clone := *release
+			releases = append(releases, &clone)
+		}
+	}
+	slices.SortFunc(releases, func(a, b *domain.PackRelease) int {
+		if c := b.PublishedAt.Compare(a.PublishedAt); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Language, b.Language); c != 0 {
+			return c
+		}
+		return b.Version - a.Version
+	})
+	return releases, nil
+}
+
+// publish releases a language's unreleased live questions as the next
+// version of its pack. The caller must hold the lock.
+func (r *QuestionRepository) publish(language string, notes string, at time.Time) (*domain.PackRelease, error) {
+	release := &domain.PackRelease{
+		Language:    language,
+		Version:     r.latestPackVersion(language) + 1,
+		Notes:       notes,
+		PublishedAt: at,
+	}
+	for _, q := range r.questions {
+		if q.Language == language && q.PackVersion == 0 && q.DeletedAt == nil {
+			q.PackVersion = release.Version
+			release.Questions++
+		}
+	}
+	if release.Questions == 0 {
+		return nil, domain.ErrNothingToRelease
+	}
+
+	clone := *release
+	r.releases = append(r.releases, &clone)
+	return release, nil
+}
+
+// latestPackVersion returns the latest released version of a language's
+// pack. The caller must hold the lock.
+func (r *QuestionRepository) latestPackVersion(language string) int {
+	latest := 0
+	for _, release := range r.releases {
+		if release.Language == language {
+			latest = max(latest, release.Version)
+		}
+	}
+	return latest
+}
+
+// released reports whether a question was released in packVersion or
+// earlier, in any release when it is 0
+func released(q *domain.Question, packVersion int) bool {
+	return q.PackVersion > 0 && (packVersion == 0 || q.PackVersion <= packVersion)
+}
+
+// create assigns an ID and timestamps and stores a copy. The caller must hold the lock.
+func (r *QuestionRepository) create(question *domain.Question) {
+	r.nextID++
+	now := time.Now().UTC()
+	question.ID = strconv.Itoa(r.nextID)
+	if question.Difficulty == "" {
+		question.Difficulty = defaultDifficulty
+	}
+	if question.Language == "" {
+		question.Language = domain.DefaultQuestionLanguage
+	}
+	if question.Rating == "" {
+		question.Rating = domain.DefaultContentRating
+	}
+	question.PackVersion = 0
+	question.CreatedAt = now
+	question.UpdatedAt = now
+	question.DeletedAt = nil
+	r.questions[question.ID] = cloneQuestion(question)
+}
+
+// compareQuestionIDs orders questions by their numeric IDs
+func compareQuestionIDs(a, b *domain.Question) int {
+	if len(a.ID) != len(b.ID) {
+		return len(a.ID) - len(b.ID)
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+// cloneQuestion copies a question
+func cloneQuestion(q *domain.Question) *domain.Question {
+	clone := *q
+	clone.FillerAnswers = slices.Clone(q.FillerAnswers)
+	if q.DeletedAt != nil {
+		deletedAt := *q.DeletedAt
+		clone.DeletedAt = &deletedAt
+	}
+	return &clone
+}
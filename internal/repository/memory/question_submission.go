@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// QuestionSubmissionRepository implements the domain.QuestionSubmissionRepository interface in memory
+type QuestionSubmissionRepository struct {
+	mu          sync.RWMutex
+	submissions map[string]*domain.QuestionSubmission
+	thresholds  domain.TrustThresholds
+}
+
+// NewQuestionSubmissionRepository creates a new in-memory question
+// submission repository with the default trust thresholds
+func NewQuestionSubmissionRepository() *QuestionSubmissionRepository {
+	return &QuestionSubmissionRepository{
+		submissions: make(map[string]*domain.QuestionSubmission),
+		thresholds:  domain.DefaultTrustThresholds(),
+	}
+}
+
+// Create creates a submission
+func (r *QuestionSubmissionRepository) Create(ctx context.Context, submission *domain.QuestionSubmission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.submissions[submission.ID]; ok {
+		return fmt.Errorf("question submission already exists: %s", submission.ID)
+	}
+	r.submissions[submission.ID] = cloneSubmission(submission)
+	return nil
+}
+
+// Get retrieves a submission by ID
+func (r *QuestionSubmissionRepository) Get(ctx context.Context, id string) (*domain.QuestionSubmission, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	submission, ok := r.submissions[id]
+	if !ok {
+		return nil, domain.ErrSubmissionNotFound
+	}
+	return cloneSubmission(submission), nil
+}
+
+// ListByStatus retrieves the oldest submissions with a status first
+func (r *QuestionSubmissionRepository) ListByStatus(ctx context.Context, status domain.SubmissionStatus, limit int) ([]*domain.QuestionSubmission, error) {
+	return r.list(limit, func(s *domain.QuestionSubmission) bool { return s.Status == status }, 1), nil
+}
+
+// ListByUser retrieves a user's submissions, newest first
+func (r *QuestionSubmissionRepository) ListByUser(ctx context.Context, userID string, limit int) ([]*domain.QuestionSubmission, error) {
+	return r.list(limit, func(s *domain.QuestionSubmission) bool { return s.UserID == userID }, -1), nil
+}
+
+// Review records a moderator's review of a pending submission
+func (r *QuestionSubmissionRepository) Review(ctx context.Context, submission *domain.QuestionSubmission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.submissions[submission.ID]
+	if !ok || existing.Status != domain.SubmissionStatusPending {
+		return domain.ErrSubmissionNotFound
+	}
+	reviewed := cloneSubmission(submission)
+	reviewed.UserID, reviewed.CreatedAt = existing.UserID, existing.CreatedAt
+	r.submissions[submission.ID] = reviewed
+	return nil
+}
+
+// CountByUser counts a user's submissions by status
+func (r *QuestionSubmissionRepository) CountByUser(ctx context.Context, userID string) (map[domain.SubmissionStatus]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[domain.SubmissionStatus]int)
+	for _, submission := range r.submissions {
+		if submission.UserID == userID {
+			counts[submission.Status]++
+		}
+	}
+	return counts, nil
+}
+
+// GetTrustThresholds retrieves the thresholds for trusting contributors
+func (r *QuestionSubmissionRepository) GetTrustThresholds(ctx context.Context) (*domain.TrustThresholds, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	thresholds := r.thresholds
+	return &thresholds, nil
+}
+
+// SaveTrustThresholds replaces the thresholds for trusting contributors
+func (r *QuestionSubmissionRepository) SaveTrustThresholds(ctx context.Context, thresholds *domain.TrustThresholds) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.thresholds = *thresholds
+	return nil
+}
+
+// list returns copies of the submissions matching a filter, oldest first
+// when order is 1 and newest first when it is -1
+func (r *QuestionSubmissionRepository) list(limit int, match func(*domain.QuestionSubmission) bool, order int) []*domain.QuestionSubmission {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	submissions := []*domain.QuestionSubmission{}
+	for _, submission := range r.submissions {
+		if match(submission) {
+			submissions = append(submissions, cloneSubmission(submission))
+		}
+	}
+	slices.SortFunc(submissions, func(a, b *domain.QuestionSubmission) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt) * order; c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	if len(submissions) > limit {
+		submissions = submissions[:limit]
+	}
+	return submissions
+}
+
+// cloneSubmission copies a submission, including its question's filler
+// answers and review time
+func cloneSubmission(submission *domain.QuestionSubmission) *domain.QuestionSubmission {
+	clone := *submission
+	clone.Question.FillerAnswers = slices.Clone(submission.Question.FillerAnswers)
+	if submission.ReviewedAt != nil {
+		reviewedAt := *submission.ReviewedAt
+		clone.ReviewedAt = &reviewedAt
+	}
+	return &clone
+}
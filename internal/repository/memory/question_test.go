@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+	"github.com/zizouhuweidi/dahaa/internal/random"
+)
+
+// newQuestion returns a question in a category
+func newQuestion(category string, n int) *domain.Question {
+	return &domain.Question{
+		Text:     fmt.Sprintf("%s question %d", category, n),
+		Answer:   fmt.Sprintf("%s answer %d", category, n),
+		Category: category,
+	}
+}
+
+func TestCreateQuestionIsUnreleased(t *testing.T) {
+	ctx := context.Background()
+	repo := NewQuestionRepository(random.New(1))
+
+	q := newQuestion("history", 1)
+	if err := repo.CreateQuestion(ctx, q); err != nil {
+		t.Fatal(err)
+	}
+	if q.ID == "" || q.PackVersion != 0 || q.Difficulty != defaultDifficulty || q.Language != domain.DefaultQuestionLanguage || q.Rating != domain.DefaultContentRating {
+		t.Errorf("created question = %+v, want an ID, defaults and no pack version", q)
+	}
+
+	counts, err := repo.GetCategoryCounts(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 0 {
+		t.Errorf("category counts before publishing = %v, want none", counts)
+	}
+	if _, err := repo.GetRandomQuestion(ctx, "history", domain.DefaultQuestionLanguage, 0, "", nil); !errors.Is(err, domain.ErrQuestionNotFound) {
+		t.Errorf("drawing before publishing: error = %v, want ErrQuestionNotFound", err)
+	}
+
+	release, err := repo.PublishPack(ctx, domain.DefaultQuestionLanguage, "", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.Version != 1 || release.Questions != 1 {
+		t.Errorf("release = %+v, want version 1 with 1 question", release)
+	}
+	drawn, err := repo.GetRandomQuestion(ctx, "history", domain.DefaultQuestionLanguage, 0, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if drawn.ID != q.ID || drawn.PackVersion != 1 {
+		t.Errorf("drawn question = %+v, want %s in pack version 1", drawn, q.ID)
+	}
+
+	if _, err := repo.PublishPack(ctx, domain.DefaultQuestionLanguage, "", time.Now()); !errors.Is(err, domain.ErrNothingToRelease) {
+		t.Errorf("publishing again: error = %v, want ErrNothingToRelease", err)
+	}
+}
+
+func TestSeedQuestions(t *testing.T) {
+	ctx := context.Background()
+	repo := NewQuestionRepository(random.New(1))
+
+	arabic := newQuestion("history", 2)
+	arabic.Language = "ar"
+	if err := repo.SeedQuestions(ctx, newQuestion("history", 1), arabic, newQuestion("science", 1)); err != nil {
+		t.Fatal(err)
+	}
+	if arabic.PackVersion != 1 {
+		t.Errorf("seeded question pack version = %d, want 1", arabic.PackVersion)
+	}
+
+	counts, err := repo.GetCategoryCounts(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []domain.CategoryCount{
+		{Category: "history", Language: "ar", Count: 1},
+		{Category: "history", Language: domain.DefaultQuestionLanguage, Count: 1},
+		{Category: "science", Language: domain.DefaultQuestionLanguage, Count: 1},
+	}
+	if fmt.Sprint(counts) != fmt.Sprint(want) {
+		t.Errorf("category counts = %v, want %v", counts, want)
+	}
+
+	releases, err := repo.ListPackReleases(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) != 2 {
+		t.Errorf("seeding released %d packs, want one per language", len(releases))
+	}
+}
+
+func TestGetRandomQuestionFilters(t *testing.T) {
+	ctx := context.Background()
+	repo := NewQuestionRepository(random.New(1))
+
+	first, second, adult := newQuestion("history", 1), newQuestion("history", 2), newQuestion("history", 3)
+	adult.Rating = domain.ContentRatingAdult
+	if err := repo.SeedQuestions(ctx, first, adult); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SeedQuestions(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		packVersion int
+		maxRating   domain.ContentRating
+		exclude     []string
+		want        string
+	}{
+		{"pinned to the first pack, family only", 1, domain.ContentRatingFamily, nil, first.ID},
+		{"excluding the first question", 0, domain.ContentRatingFamily, []string{first.ID}, second.ID},
+		{"excluding every family question", 0, "", []string{first.ID, second.ID}, adult.ID},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 10; i++ {
+			q, err := repo.GetRandomQuestion(ctx, "history", domain.DefaultQuestionLanguage, tt.packVersion, tt.maxRating, tt.exclude)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if q.ID != tt.want {
+				t.Errorf("%s: drew %s, want %s", tt.name, q.ID, tt.want)
+			}
+		}
+	}
+
+	if _, err := repo.GetRandomQuestion(ctx, "history", domain.DefaultQuestionLanguage, 1, domain.ContentRatingFamily, []string{first.ID}); !errors.Is(err, domain.ErrQuestionNotFound) {
+		t.Errorf("drawing with every candidate excluded: error = %v, want ErrQuestionNotFound", err)
+	}
+}
+
+func TestQuestionListPaginates(t *testing.T) {
+	ctx := context.Background()
+	repo := NewQuestionRepository(random.New(1))
+
+	for i := 0; i < 7; i++ {
+		if err := repo.CreateQuestion(ctx, newQuestion("history", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.DeleteQuestion(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]bool)
+	query := url.Values{"limit": {"3"}, "category": {"history"}}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination did not end")
+		}
+		params, err := pagination.Parse(query, domain.QuestionListOptions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, err := repo.List(ctx, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, q := range page.Items {
+			if seen[q.ID] {
+				t.Errorf("question %s listed twice", q.ID)
+			}
+			seen[q.ID] = true
+		}
+		if !page.HasMore {
+			break
+		}
+		query.Set("cursor", page.NextCursor)
+	}
+	if len(seen) != 6 || seen["1"] {
+		t.Errorf("listed %d questions, want the 6 live ones", len(seen))
+	}
+}
@@ -0,0 +1,218 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// GameSessionStore implements the domain.GameSessionStore interface in
+// memory, in place of the Redis session manager. Nothing it stores expires:
+// games stay active until they are deleted, and tokens until they are
+// revoked or replaced.
+type GameSessionStore struct {
+	mu             sync.RWMutex
+	games          map[string]*domain.Game          // Keyed by ID
+	playerTokens   map[string]string                // Player IDs keyed by game ID and token
+	playerSessions map[string]string                // Tokens keyed by game ID and player ID
+	overlayTokens  map[string]string                // Game IDs keyed by token
+	gameOverlays   map[string]string                // Tokens keyed by game ID
+	resumeSessions map[string]*domain.ResumeSession // Keyed by token
+}
+
+// NewGameSessionStore creates a new in-memory game session store
+func NewGameSessionStore() *GameSessionStore {
+	return &GameSessionStore{
+		games:          make(map[string]*domain.Game),
+		playerTokens:   make(map[string]string),
+		playerSessions: make(map[string]string),
+		overlayTokens:  make(map[string]string),
+		gameOverlays:   make(map[string]string),
+		resumeSessions: make(map[string]*domain.ResumeSession),
+	}
+}
+
+// StoreGame stores an active game, replacing all of its stored rounds
+func (s *GameSessionStore) StoreGame(ctx context.Context, game *domain.Game) error {
+	stored, err := cloneGame(game)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.games[game.ID] = stored
+	return nil
+}
+
+// StoreGameRounds stores a game's state other than its rounds, and its
+// rounds from index from on, leaving the earlier rounds already stored as
+// they are. A game whose earlier rounds are not all stored is dropped, so
+// that it is read back whole from the game repository, as with Redis.
+func (s *GameSessionStore) StoreGameRounds(ctx context.Context, game *domain.Game, from int) error {
+	stored, err := cloneGame(game)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from = min(max(from, 0), len(stored.Rounds))
+	existing, ok := s.games[game.ID]
+	if from > 0 && (!ok || len(existing.Rounds) < from) {
+		delete(s.games, game.ID)
+		return nil
+	}
+	if from > 0 {
+		copy(stored.Rounds[:from], existing.Rounds[:from])
+	}
+	s.games[game.ID] = stored
+	return nil
+}
+
+// GetGame retrieves an active game by its ID, with all of its rounds
+func (s *GameSessionStore) GetGame(ctx context.Context, gameID string) (*domain.Game, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	game, ok := s.games[gameID]
+	if !ok {
+		return nil, domain.ErrGameNotFound
+	}
+	return cloneGame(game)
+}
+
+// GetGameHead retrieves an active game by its ID with only its current round
+func (s *GameSessionStore) GetGameHead(ctx context.Context, gameID string) (*domain.GameHead, error) {
+	game, err := s.GetGame(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+	head := &domain.GameHead{Game: game, RoundCount: len(game.Rounds)}
+	game.Rounds = game.Rounds[max(len(game.Rounds)-1, 0):]
+	return head, nil
+}
+
+// GetGameHeads retrieves every active game with only its current round
+func (s *GameSessionStore) GetGameHeads(ctx context.Context) ([]*domain.GameHead, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	heads := make([]*domain.GameHead, 0, len(s.games))
+	for _, game := range s.games {
+		clone, err := cloneGame(game)
+		if err != nil {
+			return nil, err
+		}
+		head := &domain.GameHead{Game: clone, RoundCount: len(clone.Rounds)}
+		clone.Rounds = clone.Rounds[max(len(clone.Rounds)-1, 0):]
+		heads = append(heads, head)
+	}
+	slices.SortFunc(heads, func(a, b *domain.GameHead) int { return strings.Compare(a.ID, b.ID) })
+	return heads, nil
+}
+
+// DeleteGame removes an active game
+func (s *GameSessionStore) DeleteGame(ctx context.Context, gameID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.games, gameID)
+	return nil
+}
+
+// StorePlayerSession stores a player's session along with the game token
+// that authenticates the player's actions
+func (s *GameSessionStore) StorePlayerSession(ctx context.Context, gameID string, player *domain.Player, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.playerTokens[sessionKey(gameID, token)] = player.ID
+	s.playerSessions[sessionKey(gameID, player.ID)] = token
+	return nil
+}
+
+// ResolvePlayerToken returns the ID of the player a game token was issued to
+func (s *GameSessionStore) ResolvePlayerToken(ctx context.Context, gameID string, token string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	playerID, ok := s.playerTokens[sessionKey(gameID, token)]
+	if !ok {
+		return "", domain.ErrInvalidPlayerToken
+	}
+	return playerID, nil
+}
+
+// DeletePlayerSession deletes a player's session and revokes its token
+func (s *GameSessionStore) DeletePlayerSession(ctx context.Context, gameID string, playerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := sessionKey(gameID, playerID)
+	if token, ok := s.playerSessions[key]; ok {
+		delete(s.playerTokens, sessionKey(gameID, token))
+		delete(s.playerSessions, key)
+	}
+	return nil
+}
+
+// StoreOverlayToken stores the token that lets stream overlays follow a
+// game, revoking the token previously issued for it
+func (s *GameSessionStore) StoreOverlayToken(ctx context.Context, gameID string, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if previous, ok := s.gameOverlays[gameID]; ok {
+		delete(s.overlayTokens, previous)
+	}
+	s.gameOverlays[gameID] = token
+	s.overlayTokens[token] = gameID
+	return nil
+}
+
+// ResolveOverlayToken returns the ID of the game an overlay token was issued for
+func (s *GameSessionStore) ResolveOverlayToken(ctx context.Context, token string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	gameID, ok := s.overlayTokens[token]
+	if !ok {
+		return "", domain.ErrInvalidOverlayToken
+	}
+	return gameID, nil
+}
+
+// StoreResumeSession stores what a WebSocket client is bound to under its
+// resume token
+func (s *GameSessionStore) StoreResumeSession(ctx context.Context, token string, session *domain.ResumeSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clone := *session
+	s.resumeSessions[token] = &clone
+	return nil
+}
+
+// ResolveResumeToken returns what the client holding a resume token was bound to
+func (s *GameSessionStore) ResolveResumeToken(ctx context.Context, token string) (*domain.ResumeSession, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.resumeSessions[token]
+	if !ok {
+		return nil, domain.ErrInvalidResumeToken
+	}
+	clone := *session
+	return &clone, nil
+}
+
+// sessionKey keys a token or player's session within a game
+func sessionKey(gameID string, key string) string {
+	return fmt.Sprintf("%s:%s", gameID, key)
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+func TestGameSessionStoreRounds(t *testing.T) {
+	ctx := context.Background()
+	store := NewGameSessionStore()
+
+	game := &domain.Game{ID: "game-1", Rounds: []domain.Round{{Number: 1, Question: "first"}, {Number: 2}}}
+	if err := store.StoreGame(ctx, game); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the rounds from the given index on are written
+	changed := &domain.Game{ID: "game-1", Version: 2, Rounds: []domain.Round{{Number: 1, Question: "rewritten"}, {Number: 2, Question: "second"}, {Number: 3}}}
+	if err := store.StoreGameRounds(ctx, changed, 1); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := store.GetGame(ctx, "game-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Version != 2 || len(stored.Rounds) != 3 || stored.Rounds[0].Question != "first" || stored.Rounds[1].Question != "second" {
+		t.Errorf("stored game = %+v, want version 2 with the first round kept", stored)
+	}
+
+	heads, err := store.GetGameHeads(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(heads) != 1 || heads[0].RoundCount != 3 || len(heads[0].Rounds) != 1 || heads[0].Rounds[0].Number != 3 {
+		t.Errorf("heads = %+v, want the game with only its current round", heads)
+	}
+	head, err := store.GetGameHead(ctx, "game-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Version != 2 || head.RoundCount != 3 || len(head.Rounds) != 1 || head.Rounds[0].Number != 3 {
+		t.Errorf("head = %+v, want version 2 with only its current round", head)
+	}
+
+	// A game missing earlier rounds is read back from the game repository
+	if err := store.StoreGameRounds(ctx, &domain.Game{ID: "game-2", Rounds: make([]domain.Round, 2)}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetGame(ctx, "game-2"); !errors.Is(err, domain.ErrGameNotFound) {
+		t.Errorf("getting a game stored without its earlier rounds: error = %v, want ErrGameNotFound", err)
+	}
+
+	if err := store.DeleteGame(ctx, "game-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetGame(ctx, "game-1"); !errors.Is(err, domain.ErrGameNotFound) {
+		t.Errorf("getting a deleted game: error = %v, want ErrGameNotFound", err)
+	}
+}
+
+func TestGameSessionStoreTokens(t *testing.T) {
+	ctx := context.Background()
+	store := NewGameSessionStore()
+
+	if err := store.StorePlayerSession(ctx, "game-1", &domain.Player{ID: "alice"}, "token-a"); err != nil {
+		t.Fatal(err)
+	}
+	if playerID, err := store.ResolvePlayerToken(ctx, "game-1", "token-a"); err != nil || playerID != "alice" {
+		t.Errorf("resolving a player token = %q, %v, want alice", playerID, err)
+	}
+	if _, err := store.ResolvePlayerToken(ctx, "game-2", "token-a"); !errors.Is(err, domain.ErrInvalidPlayerToken) {
+		t.Errorf("resolving a token in another game: error = %v, want ErrInvalidPlayerToken", err)
+	}
+	if err := store.DeletePlayerSession(ctx, "game-1", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.ResolvePlayerToken(ctx, "game-1", "token-a"); !errors.Is(err, domain.ErrInvalidPlayerToken) {
+		t.Errorf("resolving a revoked token: error = %v, want ErrInvalidPlayerToken", err)
+	}
+
+	for _, token := range []string{"overlay-1", "overlay-2"} {
+		if err := store.StoreOverlayToken(ctx, "game-1", token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.ResolveOverlayToken(ctx, "overlay-1"); !errors.Is(err, domain.ErrInvalidOverlayToken) {
+		t.Errorf("resolving a replaced overlay token: error = %v, want ErrInvalidOverlayToken", err)
+	}
+	if gameID, err := store.ResolveOverlayToken(ctx, "overlay-2"); err != nil || gameID != "game-1" {
+		t.Errorf("resolving an overlay token = %q, %v, want game-1", gameID, err)
+	}
+
+	session := &domain.ResumeSession{GameID: "game-1", PlayerID: "alice"}
+	if err := store.StoreResumeSession(ctx, "resume", session); err != nil {
+		t.Fatal(err)
+	}
+	session.LastAck = 5
+	if resumed, err := store.ResolveResumeToken(ctx, "resume"); err != nil || resumed.LastAck != 0 || resumed.PlayerID != "alice" {
+		t.Errorf("resolving a resume token = %+v, %v, want the session as stored", resumed, err)
+	}
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// SettingsPresetRepository implements the domain.SettingsPresetRepository interface in memory
+type SettingsPresetRepository struct {
+	mu      sync.RWMutex
+	presets map[settingsPresetKey]*domain.SettingsPreset
+}
+
+// settingsPresetKey identifies one of a user's presets
+type settingsPresetKey struct {
+	userID string
+	key    string
+}
+
+// NewSettingsPresetRepository creates a new in-memory settings preset repository
+func NewSettingsPresetRepository() *SettingsPresetRepository {
+	return &SettingsPresetRepository{
+		presets: make(map[settingsPresetKey]*domain.SettingsPreset),
+	}
+}
+
+// ListByUser retrieves a user's presets ordered by name
+func (r *SettingsPresetRepository) ListByUser(ctx context.Context, userID string) ([]*domain.SettingsPreset, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	presets := []*domain.SettingsPreset{}
+	for key, preset := range r.presets {
+		if key.userID == userID {
+			presets = append(presets, clonePreset(preset))
+		}
+	}
+	slices.SortFunc(presets, func(a, b *domain.SettingsPreset) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return presets, nil
+}
+
+// Get retrieves one of a user's presets by key
+func (r *SettingsPresetRepository) Get(ctx context.Context, userID string, key string) (*domain.SettingsPreset, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	preset, ok := r.presets[settingsPresetKey{userID, key}]
+	if !ok {
+		return nil, domain.ErrPresetNotFound
+	}
+	return clonePreset(preset), nil
+}
+
+// Save creates a preset or replaces the user's preset with the same key,
+// keeping its creation time
+func (r *SettingsPresetRepository) Save(ctx context.Context, preset *domain.SettingsPreset) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := settingsPresetKey{preset.UserID, preset.Key}
+	if existing, ok := r.presets[key]; ok {
+		preset.CreatedAt = existing.CreatedAt
+	}
+	r.presets[key] = clonePreset(preset)
+	return nil
+}
+
+// Delete removes one of a user's presets
+func (r *SettingsPresetRepository) Delete(ctx context.Context, userID string, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.presets[settingsPresetKey{userID, key}]; !ok {
+		return domain.ErrPresetNotFound
+	}
+	delete(r.presets, settingsPresetKey{userID, key})
+	return nil
+}
+
+// clonePreset copies a preset, including its category list
+func clonePreset(preset *domain.SettingsPreset) *domain.SettingsPreset {
+	clone := *preset
+	clone.Settings.SelectedCategories = slices.Clone(preset.Settings.SelectedCategories)
+	return &clone
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// StatsRollupRepository implements the domain.StatsRollupRepository interface in memory
+type StatsRollupRepository struct {
+	mu      sync.RWMutex
+	rollups map[statsRollupKey]*domain.StatsRollup
+}
+
+// statsRollupKey identifies a user's rollup for one period
+type statsRollupKey struct {
+	userID string
+	period domain.StatsPeriod
+	start  int64 // Unix time of the period start
+}
+
+// NewStatsRollupRepository creates a new in-memory stats rollup repository
+func NewStatsRollupRepository() *StatsRollupRepository {
+	return &StatsRollupRepository{
+		rollups: make(map[statsRollupKey]*domain.StatsRollup),
+	}
+}
+
+// Upsert creates or replaces rollups
+func (r *StatsRollupRepository) Upsert(ctx context.Context, rollups []*domain.StatsRollup) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rollup := range rollups {
+		stored := *rollup
+		r.rollups[statsRollupKey{rollup.UserID, rollup.Period, rollup.PeriodStart.Unix()}] = &stored
+	}
+	return nil
+}
+
+// ListByUser retrieves a user's most recent rollups for a period, newest first
+func (r *StatsRollupRepository) ListByUser(ctx context.Context, userID string, period domain.StatsPeriod, limit int) ([]*domain.StatsRollup, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rollups := []*domain.StatsRollup{}
+	for key, rollup := range r.rollups {
+		if key.userID == userID && key.period == period {
+			clone := *rollup
+			rollups = append(rollups, &clone)
+		}
+	}
+	slices.SortFunc(rollups, func(a, b *domain.StatsRollup) int {
+		return b.PeriodStart.Compare(a.PeriodStart)
+	})
+	return rollups[:min(limit, len(rollups))], nil
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// TenantRepository implements the domain.TenantRepository interface in memory
+type TenantRepository struct {
+	mu      sync.RWMutex
+	tenants map[string]*domain.Tenant
+	sso     map[string]*domain.SSOConfig // By tenant ID
+}
+
+// NewTenantRepository creates a new in-memory tenant repository
+func NewTenantRepository() *TenantRepository {
+	return &TenantRepository{
+		tenants: make(map[string]*domain.Tenant),
+		sso:     make(map[string]*domain.SSOConfig),
+	}
+}
+
+// Save creates or replaces an organization
+func (r *TenantRepository) Save(ctx context.Context, tenant *domain.Tenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.tenants[tenant.ID]; ok {
+		tenant.CreatedAt = existing.CreatedAt
+	}
+	stored := *tenant
+	r.tenants[tenant.ID] = &stored
+	return nil
+}
+
+// Get retrieves an organization by ID
+func (r *TenantRepository) Get(ctx context.Context, id string) (*domain.Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tenant, ok := r.tenants[id]
+	if !ok {
+		return nil, domain.ErrTenantNotFound
+	}
+	clone := *tenant
+	return &clone, nil
+}
+
+// List retrieves every organization, ordered by ID
+func (r *TenantRepository) List(ctx context.Context) ([]*domain.Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tenants := make([]*domain.Tenant, 0, len(r.tenants))
+	for _, tenant := range r.tenants {
+		clone := *tenant
+		tenants = append(tenants, &clone)
+	}
+	slices.SortFunc(tenants, func(a, b *domain.Tenant) int { return strings.Compare(a.ID, b.ID) })
+	return tenants, nil
+}
+
+// SaveSSO creates or replaces an organization's single sign-on configuration
+func (r *TenantRepository) SaveSSO(ctx context.Context, config *domain.SSOConfig) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.sso[config.TenantID]; ok {
+		config.CreatedAt = existing.CreatedAt
+	}
+	r.sso[config.TenantID] = cloneSSOConfig(config)
+	return nil
+}
+
+// GetSSO retrieves an organization's single sign-on configuration
+func (r *TenantRepository) GetSSO(ctx context.Context, tenantID string) (*domain.SSOConfig, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	config, ok := r.sso[tenantID]
+	if !ok {
+		return nil, domain.ErrSSONotConfigured
+	}
+	return cloneSSOConfig(config), nil
+}
+
+// DeleteSSO removes an organization's single sign-on configuration
+func (r *TenantRepository) DeleteSSO(ctx context.Context, tenantID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sso[tenantID]; !ok {
+		return domain.ErrSSONotConfigured
+	}
+	delete(r.sso, tenantID)
+	return nil
+}
+
+// cloneSSOConfig returns a copy of a single sign-on configuration that shares
+// no maps with it
+func cloneSSOConfig(config *domain.SSOConfig) *domain.SSOConfig {
+	clone := *config
+	clone.RoleMapping = maps.Clone(config.RoleMapping)
+	return &clone
+}
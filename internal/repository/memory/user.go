@@ -0,0 +1,294 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+)
+
+// userSortFields maps sortable fields to their values
+var userSortFields = map[string]sortField[*domain.User]{
+	"created_at": func(u *domain.User) time.Time { return u.CreatedAt },
+	"updated_at": func(u *domain.User) time.Time { return u.UpdatedAt },
+}
+
+// UserRepository implements the domain.UserRepository interface in memory
+type UserRepository struct {
+	mu         sync.RWMutex
+	users      map[string]*domain.User // Keyed by ID
+	deleted    map[string]time.Time    // When deleted users were deleted, by ID
+	identities map[identityKey]string  // IDs of the users outside accounts are linked to
+}
+
+// identityKey identifies an outside account
+type identityKey struct {
+	provider string
+	subject  string
+}
+
+// NewUserRepository creates a new in-memory user repository
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		users:      make(map[string]*domain.User),
+		deleted:    make(map[string]time.Time),
+		identities: make(map[identityKey]string),
+	}
+}
+
+// Create creates a new user. Usernames and emails must be unique.
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; ok {
+		return domain.ErrUserAlreadyExists
+	}
+	for _, existing := range r.users {
+		if existing.Username == user.Username || strings.EqualFold(existing.Email, user.Email) {
+			return domain.ErrUserAlreadyExists
+		}
+	}
+
+	if user.Role == "" {
+		user.Role = domain.UserRoleUser
+	}
+	stored := *user
+	r.users[user.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves a user by their ID
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok || r.isDeleted(id) {
+		return nil, domain.ErrUserNotFound
+	}
+	clone := *user
+	return &clone, nil
+}
+
+// GetByUsername retrieves a user by their username
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	return r.find(func(user *domain.User) bool { return user.Username == username })
+}
+
+// GetByEmail retrieves a user by their email
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.find(func(user *domain.User) bool { return strings.EqualFold(user.Email, email) })
+}
+
+// GetByIdentity retrieves the user an outside account is linked to
+func (r *UserRepository) GetByIdentity(ctx context.Context, provider string, subject string) (*domain.User, error) {
+	r.mu.RLock()
+	userID, ok := r.identities[identityKey{provider, subject}]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return r.GetByID(ctx, userID)
+}
+
+// LinkIdentity links an outside account to a user, replacing the user it
+// was linked to
+func (r *UserRepository) LinkIdentity(ctx context.Context, userID string, provider string, subject string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[userID]; !ok || r.isDeleted(userID) {
+		return domain.ErrUserNotFound
+	}
+	r.identities[identityKey{provider, subject}] = userID
+	return nil
+}
+
+// Update updates a user's information, keeping their role if none is given
+// and their organization as it is
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.users[user.ID]
+	if !ok || r.isDeleted(user.ID) {
+		return nil
+	}
+
+	stored := *user
+	if stored.Role == "" {
+		stored.Role = existing.Role
+	}
+	stored.TenantID, stored.TenantRole = existing.TenantID, existing.TenantRole
+	stored.CreatedAt = existing.CreatedAt
+	stored.UpdatedAt = time.Now()
+	r.users[user.ID] = &stored
+	return nil
+}
+
+// Delete soft-deletes a user, anonymizing their account and unlinking their
+// outside accounts. Unlike the
+// Postgres repository, it cannot rename them in game results or parties.
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || r.isDeleted(id) {
+		return domain.ErrUserNotFound
+	}
+
+	now := time.Now()
+	user.Username = "deleted_" + id
+	user.Email = "deleted+" + id + "@invalid"
+	user.PasswordHash = ""
+	user.DisplayName = domain.DeletedPlayerName
+	user.UpdatedAt = now
+	r.deleted[id] = now
+	for key, userID := range r.identities {
+		if userID == id {
+			delete(r.identities, key)
+		}
+	}
+	return nil
+}
+
+// PurgeDeleted permanently removes up to limit users deleted before the cutoff
+func (r *UserRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, deletedAt := range r.deleted {
+		if purged == int64(limit) {
+			break
+		}
+		if deletedAt.Before(before) {
+			delete(r.users, id)
+			delete(r.deleted, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// UpdateStats updates a user's game statistics
+func (r *UserRepository) UpdateStats(ctx context.Context, id string, stats domain.UserStats) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user, ok := r.users[id]; ok && !r.isDeleted(id) {
+		user.Stats = stats
+		user.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// UpdatePreferences replaces a user's preferences
+func (r *UserRepository) UpdatePreferences(ctx context.Context, id string, preferences domain.UserPreferences) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || r.isDeleted(id) {
+		return domain.ErrUserNotFound
+	}
+	if preferences.GameSettings != nil {
+		settings := *preferences.GameSettings
+		settings.SelectedCategories = slices.Clone(settings.SelectedCategories)
+		preferences.GameSettings = &settings
+	}
+	user.Preferences = preferences
+	user.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetTenant moves a user into an organization with a role, or out of theirs
+// when tenantID is empty
+func (r *UserRepository) SetTenant(ctx context.Context, id string, tenantID string, role domain.TenantRole) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || r.isDeleted(id) {
+		return domain.ErrUserNotFound
+	}
+	if tenantID == "" {
+		role = ""
+	}
+	user.TenantID, user.TenantRole = tenantID, role
+	user.UpdatedAt = time.Now()
+	return nil
+}
+
+// ListByTenant retrieves the members of an organization, ordered by display
+// name
+func (r *UserRepository) ListByTenant(ctx context.Context, tenantID string) ([]*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := []*domain.User{}
+	for _, user := range r.users {
+		if user.TenantID == tenantID && !r.isDeleted(user.ID) {
+			clone := *user
+			members = append(members, &clone)
+		}
+	}
+	slices.SortFunc(members, func(a, b *domain.User) int {
+		if c := strings.Compare(a.DisplayName, b.DisplayName); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return members, nil
+}
+
+// List retrieves a page of users, optionally filtered by role or organization
+func (r *UserRepository) List(ctx context.Context, params *pagination.Params) (pagination.Page[*domain.User], error) {
+	role, byRole := params.Filter("role")
+	tenantID, byTenant := params.Filter("tenant_id")
+
+	r.mu.RLock()
+	var users []*domain.User
+	for _, user := range r.users {
+		if r.isDeleted(user.ID) {
+			continue
+		}
+		if byRole && string(user.Role) != role {
+			continue
+		}
+		if byTenant && user.TenantID != tenantID {
+			continue
+		}
+		clone := *user
+		users = append(users, &clone)
+	}
+	r.mu.RUnlock()
+
+	return paginate(users, params, userSortFields, func(u *domain.User) string { return u.ID })
+}
+
+// find returns a copy of the first user matching the predicate
+func (r *UserRepository) find(match func(user *domain.User) bool) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if !r.isDeleted(user.ID) && match(user) {
+			clone := *user
+			return &clone, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+// isDeleted reports whether a user was deleted. The caller must hold the lock.
+func (r *UserRepository) isDeleted(id string) bool {
+	_, ok := r.deleted[id]
+	return ok
+}
@@ -0,0 +1,171 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// UserBanRepository implements the domain.UserBanRepository interface in memory
+type UserBanRepository struct {
+	mu   sync.RWMutex
+	bans map[string]*domain.UserBan
+	log  []*domain.ModerationLogEntry
+}
+
+// NewUserBanRepository creates a new in-memory user ban repository
+func NewUserBanRepository() *UserBanRepository {
+	return &UserBanRepository{
+		bans: make(map[string]*domain.UserBan),
+	}
+}
+
+// Create creates a ban
+func (r *UserBanRepository) Create(ctx context.Context, ban *domain.UserBan, entry *domain.ModerationLogEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.bans[ban.ID]; ok {
+		return fmt.Errorf("ban already exists: %s", ban.ID)
+	}
+	r.bans[ban.ID] = cloneBan(ban)
+	r.appendLog(entry)
+	return nil
+}
+
+// Get retrieves a ban by ID
+func (r *UserBanRepository) Get(ctx context.Context, id string) (*domain.UserBan, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ban, ok := r.bans[id]
+	if !ok {
+		return nil, domain.ErrBanNotFound
+	}
+	return cloneBan(ban), nil
+}
+
+// GetActive retrieves the user's ban in force at a time, preferring
+// permanent bans and then the one expiring last
+func (r *UserBanRepository) GetActive(ctx context.Context, userID string, now time.Time) (*domain.UserBan, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var active *domain.UserBan
+	for _, ban := range r.bans {
+		if ban.UserID != userID || !ban.IsActive(now) {
+			continue
+		}
+		if active == nil || ban.ExpiresAt == nil || (active.ExpiresAt != nil && ban.ExpiresAt.After(*active.ExpiresAt)) {
+			active = ban
+		}
+	}
+	if active == nil {
+		return nil, domain.ErrBanNotFound
+	}
+	return cloneBan(active), nil
+}
+
+// ListByUser retrieves a user's bans, newest first
+func (r *UserBanRepository) ListByUser(ctx context.Context, userID string) ([]*domain.UserBan, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bans := []*domain.UserBan{}
+	for _, ban := range r.bans {
+		if ban.UserID == userID {
+			bans = append(bans, cloneBan(ban))
+		}
+	}
+	slices.SortFunc(bans, func(a, b *domain.UserBan) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return bans, nil
+}
+
+// Lift ends a ban early
+func (r *UserBanRepository) Lift(ctx context.Context, id string, liftedBy string, at time.Time, entry *domain.ModerationLogEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ban, ok := r.bans[id]
+	if !ok || ban.LiftedAt != nil {
+		return domain.ErrBanNotFound
+	}
+	lifted := cloneBan(ban)
+	lifted.LiftedAt = &at
+	lifted.LiftedBy = liftedBy
+	r.bans[id] = lifted
+	r.appendLog(entry)
+	return nil
+}
+
+// UpdateAppealNote replaces a ban's appeal note
+func (r *UserBanRepository) UpdateAppealNote(ctx context.Context, id string, note string, entry *domain.ModerationLogEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ban, ok := r.bans[id]
+	if !ok {
+		return domain.ErrBanNotFound
+	}
+	updated := cloneBan(ban)
+	updated.AppealNote = note
+	r.bans[id] = updated
+	r.appendLog(entry)
+	return nil
+}
+
+// ListLog retrieves the most recent moderation log entries, newest first,
+// only for a user when userID is set
+func (r *UserBanRepository) ListLog(ctx context.Context, userID string, limit int) ([]*domain.ModerationLogEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := []*domain.ModerationLogEntry{}
+	for _, entry := range r.log {
+		if userID == "" || entry.UserID == userID {
+			clone := *entry
+			entries = append(entries, &clone)
+		}
+	}
+	slices.SortFunc(entries, func(a, b *domain.ModerationLogEntry) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// appendLog records a copy of a moderation log entry. The caller must hold
+// the lock.
+func (r *UserBanRepository) appendLog(entry *domain.ModerationLogEntry) {
+	clone := *entry
+	r.log = append(r.log, &clone)
+}
+
+// cloneBan copies a ban, including its timestamps
+func cloneBan(ban *domain.UserBan) *domain.UserBan {
+	clone := *ban
+	if ban.ExpiresAt != nil {
+		expiresAt := *ban.ExpiresAt
+		clone.ExpiresAt = &expiresAt
+	}
+	if ban.LiftedAt != nil {
+		liftedAt := *ban.LiftedAt
+		clone.LiftedAt = &liftedAt
+	}
+	return &clone
+}
@@ -0,0 +1,179 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+)
+
+func TestUserRepositoryUniqueness(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository()
+
+	if err := repo.Create(ctx, &domain.User{ID: "user-1", Username: "alice", Email: "alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	duplicates := []*domain.User{
+		{ID: "user-1", Username: "bob", Email: "bob@example.com"},
+		{ID: "user-2", Username: "alice", Email: "other@example.com"},
+		{ID: "user-3", Username: "carol", Email: "Alice@Example.com"},
+	}
+	for _, user := range duplicates {
+		if err := repo.Create(ctx, user); err == nil {
+			t.Errorf("creating %+v succeeded, want it rejected as a duplicate", user)
+		}
+	}
+
+	user, err := repo.GetByEmail(ctx, "ALICE@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != "user-1" || user.Role != domain.UserRoleUser {
+		t.Errorf("user = %+v, want user-1 with the default role", user)
+	}
+}
+
+func TestUserRepositoryDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository()
+
+	if err := repo.Create(ctx, &domain.User{ID: "user-1", Username: "alice", Email: "alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.LinkIdentity(ctx, "user-1", "google", "subject"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, "user-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.GetByID(ctx, "user-1"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("getting a deleted user: error = %v, want ErrUserNotFound", err)
+	}
+	if _, err := repo.GetByIdentity(ctx, "google", "subject"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("getting a deleted user's identity: error = %v, want ErrUserNotFound", err)
+	}
+	if err := repo.Delete(ctx, "user-1"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("deleting a user twice: error = %v, want ErrUserNotFound", err)
+	}
+
+	// Their username and email are free again
+	if err := repo.Create(ctx, &domain.User{ID: "user-2", Username: "alice", Email: "alice@example.com"}); err != nil {
+		t.Errorf("reusing a deleted user's username and email: %v", err)
+	}
+	if purged, err := repo.PurgeDeleted(ctx, time.Now().Add(time.Minute), 10); err != nil || purged != 1 {
+		t.Errorf("purging deleted users = %d, %v, want 1", purged, err)
+	}
+}
+
+func TestGameInviteRepositoryExpiry(t *testing.T) {
+	ctx := context.Background()
+	repo := NewGameInviteRepository()
+	now := time.Now()
+
+	invites := []*domain.GameInvite{
+		{ID: "old", ToUser: "bob", Status: domain.InviteStatusPending, CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(time.Hour)},
+		{ID: "new", ToUser: "bob", Status: domain.InviteStatusPending, CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)},
+		{ID: "lapsed", ToUser: "bob", Status: domain.InviteStatusPending, CreatedAt: now.Add(-3 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+		{ID: "accepted", ToUser: "bob", Status: domain.InviteStatusAccepted, CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+	}
+	for _, invite := range invites {
+		if err := repo.Create(ctx, invite); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	params, err := pagination.Parse(url.Values{"limit": {"1"}}, domain.InviteListOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := repo.GetPendingInvites(ctx, "bob", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Items) != 1 || first.Items[0].ID != "new" || !first.HasMore {
+		t.Errorf("first page of pending invites = %+v, want new with more to come", first)
+	}
+	params.Cursor, err = pagination.DecodeCursor(first.NextCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := repo.GetPendingInvites(ctx, "bob", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Items) != 1 || second.Items[0].ID != "old" || second.HasMore {
+		t.Errorf("second page of pending invites = %+v, want old and no more", second)
+	}
+
+	if n, err := repo.MarkExpired(ctx, now); err != nil || n != 1 {
+		t.Errorf("marking expired invites = %d, %v, want 1", n, err)
+	}
+	lapsed, err := repo.GetByID(ctx, "lapsed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lapsed.Status != domain.InviteStatusExpired {
+		t.Errorf("lapsed invite status = %s, want %s", lapsed.Status, domain.InviteStatusExpired)
+	}
+
+	if n, err := repo.DeleteExpired(ctx, now, 10); err != nil || n != 1 {
+		t.Errorf("deleting expired invites = %d, %v, want 1", n, err)
+	}
+	if _, err := repo.GetByID(ctx, "lapsed"); !errors.Is(err, domain.ErrInviteNotFound) {
+		t.Errorf("getting a deleted invite: error = %v, want ErrInviteNotFound", err)
+	}
+}
+
+func TestUserRepositoryList(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository()
+	now := time.Now()
+
+	users := []*domain.User{
+		{ID: "alice", Username: "alice", Email: "alice@example.com", Role: domain.UserRoleAdmin, CreatedAt: now.Add(-3 * time.Hour)},
+		{ID: "bob", Username: "bob", Email: "bob@example.com", Role: domain.UserRoleUser, TenantID: "acme", CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "carol", Username: "carol", Email: "carol@example.com", Role: domain.UserRoleUser, CreatedAt: now.Add(-time.Hour)},
+	}
+	for _, user := range users {
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.Delete(ctx, "carol"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query url.Values
+		want  []string
+	}{
+		{url.Values{}, []string{"bob", "alice"}},
+		{url.Values{"order": {"asc"}}, []string{"alice", "bob"}},
+		{url.Values{"role": {"user"}}, []string{"bob"}},
+		{url.Values{"tenant_id": {"acme"}}, []string{"bob"}},
+	}
+	for _, tt := range tests {
+		params, err := pagination.Parse(tt.query, domain.UserListOptions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, err := repo.List(ctx, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, user := range page.Items {
+			got = append(got, user.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("listing users with %v = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// AccountSignalRepository implements the domain.AccountSignalRepository interface
+type AccountSignalRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAccountSignalRepository creates a new account signal repository
+func NewAccountSignalRepository(pool *pgxpool.Pool) *AccountSignalRepository {
+	return &AccountSignalRepository{pool: pool}
+}
+
+// Create records a signal
+func (r *AccountSignalRepository) Create(ctx context.Context, signal *domain.AccountSignal) error {
+	flags := make([]string, len(signal.Flags))
+	for i, flag := range signal.Flags {
+		flags[i] = string(flag)
+	}
+
+	query := `
+		INSERT INTO account_signals (id, user_id, kind, ip, country, flags, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		signal.ID,
+		signal.UserID,
+		signal.Kind,
+		signal.IP,
+		signal.Country,
+		flags,
+		signal.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create account signal: %w", err)
+	}
+	return nil
+}
+
+// ListByUser retrieves a user's most recent signals, newest first
+func (r *AccountSignalRepository) ListByUser(ctx context.Context, userID string, limit int) ([]*domain.AccountSignal, error) {
+	query := `
+		SELECT id, user_id, kind, ip, country, flags, created_at
+		FROM account_signals
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account signals: %w", err)
+	}
+	defer rows.Close()
+
+	signals := []*domain.AccountSignal{}
+	for rows.Next() {
+		signal, err := scanAccountSignal(rows)
+		if err != nil {
+			return nil, err
+		}
+		signals = append(signals, signal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating account signals: %w", err)
+	}
+
+	return signals, nil
+}
+
+// CountAccountsByIP counts the accounts other than excludeUserID with
+// signals from an IP address since a time
+func (r *AccountSignalRepository) CountAccountsByIP(ctx context.Context, ip string, since time.Time, excludeUserID string) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT user_id)
+		FROM account_signals
+		WHERE ip = $1 AND created_at >= $2 AND user_id <> $3
+	`
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, ip, since, excludeUserID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count accounts by IP: %w", err)
+	}
+	return count, nil
+}
+
+// ListSharedIPs retrieves the IP addresses that at least minAccounts
+// accounts have signals from since a time, most accounts first
+func (r *AccountSignalRepository) ListSharedIPs(ctx context.Context, since time.Time, minAccounts int, limit int) ([]*domain.SharedIP, error) {
+	query := `
+		SELECT ip, MAX(country), ARRAY_AGG(DISTINCT user_id), MAX(created_at)
+		FROM account_signals
+		WHERE created_at >= $1
+		GROUP BY ip
+		HAVING COUNT(DISTINCT user_id) >= $2
+		ORDER BY COUNT(DISTINCT user_id) DESC, MAX(created_at) DESC, ip
+		LIMIT $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, since, minAccounts, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shared IPs: %w", err)
+	}
+	defer rows.Close()
+
+	shared := []*domain.SharedIP{}
+	for rows.Next() {
+		var ip domain.SharedIP
+		if err := rows.Scan(&ip.IP, &ip.Country, &ip.UserIDs, &ip.LastSeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan shared IP: %w", err)
+		}
+		shared = append(shared, &ip)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating shared IPs: %w", err)
+	}
+
+	return shared, nil
+}
+
+// scanAccountSignal scans an account signal row
+func scanAccountSignal(row pgx.Row) (*domain.AccountSignal, error) {
+	var signal domain.AccountSignal
+	var flags []string
+	if err := row.Scan(
+		&signal.ID,
+		&signal.UserID,
+		&signal.Kind,
+		&signal.IP,
+		&signal.Country,
+		&flags,
+		&signal.CreatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("failed to scan account signal: %w", err)
+	}
+	signal.Flags = make([]domain.AbuseFlag, len(flags))
+	for i, flag := range flags {
+		signal.Flags[i] = domain.AbuseFlag(flag)
+	}
+	return &signal, nil
+}
@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// CategoryStatsRepository implements the domain.CategoryStatsRepository interface
+type CategoryStatsRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewCategoryStatsRepository creates a new category stats repository
+func NewCategoryStatsRepository(pool *pgxpool.Pool) *CategoryStatsRepository {
+	return &CategoryStatsRepository{
+		pool: pool,
+	}
+}
+
+// addCategoryStats adds rounds' increments to the players' category totals
+func addCategoryStats(ctx context.Context, tx pgx.Tx, stats []*domain.CategoryStats) error {
+	query := `
+		INSERT INTO category_stats (
+			user_id, category, rounds, votes_cast, correct_votes,
+			players_fooled, fooled_by_house, opponent_votes, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id, category) DO UPDATE SET
+			rounds = category_stats.rounds + EXCLUDED.rounds,
+			votes_cast = category_stats.votes_cast + EXCLUDED.votes_cast,
+			correct_votes = category_stats.correct_votes + EXCLUDED.correct_votes,
+			players_fooled = category_stats.players_fooled + EXCLUDED.players_fooled,
+			fooled_by_house = category_stats.fooled_by_house + EXCLUDED.fooled_by_house,
+			opponent_votes = category_stats.opponent_votes + EXCLUDED.opponent_votes,
+			updated_at = EXCLUDED.updated_at
+	`
+	for _, s := range stats {
+		if _, err := tx.Exec(ctx, query,
+			s.UserID,
+			s.Category,
+			s.Rounds,
+			s.VotesCast,
+			s.CorrectVotes,
+			s.PlayersFooled,
+			s.FooledByHouse,
+			s.OpponentVotes,
+			s.UpdatedAt,
+		); err != nil {
+			return fmt.Errorf("failed to update category stats: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListByUser retrieves a user's stats for every category, most played first
+func (r *CategoryStatsRepository) ListByUser(ctx context.Context, userID string) ([]*domain.CategoryStats, error) {
+	query := `
+		SELECT user_id, category, rounds, votes_cast, correct_votes,
+			players_fooled, fooled_by_house, opponent_votes, updated_at
+		FROM category_stats
+		WHERE user_id = $1
+		ORDER BY rounds DESC, category
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list category stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []*domain.CategoryStats{}
+	for rows.Next() {
+		var s domain.CategoryStats
+		if err := rows.Scan(
+			&s.UserID,
+			&s.Category,
+			&s.Rounds,
+			&s.VotesCast,
+			&s.CorrectVotes,
+			&s.PlayersFooled,
+			&s.FooledByHouse,
+			&s.OpponentVotes,
+			&s.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan category stats: %w", err)
+		}
+		s.ComputeRates()
+		stats = append(stats, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating category stats: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// CosmeticRepository implements the domain.CosmeticRepository interface
+type CosmeticRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewCosmeticRepository creates a new cosmetic repository
+func NewCosmeticRepository(pool *pgxpool.Pool) *CosmeticRepository {
+	return &CosmeticRepository{pool: pool}
+}
+
+// Unlock records cosmetics as unlocked by a user, keeping those already
+// unlocked as they are
+func (r *CosmeticRepository) Unlock(ctx context.Context, userID string, cosmetics []string, at time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO cosmetic_unlocks (user_id, cosmetic, unlocked_at)
+		SELECT $1, cosmetic, $3 FROM unnest($2::text[]) AS cosmetic
+		ON CONFLICT (user_id, cosmetic) DO NOTHING
+	`, userID, cosmetics, at)
+	if err != nil {
+		return fmt.Errorf("failed to unlock cosmetics: %w", err)
+	}
+	return nil
+}
+
+// ListUnlocked retrieves the cosmetics a user has unlocked, ordered by
+// cosmetic
+func (r *CosmeticRepository) ListUnlocked(ctx context.Context, userID string) ([]*domain.CosmeticUnlock, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT user_id, cosmetic, equipped, unlocked_at
+		FROM cosmetic_unlocks
+		WHERE user_id = $1
+		ORDER BY cosmetic
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cosmetics: %w", err)
+	}
+	defer rows.Close()
+
+	unlocks := []*domain.CosmeticUnlock{}
+	for rows.Next() {
+		var unlock domain.CosmeticUnlock
+		if err := rows.Scan(&unlock.UserID, &unlock.Cosmetic, &unlock.Equipped, &unlock.UnlockedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan cosmetic: %w", err)
+		}
+		unlocks = append(unlocks, &unlock)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cosmetics: %w", err)
+	}
+
+	return unlocks, nil
+}
+
+// Equip wears a cosmetic, taking off the others of its kind
+func (r *CosmeticRepository) Equip(ctx context.Context, userID string, cosmetic string, kindCosmetics []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if cosmetic != "" {
+		var unlocked bool
+		if err := tx.QueryRow(ctx, `
+			SELECT EXISTS (SELECT 1 FROM cosmetic_unlocks WHERE user_id = $1 AND cosmetic = $2)
+		`, userID, cosmetic).Scan(&unlocked); err != nil {
+			return fmt.Errorf("failed to check cosmetic: %w", err)
+		}
+		if !unlocked {
+			return domain.ErrCosmeticLocked
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE cosmetic_unlocks SET equipped = (cosmetic = $2)
+		WHERE user_id = $1 AND cosmetic = ANY($3)
+	`, userID, cosmetic, kindCosmetics); err != nil {
+		return fmt.Errorf("failed to equip cosmetic: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
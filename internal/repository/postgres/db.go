@@ -4,13 +4,49 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// NewDB creates a new database connection pool
-func NewDB() (*pgxpool.Pool, error) {
+// querier is implemented by both pools and transactions, so queries can run
+// on their own or as part of a larger transaction
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// queryExecModes maps the names POSTGRES_QUERY_EXEC_MODE accepts to modes.
+// Poolers that do not support prepared statements need describe_exec, exec
+// or simple_protocol.
+var queryExecModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
+
+// DBOption configures a database connection pool
+type DBOption func(*queryTracer)
+
+// WithoutQueryTimeout lets queries run for as long as they take, for
+// maintenance such as migrations and imports
+func WithoutQueryTimeout() DBOption {
+	return func(t *queryTracer) {
+		t.timeout = 0
+	}
+}
+
+// NewDB creates a new database connection pool. Every query is bounded by
+// POSTGRES_QUERY_TIMEOUT, and queries slower than
+// POSTGRES_SLOW_QUERY_THRESHOLD are logged; either is disabled by setting it
+// to 0. Statements are prepared and cached per connection according to
+// POSTGRES_QUERY_EXEC_MODE and POSTGRES_STATEMENT_CACHE_CAPACITY.
+func NewDB(opts ...DBOption) (*pgxpool.Pool, error) {
 	// Get database connection parameters from environment variables
 	host := getEnv("POSTGRES_HOST", "localhost")
 	port := getEnv("POSTGRES_PORT", "5432")
@@ -35,6 +71,34 @@ func NewDB() (*pgxpool.Pool, error) {
 	config.MaxConnIdleTime = 30 * time.Minute
 	config.HealthCheckPeriod = time.Minute
 
+	// Configure statement caching
+	execMode, ok := queryExecModes[getEnv("POSTGRES_QUERY_EXEC_MODE", "cache_statement")]
+	if !ok {
+		return nil, fmt.Errorf("invalid POSTGRES_QUERY_EXEC_MODE %q", os.Getenv("POSTGRES_QUERY_EXEC_MODE"))
+	}
+	cacheCapacity, err := strconv.Atoi(getEnv("POSTGRES_STATEMENT_CACHE_CAPACITY", "512"))
+	if err != nil || cacheCapacity < 0 {
+		return nil, fmt.Errorf("invalid POSTGRES_STATEMENT_CACHE_CAPACITY %q", os.Getenv("POSTGRES_STATEMENT_CACHE_CAPACITY"))
+	}
+	config.ConnConfig.DefaultQueryExecMode = execMode
+	config.ConnConfig.StatementCacheCapacity = cacheCapacity
+	config.ConnConfig.DescriptionCacheCapacity = cacheCapacity
+
+	// Bound and log queries
+	timeout, err := time.ParseDuration(getEnv("POSTGRES_QUERY_TIMEOUT", "10s"))
+	if err != nil || timeout < 0 {
+		return nil, fmt.Errorf("invalid POSTGRES_QUERY_TIMEOUT %q", os.Getenv("POSTGRES_QUERY_TIMEOUT"))
+	}
+	slowThreshold, err := time.ParseDuration(getEnv("POSTGRES_SLOW_QUERY_THRESHOLD", "500ms"))
+	if err != nil || slowThreshold < 0 {
+		return nil, fmt.Errorf("invalid POSTGRES_SLOW_QUERY_THRESHOLD %q", os.Getenv("POSTGRES_SLOW_QUERY_THRESHOLD"))
+	}
+	tracer := &queryTracer{timeout: timeout, slowThreshold: slowThreshold}
+	for _, opt := range opts {
+		opt(tracer)
+	}
+	config.ConnConfig.Tracer = tracer
+
 	// Create connection pool
 	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
@@ -0,0 +1,302 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// EntitlementRepository implements the domain.EntitlementRepository interface
+type EntitlementRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewEntitlementRepository creates a new entitlement repository
+func NewEntitlementRepository(pool *pgxpool.Pool) *EntitlementRepository {
+	return &EntitlementRepository{pool: pool}
+}
+
+// SavePack creates or replaces a premium pack
+func (r *EntitlementRepository) SavePack(ctx context.Context, pack *domain.PremiumPack) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO premium_packs (id, name, categories, tenant_id, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			categories = EXCLUDED.categories,
+			tenant_id = EXCLUDED.tenant_id,
+			updated_at = EXCLUDED.updated_at
+		RETURNING created_at
+	`,
+		pack.ID,
+		pack.Name,
+		pack.Categories,
+		pack.TenantID,
+		pack.CreatedAt,
+		pack.UpdatedAt,
+	).Scan(&pack.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save premium pack: %w", err)
+	}
+	return nil
+}
+
+// GetPack retrieves a premium pack by ID
+func (r *EntitlementRepository) GetPack(ctx context.Context, id string) (*domain.PremiumPack, error) {
+	var pack domain.PremiumPack
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, name, categories, COALESCE(tenant_id, ''), created_at, updated_at
+		FROM premium_packs
+		WHERE id = $1
+	`, id).Scan(&pack.ID, &pack.Name, &pack.Categories, &pack.TenantID, &pack.CreatedAt, &pack.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrPremiumPackNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get premium pack: %w", err)
+	}
+	return &pack, nil
+}
+
+// ListPacks retrieves every premium pack, ordered by ID
+func (r *EntitlementRepository) ListPacks(ctx context.Context) ([]*domain.PremiumPack, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, name, categories, COALESCE(tenant_id, ''), created_at, updated_at
+		FROM premium_packs
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list premium packs: %w", err)
+	}
+	defer rows.Close()
+
+	packs := []*domain.PremiumPack{}
+	for rows.Next() {
+		var pack domain.PremiumPack
+		if err := rows.Scan(&pack.ID, &pack.Name, &pack.Categories, &pack.TenantID, &pack.CreatedAt, &pack.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan premium pack: %w", err)
+		}
+		packs = append(packs, &pack)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating premium packs: %w", err)
+	}
+
+	return packs, nil
+}
+
+// Grant gives a user a premium pack
+func (r *EntitlementRepository) Grant(ctx context.Context, entitlement *domain.Entitlement, entry *domain.EntitlementLogEntry) error {
+	return r.withLog(ctx, entry, func(tx pgx.Tx) error {
+		return insertEntitlement(ctx, tx, entitlement)
+	})
+}
+
+// Revoke takes a premium pack from a user
+func (r *EntitlementRepository) Revoke(ctx context.Context, userID string, packID string, entry *domain.EntitlementLogEntry) error {
+	return r.withLog(ctx, entry, func(tx pgx.Tx) error {
+		return deleteEntitlement(ctx, tx, userID, packID)
+	})
+}
+
+// Transfer moves a user's entitlement to the user of the new entitlement,
+// unless the user has gifted maxGifts packs since a time
+func (r *EntitlementRepository) Transfer(ctx context.Context, fromUserID string, entitlement *domain.Entitlement, entry *domain.EntitlementLogEntry, maxGifts int, since time.Time) error {
+	return r.withLog(ctx, entry, func(tx pgx.Tx) error {
+		// Lock the sender, so that their concurrent gifts are counted one
+		// after the other
+		if _, err := tx.Exec(ctx, `SELECT 1 FROM users WHERE id = $1 FOR UPDATE`, fromUserID); err != nil {
+			return fmt.Errorf("failed to lock gifting user: %w", err)
+		}
+
+		var gifted int
+		if err := tx.QueryRow(ctx, `
+			SELECT COUNT(*)
+			FROM entitlement_log
+			WHERE from_user_id = $1 AND action = $2 AND created_at >= $3
+		`, fromUserID, domain.EntitlementActionGift, since).Scan(&gifted); err != nil {
+			return fmt.Errorf("failed to count gifts: %w", err)
+		}
+		if gifted >= maxGifts {
+			return domain.ErrGiftLimitReached
+		}
+
+		if err := deleteEntitlement(ctx, tx, fromUserID, entitlement.PackID); err != nil {
+			return err
+		}
+		return insertEntitlement(ctx, tx, entitlement)
+	})
+}
+
+// Get retrieves a user's entitlement to a premium pack
+func (r *EntitlementRepository) Get(ctx context.Context, userID string, packID string) (*domain.Entitlement, error) {
+	var entitlement domain.Entitlement
+	err := r.pool.QueryRow(ctx, `
+		SELECT user_id, pack_id, source, granted_by, granted_at
+		FROM entitlements
+		WHERE user_id = $1 AND pack_id = $2
+	`, userID, packID).Scan(
+		&entitlement.UserID,
+		&entitlement.PackID,
+		&entitlement.Source,
+		&entitlement.GrantedBy,
+		&entitlement.GrantedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrEntitlementNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entitlement: %w", err)
+	}
+	return &entitlement, nil
+}
+
+// ListByUser retrieves the entitlements of a user, ordered by pack ID
+func (r *EntitlementRepository) ListByUser(ctx context.Context, userID string) ([]*domain.Entitlement, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT user_id, pack_id, source, granted_by, granted_at
+		FROM entitlements
+		WHERE user_id = $1
+		ORDER BY pack_id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entitlements: %w", err)
+	}
+	defer rows.Close()
+
+	entitlements := []*domain.Entitlement{}
+	for rows.Next() {
+		var entitlement domain.Entitlement
+		if err := rows.Scan(
+			&entitlement.UserID,
+			&entitlement.PackID,
+			&entitlement.Source,
+			&entitlement.GrantedBy,
+			&entitlement.GrantedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan entitlement: %w", err)
+		}
+		entitlements = append(entitlements, &entitlement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating entitlements: %w", err)
+	}
+
+	return entitlements, nil
+}
+
+// ListLog retrieves a user's most recent entitlement log entries, newest
+// first, including the packs they gifted
+func (r *EntitlementRepository) ListLog(ctx context.Context, userID string, limit int) ([]*domain.EntitlementLogEntry, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, action, user_id, from_user_id, pack_id, actor, created_at
+		FROM entitlement_log
+		WHERE user_id = $1 OR from_user_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entitlement log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*domain.EntitlementLogEntry{}
+	for rows.Next() {
+		var entry domain.EntitlementLogEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.Action,
+			&entry.UserID,
+			&entry.FromUserID,
+			&entry.PackID,
+			&entry.Actor,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan entitlement log entry: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating entitlement log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// withLog applies a change to entitlements and records its log entry in one
+// transaction
+func (r *EntitlementRepository) withLog(ctx context.Context, entry *domain.EntitlementLogEntry, change func(tx pgx.Tx) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := change(tx); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO entitlement_log (id, action, user_id, from_user_id, pack_id, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		entry.ID,
+		entry.Action,
+		entry.UserID,
+		entry.FromUserID,
+		entry.PackID,
+		entry.Actor,
+		entry.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to log entitlement change: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// insertEntitlement inserts an entitlement, returning ErrEntitlementExists
+// if the user already owns the pack
+func insertEntitlement(ctx context.Context, tx pgx.Tx, entitlement *domain.Entitlement) error {
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO entitlements (user_id, pack_id, source, granted_by, granted_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, pack_id) DO NOTHING
+	`,
+		entitlement.UserID,
+		entitlement.PackID,
+		entitlement.Source,
+		entitlement.GrantedBy,
+		entitlement.GrantedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to grant entitlement: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrEntitlementExists
+	}
+	return nil
+}
+
+// deleteEntitlement deletes an entitlement, returning ErrEntitlementNotFound
+// if the user does not own the pack
+func deleteEntitlement(ctx context.Context, tx pgx.Tx, userID string, packID string) error {
+	tag, err := tx.Exec(ctx, `DELETE FROM entitlements WHERE user_id = $1 AND pack_id = $2`, userID, packID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke entitlement: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrEntitlementNotFound
+	}
+	return nil
+}
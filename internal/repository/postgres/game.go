@@ -3,14 +3,25 @@ package postgres
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/id"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
 )
 
+// gameSortColumns maps sortable fields to their columns
+var gameSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
 // GameRepository implements the domain.GameRepository interface
 type GameRepository struct {
 	pool *pgxpool.Pool
@@ -25,9 +36,18 @@ func NewGameRepository(pool *pgxpool.Pool) *GameRepository {
 
 // Create creates a new game
 func (r *GameRepository) Create(ctx context.Context, game *domain.Game) error {
+	return createGame(ctx, r.pool, game)
+}
+
+// gamesCodeConstraint is the unique constraint on game codes
+const gamesCodeConstraint = "games_code_key"
+
+// createGame inserts a game under its own ID. Games with IDs from before
+// UUIDs are assigned one by the database.
+func createGame(ctx context.Context, q querier, game *domain.Game) error {
 	query := `
-		INSERT INTO games (code, status, players, rounds, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO games (id, code, status, players, rounds, host_id, version, created_at, updated_at)
+		VALUES (COALESCE(NULLIF($1, '')::uuid, gen_random_uuid()), $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id
 	`
 
@@ -41,29 +61,42 @@ func (r *GameRepository) Create(ctx context.Context, game *domain.Game) error {
 		return fmt.Errorf("failed to marshal rounds: %w", err)
 	}
 
+	// Keep the game's own ID unless it predates UUIDs
+	gameID := game.ID
+	if !id.IsUUID(gameID) {
+		gameID = ""
+	}
+
 	now := time.Now().UTC()
-	var id string
-	err = r.pool.QueryRow(ctx, query,
+	var assignedID string
+	err = q.QueryRow(ctx, query,
+		gameID,
 		game.Code,
 		game.Status,
 		players,
 		rounds,
+		game.HostID,
+		game.Version,
 		now,
 		now,
-	).Scan(&id)
+	).Scan(&assignedID)
 
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == gamesCodeConstraint {
+			return domain.ErrGameCodeTaken
+		}
 		return fmt.Errorf("failed to create game: %w", err)
 	}
 
-	game.ID = id
+	game.ID = assignedID
 	return nil
 }
 
 // GetByCode retrieves a game by its code
 func (r *GameRepository) GetByCode(ctx context.Context, code string) (*domain.Game, error) {
 	query := `
-		SELECT id, code, status, players, rounds, created_at, updated_at
+		SELECT id, code, status, players, rounds, host_id, version, created_at, updated_at
 		FROM games
 		WHERE code = $1
 	`
@@ -76,6 +109,8 @@ func (r *GameRepository) GetByCode(ctx context.Context, code string) (*domain.Ga
 		&game.Status,
 		&players,
 		&rounds,
+		&game.HostID,
+		&game.Version,
 		&game.CreatedAt,
 		&game.UpdatedAt,
 	)
@@ -100,10 +135,15 @@ func (r *GameRepository) GetByCode(ctx context.Context, code string) (*domain.Ga
 
 // Update updates a game
 func (r *GameRepository) Update(ctx context.Context, game *domain.Game) error {
+	return updateGame(ctx, r.pool, game)
+}
+
+// updateGame saves a game's state, matched by its code
+func updateGame(ctx context.Context, q querier, game *domain.Game) error {
 	query := `
 		UPDATE games
-		SET status = $1, players = $2, rounds = $3, updated_at = $4
-		WHERE code = $5
+		SET status = $1, players = $2, rounds = $3, version = $4, updated_at = $5
+		WHERE code = $6
 	`
 
 	players, err := json.Marshal(game.Players)
@@ -117,10 +157,11 @@ func (r *GameRepository) Update(ctx context.Context, game *domain.Game) error {
 	}
 
 	now := time.Now().UTC()
-	_, err = r.pool.Exec(ctx, query,
+	_, err = q.Exec(ctx, query,
 		game.Status,
 		players,
 		rounds,
+		game.Version,
 		now,
 		game.Code,
 	)
@@ -148,19 +189,26 @@ func (r *GameRepository) Delete(ctx context.Context, code string) error {
 }
 
 // GetByID retrieves a game by its ID
-func (r *GameRepository) GetByID(ctx context.Context, id string) (*domain.Game, error) {
+func (r *GameRepository) GetByID(ctx context.Context, gameID string) (*domain.Game, error) {
+	// Game IDs are stored as UUIDs, so nothing else can match
+	if !id.IsUUID(gameID) {
+		return nil, domain.ErrGameNotFound
+	}
+
 	var game domain.Game
 	var players, rounds []byte
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, code, status, players, rounds, created_at, updated_at, last_activity
+		SELECT id, code, status, players, rounds, host_id, version, created_at, updated_at, last_activity
 		FROM games
 		WHERE id = $1
-	`, id).Scan(
+	`, gameID).Scan(
 		&game.ID,
 		&game.Code,
 		&game.Status,
 		&players,
 		&rounds,
+		&game.HostID,
+		&game.Version,
 		&game.CreatedAt,
 		&game.UpdatedAt,
 		&game.LastActivity,
@@ -182,3 +230,82 @@ func (r *GameRepository) GetByID(ctx context.Context, id string) (*domain.Game,
 
 	return &game, nil
 }
+
+// List retrieves a page of games, optionally filtered by status
+func (r *GameRepository) List(ctx context.Context, params *pagination.Params) (pagination.Page[*domain.Game], error) {
+	var conditions []string
+	var args []any
+	if status, ok := params.Filter("status"); ok {
+		args = append(args, status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	condition, orderBy, keysetArgs, err := keyset(params, gameSortColumns, len(args))
+	if err != nil {
+		return pagination.Page[*domain.Game]{}, err
+	}
+	if condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, keysetArgs...)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, params.FetchLimit())
+
+	query := fmt.Sprintf(`
+		SELECT id, code, status, players, rounds, host_id, version, created_at, updated_at
+		FROM games
+		%s
+		%s
+		LIMIT $%d
+	`, where, orderBy, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[*domain.Game]{}, fmt.Errorf("failed to list games: %w", err)
+	}
+	defer rows.Close()
+
+	var games []*domain.Game
+	for rows.Next() {
+		var game domain.Game
+		var players, rounds []byte
+		if err := rows.Scan(
+			&game.ID,
+			&game.Code,
+			&game.Status,
+			&players,
+			&rounds,
+			&game.HostID,
+			&game.Version,
+			&game.CreatedAt,
+			&game.UpdatedAt,
+		); err != nil {
+			return pagination.Page[*domain.Game]{}, fmt.Errorf("failed to scan game: %w", err)
+		}
+
+		if err := json.Unmarshal(players, &game.Players); err != nil {
+			return pagination.Page[*domain.Game]{}, fmt.Errorf("failed to unmarshal players: %w", err)
+		}
+
+		if err := json.Unmarshal(rounds, &game.Rounds); err != nil {
+			return pagination.Page[*domain.Game]{}, fmt.Errorf("failed to unmarshal rounds: %w", err)
+		}
+
+		games = append(games, &game)
+	}
+
+	if err := rows.Err(); err != nil {
+		return pagination.Page[*domain.Game]{}, fmt.Errorf("error iterating games: %w", err)
+	}
+
+	return pagination.NewPage(games, params, func(game *domain.Game) pagination.Cursor {
+		if params.Sort == "updated_at" {
+			return timeCursor(game.UpdatedAt, game.ID)
+		}
+		return timeCursor(game.CreatedAt, game.ID)
+	}), nil
+}
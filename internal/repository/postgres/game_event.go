@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// uniqueViolation is the Postgres error code for unique constraint violations
+const uniqueViolation = "23505"
+
+// GameEventRepository implements the domain.GameEventRepository interface
+type GameEventRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewGameEventRepository creates a new game event repository
+func NewGameEventRepository(pool *pgxpool.Pool) *GameEventRepository {
+	return &GameEventRepository{
+		pool: pool,
+	}
+}
+
+// Append stores a game's events in a single transaction
+func (r *GameEventRepository) Append(ctx context.Context, events ...*domain.GameEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := appendEvents(ctx, tx, events); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// appendEvents inserts a game's events within a transaction
+func appendEvents(ctx context.Context, tx pgx.Tx, events []*domain.GameEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	// The primary key rejects duplicate sequences; this also rejects gaps
+	gameID := events[0].GameID
+	var last int
+	if err := tx.QueryRow(ctx, `
+		SELECT COALESCE(MAX(sequence), 0)
+		FROM game_events
+		WHERE game_id = $1
+	`, gameID).Scan(&last); err != nil {
+		return fmt.Errorf("failed to get last event sequence: %w", err)
+	}
+	if events[0].Sequence != last+1 {
+		return domain.ErrEventConflict
+	}
+
+	query := `
+		INSERT INTO game_events (game_id, sequence, type, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, event := range events {
+		if event.GameID != gameID {
+			return fmt.Errorf("cannot append events for multiple games at once")
+		}
+		if _, err := tx.Exec(ctx, query,
+			event.GameID,
+			event.Sequence,
+			event.Type,
+			event.Payload,
+			event.OccurredAt,
+		); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+				return domain.ErrEventConflict
+			}
+			return fmt.Errorf("failed to append event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListByGame retrieves a game's events after the given sequence, in order
+func (r *GameEventRepository) ListByGame(ctx context.Context, gameID string, afterSequence int) ([]*domain.GameEvent, error) {
+	query := `
+		SELECT game_id, sequence, type, payload, occurred_at
+		FROM game_events
+		WHERE game_id = $1 AND sequence > $2
+		ORDER BY sequence
+	`
+
+	rows, err := r.pool.Query(ctx, query, gameID, afterSequence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list game events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*domain.GameEvent{}
+	for rows.Next() {
+		var event domain.GameEvent
+		if err := rows.Scan(
+			&event.GameID,
+			&event.Sequence,
+			&event.Type,
+			&event.Payload,
+			&event.OccurredAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan game event: %w", err)
+		}
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating game events: %w", err)
+	}
+
+	return events, nil
+}
@@ -3,13 +3,21 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
 )
 
+// inviteSortColumns maps sortable fields to their columns
+var inviteSortColumns = map[string]string{
+	"created_at": "created_at",
+	"expires_at": "expires_at",
+}
+
 // GameInviteRepository implements domain.GameInviteRepository
 type GameInviteRepository struct {
 	pool *pgxpool.Pool
@@ -64,7 +72,7 @@ func (r *GameInviteRepository) GetByID(ctx context.Context, id string) (*domain.
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.New("invitation not found")
+			return nil, domain.ErrInviteNotFound
 		}
 		return nil, err
 	}
@@ -72,21 +80,34 @@ func (r *GameInviteRepository) GetByID(ctx context.Context, id string) (*domain.
 	return invite, nil
 }
 
-// GetPendingInvites retrieves all pending invitations for a user
-func (r *GameInviteRepository) GetPendingInvites(ctx context.Context, userID string) ([]*domain.GameInvite, error) {
-	query := `
+// GetPendingInvites retrieves a page of a user's unexpired pending
+// invitations
+func (r *GameInviteRepository) GetPendingInvites(ctx context.Context, userID string, params *pagination.Params) (pagination.Page[*domain.GameInvite], error) {
+	conditions := "to_user = $1 AND status = $2 AND expires_at > $3"
+	args := []any{userID, domain.InviteStatusPending, time.Now()}
+
+	condition, orderBy, keysetArgs, err := keyset(params, inviteSortColumns, len(args))
+	if err != nil {
+		return pagination.Page[*domain.GameInvite]{}, err
+	}
+	if condition != "" {
+		conditions += " AND " + condition
+		args = append(args, keysetArgs...)
+	}
+	args = append(args, params.FetchLimit())
+
+	query := fmt.Sprintf(`
 		SELECT id, game_id, from_user, to_user,
 			status, created_at, expires_at
 		FROM game_invites
-		WHERE to_user = $1
-			AND status = 'pending'
-			AND expires_at > $2
-		ORDER BY created_at DESC
-	`
+		WHERE %s
+		%s
+		LIMIT $%d
+	`, conditions, orderBy, len(args))
 
-	rows, err := r.pool.Query(ctx, query, userID, time.Now())
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return pagination.Page[*domain.GameInvite]{}, err
 	}
 	defer rows.Close()
 
@@ -103,16 +124,21 @@ func (r *GameInviteRepository) GetPendingInvites(ctx context.Context, userID str
 			&invite.ExpiresAt,
 		)
 		if err != nil {
-			return nil, err
+			return pagination.Page[*domain.GameInvite]{}, err
 		}
 		invites = append(invites, invite)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return pagination.Page[*domain.GameInvite]{}, err
 	}
 
-	return invites, nil
+	return pagination.NewPage(invites, params, func(invite *domain.GameInvite) pagination.Cursor {
+		if params.Sort == "expires_at" {
+			return timeCursor(invite.ExpiresAt, invite.ID)
+		}
+		return timeCursor(invite.CreatedAt, invite.ID)
+	}), nil
 }
 
 // UpdateStatus updates the status of a game invitation
@@ -127,9 +153,83 @@ func (r *GameInviteRepository) UpdateStatus(ctx context.Context, id string, stat
 	return err
 }
 
+// TransitionStatus updates the status of a game invitation only if it is
+// still from
+func (r *GameInviteRepository) TransitionStatus(ctx context.Context, id string, from string, to string) error {
+	query := `
+		UPDATE game_invites
+		SET status = $1
+		WHERE id = $2
+			AND status = $3
+	`
+
+	tag, err := r.pool.Exec(ctx, query, to, id, from)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInviteNotPending
+	}
+	return nil
+}
+
+// Renew makes a game invitation pending again with a new expiry
+func (r *GameInviteRepository) Renew(ctx context.Context, id string, expiresAt time.Time) error {
+	query := `
+		UPDATE game_invites
+		SET status = $1, expires_at = $2
+		WHERE id = $3
+	`
+
+	tag, err := r.pool.Exec(ctx, query, domain.InviteStatusPending, expiresAt, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInviteNotFound
+	}
+	return nil
+}
+
 // Delete deletes a game invitation
 func (r *GameInviteRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM game_invites WHERE id = $1`
 	_, err := r.pool.Exec(ctx, query, id)
 	return err
 }
+
+// MarkExpired sets pending invitations that expired before now to expired
+func (r *GameInviteRepository) MarkExpired(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		UPDATE game_invites
+		SET status = $1
+		WHERE status = $2
+			AND expires_at < $3
+	`
+
+	tag, err := r.pool.Exec(ctx, query, domain.InviteStatusExpired, domain.InviteStatusPending, now)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteExpired deletes up to limit invitations that expired before the
+// cutoff, oldest first
+func (r *GameInviteRepository) DeleteExpired(ctx context.Context, before time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM game_invites
+		WHERE id IN (
+			SELECT id FROM game_invites
+			WHERE expires_at < $1
+			ORDER BY expires_at
+			LIMIT $2
+		)
+	`
+
+	tag, err := r.pool.Exec(ctx, query, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,365 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+)
+
+// gameHistorySortColumns maps sortable fields to columns of the history query
+var gameHistorySortColumns = map[string]string{
+	"ended_at": "ended_at",
+}
+
+// GameResultRepository implements the domain.GameResultRepository interface
+type GameResultRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewGameResultRepository creates a new game result repository
+func NewGameResultRepository(pool *pgxpool.Pool) *GameResultRepository {
+	return &GameResultRepository{
+		pool: pool,
+	}
+}
+
+// saveGameResult inserts a game's result and its players' results
+func saveGameResult(ctx context.Context, tx pgx.Tx, result *domain.GameResult) error {
+	awards, err := json.Marshal(result.Awards)
+	if err != nil {
+		return fmt.Errorf("failed to marshal awards: %w", err)
+	}
+	// Results without highlights store NULL rather than a JSON null
+	var highlights []byte
+	if result.Highlights != nil {
+		if highlights, err = json.Marshal(result.Highlights); err != nil {
+			return fmt.Errorf("failed to marshal highlights: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO game_results (game_id, code, party_id, player_count, rounds_played, ended_at, awards, highlights, share_token)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, $4, $5, $6, $7, $8, NULLIF($9, ''))
+	`,
+		result.GameID,
+		result.Code,
+		result.PartyID,
+		result.PlayerCount,
+		result.RoundsPlayed,
+		result.EndedAt,
+		awards,
+		highlights,
+		result.ShareToken,
+	); err != nil {
+		return fmt.Errorf("failed to save game result: %w", err)
+	}
+
+	query := `
+		INSERT INTO game_players (
+			game_id, user_id, name, position, points,
+			players_fooled, correct_votes, fooled_by_house, likes_received, best_round, eliminated_in_round, rounds
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	for _, player := range result.Players {
+		rounds, err := json.Marshal(player.Rounds)
+		if err != nil {
+			return fmt.Errorf("failed to marshal rounds: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, query,
+			result.GameID,
+			player.PlayerID,
+			player.Name,
+			player.Position,
+			player.Points,
+			player.PlayersFooled,
+			player.CorrectVotes,
+			player.FooledByHouse,
+			player.LikesReceived,
+			player.BestRound,
+			player.EliminatedInRound,
+			rounds,
+		); err != nil {
+			return fmt.Errorf("failed to save player result: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListByUser retrieves a page of a user's past games
+func (r *GameResultRepository) ListByUser(ctx context.Context, userID string, filter domain.GameHistoryFilter, params *pagination.Params) (pagination.Page[*domain.GameHistoryEntry], error) {
+	args := []any{userID}
+	var conditions []string
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		conditions = append(conditions, fmt.Sprintf("ended_at >= $%d", len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		conditions = append(conditions, fmt.Sprintf("ended_at < $%d", len(args)))
+	}
+
+	condition, orderBy, keysetArgs, err := keyset(params, gameHistorySortColumns, len(args))
+	if err != nil {
+		return pagination.Page[*domain.GameHistoryEntry]{}, err
+	}
+	if condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, keysetArgs...)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, params.FetchLimit())
+
+	// The game ID is exposed as id for the keyset tie-breaker
+	query := fmt.Sprintf(`
+		SELECT id, code, player_count, rounds_played, ended_at, awards,
+			user_id, name, position, points, players_fooled, correct_votes, fooled_by_house, likes_received, best_round, eliminated_in_round, rounds
+		FROM (
+			SELECT r.game_id AS id, r.code, r.player_count, r.rounds_played, r.ended_at, r.awards,
+				p.user_id, p.name, p.position, p.points, p.players_fooled, p.correct_votes, p.fooled_by_house, p.likes_received, p.best_round, p.eliminated_in_round, p.rounds
+			FROM game_players p
+			JOIN game_results r ON r.game_id = p.game_id
+			WHERE p.user_id = $1
+		) history
+		%s
+		%s
+		LIMIT $%d
+	`, where, orderBy, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[*domain.GameHistoryEntry]{}, fmt.Errorf("failed to list game history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*domain.GameHistoryEntry
+	for rows.Next() {
+		var entry domain.GameHistoryEntry
+		var awards, rounds []byte
+		if err := rows.Scan(
+			&entry.GameID,
+			&entry.Code,
+			&entry.PlayerCount,
+			&entry.RoundsPlayed,
+			&entry.EndedAt,
+			&awards,
+			&entry.PlayerID,
+			&entry.Name,
+			&entry.Position,
+			&entry.Points,
+			&entry.PlayersFooled,
+			&entry.CorrectVotes,
+			&entry.FooledByHouse,
+			&entry.LikesReceived,
+			&entry.BestRound,
+			&entry.EliminatedInRound,
+			&rounds,
+		); err != nil {
+			return pagination.Page[*domain.GameHistoryEntry]{}, fmt.Errorf("failed to scan game history: %w", err)
+		}
+
+		if err := json.Unmarshal(rounds, &entry.Rounds); err != nil {
+			return pagination.Page[*domain.GameHistoryEntry]{}, fmt.Errorf("failed to unmarshal rounds: %w", err)
+		}
+
+		var gameAwards []domain.Award
+		if err := json.Unmarshal(awards, &gameAwards); err != nil {
+			return pagination.Page[*domain.GameHistoryEntry]{}, fmt.Errorf("failed to unmarshal awards: %w", err)
+		}
+		entry.Awards = domain.AwardsOf(gameAwards, entry.PlayerID)
+
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return pagination.Page[*domain.GameHistoryEntry]{}, fmt.Errorf("error iterating game history: %w", err)
+	}
+
+	return pagination.NewPage(entries, params, func(entry *domain.GameHistoryEntry) pagination.Cursor {
+		return timeCursor(entry.EndedAt, entry.GameID)
+	}), nil
+}
+
+// ListEnded retrieves the results of games that ended within the period
+func (r *GameResultRepository) ListEnded(ctx context.Context, filter domain.GameHistoryFilter) ([]*domain.GameResult, error) {
+	var args []any
+	var conditions []string
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		conditions = append(conditions, fmt.Sprintf("r.ended_at >= $%d", len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		conditions = append(conditions, fmt.Sprintf("r.ended_at < $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT r.game_id, r.code, COALESCE(r.party_id::text, ''), r.player_count, r.rounds_played, r.ended_at, r.awards, r.highlights,
+			p.user_id, p.name, p.position, p.points, p.players_fooled, p.correct_votes, p.fooled_by_house, p.likes_received, p.best_round, p.eliminated_in_round, p.rounds
+		FROM game_results r
+		JOIN game_players p ON p.game_id = r.game_id
+		%s
+		ORDER BY r.ended_at, r.game_id, p.position
+	`, where)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list game results: %w", err)
+	}
+	defer rows.Close()
+
+	return scanGameResults(rows)
+}
+
+// ListShared retrieves the results of games both users played, oldest first,
+// with only the two users' player results
+func (r *GameResultRepository) ListShared(ctx context.Context, userID string, opponentID string) ([]*domain.GameResult, error) {
+	query := `
+		SELECT r.game_id, r.code, COALESCE(r.party_id::text, ''), r.player_count, r.rounds_played, r.ended_at, r.awards, r.highlights,
+			p.user_id, p.name, p.position, p.points, p.players_fooled, p.correct_votes, p.fooled_by_house, p.likes_received, p.best_round, p.eliminated_in_round, p.rounds
+		FROM game_results r
+		JOIN game_players p ON p.game_id = r.game_id
+		WHERE p.user_id IN ($1, $2)
+			AND EXISTS (SELECT 1 FROM game_players a WHERE a.game_id = r.game_id AND a.user_id = $1)
+			AND EXISTS (SELECT 1 FROM game_players b WHERE b.game_id = r.game_id AND b.user_id = $2)
+		ORDER BY r.ended_at, r.game_id, p.position
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, opponentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shared game results: %w", err)
+	}
+	defer rows.Close()
+
+	return scanGameResults(rows)
+}
+
+// GetShared retrieves the result of the game with the given code that a
+// share token was issued for
+func (r *GameResultRepository) GetShared(ctx context.Context, code string, shareToken string) (*domain.GameResult, error) {
+	query := `
+		SELECT r.game_id, r.code, COALESCE(r.party_id::text, ''), r.player_count, r.rounds_played, r.ended_at, r.awards, r.highlights,
+			p.user_id, p.name, p.position, p.points, p.players_fooled, p.correct_votes, p.fooled_by_house, p.likes_received, p.best_round, p.eliminated_in_round, p.rounds
+		FROM game_results r
+		JOIN game_players p ON p.game_id = r.game_id
+		WHERE r.code = $1 AND r.share_token = $2
+		ORDER BY p.position
+	`
+
+	rows, err := r.pool.Query(ctx, query, code, shareToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared game result: %w", err)
+	}
+	defer rows.Close()
+
+	results, err := scanGameResults(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, domain.ErrResultNotFound
+	}
+	results[0].ShareToken = shareToken
+	return results[0], nil
+}
+
+// scanGameResults reads rows of game and player results, ordered by game,
+// into one result per game
+func scanGameResults(rows pgx.Rows) ([]*domain.GameResult, error) {
+	var results []*domain.GameResult
+	for rows.Next() {
+		var result domain.GameResult
+		var player domain.PlayerResult
+		var awards, highlights, rounds []byte
+		if err := rows.Scan(
+			&result.GameID,
+			&result.Code,
+			&result.PartyID,
+			&result.PlayerCount,
+			&result.RoundsPlayed,
+			&result.EndedAt,
+			&awards,
+			&highlights,
+			&player.PlayerID,
+			&player.Name,
+			&player.Position,
+			&player.Points,
+			&player.PlayersFooled,
+			&player.CorrectVotes,
+			&player.FooledByHouse,
+			&player.LikesReceived,
+			&player.BestRound,
+			&player.EliminatedInRound,
+			&rounds,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan game result: %w", err)
+		}
+
+		if err := json.Unmarshal(rounds, &player.Rounds); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal rounds: %w", err)
+		}
+		if err := json.Unmarshal(awards, &result.Awards); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal awards: %w", err)
+		}
+		if highlights != nil {
+			if err := json.Unmarshal(highlights, &result.Highlights); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal highlights: %w", err)
+			}
+		}
+
+		// Rows are grouped by game, one per player
+		if len(results) == 0 || results[len(results)-1].GameID != result.GameID {
+			results = append(results, &result)
+		}
+		last := results[len(results)-1]
+		last.Players = append(last.Players, player)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating game results: %w", err)
+	}
+
+	return results, nil
+}
+
+// GetCareerStats totals a user's results over every game they finished
+func (r *GameResultRepository) GetCareerStats(ctx context.Context, userID string) (*domain.CareerStats, error) {
+	var career domain.CareerStats
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE position = 1),
+			COALESCE(SUM(points), 0),
+			COALESCE(SUM(players_fooled), 0),
+			COALESCE(SUM(correct_votes), 0),
+			COALESCE(SUM(likes_received), 0)
+		FROM game_players
+		WHERE user_id = $1
+	`, userID).Scan(
+		&career.GamesPlayed,
+		&career.GamesWon,
+		&career.TotalPoints,
+		&career.PlayersFooled,
+		&career.CorrectVotes,
+		&career.LikesReceived,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get career stats: %w", err)
+	}
+	return &career, nil
+}
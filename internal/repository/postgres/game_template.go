@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// GameTemplateRepository implements the domain.GameTemplateRepository interface
+type GameTemplateRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewGameTemplateRepository creates a new game template repository
+func NewGameTemplateRepository(pool *pgxpool.Pool) *GameTemplateRepository {
+	return &GameTemplateRepository{pool: pool}
+}
+
+// Create creates a template
+func (r *GameTemplateRepository) Create(ctx context.Context, template *domain.GameTemplate) error {
+	settings, err := json.Marshal(template.Settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal template settings: %w", err)
+	}
+
+	query := `
+		INSERT INTO game_templates (id, owner_id, name, description, settings, created_at, updated_at)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7)
+	`
+
+	_, err = r.pool.Exec(ctx, query,
+		template.ID,
+		template.OwnerID,
+		template.Name,
+		template.Description,
+		settings,
+		template.CreatedAt,
+		template.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create game template: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a template by ID
+func (r *GameTemplateRepository) Get(ctx context.Context, id string) (*domain.GameTemplate, error) {
+	query := `
+		SELECT id, COALESCE(owner_id, ''), name, description, settings, created_at, updated_at
+		FROM game_templates
+		WHERE id = $1
+	`
+
+	template, err := scanGameTemplate(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrTemplateNotFound
+	}
+	return template, err
+}
+
+// ListVisible retrieves the global templates followed by the user's own,
+// each ordered by name
+func (r *GameTemplateRepository) ListVisible(ctx context.Context, userID string) ([]*domain.GameTemplate, error) {
+	query := `
+		SELECT id, COALESCE(owner_id, ''), name, description, settings, created_at, updated_at
+		FROM game_templates
+		WHERE owner_id IS NULL OR owner_id = NULLIF($1, '')
+		ORDER BY owner_id NULLS FIRST, name, id
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list game templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []*domain.GameTemplate{}
+	for rows.Next() {
+		template, err := scanGameTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating game templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// Update replaces a template's name, description and settings
+func (r *GameTemplateRepository) Update(ctx context.Context, template *domain.GameTemplate) error {
+	settings, err := json.Marshal(template.Settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal template settings: %w", err)
+	}
+
+	query := `
+		UPDATE game_templates
+		SET name = $2, description = $3, settings = $4, updated_at = $5
+		WHERE id = $1
+	`
+
+	tag, err := r.pool.Exec(ctx, query,
+		template.ID,
+		template.Name,
+		template.Description,
+		settings,
+		template.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update game template: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTemplateNotFound
+	}
+	return nil
+}
+
+// Delete removes a template
+func (r *GameTemplateRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM game_templates WHERE id = $1`
+	tag, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTemplateNotFound
+	}
+	return nil
+}
+
+// scanGameTemplate scans a template row
+func scanGameTemplate(row pgx.Row) (*domain.GameTemplate, error) {
+	var template domain.GameTemplate
+	var settings []byte
+	if err := row.Scan(
+		&template.ID,
+		&template.OwnerID,
+		&template.Name,
+		&template.Description,
+		&settings,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(settings, &template.Settings); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal template settings: %w", err)
+	}
+	return &template, nil
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+)
+
+// hallOfFameSortColumns maps sortable fields to columns
+var hallOfFameSortColumns = map[string]string{
+	"created_at": "created_at",
+}
+
+// HallOfFameRepository implements domain.HallOfFameRepository
+type HallOfFameRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewHallOfFameRepository creates a new hall of fame repository
+func NewHallOfFameRepository(pool *pgxpool.Pool) *HallOfFameRepository {
+	return &HallOfFameRepository{pool: pool}
+}
+
+// saveHallOfFame stores entries liked into the hall of fame
+func saveHallOfFame(ctx context.Context, tx pgx.Tx, entries []*domain.HallOfFameEntry) error {
+	query := `
+		INSERT INTO hall_of_fame (
+			id, game_id, category, question, correct_answer, answer,
+			author_id, author_name, likes, author_consent, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	for _, entry := range entries {
+		if _, err := tx.Exec(ctx, query,
+			entry.ID,
+			entry.GameID,
+			entry.Category,
+			entry.Question,
+			entry.CorrectAnswer,
+			entry.Answer,
+			entry.AuthorID,
+			entry.AuthorName,
+			entry.Likes,
+			entry.Consent,
+			entry.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("failed to save hall of fame entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// List retrieves a page of the entries whose authors consent to them being shown
+func (r *HallOfFameRepository) List(ctx context.Context, params *pagination.Params) (pagination.Page[*domain.HallOfFameEntry], error) {
+	conditions := []string{"author_consent"}
+	condition, orderBy, args, err := keyset(params, hallOfFameSortColumns, 0)
+	if err != nil {
+		return pagination.Page[*domain.HallOfFameEntry]{}, err
+	}
+	if condition != "" {
+		conditions = append(conditions, condition)
+	}
+	args = append(args, params.FetchLimit())
+
+	query := fmt.Sprintf(`
+		SELECT id, game_id, category, question, correct_answer, answer,
+			author_id, author_name, likes, author_consent, created_at
+		FROM hall_of_fame
+		WHERE %s
+		%s
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), orderBy, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[*domain.HallOfFameEntry]{}, fmt.Errorf("failed to list hall of fame: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*domain.HallOfFameEntry
+	for rows.Next() {
+		var entry domain.HallOfFameEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.GameID,
+			&entry.Category,
+			&entry.Question,
+			&entry.CorrectAnswer,
+			&entry.Answer,
+			&entry.AuthorID,
+			&entry.AuthorName,
+			&entry.Likes,
+			&entry.Consent,
+			&entry.CreatedAt,
+		); err != nil {
+			return pagination.Page[*domain.HallOfFameEntry]{}, fmt.Errorf("failed to scan hall of fame entry: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return pagination.Page[*domain.HallOfFameEntry]{}, fmt.Errorf("error iterating hall of fame: %w", err)
+	}
+
+	return pagination.NewPage(entries, params, func(entry *domain.HallOfFameEntry) pagination.Cursor {
+		return timeCursor(entry.CreatedAt, entry.ID)
+	}), nil
+}
+
+// SetConsent records whether a user lets their entries be shown
+func (r *HallOfFameRepository) SetConsent(ctx context.Context, userID string, consent bool) error {
+	if _, err := r.pool.Exec(ctx, `
+		UPDATE hall_of_fame SET author_consent = $2 WHERE author_id = $1
+	`, userID, consent); err != nil {
+		return fmt.Errorf("failed to update hall of fame consent: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// GameChangeStore implements the domain.GameChangeStore interface
+type GameChangeStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewGameChangeStore creates a new game change store
+func NewGameChangeStore(pool *pgxpool.Pool) *GameChangeStore {
+	return &GameChangeStore{
+		pool: pool,
+	}
+}
+
+// SaveChange writes a game's snapshot, events and outbox messages in a single transaction
+func (s *GameChangeStore) SaveChange(ctx context.Context, change *domain.GameChange) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if change.Created {
+		if err := createGame(ctx, tx, change.Game); err != nil {
+			return err
+		}
+		change.AssignGameID(change.Game.ID)
+	} else if err := updateGame(ctx, tx, change.Game); err != nil {
+		return err
+	}
+	if err := change.EncodeSnapshots(); err != nil {
+		return err
+	}
+
+	if err := appendEvents(ctx, tx, change.Events); err != nil {
+		return err
+	}
+
+	if change.Result != nil {
+		if err := saveGameResult(ctx, tx, change.Result); err != nil {
+			return err
+		}
+	}
+
+	if err := addCategoryStats(ctx, tx, change.CategoryStats); err != nil {
+		return err
+	}
+
+	if change.Party != nil {
+		if err := saveScoreboard(ctx, tx, change.Party); err != nil {
+			return err
+		}
+	}
+
+	if err := saveHallOfFame(ctx, tx, change.HallOfFame); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO outbox (game_id, player_id, audience, type, payload, created_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6)
+		RETURNING id
+	`
+	for _, msg := range change.Messages {
+		if err := tx.QueryRow(ctx, query,
+			msg.GameID,
+			msg.PlayerID,
+			msg.Audience,
+			msg.Type,
+			msg.Payload,
+			msg.CreatedAt,
+		).Scan(&msg.ID); err != nil {
+			return fmt.Errorf("failed to queue outbox message: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// OutboxRepository implements the domain.OutboxRepository interface
+type OutboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(pool *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{
+		pool: pool,
+	}
+}
+
+// ProcessPending publishes the oldest queued messages. The rows stay locked
+// until publish returns, so concurrent relays take turns and delivery order
+// is preserved.
+func (r *OutboxRepository) ProcessPending(ctx context.Context, limit int, publish func(messages []*domain.OutboxMessage) error) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, game_id, COALESCE(player_id, ''), COALESCE(audience, ''), type, payload, created_at
+		FROM outbox
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE
+	`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch outbox messages: %w", err)
+	}
+
+	var messages []*domain.OutboxMessage
+	var ids []int64
+	for rows.Next() {
+		var msg domain.OutboxMessage
+		if err := rows.Scan(&msg.ID, &msg.GameID, &msg.PlayerID, &msg.Audience, &msg.Type, &msg.Payload, &msg.CreatedAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan outbox message: %w", err)
+		}
+		messages = append(messages, &msg)
+		ids = append(ids, msg.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating outbox messages: %w", err)
+	}
+
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	if err := publish(messages); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM outbox WHERE id = ANY($1)`, ids); err != nil {
+		return 0, fmt.Errorf("failed to delete published messages: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(messages), nil
+}
@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+)
+
+// keyset builds keyset pagination clauses over a timestamp sort column with
+// the row ID as a tie-breaker. The returned condition uses placeholders
+// starting at argOffset+1 and is empty on the first page.
+func keyset(params *pagination.Params, columns map[string]string, argOffset int) (condition string, orderBy string, args []any, err error) {
+	column, ok := columns[params.Sort]
+	if !ok {
+		return "", "", nil, fmt.Errorf("%w: %s", pagination.ErrInvalidSort, params.Sort)
+	}
+
+	direction, comparator := "DESC", "<"
+	if params.Order == pagination.OrderAsc {
+		direction, comparator = "ASC", ">"
+	}
+	orderBy = fmt.Sprintf("ORDER BY %s %s, id::text %s", column, direction, direction)
+
+	if params.Cursor != nil {
+		value, err := time.Parse(time.RFC3339Nano, params.Cursor.Value)
+		if err != nil {
+			return "", "", nil, pagination.ErrInvalidCursor
+		}
+		condition = fmt.Sprintf("(%s, id::text) %s ($%d, $%d)", column, comparator, argOffset+1, argOffset+2)
+		args = []any{value, params.Cursor.ID}
+	}
+
+	return condition, orderBy, args, nil
+}
+
+// timeCursor builds a cursor for a row sorted by a timestamp column
+func timeCursor(value time.Time, id string) pagination.Cursor {
+	return pagination.Cursor{Value: value.Format(time.RFC3339Nano), ID: id}
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// PartyRepository implements the domain.PartyRepository interface
+type PartyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPartyRepository creates a new party repository
+func NewPartyRepository(pool *pgxpool.Pool) *PartyRepository {
+	return &PartyRepository{pool: pool}
+}
+
+// Create creates a party with an empty scoreboard
+func (r *PartyRepository) Create(ctx context.Context, party *domain.Party) error {
+	query := `
+		INSERT INTO parties (id, host_id, games, created_at, updated_at)
+		VALUES ($1, $2, 0, $3, $3)
+	`
+
+	if _, err := r.pool.Exec(ctx, query, party.ID, party.HostID, party.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create party: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a party's scoreboard
+func (r *PartyRepository) Get(ctx context.Context, id string) (*domain.PartyScoreboard, error) {
+	query := `
+		SELECT id, host_id, games, created_at, updated_at
+		FROM parties
+		WHERE id = $1
+	`
+
+	var board domain.PartyScoreboard
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&board.ID,
+		&board.HostID,
+		&board.Games,
+		&board.CreatedAt,
+		&board.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrPartyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get party: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT player_id, name, games, wins, points, streak, best_streak
+		FROM party_standings
+		WHERE party_id = $1
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get party standings: %w", err)
+	}
+	defer rows.Close()
+
+	board.Standings = []domain.PartyStanding{}
+	for rows.Next() {
+		var s domain.PartyStanding
+		if err := rows.Scan(&s.PlayerID, &s.Name, &s.Games, &s.Wins, &s.Points, &s.Streak, &s.BestStreak); err != nil {
+			return nil, fmt.Errorf("failed to scan party standing: %w", err)
+		}
+		board.Standings = append(board.Standings, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating party standings: %w", err)
+	}
+
+	board.Rank()
+	return &board, nil
+}
+
+// saveScoreboard writes a party's scoreboard within a transaction
+func saveScoreboard(ctx context.Context, tx pgx.Tx, board *domain.PartyScoreboard) error {
+	tag, err := tx.Exec(ctx, `
+		UPDATE parties SET games = $2, updated_at = $3
+		WHERE id = $1
+	`, board.ID, board.Games, board.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update party: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPartyNotFound
+	}
+
+	query := `
+		INSERT INTO party_standings (party_id, player_id, name, games, wins, points, streak, best_streak)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (party_id, player_id) DO UPDATE SET
+			name = EXCLUDED.name,
+			games = EXCLUDED.games,
+			wins = EXCLUDED.wins,
+			points = EXCLUDED.points,
+			streak = EXCLUDED.streak,
+			best_streak = EXCLUDED.best_streak
+	`
+	for _, s := range board.Standings {
+		if _, err := tx.Exec(ctx, query,
+			board.ID,
+			s.PlayerID,
+			s.Name,
+			s.Games,
+			s.Wins,
+			s.Points,
+			s.Streak,
+			s.BestStreak,
+		); err != nil {
+			return fmt.Errorf("failed to save party standing: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// PromoRepository implements the domain.PromoRepository interface
+type PromoRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPromoRepository creates a new promo repository
+func NewPromoRepository(pool *pgxpool.Pool) *PromoRepository {
+	return &PromoRepository{pool: pool}
+}
+
+// promoBatchColumns are the columns scanned by scanPromoBatch
+const promoBatchColumns = `id, name, pack_id, cosmetic, codes, max_redemptions, expires_at, created_by, created_at`
+
+// CreateBatch creates a batch with its codes
+func (r *PromoRepository) CreateBatch(ctx context.Context, batch *domain.PromoBatch, codes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO promo_batches (`+promoBatchColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`,
+		batch.ID,
+		batch.Name,
+		batch.Reward.PackID,
+		batch.Reward.Cosmetic,
+		batch.Codes,
+		batch.MaxRedemptions,
+		batch.ExpiresAt,
+		batch.CreatedBy,
+		batch.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to create promo batch: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO promo_codes (code, batch_id)
+		SELECT code, $2 FROM unnest($1::text[]) AS code
+	`, codes, batch.ID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return domain.ErrPromoCodeExists
+		}
+		return fmt.Errorf("failed to create promo codes: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetBatch retrieves a batch by ID
+func (r *PromoRepository) GetBatch(ctx context.Context, id string) (*domain.PromoBatch, error) {
+	query := `SELECT ` + promoBatchColumns + ` FROM promo_batches WHERE id = $1`
+
+	batch, err := scanPromoBatch(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrPromoBatchNotFound
+	}
+	return batch, err
+}
+
+// ListBatches retrieves every batch, newest first
+func (r *PromoRepository) ListBatches(ctx context.Context) ([]*domain.PromoBatch, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+promoBatchColumns+` FROM promo_batches ORDER BY created_at DESC, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list promo batches: %w", err)
+	}
+	defer rows.Close()
+
+	batches := []*domain.PromoBatch{}
+	for rows.Next() {
+		batch, err := scanPromoBatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating promo batches: %w", err)
+	}
+
+	return batches, nil
+}
+
+// GetCode retrieves a promo code
+func (r *PromoRepository) GetCode(ctx context.Context, code string) (*domain.PromoCode, error) {
+	var promo domain.PromoCode
+	err := r.pool.QueryRow(ctx, `
+		SELECT code, batch_id, redemptions FROM promo_codes WHERE code = $1
+	`, code).Scan(&promo.Code, &promo.BatchID, &promo.Redemptions)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrPromoCodeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promo code: %w", err)
+	}
+	return &promo, nil
+}
+
+// ListCodes retrieves a batch's codes
+func (r *PromoRepository) ListCodes(ctx context.Context, batchID string) ([]*domain.PromoCode, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT code, batch_id, redemptions FROM promo_codes WHERE batch_id = $1 ORDER BY code
+	`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list promo codes: %w", err)
+	}
+	defer rows.Close()
+
+	codes := []*domain.PromoCode{}
+	for rows.Next() {
+		var promo domain.PromoCode
+		if err := rows.Scan(&promo.Code, &promo.BatchID, &promo.Redemptions); err != nil {
+			return nil, fmt.Errorf("failed to scan promo code: %w", err)
+		}
+		codes = append(codes, &promo)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating promo codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// Redeem records a redemption and counts it against its code
+func (r *PromoRepository) Redeem(ctx context.Context, redemption *domain.PromoRedemption, maxRedemptions int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO promo_redemptions (batch_id, code, user_id, pack_id, cosmetic, redeemed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`,
+		redemption.BatchID,
+		redemption.Code,
+		redemption.UserID,
+		redemption.Reward.PackID,
+		redemption.Reward.Cosmetic,
+		redemption.RedeemedAt,
+	); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return domain.ErrPromoAlreadyRedeemed
+		}
+		return fmt.Errorf("failed to record promo redemption: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE promo_codes SET redemptions = redemptions + 1
+		WHERE code = $1 AND redemptions < $2
+	`, redemption.Code, maxRedemptions)
+	if err != nil {
+		return fmt.Errorf("failed to count promo redemption: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPromoCodeExhausted
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CancelRedemption removes a redemption and hands its use of the code back
+func (r *PromoRepository) CancelRedemption(ctx context.Context, redemption *domain.PromoRedemption) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		DELETE FROM promo_redemptions WHERE batch_id = $1 AND user_id = $2
+	`, redemption.BatchID, redemption.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel promo redemption: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE promo_codes SET redemptions = redemptions - 1
+		WHERE code = $1 AND redemptions > 0
+	`, redemption.Code); err != nil {
+		return fmt.Errorf("failed to uncount promo redemption: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ListRedemptionsByUser retrieves a user's redemptions, newest first
+func (r *PromoRepository) ListRedemptionsByUser(ctx context.Context, userID string) ([]*domain.PromoRedemption, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT batch_id, code, user_id, pack_id, cosmetic, redeemed_at
+		FROM promo_redemptions
+		WHERE user_id = $1
+		ORDER BY redeemed_at DESC, batch_id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list promo redemptions: %w", err)
+	}
+	defer rows.Close()
+
+	redemptions := []*domain.PromoRedemption{}
+	for rows.Next() {
+		var redemption domain.PromoRedemption
+		if err := rows.Scan(
+			&redemption.BatchID,
+			&redemption.Code,
+			&redemption.UserID,
+			&redemption.Reward.PackID,
+			&redemption.Reward.Cosmetic,
+			&redemption.RedeemedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan promo redemption: %w", err)
+		}
+		redemptions = append(redemptions, &redemption)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating promo redemptions: %w", err)
+	}
+
+	return redemptions, nil
+}
+
+// scanPromoBatch scans a promo batch row
+func scanPromoBatch(row pgx.Row) (*domain.PromoBatch, error) {
+	var batch domain.PromoBatch
+	if err := row.Scan(
+		&batch.ID,
+		&batch.Name,
+		&batch.Reward.PackID,
+		&batch.Reward.Cosmetic,
+		&batch.Codes,
+		&batch.MaxRedemptions,
+		&batch.ExpiresAt,
+		&batch.CreatedBy,
+		&batch.CreatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan promo batch: %w", err)
+	}
+	return &batch, nil
+}
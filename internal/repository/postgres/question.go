@@ -2,56 +2,112 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/id"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+	"github.com/zizouhuweidi/dahaa/internal/random"
 )
 
+// questionSortColumns maps sortable fields to their columns
+var questionSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// randomQuestionFilter matches the questions GetRandomQuestion draws from:
+// live ones in a category and language ($1, $2), with one of the allowed
+// ratings ($3), not among the excluded IDs ($4) and released no later than
+// a pack version ($5), any release when it is 0
+const randomQuestionFilter = `deleted_at IS NULL AND category = $1 AND language = $2 AND rating = ANY($3) AND NOT (id = ANY($4::uuid[])) AND pack_version > 0 AND ($5 = 0 OR pack_version <= $5)`
+
+// maxRandomQuestionAttempts bounds the draws retried when questions are
+// deleted between counting and drawing
+const maxRandomQuestionAttempts = 3
+
 // QuestionRepository implements the domain.QuestionRepository interface
 type QuestionRepository struct {
 	pool *pgxpool.Pool
+	rng  random.Source
 }
 
-// NewQuestionRepository creates a new question repository
+// NewQuestionRepository creates a new question repository, drawing random
+// questions with math/rand's shared generator
 func NewQuestionRepository(pool *pgxpool.Pool) *QuestionRepository {
 	return &QuestionRepository{
 		pool: pool,
+		rng:  random.Global(),
 	}
 }
 
-// GetRandomQuestion retrieves a random question from a category
-func (r *QuestionRepository) GetRandomQuestion(ctx context.Context, category string) (*domain.Question, error) {
-	var question domain.Question
-	err := r.pool.QueryRow(ctx, `
-		SELECT id, text, answer, category, created_at, updated_at
-		FROM questions
-		WHERE category = $1
-		ORDER BY RANDOM()
-		LIMIT 1
-	`, category).Scan(
-		&question.ID,
-		&question.Text,
-		&question.Answer,
-		&question.Category,
-		&question.CreatedAt,
-		&question.UpdatedAt,
-	)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("no questions found for category: %s", category)
+// GetRandomQuestion retrieves a random question from a category in a
+// language, released in packVersion or earlier, rated no edgier than
+// maxRating and other than the excluded questions. Rather than sorting the category randomly, it counts the
+// matching questions and skips to a random offset, both of which the
+// selection index serves. Scan order is arbitrary but fixed within a query,
+// so a uniform offset draws each question with equal chance.
+func (r *QuestionRepository) GetRandomQuestion(ctx context.Context, category string, language string, packVersion int, maxRating domain.ContentRating, exclude []string) (*domain.Question, error) {
+	if exclude == nil {
+		exclude = []string{}
+	}
+	args := []any{category, language, ratingsUpTo(maxRating), exclude, packVersion}
+
+	for attempt := 1; attempt <= maxRandomQuestionAttempts; attempt++ {
+		var count int
+		if err := r.pool.QueryRow(ctx, `
+			SELECT COUNT(*)
+			FROM questions
+			WHERE `+randomQuestionFilter, args...).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to count questions: %w", err)
 		}
-		return nil, fmt.Errorf("failed to get random question: %w", err)
+		if count == 0 {
+			break
+		}
+
+		var question domain.Question
+		err := r.pool.QueryRow(ctx, `
+			SELECT id, text, answer, category, difficulty, language, rating, pack_version, created_at, updated_at
+			FROM questions
+			WHERE `+randomQuestionFilter+`
+			OFFSET $6
+			LIMIT 1
+		`, append(args, r.rng.Intn(count))...).Scan(
+			&question.ID,
+			&question.Text,
+			&question.Answer,
+			&question.Category,
+			&question.Difficulty,
+			&question.Language,
+			&question.Rating,
+			&question.PackVersion,
+			&question.CreatedAt,
+			&question.UpdatedAt,
+		)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Questions were deleted since they were counted
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get random question: %w", err)
+		}
+		return &question, nil
 	}
-	return &question, nil
+
+	return nil, fmt.Errorf("%w: no %s questions for category %s", domain.ErrQuestionNotFound, language, category)
 }
 
-// GetCategories retrieves all available categories
+// GetCategories retrieves all categories with released questions
 func (r *QuestionRepository) GetCategories(ctx context.Context) ([]string, error) {
 	query := `
 		SELECT DISTINCT category
 		FROM questions
+		WHERE deleted_at IS NULL AND pack_version > 0
 		ORDER BY category
 	`
 
@@ -77,11 +133,46 @@ func (r *QuestionRepository) GetCategories(ctx context.Context) ([]string, error
 	return categories, nil
 }
 
+// GetCategoryCounts retrieves all categories with their counts of released
+// questions rated no edgier than maxRating, once per language the category
+// has such questions in
+func (r *QuestionRepository) GetCategoryCounts(ctx context.Context, maxRating domain.ContentRating) ([]domain.CategoryCount, error) {
+	query := `
+		SELECT category, language, COUNT(*)
+		FROM questions
+		WHERE deleted_at IS NULL AND pack_version > 0 AND rating = ANY($1)
+		GROUP BY category, language
+		ORDER BY category, language
+	`
+
+	rows, err := r.pool.Query(ctx, query, ratingsUpTo(maxRating))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category counts: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []domain.CategoryCount
+	for rows.Next() {
+		var count domain.CategoryCount
+		if err := rows.Scan(&count.Category, &count.Language, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan category count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating category counts: %w", err)
+	}
+
+	return counts, nil
+}
+
 // GetDifficulties retrieves all available difficulty levels
 func (r *QuestionRepository) GetDifficulties(ctx context.Context) ([]string, error) {
 	query := `
 		SELECT DISTINCT difficulty
 		FROM questions
+		WHERE deleted_at IS NULL
 		ORDER BY difficulty
 	`
 
@@ -107,22 +198,31 @@ func (r *QuestionRepository) GetDifficulties(ctx context.Context) ([]string, err
 	return difficulties, nil
 }
 
-// GetByID retrieves a question by its ID
-func (r *QuestionRepository) GetByID(ctx context.Context, id string) (*domain.Question, error) {
+// GetByID retrieves a question by its ID, even if it was deleted
+func (r *QuestionRepository) GetByID(ctx context.Context, questionID string) (*domain.Question, error) {
+	if !id.IsUUID(questionID) {
+		return nil, domain.ErrQuestionNotFound
+	}
+
 	var question domain.Question
 	var fillerAnswers []string
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, text, answer, category, filler_answers, created_at, updated_at
+		SELECT id, text, answer, category, difficulty, language, rating, pack_version, filler_answers, created_at, updated_at, deleted_at
 		FROM questions
 		WHERE id = $1
-	`, id).Scan(
+	`, questionID).Scan(
 		&question.ID,
 		&question.Text,
 		&question.Answer,
 		&question.Category,
+		&question.Difficulty,
+		&question.Language,
+		&question.Rating,
+		&question.PackVersion,
 		&fillerAnswers,
 		&question.CreatedAt,
 		&question.UpdatedAt,
+		&question.DeletedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -137,39 +237,56 @@ func (r *QuestionRepository) GetByID(ctx context.Context, id string) (*domain.Qu
 // CreateQuestion creates a new question
 func (r *QuestionRepository) CreateQuestion(ctx context.Context, question *domain.Question) error {
 	query := `
-		INSERT INTO questions (text, answer, category, filler_answers)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, created_at, updated_at
+		INSERT INTO questions (text, answer, category, filler_answers, difficulty, language, rating)
+		VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'medium'), COALESCE(NULLIF($6, ''), 'en'), COALESCE(NULLIF($7, ''), 'family'))
+		RETURNING id, difficulty, language, rating, created_at, updated_at
 	`
 	return r.pool.QueryRow(ctx, query,
 		question.Text,
 		question.Answer,
 		question.Category,
 		question.FillerAnswers,
-	).Scan(&question.ID, &question.CreatedAt, &question.UpdatedAt)
+		question.Difficulty,
+		question.Language,
+		question.Rating,
+	).Scan(&question.ID, &question.Difficulty, &question.Language, &question.Rating, &question.CreatedAt, &question.UpdatedAt)
 }
 
 // UpdateQuestion updates an existing question
 func (r *QuestionRepository) UpdateQuestion(ctx context.Context, question *domain.Question) error {
 	query := `
 		UPDATE questions
-		SET text = $1, answer = $2, category = $3, filler_answers = $4, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $5
-		RETURNING updated_at
+		SET text = $1, answer = $2, category = $3, filler_answers = $4,
+			difficulty = COALESCE(NULLIF($5, ''), difficulty),
+			language = COALESCE(NULLIF($6, ''), language),
+			rating = COALESCE(NULLIF($7, ''), rating), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8
+		RETURNING difficulty, language, rating, updated_at
 	`
 	return r.pool.QueryRow(ctx, query,
 		question.Text,
 		question.Answer,
 		question.Category,
 		question.FillerAnswers,
+		question.Difficulty,
+		question.Language,
+		question.Rating,
 		question.ID,
-	).Scan(&question.UpdatedAt)
+	).Scan(&question.Difficulty, &question.Language, &question.Rating, &question.UpdatedAt)
 }
 
-// DeleteQuestion deletes a question
-func (r *QuestionRepository) DeleteQuestion(ctx context.Context, id string) error {
-	query := `DELETE FROM questions WHERE id = $1`
-	result, err := r.pool.Exec(ctx, query, id)
+// DeleteQuestion soft-deletes a question
+func (r *QuestionRepository) DeleteQuestion(ctx context.Context, questionID string) error {
+	if !id.IsUUID(questionID) {
+		return domain.ErrQuestionNotFound
+	}
+
+	query := `
+		UPDATE questions
+		SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	result, err := r.pool.Exec(ctx, query, questionID)
 	if err != nil {
 		return fmt.Errorf("failed to delete question: %w", err)
 	}
@@ -179,6 +296,27 @@ func (r *QuestionRepository) DeleteQuestion(ctx context.Context, id string) erro
 	return nil
 }
 
+// RestoreQuestion restores a deleted question
+func (r *QuestionRepository) RestoreQuestion(ctx context.Context, questionID string) error {
+	if !id.IsUUID(questionID) {
+		return domain.ErrQuestionNotFound
+	}
+
+	query := `
+		UPDATE questions
+		SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+	result, err := r.pool.Exec(ctx, query, questionID)
+	if err != nil {
+		return fmt.Errorf("failed to restore question: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrQuestionNotFound
+	}
+	return nil
+}
+
 // BulkCreateQuestions creates multiple questions in a single transaction
 func (r *QuestionRepository) BulkCreateQuestions(ctx context.Context, questions []*domain.Question) error {
 	tx, err := r.pool.Begin(ctx)
@@ -188,9 +326,9 @@ func (r *QuestionRepository) BulkCreateQuestions(ctx context.Context, questions
 	defer tx.Rollback(ctx)
 
 	query := `
-		INSERT INTO questions (text, answer, category, filler_answers)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, created_at, updated_at
+		INSERT INTO questions (text, answer, category, filler_answers, difficulty, language, rating)
+		VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'medium'), COALESCE(NULLIF($6, ''), 'en'), COALESCE(NULLIF($7, ''), 'family'))
+		RETURNING id, difficulty, language, rating, created_at, updated_at
 	`
 
 	for _, question := range questions {
@@ -199,7 +337,10 @@ func (r *QuestionRepository) BulkCreateQuestions(ctx context.Context, questions
 			question.Answer,
 			question.Category,
 			question.FillerAnswers,
-		).Scan(&question.ID, &question.CreatedAt, &question.UpdatedAt)
+			question.Difficulty,
+			question.Language,
+			question.Rating,
+		).Scan(&question.ID, &question.Difficulty, &question.Language, &question.Rating, &question.CreatedAt, &question.UpdatedAt)
 		if err != nil {
 			return fmt.Errorf("failed to create question: %w", err)
 		}
@@ -212,6 +353,75 @@ func (r *QuestionRepository) BulkCreateQuestions(ctx context.Context, questions
 	return nil
 }
 
+// RenameCategory moves every question in a category to another in a single
+// transaction, merging the two if merge is set. Players' stats in the
+// category move along with its questions, as do its deleted questions, so
+// restoring them returns them to the renamed category. Only live questions
+// are counted.
+func (r *QuestionRepository) RenameCategory(ctx context.Context, from string, to string, merge bool) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if !merge {
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM questions WHERE category = $1 AND deleted_at IS NULL)`, to).Scan(&exists); err != nil {
+			return 0, fmt.Errorf("failed to check category: %w", err)
+		}
+		if exists {
+			return 0, domain.ErrCategoryExists
+		}
+	}
+
+	var moved int
+	if err := tx.QueryRow(ctx, `
+		WITH moved AS (
+			UPDATE questions
+			SET category = $2, updated_at = CURRENT_TIMESTAMP
+			WHERE category = $1
+			RETURNING deleted_at
+		)
+		SELECT COUNT(*) FROM moved WHERE deleted_at IS NULL
+	`, from, to).Scan(&moved); err != nil {
+		return 0, fmt.Errorf("failed to rename category: %w", err)
+	}
+	if moved == 0 {
+		return 0, domain.ErrCategoryNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO category_stats (
+			user_id, category, rounds, votes_cast, correct_votes,
+			players_fooled, fooled_by_house, opponent_votes, updated_at
+		)
+		SELECT user_id, $2, rounds, votes_cast, correct_votes,
+			players_fooled, fooled_by_house, opponent_votes, updated_at
+		FROM category_stats
+		WHERE category = $1
+		ON CONFLICT (user_id, category) DO UPDATE SET
+			rounds = category_stats.rounds + EXCLUDED.rounds,
+			votes_cast = category_stats.votes_cast + EXCLUDED.votes_cast,
+			correct_votes = category_stats.correct_votes + EXCLUDED.correct_votes,
+			players_fooled = category_stats.players_fooled + EXCLUDED.players_fooled,
+			fooled_by_house = category_stats.fooled_by_house + EXCLUDED.fooled_by_house,
+			opponent_votes = category_stats.opponent_votes + EXCLUDED.opponent_votes,
+			updated_at = GREATEST(category_stats.updated_at, EXCLUDED.updated_at)
+	`, from, to); err != nil {
+		return 0, fmt.Errorf("failed to merge category stats: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM category_stats WHERE category = $1`, from); err != nil {
+		return 0, fmt.Errorf("failed to delete category stats: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return moved, nil
+}
+
 // ValidateQuestion validates a question's data
 func (r *QuestionRepository) ValidateQuestion(ctx context.Context, question *domain.Question) error {
 	if question.Text == "" {
@@ -231,3 +441,177 @@ func (r *QuestionRepository) ValidateQuestion(ctx context.Context, question *dom
 	}
 	return nil
 }
+
+// List retrieves a page of questions, optionally filtered by category, difficulty, language and rating
+func (r *QuestionRepository) List(ctx context.Context, params *pagination.Params) (pagination.Page[*domain.Question], error) {
+	conditions := []string{"deleted_at IS NULL"}
+	var args []any
+	for _, filter := range []string{"category", "difficulty", "language", "rating"} {
+		if value, ok := params.Filter(filter); ok {
+			args = append(args, value)
+			conditions = append(conditions, fmt.Sprintf("%s = $%d", filter, len(args)))
+		}
+	}
+
+	condition, orderBy, keysetArgs, err := keyset(params, questionSortColumns, len(args))
+	if err != nil {
+		return pagination.Page[*domain.Question]{}, err
+	}
+	if condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, keysetArgs...)
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+	args = append(args, params.FetchLimit())
+
+	query := fmt.Sprintf(`
+		SELECT id, text, answer, category, difficulty, language, rating, pack_version, filler_answers, created_at, updated_at
+		FROM questions
+		%s
+		%s
+		LIMIT $%d
+	`, where, orderBy, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[*domain.Question]{}, fmt.Errorf("failed to list questions: %w", err)
+	}
+	defer rows.Close()
+
+	var questions []*domain.Question
+	for rows.Next() {
+		var question domain.Question
+		if err := rows.Scan(
+			&question.ID,
+			&question.Text,
+			&question.Answer,
+			&question.Category,
+			&question.Difficulty,
+			&question.Language,
+			&question.Rating,
+			&question.PackVersion,
+			&question.FillerAnswers,
+			&question.CreatedAt,
+			&question.UpdatedAt,
+		); err != nil {
+			return pagination.Page[*domain.Question]{}, fmt.Errorf("failed to scan question: %w", err)
+		}
+		questions = append(questions, &question)
+	}
+
+	if err := rows.Err(); err != nil {
+		return pagination.Page[*domain.Question]{}, fmt.Errorf("error iterating questions: %w", err)
+	}
+
+	return pagination.NewPage(questions, params, func(question *domain.Question) pagination.Cursor {
+		if params.Sort == "updated_at" {
+			return timeCursor(question.UpdatedAt, question.ID)
+		}
+		return timeCursor(question.CreatedAt, question.ID)
+	}), nil
+}
+
+// LatestPackVersion returns the latest released version of a language's pack
+func (r *QuestionRepository) LatestPackVersion(ctx context.Context, language string) (int, error) {
+	var version int
+	if err := r.pool.QueryRow(ctx, `
+		SELECT COALESCE(MAX(version), 0)
+		FROM pack_releases
+		WHERE language = $1
+	`, language).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to get latest pack version: %w", err)
+	}
+	return version, nil
+}
+
+// PublishPack releases a language's unreleased live questions as the next
+// version of its pack. Releases of the same pack are serialized by the
+// primary key on their version.
+func (r *QuestionRepository) PublishPack(ctx context.Context, language string, notes string, at time.Time) (*domain.PackRelease, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	release := &domain.PackRelease{Language: language, Notes: notes, PublishedAt: at}
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO pack_releases (language, version, notes, questions, published_at)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, 0, $3
+		FROM pack_releases
+		WHERE language = $1
+		RETURNING version
+	`, language, notes, at).Scan(&release.Version); err != nil {
+		return nil, fmt.Errorf("failed to create pack release: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE questions
+		SET pack_version = $2
+		WHERE language = $1 AND pack_version = 0 AND deleted_at IS NULL
+	`, language, release.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release questions: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, domain.ErrNothingToRelease
+	}
+	release.Questions = int(tag.RowsAffected())
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE pack_releases SET questions = $3 WHERE language = $1 AND version = $2
+	`, language, release.Version, release.Questions); err != nil {
+		return nil, fmt.Errorf("failed to count released questions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return release, nil
+}
+
+// ListPackReleases retrieves the releases, newest first, of one language's
+// pack or of every pack when language is empty
+func (r *QuestionRepository) ListPackReleases(ctx context.Context, language string) ([]*domain.PackRelease, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT language, version, notes, questions, published_at
+		FROM pack_releases
+		WHERE $1 = '' OR language = $1
+		ORDER BY published_at DESC, language, version DESC
+	`, language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pack releases: %w", err)
+	}
+	defer rows.Close()
+
+	releases := []*domain.PackRelease{}
+	for rows.Next() {
+		var release domain.PackRelease
+		if err := rows.Scan(
+			&release.Language,
+			&release.Version,
+			&release.Notes,
+			&release.Questions,
+			&release.PublishedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan pack release: %w", err)
+		}
+		releases = append(releases, &release)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pack releases: %w", err)
+	}
+
+	return releases, nil
+}
+
+// ratingsUpTo lists the ratings allowed under a cap as strings for query arguments
+func ratingsUpTo(maxRating domain.ContentRating) []string {
+	var ratings []string
+	for _, rating := range domain.RatingsUpTo(maxRating) {
+		ratings = append(ratings, string(rating))
+	}
+	return ratings
+}
@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// QuestionSubmissionRepository implements the domain.QuestionSubmissionRepository interface
+type QuestionSubmissionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewQuestionSubmissionRepository creates a new question submission repository
+func NewQuestionSubmissionRepository(pool *pgxpool.Pool) *QuestionSubmissionRepository {
+	return &QuestionSubmissionRepository{pool: pool}
+}
+
+// questionSubmissionColumns are the columns scanned by scanQuestionSubmission
+const questionSubmissionColumns = `id, user_id, question, status, reviewed_by, review_note, created_at, reviewed_at`
+
+// Create creates a submission
+func (r *QuestionSubmissionRepository) Create(ctx context.Context, submission *domain.QuestionSubmission) error {
+	question, err := json.Marshal(submission.Question)
+	if err != nil {
+		return fmt.Errorf("failed to marshal submitted question: %w", err)
+	}
+
+	query := `
+		INSERT INTO question_submissions (id, user_id, question, status, reviewed_by, review_note, created_at, reviewed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = r.pool.Exec(ctx, query,
+		submission.ID,
+		submission.UserID,
+		question,
+		submission.Status,
+		submission.ReviewedBy,
+		submission.ReviewNote,
+		submission.CreatedAt,
+		submission.ReviewedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create question submission: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a submission by ID
+func (r *QuestionSubmissionRepository) Get(ctx context.Context, id string) (*domain.QuestionSubmission, error) {
+	query := `SELECT ` + questionSubmissionColumns + ` FROM question_submissions WHERE id = $1`
+
+	submission, err := scanQuestionSubmission(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrSubmissionNotFound
+	}
+	return submission, err
+}
+
+// ListByStatus retrieves the oldest submissions with a status first
+func (r *QuestionSubmissionRepository) ListByStatus(ctx context.Context, status domain.SubmissionStatus, limit int) ([]*domain.QuestionSubmission, error) {
+	query := `
+		SELECT ` + questionSubmissionColumns + `
+		FROM question_submissions
+		WHERE status = $1
+		ORDER BY created_at, id
+		LIMIT $2
+	`
+	return r.list(ctx, query, status, limit)
+}
+
+// ListByUser retrieves a user's submissions, newest first
+func (r *QuestionSubmissionRepository) ListByUser(ctx context.Context, userID string, limit int) ([]*domain.QuestionSubmission, error) {
+	query := `
+		SELECT ` + questionSubmissionColumns + `
+		FROM question_submissions
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	`
+	return r.list(ctx, query, userID, limit)
+}
+
+// Review records a moderator's review of a pending submission
+func (r *QuestionSubmissionRepository) Review(ctx context.Context, submission *domain.QuestionSubmission) error {
+	question, err := json.Marshal(submission.Question)
+	if err != nil {
+		return fmt.Errorf("failed to marshal submitted question: %w", err)
+	}
+
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE question_submissions
+		SET question = $2, status = $3, reviewed_by = $4, review_note = $5, reviewed_at = $6
+		WHERE id = $1 AND status = $7
+	`,
+		submission.ID,
+		question,
+		submission.Status,
+		submission.ReviewedBy,
+		submission.ReviewNote,
+		submission.ReviewedAt,
+		domain.SubmissionStatusPending,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to review question submission: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSubmissionNotFound
+	}
+	return nil
+}
+
+// CountByUser counts a user's submissions by status
+func (r *QuestionSubmissionRepository) CountByUser(ctx context.Context, userID string) (map[domain.SubmissionStatus]int, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT status, COUNT(*)
+		FROM question_submissions
+		WHERE user_id = $1
+		GROUP BY status
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count question submissions: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[domain.SubmissionStatus]int)
+	for rows.Next() {
+		var status domain.SubmissionStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan question submission count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating question submission counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetTrustThresholds retrieves the thresholds for trusting contributors
+func (r *QuestionSubmissionRepository) GetTrustThresholds(ctx context.Context) (*domain.TrustThresholds, error) {
+	var thresholds domain.TrustThresholds
+	err := r.pool.QueryRow(ctx, `
+		SELECT min_approved, max_rejected_percent, min_account_days, updated_at
+		FROM contributor_trust_thresholds
+	`).Scan(
+		&thresholds.MinApproved,
+		&thresholds.MaxRejectedPercent,
+		&thresholds.MinAccountDays,
+		&thresholds.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		defaults := domain.DefaultTrustThresholds()
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trust thresholds: %w", err)
+	}
+	return &thresholds, nil
+}
+
+// SaveTrustThresholds replaces the thresholds for trusting contributors
+func (r *QuestionSubmissionRepository) SaveTrustThresholds(ctx context.Context, thresholds *domain.TrustThresholds) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO contributor_trust_thresholds (min_approved, max_rejected_percent, min_account_days, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET
+			min_approved = EXCLUDED.min_approved,
+			max_rejected_percent = EXCLUDED.max_rejected_percent,
+			min_account_days = EXCLUDED.min_account_days,
+			updated_at = EXCLUDED.updated_at
+	`,
+		thresholds.MinApproved,
+		thresholds.MaxRejectedPercent,
+		thresholds.MinAccountDays,
+		thresholds.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save trust thresholds: %w", err)
+	}
+	return nil
+}
+
+// list runs a query for submissions
+func (r *QuestionSubmissionRepository) list(ctx context.Context, query string, args ...any) ([]*domain.QuestionSubmission, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list question submissions: %w", err)
+	}
+	defer rows.Close()
+
+	submissions := []*domain.QuestionSubmission{}
+	for rows.Next() {
+		submission, err := scanQuestionSubmission(rows)
+		if err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, submission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating question submissions: %w", err)
+	}
+
+	return submissions, nil
+}
+
+// scanQuestionSubmission scans a submission row
+func scanQuestionSubmission(row pgx.Row) (*domain.QuestionSubmission, error) {
+	var submission domain.QuestionSubmission
+	var question []byte
+	if err := row.Scan(
+		&submission.ID,
+		&submission.UserID,
+		&question,
+		&submission.Status,
+		&submission.ReviewedBy,
+		&submission.ReviewNote,
+		&submission.CreatedAt,
+		&submission.ReviewedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan question submission: %w", err)
+	}
+	if err := json.Unmarshal(question, &submission.Question); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal submitted question: %w", err)
+	}
+	return &submission, nil
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// benchBankSize is the number of questions in the generated bank draws are
+// benchmarked against
+const benchBankSize = 100000
+
+// benchQuestionBank connects to the database configured by the POSTGRES_*
+// variables and generates a bank of questions in a category of its own,
+// deleted once the benchmark is over. The database must be migrated, and
+// is only written to when DAHAA_TEST_POSTGRES is set.
+func benchQuestionBank(b *testing.B) (*pgxpool.Pool, string) {
+	b.Helper()
+	if os.Getenv("DAHAA_TEST_POSTGRES") == "" {
+		b.Skip("set DAHAA_TEST_POSTGRES to benchmark against the configured database")
+	}
+
+	ctx := context.Background()
+	pool, err := NewDB(WithoutQueryTimeout())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(pool.Close)
+
+	category := fmt.Sprintf("bench_%d", time.Now().UnixNano())
+	if _, err := pool.Exec(ctx, `
+		INSERT INTO questions (text, answer, category, filler_answers, pack_version)
+		SELECT 'Benchmark question ' || n, 'Answer ' || n, $1, ARRAY['Filler one', 'Filler two', 'Filler three'], 1
+		FROM generate_series(1, $2) AS n
+	`, category, benchBankSize); err != nil {
+		b.Fatalf("failed to generate questions: %v", err)
+	}
+	b.Cleanup(func() {
+		if _, err := pool.Exec(context.Background(), `DELETE FROM questions WHERE category = $1`, category); err != nil {
+			b.Errorf("failed to delete generated questions in %s: %v", category, err)
+		}
+	})
+	if _, err := pool.Exec(ctx, `ANALYZE questions`); err != nil {
+		b.Fatalf("failed to analyze questions: %v", err)
+	}
+	return pool, category
+}
+
+// BenchmarkGetRandomQuestion draws questions from the bank the way games
+// do, at a random offset into the counted category
+func BenchmarkGetRandomQuestion(b *testing.B) {
+	pool, category := benchQuestionBank(b)
+	repo := NewQuestionRepository(pool)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.GetRandomQuestion(ctx, category, domain.DefaultQuestionLanguage, 0, "", nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkOrderByRandomQuestion draws questions from the bank by sorting
+// the whole category randomly, as games once did
+func BenchmarkOrderByRandomQuestion(b *testing.B) {
+	pool, category := benchQuestionBank(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var id string
+		if err := pool.QueryRow(ctx, `
+			SELECT id FROM questions
+			WHERE deleted_at IS NULL AND category = $1 AND language = $2
+			ORDER BY RANDOM()
+			LIMIT 1
+		`, category, domain.DefaultQuestionLanguage).Scan(&id); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
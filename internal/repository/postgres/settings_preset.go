@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// SettingsPresetRepository implements the domain.SettingsPresetRepository interface
+type SettingsPresetRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSettingsPresetRepository creates a new settings preset repository
+func NewSettingsPresetRepository(pool *pgxpool.Pool) *SettingsPresetRepository {
+	return &SettingsPresetRepository{pool: pool}
+}
+
+// ListByUser retrieves a user's presets ordered by name
+func (r *SettingsPresetRepository) ListByUser(ctx context.Context, userID string) ([]*domain.SettingsPreset, error) {
+	query := `
+		SELECT user_id, key, name, settings, created_at, updated_at
+		FROM settings_presets
+		WHERE user_id = $1
+		ORDER BY name, key
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settings presets: %w", err)
+	}
+	defer rows.Close()
+
+	presets := []*domain.SettingsPreset{}
+	for rows.Next() {
+		preset, err := scanSettingsPreset(rows)
+		if err != nil {
+			return nil, err
+		}
+		presets = append(presets, preset)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating settings presets: %w", err)
+	}
+
+	return presets, nil
+}
+
+// Get retrieves one of a user's presets by key
+func (r *SettingsPresetRepository) Get(ctx context.Context, userID string, key string) (*domain.SettingsPreset, error) {
+	query := `
+		SELECT user_id, key, name, settings, created_at, updated_at
+		FROM settings_presets
+		WHERE user_id = $1 AND key = $2
+	`
+
+	preset, err := scanSettingsPreset(r.pool.QueryRow(ctx, query, userID, key))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrPresetNotFound
+	}
+	return preset, err
+}
+
+// Save creates a preset or replaces the user's preset with the same key,
+// keeping its creation time
+func (r *SettingsPresetRepository) Save(ctx context.Context, preset *domain.SettingsPreset) error {
+	settings, err := json.Marshal(preset.Settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal preset settings: %w", err)
+	}
+
+	query := `
+		INSERT INTO settings_presets (user_id, key, name, settings, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, key) DO UPDATE SET
+			name = EXCLUDED.name,
+			settings = EXCLUDED.settings,
+			updated_at = EXCLUDED.updated_at
+		RETURNING created_at
+	`
+
+	return r.pool.QueryRow(ctx, query,
+		preset.UserID,
+		preset.Key,
+		preset.Name,
+		settings,
+		preset.CreatedAt,
+		preset.UpdatedAt,
+	).Scan(&preset.CreatedAt)
+}
+
+// Delete removes one of a user's presets
+func (r *SettingsPresetRepository) Delete(ctx context.Context, userID string, key string) error {
+	query := `DELETE FROM settings_presets WHERE user_id = $1 AND key = $2`
+	tag, err := r.pool.Exec(ctx, query, userID, key)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPresetNotFound
+	}
+	return nil
+}
+
+// scanSettingsPreset scans a preset row
+func scanSettingsPreset(row pgx.Row) (*domain.SettingsPreset, error) {
+	var preset domain.SettingsPreset
+	var settings []byte
+	if err := row.Scan(
+		&preset.UserID,
+		&preset.Key,
+		&preset.Name,
+		&settings,
+		&preset.CreatedAt,
+		&preset.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(settings, &preset.Settings); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal preset settings: %w", err)
+	}
+	return &preset, nil
+}
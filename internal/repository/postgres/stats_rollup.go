@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// StatsRollupRepository implements the domain.StatsRollupRepository interface
+type StatsRollupRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewStatsRollupRepository creates a new stats rollup repository
+func NewStatsRollupRepository(pool *pgxpool.Pool) *StatsRollupRepository {
+	return &StatsRollupRepository{
+		pool: pool,
+	}
+}
+
+// Upsert creates or replaces rollups in a single transaction
+func (r *StatsRollupRepository) Upsert(ctx context.Context, rollups []*domain.StatsRollup) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO stats_rollups (
+			user_id, period, period_start, games, wins,
+			total_points, average_score, favorite_category, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id, period, period_start) DO UPDATE SET
+			games = EXCLUDED.games,
+			wins = EXCLUDED.wins,
+			total_points = EXCLUDED.total_points,
+			average_score = EXCLUDED.average_score,
+			favorite_category = EXCLUDED.favorite_category,
+			updated_at = EXCLUDED.updated_at
+	`
+	for _, rollup := range rollups {
+		if _, err := tx.Exec(ctx, query,
+			rollup.UserID,
+			rollup.Period,
+			rollup.PeriodStart,
+			rollup.Games,
+			rollup.Wins,
+			rollup.TotalPoints,
+			rollup.AverageScore,
+			rollup.FavoriteCategory,
+			rollup.UpdatedAt,
+		); err != nil {
+			return fmt.Errorf("failed to upsert stats rollup: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListByUser retrieves a user's most recent rollups for a period, newest first
+func (r *StatsRollupRepository) ListByUser(ctx context.Context, userID string, period domain.StatsPeriod, limit int) ([]*domain.StatsRollup, error) {
+	query := `
+		SELECT user_id, period, period_start, games, wins,
+			total_points, average_score, favorite_category, updated_at
+		FROM stats_rollups
+		WHERE user_id = $1 AND period = $2
+		ORDER BY period_start DESC
+		LIMIT $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, period, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stats rollups: %w", err)
+	}
+	defer rows.Close()
+
+	rollups := []*domain.StatsRollup{}
+	for rows.Next() {
+		var rollup domain.StatsRollup
+		if err := rows.Scan(
+			&rollup.UserID,
+			&rollup.Period,
+			&rollup.PeriodStart,
+			&rollup.Games,
+			&rollup.Wins,
+			&rollup.TotalPoints,
+			&rollup.AverageScore,
+			&rollup.FavoriteCategory,
+			&rollup.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan stats rollup: %w", err)
+		}
+		rollups = append(rollups, &rollup)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stats rollups: %w", err)
+	}
+
+	return rollups, nil
+}
@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// TenantRepository implements the domain.TenantRepository interface
+type TenantRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewTenantRepository creates a new tenant repository
+func NewTenantRepository(pool *pgxpool.Pool) *TenantRepository {
+	return &TenantRepository{pool: pool}
+}
+
+// Save creates or replaces an organization
+func (r *TenantRepository) Save(ctx context.Context, tenant *domain.Tenant) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO tenants (id, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			updated_at = EXCLUDED.updated_at
+		RETURNING created_at
+	`,
+		tenant.ID,
+		tenant.Name,
+		tenant.CreatedAt,
+		tenant.UpdatedAt,
+	).Scan(&tenant.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save organization: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves an organization by ID
+func (r *TenantRepository) Get(ctx context.Context, id string) (*domain.Tenant, error) {
+	var tenant domain.Tenant
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, name, created_at, updated_at
+		FROM tenants
+		WHERE id = $1
+	`, id).Scan(&tenant.ID, &tenant.Name, &tenant.CreatedAt, &tenant.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	return &tenant, nil
+}
+
+// List retrieves every organization, ordered by ID
+func (r *TenantRepository) List(ctx context.Context) ([]*domain.Tenant, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, name, created_at, updated_at
+		FROM tenants
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := []*domain.Tenant{}
+	for rows.Next() {
+		var tenant domain.Tenant
+		if err := rows.Scan(&tenant.ID, &tenant.Name, &tenant.CreatedAt, &tenant.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		tenants = append(tenants, &tenant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating organizations: %w", err)
+	}
+
+	return tenants, nil
+}
+
+// SaveSSO creates or replaces an organization's single sign-on configuration
+func (r *TenantRepository) SaveSSO(ctx context.Context, config *domain.SSOConfig) error {
+	roleMapping, err := json.Marshal(config.RoleMapping)
+	if err != nil {
+		return fmt.Errorf("failed to marshal role mapping: %w", err)
+	}
+
+	err = r.pool.QueryRow(ctx, `
+		INSERT INTO tenant_sso (
+			tenant_id, issuer, client_id, client_secret, role_claim, role_mapping,
+			enabled, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			issuer = EXCLUDED.issuer,
+			client_id = EXCLUDED.client_id,
+			client_secret = EXCLUDED.client_secret,
+			role_claim = EXCLUDED.role_claim,
+			role_mapping = EXCLUDED.role_mapping,
+			enabled = EXCLUDED.enabled,
+			updated_at = EXCLUDED.updated_at
+		RETURNING created_at
+	`,
+		config.TenantID,
+		config.Issuer,
+		config.ClientID,
+		config.ClientSecret,
+		config.RoleClaim,
+		roleMapping,
+		config.Enabled,
+		config.CreatedAt,
+		config.UpdatedAt,
+	).Scan(&config.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save single sign-on configuration: %w", err)
+	}
+	return nil
+}
+
+// GetSSO retrieves an organization's single sign-on configuration
+func (r *TenantRepository) GetSSO(ctx context.Context, tenantID string) (*domain.SSOConfig, error) {
+	var config domain.SSOConfig
+	var roleMapping []byte
+	err := r.pool.QueryRow(ctx, `
+		SELECT tenant_id, issuer, client_id, client_secret, role_claim, role_mapping,
+			enabled, created_at, updated_at
+		FROM tenant_sso
+		WHERE tenant_id = $1
+	`, tenantID).Scan(
+		&config.TenantID,
+		&config.Issuer,
+		&config.ClientID,
+		&config.ClientSecret,
+		&config.RoleClaim,
+		&roleMapping,
+		&config.Enabled,
+		&config.CreatedAt,
+		&config.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrSSONotConfigured
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get single sign-on configuration: %w", err)
+	}
+	if err := json.Unmarshal(roleMapping, &config.RoleMapping); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal role mapping: %w", err)
+	}
+	return &config, nil
+}
+
+// DeleteSSO removes an organization's single sign-on configuration
+func (r *TenantRepository) DeleteSSO(ctx context.Context, tenantID string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM tenant_sso WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete single sign-on configuration: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSSONotConfigured
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxLoggedQueryLength bounds how much of a slow query's SQL is logged
+const maxLoggedQueryLength = 500
+
+// QueryStats counts the queries run on a pool since it was created
+type QueryStats struct {
+	Queries  int64 `json:"queries"`
+	Slow     int64 `json:"slow"`      // Queries that took longer than the slow query threshold
+	TimedOut int64 `json:"timed_out"` // Queries canceled by the query timeout
+}
+
+// queryTracer bounds how long each query may run and logs the queries that
+// run slower than a threshold. A query lasts from when it is sent until its
+// rows are closed, so slow callers reading rows make queries slow too.
+type queryTracer struct {
+	timeout       time.Duration // Zero for no timeout
+	slowThreshold time.Duration // Zero to log no queries
+
+	queries  atomic.Int64
+	slow     atomic.Int64
+	timedOut atomic.Int64
+}
+
+// queryTraceKey is the context key of a running query's trace
+type queryTraceKey struct{}
+
+// queryTrace is what the tracer remembers about a running query
+type queryTrace struct {
+	sql     string
+	startAt time.Time
+	cancel  context.CancelFunc
+}
+
+// TraceQueryStart applies the query timeout to the context the query runs with
+func (t *queryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	trace := &queryTrace{sql: data.SQL, startAt: time.Now()}
+	if t.timeout > 0 {
+		ctx, trace.cancel = context.WithTimeout(ctx, t.timeout)
+	}
+	return context.WithValue(ctx, queryTraceKey{}, trace)
+}
+
+// TraceQueryEnd releases the query's timeout and records how it went
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	trace, ok := ctx.Value(queryTraceKey{}).(*queryTrace)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(trace.startAt)
+	timedOut := trace.cancel != nil && errors.Is(data.Err, context.DeadlineExceeded) && elapsed >= t.timeout
+	if trace.cancel != nil {
+		trace.cancel()
+	}
+
+	t.queries.Add(1)
+	if timedOut {
+		t.timedOut.Add(1)
+		log.Printf("Query timed out after %s: %s", elapsed.Round(time.Millisecond), compactSQL(trace.sql))
+		return
+	}
+	if t.slowThreshold > 0 && elapsed >= t.slowThreshold {
+		t.slow.Add(1)
+		log.Printf("Slow query took %s: %s", elapsed.Round(time.Millisecond), compactSQL(trace.sql))
+	}
+}
+
+// Stats returns the query counts of a pool created by NewDB
+func Stats(pool *pgxpool.Pool) QueryStats {
+	tracer, ok := pool.Config().ConnConfig.Tracer.(*queryTracer)
+	if !ok {
+		return QueryStats{}
+	}
+	return QueryStats{
+		Queries:  tracer.queries.Load(),
+		Slow:     tracer.slow.Load(),
+		TimedOut: tracer.timedOut.Load(),
+	}
+}
+
+// compactSQL collapses a query's whitespace onto one line for logging
+func compactSQL(sql string) string {
+	compact := strings.Join(strings.Fields(sql), " ")
+	if len(compact) > maxLoggedQueryLength {
+		compact = compact[:maxLoggedQueryLength] + "..."
+	}
+	return compact
+}
@@ -2,14 +2,24 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
 )
 
+// userSortColumns maps sortable fields to their columns
+var userSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
 // UserRepository implements domain.UserRepository
 type UserRepository struct {
 	pool *pgxpool.Pool
@@ -24,24 +34,34 @@ func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
 func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 	query := `
 		INSERT INTO users (
-			id, username, email, password_hash, display_name,
-			games_played, games_won, total_points,
-			last_login_at, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			id, username, email, password_hash, display_name, role,
+			games_played, games_won, total_points, preferences,
+			last_login_at, created_at, updated_at, verified, tenant_id, tenant_role
+		) VALUES ($1, $2, $3, $4, $5, COALESCE(NULLIF($6, ''), 'user'), $7, $8, $9, $10, $11, $12, $13, $14, NULLIF($15, ''), $16)
 	`
 
-	_, err := r.pool.Exec(ctx, query,
+	preferences, err := json.Marshal(user.Preferences)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user preferences: %w", err)
+	}
+
+	_, err = r.pool.Exec(ctx, query,
 		user.ID,
 		user.Username,
 		user.Email,
 		user.PasswordHash,
 		user.DisplayName,
+		user.Role,
 		user.Stats.GamesPlayed,
 		user.Stats.GamesWon,
 		user.Stats.TotalPoints,
+		preferences,
 		user.LastLoginAt,
 		user.CreatedAt,
 		user.UpdatedAt,
+		user.Verified,
+		user.TenantID,
+		user.TenantRole,
 	)
 
 	return err
@@ -50,26 +70,33 @@ func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 // GetByID retrieves a user by ID
 func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, display_name,
-			games_played, games_won, total_points,
-			last_login_at, created_at, updated_at
+		SELECT id, username, email, password_hash, display_name, role,
+			games_played, games_won, total_points, preferences,
+			last_login_at, created_at, updated_at, verified,
+			COALESCE(tenant_id, ''), tenant_role
 		FROM users
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	user := &domain.User{}
+	var preferences []byte
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.PasswordHash,
 		&user.DisplayName,
+		&user.Role,
 		&user.Stats.GamesPlayed,
 		&user.Stats.GamesWon,
 		&user.Stats.TotalPoints,
+		&preferences,
 		&user.LastLoginAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Verified,
+		&user.TenantID,
+		&user.TenantRole,
 	)
 
 	if err != nil {
@@ -79,32 +106,43 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User,
 		return nil, err
 	}
 
+	if err := decodePreferences(preferences, &user.Preferences); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
 
 // GetByUsername retrieves a user by username
 func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, display_name,
-			games_played, games_won, total_points,
-			last_login_at, created_at, updated_at
+		SELECT id, username, email, password_hash, display_name, role,
+			games_played, games_won, total_points, preferences,
+			last_login_at, created_at, updated_at, verified,
+			COALESCE(tenant_id, ''), tenant_role
 		FROM users
-		WHERE username = $1
+		WHERE username = $1 AND deleted_at IS NULL
 	`
 
 	user := &domain.User{}
+	var preferences []byte
 	err := r.pool.QueryRow(ctx, query, username).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.PasswordHash,
 		&user.DisplayName,
+		&user.Role,
 		&user.Stats.GamesPlayed,
 		&user.Stats.GamesWon,
 		&user.Stats.TotalPoints,
+		&preferences,
 		&user.LastLoginAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Verified,
+		&user.TenantID,
+		&user.TenantRole,
 	)
 
 	if err != nil {
@@ -114,32 +152,43 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*d
 		return nil, err
 	}
 
+	if err := decodePreferences(preferences, &user.Preferences); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
 
 // GetByEmail retrieves a user by email
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, display_name,
-			games_played, games_won, total_points,
-			last_login_at, created_at, updated_at
+		SELECT id, username, email, password_hash, display_name, role,
+			games_played, games_won, total_points, preferences,
+			last_login_at, created_at, updated_at, verified,
+			COALESCE(tenant_id, ''), tenant_role
 		FROM users
-		WHERE email = $1
+		WHERE email = $1 AND deleted_at IS NULL
 	`
 
 	user := &domain.User{}
+	var preferences []byte
 	err := r.pool.QueryRow(ctx, query, email).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.PasswordHash,
 		&user.DisplayName,
+		&user.Role,
 		&user.Stats.GamesPlayed,
 		&user.Stats.GamesWon,
 		&user.Stats.TotalPoints,
+		&preferences,
 		&user.LastLoginAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Verified,
+		&user.TenantID,
+		&user.TenantRole,
 	)
 
 	if err != nil {
@@ -149,10 +198,14 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.
 		return nil, err
 	}
 
+	if err := decodePreferences(preferences, &user.Preferences); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
 
-// Update updates a user
+// Update updates a user, leaving their organization as it is
 func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	query := `
 		UPDATE users
@@ -160,12 +213,14 @@ func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 			email = $2,
 			password_hash = $3,
 			display_name = $4,
-			games_played = $5,
-			games_won = $6,
-			total_points = $7,
-			last_login_at = $8,
-			updated_at = $9
-		WHERE id = $10
+			role = COALESCE(NULLIF($5, ''), role),
+			games_played = $6,
+			games_won = $7,
+			total_points = $8,
+			last_login_at = $9,
+			verified = $10,
+			updated_at = $11
+		WHERE id = $12 AND deleted_at IS NULL
 	`
 
 	_, err := r.pool.Exec(ctx, query,
@@ -173,10 +228,12 @@ func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 		user.Email,
 		user.PasswordHash,
 		user.DisplayName,
+		user.Role,
 		user.Stats.GamesPlayed,
 		user.Stats.GamesWon,
 		user.Stats.TotalPoints,
 		user.LastLoginAt,
+		user.Verified,
 		time.Now(),
 		user.ID,
 	)
@@ -184,11 +241,159 @@ func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	return err
 }
 
-// Delete deletes a user
+// GetByIdentity retrieves the user an outside account is linked to
+func (r *UserRepository) GetByIdentity(ctx context.Context, provider string, subject string) (*domain.User, error) {
+	var userID string
+	err := r.pool.QueryRow(ctx, `
+		SELECT user_id FROM user_identities
+		WHERE provider = $1 AND subject = $2
+	`, provider, subject).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user identity: %w", err)
+	}
+	return r.GetByID(ctx, userID)
+}
+
+// LinkIdentity links an outside account to a user, replacing the user it
+// was linked to
+func (r *UserRepository) LinkIdentity(ctx context.Context, userID string, provider string, subject string) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO user_identities (provider, subject, user_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (provider, subject) DO UPDATE SET user_id = EXCLUDED.user_id
+	`, provider, subject, userID)
+	if err != nil {
+		return fmt.Errorf("failed to link user identity: %w", err)
+	}
+	return nil
+}
+
+// Delete soft-deletes a user in a single transaction. Their username and
+// email are replaced with placeholders unique to the user, which frees them
+// for new accounts, and their name is replaced in game results and party
+// standings. Their outside accounts are unlinked, so signing in with one
+// again starts a new account. Rows referring to the user are kept until
+// they are purged.
 func (r *UserRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM users WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, id)
-	return err
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE users
+		SET username = 'deleted_' || left(md5(id), 24),
+			email = 'deleted+' || id || '@invalid',
+			password_hash = '',
+			display_name = $2,
+			deleted_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id, domain.DeletedPlayerName)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM user_identities WHERE user_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to unlink user identities: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE game_players SET name = $2 WHERE user_id = $1`, id, domain.DeletedPlayerName); err != nil {
+		return fmt.Errorf("failed to anonymize game results: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE party_standings SET name = $2 WHERE player_id = $1`, id, domain.DeletedPlayerName); err != nil {
+		return fmt.Errorf("failed to anonymize party standings: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE hall_of_fame SET author_name = $2, author_consent = FALSE WHERE author_id = $1
+	`, id, domain.DeletedPlayerName); err != nil {
+		return fmt.Errorf("failed to hide hall of fame entries: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE game_invites
+		SET status = $2
+		WHERE (from_user = $1 OR to_user = $1) AND status = $3
+	`, id, domain.InviteStatusExpired, domain.InviteStatusPending); err != nil {
+		return fmt.Errorf("failed to expire invites: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeleted permanently removes users deleted before the cutoff in a
+// single transaction. Game results keep their anonymized rows.
+func (r *UserRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM users
+		WHERE deleted_at < $1
+		ORDER BY deleted_at
+		LIMIT $2
+		FOR UPDATE
+	`, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find deleted users: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan deleted user: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating deleted users: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	for _, statement := range []string{
+		`DELETE FROM game_invites WHERE from_user = ANY($1) OR to_user = ANY($1)`,
+		`DELETE FROM category_stats WHERE user_id = ANY($1)`,
+		`DELETE FROM stats_rollups WHERE user_id = ANY($1)`,
+		`DELETE FROM settings_presets WHERE user_id = ANY($1)`,
+		`DELETE FROM account_signals WHERE user_id = ANY($1)`,
+		`DELETE FROM user_bans WHERE user_id = ANY($1)`,
+		`DELETE FROM question_submissions WHERE user_id = ANY($1)`,
+		`DELETE FROM entitlements WHERE user_id = ANY($1)`,
+		`DELETE FROM entitlement_log WHERE user_id = ANY($1) OR from_user_id = ANY($1)`,
+		`DELETE FROM promo_redemptions WHERE user_id = ANY($1)`,
+		`DELETE FROM cosmetic_unlocks WHERE user_id = ANY($1)`,
+		`DELETE FROM user_identities WHERE user_id = ANY($1)`,
+		`DELETE FROM hall_of_fame WHERE author_id = ANY($1)`,
+	} {
+		if _, err := tx.Exec(ctx, statement, ids); err != nil {
+			return 0, fmt.Errorf("failed to purge deleted users' data: %w", err)
+		}
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM users WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted users: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return result.RowsAffected(), nil
 }
 
 // UpdateStats updates a user's game statistics
@@ -199,7 +404,7 @@ func (r *UserRepository) UpdateStats(ctx context.Context, id string, stats domai
 			games_won = $2,
 			total_points = $3,
 			updated_at = $4
-		WHERE id = $5
+		WHERE id = $5 AND deleted_at IS NULL
 	`
 
 	_, err := r.pool.Exec(ctx, query,
@@ -212,3 +417,200 @@ func (r *UserRepository) UpdateStats(ctx context.Context, id string, stats domai
 
 	return err
 }
+
+// UpdatePreferences replaces a user's preferences
+func (r *UserRepository) UpdatePreferences(ctx context.Context, id string, preferences domain.UserPreferences) error {
+	data, err := json.Marshal(preferences)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user preferences: %w", err)
+	}
+
+	query := `
+		UPDATE users
+		SET preferences = $1,
+			updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`
+
+	tag, err := r.pool.Exec(ctx, query, data, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// SetTenant moves a user into an organization with a role, or out of theirs
+// when tenantID is empty
+func (r *UserRepository) SetTenant(ctx context.Context, id string, tenantID string, role domain.TenantRole) error {
+	if tenantID == "" {
+		role = ""
+	}
+
+	query := `
+		UPDATE users
+		SET tenant_id = NULLIF($1, ''),
+			tenant_role = $2,
+			updated_at = $3
+		WHERE id = $4 AND deleted_at IS NULL
+	`
+
+	tag, err := r.pool.Exec(ctx, query, tenantID, role, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to set user's organization: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// ListByTenant retrieves the members of an organization, ordered by display
+// name
+func (r *UserRepository) ListByTenant(ctx context.Context, tenantID string) ([]*domain.User, error) {
+	query := `
+		SELECT id, username, email, password_hash, display_name, role,
+			games_played, games_won, total_points, preferences,
+			last_login_at, created_at, updated_at, verified,
+			tenant_id, tenant_role
+		FROM users
+		WHERE tenant_id = $1 AND deleted_at IS NULL
+		ORDER BY display_name, id
+	`
+
+	rows, err := r.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []*domain.User{}
+	for rows.Next() {
+		user := &domain.User{}
+		var preferences []byte
+		if err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.PasswordHash,
+			&user.DisplayName,
+			&user.Role,
+			&user.Stats.GamesPlayed,
+			&user.Stats.GamesWon,
+			&user.Stats.TotalPoints,
+			&preferences,
+			&user.LastLoginAt,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.Verified,
+			&user.TenantID,
+			&user.TenantRole,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan organization member: %w", err)
+		}
+		if err := decodePreferences(preferences, &user.Preferences); err != nil {
+			return nil, err
+		}
+		members = append(members, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating organization members: %w", err)
+	}
+
+	return members, nil
+}
+
+// List retrieves a page of users, optionally filtered by role or organization
+func (r *UserRepository) List(ctx context.Context, params *pagination.Params) (pagination.Page[*domain.User], error) {
+	conditions := []string{"deleted_at IS NULL"}
+	var args []any
+	for _, filter := range []string{"role", "tenant_id"} {
+		if value, ok := params.Filter(filter); ok {
+			args = append(args, value)
+			conditions = append(conditions, fmt.Sprintf("%s = $%d", filter, len(args)))
+		}
+	}
+
+	condition, orderBy, keysetArgs, err := keyset(params, userSortColumns, len(args))
+	if err != nil {
+		return pagination.Page[*domain.User]{}, err
+	}
+	if condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, keysetArgs...)
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+	args = append(args, params.FetchLimit())
+
+	query := fmt.Sprintf(`
+		SELECT id, username, email, password_hash, display_name, role,
+			games_played, games_won, total_points, preferences,
+			last_login_at, created_at, updated_at, verified,
+			COALESCE(tenant_id, ''), tenant_role
+		FROM users
+		%s
+		%s
+		LIMIT $%d
+	`, where, orderBy, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return pagination.Page[*domain.User]{}, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		user := &domain.User{}
+		var preferences []byte
+		if err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.PasswordHash,
+			&user.DisplayName,
+			&user.Role,
+			&user.Stats.GamesPlayed,
+			&user.Stats.GamesWon,
+			&user.Stats.TotalPoints,
+			&preferences,
+			&user.LastLoginAt,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.Verified,
+			&user.TenantID,
+			&user.TenantRole,
+		); err != nil {
+			return pagination.Page[*domain.User]{}, fmt.Errorf("failed to scan user: %w", err)
+		}
+		if err := decodePreferences(preferences, &user.Preferences); err != nil {
+			return pagination.Page[*domain.User]{}, err
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return pagination.Page[*domain.User]{}, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return pagination.NewPage(users, params, func(user *domain.User) pagination.Cursor {
+		if params.Sort == "updated_at" {
+			return timeCursor(user.UpdatedAt, user.ID)
+		}
+		return timeCursor(user.CreatedAt, user.ID)
+	}), nil
+}
+
+// decodePreferences decodes stored preferences over the defaults, so that
+// preferences added since a user last saved theirs take their default
+func decodePreferences(data []byte, preferences *domain.UserPreferences) error {
+	*preferences = domain.DefaultUserPreferences()
+	if err := json.Unmarshal(data, preferences); err != nil {
+		return fmt.Errorf("failed to unmarshal user preferences: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// UserBanRepository implements the domain.UserBanRepository interface
+type UserBanRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewUserBanRepository creates a new user ban repository
+func NewUserBanRepository(pool *pgxpool.Pool) *UserBanRepository {
+	return &UserBanRepository{pool: pool}
+}
+
+// userBanColumns are the columns scanned by scanUserBan
+const userBanColumns = `id, user_id, kind, reason, appeal_note, issued_by, expires_at, created_at, lifted_at, lifted_by`
+
+// Create creates a ban
+func (r *UserBanRepository) Create(ctx context.Context, ban *domain.UserBan, entry *domain.ModerationLogEntry) error {
+	return r.withLog(ctx, entry, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO user_bans (id, user_id, kind, reason, appeal_note, issued_by, expires_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`,
+			ban.ID,
+			ban.UserID,
+			ban.Kind,
+			ban.Reason,
+			ban.AppealNote,
+			ban.IssuedBy,
+			ban.ExpiresAt,
+			ban.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create ban: %w", err)
+		}
+		return nil
+	})
+}
+
+// Get retrieves a ban by ID
+func (r *UserBanRepository) Get(ctx context.Context, id string) (*domain.UserBan, error) {
+	query := `SELECT ` + userBanColumns + ` FROM user_bans WHERE id = $1`
+
+	ban, err := scanUserBan(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrBanNotFound
+	}
+	return ban, err
+}
+
+// GetActive retrieves the user's ban in force at a time, preferring
+// permanent bans and then the one expiring last
+func (r *UserBanRepository) GetActive(ctx context.Context, userID string, now time.Time) (*domain.UserBan, error) {
+	query := `
+		SELECT ` + userBanColumns + `
+		FROM user_bans
+		WHERE user_id = $1 AND lifted_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+		ORDER BY expires_at DESC NULLS FIRST
+		LIMIT 1
+	`
+
+	ban, err := scanUserBan(r.pool.QueryRow(ctx, query, userID, now))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrBanNotFound
+	}
+	return ban, err
+}
+
+// ListByUser retrieves a user's bans, newest first
+func (r *UserBanRepository) ListByUser(ctx context.Context, userID string) ([]*domain.UserBan, error) {
+	query := `
+		SELECT ` + userBanColumns + `
+		FROM user_bans
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bans: %w", err)
+	}
+	defer rows.Close()
+
+	bans := []*domain.UserBan{}
+	for rows.Next() {
+		ban, err := scanUserBan(rows)
+		if err != nil {
+			return nil, err
+		}
+		bans = append(bans, ban)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bans: %w", err)
+	}
+
+	return bans, nil
+}
+
+// Lift ends a ban early
+func (r *UserBanRepository) Lift(ctx context.Context, id string, liftedBy string, at time.Time, entry *domain.ModerationLogEntry) error {
+	return r.withLog(ctx, entry, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			UPDATE user_bans
+			SET lifted_at = $2, lifted_by = $3
+			WHERE id = $1 AND lifted_at IS NULL
+		`, id, at, liftedBy)
+		if err != nil {
+			return fmt.Errorf("failed to lift ban: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrBanNotFound
+		}
+		return nil
+	})
+}
+
+// UpdateAppealNote replaces a ban's appeal note
+func (r *UserBanRepository) UpdateAppealNote(ctx context.Context, id string, note string, entry *domain.ModerationLogEntry) error {
+	return r.withLog(ctx, entry, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `UPDATE user_bans SET appeal_note = $2 WHERE id = $1`, id, note)
+		if err != nil {
+			return fmt.Errorf("failed to update appeal note: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrBanNotFound
+		}
+		return nil
+	})
+}
+
+// ListLog retrieves the most recent moderation log entries, newest first,
+// only for a user when userID is set
+func (r *UserBanRepository) ListLog(ctx context.Context, userID string, limit int) ([]*domain.ModerationLogEntry, error) {
+	query := `
+		SELECT id, action, user_id, COALESCE(ban_id::text, ''), actor, details, created_at
+		FROM moderation_log
+		WHERE $1 = '' OR user_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list moderation log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*domain.ModerationLogEntry{}
+	for rows.Next() {
+		var entry domain.ModerationLogEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.Action,
+			&entry.UserID,
+			&entry.BanID,
+			&entry.Actor,
+			&entry.Details,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan moderation log entry: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating moderation log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// withLog runs change in a transaction that also appends a moderation log
+// entry, so no change goes unlogged
+func (r *UserBanRepository) withLog(ctx context.Context, entry *domain.ModerationLogEntry, change func(tx pgx.Tx) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := change(tx); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO moderation_log (id, action, user_id, ban_id, actor, details, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid, $5, $6, $7)
+	`,
+		entry.ID,
+		entry.Action,
+		entry.UserID,
+		entry.BanID,
+		entry.Actor,
+		entry.Details,
+		entry.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("failed to log moderation action: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// scanUserBan scans a ban row
+func scanUserBan(row pgx.Row) (*domain.UserBan, error) {
+	var ban domain.UserBan
+	if err := row.Scan(
+		&ban.ID,
+		&ban.UserID,
+		&ban.Kind,
+		&ban.Reason,
+		&ban.AppealNote,
+		&ban.IssuedBy,
+		&ban.ExpiresAt,
+		&ban.CreatedAt,
+		&ban.LiftedAt,
+		&ban.LiftedBy,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan ban: %w", err)
+	}
+	return &ban, nil
+}
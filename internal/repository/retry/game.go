@@ -0,0 +1,97 @@
+package retry
+
+import (
+	"context"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+)
+
+// Compile-time checks that the decorators satisfy the domain interfaces
+var (
+	_ domain.GameRepository      = (*GameRepository)(nil)
+	_ domain.GameEventRepository = (*GameEventRepository)(nil)
+	_ domain.GameChangeStore     = (*GameChangeStore)(nil)
+)
+
+// GameRepository decorates a domain.GameRepository, retrying operations
+// that fail transiently
+type GameRepository struct {
+	repo   domain.GameRepository
+	policy Policy
+}
+
+// NewGameRepository creates a retrying decorator around a game repository
+func NewGameRepository(repo domain.GameRepository, policy Policy) *GameRepository {
+	return &GameRepository{repo: repo, policy: policy}
+}
+
+// Create creates a new game
+func (r *GameRepository) Create(ctx context.Context, game *domain.Game) error {
+	return exec(ctx, r.policy, false, func() error { return r.repo.Create(ctx, game) })
+}
+
+// GetByCode retrieves a game by its code
+func (r *GameRepository) GetByCode(ctx context.Context, code string) (*domain.Game, error) {
+	return do(ctx, r.policy, true, func() (*domain.Game, error) { return r.repo.GetByCode(ctx, code) })
+}
+
+// GetByID retrieves a game by its ID
+func (r *GameRepository) GetByID(ctx context.Context, id string) (*domain.Game, error) {
+	return do(ctx, r.policy, true, func() (*domain.Game, error) { return r.repo.GetByID(ctx, id) })
+}
+
+// Update updates a game, overwriting its stored state
+func (r *GameRepository) Update(ctx context.Context, game *domain.Game) error {
+	return exec(ctx, r.policy, true, func() error { return r.repo.Update(ctx, game) })
+}
+
+// Delete deletes a game
+func (r *GameRepository) Delete(ctx context.Context, code string) error {
+	return exec(ctx, r.policy, false, func() error { return r.repo.Delete(ctx, code) })
+}
+
+// List retrieves a page of games
+func (r *GameRepository) List(ctx context.Context, params *pagination.Params) (pagination.Page[*domain.Game], error) {
+	return do(ctx, r.policy, true, func() (pagination.Page[*domain.Game], error) { return r.repo.List(ctx, params) })
+}
+
+// GameEventRepository decorates a domain.GameEventRepository, retrying
+// operations that fail transiently
+type GameEventRepository struct {
+	repo   domain.GameEventRepository
+	policy Policy
+}
+
+// NewGameEventRepository creates a retrying decorator around a game event repository
+func NewGameEventRepository(repo domain.GameEventRepository, policy Policy) *GameEventRepository {
+	return &GameEventRepository{repo: repo, policy: policy}
+}
+
+// Append stores events
+func (r *GameEventRepository) Append(ctx context.Context, events ...*domain.GameEvent) error {
+	return exec(ctx, r.policy, false, func() error { return r.repo.Append(ctx, events...) })
+}
+
+// ListByGame retrieves a game's events with a sequence greater than afterSequence
+func (r *GameEventRepository) ListByGame(ctx context.Context, gameID string, afterSequence int) ([]*domain.GameEvent, error) {
+	return do(ctx, r.policy, true, func() ([]*domain.GameEvent, error) { return r.repo.ListByGame(ctx, gameID, afterSequence) })
+}
+
+// GameChangeStore decorates a domain.GameChangeStore, retrying changes that
+// were rejected transiently. A change whose connection broke is not
+// retried, as it may have been saved.
+type GameChangeStore struct {
+	store  domain.GameChangeStore
+	policy Policy
+}
+
+// NewGameChangeStore creates a retrying decorator around a game change store
+func NewGameChangeStore(store domain.GameChangeStore, policy Policy) *GameChangeStore {
+	return &GameChangeStore{store: store, policy: policy}
+}
+
+// SaveChange saves a change in a single transaction
+func (s *GameChangeStore) SaveChange(ctx context.Context, change *domain.GameChange) error {
+	return exec(ctx, s.policy, false, func() error { return s.store.SaveChange(ctx, change) })
+}
@@ -0,0 +1,52 @@
+package retry
+
+import (
+	"context"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// Compile-time check that the decorator satisfies the domain interface
+var _ domain.QuestionRepository = (*QuestionRepository)(nil)
+
+// QuestionRepository decorates a domain.QuestionRepository, retrying the
+// reads games make as they draw and score questions. Other operations are
+// administrative and pass through.
+type QuestionRepository struct {
+	domain.QuestionRepository
+	policy Policy
+}
+
+// NewQuestionRepository creates a retrying decorator around a question repository
+func NewQuestionRepository(repo domain.QuestionRepository, policy Policy) *QuestionRepository {
+	return &QuestionRepository{QuestionRepository: repo, policy: policy}
+}
+
+// GetRandomQuestion retrieves a random question from a category
+func (r *QuestionRepository) GetRandomQuestion(ctx context.Context, category string, language string, packVersion int, maxRating domain.ContentRating, exclude []string) (*domain.Question, error) {
+	return do(ctx, r.policy, true, func() (*domain.Question, error) {
+		return r.QuestionRepository.GetRandomQuestion(ctx, category, language, packVersion, maxRating, exclude)
+	})
+}
+
+// GetCategories retrieves all available categories
+func (r *QuestionRepository) GetCategories(ctx context.Context) ([]string, error) {
+	return do(ctx, r.policy, true, func() ([]string, error) { return r.QuestionRepository.GetCategories(ctx) })
+}
+
+// GetCategoryCounts retrieves all available categories with their counts
+func (r *QuestionRepository) GetCategoryCounts(ctx context.Context, maxRating domain.ContentRating) ([]domain.CategoryCount, error) {
+	return do(ctx, r.policy, true, func() ([]domain.CategoryCount, error) {
+		return r.QuestionRepository.GetCategoryCounts(ctx, maxRating)
+	})
+}
+
+// GetByID retrieves a question by its ID
+func (r *QuestionRepository) GetByID(ctx context.Context, id string) (*domain.Question, error) {
+	return do(ctx, r.policy, true, func() (*domain.Question, error) { return r.QuestionRepository.GetByID(ctx, id) })
+}
+
+// LatestPackVersion returns the latest released version of a language's pack
+func (r *QuestionRepository) LatestPackVersion(ctx context.Context, language string) (int, error) {
+	return do(ctx, r.policy, true, func() (int, error) { return r.QuestionRepository.LatestPackVersion(ctx, language) })
+}
@@ -0,0 +1,118 @@
+// Package retry provides decorators for the domain repositories and session
+// store that retry operations failing for transient reasons, such as
+// serialization failures, connection resets and database failovers, so a
+// momentary blip does not fail a player's action mid-round.
+//
+// Operations are only retried when doing so cannot apply them twice. Any
+// operation may be retried when it failed without taking effect: the
+// database rejected it or it was never sent. Reads and operations that
+// overwrite state may also be retried when the connection broke with their
+// outcome unknown.
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/redis/go-redis/v9"
+	"github.com/zizouhuweidi/dahaa/internal/random"
+)
+
+// Policy bounds how operations are retried
+type Policy struct {
+	Attempts  int           // Attempts in total, including the first
+	BaseDelay time.Duration // Delay before the first retry, doubled for each one after it
+	MaxDelay  time.Duration // Longest delay between attempts
+}
+
+// DefaultPolicy retries twice, spending at most about a second in all, which
+// rides out a connection reset or a quick failover while a player waits
+var DefaultPolicy = Policy{
+	Attempts:  3,
+	BaseDelay: 100 * time.Millisecond,
+	MaxDelay:  time.Second,
+}
+
+// Postgres error codes whose transactions were rolled back and can be
+// retried as a whole
+var retryableCodes = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"57P01": true, // admin_shutdown, sent to sessions when a server fails over
+	"57P02": true, // crash_shutdown
+	"57P03": true, // cannot_connect_now, while a server starts up
+}
+
+// Redis error prefixes of commands refused while a server is unavailable
+var retryablePrefixes = []string{"LOADING", "READONLY", "MASTERDOWN", "TRYAGAIN", "CLUSTERDOWN"}
+
+// Rejected reports whether an error is transient and the operation that
+// failed with it did not take effect, so it can be retried whatever it does
+func Rejected(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return retryableCodes[pgErr.Code] || strings.HasPrefix(pgErr.Code, "08") // connection_exception
+	}
+	for _, prefix := range retryablePrefixes {
+		if redis.HasErrorPrefix(err, prefix) {
+			return true
+		}
+	}
+	return pgconn.SafeToRetry(err)
+}
+
+// Disconnected reports whether an operation failed because its connection
+// broke, leaving its outcome unknown
+func Disconnected(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var opErr *net.OpError
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.As(err, &opErr)
+}
+
+// do runs an operation, retrying it after transient failures until it
+// succeeds, the attempts run out or the context is done. Idempotent
+// operations are also retried when their connection broke.
+func do[T any](ctx context.Context, p Policy, idempotent bool, op func() (T, error)) (T, error) {
+	delay := p.BaseDelay
+	for attempt := 1; ; attempt++ {
+		result, err := op()
+		if err == nil || attempt >= p.Attempts || !(Rejected(err) || idempotent && Disconnected(err)) {
+			return result, err
+		}
+
+		// Jitter spreads out the retries of clients that failed together
+		wait := delay/2 + time.Duration(random.Global().Intn(int(delay/2)+1))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return result, err
+		}
+		delay = min(delay*2, p.MaxDelay)
+	}
+}
+
+// exec runs an operation that returns only an error through do
+func exec(ctx context.Context, p Policy, idempotent bool, op func() error) error {
+	_, err := do(ctx, p, idempotent, func() (struct{}, error) {
+		return struct{}{}, op()
+	})
+	return err
+}
@@ -0,0 +1,111 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// redisError is an error reply from Redis
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+func (redisError) RedisError() {}
+
+// testPolicy retries quickly so tests do not wait out real backoff
+var testPolicy = Policy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name             string
+		err              error
+		wantRejected     bool
+		wantDisconnected bool
+	}{
+		{"nil", nil, false, false},
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, true, false},
+		{"deadlock", fmt.Errorf("failed to save: %w", &pgconn.PgError{Code: "40P01"}), true, false},
+		{"failover", &pgconn.PgError{Code: "57P01"}, true, false},
+		{"connection exception", &pgconn.PgError{Code: "08006"}, true, false},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false, false},
+		{"redis loading", redisError("LOADING Redis is loading the dataset in memory"), true, false},
+		{"redis readonly", fmt.Errorf("failed to store game: %w", redisError("READONLY You can't write against a read only replica.")), true, false},
+		{"redis wrong type", redisError("WRONGTYPE Operation against a key holding the wrong kind of value"), false, false},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), false, true},
+		{"unexpected eof", io.ErrUnexpectedEOF, false, true},
+		{"canceled", context.Canceled, false, false},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), false, false},
+		{"other", errors.New("game not found"), false, false},
+	}
+	for _, tt := range tests {
+		if got := Rejected(tt.err); got != tt.wantRejected {
+			t.Errorf("%s: Rejected = %t, want %t", tt.name, got, tt.wantRejected)
+		}
+		if got := Disconnected(tt.err); got != tt.wantDisconnected {
+			t.Errorf("%s: Disconnected = %t, want %t", tt.name, got, tt.wantDisconnected)
+		}
+	}
+}
+
+func TestDo(t *testing.T) {
+	serialization := &pgconn.PgError{Code: "40001"}
+	reset := fmt.Errorf("read: %w", syscall.ECONNRESET)
+	notFound := errors.New("game not found")
+
+	tests := []struct {
+		name         string
+		failures     []error // Errors of the attempts before the first success
+		idempotent   bool
+		wantAttempts int
+		wantErr      error
+	}{
+		{"success", nil, false, 1, nil},
+		{"rejected then success", []error{serialization}, false, 2, nil},
+		{"rejected until out of attempts", []error{serialization, serialization, serialization}, false, 3, serialization},
+		{"permanent failure", []error{notFound}, false, 1, notFound},
+		{"disconnected idempotent", []error{reset, reset}, true, 3, nil},
+		{"disconnected not idempotent", []error{reset}, false, 1, reset},
+		{"rejected then permanent", []error{serialization, notFound}, false, 2, notFound},
+	}
+	for _, tt := range tests {
+		attempts := 0
+		result, err := do(context.Background(), testPolicy, tt.idempotent, func() (int, error) {
+			attempts++
+			if attempts <= len(tt.failures) {
+				return 0, tt.failures[attempts-1]
+			}
+			return attempts, nil
+		})
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if attempts != tt.wantAttempts {
+			t.Errorf("%s: attempts = %d, want %d", tt.name, attempts, tt.wantAttempts)
+		}
+		if err == nil && result != attempts {
+			t.Errorf("%s: result = %d, want the successful attempt's %d", tt.name, result, attempts)
+		}
+	}
+}
+
+func TestDoStopsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts := 0
+	policy := Policy{Attempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	err := exec(ctx, policy, true, func() error {
+		attempts++
+		return &pgconn.PgError{Code: "40001"}
+	})
+	if attempts != 1 || err == nil {
+		t.Errorf("retrying after the context was done: attempts = %d, error = %v, want 1 attempt and the error", attempts, err)
+	}
+}
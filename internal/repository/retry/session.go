@@ -0,0 +1,89 @@
+package retry
+
+import (
+	"context"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// Compile-time check that the decorator satisfies the domain interface
+var _ domain.GameSessionStore = (*GameSessionStore)(nil)
+
+// GameSessionStore decorates a domain.GameSessionStore, retrying operations
+// that fail transiently, such as while Redis fails over to a replica. Every
+// operation sets, reads or deletes whole keys, so all may be retried when
+// their connection broke.
+type GameSessionStore struct {
+	store  domain.GameSessionStore
+	policy Policy
+}
+
+// NewGameSessionStore creates a retrying decorator around a session store
+func NewGameSessionStore(store domain.GameSessionStore, policy Policy) *GameSessionStore {
+	return &GameSessionStore{store: store, policy: policy}
+}
+
+// StoreGame stores an active game
+func (s *GameSessionStore) StoreGame(ctx context.Context, game *domain.Game) error {
+	return exec(ctx, s.policy, true, func() error { return s.store.StoreGame(ctx, game) })
+}
+
+// StoreGameRounds stores an active game's header and its rounds from index from on
+func (s *GameSessionStore) StoreGameRounds(ctx context.Context, game *domain.Game, from int) error {
+	return exec(ctx, s.policy, true, func() error { return s.store.StoreGameRounds(ctx, game, from) })
+}
+
+// GetGame retrieves an active game by its ID
+func (s *GameSessionStore) GetGame(ctx context.Context, gameID string) (*domain.Game, error) {
+	return do(ctx, s.policy, true, func() (*domain.Game, error) { return s.store.GetGame(ctx, gameID) })
+}
+
+// GetGameHead retrieves an active game by its ID with only its current round
+func (s *GameSessionStore) GetGameHead(ctx context.Context, gameID string) (*domain.GameHead, error) {
+	return do(ctx, s.policy, true, func() (*domain.GameHead, error) { return s.store.GetGameHead(ctx, gameID) })
+}
+
+// GetGameHeads retrieves every active game with only its current round
+func (s *GameSessionStore) GetGameHeads(ctx context.Context) ([]*domain.GameHead, error) {
+	return do(ctx, s.policy, true, func() ([]*domain.GameHead, error) { return s.store.GetGameHeads(ctx) })
+}
+
+// DeleteGame removes an active game
+func (s *GameSessionStore) DeleteGame(ctx context.Context, gameID string) error {
+	return exec(ctx, s.policy, true, func() error { return s.store.DeleteGame(ctx, gameID) })
+}
+
+// StorePlayerSession stores a player's session along with their token
+func (s *GameSessionStore) StorePlayerSession(ctx context.Context, gameID string, player *domain.Player, token string) error {
+	return exec(ctx, s.policy, true, func() error { return s.store.StorePlayerSession(ctx, gameID, player, token) })
+}
+
+// ResolvePlayerToken returns the ID of the player a token was issued to
+func (s *GameSessionStore) ResolvePlayerToken(ctx context.Context, gameID string, token string) (string, error) {
+	return do(ctx, s.policy, true, func() (string, error) { return s.store.ResolvePlayerToken(ctx, gameID, token) })
+}
+
+// DeletePlayerSession removes a player's session and token
+func (s *GameSessionStore) DeletePlayerSession(ctx context.Context, gameID string, playerID string) error {
+	return exec(ctx, s.policy, true, func() error { return s.store.DeletePlayerSession(ctx, gameID, playerID) })
+}
+
+// StoreOverlayToken stores a game's overlay token
+func (s *GameSessionStore) StoreOverlayToken(ctx context.Context, gameID string, token string) error {
+	return exec(ctx, s.policy, true, func() error { return s.store.StoreOverlayToken(ctx, gameID, token) })
+}
+
+// ResolveOverlayToken returns the ID of the game an overlay token was issued for
+func (s *GameSessionStore) ResolveOverlayToken(ctx context.Context, token string) (string, error) {
+	return do(ctx, s.policy, true, func() (string, error) { return s.store.ResolveOverlayToken(ctx, token) })
+}
+
+// StoreResumeSession stores what a WebSocket client is bound to
+func (s *GameSessionStore) StoreResumeSession(ctx context.Context, token string, session *domain.ResumeSession) error {
+	return exec(ctx, s.policy, true, func() error { return s.store.StoreResumeSession(ctx, token, session) })
+}
+
+// ResolveResumeToken returns what the client holding a resume token was bound to
+func (s *GameSessionStore) ResolveResumeToken(ctx context.Context, token string) (*domain.ResumeSession, error) {
+	return do(ctx, s.policy, true, func() (*domain.ResumeSession, error) { return s.store.ResolveResumeToken(ctx, token) })
+}
@@ -0,0 +1,237 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/rpc/dahaav1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GameServer implements dahaav1.GameServiceServer on top of the game service
+type GameServer struct {
+	dahaav1.UnimplementedGameServiceServer
+	games domain.GameService
+}
+
+// NewGameServer creates a new game server
+func NewGameServer(games domain.GameService) *GameServer {
+	return &GameServer{games: games}
+}
+
+// CreateGame creates a game hosted by the request's player
+func (s *GameServer) CreateGame(ctx context.Context, req *dahaav1.CreateGameRequest) (*dahaav1.CreateGameResponse, error) {
+	var settings *domain.GameSettings
+	if req.Settings != nil {
+		settings = gameSettingsFromProto(req.Settings)
+	}
+
+	game, token, err := s.games.CreateGame(ctx, req.Code, playerFromProto(req.Player), settings)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &dahaav1.CreateGameResponse{Game: gameToProto(game), PlayerToken: token}, nil
+}
+
+// GetGame retrieves a game by its ID
+func (s *GameServer) GetGame(ctx context.Context, req *dahaav1.GetGameRequest) (*dahaav1.Game, error) {
+	game, err := s.games.GetGame(ctx, req.GameId)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return gameToProto(game), nil
+}
+
+// JoinGame seats the request's player in a game
+func (s *GameServer) JoinGame(ctx context.Context, req *dahaav1.JoinGameRequest) (*dahaav1.JoinGameResponse, error) {
+	token, err := s.games.JoinGame(ctx, req.GameId, playerFromProto(req.Player))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &dahaav1.JoinGameResponse{PlayerToken: token}, nil
+}
+
+// StartGame starts a game on behalf of its host
+func (s *GameServer) StartGame(ctx context.Context, req *dahaav1.StartGameRequest) (*dahaav1.Empty, error) {
+	if err := s.games.StartGame(ctx, req.GameId, req.CallerId, req.Force); err != nil {
+		return nil, statusError(err)
+	}
+	return &dahaav1.Empty{}, nil
+}
+
+// EndGame ends a game on behalf of its host
+func (s *GameServer) EndGame(ctx context.Context, req *dahaav1.GameActionRequest) (*dahaav1.Empty, error) {
+	if err := s.games.EndGame(ctx, req.GameId, req.CallerId); err != nil {
+		return nil, statusError(err)
+	}
+	return &dahaav1.Empty{}, nil
+}
+
+// StartTurn starts a player's turn to choose the round's category
+func (s *GameServer) StartTurn(ctx context.Context, req *dahaav1.StartTurnRequest) (*dahaav1.Empty, error) {
+	if err := s.games.StartTurn(ctx, req.GameId, req.PlayerId); err != nil {
+		return nil, statusError(err)
+	}
+	return &dahaav1.Empty{}, nil
+}
+
+// SelectCategory selects the category of the current round on behalf of the
+// player holding the turn
+func (s *GameServer) SelectCategory(ctx context.Context, req *dahaav1.SelectCategoryRequest) (*dahaav1.Empty, error) {
+	if err := s.games.SelectCategory(ctx, req.GameId, req.PlayerId, req.Category); err != nil {
+		return nil, statusError(err)
+	}
+	return &dahaav1.Empty{}, nil
+}
+
+// SubmitAnswer submits a player's fake answer to the current round
+func (s *GameServer) SubmitAnswer(ctx context.Context, req *dahaav1.SubmitAnswerRequest) (*dahaav1.Empty, error) {
+	if err := s.games.SubmitAnswer(ctx, req.GameId, req.PlayerId, req.Answer); err != nil {
+		return nil, statusError(err)
+	}
+	return &dahaav1.Empty{}, nil
+}
+
+// SubmitVote submits a player's vote in the current round
+func (s *GameServer) SubmitVote(ctx context.Context, req *dahaav1.SubmitVoteRequest) (*dahaav1.Empty, error) {
+	if err := s.games.SubmitVote(ctx, req.GameId, req.PlayerId, req.AnswerId); err != nil {
+		return nil, statusError(err)
+	}
+	return &dahaav1.Empty{}, nil
+}
+
+// EndRound ends the current round on behalf of the host
+func (s *GameServer) EndRound(ctx context.Context, req *dahaav1.GameActionRequest) (*dahaav1.Empty, error) {
+	if err := s.games.EndRound(ctx, req.GameId, req.CallerId); err != nil {
+		return nil, statusError(err)
+	}
+	return &dahaav1.Empty{}, nil
+}
+
+// playerFromProto converts a player joining or hosting a game
+func playerFromProto(p *dahaav1.Player) domain.Player {
+	if p == nil {
+		return domain.Player{}
+	}
+	return domain.Player{ID: p.Id, Name: p.Name, IsActive: true}
+}
+
+// gameSettingsFromProto converts the settings a game is created with
+func gameSettingsFromProto(s *dahaav1.GameSettings) *domain.GameSettings {
+	settings := &domain.GameSettings{
+		Mode:               domain.GameMode(s.Mode),
+		Rounds:             int(s.Rounds),
+		SelectedCategories: s.SelectedCategories,
+		MaxPlayers:         int(s.MaxPlayers),
+		MinPlayers:         int(s.MinPlayers),
+		Language:           s.Language,
+		MaxRating:          domain.ContentRating(s.MaxRating),
+		Listed:             s.Listed,
+	}
+	if s.TimeLimits != nil {
+		settings.TimeLimits = domain.TimeLimits{
+			CategorySelection: int(s.TimeLimits.CategorySelection),
+			AnswerWriting:     int(s.TimeLimits.AnswerWriting),
+			Voting:            int(s.TimeLimits.Voting),
+			Intermission:      int(s.TimeLimits.Intermission),
+		}
+	}
+	return settings
+}
+
+func gameSettingsToProto(s *domain.GameSettings) *dahaav1.GameSettings {
+	if s == nil {
+		return nil
+	}
+	return &dahaav1.GameSettings{
+		Rounds: int32(s.Rounds),
+		TimeLimits: &dahaav1.TimeLimits{
+			CategorySelection: int32(s.TimeLimits.CategorySelection),
+			AnswerWriting:     int32(s.TimeLimits.AnswerWriting),
+			Voting:            int32(s.TimeLimits.Voting),
+			Intermission:      int32(s.TimeLimits.Intermission),
+		},
+		SelectedCategories: s.SelectedCategories,
+		MaxPlayers:         int32(s.MaxPlayers),
+		Mode:               string(s.Mode),
+		MinPlayers:         int32(s.MinPlayers),
+		Language:           s.Language,
+		MaxRating:          string(s.MaxRating),
+		Listed:             s.Listed,
+	}
+}
+
+func gameToProto(g *domain.Game) *dahaav1.Game {
+	game := &dahaav1.Game{
+		Id:           g.ID,
+		Code:         g.Code,
+		Status:       string(g.Status),
+		Settings:     gameSettingsToProto(g.Settings),
+		CreatedAt:    timestamp(g.CreatedAt),
+		UpdatedAt:    timestamp(g.UpdatedAt),
+		LastActivity: timestamp(g.LastActivity),
+		HostId:       g.HostID,
+		PartyId:      g.PartyID,
+		TenantId:     g.TenantID,
+		Version:      int32(g.Version),
+		PackVersion:  int32(g.PackVersion),
+	}
+	for _, p := range g.Players {
+		game.Players = append(game.Players, &dahaav1.Player{
+			Id:          p.ID,
+			Name:        p.Name,
+			Score:       int32(p.Score),
+			IsConnected: p.IsConnected,
+			LastSeen:    timestamp(p.LastSeen),
+			IsActive:    p.IsActive,
+			Slot:        int32(p.Slot),
+		})
+	}
+	for _, r := range g.Rounds {
+		round := &dahaav1.Round{
+			Number:     int32(r.Number),
+			Category:   r.Category,
+			Question:   r.Question,
+			QuestionId: r.QuestionID,
+			Status:     string(r.Status),
+			StartTime:  timestamp(r.StartTime),
+			EndTime:    timestamp(r.EndTime),
+			AnswerPool: &dahaav1.AnswerPool{
+				CorrectAnswer: r.AnswerPool.CorrectAnswer,
+				FakeAnswers:   answersToProto(r.AnswerPool.FakeAnswers),
+				FillerAnswers: answersToProto(r.AnswerPool.FillerAnswers),
+			},
+		}
+		if len(r.Points) > 0 {
+			round.Points = make(map[string]int32, len(r.Points))
+			for playerID, points := range r.Points {
+				round.Points[playerID] = int32(points)
+			}
+		}
+		game.Rounds = append(game.Rounds, round)
+	}
+	return game
+}
+
+func answersToProto(answers []domain.Answer) []*dahaav1.Answer {
+	converted := make([]*dahaav1.Answer, 0, len(answers))
+	for _, a := range answers {
+		converted = append(converted, &dahaav1.Answer{
+			Id:        a.ID,
+			PlayerId:  a.PlayerID,
+			Text:      a.Text,
+			Votes:     a.Votes,
+			CreatedAt: timestamp(a.CreatedAt),
+		})
+	}
+	return converted
+}
+
+// timestamp converts a time, leaving the zero time unset
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,131 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/rpc/dahaav1"
+)
+
+// QuestionServer implements dahaav1.QuestionServiceServer on top of the
+// question repository
+type QuestionServer struct {
+	dahaav1.UnimplementedQuestionServiceServer
+	questions domain.QuestionRepository
+}
+
+// NewQuestionServer creates a new question server
+func NewQuestionServer(questions domain.QuestionRepository) *QuestionServer {
+	return &QuestionServer{questions: questions}
+}
+
+// GetRandomQuestion draws a random released question from a category
+func (s *QuestionServer) GetRandomQuestion(ctx context.Context, req *dahaav1.GetRandomQuestionRequest) (*dahaav1.Question, error) {
+	language := req.Language
+	if language == "" {
+		language = domain.DefaultQuestionLanguage
+	}
+
+	question, err := s.questions.GetRandomQuestion(ctx, req.Category, language, int(req.PackVersion), domain.ContentRating(req.MaxRating), req.Exclude)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return questionToProto(question), nil
+}
+
+// GetCategories lists the categories with released questions
+func (s *QuestionServer) GetCategories(ctx context.Context, req *dahaav1.GetCategoriesRequest) (*dahaav1.GetCategoriesResponse, error) {
+	categories, err := s.questions.GetCategories(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &dahaav1.GetCategoriesResponse{Categories: categories}, nil
+}
+
+// GetQuestion retrieves a question by its ID
+func (s *QuestionServer) GetQuestion(ctx context.Context, req *dahaav1.GetQuestionRequest) (*dahaav1.Question, error) {
+	question, err := s.questions.GetByID(ctx, req.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return questionToProto(question), nil
+}
+
+// CreateQuestion creates a question, unreleased until its pack is published
+func (s *QuestionServer) CreateQuestion(ctx context.Context, req *dahaav1.Question) (*dahaav1.Question, error) {
+	question := questionFromProto(req)
+	if err := s.questions.CreateQuestion(ctx, question); err != nil {
+		return nil, statusError(err)
+	}
+	return questionToProto(question), nil
+}
+
+// UpdateQuestion updates a question's content, keeping its difficulty,
+// language and rating where the request leaves them empty
+func (s *QuestionServer) UpdateQuestion(ctx context.Context, req *dahaav1.Question) (*dahaav1.Question, error) {
+	if err := s.questions.UpdateQuestion(ctx, questionFromProto(req)); err != nil {
+		return nil, statusError(err)
+	}
+
+	question, err := s.questions.GetByID(ctx, req.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return questionToProto(question), nil
+}
+
+// DeleteQuestion soft-deletes a question
+func (s *QuestionServer) DeleteQuestion(ctx context.Context, req *dahaav1.DeleteQuestionRequest) (*dahaav1.DeleteQuestionResponse, error) {
+	if err := s.questions.DeleteQuestion(ctx, req.Id); err != nil {
+		return nil, statusError(err)
+	}
+	return &dahaav1.DeleteQuestionResponse{}, nil
+}
+
+// BulkCreateQuestions creates questions in a single transaction
+func (s *QuestionServer) BulkCreateQuestions(ctx context.Context, req *dahaav1.BulkCreateQuestionsRequest) (*dahaav1.BulkCreateQuestionsResponse, error) {
+	questions := make([]*domain.Question, 0, len(req.Questions))
+	for _, q := range req.Questions {
+		questions = append(questions, questionFromProto(q))
+	}
+	if err := s.questions.BulkCreateQuestions(ctx, questions); err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &dahaav1.BulkCreateQuestionsResponse{}
+	for _, question := range questions {
+		resp.Questions = append(resp.Questions, questionToProto(question))
+	}
+	return resp, nil
+}
+
+// questionFromProto converts a question to create or update. The repository
+// assigns new questions their ID and defaults their empty fields.
+func questionFromProto(q *dahaav1.Question) *domain.Question {
+	return &domain.Question{
+		ID:            q.Id,
+		Text:          q.Text,
+		Answer:        q.Answer,
+		Category:      q.Category,
+		Difficulty:    q.Difficulty,
+		Language:      q.Language,
+		Rating:        domain.ContentRating(q.Rating),
+		FillerAnswers: q.FillerAnswers,
+	}
+}
+
+func questionToProto(q *domain.Question) *dahaav1.Question {
+	return &dahaav1.Question{
+		Id:            q.ID,
+		Text:          q.Text,
+		Answer:        q.Answer,
+		Category:      q.Category,
+		FillerAnswers: q.FillerAnswers,
+		CreatedAt:     timestamp(q.CreatedAt),
+		UpdatedAt:     timestamp(q.UpdatedAt),
+		Difficulty:    q.Difficulty,
+		Language:      q.Language,
+		Rating:        string(q.Rating),
+		PackVersion:   int32(q.PackVersion),
+	}
+}
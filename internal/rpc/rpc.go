@@ -0,0 +1,81 @@
+// Package rpc serves the game service and the question bank over gRPC to
+// internal consumers, such as matchmaking workers, analytics ingesters and
+// bots, on a listener separate from the public API. The services are defined
+// in proto/dahaa/v1 and their code generated into dahaav1 by make proto.
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/rpc/dahaav1"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer creates the gRPC server with the game and question services
+// registered. Every call must present the token as a bearer token in its
+// authorization metadata.
+func NewServer(token string, games domain.GameService, questions domain.QuestionRepository) *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(requireToken(token)))
+	dahaav1.RegisterGameServiceServer(server, NewGameServer(games))
+	dahaav1.RegisterQuestionServiceServer(server, NewQuestionServer(questions))
+	return server
+}
+
+// requireToken rejects calls that do not carry the token
+func requireToken(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var presented string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("authorization"); len(values) > 0 {
+				presented, _ = strings.CutPrefix(values[0], "Bearer ")
+			}
+		}
+
+		if presented == "" || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "a valid token is required")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// statusError converts a service or repository error to a gRPC status
+func statusError(err error) error {
+	var banErr *service.BanError
+	switch {
+	case errors.As(err, &banErr):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domain.ErrGameNotFound), errors.Is(err, domain.ErrQuestionNotFound),
+		errors.Is(err, domain.ErrPartyNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrGameCodeTaken), errors.Is(err, service.ErrPlayerInGame):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrNotHost), errors.Is(err, service.ErrPremiumPackNotOwned),
+		errors.Is(err, service.ErrTenantGame), errors.Is(err, service.ErrNotYourTurn):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrInvalidSettings), errors.Is(err, service.ErrNoCategories),
+		errors.Is(err, domain.ErrInvalidCategory), errors.Is(err, service.ErrInvalidAnswer),
+		errors.Is(err, domain.ErrInvalidAnswer), errors.Is(err, service.ErrInvalidVote),
+		errors.Is(err, domain.ErrInvalidVote), errors.Is(err, service.ErrAnswerTooSimilar),
+		errors.Is(err, service.ErrAnswerIsCorrect):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrEventConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, service.ErrGameFull), errors.Is(err, service.ErrGameInProgress),
+		errors.Is(err, domain.ErrIllegalTransition), errors.Is(err, service.ErrNotEnoughPlayers),
+		errors.Is(err, service.ErrPlayersNotReady), errors.Is(err, service.ErrPlayerNotInGame),
+		errors.Is(err, domain.ErrPlayerNotInGame), errors.Is(err, service.ErrAnswerSubmitted),
+		errors.Is(err, domain.ErrAnswerSubmitted), errors.Is(err, service.ErrVoteSubmitted),
+		errors.Is(err, domain.ErrVoteSubmitted), errors.Is(err, service.ErrSubmissionTooLate),
+		errors.Is(err, service.ErrLiarCannotVote):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
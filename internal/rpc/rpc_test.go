@@ -0,0 +1,235 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/random"
+	"github.com/zizouhuweidi/dahaa/internal/repository/memory"
+	"github.com/zizouhuweidi/dahaa/internal/rpc/dahaav1"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+	"github.com/zizouhuweidi/dahaa/internal/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testToken = "secret"
+
+// dial serves the game and question services from in-memory stores, with a
+// released question in the history category, and connects a client to them
+func dial(t *testing.T) (*grpc.ClientConn, *memory.QuestionRepository) {
+	t.Helper()
+
+	questions := memory.NewQuestionRepository(random.New(1))
+	if err := questions.SeedQuestions(context.Background(), &domain.Question{
+		Text:          "history question",
+		Answer:        "history answer",
+		Category:      "history",
+		FillerAnswers: []string{"filler one", "filler two", "filler three"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	games := memory.NewGameRepository()
+	events := memory.NewGameEventRepository()
+	changes := memory.NewGameChangeStore(games, events, memory.NewOutboxRepository(), memory.NewGameResultRepository(),
+		memory.NewCategoryStatsRepository(), memory.NewPartyRepository(), memory.NewHallOfFameRepository())
+	gameService := service.NewGameService(games, events, changes, questions, websocket.NewHub(), memory.NewGameSessionStore())
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(testToken, gameService, questions)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, questions
+}
+
+// authorized returns a context presenting a token
+func authorized(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+// wantCode fails the test unless err has a gRPC status code
+func wantCode(t *testing.T, call string, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Errorf("%s: code = %v (%v), want %v", call, got, err, want)
+	}
+}
+
+func TestRequireToken(t *testing.T) {
+	conn, _ := dial(t)
+	client := dahaav1.NewQuestionServiceClient(conn)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{"no token", context.Background(), codes.Unauthenticated},
+		{"wrong token", authorized("guess"), codes.Unauthenticated},
+		{"token", authorized(testToken), codes.OK},
+	}
+	for _, tt := range tests {
+		_, err := client.GetCategories(tt.ctx, &dahaav1.GetCategoriesRequest{})
+		wantCode(t, tt.name, err, tt.want)
+	}
+}
+
+func TestGameService(t *testing.T) {
+	conn, _ := dial(t)
+	client := dahaav1.NewGameServiceClient(conn)
+	ctx := authorized(testToken)
+
+	created, err := client.CreateGame(ctx, &dahaav1.CreateGameRequest{
+		Code:   "ABCD",
+		Player: &dahaav1.Player{Id: "alice", Name: "Alice"},
+		Settings: &dahaav1.GameSettings{
+			Rounds:             2,
+			TimeLimits:         &dahaav1.TimeLimits{CategorySelection: 30, AnswerWriting: 30, Voting: 30},
+			SelectedCategories: []string{"history"},
+			MaxPlayers:         8,
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	if created.PlayerToken == "" || created.Game.HostId != "alice" || created.Game.Code != "ABCD" {
+		t.Fatalf("CreateGame = %v, want ABCD hosted by alice with a player token", created)
+	}
+	gameID := created.Game.Id
+
+	for _, id := range []string{"bob", "carol"} {
+		joined, err := client.JoinGame(ctx, &dahaav1.JoinGameRequest{GameId: gameID, Player: &dahaav1.Player{Id: id, Name: id}})
+		if err != nil {
+			t.Fatalf("JoinGame %s: %v", id, err)
+		}
+		if joined.PlayerToken == "" {
+			t.Errorf("JoinGame %s: no player token", id)
+		}
+	}
+	_, err = client.JoinGame(ctx, &dahaav1.JoinGameRequest{GameId: gameID, Player: &dahaav1.Player{Id: "bob", Name: "bob"}})
+	wantCode(t, "JoinGame twice", err, codes.AlreadyExists)
+
+	_, err = client.StartGame(ctx, &dahaav1.StartGameRequest{GameId: gameID, CallerId: "bob", Force: true})
+	wantCode(t, "StartGame by a player", err, codes.PermissionDenied)
+	if _, err := client.StartGame(ctx, &dahaav1.StartGameRequest{GameId: gameID, CallerId: "alice", Force: true}); err != nil {
+		t.Fatalf("StartGame: %v", err)
+	}
+	if _, err := client.StartTurn(ctx, &dahaav1.StartTurnRequest{GameId: gameID, PlayerId: "alice"}); err != nil {
+		t.Fatalf("StartTurn: %v", err)
+	}
+	if _, err := client.SelectCategory(ctx, &dahaav1.SelectCategoryRequest{GameId: gameID, PlayerId: "alice", Category: "history"}); err != nil {
+		t.Fatalf("SelectCategory: %v", err)
+	}
+	if _, err := client.SubmitAnswer(ctx, &dahaav1.SubmitAnswerRequest{GameId: gameID, PlayerId: "bob", Answer: "bob lie"}); err != nil {
+		t.Fatalf("SubmitAnswer: %v", err)
+	}
+
+	game, err := client.GetGame(ctx, &dahaav1.GetGameRequest{GameId: gameID})
+	if err != nil {
+		t.Fatalf("GetGame: %v", err)
+	}
+	if game.Status != string(domain.GameStatusPlaying) || len(game.Players) != 3 || len(game.Rounds) != 1 {
+		t.Fatalf("GetGame = %s with %d players and %d rounds, want playing with 3 and 1", game.Status, len(game.Players), len(game.Rounds))
+	}
+	round := game.Rounds[0]
+	if round.Category != "history" || round.AnswerPool.CorrectAnswer != "history answer" || len(round.AnswerPool.FakeAnswers) != 1 {
+		t.Errorf("round = %v, want history with bob's answer", round)
+	}
+
+	_, err = client.GetGame(ctx, &dahaav1.GetGameRequest{GameId: "missing"})
+	wantCode(t, "GetGame unknown", err, codes.NotFound)
+
+	if _, err := client.EndGame(ctx, &dahaav1.GameActionRequest{GameId: gameID, CallerId: "alice"}); err != nil {
+		t.Fatalf("EndGame: %v", err)
+	}
+}
+
+func TestQuestionService(t *testing.T) {
+	conn, questions := dial(t)
+	client := dahaav1.NewQuestionServiceClient(conn)
+	ctx := authorized(testToken)
+
+	created, err := client.CreateQuestion(ctx, &dahaav1.Question{
+		Text:          "science question",
+		Answer:        "science answer",
+		Category:      "science",
+		FillerAnswers: []string{"one", "two", "three"},
+	})
+	if err != nil {
+		t.Fatalf("CreateQuestion: %v", err)
+	}
+	if created.Id == "" || created.Language != domain.DefaultQuestionLanguage || created.PackVersion != 0 {
+		t.Fatalf("CreateQuestion = %v, want an unreleased question in the default language", created)
+	}
+
+	// Unreleased questions are not drawn
+	_, err = client.GetRandomQuestion(ctx, &dahaav1.GetRandomQuestionRequest{Category: "science"})
+	wantCode(t, "GetRandomQuestion unreleased", err, codes.NotFound)
+	if _, err := questions.PublishPack(context.Background(), domain.DefaultQuestionLanguage, "", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	drawn, err := client.GetRandomQuestion(ctx, &dahaav1.GetRandomQuestionRequest{Category: "science"})
+	if err != nil {
+		t.Fatalf("GetRandomQuestion: %v", err)
+	}
+	if drawn.Id != created.Id {
+		t.Errorf("GetRandomQuestion = %s, want %s", drawn.Id, created.Id)
+	}
+
+	updated, err := client.UpdateQuestion(ctx, &dahaav1.Question{
+		Id:            created.Id,
+		Text:          "science question, revised",
+		Answer:        "science answer",
+		Category:      "science",
+		FillerAnswers: []string{"one", "two", "three"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateQuestion: %v", err)
+	}
+	if updated.Text != "science question, revised" || updated.Language != domain.DefaultQuestionLanguage {
+		t.Errorf("UpdateQuestion = %v, want the revised text in the kept language", updated)
+	}
+
+	categories, err := client.GetCategories(ctx, &dahaav1.GetCategoriesRequest{})
+	if err != nil {
+		t.Fatalf("GetCategories: %v", err)
+	}
+	if len(categories.Categories) != 2 {
+		t.Errorf("GetCategories = %v, want history and science", categories.Categories)
+	}
+
+	bulk, err := client.BulkCreateQuestions(ctx, &dahaav1.BulkCreateQuestionsRequest{Questions: []*dahaav1.Question{
+		{Text: "a", Answer: "a", Category: "art", FillerAnswers: []string{"1", "2", "3"}},
+		{Text: "b", Answer: "b", Category: "art", FillerAnswers: []string{"1", "2", "3"}},
+	}})
+	if err != nil {
+		t.Fatalf("BulkCreateQuestions: %v", err)
+	}
+	if len(bulk.Questions) != 2 || bulk.Questions[0].Id == "" || bulk.Questions[0].Id == bulk.Questions[1].Id {
+		t.Errorf("BulkCreateQuestions = %v, want two questions with their own IDs", bulk.Questions)
+	}
+
+	if _, err := client.DeleteQuestion(ctx, &dahaav1.DeleteQuestionRequest{Id: created.Id}); err != nil {
+		t.Fatalf("DeleteQuestion: %v", err)
+	}
+	_, err = client.GetRandomQuestion(ctx, &dahaav1.GetRandomQuestionRequest{Category: "science"})
+	wantCode(t, "GetRandomQuestion deleted", err, codes.NotFound)
+
+	_, err = client.GetQuestion(ctx, &dahaav1.GetQuestionRequest{Id: "missing"})
+	wantCode(t, "GetQuestion unknown", err, codes.NotFound)
+}
@@ -0,0 +1,156 @@
+package seed
+
+// country holds facts used to generate geography questions
+type country struct {
+	Name        string
+	NameAr      string
+	Capital     string
+	CapitalAr   string
+	Currency    string
+	CurrencyAr  string
+	Continent   string
+	ContinentAr string
+}
+
+// element holds facts used to generate science questions
+type element struct {
+	Name   string
+	NameAr string
+	Symbol string
+	Number int
+}
+
+var countries = []country{
+	{"France", "فرنسا", "Paris", "باريس", "Euro", "اليورو", "Europe", "أوروبا"},
+	{"Germany", "ألمانيا", "Berlin", "برلين", "Euro", "اليورو", "Europe", "أوروبا"},
+	{"Italy", "إيطاليا", "Rome", "روما", "Euro", "اليورو", "Europe", "أوروبا"},
+	{"Spain", "إسبانيا", "Madrid", "مدريد", "Euro", "اليورو", "Europe", "أوروبا"},
+	{"Portugal", "البرتغال", "Lisbon", "لشبونة", "Euro", "اليورو", "Europe", "أوروبا"},
+	{"United Kingdom", "المملكة المتحدة", "London", "لندن", "Pound Sterling", "الجنيه الإسترليني", "Europe", "أوروبا"},
+	{"Switzerland", "سويسرا", "Bern", "برن", "Swiss Franc", "الفرنك السويسري", "Europe", "أوروبا"},
+	{"Sweden", "السويد", "Stockholm", "ستوكهولم", "Swedish Krona", "الكرونا السويدية", "Europe", "أوروبا"},
+	{"Norway", "النرويج", "Oslo", "أوسلو", "Norwegian Krone", "الكرونة النرويجية", "Europe", "أوروبا"},
+	{"Poland", "بولندا", "Warsaw", "وارسو", "Zloty", "الزلوتي", "Europe", "أوروبا"},
+	{"Russia", "روسيا", "Moscow", "موسكو", "Ruble", "الروبل", "Europe", "أوروبا"},
+	{"Greece", "اليونان", "Athens", "أثينا", "Euro", "اليورو", "Europe", "أوروبا"},
+	{"Turkey", "تركيا", "Ankara", "أنقرة", "Turkish Lira", "الليرة التركية", "Asia", "آسيا"},
+	{"Egypt", "مصر", "Cairo", "القاهرة", "Egyptian Pound", "الجنيه المصري", "Africa", "أفريقيا"},
+	{"Morocco", "المغرب", "Rabat", "الرباط", "Moroccan Dirham", "الدرهم المغربي", "Africa", "أفريقيا"},
+	{"Algeria", "الجزائر", "Algiers", "الجزائر العاصمة", "Algerian Dinar", "الدينار الجزائري", "Africa", "أفريقيا"},
+	{"Tunisia", "تونس", "Tunis", "تونس العاصمة", "Tunisian Dinar", "الدينار التونسي", "Africa", "أفريقيا"},
+	{"Libya", "ليبيا", "Tripoli", "طرابلس", "Libyan Dinar", "الدينار الليبي", "Africa", "أفريقيا"},
+	{"Sudan", "السودان", "Khartoum", "الخرطوم", "Sudanese Pound", "الجنيه السوداني", "Africa", "أفريقيا"},
+	{"Nigeria", "نيجيريا", "Abuja", "أبوجا", "Naira", "النايرا", "Africa", "أفريقيا"},
+	{"Kenya", "كينيا", "Nairobi", "نيروبي", "Kenyan Shilling", "الشلن الكيني", "Africa", "أفريقيا"},
+	{"Ethiopia", "إثيوبيا", "Addis Ababa", "أديس أبابا", "Birr", "البير", "Africa", "أفريقيا"},
+	{"Ghana", "غانا", "Accra", "أكرا", "Cedi", "السيدي", "Africa", "أفريقيا"},
+	{"South Africa", "جنوب أفريقيا", "Pretoria", "بريتوريا", "Rand", "الراند", "Africa", "أفريقيا"},
+	{"Saudi Arabia", "السعودية", "Riyadh", "الرياض", "Saudi Riyal", "الريال السعودي", "Asia", "آسيا"},
+	{"United Arab Emirates", "الإمارات", "Abu Dhabi", "أبوظبي", "UAE Dirham", "الدرهم الإماراتي", "Asia", "آسيا"},
+	{"Qatar", "قطر", "Doha", "الدوحة", "Qatari Riyal", "الريال القطري", "Asia", "آسيا"},
+	{"Kuwait", "الكويت", "Kuwait City", "مدينة الكويت", "Kuwaiti Dinar", "الدينار الكويتي", "Asia", "آسيا"},
+	{"Bahrain", "البحرين", "Manama", "المنامة", "Bahraini Dinar", "الدينار البحريني", "Asia", "آسيا"},
+	{"Oman", "عُمان", "Muscat", "مسقط", "Omani Rial", "الريال العماني", "Asia", "آسيا"},
+	{"Yemen", "اليمن", "Sanaa", "صنعاء", "Yemeni Rial", "الريال اليمني", "Asia", "آسيا"},
+	{"Jordan", "الأردن", "Amman", "عمّان", "Jordanian Dinar", "الدينار الأردني", "Asia", "آسيا"},
+	{"Lebanon", "لبنان", "Beirut", "بيروت", "Lebanese Pound", "الليرة اللبنانية", "Asia", "آسيا"},
+	{"Syria", "سوريا", "Damascus", "دمشق", "Syrian Pound", "الليرة السورية", "Asia", "آسيا"},
+	{"Iraq", "العراق", "Baghdad", "بغداد", "Iraqi Dinar", "الدينار العراقي", "Asia", "آسيا"},
+	{"Iran", "إيران", "Tehran", "طهران", "Iranian Rial", "الريال الإيراني", "Asia", "آسيا"},
+	{"India", "الهند", "New Delhi", "نيودلهي", "Indian Rupee", "الروبية الهندية", "Asia", "آسيا"},
+	{"Pakistan", "باكستان", "Islamabad", "إسلام أباد", "Pakistani Rupee", "الروبية الباكستانية", "Asia", "آسيا"},
+	{"China", "الصين", "Beijing", "بكين", "Renminbi", "اليوان", "Asia", "آسيا"},
+	{"Japan", "اليابان", "Tokyo", "طوكيو", "Yen", "الين", "Asia", "آسيا"},
+	{"South Korea", "كوريا الجنوبية", "Seoul", "سول", "South Korean Won", "الوون الكوري", "Asia", "آسيا"},
+	{"Indonesia", "إندونيسيا", "Jakarta", "جاكرتا", "Rupiah", "الروبية الإندونيسية", "Asia", "آسيا"},
+	{"Thailand", "تايلاند", "Bangkok", "بانكوك", "Baht", "البات", "Asia", "آسيا"},
+	{"Malaysia", "ماليزيا", "Kuala Lumpur", "كوالالمبور", "Ringgit", "الرينغيت", "Asia", "آسيا"},
+	{"United States", "الولايات المتحدة", "Washington, D.C.", "واشنطن", "US Dollar", "الدولار الأمريكي", "North America", "أمريكا الشمالية"},
+	{"Canada", "كندا", "Ottawa", "أوتاوا", "Canadian Dollar", "الدولار الكندي", "North America", "أمريكا الشمالية"},
+	{"Mexico", "المكسيك", "Mexico City", "مكسيكو سيتي", "Mexican Peso", "البيزو المكسيكي", "North America", "أمريكا الشمالية"},
+	{"Brazil", "البرازيل", "Brasília", "برازيليا", "Real", "الريال البرازيلي", "South America", "أمريكا الجنوبية"},
+	{"Argentina", "الأرجنتين", "Buenos Aires", "بوينس آيرس", "Argentine Peso", "البيزو الأرجنتيني", "South America", "أمريكا الجنوبية"},
+	{"Chile", "تشيلي", "Santiago", "سانتياغو", "Chilean Peso", "البيزو التشيلي", "South America", "أمريكا الجنوبية"},
+	{"Peru", "بيرو", "Lima", "ليما", "Sol", "السول", "South America", "أمريكا الجنوبية"},
+	{"Colombia", "كولومبيا", "Bogotá", "بوغوتا", "Colombian Peso", "البيزو الكولومبي", "South America", "أمريكا الجنوبية"},
+	{"Australia", "أستراليا", "Canberra", "كانبرا", "Australian Dollar", "الدولار الأسترالي", "Oceania", "أوقيانوسيا"},
+	{"New Zealand", "نيوزيلندا", "Wellington", "ويلينغتون", "New Zealand Dollar", "الدولار النيوزيلندي", "Oceania", "أوقيانوسيا"},
+}
+
+var elements = []element{
+	{"Hydrogen", "الهيدروجين", "H", 1},
+	{"Helium", "الهيليوم", "He", 2},
+	{"Lithium", "الليثيوم", "Li", 3},
+	{"Beryllium", "البيريليوم", "Be", 4},
+	{"Boron", "البورون", "B", 5},
+	{"Carbon", "الكربون", "C", 6},
+	{"Nitrogen", "النيتروجين", "N", 7},
+	{"Oxygen", "الأكسجين", "O", 8},
+	{"Fluorine", "الفلور", "F", 9},
+	{"Neon", "النيون", "Ne", 10},
+	{"Sodium", "الصوديوم", "Na", 11},
+	{"Magnesium", "المغنيسيوم", "Mg", 12},
+	{"Aluminium", "الألومنيوم", "Al", 13},
+	{"Silicon", "السيليكون", "Si", 14},
+	{"Phosphorus", "الفوسفور", "P", 15},
+	{"Sulfur", "الكبريت", "S", 16},
+	{"Chlorine", "الكلور", "Cl", 17},
+	{"Argon", "الأرغون", "Ar", 18},
+	{"Potassium", "البوتاسيوم", "K", 19},
+	{"Calcium", "الكالسيوم", "Ca", 20},
+	{"Iron", "الحديد", "Fe", 26},
+	{"Cobalt", "الكوبالت", "Co", 27},
+	{"Nickel", "النيكل", "Ni", 28},
+	{"Copper", "النحاس", "Cu", 29},
+	{"Zinc", "الزنك", "Zn", 30},
+	{"Silver", "الفضة", "Ag", 47},
+	{"Tin", "القصدير", "Sn", 50},
+	{"Iodine", "اليود", "I", 53},
+	{"Platinum", "البلاتين", "Pt", 78},
+	{"Gold", "الذهب", "Au", 79},
+	{"Mercury", "الزئبق", "Hg", 80},
+	{"Lead", "الرصاص", "Pb", 82},
+	{"Uranium", "اليورانيوم", "U", 92},
+}
+
+// Category names for seeded questions
+const (
+	categoryCapitals   = "capitals"
+	categoryCurrencies = "currencies"
+	categoryContinents = "continents"
+	categoryElements   = "elements"
+
+	categoryCapitalsAr   = "عواصم"
+	categoryCurrenciesAr = "عملات"
+	categoryContinentsAr = "قارات"
+	categoryElementsAr   = "عناصر"
+)
+
+// Languages of the seeded content packs
+const (
+	languageEn = "en"
+	languageAr = "ar"
+)
+
+// demoUsers are created with demoPassword so the demo lobbies can be joined
+var demoUsers = []struct {
+	Username    string
+	DisplayName string
+}{
+	{"demo_host", "Demo Host"},
+	{"demo_layla", "Layla"},
+	{"demo_omar", "Omar"},
+	{"demo_sam", "Sam"},
+}
+
+const demoPassword = "demo-password"
+
+// demoLobbies are the waiting games created for each content pack
+var demoLobbies = []struct {
+	Code       string
+	Language   string
+	Categories []string
+}{
+	{"DEMO01", languageEn, []string{categoryCapitals, categoryElements}},
+	{"DEMOAR", languageAr, []string{categoryCapitalsAr, categoryElementsAr}},
+}
@@ -0,0 +1,251 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// fillersPerQuestion is the number of filler answers generated per question
+const fillersPerQuestion = 4
+
+// Result summarizes what a seed run created
+type Result struct {
+	Questions int      `json:"questions"`
+	Users     int      `json:"users"`
+	Skipped   []string `json:"skipped"`              // Categories that already had questions
+	GameCodes []string `json:"game_codes,omitempty"` // Demo lobbies created, one per language
+}
+
+// Seeder populates a database with sample content for development and demos
+type Seeder struct {
+	questionRepo domain.QuestionRepository
+	userService  *service.UserService
+	gameService  *service.GameService
+}
+
+// NewSeeder creates a new seeder
+func NewSeeder(questionRepo domain.QuestionRepository, userService *service.UserService, gameService *service.GameService) *Seeder {
+	return &Seeder{
+		questionRepo: questionRepo,
+		userService:  userService,
+		gameService:  gameService,
+	}
+}
+
+// Run seeds questions, demo users and a demo lobby per language. It is safe
+// to run repeatedly: existing categories, users and lobbies are left alone.
+func (s *Seeder) Run(ctx context.Context) (*Result, error) {
+	result := &Result{Skipped: []string{}}
+
+	if err := s.seedQuestions(ctx, result); err != nil {
+		return nil, err
+	}
+
+	users, err := s.seedUsers(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lobby := range demoLobbies {
+		if err := s.seedLobby(ctx, users, lobby.Code, lobby.Language, lobby.Categories, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// seedQuestions creates the sample questions for categories that are still empty
+func (s *Seeder) seedQuestions(ctx context.Context, result *Result) error {
+	counts, err := s.questionRepo.GetCategoryCounts(ctx, "")
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(counts))
+	for _, c := range counts {
+		existing[c.Category] = true
+	}
+
+	byCategory := make(map[string][]*domain.Question)
+	var order []string
+	for _, q := range Questions() {
+		if _, ok := byCategory[q.Category]; !ok {
+			order = append(order, q.Category)
+		}
+		byCategory[q.Category] = append(byCategory[q.Category], q)
+	}
+
+	var languages []string
+	for _, category := range order {
+		if existing[category] {
+			result.Skipped = append(result.Skipped, category)
+			continue
+		}
+		if err := s.questionRepo.BulkCreateQuestions(ctx, byCategory[category]); err != nil {
+			return fmt.Errorf("failed to seed %s questions: %w", category, err)
+		}
+		result.Questions += len(byCategory[category])
+		for _, q := range byCategory[category] {
+			if !slices.Contains(languages, q.Language) {
+				languages = append(languages, q.Language)
+			}
+		}
+	}
+
+	// Release the seeded questions so games can draw them, along with any
+	// others waiting in their packs
+	for _, language := range languages {
+		if _, err := s.questionRepo.PublishPack(ctx, language, "Sample questions", time.Now()); err != nil {
+			return fmt.Errorf("failed to release the %s pack: %w", language, err)
+		}
+	}
+
+	return nil
+}
+
+// seedUsers creates the demo users, returning them in demoUsers order
+func (s *Seeder) seedUsers(ctx context.Context, result *Result) ([]*domain.User, error) {
+	users := make([]*domain.User, 0, len(demoUsers))
+	for _, demo := range demoUsers {
+		user, err := s.userService.Register(ctx, service.RegisterRequest{
+			Username:    demo.Username,
+			Email:       demo.Username + "@example.com",
+			Password:    demoPassword,
+			DisplayName: demo.DisplayName,
+		})
+		switch {
+		case err == nil:
+			result.Users++
+		case errors.Is(err, domain.ErrUserAlreadyExists):
+			user, err = s.userService.GetUserByUsername(ctx, demo.Username)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get demo user %s: %w", demo.Username, err)
+			}
+		default:
+			return nil, fmt.Errorf("failed to create demo user %s: %w", demo.Username, err)
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// seedLobby creates a waiting demo game hosted by the first demo user
+func (s *Seeder) seedLobby(ctx context.Context, users []*domain.User, code string, language string, categories []string, result *Result) error {
+	settings := domain.DefaultGameSettings()
+	settings.Language = language
+	settings.SelectedCategories = categories
+
+	players := make([]domain.Player, 0, len(users))
+	for _, user := range users {
+		players = append(players, domain.Player{ID: user.ID, Name: user.DisplayName, IsActive: true})
+	}
+
+	game, _, err := s.gameService.CreateGame(ctx, code, players[0], settings)
+	if errors.Is(err, service.ErrGameCodeTaken) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create demo lobby: %w", err)
+	}
+	for _, player := range players[1:] {
+		if _, err := s.gameService.JoinGame(ctx, game.Code, player); err != nil {
+			return fmt.Errorf("failed to join demo lobby: %w", err)
+		}
+	}
+
+	result.GameCodes = append(result.GameCodes, game.Code)
+	return nil
+}
+
+// Questions generates the sample question bank in English and Arabic
+func Questions() []*domain.Question {
+	var questions []*domain.Question
+
+	capitals := make([]string, len(countries))
+	capitalsAr := make([]string, len(countries))
+	continents := make([]string, len(countries))
+	continentsAr := make([]string, len(countries))
+	for i, c := range countries {
+		capitals[i], capitalsAr[i] = c.Capital, c.CapitalAr
+		continents[i], continentsAr[i] = c.Continent, c.ContinentAr
+	}
+
+	for i, c := range countries {
+		questions = append(questions,
+			newQuestion(categoryCapitals, languageEn, "medium", "What is the capital of "+c.Name+"?", c.Capital, fillers(capitals, i)),
+			newQuestion(categoryCapitalsAr, languageAr, "medium", "ما هي عاصمة "+c.NameAr+"؟", c.CapitalAr, fillers(capitalsAr, i)),
+			newQuestion(categoryContinents, languageEn, "easy", "On which continent is "+c.Name+"?", c.Continent, fillers(continents, i)),
+			newQuestion(categoryContinentsAr, languageAr, "easy", "في أي قارة تقع "+c.NameAr+"؟", c.ContinentAr, fillers(continentsAr, i)),
+		)
+	}
+
+	// Shared currencies such as the euro make ambiguous questions
+	var currencies, currenciesAr []string
+	var currencyCountries []country
+	for _, c := range countries {
+		if c.Currency == "Euro" {
+			continue
+		}
+		currencies = append(currencies, c.Currency)
+		currenciesAr = append(currenciesAr, c.CurrencyAr)
+		currencyCountries = append(currencyCountries, c)
+	}
+	for i, c := range currencyCountries {
+		questions = append(questions,
+			newQuestion(categoryCurrencies, languageEn, "hard", "What is the currency of "+c.Name+"?", c.Currency, fillers(currencies, i)),
+			newQuestion(categoryCurrenciesAr, languageAr, "hard", "ما هي عملة "+c.NameAr+"؟", c.CurrencyAr, fillers(currenciesAr, i)),
+		)
+	}
+
+	symbols := make([]string, len(elements))
+	names := make([]string, len(elements))
+	namesAr := make([]string, len(elements))
+	for i, e := range elements {
+		symbols[i], names[i], namesAr[i] = e.Symbol, e.Name, e.NameAr
+	}
+	for i, e := range elements {
+		number := strconv.Itoa(e.Number)
+		questions = append(questions,
+			newQuestion(categoryElements, languageEn, "medium", "What is the chemical symbol for "+e.Name+"?", e.Symbol, fillers(symbols, i)),
+			newQuestion(categoryElements, languageEn, "hard", "Which element has atomic number "+number+"?", e.Name, fillers(names, i)),
+			newQuestion(categoryElementsAr, languageAr, "medium", "ما هو الرمز الكيميائي لعنصر "+e.NameAr+"؟", e.Symbol, fillers(symbols, i)),
+			newQuestion(categoryElementsAr, languageAr, "hard", "ما العنصر الذي عدده الذري "+number+"؟", e.NameAr, fillers(namesAr, i)),
+		)
+	}
+
+	return questions
+}
+
+// newQuestion builds a seed question
+func newQuestion(category, language, difficulty, text, answer string, fillerAnswers []string) *domain.Question {
+	return &domain.Question{
+		Text:          text,
+		Answer:        answer,
+		Category:      category,
+		Difficulty:    difficulty,
+		Language:      language,
+		FillerAnswers: fillerAnswers,
+	}
+}
+
+// fillers deterministically picks distinct values from the pool, excluding
+// the answer at index i
+func fillers(pool []string, i int) []string {
+	seen := map[string]bool{pool[i]: true}
+	result := make([]string, 0, fillersPerQuestion)
+	for step := 1; step < len(pool) && len(result) < fillersPerQuestion; step++ {
+		candidate := pool[(i+step*7)%len(pool)]
+		if !seen[candidate] {
+			seen[candidate] = true
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/id"
+)
+
+// Abuse heuristic defaults
+const (
+	// defaultSharedIPAccounts is how many accounts signing in from one IP
+	// address get it flagged as shared
+	defaultSharedIPAccounts = 5
+
+	// defaultSharedIPWindow is how far back accounts sharing an IP address
+	// are counted
+	defaultSharedIPWindow = 7 * 24 * time.Hour
+
+	// defaultSignalLimit and maxSignalLimit bound the signals and shared IP
+	// addresses listed for moderators
+	defaultSignalLimit = 50
+	maxSignalLimit     = 500
+)
+
+// disposableEmailDomains are well-known throwaway email providers. Their
+// subdomains count too.
+var disposableEmailDomains = []string{
+	"10minutemail.com", "discard.email", "dispostable.com", "emailondeck.com",
+	"fakeinbox.com", "getnada.com", "guerrillamail.com", "guerrillamail.net",
+	"mailinator.com", "maildrop.cc", "mailnesia.com", "mintemail.com",
+	"moakt.com", "sharklasers.com", "temp-mail.org", "tempail.com",
+	"tempmail.com", "tempr.email", "throwawaymail.com", "trashmail.com",
+	"yopmail.com",
+}
+
+// AbuseService records where accounts register and log in from, flagging
+// sign-ins that abuse heuristics find suspicious, for moderators to review.
+// It only records signals; acting on them is left to moderators.
+type AbuseService struct {
+	repo              domain.AccountSignalRepository
+	disposableDomains map[string]bool
+	sharedIPAccounts  int
+	sharedIPWindow    time.Duration
+}
+
+// AbuseOption configures an AbuseService
+type AbuseOption func(*AbuseService)
+
+// WithDisposableEmailDomains flags emails from more throwaway providers,
+// besides the well-known ones
+func WithDisposableEmailDomains(domains ...string) AbuseOption {
+	return func(s *AbuseService) {
+		for _, name := range domains {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				s.disposableDomains[name] = true
+			}
+		}
+	}
+}
+
+// WithSharedIPThreshold flags IP addresses that the given number of accounts
+// signed in from within the window. Values that are not positive keep the
+// defaults.
+func WithSharedIPThreshold(accounts int, window time.Duration) AbuseOption {
+	return func(s *AbuseService) {
+		if accounts > 0 {
+			s.sharedIPAccounts = accounts
+		}
+		if window > 0 {
+			s.sharedIPWindow = window
+		}
+	}
+}
+
+// NewAbuseService creates a new abuse service
+func NewAbuseService(repo domain.AccountSignalRepository, opts ...AbuseOption) *AbuseService {
+	s := &AbuseService{
+		repo:              repo,
+		disposableDomains: make(map[string]bool, len(disposableEmailDomains)),
+		sharedIPAccounts:  defaultSharedIPAccounts,
+		sharedIPWindow:    defaultSharedIPWindow,
+	}
+	for _, name := range disposableEmailDomains {
+		s.disposableDomains[name] = true
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// RecordSignal records that a user registered or logged in from an IP
+// address, located in country when geolocation is enabled, along with the
+// abuse heuristics it trips
+func (s *AbuseService) RecordSignal(ctx context.Context, kind domain.AccountSignalKind, user *domain.User, ip string, country string) (*domain.AccountSignal, error) {
+	now := time.Now()
+	signal := &domain.AccountSignal{
+		ID:        id.New(),
+		UserID:    user.ID,
+		Kind:      kind,
+		IP:        ip,
+		Country:   strings.ToUpper(country),
+		Flags:     []domain.AbuseFlag{},
+		CreatedAt: now,
+	}
+
+	if s.IsDisposableEmail(user.Email) {
+		signal.Flags = append(signal.Flags, domain.AbuseFlagDisposableEmail)
+	}
+
+	if ip != "" {
+		others, err := s.repo.CountAccountsByIP(ctx, ip, now.Add(-s.sharedIPWindow), user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if others+1 >= s.sharedIPAccounts {
+			signal.Flags = append(signal.Flags, domain.AbuseFlagSharedIP)
+		}
+	}
+
+	if err := s.repo.Create(ctx, signal); err != nil {
+		return nil, err
+	}
+	return signal, nil
+}
+
+// IsDisposableEmail reports whether an email address belongs to a throwaway
+// email provider
+func (s *AbuseService) IsDisposableEmail(email string) bool {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return false
+	}
+
+	// Check the domain and each parent domain
+	host := strings.ToLower(email[at+1:])
+	for host != "" {
+		if s.disposableDomains[host] {
+			return true
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			break
+		}
+		host = parent
+	}
+	return false
+}
+
+// ListUserSignals returns a user's most recent signals, newest first
+func (s *AbuseService) ListUserSignals(ctx context.Context, userID string, limit int) ([]*domain.AccountSignal, error) {
+	return s.repo.ListByUser(ctx, userID, signalLimit(limit))
+}
+
+// ListSharedIPs returns the IP addresses flagged as shared since a time,
+// most accounts first. A zero since covers the shared IP window.
+func (s *AbuseService) ListSharedIPs(ctx context.Context, since time.Time, limit int) ([]*domain.SharedIP, error) {
+	if since.IsZero() {
+		since = time.Now().Add(-s.sharedIPWindow)
+	}
+	return s.repo.ListSharedIPs(ctx, since, s.sharedIPAccounts, signalLimit(limit))
+}
+
+// signalLimit applies the default and maximum to a requested limit
+func signalLimit(limit int) int {
+	if limit <= 0 {
+		return defaultSignalLimit
+	}
+	return min(limit, maxSignalLimit)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"log"
+	"slices"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/validation"
+)
+
+// answerStrikesToMute is how many withheld answers get a player muted for
+// the rest of the game
+const answerStrikesToMute = 2
+
+// WithAnswerModerator checks fake answers with a moderation service as well
+// as the profanity filter before they enter the voting pool
+func WithAnswerModerator(moderator domain.AnswerModerator) GameServiceOption {
+	return func(s *GameService) {
+		s.moderator = moderator
+	}
+}
+
+// flagAnswer reports whether moderation withholds an answer. Answers are
+// let through when the moderation service fails, so that it cannot hold up
+// games.
+func (s *GameService) flagAnswer(ctx context.Context, game *domain.Game, answer string) bool {
+	if validation.ContainsProfanity(answer) {
+		return true
+	}
+	if s.moderator == nil {
+		return false
+	}
+
+	flagged, err := s.moderator.Flag(ctx, answer, game.Settings.QuestionLanguage())
+	if err != nil {
+		log.Printf("Failed to moderate answer in game %s: %v", game.Code, err)
+		return false
+	}
+	return flagged
+}
+
+// withholdAnswer records that moderation withheld a player's answer, which
+// counts as answering and leaves a filler to take its place. The player is
+// warned, and muted once they have had answerStrikesToMute answers withheld.
+// It reports whether the player was muted, which the hub must be told once
+// the change is committed.
+func withholdAnswer(change *gameChange, game *domain.Game, playerID string) (bool, error) {
+	i := slices.IndexFunc(game.Players, func(p domain.Player) bool { return p.ID == playerID })
+	if i < 0 {
+		return false, ErrPlayerNotInGame
+	}
+	player := game.Players[i]
+	strikes := player.AnswerStrikes + 1
+
+	if err := change.emit(domain.EventAnswerModerated, domain.AnswerModeratedPayload{PlayerID: playerID, Strikes: strikes}); err != nil {
+		return false, err
+	}
+
+	mute := strikes >= answerStrikesToMute && !player.Muted && playerID != game.HostID
+	if err := change.publishTo(playerID, "answer_moderated", map[string]any{
+		"strikes": strikes,
+		"muted":   mute || player.Muted,
+	}); err != nil {
+		return false, err
+	}
+	if !mute {
+		return false, nil
+	}
+
+	if err := change.emit(domain.EventPlayerMuted, domain.PlayerMutedPayload{PlayerID: playerID, Muted: true}); err != nil {
+		return false, err
+	}
+	if err := change.publish("player_muted", map[string]any{
+		"player_id": playerID,
+		"name":      player.Name,
+		"muted":     true,
+		"automatic": true,
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
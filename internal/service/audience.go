@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// CastAudienceVotes adds a batch of viewers' votes to a round, counted by
+// the number of each option in the voting pool, from 1. Votes for numbers
+// outside the pool are ignored. Audience votes score no points; they are
+// shown alongside the players' votes.
+func (s *GameService) CastAudienceVotes(ctx context.Context, code string, round int, votes map[int]int) error {
+	return retryConflicts(ctx, func() error {
+		game, err := s.GetGame(ctx, code)
+		if err != nil {
+			return err
+		}
+
+		if game.Status == domain.GameStatusEnded {
+			return ErrGameEnded
+		}
+		if len(game.Rounds) == 0 || game.Rounds[len(game.Rounds)-1].Number != round {
+			return ErrInvalidRound
+		}
+		if game.State() != domain.GameStateVoting {
+			return ErrRoundNotVoting
+		}
+		currentRound := game.Rounds[len(game.Rounds)-1]
+
+		options := currentRound.AnswerPool.VotingPool
+		counted := make(map[string]int, len(votes))
+		for number, count := range votes {
+			if number < 1 || number > len(options) || count <= 0 {
+				continue
+			}
+			counted[options[number-1].ID] += count
+		}
+		if len(counted) == 0 {
+			return nil
+		}
+
+		change := s.newChange(game)
+		if err := change.emit(domain.EventAudienceVoted, domain.AudienceVotedPayload{Votes: counted}); err != nil {
+			return err
+		}
+
+		if err := change.publish("audience_voted", map[string]any{
+			"round": round,
+			"votes": change.game.Rounds[len(change.game.Rounds)-1].AudienceVotes,
+		}); err != nil {
+			return err
+		}
+
+		return s.commit(ctx, change)
+	})
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/stream"
+)
+
+const (
+	// audienceFlushInterval is how often viewers' votes are added to the
+	// game, and how soon after voting opens they start being counted
+	audienceFlushInterval = time.Second
+
+	// chatRetryDelay is how long to wait before reconnecting to a chat
+	chatRetryDelay = 5 * time.Second
+)
+
+// Stream platforms whose chat can vote
+const (
+	PlatformTwitch  = "twitch"
+	PlatformYouTube = "youtube"
+)
+
+var (
+	// ErrPlatformUnavailable is returned when connecting a stream on a
+	// platform the server is not configured for
+	ErrPlatformUnavailable = errors.New("stream platform is not available")
+
+	// ErrInvalidStreamChannel is returned when a channel is not a Twitch
+	// channel name or YouTube video ID
+	ErrInvalidStreamChannel = errors.New("invalid stream channel")
+
+	// ErrStreamNotConnected is returned when disconnecting a game that has
+	// no stream connected
+	ErrStreamNotConnected = errors.New("no stream is connected to the game")
+)
+
+// streamChannelPattern matches Twitch channel names and YouTube video IDs,
+// keeping anything that could alter a chat protocol's commands out
+var streamChannelPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// AudienceStreamService connects games to the chat of the host's live
+// stream, counting viewers' votes as audience votes. Connections live in
+// this process and end with the game.
+type AudienceStreamService struct {
+	games         domain.GameService
+	youtubeAPIKey string
+
+	mu      sync.Mutex
+	streams map[string]*audienceStream // Connected games by ID
+}
+
+// audienceStream is a game's connection to a stream's chat
+type audienceStream struct {
+	cancel context.CancelFunc
+}
+
+// NewAudienceStreamService creates a new audience stream service. YouTube
+// streams can only be connected with an API key.
+func NewAudienceStreamService(games domain.GameService, youtubeAPIKey string) *AudienceStreamService {
+	return &AudienceStreamService{
+		games:         games,
+		youtubeAPIKey: youtubeAPIKey,
+		streams:       make(map[string]*audienceStream),
+	}
+}
+
+// Connect starts counting votes from a stream's chat in a game, replacing
+// any stream already connected. The channel is a Twitch channel name or a
+// YouTube video ID. Only the host may connect a stream.
+func (s *AudienceStreamService) Connect(ctx context.Context, code string, callerID string, platform string, channel string) error {
+	game, err := s.games.GetGame(ctx, code)
+	if err != nil {
+		return err
+	}
+	if err := authorizeHost(game, callerID); err != nil {
+		return err
+	}
+	if game.Status == domain.GameStatusEnded {
+		return ErrGameEnded
+	}
+
+	channel = strings.TrimPrefix(channel, "#")
+	if !streamChannelPattern.MatchString(channel) {
+		return ErrInvalidStreamChannel
+	}
+
+	var source stream.ChatSource
+	switch {
+	case platform == PlatformTwitch:
+		source = stream.NewTwitchChat(channel)
+	case platform == PlatformYouTube && s.youtubeAPIKey != "":
+		source = stream.NewYouTubeChat(s.youtubeAPIKey, channel)
+	default:
+		return ErrPlatformUnavailable
+	}
+
+	// The connection outlives the request that made it
+	streamCtx, cancel := context.WithCancel(context.Background())
+	conn := &audienceStream{cancel: cancel}
+	s.mu.Lock()
+	if previous, ok := s.streams[game.ID]; ok {
+		previous.cancel()
+	}
+	s.streams[game.ID] = conn
+	s.mu.Unlock()
+
+	go s.run(streamCtx, conn, game.ID, source)
+	return nil
+}
+
+// Disconnect stops counting votes from a game's stream. Only the host may
+// disconnect it.
+func (s *AudienceStreamService) Disconnect(ctx context.Context, code string, callerID string) error {
+	game, err := s.games.GetGame(ctx, code)
+	if err != nil {
+		return err
+	}
+	if err := authorizeHost(game, callerID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conn, ok := s.streams[game.ID]
+	if !ok {
+		return ErrStreamNotConnected
+	}
+	conn.cancel()
+	delete(s.streams, game.ID)
+	return nil
+}
+
+// run reads a stream's chat into a game until the stream is disconnected or
+// the game ends. Each viewer's first vote in a round's voting phase counts.
+func (s *AudienceStreamService) run(ctx context.Context, conn *audienceStream, gameID string, source stream.ChatSource) {
+	defer s.forget(gameID, conn)
+
+	messages := make(chan stream.ChatMessage, 256)
+	go s.read(ctx, conn, gameID, source, messages)
+
+	ticker := time.NewTicker(audienceFlushInterval)
+	defer ticker.Stop()
+
+	var round int
+	var voting bool
+	voted := make(map[string]bool)
+	pending := make(map[int]int)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case msg := <-messages:
+			number, ok := stream.ParseVote(msg.Text)
+			if !voting || !ok || voted[msg.Viewer] {
+				continue
+			}
+			voted[msg.Viewer] = true
+			pending[number]++
+
+		case <-ticker.C:
+			game, err := s.games.GetGame(ctx, gameID)
+			if errors.Is(err, ErrGameNotFound) {
+				return
+			}
+			if err != nil {
+				fmt.Printf("Failed to get game %s for its stream: %v\n", gameID, err)
+				continue
+			}
+			if game.Status == domain.GameStatusEnded {
+				return
+			}
+			if len(game.Rounds) == 0 {
+				continue
+			}
+
+			current := game.Rounds[len(game.Rounds)-1]
+			if current.Number != round {
+				round = current.Number
+				clear(voted)
+				clear(pending)
+			}
+			voting = current.Status == domain.RoundStatusVoting
+			if len(pending) == 0 {
+				continue
+			}
+
+			err = s.games.CastAudienceVotes(ctx, gameID, round, pending)
+			if err != nil && !errors.Is(err, ErrRoundNotVoting) && !errors.Is(err, ErrInvalidRound) {
+				fmt.Printf("Failed to count stream votes in game %s: %v\n", gameID, err)
+			}
+			clear(pending)
+		}
+	}
+}
+
+// read reads a stream's chat, reconnecting after failures until the stream
+// is stopped or its chat is over
+func (s *AudienceStreamService) read(ctx context.Context, conn *audienceStream, gameID string, source stream.ChatSource, messages chan<- stream.ChatMessage) {
+	for {
+		err := source.Read(ctx, messages)
+		if ctx.Err() != nil {
+			return
+		}
+		fmt.Printf("Stream chat of game %s stopped: %v\n", gameID, err)
+		if errors.Is(err, stream.ErrNotLive) {
+			conn.cancel()
+			return
+		}
+
+		select {
+		case <-time.After(chatRetryDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// forget removes a game's stream once it stops, unless it has already been
+// replaced by another
+func (s *AudienceStreamService) forget(gameID string, conn *audienceStream) {
+	conn.cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.streams[gameID] == conn {
+		delete(s.streams, gameID)
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/id"
+)
+
+// Ban errors
+var (
+	ErrUserBanned    = errors.New("this account is banned")
+	ErrUserSuspended = errors.New("this account is suspended")
+	ErrBanNotFound   = domain.ErrBanNotFound
+	ErrInvalidBan    = errors.New("bans need a reason, and suspensions a future expiry")
+)
+
+// defaultModerationLogLimit and maxModerationLogLimit bound the moderation
+// log entries listed at once
+const (
+	defaultModerationLogLimit = 50
+	maxModerationLogLimit     = 500
+)
+
+// BanError is returned when a suspended or banned user logs in, joins a game
+// or connects to one. It matches ErrUserSuspended or ErrUserBanned.
+type BanError struct {
+	Ban *domain.UserBan
+}
+
+// Error describes the ban
+func (e *BanError) Error() string {
+	return e.Unwrap().Error() + ": " + e.Ban.Reason
+}
+
+// Unwrap returns the error for the kind of ban
+func (e *BanError) Unwrap() error {
+	if e.Ban.Kind == domain.BanKindSuspension {
+		return ErrUserSuspended
+	}
+	return ErrUserBanned
+}
+
+// BanService suspends and bans users, logging every moderator action, and
+// tells the rest of the services whether a user is kept out
+type BanService struct {
+	repo     domain.UserBanRepository
+	userRepo domain.UserRepository
+}
+
+// NewBanService creates a new ban service
+func NewBanService(repo domain.UserBanRepository, userRepo domain.UserRepository) *BanService {
+	return &BanService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// IssueBanRequest represents a moderator suspending or banning a user
+type IssueBanRequest struct {
+	Kind      domain.BanKind `json:"kind" validate:"required,oneof=suspension ban"`
+	Reason    string         `json:"reason" validate:"required,max=500"`
+	ExpiresAt *time.Time     `json:"expires_at"` // Required for suspensions
+	Moderator string         `json:"moderator" validate:"required,max=100"`
+}
+
+// Check returns a *BanError when the user is suspended or banned. Guests and
+// other players without an account are never kept out.
+func (s *BanService) Check(ctx context.Context, userID string) error {
+	if userID == "" {
+		return nil
+	}
+
+	ban, err := s.repo.GetActive(ctx, userID, time.Now())
+	if err != nil {
+		if errors.Is(err, domain.ErrBanNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to check bans: %w", err)
+	}
+	return &BanError{Ban: ban}
+}
+
+// IssueBan suspends a user until an expiry or bans them for good
+func (s *BanService) IssueBan(ctx context.Context, userID string, req IssueBanRequest) (*domain.UserBan, error) {
+	if req.Reason == "" {
+		return nil, ErrInvalidBan
+	}
+
+	now := time.Now()
+	switch req.Kind {
+	case domain.BanKindSuspension:
+		if req.ExpiresAt == nil || !req.ExpiresAt.After(now) {
+			return nil, ErrInvalidBan
+		}
+	case domain.BanKindBan:
+		if req.ExpiresAt != nil {
+			return nil, ErrInvalidBan
+		}
+	default:
+		return nil, ErrInvalidBan
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	ban := &domain.UserBan{
+		ID:        id.New(),
+		UserID:    userID,
+		Kind:      req.Kind,
+		Reason:    req.Reason,
+		IssuedBy:  req.Moderator,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: now,
+	}
+
+	action, details := domain.ModerationActionBan, req.Reason
+	if req.Kind == domain.BanKindSuspension {
+		action = domain.ModerationActionSuspend
+		details = fmt.Sprintf("%s (until %s)", req.Reason, req.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	if err := s.repo.Create(ctx, ban, moderationLogEntry(action, ban, req.Moderator, details, now)); err != nil {
+		return nil, err
+	}
+	return ban, nil
+}
+
+// ListBans returns a user's bans, newest first
+func (s *BanService) ListBans(ctx context.Context, userID string) ([]*domain.UserBan, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// LiftBan ends a ban early
+func (s *BanService) LiftBan(ctx context.Context, banID string, moderator string, note string) (*domain.UserBan, error) {
+	ban, err := s.get(ctx, banID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.repo.Lift(ctx, banID, moderator, now, moderationLogEntry(domain.ModerationActionLift, ban, moderator, note, now)); err != nil {
+		return nil, err
+	}
+	ban.LiftedAt, ban.LiftedBy = &now, moderator
+	return ban, nil
+}
+
+// UpdateAppealNote records moderators' notes on a user's appeal of a ban
+func (s *BanService) UpdateAppealNote(ctx context.Context, banID string, moderator string, note string) (*domain.UserBan, error) {
+	ban, err := s.get(ctx, banID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := moderationLogEntry(domain.ModerationActionAppealNote, ban, moderator, note, time.Now())
+	if err := s.repo.UpdateAppealNote(ctx, banID, note, entry); err != nil {
+		return nil, err
+	}
+	ban.AppealNote = note
+	return ban, nil
+}
+
+// ListModerationLog returns the most recent moderator actions, newest first,
+// only on a user when userID is set
+func (s *BanService) ListModerationLog(ctx context.Context, userID string, limit int) ([]*domain.ModerationLogEntry, error) {
+	if limit <= 0 {
+		limit = defaultModerationLogLimit
+	}
+	return s.repo.ListLog(ctx, userID, min(limit, maxModerationLogLimit))
+}
+
+// get returns a ban by ID
+func (s *BanService) get(ctx context.Context, banID string) (*domain.UserBan, error) {
+	if !id.IsUUID(banID) {
+		return nil, ErrBanNotFound
+	}
+	return s.repo.Get(ctx, banID)
+}
+
+// moderationLogEntry builds the moderation log entry for an action on a ban
+func moderationLogEntry(action domain.ModerationAction, ban *domain.UserBan, actor string, details string, at time.Time) *domain.ModerationLogEntry {
+	return &domain.ModerationLogEntry{
+		ID:        id.New(),
+		Action:    action,
+		UserID:    ban.UserID,
+		BanID:     ban.ID,
+		Actor:     actor,
+		Details:   details,
+		CreatedAt: at,
+	}
+}
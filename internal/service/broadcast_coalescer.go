@@ -0,0 +1,140 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// coalescedTypes are the messages carrying a game's whole state, which
+// supersede any of the same type sent before them
+var coalescedTypes = map[string]bool{
+	"game_updated":    true,
+	"display_updated": true,
+}
+
+// BroadcastCoalescer decorates a broadcaster, coalescing the state updates
+// of games that change in quick succession, such as when every player
+// answers within a second. A game's first update goes out at once; updates
+// following it within the window are held, and only the latest is sent when
+// the window ends. Any other message to the game, such as a phase change,
+// first flushes the held updates, so clients see them in order.
+type BroadcastCoalescer struct {
+	domain.GameBroadcaster
+	window time.Duration
+
+	mu    sync.Mutex
+	games map[string]map[coalescedStream]*coalescedUpdate // Streams within their window, by game ID
+}
+
+// coalescedStream identifies the state updates of one type to one audience
+// of a game
+type coalescedStream struct {
+	audience    domain.MessageAudience
+	messageType string
+}
+
+// coalescedUpdate is the state of a stream within its window
+type coalescedUpdate struct {
+	payload []byte      // Latest update held back, nil if none
+	timer   *time.Timer // Ends the window
+}
+
+// NewBroadcastCoalescer creates a coalescing decorator around a broadcaster,
+// sending each game at most one state update of each type per window
+func NewBroadcastCoalescer(broadcaster domain.GameBroadcaster, window time.Duration) *BroadcastCoalescer {
+	return &BroadcastCoalescer{
+		GameBroadcaster: broadcaster,
+		window:          window,
+		games:           make(map[string]map[coalescedStream]*coalescedUpdate),
+	}
+}
+
+// BroadcastToGame sends an event to every client in a game
+func (b *BroadcastCoalescer) BroadcastToGame(gameID string, messageType string, payload []byte) {
+	b.broadcast(gameID, coalescedStream{domain.AudienceEveryone, messageType}, payload)
+}
+
+// BroadcastToAudience sends an event only to one kind of client in a game
+func (b *BroadcastCoalescer) BroadcastToAudience(gameID string, audience domain.MessageAudience, messageType string, payload []byte) {
+	b.broadcast(gameID, coalescedStream{audience, messageType}, payload)
+}
+
+// CloseGame flushes a game's held updates and disconnects its clients
+func (b *BroadcastCoalescer) CloseGame(gameID string) {
+	b.mu.Lock()
+	b.flushGame(gameID)
+	b.mu.Unlock()
+
+	b.GameBroadcaster.CloseGame(gameID)
+}
+
+// broadcast sends or holds a message to a game's audience
+func (b *BroadcastCoalescer) broadcast(gameID string, stream coalescedStream, payload []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !coalescedTypes[stream.messageType] {
+		b.flushGame(gameID)
+		b.send(gameID, stream, payload)
+		return
+	}
+
+	if update, ok := b.games[gameID][stream]; ok {
+		update.payload = payload
+		return
+	}
+
+	b.send(gameID, stream, payload)
+	if b.games[gameID] == nil {
+		b.games[gameID] = make(map[coalescedStream]*coalescedUpdate)
+	}
+	update := &coalescedUpdate{}
+	update.timer = time.AfterFunc(b.window, func() { b.endWindow(gameID, stream, update) })
+	b.games[gameID][stream] = update
+}
+
+// endWindow sends a stream's held update, if any, and starts a new window
+// after it. Streams with nothing held are forgotten.
+func (b *BroadcastCoalescer) endWindow(gameID string, stream coalescedStream, update *coalescedUpdate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// The stream may have been flushed since the timer fired
+	if b.games[gameID][stream] != update {
+		return
+	}
+	if update.payload == nil {
+		delete(b.games[gameID], stream)
+		if len(b.games[gameID]) == 0 {
+			delete(b.games, gameID)
+		}
+		return
+	}
+
+	b.send(gameID, stream, update.payload)
+	update.payload = nil
+	update.timer.Reset(b.window)
+}
+
+// flushGame sends every update held for a game and forgets its streams. The
+// caller must hold the lock.
+func (b *BroadcastCoalescer) flushGame(gameID string) {
+	for stream, update := range b.games[gameID] {
+		if update.payload != nil {
+			b.send(gameID, stream, update.payload)
+		}
+		update.timer.Stop()
+	}
+	delete(b.games, gameID)
+}
+
+// send passes a message on to the decorated broadcaster
+func (b *BroadcastCoalescer) send(gameID string, stream coalescedStream, payload []byte) {
+	if stream.audience == domain.AudienceEveryone {
+		b.GameBroadcaster.BroadcastToGame(gameID, stream.messageType, payload)
+		return
+	}
+	b.GameBroadcaster.BroadcastToAudience(gameID, stream.audience, stream.messageType, payload)
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// Cosmetic errors
+var (
+	ErrCosmeticNotFound = domain.ErrCosmeticNotFound
+	ErrCosmeticLocked   = domain.ErrCosmeticLocked
+	ErrInvalidCosmetic  = errors.New("cosmetic does not fit this slot")
+)
+
+// CosmeticStatus is a cosmetic of the catalog and whether a user has
+// unlocked and equipped it
+type CosmeticStatus struct {
+	domain.Cosmetic
+	Unlocked   bool       `json:"unlocked"`
+	Equipped   bool       `json:"equipped"`
+	UnlockedAt *time.Time `json:"unlocked_at,omitempty"`
+}
+
+// CosmeticCollection is a user's progress through the cosmetics catalog
+type CosmeticCollection struct {
+	Level     int                      `json:"level"`
+	Career    *domain.CareerStats      `json:"career"`
+	Equipped  domain.EquippedCosmetics `json:"equipped"`
+	Cosmetics []CosmeticStatus         `json:"cosmetics"`
+}
+
+// CosmeticService unlocks cosmetics as users reach their achievements and
+// lets users choose the cosmetics they wear in games. Achievements are
+// checked against the user's finished games whenever their cosmetics are
+// looked at or changed.
+type CosmeticService struct {
+	repo       domain.CosmeticRepository
+	resultRepo domain.GameResultRepository
+}
+
+// NewCosmeticService creates a new cosmetic service
+func NewCosmeticService(repo domain.CosmeticRepository, resultRepo domain.GameResultRepository) *CosmeticService {
+	return &CosmeticService{
+		repo:       repo,
+		resultRepo: resultRepo,
+	}
+}
+
+// GetCollection returns a user's level and the cosmetics catalog with those
+// they have unlocked and equipped, unlocking any they have newly earned
+func (s *CosmeticService) GetCollection(ctx context.Context, userID string) (*CosmeticCollection, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	career, unlocks, err := s.sync(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &CosmeticCollection{
+		Level:     career.Level(),
+		Career:    career,
+		Equipped:  equippedCosmetics(unlocks),
+		Cosmetics: make([]CosmeticStatus, 0, len(domain.Cosmetics)),
+	}
+	for _, cosmetic := range domain.Cosmetics {
+		status := CosmeticStatus{Cosmetic: cosmetic}
+		if i := slices.IndexFunc(unlocks, func(u *domain.CosmeticUnlock) bool { return u.Cosmetic == cosmetic.ID }); i >= 0 {
+			status.Unlocked, status.Equipped, status.UnlockedAt = true, unlocks[i].Equipped, &unlocks[i].UnlockedAt
+		}
+		collection.Cosmetics = append(collection.Cosmetics, status)
+	}
+	return collection, nil
+}
+
+// Equip wears one of a user's cosmetics in the slot of a kind, or takes off
+// the slot's cosmetic when cosmeticID is empty. Games the user joins from
+// then on show it to every player.
+func (s *CosmeticService) Equip(ctx context.Context, userID string, kind domain.CosmeticKind, cosmeticID string) (*domain.EquippedCosmetics, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+	if !slices.Contains(domain.CosmeticKinds, kind) {
+		return nil, ErrInvalidCosmetic
+	}
+	if cosmeticID != "" {
+		cosmetic, ok := domain.FindCosmetic(cosmeticID)
+		if !ok {
+			return nil, ErrCosmeticNotFound
+		}
+		if cosmetic.Kind != kind {
+			return nil, ErrInvalidCosmetic
+		}
+	}
+
+	if _, _, err := s.sync(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	var kindCosmetics []string
+	for _, cosmetic := range domain.Cosmetics {
+		if cosmetic.Kind == kind {
+			kindCosmetics = append(kindCosmetics, cosmetic.ID)
+		}
+	}
+	if err := s.repo.Equip(ctx, userID, cosmeticID, kindCosmetics); err != nil {
+		return nil, err
+	}
+	return s.Equipped(ctx, userID)
+}
+
+// Equipped returns the cosmetics a user wears. Players without an account
+// wear none.
+func (s *CosmeticService) Equipped(ctx context.Context, userID string) (*domain.EquippedCosmetics, error) {
+	if userID == "" {
+		return &domain.EquippedCosmetics{}, nil
+	}
+	unlocks, err := s.repo.ListUnlocked(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	equipped := equippedCosmetics(unlocks)
+	return &equipped, nil
+}
+
+// unlock records cosmetics as unlocked by a user outside of achievements,
+// such as by a promo code
+func (s *CosmeticService) unlock(ctx context.Context, userID string, cosmetics ...string) error {
+	return s.repo.Unlock(ctx, userID, cosmetics, time.Now())
+}
+
+// sync unlocks the cosmetics a user has earned with their career but not yet
+// been given, returning the career and every cosmetic they have unlocked
+func (s *CosmeticService) sync(ctx context.Context, userID string) (*domain.CareerStats, []*domain.CosmeticUnlock, error) {
+	career, err := s.resultRepo.GetCareerStats(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	unlocks, err := s.repo.ListUnlocked(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var earned []string
+	for _, cosmetic := range domain.Cosmetics {
+		if cosmetic.Requirement == nil || !cosmetic.Requirement.MetBy(career) {
+			continue
+		}
+		if !slices.ContainsFunc(unlocks, func(u *domain.CosmeticUnlock) bool { return u.Cosmetic == cosmetic.ID }) {
+			earned = append(earned, cosmetic.ID)
+		}
+	}
+	if len(earned) == 0 {
+		return career, unlocks, nil
+	}
+
+	if err := s.unlock(ctx, userID, earned...); err != nil {
+		return nil, nil, err
+	}
+	unlocks, err = s.repo.ListUnlocked(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return career, unlocks, nil
+}
+
+// equippedCosmetics returns the cosmetics worn among a user's unlocked ones
+func equippedCosmetics(unlocks []*domain.CosmeticUnlock) domain.EquippedCosmetics {
+	var equipped domain.EquippedCosmetics
+	for _, unlock := range unlocks {
+		if cosmetic, ok := domain.FindCosmetic(unlock.Cosmetic); ok && unlock.Equipped {
+			equipped.Set(cosmetic.Kind, cosmetic.ID)
+		}
+	}
+	return equipped
+}
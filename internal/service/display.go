@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// GetDisplayView retrieves the sanitized view of a game shown on shared
+// screens in display mode
+func (s *GameService) GetDisplayView(ctx context.Context, code string) (*domain.DisplayView, error) {
+	game, err := s.GetGame(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return newDisplayView(game), nil
+}
+
+// newDisplayView builds a game's display view: its summary, the current
+// round as players may see it, the live vote tally and the pause before the
+// next round
+func newDisplayView(game *domain.Game) *domain.DisplayView {
+	view := &domain.DisplayView{GameSummary: game.Summary()}
+	if len(game.Rounds) == 0 || game.Status == domain.GameStatusWaiting {
+		return view
+	}
+
+	round := game.Rounds[len(game.Rounds)-1]
+	view.CurrentRound = newRoundView(game, round, "")
+	if tally := round.AnswerPool.Tally(); len(tally) > 0 {
+		view.Tally = tally
+	}
+	view.Intermission = game.Intermission
+	return view
+}
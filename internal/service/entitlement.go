@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/id"
+)
+
+// Entitlement errors
+var (
+	ErrPremiumPackNotFound = domain.ErrPremiumPackNotFound
+	ErrEntitlementNotFound = domain.ErrEntitlementNotFound
+	ErrInvalidPremiumPack  = errors.New("premium packs need an ID, a name and categories no other premium pack has")
+	ErrPremiumPackNotOwned = errors.New("the game's categories include a premium pack the player does not own")
+	ErrEntitlementExists   = domain.ErrEntitlementExists
+	ErrGiftToSelf          = errors.New("premium packs cannot be gifted to yourself")
+	ErrGiftLimitReached    = domain.ErrGiftLimitReached
+	ErrGiftTooRecent       = errors.New("premium packs received as gifts cannot be gifted again yet")
+)
+
+// Gifting limits, against accounts used to pass packs around
+const (
+	// giftsPerDay is how many packs a user may gift a day
+	giftsPerDay = 3
+
+	// giftCooldown is how long a gifted pack is kept before it can be
+	// gifted again
+	giftCooldown = 7 * 24 * time.Hour
+)
+
+// defaultEntitlementLogLimit and maxEntitlementLogLimit bound the
+// entitlement log entries listed at once
+const (
+	defaultEntitlementLogLimit = 50
+	maxEntitlementLogLimit     = 500
+)
+
+// EntitlementService manages premium packs and who owns them, and checks
+// that players own the premium packs of the games they host and join. Until
+// a payment provider is added, packs are granted by admins and passed on by
+// users as gifts. Every change is recorded in the entitlement log.
+type EntitlementService struct {
+	repo     domain.EntitlementRepository
+	userRepo domain.UserRepository
+	hub      domain.GameBroadcaster
+	policy   domain.PremiumPackPolicy
+}
+
+// NewEntitlementService creates a new entitlement service enforcing a
+// policy on who in a game must own its premium packs, the host's when empty.
+// Gifts are pushed to their recipients over the hub.
+func NewEntitlementService(repo domain.EntitlementRepository, userRepo domain.UserRepository, hub domain.GameBroadcaster, policy domain.PremiumPackPolicy) *EntitlementService {
+	if policy == "" {
+		policy = domain.PremiumPackPolicyHost
+	}
+	return &EntitlementService{
+		repo:     repo,
+		userRepo: userRepo,
+		hub:      hub,
+		policy:   policy,
+	}
+}
+
+// SavePackRequest represents an admin creating or replacing a premium pack
+type SavePackRequest struct {
+	Name       string   `json:"name" validate:"required,max=100"`
+	Categories []string `json:"categories" validate:"required,min=1,dive,required,max=50"`
+}
+
+// SavePack creates or replaces a premium pack. A category can only be in one
+// premium pack. Games already created keep the packs they were created with.
+func (s *EntitlementService) SavePack(ctx context.Context, packID string, req SavePackRequest) (*domain.PremiumPack, error) {
+	return s.savePack(ctx, packID, "", req)
+}
+
+// SaveTenantPack creates or replaces a premium pack private to the
+// organization a user administers. Its categories must be named after the
+// organization, as in acme/onboarding, so organizations cannot claim
+// categories everyone plays.
+func (s *EntitlementService) SaveTenantPack(ctx context.Context, actorID string, packID string, req SavePackRequest) (*domain.PremiumPack, error) {
+	if actorID == "" {
+		return nil, ErrUnauthenticated
+	}
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsTenantAdmin() {
+		return nil, ErrNotTenantAdmin
+	}
+
+	for _, category := range req.Categories {
+		if !strings.HasPrefix(category, actor.TenantID+"/") {
+			return nil, fmt.Errorf("%w: %s must start with %s/", ErrInvalidPremiumPack, category, actor.TenantID)
+		}
+	}
+	return s.savePack(ctx, packID, actor.TenantID, req)
+}
+
+// savePack creates or replaces a premium pack, private to an organization if
+// tenantID is set. Packs cannot be moved between organizations, or made
+// private or public once saved.
+func (s *EntitlementService) savePack(ctx context.Context, packID string, tenantID string, req SavePackRequest) (*domain.PremiumPack, error) {
+	if packID == "" || len(packID) > 50 || req.Name == "" || len(req.Categories) == 0 {
+		return nil, ErrInvalidPremiumPack
+	}
+
+	packs, err := s.repo.ListPacks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pack := range packs {
+		if pack.ID == packID {
+			if pack.TenantID != tenantID {
+				return nil, fmt.Errorf("%w: %s is taken", ErrInvalidPremiumPack, packID)
+			}
+			continue
+		}
+		for _, category := range req.Categories {
+			if slices.Contains(pack.Categories, category) {
+				return nil, fmt.Errorf("%w: %s is in %s", ErrInvalidPremiumPack, category, pack.ID)
+			}
+		}
+	}
+
+	categories := slices.Clone(req.Categories)
+	slices.Sort(categories)
+
+	now := time.Now()
+	pack := &domain.PremiumPack{
+		ID:         packID,
+		Name:       req.Name,
+		Categories: slices.Compact(categories),
+		TenantID:   tenantID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.repo.SavePack(ctx, pack); err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// ListPacks returns every premium pack
+func (s *EntitlementService) ListPacks(ctx context.Context) ([]*domain.PremiumPack, error) {
+	return s.repo.ListPacks(ctx)
+}
+
+// ListVisiblePacks returns the premium packs anyone can own, followed by
+// those private to the user's organization. Anonymous callers only get the
+// former.
+func (s *EntitlementService) ListVisiblePacks(ctx context.Context, userID string) ([]*domain.PremiumPack, error) {
+	packs, err := s.repo.ListPacks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantID := ""
+	if userID != "" {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+			return nil, err
+		}
+		if user != nil {
+			tenantID = user.TenantID
+		}
+	}
+
+	visible := slices.DeleteFunc(packs, func(pack *domain.PremiumPack) bool {
+		return pack.TenantID != "" && pack.TenantID != tenantID
+	})
+	slices.SortStableFunc(visible, func(a, b *domain.PremiumPack) int {
+		return strings.Compare(a.TenantID, b.TenantID)
+	})
+	return visible, nil
+}
+
+// GetPack returns a premium pack
+func (s *EntitlementService) GetPack(ctx context.Context, packID string) (*domain.PremiumPack, error) {
+	return s.repo.GetPack(ctx, packID)
+}
+
+// Grant gives a user a premium pack on an admin's behalf. Granting a pack the
+// user already owns changes nothing.
+func (s *EntitlementService) Grant(ctx context.Context, userID string, packID string, grantedBy string) (*domain.Entitlement, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetPack(ctx, packID); err != nil {
+		return nil, err
+	}
+
+	return s.grant(ctx, userID, packID, domain.EntitlementSourceGrant, grantedBy)
+}
+
+// owns returns whether a user owns a premium pack
+func (s *EntitlementService) owns(ctx context.Context, userID string, packID string) (bool, error) {
+	_, err := s.repo.Get(ctx, userID, packID)
+	if errors.Is(err, domain.ErrEntitlementNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// grant gives a user a premium pack, returning the existing entitlement if
+// they already own it
+func (s *EntitlementService) grant(ctx context.Context, userID string, packID string, source domain.EntitlementSource, grantedBy string) (*domain.Entitlement, error) {
+	now := time.Now()
+	entitlement := &domain.Entitlement{
+		UserID:    userID,
+		PackID:    packID,
+		Source:    source,
+		GrantedBy: grantedBy,
+		GrantedAt: now,
+	}
+	err := s.repo.Grant(ctx, entitlement, entitlementLogEntry(domain.EntitlementActionGrant, userID, packID, grantedBy, now))
+	if errors.Is(err, domain.ErrEntitlementExists) {
+		return s.repo.Get(ctx, userID, packID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entitlement, nil
+}
+
+// Revoke takes a premium pack from a user on an admin's behalf. Games they
+// already host or play are not affected.
+func (s *EntitlementService) Revoke(ctx context.Context, userID string, packID string, revokedBy string) error {
+	return s.repo.Revoke(ctx, userID, packID, entitlementLogEntry(domain.EntitlementActionRevoke, userID, packID, revokedBy, time.Now()))
+}
+
+// Gift gives up a user's premium pack to another user, who is notified of
+// it. Users may gift a few packs a day, and only packs they did not receive
+// as a gift recently.
+func (s *EntitlementService) Gift(ctx context.Context, fromUserID string, toUserID string, packID string) (*domain.Entitlement, error) {
+	if fromUserID == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	// Accept any casing of UUIDs as well as legacy IDs
+	toUserID, err := id.Parse(toUserID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+	if toUserID == fromUserID {
+		return nil, ErrGiftToSelf
+	}
+	if _, err := s.userRepo.GetByID(ctx, toUserID); err != nil {
+		return nil, err
+	}
+
+	owned, err := s.repo.Get(ctx, fromUserID, packID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if owned.Source == domain.EntitlementSourceGift && now.Sub(owned.GrantedAt) < giftCooldown {
+		return nil, ErrGiftTooRecent
+	}
+
+	entitlement := &domain.Entitlement{
+		UserID:    toUserID,
+		PackID:    packID,
+		Source:    domain.EntitlementSourceGift,
+		GrantedBy: fromUserID,
+		GrantedAt: now,
+	}
+	entry := entitlementLogEntry(domain.EntitlementActionGift, toUserID, packID, fromUserID, now)
+	entry.FromUserID = fromUserID
+	if err := s.repo.Transfer(ctx, fromUserID, entitlement, entry, giftsPerDay, now.Add(-24*time.Hour)); err != nil {
+		return nil, err
+	}
+
+	s.notify(toUserID, "pack_gifted", entitlement)
+	return entitlement, nil
+}
+
+// ListLog returns a user's most recent entitlement changes, newest first,
+// including the packs they gifted
+func (s *EntitlementService) ListLog(ctx context.Context, userID string, limit int) ([]*domain.EntitlementLogEntry, error) {
+	if limit <= 0 {
+		limit = defaultEntitlementLogLimit
+	}
+	return s.repo.ListLog(ctx, userID, min(limit, maxEntitlementLogLimit))
+}
+
+// ListEntitlements returns the premium packs a user owns
+func (s *EntitlementService) ListEntitlements(ctx context.Context, userID string) ([]*domain.Entitlement, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// RequiredPacks returns the IDs of the premium packs a game's categories
+// belong to, checking the host's account owns them. userID must be the
+// authenticated host, never an ID a client supplied.
+func (s *EntitlementService) RequiredPacks(ctx context.Context, userID string, categories []string) ([]string, error) {
+	packs, err := s.repo.ListPacks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get premium packs: %w", err)
+	}
+
+	var required []string
+	for _, pack := range packs {
+		if slices.ContainsFunc(categories, func(category string) bool {
+			return slices.Contains(pack.Categories, category)
+		}) {
+			required = append(required, pack.ID)
+		}
+	}
+	if len(required) == 0 {
+		return nil, nil
+	}
+
+	if err := s.checkOwned(ctx, userID, required); err != nil {
+		return nil, err
+	}
+	return required, nil
+}
+
+// CheckJoin returns ErrPremiumPackNotOwned when the policy has every player
+// own a game's premium packs and the joining player's account does not.
+// userID must be the authenticated player, never an ID a client supplied.
+func (s *EntitlementService) CheckJoin(ctx context.Context, userID string, packIDs []string) error {
+	if s.policy != domain.PremiumPackPolicyAll || len(packIDs) == 0 {
+		return nil
+	}
+	return s.checkOwned(ctx, userID, packIDs)
+}
+
+// checkOwned returns ErrPremiumPackNotOwned unless a user owns every one of
+// the premium packs, either through an entitlement or as a member of the
+// organization a pack is private to. Players without an account own none.
+func (s *EntitlementService) checkOwned(ctx context.Context, userID string, packIDs []string) error {
+	if userID == "" {
+		return ErrPremiumPackNotOwned
+	}
+
+	entitlements, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get entitlements: %w", err)
+	}
+	var user *domain.User
+	for _, packID := range packIDs {
+		if slices.ContainsFunc(entitlements, func(e *domain.Entitlement) bool { return e.PackID == packID }) {
+			continue
+		}
+
+		pack, err := s.repo.GetPack(ctx, packID)
+		if err != nil {
+			return fmt.Errorf("failed to get premium pack: %w", err)
+		}
+		if pack.TenantID != "" {
+			if user == nil {
+				if user, err = s.userRepo.GetByID(ctx, userID); err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+					return fmt.Errorf("failed to get user: %w", err)
+				}
+			}
+			if user != nil && user.TenantID == pack.TenantID {
+				continue
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrPremiumPackNotOwned, packID)
+	}
+	return nil
+}
+
+// notify pushes an entitlement event to a user
+func (s *EntitlementService) notify(userID string, eventType string, entitlement *domain.Entitlement) {
+	payload, err := json.Marshal(entitlement)
+	if err != nil {
+		log.Printf("Failed to marshal entitlement: %v", err)
+		return
+	}
+	s.hub.BroadcastToGame(UserChannel(userID), eventType, payload)
+}
+
+// entitlementLogEntry builds the entitlement log entry for a change to a
+// user's entitlement
+func entitlementLogEntry(action domain.EntitlementAction, userID string, packID string, actor string, at time.Time) *domain.EntitlementLogEntry {
+	return &domain.EntitlementLogEntry{
+		ID:        id.New(),
+		Action:    action,
+		UserID:    userID,
+		PackID:    packID,
+		Actor:     actor,
+		CreatedAt: at,
+	}
+}
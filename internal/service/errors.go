@@ -1,12 +1,23 @@
 package service
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
 
 // Common service errors
 var (
 	ErrUserNotFound       = errors.New("user not found")
 	ErrUserAlreadyExists  = errors.New("user already exists")
 	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrInviteNotFound     = errors.New("invitation not found")
+	ErrInvalidSession     = domain.ErrInvalidSession
+	ErrInvalidPreferences = errors.New("invalid preferences")
+	ErrInvalidUsername    = errors.New("invalid username")
+	ErrInvalidDisplayName = errors.New("invalid display name")
+	ErrInviteNotFound     = domain.ErrInviteNotFound
 	ErrInviteExpired      = errors.New("invitation has expired")
+	ErrInviteNotPending   = domain.ErrInviteNotPending
+	ErrNotInviteSender    = errors.New("only the sender can manage this invitation")
+	ErrNotInviteRecipient = errors.New("only the recipient can answer this invitation")
 )
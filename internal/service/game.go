@@ -5,70 +5,219 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math/rand"
+	"math"
 	"slices"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/zizouhuweidi/dahaa/internal/clock"
 	"github.com/zizouhuweidi/dahaa/internal/domain"
-	"github.com/zizouhuweidi/dahaa/internal/repository/postgres"
-	"github.com/zizouhuweidi/dahaa/internal/session"
+	"github.com/zizouhuweidi/dahaa/internal/id"
+	"github.com/zizouhuweidi/dahaa/internal/random"
 	"github.com/zizouhuweidi/dahaa/internal/validation"
-	"github.com/zizouhuweidi/dahaa/internal/websocket"
 )
 
 var (
-	ErrGameNotFound    = errors.New("game not found")
-	ErrGameFull        = errors.New("game is full")
-	ErrGameInProgress  = errors.New("game is already in progress")
-	ErrGameNotStarted  = errors.New("game has not started")
-	ErrInvalidRound    = errors.New("invalid round number")
-	ErrPlayerNotFound  = errors.New("player not found")
-	ErrAnswerSubmitted = errors.New("answer already submitted")
-	ErrVoteSubmitted   = errors.New("vote already submitted")
-	ErrInvalidAnswer   = errors.New("invalid answer")
-	ErrInvalidVote     = errors.New("invalid vote")
+	ErrGameNotFound        = domain.ErrGameNotFound
+	ErrGameFull            = errors.New("game is full")
+	ErrGameCodeTaken       = domain.ErrGameCodeTaken
+	ErrGameInProgress      = errors.New("game is already in progress")
+	ErrGameNotStarted      = errors.New("game has not started")
+	ErrInvalidRound        = errors.New("invalid round number")
+	ErrPlayerNotFound      = errors.New("player not found")
+	ErrAnswerSubmitted     = errors.New("answer already submitted")
+	ErrVoteSubmitted       = errors.New("vote already submitted")
+	ErrInvalidAnswer       = errors.New("invalid answer")
+	ErrInvalidVote         = errors.New("invalid vote")
+	ErrUnauthenticated     = errors.New("caller is not identified")
+	ErrNotHost             = errors.New("only the host can perform this action")
+	ErrInvalidPlayerToken  = errors.New("invalid player token")
+	ErrInvalidOverlayToken = errors.New("invalid overlay token")
+	ErrInvalidResumeToken  = errors.New("invalid resume token")
+	ErrPlayerInGame        = errors.New("player already in game")
+	ErrNoCategories        = errors.New("at least one category must be selected")
+	ErrGameStarted         = errors.New("game has already started")
+	ErrNotEnoughPlayers    = errors.New("not enough players to start")
+	ErrInvalidSettings     = errors.New("invalid game settings")
+	ErrPlayersNotReady     = errors.New("not every player is ready")
+	ErrNoCountdown         = errors.New("game is not counting down to its start")
+	ErrNoTimedPhase        = errors.New("no timed phase is running")
+	ErrLiarCannotVote      = errors.New("the player who chose the category cannot vote")
+	ErrRoundNotWaiting     = errors.New("round is not in waiting state")
+	ErrNoActiveTurn        = errors.New("no active turn")
+	ErrNotYourTurn         = errors.New("another player holds the turn")
+	ErrInvalidCategory     = domain.ErrInvalidCategory
+	ErrRoundNotAnswering   = errors.New("round is not accepting answers")
+	ErrAnswerTooSimilar    = errors.New("answer is too similar to an existing answer")
+	ErrAnswerIsCorrect     = errors.New("answer is too similar to the correct answer")
+	ErrRoundNotVoting      = errors.New("round is not in voting phase")
+	ErrNoRounds            = errors.New("no rounds found in game")
+	ErrRoundCompleted      = errors.New("round already completed")
+	ErrGameEnded           = errors.New("game has already ended")
+	ErrPlayerNotInGame     = errors.New("player not found in game")
+	ErrSubmissionTooLate   = errors.New("time is up for this phase")
 )
 
 // GameService implements the domain.GameService interface
 type GameService struct {
-	gameRepo     *postgres.GameRepository
-	questionRepo *postgres.QuestionRepository
-	hub          *websocket.Hub
-	sessionMgr   *session.Manager
+	gameRepo     domain.GameRepository
+	eventRepo    domain.GameEventRepository
+	changeStore  domain.GameChangeStore
+	questionRepo domain.QuestionRepository
+	hub          domain.GameBroadcaster
+	sessionMgr   domain.GameSessionStore
+	relay        *OutboxRelay
+	clock        clock.Clock
+	rng          random.Source
+	ids          *id.Generator
+	parties      domain.PartyRepository
+	history      domain.QuestionHistory
+	origin       *domain.GameOrigin
+	bans         *BanService
+	moderator    domain.AnswerModerator
+	fillers      domain.FillerGenerator
+	entitlements *EntitlementService
+	cosmetics    *CosmeticService
+	users        domain.UserRepository
+	tenants      *TenantService
+
+	inactivityTimeout time.Duration   // How long a game may go without activity before it expires
+	expiryWarnings    []time.Duration // How long before expiry players are warned, shortest first
+	reconnectGrace    time.Duration   // How long a disconnected player is waited for before being removed
 }
 
-// NewGameService creates a new game service
-func NewGameService(gameRepo *postgres.GameRepository, questionRepo *postgres.QuestionRepository, hub *websocket.Hub, sessionMgr *session.Manager) *GameService {
-	return &GameService{
+// GameServiceOption configures optional GameService dependencies
+type GameServiceOption func(*GameService)
+
+// WithClock sets the clock used for timestamps and timers
+func WithClock(c clock.Clock) GameServiceOption {
+	return func(s *GameService) {
+		s.clock = c
+	}
+}
+
+// WithRandom sets the source used for IDs, game codes and filler answers
+func WithRandom(rng random.Source) GameServiceOption {
+	return func(s *GameService) {
+		s.rng = rng
+	}
+}
+
+// WithOutboxRelay sets the relay to wake when a change queues messages
+func WithOutboxRelay(relay *OutboxRelay) GameServiceOption {
+	return func(s *GameService) {
+		s.relay = relay
+	}
+}
+
+// WithQuestionHistory sets where the questions players were asked are
+// remembered, so that games avoid asking them again for a while
+func WithQuestionHistory(history domain.QuestionHistory) GameServiceOption {
+	return func(s *GameService) {
+		s.history = history
+	}
+}
+
+// WithOrigin sets the region and instance recorded on the games the service
+// creates. Games record no origin when both are empty.
+func WithOrigin(region string, instance string) GameServiceOption {
+	return func(s *GameService) {
+		if region != "" || instance != "" {
+			s.origin = &domain.GameOrigin{Region: region, Instance: instance}
+		}
+	}
+}
+
+// WithBans keeps suspended and banned users from creating and joining games
+func WithBans(bans *BanService) GameServiceOption {
+	return func(s *GameService) {
+		s.bans = bans
+	}
+}
+
+// WithEntitlements keeps players from hosting games with premium packs they
+// do not own, and from joining them when the policy requires every player to
+// own them
+func WithEntitlements(entitlements *EntitlementService) GameServiceOption {
+	return func(s *GameService) {
+		s.entitlements = entitlements
+	}
+}
+
+// WithCosmetics shows the cosmetics players wear to everyone in the games
+// they create and join
+func WithCosmetics(cosmetics *CosmeticService) GameServiceOption {
+	return func(s *GameService) {
+		s.cosmetics = cosmetics
+	}
+}
+
+// WithHallOfFame records answers liked into the hall of fame, looking up
+// whether their authors consent to them being shown. Users are also looked up
+// for the region they prefer games hosted in.
+func WithHallOfFame(users domain.UserRepository) GameServiceOption {
+	return func(s *GameService) {
+		s.users = users
+	}
+}
+
+// WithFillerGenerator has a language model write the fillers a round's
+// question does not come with, before falling back to templates
+func WithFillerGenerator(fillers domain.FillerGenerator) GameServiceOption {
+	return func(s *GameService) {
+		s.fillers = fillers
+	}
+}
+
+// WithTenants makes the games organization members host private to their
+// organization, keeping everyone else from joining them
+func WithTenants(tenants *TenantService) GameServiceOption {
+	return func(s *GameService) {
+		s.tenants = tenants
+	}
+}
+
+// NewGameService creates a new game service. By default it uses the system
+// clock and a random source backed by crypto/rand. Messages for clients are queued in
+// the change store's outbox and reach the hub through an OutboxRelay; the hub
+// is only used directly to close deleted games and for countdown ticks, which
+// are not saved.
+func NewGameService(gameRepo domain.GameRepository, eventRepo domain.GameEventRepository, changeStore domain.GameChangeStore, questionRepo domain.QuestionRepository, hub domain.GameBroadcaster, sessionMgr domain.GameSessionStore, opts ...GameServiceOption) *GameService {
+	s := &GameService{
 		gameRepo:     gameRepo,
+		eventRepo:    eventRepo,
+		changeStore:  changeStore,
 		questionRepo: questionRepo,
 		hub:          hub,
 		sessionMgr:   sessionMgr,
+		clock:        clock.System(),
+		rng:          random.Secure(),
+
+		inactivityTimeout: defaultInactivityTimeout,
+		expiryWarnings:    defaultExpiryWarnings,
+		reconnectGrace:    defaultReconnectGrace,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	s.ids = id.NewGenerator(s.rng)
+	return s
 }
 
-// CreateGame creates a new game session
-func (s *GameService) CreateGame(ctx context.Context, code string, player domain.Player, settings *domain.GameSettings) (*domain.Game, error) {
-	// Generate code if none provided
-	if code == "" {
-		// Try up to 3 times to generate a unique code
-		for i := 0; i < 3; i++ {
-			code = generateGameCode()
-			existingGame, err := s.gameRepo.GetByCode(ctx, code)
-			if err != nil || existingGame == nil {
-				break // Found a unique code
-			}
-			if i == 2 { // Last attempt
-				return nil, errors.New("failed to generate unique game code after multiple attempts")
-			}
-		}
-	} else {
-		// Check if provided code already exists
-		existingGame, err := s.gameRepo.GetByCode(ctx, code)
-		if err == nil && existingGame != nil {
-			return nil, errors.New("game code already exists")
-		}
+// CreateGame creates a new game session and returns it along with the host's
+// player token. The player's ID must be the host's authenticated identity,
+// as their bans, premium pack entitlements and organization are keyed on it.
+func (s *GameService) CreateGame(ctx context.Context, code string, player domain.Player, settings *domain.GameSettings) (*domain.Game, string, error) {
+	return s.hostGame(ctx, code, player, settings, "")
+}
+
+// hostGame creates a game session, as one of a party's games if partyID is
+// set, and returns it along with the host's player token
+func (s *GameService) hostGame(ctx context.Context, code string, player domain.Player, settings *domain.GameSettings, partyID string) (*domain.Game, string, error) {
+	if err := s.checkBanned(ctx, player.ID); err != nil {
+		return nil, "", err
 	}
 
 	// Use default settings if none provided
@@ -78,99 +227,252 @@ func (s *GameService) CreateGame(ctx context.Context, code string, player domain
 
 	// Validate selected categories
 	if len(settings.SelectedCategories) == 0 {
-		return nil, errors.New("at least one category must be selected")
+		return nil, "", ErrNoCategories
+	}
+
+	if err := validateSettings(settings); err != nil {
+		return nil, "", err
+	}
+	if settings.IsLightning() {
+		settings.Rounds = domain.LightningRounds
 	}
 
-	// Verify all selected categories exist
-	availableCategories, err := s.questionRepo.GetCategories(ctx)
+	// Verify all selected categories have questions in the game's language
+	// that its rating cap allows
+	if settings.Language == "" {
+		settings.Language = domain.DefaultQuestionLanguage
+	}
+	availableCategories, err := s.questionRepo.GetCategoryCounts(ctx, settings.MaxRating)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get categories: %w", err)
+		return nil, "", fmt.Errorf("failed to get categories: %w", err)
 	}
 
 	categoryMap := make(map[string]bool)
 	for _, cat := range availableCategories {
-		categoryMap[cat] = true
+		if cat.Language == settings.Language {
+			categoryMap[cat.Category] = true
+		}
 	}
 
 	for _, cat := range settings.SelectedCategories {
 		if !categoryMap[cat] {
-			return nil, fmt.Errorf("invalid category: %s", cat)
+			return nil, "", fmt.Errorf("invalid category: %s", cat)
 		}
 	}
 
-	// Create new game
-	game := &domain.Game{
-		ID:           generateID(),
-		Code:         code,
-		Status:       domain.GameStatusWaiting,
-		Players:      []domain.Player{player},
-		Rounds:       []domain.Round{},
-		Settings:     settings,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-		LastActivity: time.Now(),
+	premiumPacks, err := s.requiredPremiumPacks(ctx, player.ID, settings.SelectedCategories)
+	if err != nil {
+		return nil, "", err
 	}
 
-	// Save to database
-	if err := s.gameRepo.Create(ctx, game); err != nil {
-		return nil, err
+	tenantID, err := s.gameTenant(ctx, player.ID)
+	if err != nil {
+		return nil, "", err
 	}
 
-	// Store in Redis for active game management
-	if err := s.sessionMgr.StoreGame(ctx, game); err != nil {
-		return nil, err
+	// Pin the latest release of the language's pack, so releases published
+	// during the game do not change it
+	packVersion, err := s.questionRepo.LatestPackVersion(ctx, settings.Language)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get pack version: %w", err)
 	}
 
-	// Notify all clients about new game
-	payload, err := json.Marshal(game)
-	if err != nil {
-		return nil, err
+	// Create the game, generating a code if none was provided. Codes are
+	// unique in the store, so a generated code that is already taken is
+	// replaced and the game created again.
+	generated := code == ""
+	var game *domain.Game
+	for attempt := 1; ; attempt++ {
+		if generated {
+			code = s.newGameCode()
+		}
+
+		game, err = s.createGame(ctx, code, player, settings, partyID, packVersion, premiumPacks, tenantID)
+		if err == nil {
+			break
+		}
+		if !generated || !errors.Is(err, domain.ErrGameCodeTaken) {
+			return nil, "", err
+		}
+		if attempt == maxGameCodeAttempts {
+			return nil, "", fmt.Errorf("failed to generate unique game code after %d attempts: %w", attempt, err)
+		}
 	}
 
-	s.hub.BroadcastToGame(game.ID, "game_created", payload)
+	// Issue the host's player token
+	token, err := s.issuePlayerToken(ctx, game.ID, &game.Players[0])
+	if err != nil {
+		return nil, "", err
+	}
 
-	return game, nil
+	return game, token, nil
 }
 
-// JoinGame allows a player to join an existing game
-func (s *GameService) JoinGame(ctx context.Context, code string, player domain.Player) error {
-	game, err := s.GetGame(ctx, code)
-	if err != nil {
-		return err
+// validateSettings checks that a game's player counts and quorums can be met
+func validateSettings(settings *domain.GameSettings) error {
+	if !slices.Contains(validModes, settings.Mode) {
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidSettings, settings.Mode)
+	}
+	if settings.MinPlayers != 0 && (settings.MinPlayers < domain.DefaultMinPlayers || settings.MinPlayers > settings.MaxPlayers) {
+		return fmt.Errorf("%w: min players must be between %d and max players", ErrInvalidSettings, domain.DefaultMinPlayers)
+	}
+	if settings.AnswerQuorum < 0 || settings.AnswerQuorum > 1 || settings.VoteQuorum < 0 || settings.VoteQuorum > 1 {
+		return fmt.Errorf("%w: quorums must be between 0 and 1", ErrInvalidSettings)
+	}
+	if settings.MaxRating != "" && !slices.Contains(domain.ContentRatings, settings.MaxRating) {
+		return fmt.Errorf("%w: unknown content rating %q", ErrInvalidSettings, settings.MaxRating)
+	}
+	if settings.TimeLimits.Intermission < 0 {
+		return fmt.Errorf("%w: intermission cannot be negative", ErrInvalidSettings)
+	}
+	if settings.EliminationInterval < 0 {
+		return fmt.Errorf("%w: elimination interval cannot be negative", ErrInvalidSettings)
 	}
+	if settings.SpeedBonus < 0 || settings.SpeedBonus > domain.MaxSpeedBonus {
+		return fmt.Errorf("%w: speed bonus must be between 0 and %d", ErrInvalidSettings, domain.MaxSpeedBonus)
+	}
+	if settings.AutoStartPlayers != 0 && (settings.AutoStartPlayers < settings.MinimumPlayers() || settings.AutoStartPlayers > settings.MaxPlayers) {
+		return fmt.Errorf("%w: auto start players must be between min and max players", ErrInvalidSettings)
+	}
+	return nil
+}
 
-	if game.Status != domain.GameStatusWaiting {
-		return errors.New("game has already started")
+// createGame creates and saves a game with the given code
+func (s *GameService) createGame(ctx context.Context, code string, player domain.Player, settings *domain.GameSettings, partyID string, packVersion int, premiumPacks []string, tenantID string) (*domain.Game, error) {
+	change := s.newChange(&domain.Game{})
+	seatPlayer(change.game, &player)
+	player.Cosmetics = s.equippedCosmetics(ctx, player.ID)
+	player.IsConnected, player.LastSeen = true, change.now
+	if err := change.emit(domain.EventGameCreated, domain.GameCreatedPayload{
+		ID:           s.newID(),
+		Code:         code,
+		Host:         player,
+		Settings:     settings,
+		PartyID:      partyID,
+		Origin:       s.origin,
+		PackVersion:  packVersion,
+		PremiumPacks: premiumPacks,
+		TenantID:     tenantID,
+	}); err != nil {
+		return nil, err
 	}
+	change.created = true
+
+	// Notify all clients about new game
+	change.publishGame("game_created")
 
-	if len(game.Players) >= game.Settings.MaxPlayers {
-		return errors.New("game is full")
+	// Save to database. The database may assign its own ID.
+	if err := s.commit(ctx, change); err != nil {
+		return nil, err
 	}
+	return change.game, nil
+}
 
-	// Check if player already exists
-	for _, p := range game.Players {
-		if p.ID == player.ID {
-			return errors.New("player already in game")
-		}
+// checkBanned returns a *BanError when a player's account is suspended or
+// banned
+func (s *GameService) checkBanned(ctx context.Context, playerID string) error {
+	if s.bans == nil {
+		return nil
 	}
+	return s.bans.Check(ctx, playerID)
+}
 
-	game.Players = append(game.Players, player)
-	game.UpdatedAt = time.Now()
-	game.LastActivity = time.Now()
+// requiredPremiumPacks returns the premium packs of a game's categories,
+// returning ErrPremiumPackNotOwned if the host's account does not own them
+func (s *GameService) requiredPremiumPacks(ctx context.Context, userID string, categories []string) ([]string, error) {
+	if s.entitlements == nil {
+		return nil, nil
+	}
+	return s.entitlements.RequiredPacks(ctx, userID, categories)
+}
 
-	if err := s.UpdateGame(ctx, game); err != nil {
-		return err
+// gameTenant returns the organization a host's account makes their games
+// private to, if any
+func (s *GameService) gameTenant(ctx context.Context, userID string) (string, error) {
+	if s.tenants == nil {
+		return "", nil
 	}
+	return s.tenants.GameTenant(ctx, userID)
+}
 
-	// Notify all clients about player joining
-	payload, err := json.Marshal(player)
+// equippedCosmetics returns the cosmetics a player wears, or nil if they wear
+// none. Failing to look them up does not keep the player out of the game.
+func (s *GameService) equippedCosmetics(ctx context.Context, playerID string) *domain.EquippedCosmetics {
+	if s.cosmetics == nil {
+		return nil
+	}
+	equipped, err := s.cosmetics.Equipped(ctx, playerID)
 	if err != nil {
-		return err
+		fmt.Printf("Failed to get cosmetics of player %s: %v\n", playerID, err)
+		return nil
+	}
+	if equipped.IsZero() {
+		return nil
 	}
+	return equipped
+}
 
-	s.hub.BroadcastToGame(game.ID, "player_joined", payload)
+// JoinGame allows a player to join an existing game and returns the player's
+// token, which must accompany their subsequent game actions. As with
+// CreateGame, the player's ID must be their authenticated identity.
+func (s *GameService) JoinGame(ctx context.Context, code string, player domain.Player) (string, error) {
+	return retryConflictsResult(ctx, func() (string, error) {
+		if err := s.checkBanned(ctx, player.ID); err != nil {
+			return "", err
+		}
 
-	return nil
+		game, err := s.GetGame(ctx, code)
+		if err != nil {
+			return "", err
+		}
+
+		if game.Status != domain.GameStatusWaiting {
+			return "", ErrGameInProgress
+		}
+
+		if len(game.Players) >= game.Settings.MaxPlayers {
+			return "", ErrGameFull
+		}
+
+		if s.entitlements != nil {
+			if err := s.entitlements.CheckJoin(ctx, player.ID, game.PremiumPacks); err != nil {
+				return "", err
+			}
+		}
+		if s.tenants != nil {
+			if err := s.tenants.CheckJoin(ctx, player.ID, game.TenantID); err != nil {
+				return "", err
+			}
+		}
+
+		// Check if player already exists
+		for _, p := range game.Players {
+			if p.ID == player.ID {
+				return "", ErrPlayerInGame
+			}
+		}
+
+		change := s.newChange(game)
+		seatPlayer(game, &player)
+		player.Cosmetics = s.equippedCosmetics(ctx, player.ID)
+		player.IsConnected, player.LastSeen = true, change.now
+		if err := change.emit(domain.EventPlayerJoined, domain.PlayerJoinedPayload{Player: player}); err != nil {
+			return "", err
+		}
+
+		// Notify all clients about player joining
+		if err := change.publish("player_joined", player); err != nil {
+			return "", err
+		}
+		if err := s.startCountdownIfDue(change); err != nil {
+			return "", err
+		}
+		if err := s.commit(ctx, change); err != nil {
+			return "", err
+		}
+
+		return s.issuePlayerToken(ctx, game.ID, &player)
+	})
 }
 
 // GetGame retrieves a game by its code
@@ -192,342 +494,905 @@ func (s *GameService) GetGame(ctx context.Context, gameID string) (*domain.Game,
 	return game, nil
 }
 
-// UpdateGame updates a game's state
-func (s *GameService) UpdateGame(ctx context.Context, game *domain.Game) error {
-	// Update in database
-	if err := s.gameRepo.Update(ctx, game); err != nil {
-		return err
+// loadHead loads a game scanned as a head in full, so that a background job
+// can change it. A game that has changed or ended since it was scanned is
+// returned as nil, for the job to look at again on its next tick.
+func (s *GameService) loadHead(ctx context.Context, head *domain.GameHead) (*domain.Game, error) {
+	game, err := s.GetGame(ctx, head.ID)
+	if errors.Is(err, domain.ErrGameNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if game.Version != head.Version {
+		return nil, nil
 	}
+	return game, nil
+}
 
-	// Update in Redis
-	if err := s.sessionMgr.StoreGame(ctx, game); err != nil {
-		// Log error but continue
-		fmt.Printf("Failed to update game in Redis: %v\n", err)
+// getGameHead retrieves a game with only its current round, for commands
+// that validate against it before changing the game. Games not in Redis are
+// loaded whole.
+func (s *GameService) getGameHead(ctx context.Context, gameID string) (*domain.GameHead, error) {
+	if head, err := s.sessionMgr.GetGameHead(ctx, gameID); err == nil {
+		return head, nil
 	}
 
-	// Notify all clients about game update
-	payload, err := json.Marshal(game)
+	game, err := s.GetGame(ctx, gameID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return &domain.GameHead{Game: game, RoundCount: len(game.Rounds)}, nil
+}
 
-	s.hub.BroadcastToGame(game.ID, "game_updated", payload)
+// fullGame loads the rounds a game head was read without, once a command
+// has decided to change the game. A game that changed since its head was
+// read is reported as a conflict, for the command to run again.
+func (s *GameService) fullGame(ctx context.Context, head *domain.GameHead) (*domain.Game, error) {
+	if len(head.Rounds) == head.RoundCount {
+		return head.Game, nil
+	}
 
-	return nil
+	game, err := s.loadHead(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+	if game == nil {
+		return nil, domain.ErrEventConflict
+	}
+	return game, nil
 }
 
-// DeleteGame deletes a game
-func (s *GameService) DeleteGame(ctx context.Context, code string) error {
-	// Get game first to get its ID
+// GetRound retrieves the sanitized state of a specific round
+func (s *GameService) GetRound(ctx context.Context, code string, number int, viewerID string) (*domain.RoundView, error) {
 	game, err := s.GetGame(ctx, code)
 	if err != nil {
+		return nil, err
+	}
+
+	for _, round := range game.Rounds {
+		if round.Number == number {
+			return newRoundView(game, round, viewerID), nil
+		}
+	}
+
+	return nil, ErrInvalidRound
+}
+
+// newRoundView builds the client-facing view of a round for a viewer,
+// revealing answers and votes only when the round's phase allows it. The
+// round's liar is never shown its correct answer.
+func newRoundView(game *domain.Game, round domain.Round, viewerID string) *domain.RoundView {
+	pool := round.AnswerPool
+	view := &domain.RoundView{
+		Number:      round.Number,
+		Category:    round.Category,
+		Question:    round.Question,
+		Status:      round.Status,
+		StartTime:   round.StartTime,
+		EndTime:     round.EndTime,
+		CurrentTurn: round.CurrentTurn,
+		Timer:       round.Timer,
+		AnswerCount: len(round.Answered()),
+
+		// Viewers' votes are only counts, revealing no one's answer
+		AudienceVotes: round.AudienceVotes,
+	}
+
+	switch round.Status {
+	case domain.RoundStatusVoting:
+		// Present the voting pool anonymously, fillers and the correct answer
+		// alongside the players' answers
+		view.Options = pool.VotingPool
+
+	case domain.RoundStatusCompleted:
+		// The recap reveals who wrote each answer, fillers included
+		if viewerID == "" || viewerID != game.Liar(&round) {
+			view.CorrectAnswer = pool.CorrectAnswer
+		}
+		view.CorrectVotes = pool.CorrectVotes
+		view.FooledByHouse = pool.FillerVoters()
+		view.VotedAt = round.VotedAt
+		view.SpeedBonuses = round.SpeedBonuses
+		view.Likes = round.LikeCounts()
+		view.LikeBonuses = round.LikeBonuses
+		view.Answers = append(slices.Clone(pool.FakeAnswers), pool.FillerAnswers...)
+	}
+
+	return view
+}
+
+// gameChange collects the events produced by a single command, applying each
+// to the game as it is emitted, along with the messages to send to clients
+// once it is committed
+type gameChange struct {
+	game          *domain.Game
+	created       bool
+	events        []*domain.GameEvent
+	messages      []*domain.OutboxMessage
+	result        *domain.GameResult
+	categoryStats []*domain.CategoryStats
+	party         *domain.PartyScoreboard
+	hallOfFame    []*domain.HallOfFameEntry
+	now           time.Time
+
+	// firstRound is the index of the round that was current when the change
+	// started, the first a change can touch
+	firstRound int
+}
+
+// newChange starts a change to a game at the current time
+func (s *GameService) newChange(game *domain.Game) *gameChange {
+	return &gameChange{game: game, now: s.clock.Now(), firstRound: max(len(game.Rounds)-1, 0)}
+}
+
+// emit records an event and applies it to the game, unless the game's state
+// does not allow it. Events moving the game into another state are emitted
+// through GameService.transition, which runs the states' hooks.
+func (c *gameChange) emit(eventType domain.GameEventType, payload any) error {
+	if _, _, err := c.game.Transition(eventType); err != nil {
 		return err
 	}
 
-	// Delete from database
-	if err := s.gameRepo.Delete(ctx, code); err != nil {
+	event, err := domain.NewGameEvent(c.game.ID, eventType, payload, c.now)
+	if err != nil {
 		return err
 	}
+	event.Sequence = c.game.Version + 1
 
-	// Delete from Redis
-	if err := s.sessionMgr.DeleteGame(ctx, code); err != nil {
+	if err := c.game.Apply(event); err != nil {
 		return err
 	}
+	// The ID of a new game is only known once its creation is applied
+	event.GameID = c.game.ID
 
-	// Notify all clients about game deletion
-	payload, err := json.Marshal(map[string]string{
-		"code": code,
-	})
+	c.events = append(c.events, event)
+	return nil
+}
+
+// publish queues a message to the game's clients
+func (c *gameChange) publish(messageType string, payload any) error {
+	data, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
 
-	s.hub.BroadcastToGame(game.ID, "game_deleted", payload)
+	c.messages = append(c.messages, &domain.OutboxMessage{
+		GameID:    c.game.ID,
+		Type:      messageType,
+		Payload:   data,
+		CreatedAt: c.now,
+	})
+	return nil
+}
 
+// publishTo queues a private message to one of the game's players
+func (c *gameChange) publishTo(playerID string, messageType string, payload any) error {
+	if err := c.publish(messageType, payload); err != nil {
+		return err
+	}
+	c.messages[len(c.messages)-1].PlayerID = playerID
 	return nil
 }
 
-// StartGame starts a game session
-func (s *GameService) StartGame(ctx context.Context, code string) error {
+// publishGame queues a message carrying the game as saved by the change.
+// Displays are sent its sanitized view instead.
+func (c *gameChange) publishGame(messageType string) {
+	c.messages = append(c.messages, &domain.OutboxMessage{
+		GameID:    c.game.ID,
+		Audience:  domain.AudiencePlayers,
+		Type:      messageType,
+		CreatedAt: c.now,
+	})
+}
+
+// commit appends a change's events to the game's stream, saves the projected
+// snapshot and queues its messages in one transaction, so clients are only
+// notified of changes that were saved. Every change to an existing game also
+// notifies players of the game's new state, and displays of its new view.
+func (s *GameService) commit(ctx context.Context, change *gameChange) error {
+	messages := change.messages
+	if !change.created {
+		messages = append([]*domain.OutboxMessage{{
+			GameID:    change.game.ID,
+			Audience:  domain.AudiencePlayers,
+			Type:      "game_updated",
+			CreatedAt: change.now,
+		}}, messages...)
+
+		display, err := json.Marshal(newDisplayView(change.game))
+		if err != nil {
+			return err
+		}
+		messages = append(messages, &domain.OutboxMessage{
+			GameID:    change.game.ID,
+			Audience:  domain.AudienceDisplays,
+			Type:      "display_updated",
+			Payload:   display,
+			CreatedAt: change.now,
+		})
+	}
+
+	saved := &domain.GameChange{
+		Game:          change.game,
+		Created:       change.created,
+		Events:        change.events,
+		Messages:      messages,
+		Result:        change.result,
+		CategoryStats: change.categoryStats,
+		Party:         change.party,
+		HallOfFame:    change.hallOfFame,
+	}
+	if err := s.changeStore.SaveChange(ctx, saved); err != nil {
+		// The game changed since it was read, so the copy in Redis may be
+		// stale. Drop it for the command's retry to read Postgres instead.
+		if errors.Is(err, domain.ErrEventConflict) {
+			s.dropSession(ctx, change.game.ID)
+		}
+		return err
+	}
+
+	if s.relay != nil {
+		s.relay.Notify()
+	}
+
+	// Ended games leave active game management. The change is already
+	// committed, so failing to remove the game from Redis fails nothing.
+	if change.game.Status == domain.GameStatusEnded {
+		if err := s.sessionMgr.DeleteGame(ctx, change.game.ID); err != nil {
+			// Log error but continue
+			fmt.Printf("Failed to delete ended game from Redis: %v\n", err)
+		}
+		return nil
+	}
+
+	// Update in Redis for active game management, rewriting only the rounds
+	// the change could have touched. A copy left behind by a failed update
+	// would be stale, so it is dropped for the next read to reload the game
+	// from Postgres.
+	if err := s.sessionMgr.StoreGameRounds(ctx, change.game, change.firstRound); err != nil {
+		fmt.Printf("Failed to update game in Redis: %v\n", err)
+		s.dropSession(ctx, change.game.ID)
+	}
+
+	return nil
+}
+
+// dropSession removes a game from Redis, for its next read to load it from
+// Postgres
+func (s *GameService) dropSession(ctx context.Context, gameID string) {
+	if err := s.sessionMgr.DeleteGame(ctx, gameID); err != nil {
+		fmt.Printf("Failed to delete game %s from Redis: %v\n", gameID, err)
+	}
+}
+
+// maxConflictAttempts is how many times a command is run before a conflict
+// with concurrent changes to its game is returned to the caller
+const maxConflictAttempts = 3
+
+// retryConflicts runs a command, running it again when its change conflicts
+// with another committed to the game concurrently. The conflicting commit
+// dropped the game from Redis, so the command reloads it from Postgres and
+// validates itself against the game as it now is.
+func retryConflicts(ctx context.Context, command func() error) error {
+	_, err := retryConflictsResult(ctx, func() (struct{}, error) {
+		return struct{}{}, command()
+	})
+	return err
+}
+
+// retryConflictsResult is retryConflicts for commands returning a result
+func retryConflictsResult[T any](ctx context.Context, command func() (T, error)) (T, error) {
+	var (
+		result T
+		err    error
+	)
+	for attempt := 0; attempt < maxConflictAttempts; attempt++ {
+		result, err = command()
+		if !errors.Is(err, domain.ErrEventConflict) || ctx.Err() != nil {
+			break
+		}
+	}
+	return result, err
+}
+
+// newTimer creates a timer of the given length starting now
+func (s *GameService) newTimer(timerType domain.TimerType, seconds int) *domain.Timer {
+	now := s.clock.Now()
+	return &domain.Timer{
+		Type:      timerType,
+		StartTime: now,
+		Duration:  seconds,
+		EndTime:   now.Add(time.Duration(seconds) * time.Second),
+	}
+}
+
+// GetGameEvents retrieves a game's event stream for auditing. Only the host
+// may read it, since it reveals answers and their authors.
+func (s *GameService) GetGameEvents(ctx context.Context, code string, callerID string) ([]*domain.GameEvent, error) {
 	game, err := s.GetGame(ctx, code)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if game.Status != domain.GameStatusWaiting {
-		return errors.New("game has already started")
+	if err := authorizeHost(game, callerID); err != nil {
+		return nil, err
 	}
 
-	if len(game.Players) < 2 {
-		return errors.New("need at least 2 players to start")
+	return s.eventRepo.ListByGame(ctx, game.ID, 0)
+}
+
+// ReplayGame rebuilds a game's state from its event stream, bypassing any
+// stored snapshot
+func (s *GameService) ReplayGame(ctx context.Context, gameID string) (*domain.Game, error) {
+	events, err := s.eventRepo.ListByGame(ctx, gameID, 0)
+	if err != nil {
+		return nil, err
 	}
+	if len(events) == 0 {
+		return nil, ErrGameNotFound
+	}
+	return domain.ReplayGame(events)
+}
 
-	game.Status = domain.GameStatusPlaying
-	game.UpdatedAt = time.Now()
-	game.LastActivity = time.Now()
+// DeleteGame deletes a game, closes its WebSocket connections and removes
+// its session state. Only the host may delete the game.
+func (s *GameService) DeleteGame(ctx context.Context, code string, callerID string) error {
+	// Get game first to get its ID
+	game, err := s.GetGame(ctx, code)
+	if err != nil {
+		return err
+	}
 
-	// Create first round
-	round := domain.Round{
-		Number:    1,
-		Status:    domain.RoundStatusWaiting,
-		StartTime: time.Now(),
-		AnswerPool: domain.AnswerPool{
-			CorrectAnswer: "",
-			FakeAnswers:   make([]domain.Answer, 0),
-			FillerAnswers: make([]domain.Answer, 0),
-		},
+	if err := authorizeHost(game, callerID); err != nil {
+		return err
 	}
 
-	game.Rounds = append(game.Rounds, round)
+	// Delete from database
+	if err := s.gameRepo.Delete(ctx, game.Code); err != nil {
+		return err
+	}
 
-	if err := s.UpdateGame(ctx, game); err != nil {
+	// Delete from Redis
+	if err := s.sessionMgr.DeleteGame(ctx, game.ID); err != nil {
 		return err
 	}
+	for _, p := range game.Players {
+		if err := s.sessionMgr.DeletePlayerSession(ctx, game.ID, p.ID); err != nil {
+			// Log error but continue
+			fmt.Printf("Failed to delete player session: %v\n", err)
+		}
+	}
 
-	// Notify all clients about game start
-	payload, err := json.Marshal(game)
+	// Notify all clients about game deletion
+	payload, err := json.Marshal(map[string]string{
+		"code": game.Code,
+	})
 	if err != nil {
 		return err
 	}
 
-	s.hub.BroadcastToGame(game.ID, "game_started", payload)
+	s.hub.BroadcastToGame(game.ID, "game_deleted", payload)
+	s.hub.CloseGame(game.ID)
 
 	return nil
 }
 
+// StartGame starts a game session. Only the host may start the game. Games
+// that require players to be ready wait for every connected player unless
+// the host forces the start.
+func (s *GameService) StartGame(ctx context.Context, code string, callerID string, force bool) error {
+	return retryConflicts(ctx, func() error {
+		game, err := s.GetGame(ctx, code)
+		if err != nil {
+			return err
+		}
+
+		if err := authorizeHost(game, callerID); err != nil {
+			return err
+		}
+
+		if game.Status != domain.GameStatusWaiting {
+			return ErrGameStarted
+		}
+
+		if len(game.ConnectedPlayers()) < game.Settings.MinimumPlayers() {
+			return ErrNotEnoughPlayers
+		}
+
+		if game.Settings.RequireReady && !force && !allReady(game) {
+			return ErrPlayersNotReady
+		}
+
+		return s.startGame(ctx, game)
+	})
+}
+
+// startGame starts a game, which also creates its first round
+func (s *GameService) startGame(ctx context.Context, game *domain.Game) error {
+	change := s.newChange(game)
+	if err := s.transition(ctx, change, domain.EventGameStarted, domain.GameStartedPayload{}); err != nil {
+		return err
+	}
+
+	// Lightning games skip category selection
+	if game.Settings.IsLightning() {
+		if err := s.drawCategory(ctx, change); err != nil {
+			return err
+		}
+	}
+
+	return s.commit(ctx, change)
+}
+
 // StartTurn starts a new turn for a player
 func (s *GameService) StartTurn(ctx context.Context, gameID string, playerID string) error {
-	game, err := s.GetGame(ctx, gameID)
+	return retryConflicts(ctx, func() error {
+		head, err := s.getGameHead(ctx, gameID)
+		if err != nil {
+			return err
+		}
+		game := head.Game
+
+		if game.Status != domain.GameStatusPlaying {
+			return domain.ErrGameNotStarted
+		}
+		if game.Settings.IsLightning() {
+			return ErrCategoriesDrawn
+		}
+		if isEliminated(game, playerID) {
+			return ErrPlayerEliminated
+		}
+		if game.State() != domain.GameStateCategorySelection {
+			return ErrRoundNotWaiting
+		}
+
+		// Start the turn with a timer for category selection using game settings
+		if game, err = s.fullGame(ctx, head); err != nil {
+			return err
+		}
+		change := s.newChange(game)
+		if err := change.emit(domain.EventTurnStarted, domain.TurnStartedPayload{
+			PlayerID: playerID,
+			Timer:    s.stateTimer(game, domain.GameStateCategorySelection),
+		}); err != nil {
+			return err
+		}
+		return s.commit(ctx, change)
+	})
+}
+
+// SelectCategory handles category selection by the player holding the turn
+func (s *GameService) SelectCategory(ctx context.Context, gameID string, playerID string, category string) error {
+	return retryConflicts(ctx, func() error {
+		head, err := s.getGameHead(ctx, gameID)
+		if err != nil {
+			return err
+		}
+		game := head.Game
+
+		if game.Settings.IsLightning() {
+			return ErrCategoriesDrawn
+		}
+		if game.State() != domain.GameStateCategorySelection {
+			return ErrNoActiveTurn
+		}
+
+		currentRound := &game.Rounds[len(game.Rounds)-1]
+		if currentRound.CurrentTurn == nil || currentRound.CurrentTurn.Status != domain.TurnStatusActive {
+			return ErrNoActiveTurn
+		}
+		if currentRound.CurrentTurn.PlayerID != playerID {
+			return ErrNotYourTurn
+		}
+
+		// Validate category is in selected categories
+		validCategory := slices.Contains(game.Settings.SelectedCategories, category)
+
+		if !validCategory {
+			return ErrInvalidCategory
+		}
+
+		if game, err = s.fullGame(ctx, head); err != nil {
+			return err
+		}
+		change := s.newChange(game)
+		if err := s.askQuestion(ctx, change, category); err != nil {
+			return err
+		}
+		return s.commit(ctx, change)
+	})
+}
+
+// askQuestion draws a random question from a category in the game's
+// language and pack version for the current round and starts the answer
+// writing timer using game settings. Questions the players were asked recently are only drawn
+// once the category has no others left.
+func (s *GameService) askQuestion(ctx context.Context, change *gameChange, category string) error {
+	settings := change.game.Settings
+	players := askedPlayerIDs(change.game)
+	recent := s.recentlyAsked(ctx, players)
+
+	language, packVersion := settings.QuestionLanguage(), change.game.PackVersion
+	question, err := s.questionRepo.GetRandomQuestion(ctx, category, language, packVersion, settings.MaxRating, recent)
+	if errors.Is(err, domain.ErrQuestionNotFound) && len(recent) > 0 {
+		question, err = s.questionRepo.GetRandomQuestion(ctx, category, language, packVersion, settings.MaxRating, nil)
+	}
 	if err != nil {
 		return err
 	}
 
-	if game.Status != domain.GameStatusPlaying {
-		return domain.ErrGameNotStarted
+	if s.history != nil {
+		if err := s.history.RecordAsked(ctx, players, question.ID); err != nil {
+			// Log error but continue
+			fmt.Printf("Failed to record asked question: %v\n", err)
+		}
 	}
 
-	currentRound := &game.Rounds[len(game.Rounds)-1]
-	if currentRound.Status != domain.RoundStatusWaiting {
-		return errors.New("round is not in waiting state")
+	return s.transition(ctx, change, domain.EventCategorySelected, domain.CategorySelectedPayload{
+		Category:      category,
+		QuestionID:    question.ID,
+		Question:      question.Text,
+		CorrectAnswer: question.Answer,
+		Timer:         s.stateTimer(change.game, domain.GameStateAnswering),
+	})
+}
+
+// recentlyAsked returns the questions any of the players were asked
+// recently. The history only steers which questions are drawn, so games go
+// on without it if it is unavailable.
+func (s *GameService) recentlyAsked(ctx context.Context, playerIDs []string) []string {
+	if s.history == nil {
+		return nil
 	}
 
-	// Create new turn
-	turn := &domain.Turn{
-		PlayerID:  playerID,
-		StartTime: time.Now(),
-		Status:    domain.TurnStatusActive,
+	questionIDs, err := s.history.RecentlyAsked(ctx, playerIDs)
+	if err != nil {
+		fmt.Printf("Failed to get recently asked questions: %v\n", err)
+		return nil
 	}
+	return questionIDs
+}
 
-	// Set timer for category selection using game settings
-	turn.Timer = &domain.Timer{
-		Type:      domain.TimerTypeCategorySelection,
-		StartTime: time.Now(),
-		Duration:  game.Settings.TimeLimits.CategorySelection,
-		EndTime:   time.Now().Add(time.Duration(game.Settings.TimeLimits.CategorySelection) * time.Second),
+// askedPlayerIDs returns the IDs of the players still in a game, who are
+// asked its questions
+func askedPlayerIDs(game *domain.Game) []string {
+	var ids []string
+	for _, p := range game.Players {
+		if !p.Removed {
+			ids = append(ids, p.ID)
+		}
 	}
+	return ids
+}
+
+// SubmitAnswer submits a player's answer for the current round. Until the
+// writing timer runs out, submitting again replaces the player's answer. The
+// player is sent a receipt with the answer as stored.
+func (s *GameService) SubmitAnswer(ctx context.Context, gameID string, playerID string, answer string) error {
+	return retryConflicts(ctx, func() error {
+		head, err := s.getGameHead(ctx, gameID)
+		if err != nil {
+			return err
+		}
+		game := head.Game
+
+		if isEliminated(game, playerID) {
+			return ErrPlayerEliminated
+		}
+		if game.State() != domain.GameStateAnswering {
+			return ErrRoundNotAnswering
+		}
+
+		currentRound := &game.Rounds[len(game.Rounds)-1]
+		if s.timeIsUp(currentRound, domain.TimerTypeAnswerWriting) {
+			if game, err = s.fullGame(ctx, head); err != nil {
+				return err
+			}
+			return s.closePhase(ctx, game)
+		}
+
+		// Check if answer is similar to any other player's answer, using the
+		// matching rules of the game's language
+		normalizer := validation.ForLanguage(game.Settings.QuestionLanguage())
+		var previous *domain.Answer
+		for i, ans := range currentRound.AnswerPool.FakeAnswers {
+			if ans.PlayerID == playerID {
+				previous = &currentRound.AnswerPool.FakeAnswers[i]
+				continue
+			}
+			if normalizer.IsSimilar(ans.Text, answer) {
+				return ErrAnswerTooSimilar
+			}
+		}
+
+		// Check if answer is similar to correct answer
+		if normalizer.IsSimilar(currentRound.AnswerPool.CorrectAnswer, answer) {
+			return ErrAnswerIsCorrect
+		}
+
+		// Withhold answers moderation flags, replacing the player's earlier
+		// answer if they had one
+		withheld := s.flagAnswer(ctx, game, answer)
+
+		// The answer is accepted, so the rest of the game is loaded to save it
+		if game, err = s.fullGame(ctx, head); err != nil {
+			return err
+		}
+		currentRound = &game.Rounds[len(game.Rounds)-1]
+		if withheld {
+			return s.submitWithheldAnswer(ctx, game, currentRound, playerID)
+		}
+
+		change := s.newChange(game)
+		submitted := domain.Answer{
+			ID:        s.newID(),
+			PlayerID:  playerID,
+			Text:      answer,
+			Votes:     make([]string, 0),
+			CreatedAt: s.clock.Now(),
+		}
+
+		// Replace the player's earlier answer, which changes nothing else
+		if previous != nil {
+			submitted.ID = previous.ID
+			if err := change.emit(domain.EventAnswerReplaced, domain.AnswerReplacedPayload{Answer: submitted}); err != nil {
+				return err
+			}
+			if err := publishAnswerReceipt(change, submitted, true); err != nil {
+				return err
+			}
+			return s.commit(ctx, change)
+		}
+
+		// Add answer to pool
+		if err := change.emit(domain.EventAnswerSubmitted, domain.AnswerSubmittedPayload{Answer: submitted}); err != nil {
+			return err
+		}
+		if err := publishAnswerReceipt(change, submitted, false); err != nil {
+			return err
+		}
+
+		// If enough players have submitted answers, start voting
+		if answersComplete(game, currentRound) {
+			if err := s.startVoting(ctx, change); err != nil {
+				return err
+			}
+		}
 
-	currentRound.CurrentTurn = turn
-	return s.UpdateGame(ctx, game)
+		return s.commit(ctx, change)
+	})
 }
 
-// SelectCategory handles category selection during a turn
-func (s *GameService) SelectCategory(ctx context.Context, gameID string, category string) error {
-	game, err := s.GetGame(ctx, gameID)
+// submitWithheldAnswer records a player's answer withheld by moderation,
+// starting the vote if it completes the answers
+func (s *GameService) submitWithheldAnswer(ctx context.Context, game *domain.Game, round *domain.Round, playerID string) error {
+	change := s.newChange(game)
+	muted, err := withholdAnswer(change, game, playerID)
 	if err != nil {
 		return err
 	}
 
-	currentRound := &game.Rounds[len(game.Rounds)-1]
-	if currentRound.CurrentTurn == nil || currentRound.CurrentTurn.Status != domain.TurnStatusActive {
-		return errors.New("no active turn")
-	}
-
-	// Validate category is in selected categories
-	validCategory := slices.Contains(game.Settings.SelectedCategories, category)
-
-	if !validCategory {
-		return errors.New("invalid category")
+	if answersComplete(game, round) {
+		if err := s.startVoting(ctx, change); err != nil {
+			return err
+		}
 	}
 
-	// Get random question from category
-	question, err := s.questionRepo.GetRandomQuestion(ctx, category)
-	if err != nil {
+	if err := s.commit(ctx, change); err != nil {
 		return err
 	}
+	if muted {
+		// The hub filters chat as it relays it, so it is told directly
+		s.hub.MutePlayer(game.ID, playerID, true)
+	}
+	return nil
+}
+
+// publishAnswerReceipt privately confirms to a player the answer stored for them
+func publishAnswerReceipt(change *gameChange, answer domain.Answer, replaced bool) error {
+	return change.publishTo(answer.PlayerID, "answer_received", map[string]any{
+		"answer_id": answer.ID,
+		"text":      answer.Text,
+		"replaced":  replaced,
+	})
+}
 
-	// Update round with question and category
-	currentRound.Category = category
-	currentRound.Question = question.Text
-	currentRound.QuestionID = question.ID
-	currentRound.AnswerPool.CorrectAnswer = question.Answer
+// answersComplete reports whether the answer quorum of the contestants other
+// than the one who chose the question has answered. An answer from the one
+// who chose it does not count towards the quorum, which they are not part of.
+func answersComplete(game *domain.Game, round *domain.Round) bool {
+	var turnPlayerID string
+	if round.CurrentTurn != nil {
+		turnPlayerID = round.CurrentTurn.PlayerID
+	}
 
-	// Start answer writing timer using game settings
-	currentRound.Timer = &domain.Timer{
-		Type:      domain.TimerTypeAnswerWriting,
-		StartTime: time.Now(),
-		Duration:  game.Settings.TimeLimits.AnswerWriting,
-		EndTime:   time.Now().Add(time.Duration(game.Settings.TimeLimits.AnswerWriting) * time.Second),
+	answering := slices.DeleteFunc(game.Contestants(), func(p domain.Player) bool { return p.ID == turnPlayerID })
+	answered := 0
+	for _, playerID := range round.Answered() {
+		if slices.ContainsFunc(answering, func(p domain.Player) bool { return p.ID == playerID }) {
+			answered++
+		}
 	}
+	return answered >= game.Settings.AnswersNeeded(len(answering))
+}
+
+// submissionTolerance is how long after a phase's timer runs out answers and
+// votes are still accepted, covering the time they spend in transit
+const submissionTolerance = 2 * time.Second
 
-	return s.UpdateGame(ctx, game)
+// timeIsUp reports whether the round's timer for a phase has run out
+func (s *GameService) timeIsUp(round *domain.Round, timerType domain.TimerType) bool {
+	return round.Timer != nil && round.Timer.Type == timerType &&
+		s.clock.Now().After(round.Timer.EndTime.Add(submissionTolerance))
 }
 
-// SubmitAnswer submits a player's answer for the current round
-func (s *GameService) SubmitAnswer(ctx context.Context, gameID string, playerID string, answer string) error {
-	game, err := s.GetGame(ctx, gameID)
-	if err != nil {
+// closePhase moves on a round whose timer has run out, counting players who
+// have not answered or voted as having no answer, and rejects the late
+// submission that found it still open
+func (s *GameService) closePhase(ctx context.Context, game *domain.Game) error {
+	change := s.newChange(game)
+	if err := s.advanceRound(ctx, change, true); err != nil {
 		return err
 	}
-
-	currentRound := &game.Rounds[len(game.Rounds)-1]
-	if currentRound.Status != domain.RoundStatusWaiting {
-		return errors.New("round is not accepting answers")
+	if err := s.commit(ctx, change); err != nil {
+		return err
 	}
+	return ErrSubmissionTooLate
+}
 
-	// Check if answer is similar to any existing answer
-	for _, ans := range currentRound.AnswerPool.FakeAnswers {
-		if validation.IsSimilarAnswer(ans.Text, answer) {
-			return errors.New("answer is too similar to an existing answer")
+// advanceRound moves the current round on once the active players have
+// played their part: to voting once the answer quorum has answered, and to
+// its end once the vote quorum has voted. A round whose timer has run out moves on without
+// waiting, with fillers standing in for missing answers.
+func (s *GameService) advanceRound(ctx context.Context, change *gameChange, timedOut bool) error {
+	game := change.game
+	switch game.State() {
+	case domain.GameStateAnswering:
+		if currentRound := &game.Rounds[len(game.Rounds)-1]; timedOut || answersComplete(game, currentRound) {
+			return s.startVoting(ctx, change)
 		}
-	}
 
-	// Check if answer is similar to correct answer
-	if validation.IsSimilarAnswer(currentRound.AnswerPool.CorrectAnswer, answer) {
-		return errors.New("answer is too similar to the correct answer")
+	case domain.GameStateVoting:
+		if currentRound := &game.Rounds[len(game.Rounds)-1]; timedOut || votesComplete(game, currentRound) {
+			return s.finishRound(ctx, change, roundPoints(currentRound))
+		}
 	}
+	return nil
+}
 
-	// Add answer to pool
-	newAnswer := domain.Answer{
-		ID:        generateID(),
-		PlayerID:  playerID,
-		Text:      answer,
-		Votes:     make([]string, 0),
-		CreatedAt: time.Now(),
-	}
+// votingSeconds is how long players have to vote
+const votingSeconds = 30
 
-	currentRound.AnswerPool.FakeAnswers = append(currentRound.AnswerPool.FakeAnswers, newAnswer)
+// minVotingOptions is the fewest options offered in a vote, counting the
+// correct answer, so that even a two-player game leaves a real choice
+const minVotingOptions = 4
 
-	// Check if we need to generate filler answers
-	if err := s.ensureAnswerPool(ctx, game); err != nil {
+// maxFillerAttempts bounds the fillers generated from templates for a round,
+// as a question with few key terms can only produce a handful of distinct ones
+const maxFillerAttempts = 50
+
+// startVoting moves the current round from answering to voting. Fillers top
+// up the players' answers, and the voting pool is fixed from the correct
+// answer, the players' answers and the fillers in a shuffled order.
+func (s *GameService) startVoting(ctx context.Context, change *gameChange) error {
+	fillers, err := s.fillerAnswers(ctx, change.game)
+	if err != nil {
 		return err
 	}
+	if len(fillers) > 0 {
+		if err := change.emit(domain.EventFillersAdded, domain.FillersAddedPayload{Answers: fillers}); err != nil {
+			return err
+		}
+	}
 
-	// If all players have submitted answers, start voting
-	if len(currentRound.AnswerPool.FakeAnswers) == len(game.Players)-1 {
-		currentRound.Status = domain.RoundStatusVoting
-		currentRound.Timer = &domain.Timer{
-			Type:      domain.TimerTypeVoting,
-			StartTime: time.Now(),
-			Duration:  30, // 30 seconds for voting
-			EndTime:   time.Now().Add(30 * time.Second),
+	pool := change.game.Rounds[len(change.game.Rounds)-1].AnswerPool
+	correctID := s.newID()
+	options := []domain.AnswerOption{{ID: correctID, Text: pool.CorrectAnswer}}
+	for _, answers := range [][]domain.Answer{pool.FakeAnswers, pool.FillerAnswers} {
+		for _, answer := range answers {
+			options = append(options, domain.AnswerOption{ID: answer.ID, Text: answer.Text})
 		}
 	}
+	s.rng.Shuffle(len(options), func(i, j int) {
+		options[i], options[j] = options[j], options[i]
+	})
 
-	return s.UpdateGame(ctx, game)
+	return s.transition(ctx, change, domain.EventVotingStarted, domain.VotingStartedPayload{
+		Timer:           s.stateTimer(change.game, domain.GameStateVoting),
+		CorrectAnswerID: correctID,
+		Options:         options,
+	})
 }
 
-// ensureAnswerPool ensures we have n+1 answers in the pool
-func (s *GameService) ensureAnswerPool(ctx context.Context, game *domain.Game) error {
+// fillerAnswers generates the filler answers needed for a voting pool of an
+// option per contestant plus the correct answer, and at least
+// minVotingOptions options. The question's own fillers are used first, then
+// ones the language model writes, if there is one, then ones generated from
+// templates; none may resemble another answer in the pool.
+func (s *GameService) fillerAnswers(ctx context.Context, game *domain.Game) ([]domain.Answer, error) {
 	currentRound := &game.Rounds[len(game.Rounds)-1]
-	requiredAnswers := len(game.Players)
+	pool := currentRound.AnswerPool
+	requiredAnswers := max(len(game.Contestants()), minVotingOptions-1)
+	language := game.Settings.QuestionLanguage()
+	normalizer := validation.ForLanguage(language)
 
 	// If we have enough answers, no need for fillers
-	if len(currentRound.AnswerPool.FakeAnswers) >= requiredAnswers {
-		return nil
+	neededFillers := requiredAnswers - len(pool.FakeAnswers) - len(pool.FillerAnswers)
+	if neededFillers <= 0 {
+		return nil, nil
 	}
 
 	// Get the current question to access its filler answers
 	question, err := s.questionRepo.GetByID(ctx, currentRound.QuestionID)
 	if err != nil {
-		return fmt.Errorf("failed to get question: %w", err)
+		return nil, fmt.Errorf("failed to get question: %w", err)
 	}
 
 	// Shuffle filler answers to randomize selection
 	fillerAnswers := make([]string, len(question.FillerAnswers))
 	copy(fillerAnswers, question.FillerAnswers)
-	rand.Shuffle(len(fillerAnswers), func(i, j int) {
+	s.rng.Shuffle(len(fillerAnswers), func(i, j int) {
 		fillerAnswers[i], fillerAnswers[j] = fillerAnswers[j], fillerAnswers[i]
 	})
 
-	// Add filler answers until we have enough
-	neededFillers := requiredAnswers - len(currentRound.AnswerPool.FakeAnswers)
-	for i := 0; i < neededFillers && i < len(fillerAnswers); i++ {
-		fillerAnswer := fillerAnswers[i]
-
-		// Check if filler answer is similar to any existing answer
-		isSimilar := false
-		for _, ans := range currentRound.AnswerPool.FakeAnswers {
-			if validation.IsSimilarAnswer(ans.Text, fillerAnswer) {
-				isSimilar = true
-				break
-			}
-		}
-		if validation.IsSimilarAnswer(currentRound.AnswerPool.CorrectAnswer, fillerAnswer) {
-			isSimilar = true
+	// taken holds every answer a filler must not resemble
+	taken := []string{pool.CorrectAnswer}
+	for _, answers := range [][]domain.Answer{pool.FakeAnswers, pool.FillerAnswers} {
+		for _, ans := range answers {
+			taken = append(taken, ans.Text)
 		}
+	}
 
-		// If similar, skip this filler
-		if isSimilar {
-			continue
+	var added []domain.Answer
+	add := func(fillerAnswer string) {
+		if slices.ContainsFunc(taken, func(t string) bool { return normalizer.IsSimilar(t, fillerAnswer) }) {
+			return
 		}
-
-		newAnswer := domain.Answer{
-			ID:        generateID(),
-			PlayerID:  "system",
+		taken = append(taken, fillerAnswer)
+		added = append(added, domain.Answer{
+			ID:        s.newID(),
+			PlayerID:  domain.FillerPlayerID,
 			Text:      fillerAnswer,
 			Votes:     make([]string, 0),
-			CreatedAt: time.Now(),
-		}
-
-		currentRound.AnswerPool.FillerAnswers = append(currentRound.AnswerPool.FillerAnswers, newAnswer)
+			CreatedAt: s.clock.Now(),
+		})
 	}
 
-	// If we still need more fillers, generate them using templates
-	if len(currentRound.AnswerPool.FillerAnswers) < neededFillers {
-		remaining := neededFillers - len(currentRound.AnswerPool.FillerAnswers)
-		for i := 0; i < remaining; i++ {
-			fillerAnswer, err := s.generateFillerAnswer(currentRound.Question, currentRound.Category)
-			if err != nil {
-				return err
-			}
-
-			// Check if filler answer is similar to any existing answer
-			isSimilar := false
-			for _, ans := range currentRound.AnswerPool.FakeAnswers {
-				if validation.IsSimilarAnswer(ans.Text, fillerAnswer) {
-					isSimilar = true
-					break
-				}
-			}
-			if validation.IsSimilarAnswer(currentRound.AnswerPool.CorrectAnswer, fillerAnswer) {
-				isSimilar = true
-			}
-
-			// If similar, try again
-			if isSimilar {
-				i--
-				continue
-			}
+	// Add filler answers until we have enough
+	for i := 0; i < len(fillerAnswers) && len(added) < neededFillers; i++ {
+		add(fillerAnswers[i])
+	}
 
-			newAnswer := domain.Answer{
-				ID:        generateID(),
-				PlayerID:  "system",
-				Text:      fillerAnswer,
-				Votes:     make([]string, 0),
-				CreatedAt: time.Now(),
-			}
+	// Then ask the language model. Templates make up for it when it fails,
+	// so that it cannot hold up voting.
+	if s.fillers != nil && len(added) < neededFillers {
+		generated, err := s.fillers.Generate(ctx, currentRound.Question, pool.CorrectAnswer, currentRound.Category, language, neededFillers-len(added))
+		if err != nil {
+			fmt.Printf("Failed to generate fillers for game %s: %v\n", game.ID, err)
+		}
+		for i := 0; i < len(generated) && len(added) < neededFillers; i++ {
+			add(generated[i])
+		}
+	}
 
-			currentRound.AnswerPool.FillerAnswers = append(currentRound.AnswerPool.FillerAnswers, newAnswer)
+	// If we still need more fillers, generate them using templates
+	for attempt := 0; attempt < maxFillerAttempts && len(added) < neededFillers; attempt++ {
+		fillerAnswer, err := s.generateFillerAnswer(currentRound.Question, currentRound.Category, language)
+		if err != nil {
+			return nil, err
 		}
+		add(fillerAnswer)
 	}
 
-	return nil
+	return added, nil
 }
 
-// generateFillerAnswer generates a contextually appropriate filler answer
-func (s *GameService) generateFillerAnswer(question string, category string) (string, error) {
-	// For now, use a simple template-based approach
-	templates := map[string][]string{
+// fillerTemplates are the filler answer templates of each language, by
+// category. Every language has a "general" set used for other categories.
+var fillerTemplates = map[string]map[string][]string{
+	"en": {
 		"movies": {
 			"A classic film about %s",
 			"The story of %s",
@@ -552,6 +1417,41 @@ func (s *GameService) generateFillerAnswer(question string, category string) (st
 			"An item connected to %s",
 			"A concept involving %s",
 		},
+	},
+	"ar": {
+		"general": {
+			"شيء متعلق بـ%s",
+			"أمر يخص %s",
+			"عنصر مرتبط بـ%s",
+			"فكرة تتعلق بـ%s",
+		},
+	},
+}
+
+// commonWords are the words of each language skipped when picking key terms
+// from a question
+var commonWords = map[string]map[string]bool{
+	"en": {
+		"the": true, "a": true, "an": true, "in": true, "on": true,
+		"at": true, "to": true, "for": true, "with": true, "by": true,
+		"what": true, "who": true, "where": true, "when": true, "why": true,
+		"how": true, "is": true, "are": true, "was": true, "were": true,
+	},
+	"ar": {
+		"ما": true, "ماذا": true, "من": true, "متى": true, "أين": true,
+		"كيف": true, "لماذا": true, "أي": true, "هو": true, "هي": true,
+		"في": true, "على": true, "عن": true, "إلى": true, "الذي": true,
+		"التي": true, "هل": true, "كم": true,
+	},
+}
+
+// generateFillerAnswer generates a contextually appropriate filler answer in
+// the game's language
+func (s *GameService) generateFillerAnswer(question string, category string, language string) (string, error) {
+	// For now, use a simple template-based approach
+	templates, ok := fillerTemplates[language]
+	if !ok {
+		templates = fillerTemplates[domain.DefaultQuestionLanguage]
 	}
 
 	// Get templates for category or use general ones
@@ -561,32 +1461,31 @@ func (s *GameService) generateFillerAnswer(question string, category string) (st
 	}
 
 	// Extract key terms from the question
-	terms := extractKeyTerms(question)
+	terms := extractKeyTerms(question, language)
 	if len(terms) == 0 {
 		terms = []string{category}
 	}
 
 	// Select a random template and term
-	tmpl := tmpls[rand.Intn(len(tmpls))]
-	term := terms[rand.Intn(len(terms))]
+	tmpl := tmpls[s.rng.Intn(len(tmpls))]
+	term := terms[s.rng.Intn(len(terms))]
 
 	return fmt.Sprintf(tmpl, term), nil
 }
 
 // extractKeyTerms extracts key terms from a question
-func extractKeyTerms(question string) []string {
+func extractKeyTerms(question string, language string) []string {
 	// Simple implementation: split by spaces and filter out common words
 	words := strings.Fields(strings.ToLower(question))
-	commonWords := map[string]bool{
-		"the": true, "a": true, "an": true, "in": true, "on": true,
-		"at": true, "to": true, "for": true, "with": true, "by": true,
-		"what": true, "who": true, "where": true, "when": true, "why": true,
-		"how": true, "is": true, "are": true, "was": true, "were": true,
+	common, ok := commonWords[language]
+	if !ok {
+		common = commonWords[domain.DefaultQuestionLanguage]
 	}
 
 	var terms []string
 	for _, word := range words {
-		if !commonWords[word] && len(word) > 2 {
+		word = strings.TrimFunc(word, unicode.IsPunct)
+		if !common[word] && utf8.RuneCountInString(word) > 2 {
 			terms = append(terms, word)
 		}
 	}
@@ -594,273 +1493,398 @@ func extractKeyTerms(question string) []string {
 	return terms
 }
 
-// SubmitVote submits a player's vote for an answer
+// SubmitVote submits a player's vote for one of the options in the voting
+// pool, which may be another player's answer, a filler or the correct answer
 func (s *GameService) SubmitVote(ctx context.Context, gameID string, playerID string, answerID string) error {
-	game, err := s.GetGame(ctx, gameID)
-	if err != nil {
-		return err
-	}
+	return retryConflicts(ctx, func() error {
+		head, err := s.getGameHead(ctx, gameID)
+		if err != nil {
+			return err
+		}
+		game := head.Game
 
-	currentRound := &game.Rounds[len(game.Rounds)-1]
-	if currentRound.Status != domain.RoundStatusVoting {
-		return errors.New("round is not in voting phase")
-	}
+		if game.State() != domain.GameStateVoting {
+			return ErrRoundNotVoting
+		}
+		currentRound := &game.Rounds[len(game.Rounds)-1]
+		if s.timeIsUp(currentRound, domain.TimerTypeVoting) {
+			if game, err = s.fullGame(ctx, head); err != nil {
+				return err
+			}
+			return s.closePhase(ctx, game)
+		}
 
-	// Check if player has already voted
-	for _, answer := range currentRound.AnswerPool.FakeAnswers {
-		if slices.Contains(answer.Votes, playerID) {
+		if playerID == game.Liar(currentRound) {
+			return ErrLiarCannotVote
+		}
+
+		// Check if player has already voted
+		if currentRound.AnswerPool.HasVoted(playerID) {
 			return domain.ErrVoteSubmitted
 		}
-	}
 
-	// Find the answer and add the vote
-	found := false
-	for i := range currentRound.AnswerPool.FakeAnswers {
-		if currentRound.AnswerPool.FakeAnswers[i].ID == answerID {
-			currentRound.AnswerPool.FakeAnswers[i].Votes = append(currentRound.AnswerPool.FakeAnswers[i].Votes, playerID)
-			found = true
-			break
+		// Find the answer and add the vote
+		if !currentRound.AnswerPool.HasOption(answerID) {
+			return domain.ErrInvalidVote
 		}
-	}
 
-	if !found {
-		return domain.ErrInvalidVote
-	}
-
-	// Check if all players have voted
-	totalVotes := 0
-	for _, answer := range currentRound.AnswerPool.FakeAnswers {
-		totalVotes += len(answer.Votes)
-	}
-
-	if totalVotes == len(game.Players) {
-		// Group answers by content to find duplicates
-		answerGroups := make(map[string][]domain.Answer)
-		for _, answer := range currentRound.AnswerPool.FakeAnswers {
-			answerGroups[answer.Text] = append(answerGroups[answer.Text], answer)
-		}
-
-		// Calculate scores
-		for _, group := range answerGroups {
-			if len(group) > 1 {
-				// This is a group of duplicate answers
-				// Each player in the group gets points for each vote their shared answer received
-				totalVotesForGroup := 0
-				for _, answer := range group {
-					totalVotesForGroup += len(answer.Votes)
-				}
-
-				// Each player in the group gets points equal to the total votes
-				pointsPerPlayer := totalVotesForGroup
-				for _, answer := range group {
-					for i := range game.Players {
-						if game.Players[i].ID == answer.PlayerID {
-							game.Players[i].Score += pointsPerPlayer
-							break
-						}
-					}
-				}
-			} else {
-				// Single answer - normal scoring
-				answer := group[0]
-				points := len(answer.Votes)
-				for i := range game.Players {
-					if game.Players[i].ID == answer.PlayerID {
-						game.Players[i].Score += points
-						break
-					}
-				}
+		if game, err = s.fullGame(ctx, head); err != nil {
+			return err
+		}
+		currentRound = &game.Rounds[len(game.Rounds)-1]
+
+		change := s.newChange(game)
+		if err := change.emit(domain.EventVoteCast, domain.VoteCastPayload{PlayerID: playerID, AnswerID: answerID}); err != nil {
+			return err
+		}
+
+		// Check if enough players have voted
+		if votesComplete(game, currentRound) {
+			if err := s.finishRound(ctx, change, roundPoints(currentRound)); err != nil {
+				return err
 			}
 		}
 
-		currentRound.Status = domain.RoundStatusCompleted
-		currentRound.EndTime = time.Now()
+		return s.commit(ctx, change)
+	})
+}
 
-		// Notify all players of round end and scores
-		payload, err := json.Marshal(game)
-		if err != nil {
-			return err
+// votesComplete reports whether the vote quorum of the active players other
+// than the round's liar has voted
+func votesComplete(game *domain.Game, round *domain.Round) bool {
+	active := voters(game, round)
+	voted := 0
+	for _, voterID := range round.AnswerPool.Voters() {
+		if slices.ContainsFunc(active, func(p domain.Player) bool { return p.ID == voterID }) {
+			voted++
 		}
-		s.hub.BroadcastToGame(game.ID, "round_ended", payload)
 	}
+	return voted >= game.Settings.VotesNeeded(len(active))
+}
 
-	return s.UpdateGame(ctx, game)
+// voters returns the active players who may vote in a round, which leaves
+// out its liar
+func voters(game *domain.Game, round *domain.Round) []domain.Player {
+	liar := game.Liar(round)
+	return slices.DeleteFunc(game.ActivePlayers(), func(p domain.Player) bool { return p.ID == liar })
 }
 
-// EndRound ends the current round and starts a new one
-func (s *GameService) EndRound(ctx context.Context, code string) error {
-	game, err := s.GetGame(ctx, code)
-	if err != nil {
-		return err
+// correctGuessPoints is what a player earns for voting for the correct answer
+const correctGuessPoints = 2
+
+// roundPoints scores a round: each answer earns its author a point per vote,
+// and players who wrote the same answer share the votes of all its copies.
+// Finding the correct answer earns correctGuessPoints. Votes for fillers earn
+// no one anything, and cost the voter their chance at the correct answer.
+func roundPoints(round *domain.Round) map[string]int {
+	// Group answers by content to find duplicates
+	answerGroups := make(map[string][]domain.Answer)
+	for _, answer := range round.AnswerPool.FakeAnswers {
+		answerGroups[answer.Text] = append(answerGroups[answer.Text], answer)
+	}
+
+	// Calculate scores
+	points := make(map[string]int)
+	for _, group := range answerGroups {
+		if len(group) > 1 {
+			// This is a group of duplicate answers
+			// Each player in the group gets points for each vote their shared answer received
+			totalVotesForGroup := 0
+			for _, answer := range group {
+				totalVotesForGroup += len(answer.Votes)
+			}
+
+			// Each player in the group gets points equal to the total votes
+			pointsPerPlayer := totalVotesForGroup
+			for _, answer := range group {
+				points[answer.PlayerID] += pointsPerPlayer
+			}
+		} else {
+			// Single answer - normal scoring
+			answer := group[0]
+			points[answer.PlayerID] += len(answer.Votes)
+		}
 	}
+	for _, voterID := range round.AnswerPool.CorrectVotes {
+		points[voterID] += correctGuessPoints
+	}
+	return points
+}
 
-	if len(game.Rounds) == 0 {
-		return errors.New("no rounds found in game")
+// speedBonuses scores the speed bonus of each correct vote in a round, in
+// proportion to the time that was left on the voting timer when it was cast.
+// Votes cast before vote times were recorded earn no bonus.
+func speedBonuses(settings *domain.GameSettings, round *domain.Round) map[string]int {
+	timer := round.Timer
+	if settings.SpeedBonus <= 0 || timer == nil || timer.Type != domain.TimerTypeVoting {
+		return nil
+	}
+	length := timer.EndTime.Sub(timer.StartTime)
+	if length <= 0 {
+		return nil
 	}
 
-	currentRound := &game.Rounds[len(game.Rounds)-1]
-	if currentRound.Status == domain.RoundStatusCompleted {
-		return errors.New("round already completed")
+	bonuses := make(map[string]int)
+	for _, voterID := range round.AnswerPool.CorrectVotes {
+		votedAt, ok := round.VotedAt[voterID]
+		if !ok {
+			continue
+		}
+		left := min(max(timer.EndTime.Sub(votedAt), 0), length)
+		if bonus := int(math.Round(float64(settings.SpeedBonus) * float64(left) / float64(length))); bonus > 0 {
+			bonuses[voterID] = bonus
+		}
+	}
+	if len(bonuses) == 0 {
+		return nil
 	}
+	return bonuses
+}
 
-	// Mark round as completed
-	currentRound.Status = domain.RoundStatusCompleted
-	currentRound.EndTime = time.Now()
+// EndRound ends the current round and starts a new one. Only the host may
+// force the round to end.
+func (s *GameService) EndRound(ctx context.Context, code string, callerID string) error {
+	return retryConflicts(ctx, func() error {
+		game, err := s.GetGame(ctx, code)
+		if err != nil {
+			return err
+		}
 
-	// Notify all players of round end and scores
-	payload, err := json.Marshal(game)
-	if err != nil {
-		return err
-	}
-	s.hub.BroadcastToGame(game.ID, "round_ended", payload)
+		if err := authorizeHost(game, callerID); err != nil {
+			return err
+		}
 
-	return s.UpdateGame(ctx, game)
+		switch game.State() {
+		case domain.GameStateLobby:
+			return ErrNoRounds
+		case domain.GameStateReveal, domain.GameStateIntermission:
+			return ErrRoundCompleted
+		case domain.GameStateFinished:
+			return ErrGameEnded
+		}
+
+		// Mark round as completed
+		change := s.newChange(game)
+		if err := s.finishRound(ctx, change, nil); err != nil {
+			return err
+		}
+
+		return s.commit(ctx, change)
+	})
 }
 
-// EndGame ends a game session
-func (s *GameService) EndGame(ctx context.Context, code string) error {
-	game, err := s.GetGame(ctx, code)
-	if err != nil {
-		return err
-	}
+// EndGame ends a game session. Only the host may end the game.
+func (s *GameService) EndGame(ctx context.Context, code string, callerID string) error {
+	return retryConflicts(ctx, func() error {
+		game, err := s.GetGame(ctx, code)
+		if err != nil {
+			return err
+		}
 
-	if game.Status == domain.GameStatusEnded {
-		return errors.New("game has already ended")
-	}
+		if err := authorizeHost(game, callerID); err != nil {
+			return err
+		}
 
-	game.Status = domain.GameStatusEnded
-	game.UpdatedAt = time.Now()
-	game.LastActivity = time.Now()
+		return s.endGame(ctx, game, false)
+	})
+}
 
-	if err := s.UpdateGame(ctx, game); err != nil {
-		return err
-	}
+// ForceEndGame ends a game session on behalf of an operator, bypassing host checks
+func (s *GameService) ForceEndGame(ctx context.Context, code string) error {
+	return retryConflicts(ctx, func() error {
+		game, err := s.GetGame(ctx, code)
+		if err != nil {
+			return err
+		}
 
-	// Notify all clients about game end
-	payload, err := json.Marshal(game)
-	if err != nil {
-		return err
-	}
+		return s.endGame(ctx, game, false)
+	})
+}
 
-	s.hub.BroadcastToGame(game.ID, "game_ended", payload)
+// endGame ends a game session without authorization checks. Games that
+// expired from inactivity tell clients so before the usual game_ended.
+func (s *GameService) endGame(ctx context.Context, game *domain.Game, expired bool) error {
+	if game.Status == domain.GameStatusEnded {
+		return ErrGameEnded
+	}
 
-	// Clean up game session
-	if err := s.sessionMgr.DeleteGame(ctx, game.ID); err != nil {
+	change := s.newChange(game)
+	if err := s.finishGame(ctx, change, expired); err != nil {
 		return err
 	}
-
-	return nil
+	return s.commit(ctx, change)
 }
 
-// HandlePlayerReconnection handles a player reconnecting to the game
-func (s *GameService) HandlePlayerReconnection(ctx context.Context, gameID string, playerID string) error {
-	game, err := s.GetGame(ctx, gameID)
+// finishGame ends the changed game, recording its result and adding it to
+// its party's scoreboard
+func (s *GameService) finishGame(ctx context.Context, change *gameChange, expired bool) error {
+	game := change.game
+	if expired {
+		if err := change.publish("game_expired", map[string]any{
+			"last_activity": game.LastActivity,
+		}); err != nil {
+			return err
+		}
+	}
+	// A game ended during an intermission still rewards the round's likes,
+	// which its highlights count
+	from, err := s.leaveState(ctx, change, domain.EventGameEnded)
 	if err != nil {
 		return err
 	}
-
-	// Find player
-	var player *domain.Player
-	for i, p := range game.Players {
-		if p.ID == playerID {
-			player = &game.Players[i]
-			break
-		}
+	// Highlights go in the event log, so replays of the game show them too
+	var ended domain.GameEndedPayload
+	if len(game.Rounds) > 0 {
+		ended.Highlights = domain.NewHighlights(game)
 	}
-
-	if player == nil {
-		return errors.New("player not found in game")
+	if err := change.emit(domain.EventGameEnded, ended); err != nil {
+		return err
 	}
-
-	// Update player status
-	player.IsConnected = true
-	player.LastSeen = time.Now()
-
-	if err := s.UpdateGame(ctx, game); err != nil {
+	if err := s.enterState(ctx, change, from); err != nil {
 		return err
 	}
 
-	// Notify all clients about player reconnection
-	payload, err := json.Marshal(player)
-	if err != nil {
-		return err
+	// Record the outcome of games that got past the lobby, and show players
+	// their standings, awards and highlights
+	if len(game.Rounds) > 0 {
+		change.result = domain.NewGameResult(game, change.now)
+		shareToken, err := generateSessionToken()
+		if err != nil {
+			return fmt.Errorf("failed to generate share token: %w", err)
+		}
+		change.result.ShareToken = shareToken
+		if err := change.publish("game_results", change.result); err != nil {
+			return err
+		}
 	}
 
-	s.hub.BroadcastToGame(game.ID, "player_reconnected", payload)
+	// Notify all clients about game end
+	change.publishGame("game_ended")
 
+	if change.result != nil && game.PartyID != "" {
+		return s.recordPartyGame(ctx, change)
+	}
 	return nil
 }
 
-// HandlePlayerDisconnection handles a player disconnecting from the game
-func (s *GameService) HandlePlayerDisconnection(ctx context.Context, gameID string, playerID string) error {
-	game, err := s.GetGame(ctx, gameID)
+// AuthenticatePlayer resolves a player token to the ID of the player it was
+// issued to
+func (s *GameService) AuthenticatePlayer(ctx context.Context, code string, token string) (string, error) {
+	if token == "" {
+		return "", ErrInvalidPlayerToken
+	}
+
+	game, err := s.GetGame(ctx, code)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	// Update player's active status
-	for i := range game.Players {
-		if game.Players[i].ID == playerID {
-			game.Players[i].IsConnected = false
-			game.Players[i].LastSeen = time.Now()
-			break
+	playerID, err := s.sessionMgr.ResolvePlayerToken(ctx, game.ID, token)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPlayerToken) {
+			return "", ErrInvalidPlayerToken
 		}
+		return "", err
 	}
 
-	// Update game state
-	if err := s.UpdateGame(ctx, game); err != nil {
-		return err
+	// The player may have been removed from the game since the token was issued
+	if !slices.ContainsFunc(game.Players, func(p domain.Player) bool { return p.ID == playerID }) {
+		return "", ErrInvalidPlayerToken
 	}
 
-	// Notify other players
-	payload, err := json.Marshal(map[string]any{
-		"player_id": playerID,
-		"status":    "disconnected",
-	})
+	return playerID, nil
+}
+
+// issuePlayerToken generates a game token for a player and stores it in their session
+func (s *GameService) issuePlayerToken(ctx context.Context, gameID string, player *domain.Player) (string, error) {
+	token, err := generateSessionToken()
 	if err != nil {
-		return err
+		return "", fmt.Errorf("failed to generate player token: %w", err)
 	}
-	s.hub.BroadcastToGame(gameID, "player_disconnected", payload)
 
-	return nil
+	if err := s.sessionMgr.StorePlayerSession(ctx, gameID, player, token); err != nil {
+		return "", err
+	}
+
+	return token, nil
 }
 
-// CleanupInactiveGames cleans up inactive game sessions
-func (s *GameService) CleanupInactiveGames(ctx context.Context) error {
-	games, err := s.sessionMgr.GetAllGames(ctx)
-	if err != nil {
-		return err
-	}
+// HandlePlayerReconnection handles a player reconnecting to the game
+func (s *GameService) HandlePlayerReconnection(ctx context.Context, gameID string, playerID string) error {
+	return retryConflicts(ctx, func() error {
+		game, err := s.GetGame(ctx, gameID)
+		if err != nil {
+			return err
+		}
 
-	now := time.Now()
-	for _, game := range games {
-		// Check if game has been inactive for more than 24 hours
-		if now.Sub(game.LastActivity) > 24*time.Hour {
-			if err := s.EndGame(ctx, game.Code); err != nil {
-				return err
+		// Find player
+		var player *domain.Player
+		for i, p := range game.Players {
+			if p.ID == playerID {
+				player = &game.Players[i]
+				break
 			}
 		}
-	}
 
-	return nil
+		if player == nil {
+			return ErrPlayerNotInGame
+		}
+
+		// Update player status
+		change := s.newChange(game)
+		if err := change.emit(domain.EventPlayerReconnected, domain.PlayerConnectionPayload{PlayerID: playerID}); err != nil {
+			return err
+		}
+
+		// Notify all clients about player reconnection
+		if err := change.publish("player_reconnected", player); err != nil {
+			return err
+		}
+
+		return s.commit(ctx, change)
+	})
+}
+
+// HandlePlayerDisconnection handles a player disconnecting from the game
+func (s *GameService) HandlePlayerDisconnection(ctx context.Context, gameID string, playerID string) error {
+	return retryConflicts(ctx, func() error {
+		game, err := s.GetGame(ctx, gameID)
+		if err != nil {
+			return err
+		}
+
+		// Update player's active status
+		change := s.newChange(game)
+		if err := change.emit(domain.EventPlayerDisconnected, domain.PlayerConnectionPayload{PlayerID: playerID}); err != nil {
+			return err
+		}
+
+		// Notify other players
+		if err := change.publish("player_disconnected", map[string]any{
+			"player_id": playerID,
+			"status":    "disconnected",
+		}); err != nil {
+			return err
+		}
+
+		return s.commit(ctx, change)
+	})
 }
 
 // Helper functions
 
-func generateID() string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, 8)
-	for i := range b {
-		b[i] = charset[rand.Intn(len(charset))]
+// authorizeHost ensures the caller is the game's host
+func authorizeHost(game *domain.Game, callerID string) error {
+	if callerID == "" {
+		return ErrUnauthenticated
+	}
+	if game.HostID != callerID {
+		return ErrNotHost
 	}
-	return string(b)
+	return nil
 }
 
-func generateGameCode() string {
-	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, 6)
-	for i := range b {
-		b[i] = charset[rand.Intn(len(charset))]
-	}
-	return string(b)
+// newID generates an entity ID from the service's clock and random source
+func (s *GameService) newID() string {
+	return s.ids.New(s.clock.Now())
 }
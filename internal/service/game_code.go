@@ -0,0 +1,64 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/zizouhuweidi/dahaa/internal/random"
+)
+
+const (
+	// gameCodeCharset leaves out characters that are easily confused when
+	// read aloud or typed: 0 and O, 1 and I
+	gameCodeCharset = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	// gameCodeLength is the number of characters in a generated game code
+	gameCodeLength = 6
+
+	// maxGameCodeAttempts bounds the retries when a generated code is taken
+	maxGameCodeAttempts = 10
+)
+
+// bannedCodeSubstrings are words that must not appear in generated game
+// codes. Digits that read as letters are folded before matching, so 455
+// matches ASS. Words spelled with I or O are left out as the charset cannot
+// produce them.
+var bannedCodeSubstrings = []string{
+	"ANAL", "ANUS", "ARSE", "ASS", "BUTT", "CRAP", "CUM", "CUNT", "DAMN",
+	"DCK", "DYKE", "FAG", "FCK", "FUCK", "FUK", "FVCK", "GAY", "HELL", "JAP",
+	"JEW", "KKK", "NGGR", "PHUK", "PUSSY", "RAPE", "SEX", "SHAG", "SHT",
+	"SLUT", "TWAT", "WANK", "WTF", "XXX",
+}
+
+// lookalikeDigits maps digits to the letters they are read as
+var lookalikeDigits = strings.NewReplacer("2", "Z", "3", "E", "4", "A", "5", "S", "6", "G", "7", "T", "8", "B", "9", "G")
+
+// newGameCode generates a join code from the service's random source,
+// drawing again until it contains none of the banned words
+func (s *GameService) newGameCode() string {
+	for {
+		code := randomString(s.rng, gameCodeCharset, gameCodeLength)
+		if isCleanGameCode(code) {
+			return code
+		}
+	}
+}
+
+// isCleanGameCode reports whether a code is free of banned words
+func isCleanGameCode(code string) bool {
+	folded := lookalikeDigits.Replace(code)
+	for _, word := range bannedCodeSubstrings {
+		if strings.Contains(code, word) || strings.Contains(folded, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// randomString builds a string of length n from charset
+func randomString(rng random.Source, charset string, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = charset[rng.Intn(len(charset))]
+	}
+	return string(b)
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+const (
+	// defaultInactivityTimeout is how long a game may go without activity
+	// before it expires
+	defaultInactivityTimeout = 24 * time.Hour
+
+	// expiryCheckInterval is how often the expiry job checks games, which
+	// bounds how late warnings and expiries can be
+	expiryCheckInterval = time.Minute
+)
+
+// defaultExpiryWarnings are how long before expiry players are warned
+var defaultExpiryWarnings = []time.Duration{time.Minute, 10 * time.Minute, time.Hour}
+
+// WithExpiry sets how long games may stay inactive before they expire and how
+// long before expiry players are warned. Warnings longer than the timeout are
+// ignored.
+func WithExpiry(timeout time.Duration, warnings ...time.Duration) GameServiceOption {
+	return func(s *GameService) {
+		s.inactivityTimeout = timeout
+		s.expiryWarnings = nil
+		for _, w := range warnings {
+			if w > 0 && w < timeout {
+				s.expiryWarnings = append(s.expiryWarnings, w)
+			}
+		}
+		slices.Sort(s.expiryWarnings)
+	}
+}
+
+// CleanupInactiveGames warns players of games about to expire from
+// inactivity and ends the games that have expired
+func (s *GameService) CleanupInactiveGames(ctx context.Context) error {
+	heads, err := s.sessionMgr.GetGameHeads(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	for _, head := range heads {
+		expiresAt := head.ExpiresAt(s.inactivityTimeout)
+		expired := now.After(expiresAt)
+		if !expired && !s.expiryWarningDue(head.Game, expiresAt, now) {
+			continue
+		}
+
+		game, err := s.loadHead(ctx, head)
+		if err != nil {
+			return err
+		}
+		if game == nil {
+			continue
+		}
+		if expired {
+			err = s.endGame(ctx, game, true)
+		} else {
+			err = s.warnExpiry(ctx, game, expiresAt)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expiryWarningDue reports whether a warning threshold has been crossed since
+// the game's players were last warned
+func (s *GameService) expiryWarningDue(game *domain.Game, expiresAt time.Time, now time.Time) bool {
+	remaining := expiresAt.Sub(now)
+	for _, warning := range s.expiryWarnings {
+		if remaining > warning {
+			continue
+		}
+		// The shortest threshold crossed is the most recent one
+		crossedAt := expiresAt.Add(-warning)
+		return game.ExpiryWarnedAt == nil || game.ExpiryWarnedAt.Before(crossedAt)
+	}
+	return false
+}
+
+// warnExpiry tells a game's players how long is left before it expires
+func (s *GameService) warnExpiry(ctx context.Context, game *domain.Game, expiresAt time.Time) error {
+	change := s.newChange(game)
+	if err := change.emit(domain.EventExpiryWarned, domain.ExpiryWarnedPayload{ExpiresAt: expiresAt}); err != nil {
+		return err
+	}
+
+	if err := change.publish("game_expiring", map[string]any{
+		"expires_at": expiresAt,
+		"minutes":    int(math.Ceil(expiresAt.Sub(change.now).Minutes())),
+	}); err != nil {
+		return err
+	}
+
+	return s.commit(ctx, change)
+}
+
+// ExtendGame keeps a game from expiring by resetting its inactivity timer,
+// returning when it will now expire. Only the host may extend the game.
+func (s *GameService) ExtendGame(ctx context.Context, code string, callerID string) (time.Time, error) {
+	return retryConflictsResult(ctx, func() (time.Time, error) {
+		game, err := s.GetGame(ctx, code)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if err := authorizeHost(game, callerID); err != nil {
+			return time.Time{}, err
+		}
+		if game.Status == domain.GameStatusEnded {
+			return time.Time{}, ErrGameEnded
+		}
+
+		change := s.newChange(game)
+		if err := change.emit(domain.EventGameExtended, domain.GameExtendedPayload{}); err != nil {
+			return time.Time{}, err
+		}
+
+		expiresAt := game.ExpiresAt(s.inactivityTimeout)
+		if err := change.publish("game_extended", map[string]any{
+			"expires_at": expiresAt,
+		}); err != nil {
+			return time.Time{}, err
+		}
+
+		if err := s.commit(ctx, change); err != nil {
+			return time.Time{}, err
+		}
+		return expiresAt, nil
+	})
+}
+
+// StartExpiryJob starts a background job that warns about and ends inactive games
+func (s *GameService) StartExpiryJob(ctx context.Context) {
+	ticker := time.NewTicker(expiryCheckInterval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.CleanupInactiveGames(ctx); err != nil {
+					fmt.Printf("Failed to cleanup inactive games: %v\n", err)
+				}
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+var (
+	// ErrCategoriesDrawn is returned when a player tries to choose the
+	// category in a mode that draws categories at random
+	ErrCategoriesDrawn = errors.New("categories are drawn at random in this mode")
+
+	// ErrPlayerEliminated is returned when an eliminated player tries to
+	// play a part only contestants have
+	ErrPlayerEliminated = errors.New("eliminated players can only vote")
+)
+
+// validModes are the game modes a game can be created with. The empty mode
+// plays as classic.
+var validModes = []domain.GameMode{"", domain.GameModeClassic, domain.GameModeLightning, domain.GameModeElimination}
+
+// finishRound ends the current round, awarding the given points along with
+// the speed bonuses of its correct votes, and moves the game on as its mode
+// requires. Rounds ended without points earn no bonuses either.
+func (s *GameService) finishRound(ctx context.Context, change *gameChange, points map[string]int) error {
+	settings := change.game.Settings
+	var bonuses map[string]int
+	if points != nil {
+		bonuses = speedBonuses(settings, &change.game.Rounds[len(change.game.Rounds)-1])
+		for playerID, bonus := range bonuses {
+			points[playerID] += bonus
+		}
+	}
+	if settings.IsElimination() {
+		// The audience's scores are frozen once they are eliminated
+		for playerID := range points {
+			if isEliminated(change.game, playerID) {
+				delete(points, playerID)
+				delete(bonuses, playerID)
+			}
+		}
+	}
+
+	if err := s.transition(ctx, change, domain.EventRoundEnded, domain.RoundEndedPayload{Points: points, SpeedBonuses: bonuses}); err != nil {
+		return err
+	}
+
+	if settings.IsElimination() {
+		return s.nextEliminationRound(ctx, change)
+	}
+	return s.nextPlannedRound(ctx, change)
+}
+
+// nextPlannedRound moves a classic or lightning game on to its next round,
+// or ends the game once all its rounds are played
+func (s *GameService) nextPlannedRound(ctx context.Context, change *gameChange) error {
+	if len(change.game.Rounds) >= change.game.Settings.RoundCount() {
+		return s.finishGame(ctx, change, false)
+	}
+	return s.nextRound(ctx, change)
+}
+
+// drawCategory picks a random category from the game's selection for the
+// current round and starts answering its question, skipping category selection
+func (s *GameService) drawCategory(ctx context.Context, change *gameChange) error {
+	categories := change.game.Settings.SelectedCategories
+	if len(categories) == 0 {
+		return ErrNoCategories
+	}
+	category := categories[s.rng.Intn(len(categories))]
+
+	return s.askQuestion(ctx, change, category)
+}
+
+// nextEliminationRound eliminates the lowest scorer at the end of every
+// interval of rounds and moves on to the next round. Once the final two have
+// played their duel, or too few contestants are left, the game ends.
+func (s *GameService) nextEliminationRound(ctx context.Context, change *gameChange) error {
+	game := change.game
+	round := game.Rounds[len(game.Rounds)-1]
+	contestants := game.Contestants()
+	if len(contestants) < 2 {
+		return s.finishGame(ctx, change, false)
+	}
+
+	if round.Number%game.Settings.EliminationRounds() == 0 {
+		if len(contestants) == 2 {
+			return s.finishGame(ctx, change, false)
+		}
+		if err := eliminate(change, lowestContestant(contestants, round)); err != nil {
+			return err
+		}
+	}
+
+	return s.nextRound(ctx, change)
+}
+
+// eliminate moves a contestant to the audience, announcing the final duel if
+// only two are left
+func eliminate(change *gameChange, player domain.Player) error {
+	round := change.game.Rounds[len(change.game.Rounds)-1].Number
+	if err := change.emit(domain.EventPlayerEliminated, domain.PlayerEliminatedPayload{
+		PlayerID: player.ID,
+		Round:    round,
+	}); err != nil {
+		return err
+	}
+
+	remaining := change.game.Contestants()
+	if err := change.publish("player_eliminated", map[string]any{
+		"player_id": player.ID,
+		"name":      player.Name,
+		"score":     player.Score,
+		"round":     round,
+		"remaining": len(remaining),
+	}); err != nil {
+		return err
+	}
+
+	if len(remaining) != 2 {
+		return nil
+	}
+	return change.publish("final_duel", map[string]any{
+		"player_ids": []string{remaining[0].ID, remaining[1].ID},
+		"names":      []string{remaining[0].Name, remaining[1].Name},
+		"rounds":     change.game.Settings.EliminationRounds(),
+	})
+}
+
+// lowestContestant returns the contestant to eliminate: the lowest scorer,
+// then the one who scored least in the last round, then the last seated
+func lowestContestant(contestants []domain.Player, round domain.Round) domain.Player {
+	return slices.MinFunc(contestants, func(a, b domain.Player) int {
+		if a.Score != b.Score {
+			return a.Score - b.Score
+		}
+		if lastA, lastB := round.Points[a.ID], round.Points[b.ID]; lastA != lastB {
+			return lastA - lastB
+		}
+		return b.Slot - a.Slot
+	})
+}
+
+// isEliminated reports whether a player has been eliminated from the game
+func isEliminated(game *domain.Game, playerID string) bool {
+	return slices.ContainsFunc(game.Players, func(p domain.Player) bool {
+		return p.ID == playerID && p.EliminatedInRound > 0
+	})
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+const (
+	// defaultReconnectGrace is how long a player who disconnects mid-game
+	// keeps holding up the round before being removed
+	defaultReconnectGrace = time.Minute
+
+	// presenceCheckInterval is how often the presence job looks for players
+	// whose grace period has run out
+	presenceCheckInterval = 5 * time.Second
+)
+
+// WithReconnectGrace sets how long a player who disconnects mid-game is
+// waited for before rounds stop counting on their answers and votes
+func WithReconnectGrace(grace time.Duration) GameServiceOption {
+	return func(s *GameService) {
+		s.reconnectGrace = grace
+	}
+}
+
+// RemoveDisconnectedPlayers removes players of games in progress who have
+// been disconnected for longer than the reconnect grace period, moving on
+// any round that was only waiting for them
+func (s *GameService) RemoveDisconnectedPlayers(ctx context.Context) error {
+	heads, err := s.sessionMgr.GetGameHeads(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	for _, head := range heads {
+		if head.Status != domain.GameStatusPlaying {
+			continue
+		}
+
+		var expired []domain.Player
+		for _, p := range head.Players {
+			if !p.IsConnected && !p.Removed && now.Sub(p.LastSeen) >= s.reconnectGrace {
+				expired = append(expired, p)
+			}
+		}
+		if len(expired) == 0 {
+			continue
+		}
+
+		game, err := s.loadHead(ctx, head)
+		if err != nil {
+			return err
+		}
+		if game == nil {
+			continue
+		}
+		if err := s.removePlayers(ctx, game, expired); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removePlayers removes players from a game's quotas and advances the
+// current round if the remaining players now make up its quorum
+func (s *GameService) removePlayers(ctx context.Context, game *domain.Game, players []domain.Player) error {
+	change := s.newChange(game)
+	for _, p := range players {
+		if err := change.emit(domain.EventPlayerRemoved, domain.PlayerConnectionPayload{PlayerID: p.ID}); err != nil {
+			return err
+		}
+		if err := change.publish("player_removed", map[string]any{
+			"player_id": p.ID,
+			"name":      p.Name,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := s.advanceRound(ctx, change, false); err != nil {
+		return err
+	}
+
+	return s.commit(ctx, change)
+}
+
+// StartPresenceJob starts a background job that removes players whose
+// reconnect grace period has run out
+func (s *GameService) StartPresenceJob(ctx context.Context) {
+	ticker := time.NewTicker(presenceCheckInterval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.RemoveDisconnectedPlayers(ctx); err != nil {
+					fmt.Printf("Failed to remove disconnected players: %v\n", err)
+				}
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
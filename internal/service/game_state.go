@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// stateHook runs as a change moves a game from one state to another. Exit
+// hooks run before the event leaving their state is applied, and entry hooks
+// after the event entering theirs is.
+type stateHook func(s *GameService, ctx context.Context, change *gameChange, from domain.GameState, to domain.GameState) error
+
+// stateExitHooks are the hooks run as a game leaves a state
+var stateExitHooks = map[domain.GameState][]stateHook{
+	domain.GameStateReveal:       {tallyRevealedLikes},
+	domain.GameStateIntermission: {tallyRevealedLikes},
+}
+
+// stateEntryHooks are the hooks run as a game enters a state
+var stateEntryHooks = map[domain.GameState][]stateHook{
+	domain.GameStateCategorySelection: {announceRound},
+	domain.GameStateReveal:            {revealRound},
+	domain.GameStateIntermission:      {announceIntermission},
+}
+
+// transition records an event moving a game into another state, running the
+// hooks of the state it leaves and of the one it enters. Every state change
+// goes through here; emit rejects events the game's state does not allow.
+func (s *GameService) transition(ctx context.Context, change *gameChange, eventType domain.GameEventType, payload any) error {
+	from, err := s.leaveState(ctx, change, eventType)
+	if err != nil {
+		return err
+	}
+	if err := change.emit(eventType, payload); err != nil {
+		return err
+	}
+	return s.enterState(ctx, change, from)
+}
+
+// leaveState checks that an event can move the game on from its state and
+// runs the state's exit hooks, returning the state left. Changes whose event
+// payload depends on what the exit hooks record leave the state first and
+// emit the event themselves.
+func (s *GameService) leaveState(ctx context.Context, change *gameChange, eventType domain.GameEventType) (domain.GameState, error) {
+	from, to, err := change.game.Transition(eventType)
+	if err != nil {
+		return "", err
+	}
+	for _, hook := range stateExitHooks[from] {
+		if err := hook(s, ctx, change, from, to); err != nil {
+			return "", err
+		}
+	}
+	return from, nil
+}
+
+// enterState announces the state the game has entered and runs its entry
+// hooks
+func (s *GameService) enterState(ctx context.Context, change *gameChange, from domain.GameState) error {
+	game := change.game
+	to := game.State()
+	payload := map[string]any{
+		"state":    to,
+		"previous": from,
+	}
+	if len(game.Rounds) > 0 {
+		payload["round"] = len(game.Rounds)
+	}
+	if err := change.publish("state_changed", payload); err != nil {
+		return err
+	}
+
+	for _, hook := range stateEntryHooks[to] {
+		if err := hook(s, ctx, change, from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stateTimer starts the timer of a state the game is entering, or of a turn
+// to pick the round's category
+func (s *GameService) stateTimer(game *domain.Game, state domain.GameState) *domain.Timer {
+	settings := game.Settings
+	switch state {
+	case domain.GameStateCategorySelection:
+		return s.newTimer(domain.TimerTypeCategorySelection, settings.PhaseSeconds(settings.TimeLimits.CategorySelection))
+	case domain.GameStateAnswering:
+		return s.newTimer(domain.TimerTypeAnswerWriting, settings.PhaseSeconds(settings.TimeLimits.AnswerWriting))
+	case domain.GameStateVoting:
+		return s.newTimer(domain.TimerTypeVoting, settings.PhaseSeconds(votingSeconds))
+	case domain.GameStateIntermission:
+		return s.newTimer(domain.TimerTypeIntermission, settings.PhaseSeconds(settings.TimeLimits.Intermission))
+	}
+	return nil
+}
+
+// tallyRevealedLikes awards the like bonus of the round once its reveal is
+// over. A round revealed before an intermission is tallied when the
+// intermission ends, since its answers are liked during it.
+func tallyRevealedLikes(s *GameService, ctx context.Context, change *gameChange, from domain.GameState, to domain.GameState) error {
+	if to == domain.GameStateIntermission {
+		return nil
+	}
+	return s.tallyLikes(ctx, change)
+}
+
+// announceRound tells clients that the game has started or a new round has
+func announceRound(s *GameService, ctx context.Context, change *gameChange, from domain.GameState, to domain.GameState) error {
+	if from == domain.GameStateLobby {
+		change.publishGame("game_started")
+	} else {
+		change.publishGame("round_started")
+	}
+	return nil
+}
+
+// revealRound records each player's performance in the round's category,
+// lets players who fell for a filler know the house fooled them and tells
+// clients the round's scores
+func revealRound(s *GameService, ctx context.Context, change *gameChange, from domain.GameState, to domain.GameState) error {
+	game := change.game
+	round := &game.Rounds[len(game.Rounds)-1]
+	change.categoryStats = append(change.categoryStats, domain.NewRoundCategoryStats(game, round, change.now)...)
+
+	for _, answer := range round.AnswerPool.FillerAnswers {
+		for _, voterID := range answer.Votes {
+			if err := change.publishTo(voterID, "fooled_by_house", map[string]any{
+				"answer_id":      answer.ID,
+				"text":           answer.Text,
+				"correct_answer": round.AnswerPool.CorrectAnswer,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	change.publishGame("round_ended")
+	return nil
+}
+
+// announceIntermission shows the recap of the round just revealed and
+// announces whose turn is next
+func announceIntermission(s *GameService, ctx context.Context, change *gameChange, from domain.GameState, to domain.GameState) error {
+	game := change.game
+	round := game.Rounds[len(game.Rounds)-1]
+	timer := game.Intermission.Timer
+	payload := map[string]any{
+		"round":   round.Number,
+		"points":  round.Points,
+		"ends_at": timer.EndTime,
+		"seconds": timer.Duration,
+	}
+	if i := slices.IndexFunc(game.Players, func(p domain.Player) bool { return p.ID == game.Intermission.NextPlayerID }); i >= 0 {
+		payload["next_player_id"] = game.Players[i].ID
+		payload["next_player_name"] = game.Players[i].Name
+	}
+	return change.publish("intermission_started", payload)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/random"
+	"github.com/zizouhuweidi/dahaa/internal/repository/memory"
+	"github.com/zizouhuweidi/dahaa/internal/websocket"
+)
+
+// newTestGameService creates a game service on in-memory stores, with a
+// released question in the history category
+func newTestGameService(t *testing.T) (*GameService, *memory.GameSessionStore) {
+	t.Helper()
+
+	games := memory.NewGameRepository()
+	events := memory.NewGameEventRepository()
+	changes := memory.NewGameChangeStore(games, events, memory.NewOutboxRepository(), memory.NewGameResultRepository(),
+		memory.NewCategoryStatsRepository(), memory.NewPartyRepository(), memory.NewHallOfFameRepository())
+	questions := memory.NewQuestionRepository(random.New(1))
+	if err := questions.SeedQuestions(context.Background(), &domain.Question{
+		Text:          "history question",
+		Answer:        "history answer",
+		Category:      "history",
+		FillerAnswers: []string{"filler one", "filler two", "filler three"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sessions := memory.NewGameSessionStore()
+	return NewGameService(games, events, changes, questions, websocket.NewHub(), sessions), sessions
+}
+
+// createTestGame creates a history game hosted by alice
+func createTestGame(t *testing.T, s *GameService, maxPlayers int) *domain.Game {
+	t.Helper()
+
+	game, _, err := s.CreateGame(context.Background(), "", domain.Player{ID: "alice", Name: "Alice"}, &domain.GameSettings{
+		Rounds:             2,
+		TimeLimits:         domain.TimeLimits{CategorySelection: 30, AnswerWriting: 30, Voting: 30},
+		SelectedCategories: []string{"history"},
+		MaxPlayers:         maxPlayers,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return game
+}
+
+func TestCommandRetriesStaleSession(t *testing.T) {
+	ctx := context.Background()
+	s, sessions := newTestGameService(t)
+	game := createTestGame(t, s, 8)
+	if _, err := s.JoinGame(ctx, game.ID, domain.Player{ID: "bob", Name: "Bob"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Leave Redis a version behind Postgres, as when a commit's session
+	// update fails or loses a race to a concurrent commit's
+	stale, err := sessions.GetGame(ctx, game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.JoinGame(ctx, game.ID, domain.Player{ID: "carol", Name: "Carol"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sessions.StoreGame(ctx, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.JoinGame(ctx, game.ID, domain.Player{ID: "dave", Name: "Dave"}); err != nil {
+		t.Fatalf("JoinGame on a stale session: %v", err)
+	}
+	got, err := s.GetGame(ctx, game.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Players) != 4 || got.Version != stale.Version+2 {
+		t.Errorf("game has %d players at version %d, want 4 at version %d", len(got.Players), got.Version, stale.Version+2)
+	}
+}
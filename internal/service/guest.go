@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/id"
+	"github.com/zizouhuweidi/dahaa/internal/validation"
+)
+
+// errGuestsNotConfigured is returned when guests are minted without a store
+var errGuestsNotConfigured = errors.New("guests are not configured")
+
+// CreateGuestRequest represents a request to play as a guest
+type CreateGuestRequest struct {
+	DisplayName string `json:"display_name" validate:"omitempty,min=2,max=50"` // A name such as "Guest 4821" is picked when empty
+}
+
+// CreateGuest mints a temporary identity for playing without an account and
+// returns it with the session token it is presented with. Guests expire
+// after GuestExpiration unless they convert to an account.
+func (s *UserService) CreateGuest(ctx context.Context, req CreateGuestRequest) (string, *domain.Guest, error) {
+	if s.guests == nil {
+		return "", nil, errGuestsNotConfigured
+	}
+
+	displayName := fmt.Sprintf("Guest %04d", rand.Intn(10000))
+	if req.DisplayName != "" {
+		var err error
+		if displayName, err = validation.CleanDisplayName(req.DisplayName); err != nil {
+			return "", nil, fmt.Errorf("%w: %w", ErrInvalidDisplayName, err)
+		}
+	}
+
+	token, err := generateSessionToken()
+	if err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	guest := &domain.Guest{
+		ID:          id.New(),
+		DisplayName: displayName,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(domain.GuestExpiration),
+	}
+	if err := s.guests.StoreGuest(ctx, token, guest); err != nil {
+		return "", nil, err
+	}
+
+	return token, guest, nil
+}
+
+// AuthenticateGuest returns the guest a session token was minted for. It
+// returns ErrInvalidSession if the token is unknown or has expired.
+func (s *UserService) AuthenticateGuest(ctx context.Context, token string) (*domain.Guest, error) {
+	if token == "" || s.guests == nil {
+		return nil, ErrInvalidSession
+	}
+	return s.guests.ResolveGuest(ctx, token)
+}
+
+// ConvertGuest registers an account for the guest a session token was
+// minted for, ending the guest and signing the new user in. The account
+// keeps the guest's ID, so the games the guest played count towards its
+// history and stats.
+func (s *UserService) ConvertGuest(ctx context.Context, token string, req RegisterRequest) (string, *domain.User, error) {
+	guest, err := s.AuthenticateGuest(ctx, token)
+	if err != nil {
+		return "", nil, err
+	}
+
+	user, err := s.register(ctx, guest.ID, req, domain.UserRoleUser)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := s.guests.DeleteGuest(ctx, token); err != nil {
+		return "", nil, err
+	}
+
+	session, err := s.startSession(ctx, user.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	return session, user, nil
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+)
+
+// HallOfFameService lists the funniest fake answers players have written
+type HallOfFameService struct {
+	repo domain.HallOfFameRepository
+}
+
+// NewHallOfFameService creates a new hall of fame service
+func NewHallOfFameService(repo domain.HallOfFameRepository) *HallOfFameService {
+	return &HallOfFameService{repo: repo}
+}
+
+// List returns a page of the hall of fame, newest first by default. Only
+// answers whose authors consent to them being shown are listed.
+func (s *HallOfFameService) List(ctx context.Context, params *pagination.Params) (pagination.Page[*domain.HallOfFameEntry], error) {
+	return s.repo.List(ctx, params)
+}
+
+// WithHallOfFameConsent keeps users' hall of fame entries shown or hidden as
+// they change their hall_of_fame preference
+func WithHallOfFameConsent(repo domain.HallOfFameRepository) UserServiceOption {
+	return func(s *UserService) {
+		s.hallOfFame = repo
+	}
+}
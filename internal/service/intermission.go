@@ -0,0 +1,158 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// intermissionCheckInterval is how often intermissions are checked for having
+// run out, which bounds how late the next round starts
+const intermissionCheckInterval = time.Second
+
+// nextRound pauses the game for its intermission, showing the recap of the
+// round just revealed and announcing whose turn is next. Games without an
+// intermission start the next round straight away.
+func (s *GameService) nextRound(ctx context.Context, change *gameChange) error {
+	game := change.game
+	next := nextTurnPlayer(game)
+	seconds := game.Settings.TimeLimits.Intermission
+	if seconds <= 0 {
+		return s.startNextRound(ctx, change, next)
+	}
+
+	var nextID string
+	if next != nil {
+		nextID = next.ID
+	}
+	return s.transition(ctx, change, domain.EventIntermissionStarted, domain.IntermissionStartedPayload{
+		Timer:        s.stateTimer(game, domain.GameStateIntermission),
+		NextPlayerID: nextID,
+	})
+}
+
+// startNextRound starts a game's next round once the likes of the last one
+// are tallied. Lightning rounds draw their category; in other modes the given
+// player's turn to pick one starts.
+func (s *GameService) startNextRound(ctx context.Context, change *gameChange, next *domain.Player) error {
+	game := change.game
+	if err := s.transition(ctx, change, domain.EventRoundStarted, domain.RoundStartedPayload{}); err != nil {
+		return err
+	}
+
+	switch {
+	case game.Settings.IsLightning():
+		if err := s.drawCategory(ctx, change); err != nil {
+			return err
+		}
+	case next != nil:
+		if err := change.emit(domain.EventTurnStarted, domain.TurnStartedPayload{
+			PlayerID: next.ID,
+			Timer:    s.stateTimer(game, domain.GameStateCategorySelection),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextTurnPlayer returns the contestant whose turn follows the last round's,
+// in seat order, preferring connected players. Lightning rounds have no turns.
+func nextTurnPlayer(game *domain.Game) *domain.Player {
+	if game.Settings.IsLightning() {
+		return nil
+	}
+
+	contestants := game.Contestants()
+	if connected := slices.DeleteFunc(slices.Clone(contestants), func(p domain.Player) bool { return !p.IsConnected }); len(connected) > 0 {
+		contestants = connected
+	}
+	if len(contestants) == 0 {
+		return nil
+	}
+	slices.SortFunc(contestants, func(a, b domain.Player) int { return cmp.Compare(a.Slot, b.Slot) })
+
+	lastSlot := -1
+	if len(game.Rounds) > 0 {
+		if turn := game.Rounds[len(game.Rounds)-1].CurrentTurn; turn != nil {
+			if i := slices.IndexFunc(game.Players, func(p domain.Player) bool { return p.ID == turn.PlayerID }); i >= 0 {
+				lastSlot = game.Players[i].Slot
+			}
+		}
+	}
+	for i := range contestants {
+		if contestants[i].Slot > lastSlot {
+			return &contestants[i]
+		}
+	}
+	return &contestants[0]
+}
+
+// AdvanceIntermissions starts the next round of the games whose intermission
+// has run out
+func (s *GameService) AdvanceIntermissions(ctx context.Context) error {
+	heads, err := s.sessionMgr.GetGameHeads(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	for _, head := range heads {
+		if head.Status != domain.GameStatusPlaying || head.Intermission == nil || now.Before(head.Intermission.Timer.EndTime) {
+			continue
+		}
+
+		game, err := s.loadHead(ctx, head)
+		if err != nil {
+			return err
+		}
+		if game == nil {
+			continue
+		}
+		change := s.newChange(game)
+		if err := s.endIntermission(ctx, change); err != nil {
+			return err
+		}
+		if err := s.commit(ctx, change); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// endIntermission starts the next round with the turn of the player
+// announced for it, who is passed over if they have since left or been
+// eliminated
+func (s *GameService) endIntermission(ctx context.Context, change *gameChange) error {
+	game := change.game
+	next := nextTurnPlayer(game)
+	contestants := game.Contestants()
+	if i := slices.IndexFunc(contestants, func(p domain.Player) bool { return p.ID == game.Intermission.NextPlayerID }); i >= 0 {
+		next = &contestants[i]
+	}
+	return s.startNextRound(ctx, change, next)
+}
+
+// StartIntermissionJob starts a background job that starts the next round of
+// games once their intermission runs out
+func (s *GameService) StartIntermissionJob(ctx context.Context) {
+	ticker := time.NewTicker(intermissionCheckInterval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.AdvanceIntermissions(ctx); err != nil {
+					fmt.Printf("Failed to advance intermissions: %v\n", err)
+				}
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// InviteService answers game invitations on behalf of their recipients,
+// coordinating the user and game services
+type InviteService struct {
+	userService *UserService
+	gameService *GameService
+}
+
+// NewInviteService creates a new invite service
+func NewInviteService(userService *UserService, gameService *GameService) *InviteService {
+	return &InviteService{
+		userService: userService,
+		gameService: gameService,
+	}
+}
+
+// AcceptedInvite is the result of accepting an invitation
+type AcceptedInvite struct {
+	Invite      *domain.GameInvite `json:"invite"`
+	Game        *domain.Game       `json:"game"`
+	PlayerToken string             `json:"player_token"`
+}
+
+// AcceptInvite marks the invitation accepted and joins the recipient to its
+// game. The invitation is claimed before joining, so an invitation answered
+// concurrently is neither accepted twice nor declined after its recipient
+// joined. If the join fails, a full game puts the invitation back to pending
+// so it can be retried, while a game that has started or no longer exists
+// expires it.
+func (s *InviteService) AcceptInvite(ctx context.Context, inviteID string, userID string) (*AcceptedInvite, error) {
+	invite, err := s.userService.recipientInvite(ctx, inviteID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	inviteRepo := s.userService.inviteRepo
+	if err := inviteRepo.TransitionStatus(ctx, invite.ID, domain.InviteStatusPending, domain.InviteStatusAccepted); err != nil {
+		return nil, err
+	}
+	invite.Status = domain.InviteStatusAccepted
+
+	player := domain.Player{ID: user.ID, Name: user.DisplayName, IsActive: true}
+	token, err := s.gameService.JoinGame(ctx, invite.GameID, player)
+	if err != nil {
+		status := domain.InviteStatusPending
+		if errors.Is(err, ErrGameInProgress) || errors.Is(err, domain.ErrGameNotFound) {
+			status = domain.InviteStatusExpired
+		}
+		if err := inviteRepo.TransitionStatus(ctx, invite.ID, domain.InviteStatusAccepted, status); err != nil {
+			fmt.Printf("Failed to set invite %s %s: %v\n", invite.ID, status, err)
+		}
+		return nil, err
+	}
+
+	game, err := s.gameService.GetGame(ctx, invite.GameID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AcceptedInvite{Invite: invite, Game: game, PlayerToken: token}, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/repository/memory"
+	"github.com/zizouhuweidi/dahaa/internal/websocket"
+)
+
+func TestAnswerInvite(t *testing.T) {
+	ctx := context.Background()
+	games, _ := newTestGameService(t)
+	game := createTestGame(t, games, 2)
+
+	users := memory.NewUserRepository()
+	for _, id := range []string{"alice", "bob", "carol"} {
+		if err := users.Create(ctx, &domain.User{ID: id, Username: id, Email: id + "@example.com", DisplayName: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	invites := memory.NewGameInviteRepository()
+	userService := NewUserService(users, invites, websocket.NewHub())
+	s := NewInviteService(userService, games)
+
+	invite := func(id string, to string) {
+		t.Helper()
+		if err := invites.Create(ctx, &domain.GameInvite{
+			ID:        id,
+			GameID:    game.ID,
+			FromUser:  "alice",
+			ToUser:    to,
+			Status:    domain.InviteStatusPending,
+			CreatedAt: time.Now(),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantStatus := func(id string, want string) {
+		t.Helper()
+		got, err := invites.GetByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != want {
+			t.Errorf("invite %s status = %s, want %s", id, got.Status, want)
+		}
+	}
+	invite("to-bob", "bob")
+	invite("to-carol", "carol")
+
+	// Only the recipient answers an invitation
+	if err := userService.DeclineGameInvite(ctx, "to-bob", "alice"); !errors.Is(err, ErrNotInviteRecipient) {
+		t.Errorf("declining another user's invite: error = %v, want ErrNotInviteRecipient", err)
+	}
+	if _, err := s.AcceptInvite(ctx, "to-bob", "carol"); !errors.Is(err, ErrNotInviteRecipient) {
+		t.Errorf("accepting another user's invite: error = %v, want ErrNotInviteRecipient", err)
+	}
+
+	accepted, err := s.AcceptInvite(ctx, "to-bob", "bob")
+	if err != nil {
+		t.Fatalf("accepting an invite: %v", err)
+	}
+	if accepted.PlayerToken == "" || len(accepted.Game.Players) != 2 {
+		t.Errorf("accepted invite = %v, want bob seated with a player token", accepted)
+	}
+	wantStatus("to-bob", domain.InviteStatusAccepted)
+	if err := userService.DeclineGameInvite(ctx, "to-bob", "bob"); !errors.Is(err, ErrInviteNotPending) {
+		t.Errorf("declining an accepted invite: error = %v, want ErrInviteNotPending", err)
+	}
+
+	// A full game leaves the invitation pending to be retried
+	if _, err := s.AcceptInvite(ctx, "to-carol", "carol"); !errors.Is(err, ErrGameFull) {
+		t.Errorf("accepting an invite to a full game: error = %v, want ErrGameFull", err)
+	}
+	wantStatus("to-carol", domain.InviteStatusPending)
+	if err := userService.DeclineGameInvite(ctx, "to-carol", "carol"); err != nil {
+		t.Fatalf("declining an invite: %v", err)
+	}
+	wantStatus("to-carol", domain.InviteStatusDeclined)
+	if _, err := s.AcceptInvite(ctx, "to-carol", "carol"); !errors.Is(err, ErrInviteNotPending) {
+		t.Errorf("accepting a declined invite: error = %v, want ErrInviteNotPending", err)
+	}
+}
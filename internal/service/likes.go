@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+var (
+	ErrNotRevealing = errors.New("answers can only be liked while a round is revealed")
+	ErrInvalidLike  = errors.New("only other players' answers in the round can be liked")
+)
+
+// likeBonusPoints is what the authors of a round's most-liked answer earn
+const likeBonusPoints = 1
+
+// LikeAnswer records a player's like for the funniest fake answer of the
+// round being revealed, during the intermission that follows it. Each player
+// has one like per round: liking another answer moves it. Players cannot
+// like their own answer.
+func (s *GameService) LikeAnswer(ctx context.Context, code string, playerID string, answerID string) error {
+	return retryConflicts(ctx, func() error {
+		game, err := s.GetGame(ctx, code)
+		if err != nil {
+			return err
+		}
+
+		if !slices.ContainsFunc(game.Players, func(p domain.Player) bool { return p.ID == playerID }) {
+			return ErrPlayerNotInGame
+		}
+		if game.State() != domain.GameStateIntermission {
+			return ErrNotRevealing
+		}
+
+		round := &game.Rounds[len(game.Rounds)-1]
+		i := slices.IndexFunc(round.AnswerPool.FakeAnswers, func(a domain.Answer) bool { return a.ID == answerID })
+		if i < 0 || round.AnswerPool.FakeAnswers[i].PlayerID == playerID {
+			return ErrInvalidLike
+		}
+		if round.Likes[playerID] == answerID {
+			return nil
+		}
+
+		change := s.newChange(game)
+		if err := change.emit(domain.EventAnswerLiked, domain.AnswerLikedPayload{PlayerID: playerID, AnswerID: answerID}); err != nil {
+			return err
+		}
+		if err := change.publish("likes_updated", map[string]any{
+			"round": round.Number,
+			"likes": round.LikeCounts(),
+		}); err != nil {
+			return err
+		}
+		return s.commit(ctx, change)
+	})
+}
+
+// tallyLikes awards the like bonus to the authors of the last round's
+// most-liked answers once its reveal is over, sharing it between ties.
+// Eliminated players' scores are frozen, so they earn none. Answers liked
+// enough enter the hall of fame.
+func (s *GameService) tallyLikes(ctx context.Context, change *gameChange) error {
+	game := change.game
+	if len(game.Rounds) == 0 {
+		return nil
+	}
+	round := &game.Rounds[len(game.Rounds)-1]
+	if round.Status != domain.RoundStatusCompleted || round.LikeBonuses != nil {
+		return nil
+	}
+
+	likes := round.LikeCounts()
+	most := 0
+	for _, count := range likes {
+		most = max(most, count)
+	}
+	if most == 0 {
+		return nil
+	}
+	s.enterHallOfFame(ctx, change, likes)
+
+	bonuses := make(map[string]int)
+	for _, answer := range round.AnswerPool.FakeAnswers {
+		if likes[answer.ID] == most && !isEliminated(game, answer.PlayerID) {
+			bonuses[answer.PlayerID] = likeBonusPoints
+		}
+	}
+	if len(bonuses) == 0 {
+		return nil
+	}
+
+	if err := change.emit(domain.EventLikesTallied, domain.LikesTalliedPayload{Bonuses: bonuses}); err != nil {
+		return err
+	}
+	return change.publish("likes_tallied", map[string]any{
+		"round":   round.Number,
+		"likes":   likes,
+		"bonuses": bonuses,
+	})
+}
+
+// enterHallOfFame adds the last round's answers liked at least
+// HallOfFameMinLikes times to the hall of fame, noting whether their authors
+// consent to them being shown
+func (s *GameService) enterHallOfFame(ctx context.Context, change *gameChange, likes map[string]int) {
+	if s.users == nil {
+		return
+	}
+
+	game := change.game
+	round := &game.Rounds[len(game.Rounds)-1]
+	for _, answer := range round.AnswerPool.FakeAnswers {
+		if likes[answer.ID] < domain.HallOfFameMinLikes {
+			continue
+		}
+		i := slices.IndexFunc(game.Players, func(p domain.Player) bool { return p.ID == answer.PlayerID })
+		if i < 0 {
+			continue
+		}
+		change.hallOfFame = append(change.hallOfFame, &domain.HallOfFameEntry{
+			ID:            s.newID(),
+			GameID:        game.ID,
+			Category:      round.Category,
+			Question:      round.Question,
+			CorrectAnswer: round.AnswerPool.CorrectAnswer,
+			Answer:        answer.Text,
+			AuthorID:      answer.PlayerID,
+			AuthorName:    game.Players[i].Name,
+			Likes:         likes[answer.ID],
+			Consent:       s.hallOfFameConsent(ctx, answer.PlayerID),
+			CreatedAt:     change.now,
+		})
+	}
+}
+
+// hallOfFameConsent reports whether a player lets their answers be shown in
+// the hall of fame. Guests and players whose preferences cannot be looked up
+// have not consented.
+func (s *GameService) hallOfFameConsent(ctx context.Context, playerID string) bool {
+	user, err := s.users.GetByID(ctx, playerID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			fmt.Printf("Failed to get hall of fame consent of player %s: %v\n", playerID, err)
+		}
+		return false
+	}
+	return user.Preferences.HallOfFame
+}
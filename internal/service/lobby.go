@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// playerColors are the colors assigned to player slots, chosen to be told
+// apart at a glance. Games with more slots than colors reuse them in order.
+var playerColors = []string{
+	"#E6194B", "#3CB44B", "#FFE119", "#4363D8", "#F58231", "#911EB4",
+	"#42D4F4", "#F032E6", "#BFEF45", "#FABED4", "#469990", "#9A6324",
+}
+
+// seatPlayer gives a player joining a game a display name no other player
+// in it has, suffixing a number to taken names, and the lowest free slot
+// along with its color
+func seatPlayer(game *domain.Game, player *domain.Player) {
+	player.Name = uniqueName(game.Players, strings.TrimSpace(player.Name))
+
+	taken := make(map[int]bool, len(game.Players))
+	for _, p := range game.Players {
+		taken[p.Slot] = true
+	}
+	slot := 0
+	for taken[slot] {
+		slot++
+	}
+	player.Slot = slot
+	player.Color = playerColors[slot%len(playerColors)]
+}
+
+// uniqueName returns name, or name followed by the lowest number from 2 that
+// makes it unique among the players. Names are compared ignoring case.
+func uniqueName(players []domain.Player, name string) string {
+	taken := func(candidate string) bool {
+		for _, p := range players {
+			if strings.EqualFold(p.Name, candidate) {
+				return true
+			}
+		}
+		return false
+	}
+
+	candidate := name
+	for n := 2; taken(candidate); n++ {
+		candidate = fmt.Sprintf("%s %d", name, n)
+	}
+	return candidate
+}
+
+// SetReady marks a player in a game's lobby as ready to start or not
+func (s *GameService) SetReady(ctx context.Context, code string, playerID string, ready bool) error {
+	return retryConflicts(ctx, func() error {
+		game, err := s.GetGame(ctx, code)
+		if err != nil {
+			return err
+		}
+
+		if game.Status != domain.GameStatusWaiting {
+			return ErrGameStarted
+		}
+
+		var player *domain.Player
+		for i := range game.Players {
+			if game.Players[i].ID == playerID {
+				player = &game.Players[i]
+				break
+			}
+		}
+		if player == nil {
+			return ErrPlayerNotInGame
+		}
+		if player.Ready == ready {
+			return nil
+		}
+
+		change := s.newChange(game)
+		if err := change.emit(domain.EventPlayerReady, domain.PlayerReadyPayload{PlayerID: playerID, Ready: ready}); err != nil {
+			return err
+		}
+
+		if err := change.publish("player_ready", map[string]any{
+			"player_id": playerID,
+			"name":      player.Name,
+			"ready":     ready,
+		}); err != nil {
+			return err
+		}
+		if err := s.startCountdownIfDue(change); err != nil {
+			return err
+		}
+
+		return s.commit(ctx, change)
+	})
+}
+
+// allReady reports whether every connected player other than the host, who
+// starts the game, is ready
+func allReady(game *domain.Game) bool {
+	for _, p := range game.ConnectedPlayers() {
+		if p.ID != game.HostID && !p.Ready {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// ErrNoOpenLobby is returned when matchmaking finds no listed game to join
+var ErrNoOpenLobby = errors.New("no open game to join")
+
+// maxListedLobbies bounds the games the lobby browser shows at once
+const maxListedLobbies = 50
+
+// ListLobbies returns the listed games waiting for players that a caller may
+// join, those hosted in their region first. Games of an organization are
+// only listed to its members. The region is the caller's preferred one when
+// they give none.
+func (s *GameService) ListLobbies(ctx context.Context, callerID string, region string) ([]*domain.GameSummary, error) {
+	lobbies, err := s.openLobbies(ctx, callerID, region)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]*domain.GameSummary, 0, min(len(lobbies), maxListedLobbies))
+	for _, lobby := range lobbies[:min(len(lobbies), maxListedLobbies)] {
+		summaries = append(summaries, lobby.Summary())
+	}
+	return summaries, nil
+}
+
+// FindLobby matches a caller with the listed game they are best joining:
+// one hosted in their region if there is any, and of those the one closest
+// to being full, so that it starts soonest. It returns ErrNoOpenLobby if the
+// caller may join none.
+func (s *GameService) FindLobby(ctx context.Context, callerID string, region string) (*domain.GameSummary, error) {
+	lobbies, err := s.openLobbies(ctx, callerID, region)
+	if err != nil {
+		return nil, err
+	}
+	if len(lobbies) == 0 {
+		return nil, ErrNoOpenLobby
+	}
+	return lobbies[0].Summary(), nil
+}
+
+// openLobbies returns the listed games waiting for players that a caller may
+// join, those hosted in the region first, then the fullest, then the oldest
+func (s *GameService) openLobbies(ctx context.Context, callerID string, region string) ([]*domain.Game, error) {
+	if region == "" {
+		region = s.preferredRegion(ctx, callerID)
+	}
+	tenantID, err := s.gameTenant(ctx, callerID)
+	if err != nil {
+		return nil, err
+	}
+
+	heads, err := s.sessionMgr.GetGameHeads(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var lobbies []*domain.Game
+	for _, head := range heads {
+		game := head.Game
+		if game.Status != domain.GameStatusWaiting || !game.Settings.Listed || game.TenantID != tenantID ||
+			len(game.Players) >= game.Settings.MaxPlayers ||
+			callerID != "" && slices.ContainsFunc(game.Players, func(p domain.Player) bool { return p.ID == callerID }) {
+			continue
+		}
+		lobbies = append(lobbies, game)
+	}
+
+	nearby := func(g *domain.Game) bool {
+		return region != "" && g.Origin != nil && strings.EqualFold(g.Origin.Region, region)
+	}
+	slices.SortFunc(lobbies, func(a, b *domain.Game) int {
+		if nearA, nearB := nearby(a), nearby(b); nearA != nearB {
+			if nearA {
+				return -1
+			}
+			return 1
+		}
+		if c := len(b.Players) - len(a.Players); c != 0 {
+			return c
+		}
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Code, b.Code)
+	})
+	return lobbies, nil
+}
+
+// preferredRegion returns the region a user prefers games hosted in, if they
+// chose one. Guests and users whose preferences cannot be looked up have
+// none.
+func (s *GameService) preferredRegion(ctx context.Context, userID string) string {
+	if s.users == nil || userID == "" {
+		return ""
+	}
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			fmt.Printf("Failed to get preferred region of user %s: %v\n", userID, err)
+		}
+		return ""
+	}
+	return user.Preferences.Region
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// countdownTickInterval is how often players are told how long is left
+// before their game starts itself, which also bounds how late it starts
+const countdownTickInterval = time.Second
+
+// startCountdownIfDue starts the countdown to a game starting itself once
+// its lobby has filled up to the auto-start size or every player is ready
+func (s *GameService) startCountdownIfDue(change *gameChange) error {
+	game := change.game
+	if game.Status != domain.GameStatusWaiting || game.Countdown != nil || !game.Settings.AutoStarts() {
+		return nil
+	}
+
+	connected := len(game.ConnectedPlayers())
+	if connected < game.Settings.MinimumPlayers() {
+		return nil
+	}
+	full := game.Settings.AutoStartPlayers > 0 && connected >= game.Settings.AutoStartPlayers
+	ready := game.Settings.AutoStartWhenReady && allReady(game)
+	if !full && !ready {
+		return nil
+	}
+
+	timer := s.newTimer(domain.TimerTypeStartCountdown, game.Settings.CountdownSeconds())
+	if err := change.emit(domain.EventCountdownStarted, domain.CountdownStartedPayload{Timer: timer}); err != nil {
+		return err
+	}
+	return change.publish("countdown_started", map[string]any{
+		"ends_at": timer.EndTime,
+		"seconds": timer.Duration,
+	})
+}
+
+// CancelCountdown stops a game from starting itself. Only the host may cancel
+// the countdown; it starts again when the next player joins or gets ready.
+func (s *GameService) CancelCountdown(ctx context.Context, code string, callerID string) error {
+	return retryConflicts(ctx, func() error {
+		game, err := s.GetGame(ctx, code)
+		if err != nil {
+			return err
+		}
+
+		if err := authorizeHost(game, callerID); err != nil {
+			return err
+		}
+		if game.Status != domain.GameStatusWaiting {
+			return ErrGameStarted
+		}
+		if game.Countdown == nil {
+			return ErrNoCountdown
+		}
+
+		return s.cancelCountdown(ctx, game)
+	})
+}
+
+// cancelCountdown stops a game's countdown and tells its players
+func (s *GameService) cancelCountdown(ctx context.Context, game *domain.Game) error {
+	change := s.newChange(game)
+	if err := change.emit(domain.EventCountdownCanceled, domain.CountdownCanceledPayload{}); err != nil {
+		return err
+	}
+	change.publishGame("countdown_canceled")
+	return s.commit(ctx, change)
+}
+
+// AdvanceCountdowns tells the players of games counting down to their start
+// how many seconds are left, and starts the games whose countdown has run
+// out. A game that no longer has enough connected players is not started
+// and its countdown is canceled.
+func (s *GameService) AdvanceCountdowns(ctx context.Context) error {
+	heads, err := s.sessionMgr.GetGameHeads(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	for _, head := range heads {
+		if head.Status != domain.GameStatusWaiting || head.Countdown == nil {
+			continue
+		}
+
+		if now.Before(head.Countdown.EndTime) {
+			payload, err := json.Marshal(map[string]any{
+				"seconds": int(math.Ceil(head.Countdown.EndTime.Sub(now).Seconds())),
+			})
+			if err != nil {
+				return err
+			}
+			// Ticks are not saved: a client that misses one catches up on
+			// the next, or from the countdown in the game
+			s.hub.BroadcastToGame(head.ID, "countdown_tick", payload)
+			continue
+		}
+
+		game, err := s.loadHead(ctx, head)
+		if err != nil {
+			return err
+		}
+		if game == nil {
+			continue
+		}
+
+		if len(game.ConnectedPlayers()) < game.Settings.MinimumPlayers() {
+			if err := s.cancelCountdown(ctx, game); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.startGame(ctx, game); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StartCountdownJob starts a background job that ticks lobby countdowns and
+// starts their games
+func (s *GameService) StartCountdownJob(ctx context.Context) {
+	ticker := time.NewTicker(countdownTickInterval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.AdvanceCountdowns(ctx); err != nil {
+					fmt.Printf("Failed to advance start countdowns: %v\n", err)
+				}
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
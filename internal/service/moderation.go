@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// ErrCannotMuteHost is returned when the host tries to mute themselves
+var ErrCannotMuteHost = errors.New("the host cannot be muted")
+
+// MutePlayer mutes or unmutes a player's chat and reactions for the rest of
+// the game. Muted players keep playing. Only the host may mute players.
+func (s *GameService) MutePlayer(ctx context.Context, code string, callerID string, playerID string, muted bool) error {
+	return retryConflicts(ctx, func() error {
+		game, err := s.GetGame(ctx, code)
+		if err != nil {
+			return err
+		}
+
+		if err := authorizeHost(game, callerID); err != nil {
+			return err
+		}
+		if game.Status == domain.GameStatusEnded {
+			return ErrGameEnded
+		}
+		if playerID == game.HostID {
+			return ErrCannotMuteHost
+		}
+
+		i := slices.IndexFunc(game.Players, func(p domain.Player) bool { return p.ID == playerID })
+		if i < 0 {
+			return ErrPlayerNotInGame
+		}
+		player := game.Players[i]
+		if player.Muted == muted {
+			return nil
+		}
+
+		change := s.newChange(game)
+		if err := change.emit(domain.EventPlayerMuted, domain.PlayerMutedPayload{PlayerID: playerID, Muted: muted}); err != nil {
+			return err
+		}
+
+		if err := change.publish("player_muted", map[string]any{
+			"player_id": playerID,
+			"name":      player.Name,
+			"muted":     muted,
+		}); err != nil {
+			return err
+		}
+
+		if err := s.commit(ctx, change); err != nil {
+			return err
+		}
+
+		// The hub filters chat as it relays it, so it is told directly
+		s.hub.MutePlayer(game.ID, playerID, muted)
+		return nil
+	})
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/id"
+	"github.com/zizouhuweidi/dahaa/internal/validation"
+)
+
+var (
+	ErrUnknownOAuthProvider = errors.New("unknown sign-in provider")
+	ErrOAuthExchange        = domain.ErrOAuthExchange
+)
+
+// WithOAuthProviders lets users sign in with outside accounts from the given
+// providers, chosen by name
+func WithOAuthProviders(providers ...domain.OAuthProvider) UserServiceOption {
+	return func(s *UserService) {
+		if s.oauthProviders == nil {
+			s.oauthProviders = make(map[string]domain.OAuthProvider)
+		}
+		for _, provider := range providers {
+			s.oauthProviders[provider.Name()] = provider
+		}
+	}
+}
+
+// OAuthLoginRequest is an authorization code a client was given by a
+// provider's consent screen
+type OAuthLoginRequest struct {
+	Code        string `json:"code" validate:"required"`
+	RedirectURI string `json:"redirect_uri" validate:"required,url"` // The redirect URI the code was issued for
+}
+
+// LoginWithOAuth signs a user in with an outside account, returning a
+// session token along with the user and whether their account was created.
+// An account not yet linked is linked to the user with the same email if the
+// provider vouches for it, or otherwise gets a new account.
+func (s *UserService) LoginWithOAuth(ctx context.Context, providerName string, req OAuthLoginRequest) (string, *domain.User, bool, error) {
+	provider, ok := s.oauthProviders[providerName]
+	if !ok {
+		return "", nil, false, ErrUnknownOAuthProvider
+	}
+
+	identity, err := provider.Exchange(ctx, req.Code, req.RedirectURI)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	user, created, err := s.oauthUser(ctx, identity)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	token, err := s.finishOAuthLogin(ctx, user)
+	if err != nil {
+		return "", nil, false, err
+	}
+	return token, user, created, nil
+}
+
+// finishOAuthLogin starts the session of a user who signed in with an
+// outside account
+func (s *UserService) finishOAuthLogin(ctx context.Context, user *domain.User) (string, error) {
+	// Keep out suspended and banned users, once they have proven who they are
+	if s.bans != nil {
+		if err := s.bans.Check(ctx, user.ID); err != nil {
+			return "", err
+		}
+	}
+
+	token, err := s.startSession(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	user.LastLoginAt = time.Now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// oauthUser returns the user an outside account belongs to, linking it by
+// verified email or creating an account for it the first time it signs in
+func (s *UserService) oauthUser(ctx context.Context, identity *domain.OAuthIdentity) (*domain.User, bool, error) {
+	user, err := s.userRepo.GetByIdentity(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		return user, false, nil
+	}
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, false, err
+	}
+
+	if identity.Email != "" {
+		existing, err := s.userRepo.GetByEmail(ctx, identity.Email)
+		switch {
+		case err == nil && identity.EmailVerified:
+			if err := s.userRepo.LinkIdentity(ctx, existing.ID, identity.Provider, identity.Subject); err != nil {
+				return nil, false, err
+			}
+			// The provider has verified the address for the user
+			existing.Verified = true
+			return existing, false, nil
+		case err == nil:
+			// Anyone can claim an email the provider has not verified
+			return nil, false, domain.ErrUserAlreadyExists
+		case !errors.Is(err, domain.ErrUserNotFound):
+			return nil, false, err
+		}
+	}
+
+	user = newOAuthUser(identity)
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, false, err
+	}
+	if err := s.userRepo.LinkIdentity(ctx, user.ID, identity.Provider, identity.Subject); err != nil {
+		return nil, false, err
+	}
+	return user, true, nil
+}
+
+// newOAuthUser builds the account of an outside account signing in for the
+// first time. It has no password, and a generated username the user can
+// be known by until they pick a display name of their own.
+func newOAuthUser(identity *domain.OAuthIdentity) *domain.User {
+	userID := id.New()
+	// The end of an ID is random, where its start is its creation time
+	hex := strings.ReplaceAll(userID, "-", "")
+	email := identity.Email
+	if email == "" {
+		email = fmt.Sprintf("%s+%s@invalid", identity.Provider, identity.Subject)
+	}
+
+	displayName, err := validation.CleanDisplayName(identity.Name)
+	if err != nil {
+		local, _, _ := strings.Cut(email, "@")
+		if displayName, err = validation.CleanDisplayName(local); err != nil {
+			displayName = "Player"
+		}
+	}
+
+	now := time.Now()
+	return &domain.User{
+		ID:          userID,
+		Username:    "player_" + hex[len(hex)-12:],
+		Email:       email,
+		DisplayName: displayName,
+		Role:        domain.UserRoleUser,
+		Verified:    identity.EmailVerified && identity.Email != "",
+		Preferences: domain.DefaultUserPreferences(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
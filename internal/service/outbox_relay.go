@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+const (
+	// defaultRelayInterval is how often the outbox is polled when no commit
+	// has signalled new messages, e.g. ones queued by another process
+	defaultRelayInterval = time.Second
+
+	// defaultRelayBatchSize is the number of messages published per transaction
+	defaultRelayBatchSize = 100
+)
+
+// OutboxRelay publishes committed outbox messages to the WebSocket hub.
+// Messages are only removed once published, so delivery is at-least-once,
+// and they are published in the order they were committed.
+type OutboxRelay struct {
+	outbox    domain.OutboxRepository
+	hub       domain.GameBroadcaster
+	interval  time.Duration
+	batchSize int
+	wake      chan struct{}
+}
+
+// NewOutboxRelay creates a new outbox relay
+func NewOutboxRelay(outbox domain.OutboxRepository, hub domain.GameBroadcaster) *OutboxRelay {
+	return &OutboxRelay{
+		outbox:    outbox,
+		hub:       hub,
+		interval:  defaultRelayInterval,
+		batchSize: defaultRelayBatchSize,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Notify wakes the relay to publish newly committed messages without
+// waiting for the next poll
+func (r *OutboxRelay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+		// A wakeup is already pending
+	}
+}
+
+// Run publishes messages until the context is cancelled
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Flush(ctx); err != nil && ctx.Err() == nil {
+			fmt.Printf("Failed to relay outbox messages: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+// Flush publishes all pending messages and returns how many were published
+func (r *OutboxRelay) Flush(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		n, err := r.outbox.ProcessPending(ctx, r.batchSize, r.publish)
+		total += n
+		if err != nil || n < r.batchSize {
+			return total, err
+		}
+	}
+}
+
+// publish broadcasts a batch of messages to their games or audiences, or
+// sends them to their recipient
+func (r *OutboxRelay) publish(messages []*domain.OutboxMessage) error {
+	for _, msg := range messages {
+		switch {
+		case msg.PlayerID != "":
+			r.hub.SendToPlayer(msg.GameID, msg.PlayerID, msg.Type, msg.Payload)
+		case msg.Audience != domain.AudienceEveryone:
+			r.hub.BroadcastToAudience(msg.GameID, msg.Audience, msg.Type, msg.Payload)
+		default:
+			r.hub.BroadcastToGame(msg.GameID, msg.Type, msg.Payload)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// IssueOverlayToken generates a token that lets stream overlays and
+// companion apps follow a game's sanitized view without joining it. Issuing
+// a new token revokes the last one. Only the host may issue it.
+func (s *GameService) IssueOverlayToken(ctx context.Context, code string, callerID string) (string, error) {
+	game, err := s.GetGame(ctx, code)
+	if err != nil {
+		return "", err
+	}
+	if err := authorizeHost(game, callerID); err != nil {
+		return "", err
+	}
+	if game.Status == domain.GameStatusEnded {
+		return "", ErrGameEnded
+	}
+
+	token, err := generateSessionToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate overlay token: %w", err)
+	}
+	if err := s.sessionMgr.StoreOverlayToken(ctx, game.ID, token); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// AuthenticateOverlay resolves an overlay token to the ID of the game it
+// follows
+func (s *GameService) AuthenticateOverlay(ctx context.Context, token string) (string, error) {
+	if token == "" {
+		return "", ErrInvalidOverlayToken
+	}
+
+	gameID, err := s.sessionMgr.ResolveOverlayToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidOverlayToken) {
+			return "", ErrInvalidOverlayToken
+		}
+		return "", err
+	}
+
+	return gameID, nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// ErrNothingToRelease is returned when a pack is published without any new
+// questions
+var ErrNothingToRelease = domain.ErrNothingToRelease
+
+// PackService publishes versions of the question packs and tells clients
+// what each release brought
+type PackService struct {
+	questionRepo domain.QuestionRepository
+}
+
+// NewPackService creates a new pack service
+func NewPackService(questionRepo domain.QuestionRepository) *PackService {
+	return &PackService{questionRepo: questionRepo}
+}
+
+// PublishPack releases the questions added to a language's pack since its
+// last release as its next version. Games already created keep drawing from
+// the version they were created with.
+func (s *PackService) PublishPack(ctx context.Context, language string, notes string) (*domain.PackRelease, error) {
+	return s.questionRepo.PublishPack(ctx, language, notes, time.Now())
+}
+
+// GetChangelog returns the latest version of each pack, or of one
+// language's pack, and the releases newer than sinceVersion
+func (s *PackService) GetChangelog(ctx context.Context, language string, sinceVersion int) (*domain.PackChangelog, error) {
+	releases, err := s.questionRepo.ListPackReleases(ctx, language)
+	if err != nil {
+		return nil, err
+	}
+
+	changelog := &domain.PackChangelog{
+		Latest:   make(map[string]int),
+		Releases: []*domain.PackRelease{},
+	}
+	for _, release := range releases {
+		changelog.Latest[release.Language] = max(changelog.Latest[release.Language], release.Version)
+		if release.Version > sinceVersion {
+			changelog.Releases = append(changelog.Releases, release)
+		}
+	}
+	return changelog, nil
+}
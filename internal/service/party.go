@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// Party errors
+var (
+	ErrPartyNotFound = domain.ErrPartyNotFound
+	ErrNotPartyHost  = errors.New("only the party's host can start its games")
+)
+
+// WithParties sets the repository of party scoreboards. Without it games
+// cannot be played as part of a party.
+func WithParties(repo domain.PartyRepository) GameServiceOption {
+	return func(s *GameService) {
+		s.parties = repo
+	}
+}
+
+// CreatePartyGame creates a game as the next of a party's games, returning
+// it along with the host's player token. An empty partyID starts a new party
+// hosted by the player; otherwise only the party's host may create its games.
+func (s *GameService) CreatePartyGame(ctx context.Context, code string, player domain.Player, settings *domain.GameSettings, partyID string) (*domain.Game, string, error) {
+	if s.parties == nil {
+		return nil, "", ErrPartyNotFound
+	}
+	if err := s.checkBanned(ctx, player.ID); err != nil {
+		return nil, "", err
+	}
+
+	if partyID == "" {
+		party := &domain.Party{
+			ID:        s.newID(),
+			HostID:    player.ID,
+			CreatedAt: s.clock.Now(),
+		}
+		if err := s.parties.Create(ctx, party); err != nil {
+			return nil, "", err
+		}
+		partyID = party.ID
+	} else {
+		board, err := s.parties.Get(ctx, partyID)
+		if err != nil {
+			return nil, "", err
+		}
+		if board.HostID != player.ID {
+			return nil, "", ErrNotPartyHost
+		}
+	}
+
+	return s.hostGame(ctx, code, player, settings, partyID)
+}
+
+// GetPartyScoreboard retrieves a party's running scoreboard
+func (s *GameService) GetPartyScoreboard(ctx context.Context, partyID string) (*domain.PartyScoreboard, error) {
+	if s.parties == nil {
+		return nil, ErrPartyNotFound
+	}
+	return s.parties.Get(ctx, partyID)
+}
+
+// recordPartyGame adds the changed game's result to its party's scoreboard,
+// which is saved with the change, and shows players the updated scoreboard
+func (s *GameService) recordPartyGame(ctx context.Context, change *gameChange) error {
+	if s.parties == nil {
+		return nil
+	}
+
+	board, err := s.parties.Get(ctx, change.game.PartyID)
+	if err != nil {
+		return err
+	}
+	board.Record(change.result, change.now)
+	change.party = board
+
+	return change.publish("party_scoreboard", board)
+}
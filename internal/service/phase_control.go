@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// timerExtensionSeconds is how much time the host adds to a phase at once
+const timerExtensionSeconds = 15
+
+// SkipPhase ends the phase the game is in before its timer runs out, such as
+// when every player is obviously done. A category is drawn for a turn that
+// has not picked one, answering and voting close as if their time were up,
+// and an intermission starts the next round. Only the host may skip a phase.
+func (s *GameService) SkipPhase(ctx context.Context, code string, callerID string) error {
+	return retryConflicts(ctx, func() error {
+		game, err := s.GetGame(ctx, code)
+		if err != nil {
+			return err
+		}
+		if err := authorizeHost(game, callerID); err != nil {
+			return err
+		}
+
+		timer := game.CurrentTimer()
+		if timer == nil {
+			return ErrNoTimedPhase
+		}
+
+		change := s.newChange(game)
+		if err := change.publish("phase_skipped", map[string]any{
+			"phase": timer.Type,
+		}); err != nil {
+			return err
+		}
+
+		switch timer.Type {
+		case domain.TimerTypeIntermission:
+			err = s.endIntermission(ctx, change)
+		case domain.TimerTypeCategorySelection:
+			err = s.drawCategory(ctx, change)
+		default:
+			err = s.advanceRound(ctx, change, true)
+		}
+		if err != nil {
+			return err
+		}
+
+		return s.commit(ctx, change)
+	})
+}
+
+// ExtendTimer gives the phase the game is in more time, returning its timer
+// as extended. Only the host may extend the timer, and only before it runs out.
+func (s *GameService) ExtendTimer(ctx context.Context, code string, callerID string) (*domain.Timer, error) {
+	return retryConflictsResult(ctx, func() (*domain.Timer, error) {
+		game, err := s.GetGame(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		if err := authorizeHost(game, callerID); err != nil {
+			return nil, err
+		}
+
+		timer := game.CurrentTimer()
+		if timer == nil || !s.clock.Now().Before(timer.EndTime) {
+			return nil, ErrNoTimedPhase
+		}
+
+		change := s.newChange(game)
+		if err := change.emit(domain.EventTimerExtended, domain.TimerExtendedPayload{
+			Type:    timer.Type,
+			Seconds: timerExtensionSeconds,
+		}); err != nil {
+			return nil, err
+		}
+
+		timer = change.game.CurrentTimer()
+		if err := change.publish("timer_extended", map[string]any{
+			"phase":    timer.Type,
+			"seconds":  timerExtensionSeconds,
+			"ends_at":  timer.EndTime,
+			"duration": timer.Duration,
+		}); err != nil {
+			return nil, err
+		}
+
+		if err := s.commit(ctx, change); err != nil {
+			return nil, err
+		}
+		return timer, nil
+	})
+}
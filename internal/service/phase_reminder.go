@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// reminderCheckInterval is how often running phases are checked for being
+// due a reminder
+const reminderCheckInterval = time.Second
+
+// reminderThreshold is the fraction of a phase's time left when players who
+// have yet to answer or vote are reminded
+const reminderThreshold = 0.25
+
+// RemindLaggards privately reminds the players of each game who have yet to
+// answer or vote once a quarter of the phase's time is left. The host is told
+// how many players the phase is waiting on. Each phase is reminded once.
+func (s *GameService) RemindLaggards(ctx context.Context) error {
+	heads, err := s.sessionMgr.GetGameHeads(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	for _, head := range heads {
+		timer := head.CurrentTimer()
+		if timer == nil || (timer.Type != domain.TimerTypeAnswerWriting && timer.Type != domain.TimerTypeVoting) {
+			continue
+		}
+		round := &head.Rounds[len(head.Rounds)-1]
+		remaining := timer.EndTime.Sub(now)
+		if round.RemindedPhase == timer.Type || remaining <= 0 ||
+			remaining > time.Duration(float64(timer.Duration)*reminderThreshold*float64(time.Second)) {
+			continue
+		}
+
+		game, err := s.loadHead(ctx, head)
+		if err != nil {
+			return err
+		}
+		if game == nil {
+			continue
+		}
+		if err := s.remindLaggards(ctx, game, timer, remaining); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// remindLaggards reminds the players a game's phase is waiting on
+func (s *GameService) remindLaggards(ctx context.Context, game *domain.Game, timer *domain.Timer, remaining time.Duration) error {
+	round := &game.Rounds[len(game.Rounds)-1]
+	pending, expected := pendingPlayers(game, round, timer.Type)
+	playerIDs := make([]string, len(pending))
+	for i, p := range pending {
+		playerIDs[i] = p.ID
+	}
+
+	change := s.newChange(game)
+	if err := change.emit(domain.EventLaggardsReminded, domain.LaggardsRemindedPayload{
+		Phase:     timer.Type,
+		PlayerIDs: playerIDs,
+	}); err != nil {
+		return err
+	}
+	if len(playerIDs) == 0 {
+		return s.commit(ctx, change)
+	}
+
+	seconds := int(math.Ceil(remaining.Seconds()))
+	for _, playerID := range playerIDs {
+		if err := change.publishTo(playerID, "phase_reminder", map[string]any{
+			"phase":   timer.Type,
+			"seconds": seconds,
+			"ends_at": timer.EndTime,
+		}); err != nil {
+			return err
+		}
+	}
+	if err := change.publishTo(game.HostID, "players_pending", map[string]any{
+		"phase":      timer.Type,
+		"pending":    len(playerIDs),
+		"total":      expected,
+		"player_ids": playerIDs,
+	}); err != nil {
+		return err
+	}
+
+	return s.commit(ctx, change)
+}
+
+// pendingPlayers returns the players a round's phase is still waiting on,
+// and how many players it expects in all: the contestants other than the one
+// who chose the question while answering, and the active players other than
+// the round's liar while voting
+func pendingPlayers(game *domain.Game, round *domain.Round, phase domain.TimerType) ([]domain.Player, int) {
+	var expected []domain.Player
+	var done []string
+	switch phase {
+	case domain.TimerTypeAnswerWriting:
+		for _, p := range game.Contestants() {
+			if round.CurrentTurn == nil || p.ID != round.CurrentTurn.PlayerID {
+				expected = append(expected, p)
+			}
+		}
+		done = round.Answered()
+	case domain.TimerTypeVoting:
+		expected = voters(game, round)
+		done = round.AnswerPool.Voters()
+	}
+
+	var pending []domain.Player
+	for _, p := range expected {
+		if !slices.Contains(done, p.ID) {
+			pending = append(pending, p)
+		}
+	}
+	return pending, len(expected)
+}
+
+// StartReminderJob starts a background job that reminds players who have
+// yet to answer or vote when a phase's time is running out
+func (s *GameService) StartReminderJob(ctx context.Context) {
+	ticker := time.NewTicker(reminderCheckInterval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.RemindLaggards(ctx); err != nil {
+					fmt.Printf("Failed to remind laggards: %v\n", err)
+				}
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
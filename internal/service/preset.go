@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// Preset errors
+var (
+	ErrPresetNotFound    = domain.ErrPresetNotFound
+	ErrPresetKeyReserved = errors.New("preset key is reserved for a built-in preset")
+)
+
+// PresetService manages the settings presets hosts create games from: the
+// built-in presets and those users save under their account
+type PresetService struct {
+	repo domain.SettingsPresetRepository
+}
+
+// NewPresetService creates a new preset service
+func NewPresetService(repo domain.SettingsPresetRepository) *PresetService {
+	return &PresetService{repo: repo}
+}
+
+// ListPresets returns the built-in presets followed by the user's own. Anonymous
+// callers only get the built-in presets.
+func (s *PresetService) ListPresets(ctx context.Context, userID string) ([]*domain.SettingsPreset, error) {
+	presets := domain.BuiltinPresets()
+	if userID == "" {
+		return presets, nil
+	}
+
+	own, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return append(presets, own...), nil
+}
+
+// SavePreset saves settings under a key in the user's account, replacing any
+// preset they saved under the same key. Keys are case-insensitive and may not
+// shadow a built-in preset.
+func (s *PresetService) SavePreset(ctx context.Context, userID string, key string, name string, settings domain.GameSettings) (*domain.SettingsPreset, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	key = strings.ToLower(key)
+	if _, ok := domain.BuiltinPreset(key); ok {
+		return nil, ErrPresetKeyReserved
+	}
+	if err := validateSettings(&settings); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	preset := &domain.SettingsPreset{
+		Key:       key,
+		Name:      name,
+		UserID:    userID,
+		Settings:  settings,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.Save(ctx, preset); err != nil {
+		return nil, err
+	}
+	return preset, nil
+}
+
+// DeletePreset removes one of the user's presets
+func (s *PresetService) DeletePreset(ctx context.Context, userID string, key string) error {
+	if userID == "" {
+		return ErrUnauthenticated
+	}
+	return s.repo.Delete(ctx, userID, strings.ToLower(key))
+}
+
+// ResolveSettings returns the settings of a built-in preset, or of one of the
+// user's presets, to create a game with. Categories and a language given in
+// overrides take the place of the preset's, since built-in presets have no
+// categories of their own.
+func (s *PresetService) ResolveSettings(ctx context.Context, userID string, key string, overrides *domain.GameSettings) (*domain.GameSettings, error) {
+	key = strings.ToLower(key)
+	preset, ok := domain.BuiltinPreset(key)
+	if !ok {
+		if userID == "" {
+			return nil, ErrPresetNotFound
+		}
+		var err error
+		if preset, err = s.repo.Get(ctx, userID, key); err != nil {
+			return nil, err
+		}
+	}
+
+	return withOverrides(preset.Settings, overrides), nil
+}
+
+// withOverrides returns a copy of saved settings with the categories and
+// language given in overrides, when given
+func withOverrides(saved domain.GameSettings, overrides *domain.GameSettings) *domain.GameSettings {
+	settings := saved
+	settings.SelectedCategories = slices.Clone(settings.SelectedCategories)
+	if overrides != nil {
+		if len(overrides.SelectedCategories) > 0 {
+			settings.SelectedCategories = overrides.SelectedCategories
+		}
+		if overrides.Language != "" {
+			settings.Language = overrides.Language
+		}
+	}
+	return &settings
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/id"
+	"github.com/zizouhuweidi/dahaa/internal/random"
+)
+
+// Promo code errors
+var (
+	ErrPromoCodeNotFound    = domain.ErrPromoCodeNotFound
+	ErrPromoCodeExists      = domain.ErrPromoCodeExists
+	ErrPromoBatchNotFound   = domain.ErrPromoBatchNotFound
+	ErrPromoCodeExhausted   = domain.ErrPromoCodeExhausted
+	ErrPromoAlreadyRedeemed = domain.ErrPromoAlreadyRedeemed
+	ErrPromoCodeExpired     = errors.New("promo code has expired")
+	ErrInvalidPromoBatch    = errors.New("promo batches need either a pack or a cosmetic, between 1 and 10000 codes or a single custom code, and an expiry in the future")
+)
+
+const (
+	// promoCodeLength is the number of characters in a generated promo code
+	promoCodeLength = 10
+
+	// maxPromoBatchSize bounds the codes generated in a batch
+	maxPromoBatchSize = 10000
+)
+
+// customPromoCode matches the codes admins may choose themselves
+var customPromoCode = regexp.MustCompile(`^[A-Z0-9]{4,32}$`)
+
+// PromoService creates batches of promo codes and redeems them for premium
+// packs or cosmetics. Each user may redeem one code of a batch.
+type PromoService struct {
+	repo         domain.PromoRepository
+	entitlements *EntitlementService
+	cosmetics    *CosmeticService
+	rng          random.Source
+}
+
+// NewPromoService creates a new promo service. Premium packs and cosmetics
+// unlocked by promo codes are given through the entitlement and cosmetic
+// services.
+func NewPromoService(repo domain.PromoRepository, entitlements *EntitlementService, cosmetics *CosmeticService) *PromoService {
+	return &PromoService{
+		repo:         repo,
+		entitlements: entitlements,
+		cosmetics:    cosmetics,
+		rng:          random.Secure(),
+	}
+}
+
+// CreatePromoBatchRequest represents an admin creating a batch of promo codes
+type CreatePromoBatchRequest struct {
+	Name           string             `json:"name" validate:"required,max=100"`
+	Reward         domain.PromoReward `json:"reward"`
+	Count          int                `json:"count"`                  // Codes to generate, when no custom code is given
+	Code           string             `json:"code" validate:"max=32"` // Custom code, such as a campaign's name
+	MaxRedemptions int                `json:"max_redemptions" validate:"required,min=1"`
+	ExpiresAt      *time.Time         `json:"expires_at"`
+	Moderator      string             `json:"moderator" validate:"required,max=100"`
+}
+
+// CreateBatch creates a batch of promo codes, returning it with its codes.
+// Codes are generated unless the request has a custom code.
+func (s *PromoService) CreateBatch(ctx context.Context, req CreatePromoBatchRequest) (*domain.PromoBatch, []string, error) {
+	now := time.Now()
+	if req.Name == "" || req.MaxRedemptions < 1 ||
+		(req.Reward.PackID == "") == (req.Reward.Cosmetic == "") ||
+		(req.ExpiresAt != nil && !req.ExpiresAt.After(now)) {
+		return nil, nil, ErrInvalidPromoBatch
+	}
+	if req.Reward.PackID != "" {
+		if _, err := s.entitlements.GetPack(ctx, req.Reward.PackID); err != nil {
+			return nil, nil, err
+		}
+	}
+	if req.Reward.Cosmetic != "" {
+		if _, ok := domain.FindCosmetic(req.Reward.Cosmetic); !ok {
+			return nil, nil, ErrCosmeticNotFound
+		}
+	}
+
+	var codes []string
+	if req.Code != "" {
+		code := normalizePromoCode(req.Code)
+		if req.Count > 1 || !customPromoCode.MatchString(code) {
+			return nil, nil, ErrInvalidPromoBatch
+		}
+		codes = []string{code}
+	} else {
+		if req.Count < 1 || req.Count > maxPromoBatchSize {
+			return nil, nil, ErrInvalidPromoBatch
+		}
+		codes = s.newPromoCodes(req.Count)
+	}
+
+	batch := &domain.PromoBatch{
+		ID:             id.New(),
+		Name:           req.Name,
+		Reward:         req.Reward,
+		Codes:          len(codes),
+		MaxRedemptions: req.MaxRedemptions,
+		ExpiresAt:      req.ExpiresAt,
+		CreatedBy:      req.Moderator,
+		CreatedAt:      now,
+	}
+	if err := s.repo.CreateBatch(ctx, batch, codes); err != nil {
+		return nil, nil, err
+	}
+	return batch, codes, nil
+}
+
+// ListBatches returns every batch of promo codes, newest first
+func (s *PromoService) ListBatches(ctx context.Context) ([]*domain.PromoBatch, error) {
+	return s.repo.ListBatches(ctx)
+}
+
+// ListCodes returns a batch's codes and how often each was redeemed
+func (s *PromoService) ListCodes(ctx context.Context, batchID string) ([]*domain.PromoCode, error) {
+	if !id.IsUUID(batchID) {
+		return nil, ErrPromoBatchNotFound
+	}
+	if _, err := s.repo.GetBatch(ctx, batchID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListCodes(ctx, batchID)
+}
+
+// Redeem redeems a promo code for a user, granting its premium pack or
+// unlocking its cosmetic
+func (s *PromoService) Redeem(ctx context.Context, userID string, code string) (*domain.PromoRedemption, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	promo, err := s.repo.GetCode(ctx, normalizePromoCode(code))
+	if err != nil {
+		return nil, err
+	}
+	batch, err := s.repo.GetBatch(ctx, promo.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if batch.IsExpired(now) {
+		return nil, ErrPromoCodeExpired
+	}
+
+	// Codes are not spent on packs the user already owns
+	if batch.Reward.PackID != "" {
+		owned, err := s.entitlements.owns(ctx, userID, batch.Reward.PackID)
+		if err != nil {
+			return nil, err
+		}
+		if owned {
+			return nil, ErrEntitlementExists
+		}
+	}
+
+	redemption := &domain.PromoRedemption{
+		BatchID:    batch.ID,
+		Code:       promo.Code,
+		UserID:     userID,
+		Reward:     batch.Reward,
+		RedeemedAt: now,
+	}
+	if err := s.repo.Redeem(ctx, redemption, batch.MaxRedemptions); err != nil {
+		return nil, err
+	}
+
+	// Rewards are kept by other services, so a reward that cannot be given
+	// cancels the redemption rather than spending the code for nothing
+	if batch.Reward.PackID != "" {
+		if _, err := s.entitlements.grant(ctx, userID, batch.Reward.PackID, domain.EntitlementSourcePromo, "promo:"+promo.Code); err != nil {
+			log.Printf("Failed to grant pack %s for promo code %s to user %s: %v", batch.Reward.PackID, promo.Code, userID, err)
+			s.cancelRedemption(ctx, redemption)
+			return nil, fmt.Errorf("failed to grant promo pack: %w", err)
+		}
+	}
+	if batch.Reward.Cosmetic != "" {
+		if err := s.cosmetics.unlock(ctx, userID, batch.Reward.Cosmetic); err != nil {
+			log.Printf("Failed to unlock cosmetic %s for promo code %s to user %s: %v", batch.Reward.Cosmetic, promo.Code, userID, err)
+			s.cancelRedemption(ctx, redemption)
+			return nil, fmt.Errorf("failed to unlock promo cosmetic: %w", err)
+		}
+	}
+	return redemption, nil
+}
+
+// cancelRedemption hands back a redemption whose reward could not be given,
+// even if the request that redeemed it was canceled
+func (s *PromoService) cancelRedemption(ctx context.Context, redemption *domain.PromoRedemption) {
+	if err := s.repo.CancelRedemption(context.WithoutCancel(ctx), redemption); err != nil {
+		log.Printf("Failed to cancel redemption of promo code %s by user %s: %v", redemption.Code, redemption.UserID, err)
+	}
+}
+
+// ListRedemptions returns the promo codes a user has redeemed, newest first
+func (s *PromoService) ListRedemptions(ctx context.Context, userID string) ([]*domain.PromoRedemption, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+	return s.repo.ListRedemptionsByUser(ctx, userID)
+}
+
+// newPromoCodes generates n distinct promo codes from the service's random
+// source, free of the words kept out of game codes
+func (s *PromoService) newPromoCodes(n int) []string {
+	seen := make(map[string]bool, n)
+	codes := make([]string, 0, n)
+	for len(codes) < n {
+		code := randomString(s.rng, gameCodeCharset, promoCodeLength)
+		if seen[code] || !isCleanGameCode(code) {
+			continue
+		}
+		seen[code] = true
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// normalizePromoCode lets users type codes in any case and with the spaces
+// and dashes they are often printed with
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
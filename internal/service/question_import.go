@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/id"
+)
+
+const (
+	// defaultImportBatchSize is the number of questions created per transaction
+	defaultImportBatchSize = 100
+
+	// importJobRetention is how long finished jobs remain queryable
+	importJobRetention = time.Hour
+
+	// importChannelPrefix prefixes the hub channel an import's progress is streamed to
+	importChannelPrefix = "import:"
+
+	// maxActiveImports is how many imports can be pending or running at
+	// once. Jobs live in memory and each runs on its own goroutine, so
+	// further imports are refused until one finishes.
+	maxActiveImports = 4
+)
+
+// ErrTooManyImports is returned when an import is started while
+// maxActiveImports are still in progress
+var ErrTooManyImports = errors.New("too many imports are in progress, try again later")
+
+// QuestionImportService imports questions asynchronously in batches and
+// reports progress over the WebSocket hub
+type QuestionImportService struct {
+	questionRepo domain.QuestionRepository
+	hub          domain.GameBroadcaster
+	batchSize    int
+
+	mu   sync.RWMutex
+	jobs map[string]*domain.ImportJob
+}
+
+// NewQuestionImportService creates a new question import service
+func NewQuestionImportService(questionRepo domain.QuestionRepository, hub domain.GameBroadcaster) *QuestionImportService {
+	return &QuestionImportService{
+		questionRepo: questionRepo,
+		hub:          hub,
+		batchSize:    defaultImportBatchSize,
+		jobs:         make(map[string]*domain.ImportJob),
+	}
+}
+
+// ImportChannel returns the hub channel an import job's progress is broadcast to
+func ImportChannel(jobID string) string {
+	return importChannelPrefix + jobID
+}
+
+// StartImport registers an import job and processes it in the background,
+// unless too many imports are already in progress
+func (s *QuestionImportService) StartImport(questions []*domain.Question) (*domain.ImportJob, error) {
+	now := time.Now()
+	job := &domain.ImportJob{
+		ID:        id.New(),
+		Status:    domain.ImportJobStatusPending,
+		Total:     len(questions),
+		Batches:   (len(questions) + s.batchSize - 1) / s.batchSize,
+		Errors:    []domain.ImportError{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	s.mu.Lock()
+	active := 0
+	for _, other := range s.jobs {
+		if other.Status == domain.ImportJobStatusPending || other.Status == domain.ImportJobStatusRunning {
+			active++
+		}
+	}
+	if active >= maxActiveImports {
+		s.mu.Unlock()
+		return nil, ErrTooManyImports
+	}
+	s.jobs[job.ID] = job
+	snapshot := cloneImportJob(job)
+	s.mu.Unlock()
+
+	// The import outlives the request that started it
+	go s.run(context.Background(), job.ID, questions)
+
+	return snapshot, nil
+}
+
+// GetJob retrieves a snapshot of an import job
+func (s *QuestionImportService) GetJob(id string) (*domain.ImportJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, domain.ErrImportJobNotFound
+	}
+	return cloneImportJob(job), nil
+}
+
+// run creates the questions batch by batch, each in its own transaction
+func (s *QuestionImportService) run(ctx context.Context, jobID string, questions []*domain.Question) {
+	s.update(jobID, "import_started", func(job *domain.ImportJob) {
+		job.Status = domain.ImportJobStatusRunning
+	})
+
+	for batch, start := 0, 0; start < len(questions); batch, start = batch+1, start+s.batchSize {
+		end := min(start+s.batchSize, len(questions))
+		err := s.questionRepo.BulkCreateQuestions(ctx, questions[start:end])
+
+		s.update(jobID, "import_progress", func(job *domain.ImportJob) {
+			job.Processed += end - start
+			if err != nil {
+				job.Errors = append(job.Errors, domain.ImportError{Batch: batch, Error: err.Error()})
+				return
+			}
+			job.Created += end - start
+		})
+	}
+
+	s.update(jobID, "import_completed", func(job *domain.ImportJob) {
+		job.Status = domain.ImportJobStatusCompleted
+		if job.Total > 0 && job.Created == 0 {
+			job.Status = domain.ImportJobStatusFailed
+		}
+	})
+
+	// Forget the job once clients have had time to collect the result
+	time.AfterFunc(importJobRetention, func() {
+		s.mu.Lock()
+		delete(s.jobs, jobID)
+		s.mu.Unlock()
+	})
+}
+
+// update applies a change to a job and broadcasts the new state
+func (s *QuestionImportService) update(jobID string, eventType string, apply func(job *domain.ImportJob)) {
+	s.mu.Lock()
+	job := s.jobs[jobID]
+	apply(job)
+	job.UpdatedAt = time.Now()
+	snapshot := cloneImportJob(job)
+	s.mu.Unlock()
+
+	payload, err := json.Marshal(snapshot)
+	if err != nil {
+		fmt.Printf("Failed to marshal import job: %v\n", err)
+		return
+	}
+	s.hub.BroadcastToGame(ImportChannel(jobID), eventType, payload)
+}
+
+// cloneImportJob copies a job so it can be read without holding the lock
+func cloneImportJob(job *domain.ImportJob) *domain.ImportJob {
+	clone := *job
+	clone.Errors = slices.Clone(job.Errors)
+	return &clone
+}
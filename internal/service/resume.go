@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// IssueResumeToken generates a token that lets a WebSocket client whose
+// socket dropped, such as a phone app sent to the background, reconnect to
+// the same game as the same player or display without authenticating again
+func (s *GameService) IssueResumeToken(ctx context.Context, session *domain.ResumeSession) (string, error) {
+	token, err := generateSessionToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate resume token: %w", err)
+	}
+	if err := s.sessionMgr.StoreResumeSession(ctx, token, session); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ResumeSession resolves a resume token to what its client was bound to.
+// Tokens of players since removed from the game are no longer valid.
+func (s *GameService) ResumeSession(ctx context.Context, token string) (*domain.ResumeSession, error) {
+	if token == "" {
+		return nil, ErrInvalidResumeToken
+	}
+
+	session, err := s.sessionMgr.ResolveResumeToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidResumeToken) {
+			return nil, ErrInvalidResumeToken
+		}
+		return nil, err
+	}
+
+	if session.PlayerID != "" {
+		game, err := s.GetGame(ctx, session.GameID)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.ContainsFunc(game.Players, func(p domain.Player) bool { return p.ID == session.PlayerID }) {
+			return nil, ErrInvalidResumeToken
+		}
+	}
+
+	return session, nil
+}
+
+// UpdateResumeSession records how far a client got before its socket
+// dropped, giving it a fresh window to reconnect in
+func (s *GameService) UpdateResumeSession(ctx context.Context, token string, session *domain.ResumeSession) error {
+	return s.sessionMgr.StoreResumeSession(ctx, token, session)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// ErrOtherTenant is returned when an organization's identity provider signs
+// in an account that is not one of the organization's members
+var ErrOtherTenant = errors.New("the account is not a member of this organization")
+
+// WithSSO lets organizations' members sign in with their organization's
+// identity provider, configured per organization and reached through the
+// provider built for its configuration
+func WithSSO(tenants domain.TenantRepository, provider func(*domain.SSOConfig) domain.OAuthProvider) UserServiceOption {
+	return func(s *UserService) {
+		s.tenants = tenants
+		s.ssoProvider = provider
+	}
+}
+
+// LoginWithSSO signs a member in with their organization's identity
+// provider, returning a session token along with the user and whether their
+// account was created. Members signing in for the first time get an account
+// on the spot and join the organization, with the role the provider's role
+// claim maps to. When a role claim is configured, later sign-ins keep
+// members' roles in step with it.
+func (s *UserService) LoginWithSSO(ctx context.Context, tenantID string, req OAuthLoginRequest) (string, *domain.User, bool, error) {
+	if s.tenants == nil {
+		return "", nil, false, ErrSSONotConfigured
+	}
+	config, err := s.tenants.GetSSO(ctx, tenantID)
+	if err != nil {
+		return "", nil, false, err
+	}
+	if !config.Enabled {
+		return "", nil, false, ErrSSONotConfigured
+	}
+
+	identity, err := s.ssoProvider(config).Exchange(ctx, req.Code, req.RedirectURI)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	role := config.MapRole(identity.Groups)
+	user, created, err := s.ssoUser(ctx, tenantID, role, identity)
+	if err != nil {
+		return "", nil, false, err
+	}
+	if !created && config.RoleClaim != "" && user.TenantRole != role {
+		if err := s.userRepo.SetTenant(ctx, user.ID, tenantID, role); err != nil {
+			return "", nil, false, fmt.Errorf("failed to update organization member: %w", err)
+		}
+		user.TenantRole = role
+	}
+
+	token, err := s.finishOAuthLogin(ctx, user)
+	if err != nil {
+		return "", nil, false, err
+	}
+	return token, user, created, nil
+}
+
+// ssoUser returns the member an organization's identity provider signed in,
+// linking the provider's account to the member with the same verified email
+// or creating a member for it the first time it signs in. An organization's
+// provider only ever vouches for its own members: accounts outside the
+// organization are never linked or pulled into it, whatever email the
+// provider asserts.
+func (s *UserService) ssoUser(ctx context.Context, tenantID string, role domain.TenantRole, identity *domain.OAuthIdentity) (*domain.User, bool, error) {
+	user, err := s.userRepo.GetByIdentity(ctx, identity.Provider, identity.Subject)
+	switch {
+	case err == nil:
+		if user.TenantID != tenantID {
+			return nil, false, ErrOtherTenant
+		}
+		return user, false, nil
+	case !errors.Is(err, domain.ErrUserNotFound):
+		return nil, false, err
+	}
+
+	if identity.Email != "" {
+		existing, err := s.userRepo.GetByEmail(ctx, identity.Email)
+		switch {
+		case err == nil && existing.TenantID != tenantID:
+			return nil, false, ErrOtherTenant
+		case err == nil && identity.EmailVerified:
+			if err := s.userRepo.LinkIdentity(ctx, existing.ID, identity.Provider, identity.Subject); err != nil {
+				return nil, false, err
+			}
+			existing.Verified = true
+			return existing, false, nil
+		case err == nil:
+			// Anyone can claim an email the provider has not verified
+			return nil, false, domain.ErrUserAlreadyExists
+		case !errors.Is(err, domain.ErrUserNotFound):
+			return nil, false, err
+		}
+	}
+
+	user = newOAuthUser(identity)
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, false, err
+	}
+	if err := s.userRepo.SetTenant(ctx, user.ID, tenantID, role); err != nil {
+		return nil, false, fmt.Errorf("failed to add organization member: %w", err)
+	}
+	user.TenantID, user.TenantRole = tenantID, role
+	if err := s.userRepo.LinkIdentity(ctx, user.ID, identity.Provider, identity.Subject); err != nil {
+		return nil, false, err
+	}
+	return user, true, nil
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/id"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+)
+
+const (
+	// dateLayout is the layout of date-only filters
+	dateLayout = "2006-01-02"
+
+	// rollupInterval is how often the stats rollup job runs
+	rollupInterval = time.Hour
+
+	// defaultRollupLimit is the number of periods returned when none is requested
+	defaultRollupLimit = 1
+
+	// maxRollupLimit is the largest number of periods that may be requested
+	maxRollupLimit = 52
+)
+
+// StatsService reports players' records from finished games
+type StatsService struct {
+	userRepo     domain.UserRepository
+	resultRepo   domain.GameResultRepository
+	rollupRepo   domain.StatsRollupRepository
+	categoryRepo domain.CategoryStatsRepository
+}
+
+// NewStatsService creates a new stats service
+func NewStatsService(userRepo domain.UserRepository, resultRepo domain.GameResultRepository, rollupRepo domain.StatsRollupRepository, categoryRepo domain.CategoryStatsRepository) *StatsService {
+	return &StatsService{
+		userRepo:     userRepo,
+		resultRepo:   resultRepo,
+		rollupRepo:   rollupRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// GetProfile retrieves a user along with their accuracy and fool rate in
+// each category they have played, and their career totals
+func (s *StatsService) GetProfile(ctx context.Context, userID string) (*domain.UserProfile, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.categoryRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	career, err := s.resultRepo.GetCareerStats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.UserProfile{User: user, Categories: categories, Career: career}, nil
+}
+
+// GetHeadToHead compares a user's record against an opponent over the games
+// they both played
+func (s *StatsService) GetHeadToHead(ctx context.Context, userID string, opponentID string) (*domain.HeadToHead, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	// Accept any casing of UUIDs as well as legacy IDs
+	opponentID, err := id.Parse(opponentID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+	if opponentID == userID {
+		return nil, domain.ErrSelfRivalry
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, opponentID); err != nil {
+		return nil, err
+	}
+
+	results, err := s.resultRepo.ListShared(ctx, userID, opponentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewHeadToHead(userID, opponentID, results), nil
+}
+
+// GetPublicResults returns the public results of the game with the given
+// code that a share token was issued for, sanitized to be seen by anyone
+func (s *StatsService) GetPublicResults(ctx context.Context, code string, shareToken string) (*domain.PublicResult, error) {
+	if shareToken == "" {
+		return nil, domain.ErrResultNotFound
+	}
+
+	result, err := s.resultRepo.GetShared(ctx, code, shareToken)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPublicResult(result), nil
+}
+
+// GetGameHistory retrieves a page of a user's past games, optionally limited
+// to games ended between the "from" and "to" filters
+func (s *StatsService) GetGameHistory(ctx context.Context, userID string, params *pagination.Params) (pagination.Page[*domain.GameHistoryEntry], error) {
+	if userID == "" {
+		return pagination.Page[*domain.GameHistoryEntry]{}, ErrUnauthenticated
+	}
+
+	var filter domain.GameHistoryFilter
+	if raw, ok := params.Filter("from"); ok {
+		from, err := parseDateFilter(raw, false)
+		if err != nil {
+			return pagination.Page[*domain.GameHistoryEntry]{}, err
+		}
+		filter.From = from
+	}
+	if raw, ok := params.Filter("to"); ok {
+		to, err := parseDateFilter(raw, true)
+		if err != nil {
+			return pagination.Page[*domain.GameHistoryEntry]{}, err
+		}
+		filter.To = to
+	}
+
+	return s.resultRepo.ListByUser(ctx, userID, filter, params)
+}
+
+// GetStatsRollups retrieves a user's summaries for their most recent periods,
+// newest first. A limit of 0 returns only the latest period.
+func (s *StatsService) GetStatsRollups(ctx context.Context, userID string, period domain.StatsPeriod, limit int) ([]*domain.StatsRollup, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+	if limit <= 0 {
+		limit = defaultRollupLimit
+	}
+	return s.rollupRepo.ListByUser(ctx, userID, period, min(limit, maxRollupLimit))
+}
+
+// RefreshRollups recomputes every period's rollups for the current period and
+// the one before it, so games that ended just before a boundary are counted
+func (s *StatsService) RefreshRollups(ctx context.Context, now time.Time) error {
+	for _, period := range domain.StatsPeriods {
+		current := period.Start(now)
+		previous := period.Start(current.Add(-time.Nanosecond))
+
+		for _, start := range []time.Time{previous, current} {
+			results, err := s.resultRepo.ListEnded(ctx, domain.GameHistoryFilter{From: start, To: period.Next(start)})
+			if err != nil {
+				return fmt.Errorf("failed to list %s results: %w", period, err)
+			}
+
+			rollups := domain.NewStatsRollups(period, start, results, now)
+			if err := s.rollupRepo.Upsert(ctx, rollups); err != nil {
+				return fmt.Errorf("failed to save %s rollups: %w", period, err)
+			}
+		}
+	}
+	return nil
+}
+
+// StartRollupJob starts a background job to keep stats rollups up to date
+func (s *StatsService) StartRollupJob(ctx context.Context) {
+	ticker := time.NewTicker(rollupInterval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.RefreshRollups(ctx, time.Now()); err != nil {
+					fmt.Printf("Failed to refresh stats rollups: %v\n", err)
+				}
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// parseDateFilter parses an RFC 3339 timestamp or a date. An end date covers
+// the whole day, so it is moved to the start of the next one.
+func parseDateFilter(raw string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(dateLayout, raw)
+	if err != nil {
+		return time.Time{}, domain.ErrInvalidDateFilter
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
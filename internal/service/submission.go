@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/id"
+)
+
+// Submission errors
+var (
+	ErrSubmissionNotFound     = domain.ErrSubmissionNotFound
+	ErrInvalidSubmission      = errors.New("submitted question is invalid")
+	ErrInvalidTrustThresholds = errors.New("trust thresholds need at least one approved submission and a rejected share between 0 and 100 percent")
+)
+
+// defaultSubmissionLimit and maxSubmissionLimit bound the submissions listed
+// at once
+const (
+	defaultSubmissionLimit = 50
+	maxSubmissionLimit     = 500
+)
+
+// SubmissionService takes the questions users contribute. Trusted
+// contributors' questions are added as they are submitted; the others wait in
+// the moderation queue, and their review builds the contributor's reputation.
+type SubmissionService struct {
+	repo         domain.QuestionSubmissionRepository
+	questionRepo domain.QuestionRepository
+	userRepo     domain.UserRepository
+}
+
+// NewSubmissionService creates a new submission service
+func NewSubmissionService(repo domain.QuestionSubmissionRepository, questionRepo domain.QuestionRepository, userRepo domain.UserRepository) *SubmissionService {
+	return &SubmissionService{
+		repo:         repo,
+		questionRepo: questionRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// SubmitQuestion submits a question. It is added straight away when the user
+// is a trusted contributor, and queued for review otherwise.
+func (s *SubmissionService) SubmitQuestion(ctx context.Context, userID string, question domain.Question) (*domain.QuestionSubmission, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	question.ID = ""
+	if err := s.questionRepo.ValidateQuestion(ctx, &question); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSubmission, err)
+	}
+
+	reputation, err := s.GetReputation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	submission := &domain.QuestionSubmission{
+		ID:        id.New(),
+		UserID:    userID,
+		Question:  question,
+		Status:    domain.SubmissionStatusPending,
+		CreatedAt: now,
+	}
+
+	if reputation.Level == domain.TrustLevelTrusted {
+		if err := s.questionRepo.CreateQuestion(ctx, &submission.Question); err != nil {
+			return nil, err
+		}
+		submission.Status = domain.SubmissionStatusApproved
+		submission.ReviewedAt = &now
+	}
+
+	if err := s.repo.Create(ctx, submission); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// ListUserSubmissions returns a user's submissions, newest first
+func (s *SubmissionService) ListUserSubmissions(ctx context.Context, userID string, limit int) ([]*domain.QuestionSubmission, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+	return s.repo.ListByUser(ctx, userID, submissionLimit(limit))
+}
+
+// ListQueue returns the submissions waiting for review, oldest first
+func (s *SubmissionService) ListQueue(ctx context.Context, limit int) ([]*domain.QuestionSubmission, error) {
+	return s.repo.ListByStatus(ctx, domain.SubmissionStatusPending, submissionLimit(limit))
+}
+
+// ApproveSubmission adds a queued submission's question to the questions
+func (s *SubmissionService) ApproveSubmission(ctx context.Context, submissionID string, moderator string, note string) (*domain.QuestionSubmission, error) {
+	submission, err := s.pending(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.questionRepo.CreateQuestion(ctx, &submission.Question); err != nil {
+		return nil, err
+	}
+	if err := s.review(ctx, submission, domain.SubmissionStatusApproved, moderator, note); err != nil {
+		// Another moderator reviewed it first, so the question is withdrawn
+		if delErr := s.questionRepo.DeleteQuestion(ctx, submission.Question.ID); delErr != nil {
+			log.Printf("Failed to withdraw question %s of submission %s: %v", submission.Question.ID, submission.ID, delErr)
+		}
+		return nil, err
+	}
+	return submission, nil
+}
+
+// RejectSubmission turns down a queued submission
+func (s *SubmissionService) RejectSubmission(ctx context.Context, submissionID string, moderator string, note string) (*domain.QuestionSubmission, error) {
+	submission, err := s.pending(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.review(ctx, submission, domain.SubmissionStatusRejected, moderator, note); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// GetReputation returns a user's record as a contributor and the trust level
+// it earns them under the current thresholds
+func (s *SubmissionService) GetReputation(ctx context.Context, userID string) (*domain.ContributorReputation, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	thresholds, err := s.repo.GetTrustThresholds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	approved, rejected := counts[domain.SubmissionStatusApproved], counts[domain.SubmissionStatusRejected]
+	return &domain.ContributorReputation{
+		UserID:   userID,
+		Approved: approved,
+		Rejected: rejected,
+		Pending:  counts[domain.SubmissionStatusPending],
+		Level:    thresholds.Level(approved, rejected, user.CreatedAt, time.Now()),
+	}, nil
+}
+
+// GetTrustThresholds returns what contributors need to be trusted
+func (s *SubmissionService) GetTrustThresholds(ctx context.Context) (*domain.TrustThresholds, error) {
+	return s.repo.GetTrustThresholds(ctx)
+}
+
+// UpdateTrustThresholds replaces what contributors need to be trusted.
+// Contributors' trust levels follow at their next submission.
+func (s *SubmissionService) UpdateTrustThresholds(ctx context.Context, thresholds domain.TrustThresholds) (*domain.TrustThresholds, error) {
+	if thresholds.MinApproved < 1 || thresholds.MaxRejectedPercent < 0 || thresholds.MaxRejectedPercent > 100 || thresholds.MinAccountDays < 0 {
+		return nil, ErrInvalidTrustThresholds
+	}
+
+	thresholds.UpdatedAt = time.Now()
+	if err := s.repo.SaveTrustThresholds(ctx, &thresholds); err != nil {
+		return nil, err
+	}
+	return &thresholds, nil
+}
+
+// pending returns a submission waiting for review
+func (s *SubmissionService) pending(ctx context.Context, submissionID string) (*domain.QuestionSubmission, error) {
+	if !id.IsUUID(submissionID) {
+		return nil, ErrSubmissionNotFound
+	}
+	submission, err := s.repo.Get(ctx, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	if submission.Status != domain.SubmissionStatusPending {
+		return nil, ErrSubmissionNotFound
+	}
+	return submission, nil
+}
+
+// review records a moderator's review of a submission
+func (s *SubmissionService) review(ctx context.Context, submission *domain.QuestionSubmission, status domain.SubmissionStatus, moderator string, note string) error {
+	now := time.Now()
+	submission.Status = status
+	submission.ReviewedBy = moderator
+	submission.ReviewNote = note
+	submission.ReviewedAt = &now
+	return s.repo.Review(ctx, submission)
+}
+
+// submissionLimit applies the default and maximum to a requested limit
+func submissionLimit(limit int) int {
+	if limit <= 0 {
+		return defaultSubmissionLimit
+	}
+	return min(limit, maxSubmissionLimit)
+}
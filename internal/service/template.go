@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/id"
+)
+
+// Template errors
+var (
+	ErrTemplateNotFound = domain.ErrTemplateNotFound
+	ErrNotTemplateOwner = errors.New("only the template's owner can change it")
+)
+
+// TemplateService manages game templates: shareable game configurations that
+// users create under their account and administrators curate for everyone
+type TemplateService struct {
+	repo domain.GameTemplateRepository
+}
+
+// NewTemplateService creates a new template service
+func NewTemplateService(repo domain.GameTemplateRepository) *TemplateService {
+	return &TemplateService{repo: repo}
+}
+
+// ListTemplates returns the global templates followed by the user's own.
+// Anonymous callers only get the global templates.
+func (s *TemplateService) ListTemplates(ctx context.Context, userID string) ([]*domain.GameTemplate, error) {
+	return s.repo.ListVisible(ctx, userID)
+}
+
+// GetTemplate returns a template by ID. Templates are shared by ID, so any
+// caller may look one up.
+func (s *TemplateService) GetTemplate(ctx context.Context, templateID string) (*domain.GameTemplate, error) {
+	if !id.IsUUID(templateID) {
+		return nil, ErrTemplateNotFound
+	}
+	return s.repo.Get(ctx, templateID)
+}
+
+// CreateTemplate creates a template owned by the user
+func (s *TemplateService) CreateTemplate(ctx context.Context, userID string, name string, description string, settings domain.GameSettings) (*domain.GameTemplate, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+	return s.create(ctx, userID, name, description, settings)
+}
+
+// CreateGlobalTemplate creates a template listed for everyone
+func (s *TemplateService) CreateGlobalTemplate(ctx context.Context, name string, description string, settings domain.GameSettings) (*domain.GameTemplate, error) {
+	return s.create(ctx, "", name, description, settings)
+}
+
+// UpdateTemplate replaces the name, description and settings of one of the
+// user's templates
+func (s *TemplateService) UpdateTemplate(ctx context.Context, userID string, templateID string, name string, description string, settings domain.GameSettings) (*domain.GameTemplate, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+	return s.update(ctx, userID, templateID, name, description, settings)
+}
+
+// UpdateGlobalTemplate replaces the name, description and settings of a
+// global template
+func (s *TemplateService) UpdateGlobalTemplate(ctx context.Context, templateID string, name string, description string, settings domain.GameSettings) (*domain.GameTemplate, error) {
+	return s.update(ctx, "", templateID, name, description, settings)
+}
+
+// DeleteTemplate removes one of the user's templates
+func (s *TemplateService) DeleteTemplate(ctx context.Context, userID string, templateID string) error {
+	if userID == "" {
+		return ErrUnauthenticated
+	}
+	return s.delete(ctx, userID, templateID)
+}
+
+// DeleteGlobalTemplate removes a global template
+func (s *TemplateService) DeleteGlobalTemplate(ctx context.Context, templateID string) error {
+	return s.delete(ctx, "", templateID)
+}
+
+// ResolveSettings returns the settings of a template to create a game with.
+// Categories and a language given in overrides take the place of the
+// template's, as they do for presets.
+func (s *TemplateService) ResolveSettings(ctx context.Context, templateID string, overrides *domain.GameSettings) (*domain.GameSettings, error) {
+	template, err := s.GetTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	return withOverrides(template.Settings, overrides), nil
+}
+
+// create creates a template owned by ownerID, or a global one when empty
+func (s *TemplateService) create(ctx context.Context, ownerID string, name string, description string, settings domain.GameSettings) (*domain.GameTemplate, error) {
+	if err := validateSettings(&settings); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &domain.GameTemplate{
+		ID:          id.New(),
+		OwnerID:     ownerID,
+		Name:        name,
+		Description: description,
+		Settings:    settings,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// update replaces a template's contents after checking that ownerID owns it
+func (s *TemplateService) update(ctx context.Context, ownerID string, templateID string, name string, description string, settings domain.GameSettings) (*domain.GameTemplate, error) {
+	template, err := s.owned(ctx, ownerID, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSettings(&settings); err != nil {
+		return nil, err
+	}
+
+	template.Name = name
+	template.Description = description
+	template.Settings = settings
+	template.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// delete removes a template after checking that ownerID owns it
+func (s *TemplateService) delete(ctx context.Context, ownerID string, templateID string) error {
+	if _, err := s.owned(ctx, ownerID, templateID); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, templateID)
+}
+
+// owned returns a template if ownerID owns it. An empty ownerID owns the
+// global templates.
+func (s *TemplateService) owned(ctx context.Context, ownerID string, templateID string) (*domain.GameTemplate, error) {
+	template, err := s.GetTemplate(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template.OwnerID != ownerID {
+		return nil, ErrNotTemplateOwner
+	}
+	return template, nil
+}
@@ -0,0 +1,419 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// Tenant errors
+var (
+	ErrTenantNotFound    = domain.ErrTenantNotFound
+	ErrInvalidTenant     = errors.New("organizations need an ID of at most 30 letters, digits, dashes and underscores, and a name")
+	ErrInvalidTenantRole = errors.New("organization roles are member or admin")
+	ErrNotTenantMember   = errors.New("the user does not belong to an organization")
+	ErrNotTenantAdmin    = errors.New("only the organization's admins can do this")
+	ErrTenantSelf        = errors.New("organization admins cannot change their own membership")
+	ErrTenantGame        = errors.New("the game is private to an organization the player does not belong to")
+	ErrSSONotConfigured  = domain.ErrSSONotConfigured
+	ErrInvalidSSOConfig  = errors.New("single sign-on needs an https issuer, a client ID and secret, and role mappings to member or admin")
+)
+
+// maxTenantIDLength bounds organization IDs, which prefix the categories of
+// their private packs
+const maxTenantIDLength = 30
+
+// defaultLeaderboardLimit and maxLeaderboardLimit bound the leaderboard
+// entries listed at once
+const (
+	defaultLeaderboardLimit = 50
+	maxLeaderboardLimit     = 500
+)
+
+// TenantService manages organizations and their members, for companies and
+// other groups that run games among themselves. Admins create organizations
+// and assign their first members through the admin API; organization admins
+// then manage their own members. Games hosted by members are private to the
+// organization, and its leaderboard is only shown to its members.
+type TenantService struct {
+	repo     domain.TenantRepository
+	userRepo domain.UserRepository
+}
+
+// NewTenantService creates a new tenant service
+func NewTenantService(repo domain.TenantRepository, userRepo domain.UserRepository) *TenantService {
+	return &TenantService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// SaveTenantRequest represents an admin creating or renaming an organization
+type SaveTenantRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// SetMemberRequest represents a member's role being set
+type SetMemberRequest struct {
+	Role domain.TenantRole `json:"role" validate:"required,oneof=member admin"`
+}
+
+// SaveSSORequest represents an admin configuring an organization's single
+// sign-on
+type SaveSSORequest struct {
+	Issuer       string                       `json:"issuer" validate:"required,url,max=255"`
+	ClientID     string                       `json:"client_id" validate:"required,max=255"`
+	ClientSecret string                       `json:"client_secret" validate:"max=1000"` // Keeps the saved secret when empty
+	RoleClaim    string                       `json:"role_claim" validate:"max=100"`
+	RoleMapping  map[string]domain.TenantRole `json:"role_mapping"`
+	Enabled      bool                         `json:"enabled"`
+}
+
+// SaveTenant creates or renames an organization
+func (s *TenantService) SaveTenant(ctx context.Context, tenantID string, req SaveTenantRequest) (*domain.Tenant, error) {
+	if !validTenantID(tenantID) || strings.TrimSpace(req.Name) == "" {
+		return nil, ErrInvalidTenant
+	}
+
+	now := time.Now()
+	tenant := &domain.Tenant{
+		ID:        tenantID,
+		Name:      strings.TrimSpace(req.Name),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.Save(ctx, tenant); err != nil {
+		return nil, err
+	}
+	return tenant, nil
+}
+
+// validTenantID reports whether an organization ID is short and made of
+// letters, digits, dashes and underscores, so it can prefix category names
+func validTenantID(tenantID string) bool {
+	if tenantID == "" || len(tenantID) > maxTenantIDLength {
+		return false
+	}
+	for _, r := range tenantID {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// ListTenants returns every organization
+func (s *TenantService) ListTenants(ctx context.Context) ([]*domain.Tenant, error) {
+	return s.repo.List(ctx)
+}
+
+// AssignMember moves a user into an organization with a role on an admin's
+// behalf, taking them out of any other
+func (s *TenantService) AssignMember(ctx context.Context, tenantID string, userID string, role domain.TenantRole) (*domain.User, error) {
+	if !slices.Contains(domain.TenantRoles, role) {
+		return nil, ErrInvalidTenantRole
+	}
+	if _, err := s.repo.Get(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SetTenant(ctx, userID, tenantID, role); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(ctx, userID)
+}
+
+// UnassignMember takes a user out of an organization on an admin's behalf
+func (s *TenantService) UnassignMember(ctx context.Context, tenantID string, userID string) error {
+	if _, err := s.member(ctx, tenantID, userID); err != nil {
+		return err
+	}
+	return s.userRepo.SetTenant(ctx, userID, "", "")
+}
+
+// ListTenantMembers returns the members of an organization on an admin's
+// behalf
+func (s *TenantService) ListTenantMembers(ctx context.Context, tenantID string) ([]*domain.User, error) {
+	if _, err := s.repo.Get(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	return s.userRepo.ListByTenant(ctx, tenantID)
+}
+
+// SaveSSO configures an organization's single sign-on on an admin's
+// behalf. The client secret can be left out to keep the one already saved.
+func (s *TenantService) SaveSSO(ctx context.Context, tenantID string, req SaveSSORequest) (*domain.SSOConfig, error) {
+	if _, err := s.repo.Get(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	if !validIssuer(req.Issuer) {
+		return nil, ErrInvalidSSOConfig
+	}
+	for _, role := range req.RoleMapping {
+		if !slices.Contains(domain.TenantRoles, role) {
+			return nil, ErrInvalidSSOConfig
+		}
+	}
+
+	now := time.Now()
+	config := &domain.SSOConfig{
+		TenantID:     tenantID,
+		Issuer:       req.Issuer,
+		ClientID:     req.ClientID,
+		ClientSecret: req.ClientSecret,
+		RoleClaim:    strings.TrimSpace(req.RoleClaim),
+		RoleMapping:  req.RoleMapping,
+		Enabled:      req.Enabled,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if config.ClientSecret == "" {
+		existing, err := s.repo.GetSSO(ctx, tenantID)
+		switch {
+		case errors.Is(err, domain.ErrSSONotConfigured):
+			return nil, ErrInvalidSSOConfig
+		case err != nil:
+			return nil, fmt.Errorf("failed to get single sign-on: %w", err)
+		}
+		config.ClientSecret = existing.ClientSecret
+	}
+	if err := s.repo.SaveSSO(ctx, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// validIssuer reports whether an issuer is an https URL, which ID tokens are
+// trusted over, or a local one for development
+func validIssuer(issuer string) bool {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return false
+	}
+	return u.Scheme == "https" || u.Scheme == "http" && (u.Hostname() == "localhost" || u.Hostname() == "127.0.0.1")
+}
+
+// GetSSO returns an organization's single sign-on configuration on an
+// admin's behalf
+func (s *TenantService) GetSSO(ctx context.Context, tenantID string) (*domain.SSOConfig, error) {
+	if _, err := s.repo.Get(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetSSO(ctx, tenantID)
+}
+
+// DeleteSSO removes an organization's single sign-on on an admin's behalf.
+// Members it created keep their accounts but can no longer sign in with it.
+func (s *TenantService) DeleteSSO(ctx context.Context, tenantID string) error {
+	if _, err := s.repo.Get(ctx, tenantID); err != nil {
+		return err
+	}
+	return s.repo.DeleteSSO(ctx, tenantID)
+}
+
+// SSOLogin returns how an organization's members sign in with its identity
+// provider, or ErrSSONotConfigured if they cannot
+func (s *TenantService) SSOLogin(ctx context.Context, tenantID string) (*domain.SSOLogin, error) {
+	tenant, err := s.repo.Get(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	config, err := s.repo.GetSSO(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !config.Enabled {
+		return nil, ErrSSONotConfigured
+	}
+	return &domain.SSOLogin{
+		TenantID:   tenant.ID,
+		TenantName: tenant.Name,
+		Issuer:     config.Issuer,
+		ClientID:   config.ClientID,
+	}, nil
+}
+
+// GetOwnTenant returns the organization a user belongs to
+func (s *TenantService) GetOwnTenant(ctx context.Context, userID string) (*domain.Tenant, error) {
+	user, err := s.tenantMember(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, user.TenantID)
+}
+
+// ListMembers returns the members of the organization a user administers
+func (s *TenantService) ListMembers(ctx context.Context, actorID string) ([]*domain.User, error) {
+	actor, err := s.tenantAdmin(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	return s.userRepo.ListByTenant(ctx, actor.TenantID)
+}
+
+// SetMemberRole changes the role of a member of the organization a user
+// administers. Admins cannot change their own role, so an organization is
+// never left without one by accident.
+func (s *TenantService) SetMemberRole(ctx context.Context, actorID string, userID string, role domain.TenantRole) (*domain.User, error) {
+	if !slices.Contains(domain.TenantRoles, role) {
+		return nil, ErrInvalidTenantRole
+	}
+	actor, err := s.tenantAdmin(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if userID == actor.ID {
+		return nil, ErrTenantSelf
+	}
+	if _, err := s.member(ctx, actor.TenantID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SetTenant(ctx, userID, actor.TenantID, role); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(ctx, userID)
+}
+
+// RemoveMember takes a member out of the organization a user administers.
+// Admins cannot remove themselves.
+func (s *TenantService) RemoveMember(ctx context.Context, actorID string, userID string) error {
+	actor, err := s.tenantAdmin(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if userID == actor.ID {
+		return ErrTenantSelf
+	}
+	if _, err := s.member(ctx, actor.TenantID, userID); err != nil {
+		return err
+	}
+	return s.userRepo.SetTenant(ctx, userID, "", "")
+}
+
+// Leaderboard ranks the members of a user's organization by games won, then
+// by points
+func (s *TenantService) Leaderboard(ctx context.Context, userID string, limit int) ([]*domain.LeaderboardEntry, error) {
+	user, err := s.tenantMember(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultLeaderboardLimit
+	}
+
+	members, err := s.userRepo.ListByTenant(ctx, user.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization members: %w", err)
+	}
+	slices.SortStableFunc(members, func(a, b *domain.User) int {
+		if a.Stats.GamesWon != b.Stats.GamesWon {
+			return b.Stats.GamesWon - a.Stats.GamesWon
+		}
+		return b.Stats.TotalPoints - a.Stats.TotalPoints
+	})
+
+	members = members[:min(len(members), limit, maxLeaderboardLimit)]
+	entries := make([]*domain.LeaderboardEntry, 0, len(members))
+	for i, member := range members {
+		position := i + 1
+		if i > 0 {
+			previous := members[i-1]
+			if previous.Stats.GamesWon == member.Stats.GamesWon && previous.Stats.TotalPoints == member.Stats.TotalPoints {
+				position = entries[i-1].Position
+			}
+		}
+		entries = append(entries, &domain.LeaderboardEntry{
+			Position:    position,
+			UserID:      member.ID,
+			DisplayName: member.DisplayName,
+			AvatarURL:   member.AvatarURL,
+			GamesPlayed: member.Stats.GamesPlayed,
+			GamesWon:    member.Stats.GamesWon,
+			TotalPoints: member.Stats.TotalPoints,
+		})
+	}
+	return entries, nil
+}
+
+// GameTenant returns the organization the games a host creates are private
+// to, if any. Players without an account belong to none. userID must be the
+// authenticated host, never an ID a client supplied.
+func (s *TenantService) GameTenant(ctx context.Context, userID string) (string, error) {
+	if userID == "" {
+		return "", nil
+	}
+	host, err := s.userRepo.GetByID(ctx, userID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get host: %w", err)
+	}
+	return host.TenantID, nil
+}
+
+// CheckJoin returns ErrTenantGame unless a game is open to everyone or the
+// joining player's account belongs to the organization it is private to.
+// userID must be the authenticated player, never an ID a client supplied.
+func (s *TenantService) CheckJoin(ctx context.Context, userID string, tenantID string) error {
+	if tenantID == "" {
+		return nil
+	}
+	if _, err := s.member(ctx, tenantID, userID); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return ErrTenantGame
+		}
+		return err
+	}
+	return nil
+}
+
+// tenantMember returns a user who belongs to an organization, or
+// ErrNotTenantMember if they belong to none
+func (s *TenantService) tenantMember(ctx context.Context, userID string) (*domain.User, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TenantID == "" {
+		return nil, ErrNotTenantMember
+	}
+	return user, nil
+}
+
+// tenantAdmin returns a user who administers an organization, or
+// ErrNotTenantAdmin if they do not
+func (s *TenantService) tenantAdmin(ctx context.Context, userID string) (*domain.User, error) {
+	user, err := s.tenantMember(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsTenantAdmin() {
+		return nil, ErrNotTenantAdmin
+	}
+	return user, nil
+}
+
+// member returns a member of an organization. Users outside it are not
+// found, so their accounts are not revealed.
+func (s *TenantService) member(ctx context.Context, tenantID string, userID string) (*domain.User, error) {
+	if userID == "" {
+		return nil, domain.ErrUserNotFound
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TenantID != tenantID {
+		return nil, domain.ErrUserNotFound
+	}
+	return user, nil
+}
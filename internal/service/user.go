@@ -4,27 +4,120 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/text/language"
 
 	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/id"
+	"github.com/zizouhuweidi/dahaa/internal/mail"
+	"github.com/zizouhuweidi/dahaa/internal/pagination"
+	"github.com/zizouhuweidi/dahaa/internal/validation"
+)
+
+const (
+	// inviteExpiry is how long an invitation can be answered
+	inviteExpiry = 24 * time.Hour
+
+	// inviteRetention is how long invitations are kept after they expire
+	inviteRetention = 7 * 24 * time.Hour
+
+	// invitePurgeBatchSize is the number of invitations deleted per statement
+	invitePurgeBatchSize = 500
+
+	// inviteCleanupInterval is how often the invite cleanup job runs
+	inviteCleanupInterval = time.Hour
+
+	// userRetention is how long deleted users are kept, anonymized, before
+	// they are purged
+	userRetention = 30 * 24 * time.Hour
+
+	// userPurgeBatchSize is the number of deleted users purged per transaction
+	userPurgeBatchSize = 100
+
+	// userPurgeInterval is how often the user purge job runs
+	userPurgeInterval = time.Hour
+
+	// maxRegionLength bounds the region a user can prefer games hosted in
+	maxRegionLength = 64
+
+	// userChannelPrefix prefixes the hub channel a user's notifications are sent to
+	userChannelPrefix = "user:"
 )
 
 // UserService handles user-related operations
 type UserService struct {
 	userRepo   domain.UserRepository
 	inviteRepo domain.GameInviteRepository
+	hub        domain.GameBroadcaster
+	bans       *BanService
+	sessions   domain.UserSessionStore
+	guests     domain.GuestStore
+	hallOfFame domain.HallOfFameRepository
+
+	// verifications and mailer send and check the links users verify their
+	// email address with
+	verifications domain.VerificationStore
+	mailer        mail.Sender
+	verifyURL     string
+
+	// oauthProviders are the providers users can sign in with, by name
+	oauthProviders map[string]domain.OAuthProvider
+
+	// tenants and ssoProvider sign organizations' members in with their
+	// organization's identity provider
+	tenants     domain.TenantRepository
+	ssoProvider func(*domain.SSOConfig) domain.OAuthProvider
 }
 
-// NewUserService creates a new user service
-func NewUserService(userRepo domain.UserRepository, inviteRepo domain.GameInviteRepository) *UserService {
-	return &UserService{
+// UserServiceOption configures optional UserService dependencies
+type UserServiceOption func(*UserService)
+
+// WithLoginBans keeps suspended and banned users from logging in
+func WithLoginBans(bans *BanService) UserServiceOption {
+	return func(s *UserService) {
+		s.bans = bans
+	}
+}
+
+// WithSessions sets where the sessions users sign in with are kept. Users
+// cannot log in without it.
+func WithSessions(sessions domain.UserSessionStore) UserServiceOption {
+	return func(s *UserService) {
+		s.sessions = sessions
+	}
+}
+
+// WithGuests sets where guest identities are kept. Guests cannot be minted
+// without it.
+func WithGuests(guests domain.GuestStore) UserServiceOption {
+	return func(s *UserService) {
+		s.guests = guests
+	}
+}
+
+// NewUserService creates a new user service. Invitations are pushed to their
+// recipients over the hub.
+func NewUserService(userRepo domain.UserRepository, inviteRepo domain.GameInviteRepository, hub domain.GameBroadcaster, opts ...UserServiceOption) *UserService {
+	s := &UserService{
 		userRepo:   userRepo,
 		inviteRepo: inviteRepo,
+		hub:        hub,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
+}
+
+// UserChannel returns the hub channel a user's notifications are broadcast to
+func UserChannel(userID string) string {
+	return userChannelPrefix + userID
 }
 
 // RegisterRequest represents a user registration request
@@ -37,6 +130,25 @@ type RegisterRequest struct {
 
 // Register registers a new user
 func (s *UserService) Register(ctx context.Context, req RegisterRequest) (*domain.User, error) {
+	return s.register(ctx, id.New(), req, domain.UserRoleUser)
+}
+
+// CreateAdmin registers a new user with administrative access
+func (s *UserService) CreateAdmin(ctx context.Context, req RegisterRequest) (*domain.User, error) {
+	return s.register(ctx, id.New(), req, domain.UserRoleAdmin)
+}
+
+// register creates a user with the given ID and role
+func (s *UserService) register(ctx context.Context, userID string, req RegisterRequest, role domain.UserRole) (*domain.User, error) {
+	// Apply the naming policy
+	if err := validation.CheckUsername(req.Username); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidUsername, err)
+	}
+	displayName, err := validation.CleanDisplayName(req.DisplayName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDisplayName, err)
+	}
+
 	// Check if username is taken
 	if _, err := s.userRepo.GetByUsername(ctx, req.Username); err == nil {
 		return nil, domain.ErrUserAlreadyExists
@@ -55,11 +167,13 @@ func (s *UserService) Register(ctx context.Context, req RegisterRequest) (*domai
 
 	// Create user
 	user := &domain.User{
-		ID:           generateID(),
+		ID:           userID,
 		Username:     req.Username,
 		Email:        req.Email,
 		PasswordHash: string(hashedPassword),
-		DisplayName:  req.DisplayName,
+		DisplayName:  displayName,
+		Role:         role,
+		Preferences:  domain.DefaultUserPreferences(),
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
@@ -68,6 +182,14 @@ func (s *UserService) Register(ctx context.Context, req RegisterRequest) (*domai
 		return nil, err
 	}
 
+	// The account works without a verified address, and the link can be
+	// sent again
+	if s.verifications != nil {
+		if err := s.sendVerification(ctx, user); err != nil {
+			fmt.Printf("Failed to send verification email to user %s: %v\n", user.ID, err)
+		}
+	}
+
 	return user, nil
 }
 
@@ -77,98 +199,440 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
-// Login authenticates a user and returns a session token
-func (s *UserService) Login(ctx context.Context, req LoginRequest) (string, error) {
+// Login authenticates a user and returns a session token along with the
+// user, whose preferences clients apply on signing in
+func (s *UserService) Login(ctx context.Context, req LoginRequest) (string, *domain.User, error) {
 	user, err := s.userRepo.GetByUsername(ctx, req.Username)
 	if err != nil {
 		if errors.Is(err, domain.ErrUserNotFound) {
-			return "", domain.ErrInvalidCredentials
+			return "", nil, domain.ErrInvalidCredentials
 		}
-		return "", err
+		return "", nil, err
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return "", domain.ErrInvalidCredentials
+		return "", nil, domain.ErrInvalidCredentials
 	}
 
-	// Generate session token
-	token, err := generateSessionToken()
+	// Keep out suspended and banned users, once they have proven who they are
+	if s.bans != nil {
+		if err := s.bans.Check(ctx, user.ID); err != nil {
+			return "", nil, err
+		}
+	}
+
+	token, err := s.startSession(ctx, user.ID)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	// Update last login time
 	user.LastLoginAt = time.Now()
 	if err := s.userRepo.Update(ctx, user); err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	return token, user, nil
+}
+
+// startSession issues a session token for a user
+func (s *UserService) startSession(ctx context.Context, userID string) (string, error) {
+	if s.sessions == nil {
+		return "", errors.New("user sessions are not configured")
 	}
 
+	token, err := generateSessionToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.sessions.StoreUserSession(ctx, token, userID); err != nil {
+		return "", err
+	}
 	return token, nil
 }
 
+// Authenticate returns the ID of the user a session token was issued to. It
+// returns ErrInvalidSession if the token is unknown or has expired.
+func (s *UserService) Authenticate(ctx context.Context, token string) (string, error) {
+	if token == "" || s.sessions == nil {
+		return "", ErrInvalidSession
+	}
+	return s.sessions.ResolveUserSession(ctx, token)
+}
+
+// Logout ends the session a token was issued for
+func (s *UserService) Logout(ctx context.Context, token string) error {
+	if s.sessions == nil {
+		return nil
+	}
+	return s.sessions.DeleteUserSession(ctx, token)
+}
+
+// UpdateProfileRequest represents a change to a user's profile
+type UpdateProfileRequest struct {
+	DisplayName string `json:"display_name" validate:"required,min=2,max=50"`
+}
+
+// UpdateProfile changes a user's display name after applying the naming
+// policy. Games the user is already in keep the name they joined with.
+func (s *UserService) UpdateProfile(ctx context.Context, userID string, req UpdateProfileRequest) (*domain.User, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	displayName, err := validation.CleanDisplayName(req.DisplayName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDisplayName, err)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.DisplayName = displayName
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetPreferences returns a user's preferences
+func (s *UserService) GetPreferences(ctx context.Context, userID string) (*domain.UserPreferences, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &user.Preferences, nil
+}
+
+// UpdatePreferences changes the preferences given in patch, a JSON object of
+// the fields to change, leaving the others as they are. Nested objects are
+// merged in the same way, and a null game_settings clears the user's default
+// game settings.
+func (s *UserService) UpdatePreferences(ctx context.Context, userID string, patch json.RawMessage) (*domain.UserPreferences, error) {
+	if userID == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := user.Preferences
+	if err := json.Unmarshal(patch, &preferences); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPreferences, err)
+	}
+	if err := validatePreferences(&preferences); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdatePreferences(ctx, userID, preferences); err != nil {
+		return nil, err
+	}
+	if s.hallOfFame != nil && preferences.HallOfFame != user.Preferences.HallOfFame {
+		if err := s.hallOfFame.SetConsent(ctx, userID, preferences.HallOfFame); err != nil {
+			return nil, err
+		}
+	}
+	return &preferences, nil
+}
+
+// validatePreferences checks a user's theme, language and region, and that
+// their default game settings could create a game
+func validatePreferences(preferences *domain.UserPreferences) error {
+	if !slices.Contains(domain.ColorThemes, preferences.Theme) {
+		return fmt.Errorf("%w: unknown theme %q", ErrInvalidPreferences, preferences.Theme)
+	}
+	if preferences.Language != "" {
+		if _, err := language.Parse(preferences.Language); err != nil {
+			return fmt.Errorf("%w: invalid language %q", ErrInvalidPreferences, preferences.Language)
+		}
+	}
+	if len(preferences.Region) > maxRegionLength {
+		return fmt.Errorf("%w: region is too long", ErrInvalidPreferences)
+	}
+	if preferences.GameSettings != nil {
+		return validateSettings(preferences.GameSettings)
+	}
+	return nil
+}
+
 // SendGameInvite sends a game invitation to another user
-func (s *UserService) SendGameInvite(ctx context.Context, gameID string, fromUserID string, toUserID string) error {
+func (s *UserService) SendGameInvite(ctx context.Context, gameID string, fromUserID string, toUserID string) (*domain.GameInvite, error) {
+	// Accept any casing of UUIDs as well as legacy IDs
+	toUserID, err := id.Parse(toUserID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
 	// Check if users exist
 	fromUser, err := s.userRepo.GetByID(ctx, fromUserID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err := s.checkVerified(fromUser); err != nil {
+		return nil, err
 	}
 
 	toUser, err := s.userRepo.GetByID(ctx, toUserID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Create invitation
 	invite := &domain.GameInvite{
-		ID:        generateID(),
+		ID:        id.New(),
 		GameID:    gameID,
 		FromUser:  fromUser.ID,
 		ToUser:    toUser.ID,
-		Status:    "pending",
+		Status:    domain.InviteStatusPending,
 		CreatedAt: time.Now(),
-		ExpiresAt: time.Now().Add(24 * time.Hour),
+		ExpiresAt: time.Now().Add(inviteExpiry),
+	}
+
+	if err := s.inviteRepo.Create(ctx, invite); err != nil {
+		return nil, err
 	}
 
-	return s.inviteRepo.Create(ctx, invite)
+	if toUser.Preferences.Notifications.GameInvites {
+		s.notifyInvite(invite.ToUser, "game_invite", invite)
+	}
+	return invite, nil
 }
 
-// AcceptGameInvite accepts a game invitation
-func (s *UserService) AcceptGameInvite(ctx context.Context, inviteID string) error {
-	invite, err := s.inviteRepo.GetByID(ctx, inviteID)
+// CancelGameInvite withdraws a pending invitation. Only the sender may cancel it.
+func (s *UserService) CancelGameInvite(ctx context.Context, inviteID string, callerID string) error {
+	invite, err := s.senderInvite(ctx, inviteID, callerID)
 	if err != nil {
 		return err
 	}
 
-	if invite.Status != "pending" {
-		return errors.New("invitation is no longer pending")
+	if invite.Status != domain.InviteStatusPending {
+		return ErrInviteNotPending
 	}
 
-	if time.Now().After(invite.ExpiresAt) {
-		return errors.New("invitation has expired")
+	if err := s.inviteRepo.Delete(ctx, inviteID); err != nil {
+		return err
+	}
+
+	s.notifyInvite(invite.ToUser, "game_invite_cancelled", invite)
+	return nil
+}
+
+// ResendGameInvite renews an unanswered invitation's expiry and notifies the
+// recipient again. Only the sender may resend it.
+func (s *UserService) ResendGameInvite(ctx context.Context, inviteID string, callerID string) (*domain.GameInvite, error) {
+	invite, err := s.senderInvite(ctx, inviteID, callerID)
+	if err != nil {
+		return nil, err
+	}
+	sender, err := s.userRepo.GetByID(ctx, callerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkVerified(sender); err != nil {
+		return nil, err
+	}
+
+	// Invites that lapsed without an answer can be revived
+	if invite.Status != domain.InviteStatusPending && invite.Status != domain.InviteStatusExpired {
+		return nil, ErrInviteNotPending
+	}
+
+	invite.Status = domain.InviteStatusPending
+	invite.ExpiresAt = time.Now().Add(inviteExpiry)
+	if err := s.inviteRepo.Renew(ctx, inviteID, invite.ExpiresAt); err != nil {
+		return nil, err
 	}
 
-	return s.inviteRepo.UpdateStatus(ctx, inviteID, "accepted")
+	if toUser, err := s.userRepo.GetByID(ctx, invite.ToUser); err == nil && toUser.Preferences.Notifications.GameInvites {
+		s.notifyInvite(invite.ToUser, "game_invite", invite)
+	}
+	return invite, nil
 }
 
-// DeclineGameInvite declines a game invitation
-func (s *UserService) DeclineGameInvite(ctx context.Context, inviteID string) error {
+// senderInvite retrieves an invitation on behalf of its sender
+func (s *UserService) senderInvite(ctx context.Context, inviteID string, callerID string) (*domain.GameInvite, error) {
 	invite, err := s.inviteRepo.GetByID(ctx, inviteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if callerID == "" || invite.FromUser != callerID {
+		return nil, ErrNotInviteSender
+	}
+
+	return invite, nil
+}
+
+// notifyInvite pushes an invitation event to a user
+func (s *UserService) notifyInvite(userID string, eventType string, invite *domain.GameInvite) {
+	payload, err := json.Marshal(invite)
+	if err != nil {
+		fmt.Printf("Failed to marshal invite: %v\n", err)
+		return
+	}
+	s.hub.BroadcastToGame(UserChannel(userID), eventType, payload)
+}
+
+// recipientInvite retrieves an invitation that its recipient can still answer
+func (s *UserService) recipientInvite(ctx context.Context, inviteID string, callerID string) (*domain.GameInvite, error) {
+	invite, err := s.inviteRepo.GetByID(ctx, inviteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if callerID == "" || invite.ToUser != callerID {
+		return nil, ErrNotInviteRecipient
+	}
+
+	if invite.Status != domain.InviteStatusPending {
+		return nil, ErrInviteNotPending
+	}
+
+	if time.Now().After(invite.ExpiresAt) {
+		return nil, ErrInviteExpired
+	}
+
+	return invite, nil
+}
+
+// DeclineGameInvite declines a game invitation. Only the recipient may
+// decline it, and only while it is pending.
+func (s *UserService) DeclineGameInvite(ctx context.Context, inviteID string, callerID string) error {
+	invite, err := s.recipientInvite(ctx, inviteID, callerID)
 	if err != nil {
 		return err
 	}
 
-	if invite.Status != "pending" {
-		return errors.New("invitation is no longer pending")
+	return s.inviteRepo.TransitionStatus(ctx, invite.ID, domain.InviteStatusPending, domain.InviteStatusDeclined)
+}
+
+// GetUserByID retrieves a user by their ID
+func (s *UserService) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	return s.userRepo.GetByID(ctx, id)
+}
+
+// GetUserByUsername retrieves a user by their username
+func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	return s.userRepo.GetByUsername(ctx, username)
+}
+
+// DeleteAccount deletes a user's account. The user is anonymized at once,
+// leaving their game history under "Deleted player", and purged after the
+// retention window.
+func (s *UserService) DeleteAccount(ctx context.Context, userID string) error {
+	if err := s.userRepo.Delete(ctx, userID); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return err
 	}
 
-	return s.inviteRepo.UpdateStatus(ctx, inviteID, "declined")
+	// The account is gone, so are its sessions
+	if s.sessions != nil {
+		if err := s.sessions.DeleteUserSessions(ctx, userID); err != nil {
+			fmt.Printf("Failed to end sessions of deleted user %s: %v\n", userID, err)
+		}
+	}
+	return nil
+}
+
+// PurgeDeletedUsers permanently removes users deleted more than the
+// retention window ago, returning how many were removed
+func (s *UserService) PurgeDeletedUsers(ctx context.Context) (int64, error) {
+	cutoff := time.Now().Add(-userRetention)
+
+	var purged int64
+	for {
+		n, err := s.userRepo.PurgeDeleted(ctx, cutoff, userPurgeBatchSize)
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge deleted users: %w", err)
+		}
+		purged += n
+		if n < userPurgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// StartUserPurgeJob starts a background job to purge deleted users once
+// their retention window has passed
+func (s *UserService) StartUserPurgeJob(ctx context.Context) {
+	ticker := time.NewTicker(userPurgeInterval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.PurgeDeletedUsers(ctx); err != nil {
+					fmt.Printf("Failed to purge deleted users: %v\n", err)
+				}
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// GetPendingInvites retrieves a page of a user's pending invitations
+func (s *UserService) GetPendingInvites(ctx context.Context, userID string, params *pagination.Params) (pagination.Page[*domain.GameInvite], error) {
+	return s.inviteRepo.GetPendingInvites(ctx, userID, params)
 }
 
-// GetPendingInvites retrieves all pending invitations for a user
-func (s *UserService) GetPendingInvites(ctx context.Context, userID string) ([]*domain.GameInvite, error) {
-	return s.inviteRepo.GetPendingInvites(ctx, userID)
+// ListUsers retrieves a page of users for administrators
+func (s *UserService) ListUsers(ctx context.Context, params *pagination.Params) (pagination.Page[*domain.User], error) {
+	return s.userRepo.List(ctx, params)
+}
+
+// CleanupExpiredInvites marks lapsed pending invitations as expired and
+// purges invitations that expired more than the retention window ago. It
+// returns the number of invitations expired and purged.
+func (s *UserService) CleanupExpiredInvites(ctx context.Context) (expired int64, purged int64, err error) {
+	now := time.Now()
+
+	expired, err = s.inviteRepo.MarkExpired(ctx, now)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to expire invites: %w", err)
+	}
+
+	// Purge in batches so a large backlog doesn't hold locks for long
+	cutoff := now.Add(-inviteRetention)
+	for {
+		n, err := s.inviteRepo.DeleteExpired(ctx, cutoff, invitePurgeBatchSize)
+		if err != nil {
+			return expired, purged, fmt.Errorf("failed to purge invites: %w", err)
+		}
+		purged += n
+		if n < invitePurgeBatchSize {
+			return expired, purged, nil
+		}
+	}
+}
+
+// StartInviteCleanupJob starts a background job to expire and purge old invitations
+func (s *UserService) StartInviteCleanupJob(ctx context.Context) {
+	ticker := time.NewTicker(inviteCleanupInterval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if _, _, err := s.CleanupExpiredInvites(ctx); err != nil {
+					fmt.Printf("Failed to cleanup invites: %v\n", err)
+				}
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			}
+		}
+	}()
 }
 
 // Helper functions
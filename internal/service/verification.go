@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/mail"
+)
+
+// Email verification errors
+var (
+	ErrInvalidVerificationToken = domain.ErrInvalidVerificationToken
+	ErrAlreadyVerified          = errors.New("email address is already verified")
+	ErrEmailNotVerified         = errors.New("verify your email address first")
+)
+
+// errVerificationNotConfigured is returned when verification emails are
+// requested without a store or sender
+var errVerificationNotConfigured = errors.New("email verification is not configured")
+
+// WithEmailVerification makes users verify their email address before they
+// can send game invitations. Verification links point to verifyURL with the
+// token in its token query parameter.
+func WithEmailVerification(store domain.VerificationStore, sender mail.Sender, verifyURL string) UserServiceOption {
+	return func(s *UserService) {
+		s.verifications = store
+		s.mailer = sender
+		s.verifyURL = verifyURL
+	}
+}
+
+// VerifyEmailRequest is a verification token from the link emailed to a user
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// VerifyEmail marks the address of the user a verification token was emailed
+// to as verified. Tokens work once.
+func (s *UserService) VerifyEmail(ctx context.Context, token string) (*domain.User, error) {
+	if s.verifications == nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	userID, err := s.verifications.ConsumeVerification(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+
+	if !user.Verified {
+		user.Verified = true
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// ResendVerification emails a user a new verification link. Links sent
+// before it keep working until they expire.
+func (s *UserService) ResendVerification(ctx context.Context, userID string) error {
+	if userID == "" {
+		return ErrUnauthenticated
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Verified {
+		return ErrAlreadyVerified
+	}
+	return s.sendVerification(ctx, user)
+}
+
+// sendVerification emails a user a link to verify their address with
+func (s *UserService) sendVerification(ctx context.Context, user *domain.User) error {
+	if s.verifications == nil || s.mailer == nil {
+		return errVerificationNotConfigured
+	}
+
+	token, err := generateSessionToken()
+	if err != nil {
+		return err
+	}
+	if err := s.verifications.StoreVerification(ctx, token, user.ID); err != nil {
+		return err
+	}
+
+	link, err := url.Parse(s.verifyURL)
+	if err != nil {
+		return fmt.Errorf("invalid verification URL: %w", err)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen this link to verify your email address:\n\n%s\n\nThe link works for %d hours. If you did not sign up, you can ignore this email.\n",
+			user.DisplayName, link, int(domain.VerificationExpiration.Hours())),
+	})
+}
+
+// checkVerified returns ErrEmailNotVerified if a user has yet to verify
+// their email address while verification is required
+func (s *UserService) checkVerified(user *domain.User) error {
+	if s.verifications != nil && !user.Verified {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis key prefixes of cached responses
+const (
+	responseKeyPrefix           = "resp:"
+	responseGenerationKeyPrefix = "respgen:"
+)
+
+// cachedResponse is the stored form of a cached response
+type cachedResponse struct {
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// ResponseCache caches rendered HTTP responses in Redis. Responses are
+// grouped under tags, and invalidating a tag moves it to a new generation,
+// so responses cached under the old one are never read again and expire on
+// their own.
+type ResponseCache struct {
+	redis *redis.Client
+}
+
+// NewResponseCache creates a new response cache
+func NewResponseCache(redis *redis.Client) *ResponseCache {
+	return &ResponseCache{redis: redis}
+}
+
+// Get returns a cached response's content type and body, reporting whether
+// one was found
+func (c *ResponseCache) Get(ctx context.Context, tag string, key string) (string, []byte, bool, error) {
+	generation, err := c.generation(ctx, tag)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	data, err := c.redis.Get(ctx, responseKey(tag, generation, key)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return "", nil, false, nil
+		}
+		return "", nil, false, fmt.Errorf("failed to get cached response: %w", err)
+	}
+
+	var response cachedResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", nil, false, fmt.Errorf("failed to unmarshal cached response: %w", err)
+	}
+	return response.ContentType, response.Body, true, nil
+}
+
+// Set caches a response for the given time
+func (c *ResponseCache) Set(ctx context.Context, tag string, key string, contentType string, body []byte, ttl time.Duration) error {
+	generation, err := c.generation(ctx, tag)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cachedResponse{ContentType: contentType, Body: body})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cached response: %w", err)
+	}
+	if err := c.redis.Set(ctx, responseKey(tag, generation, key), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache response: %w", err)
+	}
+	return nil
+}
+
+// Invalidate drops every response cached under a tag
+func (c *ResponseCache) Invalidate(ctx context.Context, tag string) error {
+	if err := c.redis.Incr(ctx, responseGenerationKeyPrefix+tag).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate cached responses: %w", err)
+	}
+	return nil
+}
+
+// generation returns a tag's current generation
+func (c *ResponseCache) generation(ctx context.Context, tag string) (int64, error) {
+	generation, err := c.redis.Get(ctx, responseGenerationKeyPrefix+tag).Int64()
+	if err != nil && err != redis.Nil {
+		return 0, fmt.Errorf("failed to get cache generation: %w", err)
+	}
+	return generation, nil
+}
+
+// responseKey returns the Redis key of a response cached under a tag's generation
+func responseKey(tag string, generation int64, key string) string {
+	return fmt.Sprintf("%s%s:%d:%s", responseKeyPrefix, tag, generation, key)
+}
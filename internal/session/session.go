@@ -1,9 +1,12 @@
 package session
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -14,13 +17,55 @@ const (
 	// Session expiration time
 	sessionExpiration = 24 * time.Hour
 
+	// Player session expiration time, refreshed whenever the token is used
+	playerSessionExpiration = 1 * time.Hour
+
+	// User session expiration time, refreshed whenever the token is used
+	userSessionExpiration = 30 * 24 * time.Hour
+
+	// How long a WebSocket client may take to reconnect with its resume
+	// token, counting from when its session was last stored
+	resumeSessionExpiration = 15 * time.Minute
+
+	// How long a player's record of the questions they were asked is kept.
+	// The record expires as a whole, counting from the first question in it.
+	askedQuestionsExpiration = 14 * 24 * time.Hour
+
+	// Number of game keys bulk operations scan, fetch and process at once
+	gameBatchSize = 500
+
 	// Redis key prefixes
-	gameKeyPrefix    = "game:"
-	playerKeyPrefix  = "player:"
-	connectionPrefix = "conn:"
-	rateLimitPrefix  = "ratelimit:"
+	gameKeyPrefix        = "game:"
+	roundsKeyPrefix      = "rounds:"
+	playerKeyPrefix      = "player:"
+	playerTokenKeyPrefix = "ptoken:"
+	overlayKeyPrefix     = "overlay:"
+	overlayTokenPrefix   = "otoken:"
+	resumeTokenPrefix    = "rtoken:"
+	connectionPrefix     = "conn:"
+	rateLimitPrefix      = "ratelimit:"
+	askedQuestionsPrefix = "asked:"
+	userSessionPrefix    = "usession:"
+	userSessionsPrefix   = "usessions:"
+	guestKeyPrefix       = "guest:"
+	verificationPrefix   = "verify:"
 )
 
+// gameHeader is the stored form of a game's state other than its rounds.
+// Rounds are stored apart, in a hash by index, so that storing a change only
+// rewrites the rounds it touched rather than every round played. Games stored
+// whole before rounds were split out decode with their rounds inline.
+type gameHeader struct {
+	domain.Game
+	RoundCount int `json:"round_count"`
+}
+
+// playerSession is the stored form of a player's session within a game
+type playerSession struct {
+	Player domain.Player `json:"player"`
+	Token  string        `json:"token"`
+}
+
 // Manager handles game sessions and player connections
 type Manager struct {
 	redis *redis.Client
@@ -31,21 +76,75 @@ func NewManager(redis *redis.Client) *Manager {
 	return &Manager{redis: redis}
 }
 
-// StoreGame stores a game in Redis
+// encodeBuffers holds buffers for encoding games, which are only needed
+// until they are written to Redis
+var encodeBuffers = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// StoreGame stores a game in Redis, replacing all of its stored rounds
 func (m *Manager) StoreGame(ctx context.Context, game *domain.Game) error {
-	key := gameKeyPrefix + game.ID
-	data, err := json.Marshal(game)
-	if err != nil {
+	return m.storeGame(ctx, game, 0, true)
+}
+
+// StoreGameRounds stores a game's header and its rounds from index from on,
+// leaving the earlier rounds already stored as they are
+func (m *Manager) StoreGameRounds(ctx context.Context, game *domain.Game, from int) error {
+	return m.storeGame(ctx, game, from, false)
+}
+
+// storeGame writes a game's header and its rounds from index from on in one
+// transaction, first removing the stored rounds if replace is set
+func (m *Manager) storeGame(ctx context.Context, game *domain.Game, from int, replace bool) error {
+	buf := encodeBuffers.Get().(*bytes.Buffer)
+	defer encodeBuffers.Put(buf)
+	buf.Reset()
+
+	header := gameHeader{Game: *game, RoundCount: len(game.Rounds)}
+	header.Rounds = nil
+	if err := json.NewEncoder(buf).Encode(&header); err != nil {
 		return fmt.Errorf("failed to marshal game: %w", err)
 	}
 
-	return m.redis.Set(ctx, key, data, sessionExpiration).Err()
+	roundsKey := roundsKeyPrefix + game.ID
+	pipe := m.redis.TxPipeline()
+	pipe.Set(ctx, gameKeyPrefix+game.ID, buf.Bytes(), sessionExpiration)
+	if replace {
+		pipe.Del(ctx, roundsKey)
+	}
+	if from < len(game.Rounds) {
+		fields := make([]any, 0, 2*(len(game.Rounds)-from))
+		for i := max(from, 0); i < len(game.Rounds); i++ {
+			data, err := json.Marshal(&game.Rounds[i])
+			if err != nil {
+				return fmt.Errorf("failed to marshal round: %w", err)
+			}
+			fields = append(fields, strconv.Itoa(i), data)
+		}
+		pipe.HSet(ctx, roundsKey, fields...)
+	}
+	if len(game.Rounds) > 0 {
+		pipe.Expire(ctx, roundsKey, sessionExpiration)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store game: %w", err)
+	}
+	return nil
 }
 
-// GetGame retrieves a game from Redis
+// GetGame retrieves a game from Redis, assembling its header and rounds. A
+// game missing any of its rounds is reported as not found, so that it is
+// read back whole from Postgres.
 func (m *Manager) GetGame(ctx context.Context, gameID string) (*domain.Game, error) {
-	key := gameKeyPrefix + gameID
-	data, err := m.redis.Get(ctx, key).Bytes()
+	pipe := m.redis.Pipeline()
+	headerCmd := pipe.Get(ctx, gameKeyPrefix+gameID)
+	roundsCmd := pipe.HGetAll(ctx, roundsKeyPrefix+gameID)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get game: %w", err)
+	}
+
+	data, err := headerCmd.Bytes()
 	if err != nil {
 		if err == redis.Nil {
 			return nil, domain.ErrGameNotFound
@@ -53,33 +152,92 @@ func (m *Manager) GetGame(ctx context.Context, gameID string) (*domain.Game, err
 		return nil, fmt.Errorf("failed to get game: %w", err)
 	}
 
-	var game domain.Game
-	if err := json.Unmarshal(data, &game); err != nil {
+	var header gameHeader
+	if err := json.Unmarshal(data, &header); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal game: %w", err)
 	}
+	if header.Rounds != nil {
+		return &header.Game, nil
+	}
 
-	return &game, nil
+	stored := roundsCmd.Val()
+	header.Rounds = make([]domain.Round, header.RoundCount)
+	for i := range header.Rounds {
+		data, ok := stored[strconv.Itoa(i)]
+		if !ok {
+			return nil, domain.ErrGameNotFound
+		}
+		if err := json.Unmarshal([]byte(data), &header.Rounds[i]); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal round: %w", err)
+		}
+	}
+
+	return &header.Game, nil
 }
 
-// DeleteGame deletes a game from Redis
+// GetGameHead retrieves a game's header and only its current round from
+// Redis, leaving its earlier rounds unread. A game missing its current round
+// is reported as not found, as with GetGame.
+func (m *Manager) GetGameHead(ctx context.Context, gameID string) (*domain.GameHead, error) {
+	data, err := m.redis.Get(ctx, gameKeyPrefix+gameID).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, domain.ErrGameNotFound
+		}
+		return nil, fmt.Errorf("failed to get game: %w", err)
+	}
+
+	var header gameHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal game: %w", err)
+	}
+	head := &domain.GameHead{Game: &header.Game, RoundCount: header.RoundCount}
+	if header.Rounds != nil {
+		// Stored whole, so the current round is the last inline one
+		head.RoundCount = len(header.Rounds)
+		header.Rounds = header.Rounds[max(len(header.Rounds)-1, 0):]
+		return head, nil
+	}
+	if header.RoundCount == 0 {
+		return head, nil
+	}
+
+	data, err = m.redis.HGet(ctx, roundsKeyPrefix+gameID, strconv.Itoa(header.RoundCount-1)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, domain.ErrGameNotFound
+		}
+		return nil, fmt.Errorf("failed to get current round: %w", err)
+	}
+	header.Rounds = make([]domain.Round, 1)
+	if err := json.Unmarshal(data, &header.Rounds[0]); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal round: %w", err)
+	}
+	return head, nil
+}
+
+// DeleteGame deletes a game and its rounds from Redis
 func (m *Manager) DeleteGame(ctx context.Context, gameID string) error {
-	key := gameKeyPrefix + gameID
-	if err := m.redis.Del(ctx, key).Err(); err != nil {
+	if err := m.redis.Del(ctx, gameKeyPrefix+gameID, roundsKeyPrefix+gameID).Err(); err != nil {
 		return fmt.Errorf("failed to delete game from Redis: %w", err)
 	}
 
 	return nil
 }
 
-// StorePlayerSession stores a player's session
-func (m *Manager) StorePlayerSession(ctx context.Context, gameID string, player *domain.Player) error {
+// StorePlayerSession stores a player's session along with the game token
+// that authenticates the player's actions
+func (m *Manager) StorePlayerSession(ctx context.Context, gameID string, player *domain.Player, token string) error {
 	key := playerKeyPrefix + gameID + ":" + player.ID
-	data, err := json.Marshal(player)
+	data, err := json.Marshal(playerSession{Player: *player, Token: token})
 	if err != nil {
 		return fmt.Errorf("failed to marshal player: %w", err)
 	}
 
-	if err := m.redis.Set(ctx, key, data, 1*time.Hour).Err(); err != nil {
+	pipe := m.redis.TxPipeline()
+	pipe.Set(ctx, key, data, playerSessionExpiration)
+	pipe.Set(ctx, playerTokenKeyPrefix+gameID+":"+token, player.ID, playerSessionExpiration)
+	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("failed to store player session: %w", err)
 	}
 
@@ -88,32 +246,252 @@ func (m *Manager) StorePlayerSession(ctx context.Context, gameID string, player
 
 // GetPlayerSession retrieves a player's session
 func (m *Manager) GetPlayerSession(ctx context.Context, gameID string, playerID string) (*domain.Player, error) {
-	key := playerKeyPrefix + gameID + ":" + playerID
-	data, err := m.redis.Get(ctx, key).Bytes()
+	session, err := m.getPlayerSession(ctx, gameID, playerID)
+	if err != nil {
+		return nil, err
+	}
+	return &session.Player, nil
+}
+
+// ResolvePlayerToken returns the ID of the player a game token was issued to
+// and extends the session's lifetime
+func (m *Manager) ResolvePlayerToken(ctx context.Context, gameID string, token string) (string, error) {
+	tokenKey := playerTokenKeyPrefix + gameID + ":" + token
+	playerID, err := m.redis.Get(ctx, tokenKey).Result()
 	if err != nil {
 		if err == redis.Nil {
-			return nil, domain.ErrPlayerNotFound
+			return "", domain.ErrInvalidPlayerToken
 		}
-		return nil, fmt.Errorf("failed to get player session: %w", err)
+		return "", fmt.Errorf("failed to resolve player token: %w", err)
 	}
 
-	var player domain.Player
-	if err := json.Unmarshal(data, &player); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal player: %w", err)
+	pipe := m.redis.Pipeline()
+	pipe.Expire(ctx, tokenKey, playerSessionExpiration)
+	pipe.Expire(ctx, playerKeyPrefix+gameID+":"+playerID, playerSessionExpiration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf("failed to refresh player session: %w", err)
 	}
 
-	return &player, nil
+	return playerID, nil
 }
 
-// DeletePlayerSession deletes a player's session
+// DeletePlayerSession deletes a player's session and revokes its token
 func (m *Manager) DeletePlayerSession(ctx context.Context, gameID string, playerID string) error {
 	key := playerKeyPrefix + gameID + ":" + playerID
-	if err := m.redis.Del(ctx, key).Err(); err != nil {
+	keys := []string{key}
+	if session, err := m.getPlayerSession(ctx, gameID, playerID); err == nil {
+		keys = append(keys, playerTokenKeyPrefix+gameID+":"+session.Token)
+	}
+
+	if err := m.redis.Del(ctx, keys...).Err(); err != nil {
 		return fmt.Errorf("failed to delete player session: %w", err)
 	}
 	return nil
 }
 
+// StoreOverlayToken stores the token that lets stream overlays follow a
+// game, revoking the token previously issued for it
+func (m *Manager) StoreOverlayToken(ctx context.Context, gameID string, token string) error {
+	key := overlayKeyPrefix + gameID
+	previous, err := m.redis.Get(ctx, key).Result()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to get overlay token: %w", err)
+	}
+
+	pipe := m.redis.TxPipeline()
+	if previous != "" {
+		pipe.Del(ctx, overlayTokenPrefix+previous)
+	}
+	pipe.Set(ctx, key, token, sessionExpiration)
+	pipe.Set(ctx, overlayTokenPrefix+token, gameID, sessionExpiration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store overlay token: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveOverlayToken returns the ID of the game an overlay token was issued for
+func (m *Manager) ResolveOverlayToken(ctx context.Context, token string) (string, error) {
+	gameID, err := m.redis.Get(ctx, overlayTokenPrefix+token).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", domain.ErrInvalidOverlayToken
+		}
+		return "", fmt.Errorf("failed to resolve overlay token: %w", err)
+	}
+	return gameID, nil
+}
+
+// StoreResumeSession stores what a WebSocket client is bound to under its
+// resume token, restarting the token's expiry
+func (m *Manager) StoreResumeSession(ctx context.Context, token string, session *domain.ResumeSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal resume session: %w", err)
+	}
+	if err := m.redis.Set(ctx, resumeTokenPrefix+token, data, resumeSessionExpiration).Err(); err != nil {
+		return fmt.Errorf("failed to store resume session: %w", err)
+	}
+	return nil
+}
+
+// ResolveResumeToken returns what the client holding a resume token was bound to
+func (m *Manager) ResolveResumeToken(ctx context.Context, token string) (*domain.ResumeSession, error) {
+	data, err := m.redis.Get(ctx, resumeTokenPrefix+token).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, domain.ErrInvalidResumeToken
+		}
+		return nil, fmt.Errorf("failed to resolve resume token: %w", err)
+	}
+
+	var session domain.ResumeSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal resume session: %w", err)
+	}
+	return &session, nil
+}
+
+// StoreUserSession stores the user a session token was issued to, and adds
+// the token to the user's sessions so they can all be ended at once
+func (m *Manager) StoreUserSession(ctx context.Context, token string, userID string) error {
+	pipe := m.redis.TxPipeline()
+	pipe.Set(ctx, userSessionPrefix+token, userID, userSessionExpiration)
+	pipe.SAdd(ctx, userSessionsPrefix+userID, token)
+	pipe.Expire(ctx, userSessionsPrefix+userID, userSessionExpiration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store user session: %w", err)
+	}
+	return nil
+}
+
+// ResolveUserSession returns the ID of the user a session token was issued
+// to, restarting its expiry
+func (m *Manager) ResolveUserSession(ctx context.Context, token string) (string, error) {
+	userID, err := m.redis.GetEx(ctx, userSessionPrefix+token, userSessionExpiration).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", domain.ErrInvalidSession
+		}
+		return "", fmt.Errorf("failed to resolve user session: %w", err)
+	}
+	// The user's sessions must be kept as long as their latest session
+	if err := m.redis.Expire(ctx, userSessionsPrefix+userID, userSessionExpiration).Err(); err != nil {
+		return "", fmt.Errorf("failed to refresh user sessions: %w", err)
+	}
+	return userID, nil
+}
+
+// DeleteUserSession ends a session
+func (m *Manager) DeleteUserSession(ctx context.Context, token string) error {
+	userID, err := m.redis.GetDel(ctx, userSessionPrefix+token).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil
+		}
+		return fmt.Errorf("failed to delete user session: %w", err)
+	}
+	if err := m.redis.SRem(ctx, userSessionsPrefix+userID, token).Err(); err != nil {
+		return fmt.Errorf("failed to delete user session: %w", err)
+	}
+	return nil
+}
+
+// DeleteUserSessions ends every session of a user
+func (m *Manager) DeleteUserSessions(ctx context.Context, userID string) error {
+	tokens, err := m.redis.SMembers(ctx, userSessionsPrefix+userID).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get user sessions: %w", err)
+	}
+
+	keys := []string{userSessionsPrefix + userID}
+	for _, token := range tokens {
+		keys = append(keys, userSessionPrefix+token)
+	}
+	if err := m.redis.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to delete user sessions: %w", err)
+	}
+	return nil
+}
+
+// StoreGuest stores a guest under its session token until it expires
+func (m *Manager) StoreGuest(ctx context.Context, token string, guest *domain.Guest) error {
+	data, err := json.Marshal(guest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal guest: %w", err)
+	}
+	if err := m.redis.Set(ctx, guestKeyPrefix+token, data, time.Until(guest.ExpiresAt)).Err(); err != nil {
+		return fmt.Errorf("failed to store guest: %w", err)
+	}
+	return nil
+}
+
+// ResolveGuest returns the guest a session token was minted for
+func (m *Manager) ResolveGuest(ctx context.Context, token string) (*domain.Guest, error) {
+	data, err := m.redis.Get(ctx, guestKeyPrefix+token).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, domain.ErrInvalidSession
+		}
+		return nil, fmt.Errorf("failed to resolve guest: %w", err)
+	}
+
+	var guest domain.Guest
+	if err := json.Unmarshal(data, &guest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal guest: %w", err)
+	}
+	return &guest, nil
+}
+
+// DeleteGuest ends a guest identity
+func (m *Manager) DeleteGuest(ctx context.Context, token string) error {
+	if err := m.redis.Del(ctx, guestKeyPrefix+token).Err(); err != nil {
+		return fmt.Errorf("failed to delete guest: %w", err)
+	}
+	return nil
+}
+
+// StoreVerification stores the user an email verification token was sent to
+func (m *Manager) StoreVerification(ctx context.Context, token string, userID string) error {
+	if err := m.redis.Set(ctx, verificationPrefix+token, userID, domain.VerificationExpiration).Err(); err != nil {
+		return fmt.Errorf("failed to store verification token: %w", err)
+	}
+	return nil
+}
+
+// ConsumeVerification returns the user an email verification token was sent
+// to, removing the token
+func (m *Manager) ConsumeVerification(ctx context.Context, token string) (string, error) {
+	userID, err := m.redis.GetDel(ctx, verificationPrefix+token).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", domain.ErrInvalidVerificationToken
+		}
+		return "", fmt.Errorf("failed to resolve verification token: %w", err)
+	}
+	return userID, nil
+}
+
+// getPlayerSession retrieves the stored session for a player
+func (m *Manager) getPlayerSession(ctx context.Context, gameID string, playerID string) (*playerSession, error) {
+	key := playerKeyPrefix + gameID + ":" + playerID
+	data, err := m.redis.Get(ctx, key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, domain.ErrPlayerNotFound
+		}
+		return nil, fmt.Errorf("failed to get player session: %w", err)
+	}
+
+	var session playerSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal player: %w", err)
+	}
+
+	return &session, nil
+}
+
 // StorePlayerConnection stores a player's WebSocket connection
 func (m *Manager) StorePlayerConnection(ctx context.Context, gameID, playerID string) error {
 	key := connectionPrefix + gameID + ":" + playerID
@@ -177,35 +555,26 @@ func (m *Manager) SubscribeToGame(ctx context.Context, gameID string) *redis.Pub
 	return m.redis.Subscribe(ctx, "game:"+gameID)
 }
 
-// CleanupInactiveGames removes games that haven't been active for a while
+// CleanupInactiveGames removes games that haven't been active for a while,
+// deleting each batch's inactive games at once
 func (m *Manager) CleanupInactiveGames(ctx context.Context) error {
-	pattern := gameKeyPrefix + "*"
-	iter := m.redis.Scan(ctx, 0, pattern, 0).Iterator()
-	for iter.Next(ctx) {
-		key := iter.Val()
-		data, err := m.redis.Get(ctx, key).Bytes()
-		if err != nil {
-			continue
+	return m.scanGames(ctx, func(headers []*gameHeader) error {
+		var keys []string
+		for _, header := range headers {
+			if time.Since(header.LastActivity) > 24*time.Hour {
+				keys = append(keys, gameKeyPrefix+header.ID, roundsKeyPrefix+header.ID)
+			}
 		}
-
-		var game domain.Game
-		if err := json.Unmarshal(data, &game); err != nil {
-			continue
+		if len(keys) == 0 {
+			return nil
 		}
 
-		if time.Since(game.LastActivity) > 24*time.Hour {
-			if err := m.DeleteGame(ctx, game.ID); err != nil {
-				// Log error but continue with other games
-				fmt.Printf("Failed to delete inactive game %s: %v\n", game.ID, err)
-			}
+		if err := m.redis.Del(ctx, keys...).Err(); err != nil {
+			// Log error but continue with other batches
+			fmt.Printf("Failed to delete %d inactive games: %v\n", len(keys)/2, err)
 		}
-	}
-
-	if err := iter.Err(); err != nil {
-		return fmt.Errorf("failed to scan games: %w", err)
-	}
-
-	return nil
+		return nil
+	})
 }
 
 // StartCleanupJob starts a background job to clean up inactive games
@@ -226,29 +595,124 @@ func (m *Manager) StartCleanupJob(ctx context.Context) {
 	}()
 }
 
-// GetAllGames retrieves all active game sessions
-func (m *Manager) GetAllGames(ctx context.Context) ([]*domain.Game, error) {
-	pattern := gameKeyPrefix + "*"
-	iter := m.redis.Scan(ctx, 0, pattern, 0).Iterator()
-	var games []*domain.Game
-	for iter.Next(ctx) {
-		key := iter.Val()
-		data, err := m.redis.Get(ctx, key).Bytes()
+// GetGameHeads retrieves every active game with only its current round,
+// fetching the rounds of each batch of games in one pipeline
+func (m *Manager) GetGameHeads(ctx context.Context) ([]*domain.GameHead, error) {
+	var heads []*domain.GameHead
+	err := m.scanGames(ctx, func(headers []*gameHeader) error {
+		pipe := m.redis.Pipeline()
+		current := make(map[*gameHeader]*redis.StringCmd)
+		for _, header := range headers {
+			if header.Rounds == nil && header.RoundCount > 0 {
+				current[header] = pipe.HGet(ctx, roundsKeyPrefix+header.ID, strconv.Itoa(header.RoundCount-1))
+			}
+		}
+		if len(current) > 0 {
+			if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+				return fmt.Errorf("failed to get current rounds: %w", err)
+			}
+		}
+
+		for _, header := range headers {
+			head := &domain.GameHead{Game: &header.Game, RoundCount: header.RoundCount}
+			switch cmd, ok := current[header]; {
+			case header.Rounds != nil:
+				// Stored whole, so the current round is the last inline one
+				head.RoundCount = len(header.Rounds)
+				header.Rounds = header.Rounds[max(len(header.Rounds)-1, 0):]
+			case ok:
+				var round domain.Round
+				if data, err := cmd.Bytes(); err == nil && json.Unmarshal(data, &round) == nil {
+					header.Rounds = []domain.Round{round}
+				}
+			}
+			heads = append(heads, head)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return heads, nil
+}
+
+// scanGames calls fn with the header of every stored game, a batch at a
+// time. Each batch's headers are fetched with a single MGET rather than a GET
+// per game. Games that expire while being scanned, or cannot be decoded, are
+// skipped.
+func (m *Manager) scanGames(ctx context.Context, fn func(headers []*gameHeader) error) error {
+	keys := make([]string, 0, gameBatchSize)
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+		values, err := m.redis.MGet(ctx, keys...).Result()
 		if err != nil {
-			continue
+			return fmt.Errorf("failed to get games: %w", err)
 		}
+		keys = keys[:0]
 
-		var game domain.Game
-		if err := json.Unmarshal(data, &game); err != nil {
-			continue
+		headers := make([]*gameHeader, 0, len(values))
+		for _, value := range values {
+			data, ok := value.(string)
+			if !ok {
+				continue
+			}
+
+			var header gameHeader
+			if err := json.Unmarshal([]byte(data), &header); err != nil {
+				continue
+			}
+			headers = append(headers, &header)
 		}
+		return fn(headers)
+	}
 
-		games = append(games, &game)
+	iter := m.redis.Scan(ctx, 0, gameKeyPrefix+"*", gameBatchSize).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == gameBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
 	}
 
 	if err := iter.Err(); err != nil {
-		return nil, fmt.Errorf("failed to scan games: %w", err)
+		return fmt.Errorf("failed to scan games: %w", err)
 	}
 
-	return games, nil
+	return flush()
+}
+
+// RecordAsked records that players were asked a question
+func (m *Manager) RecordAsked(ctx context.Context, playerIDs []string, questionID string) error {
+	pipe := m.redis.Pipeline()
+	for _, playerID := range playerIDs {
+		key := askedQuestionsPrefix + playerID
+		pipe.SAdd(ctx, key, questionID)
+		pipe.ExpireNX(ctx, key, askedQuestionsExpiration)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record asked question: %w", err)
+	}
+	return nil
+}
+
+// RecentlyAsked returns the IDs of the questions any of the players were
+// asked recently
+func (m *Manager) RecentlyAsked(ctx context.Context, playerIDs []string) ([]string, error) {
+	if len(playerIDs) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(playerIDs))
+	for i, playerID := range playerIDs {
+		keys[i] = askedQuestionsPrefix + playerID
+	}
+	questionIDs, err := m.redis.SUnion(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get asked questions: %w", err)
+	}
+	return questionIDs, nil
 }
@@ -0,0 +1,89 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+)
+
+// Action is a game operation performed by a script step
+type Action string
+
+const (
+	ActionJoin            Action = "join"
+	ActionStart           Action = "start"
+	ActionReady           Action = "ready"
+	ActionUnready         Action = "unready"
+	ActionCancelCountdown Action = "cancel_countdown"
+	ActionCountdown       Action = "countdown"    // Ticks start countdowns, starting games whose countdown ran out
+	ActionIntermission    Action = "intermission" // Ticks intermissions, starting the next round of games whose intermission ran out
+	ActionStartTurn       Action = "start_turn"
+	ActionSelectCategory  Action = "select_category"
+	ActionAnswer          Action = "answer"
+	ActionVote            Action = "vote"
+	ActionEndRound        Action = "end_round"
+	ActionEndGame         Action = "end_game"
+	ActionDisconnect      Action = "disconnect"
+	ActionReconnect       Action = "reconnect"
+	ActionCleanup         Action = "cleanup"
+	ActionRemoveAway      Action = "remove_away" // Removes players past the reconnect grace period
+	ActionWait            Action = "wait"        // Only advances the clock
+)
+
+// Script is a scripted game: the host creates it and the steps are replayed in order
+type Script struct {
+	Seed     int64                `json:"seed"`            // Seed for the service's random source
+	Start    time.Time            `json:"start,omitempty"` // Initial clock time, defaults to DefaultStart
+	Code     string               `json:"code,omitempty"`  // Join code, generated when empty
+	Settings *domain.GameSettings `json:"settings,omitempty"`
+	Players  []domain.Player      `json:"players"` // The first player hosts the game
+	Steps    []Step               `json:"steps"`
+}
+
+// Step is a single scripted action
+type Step struct {
+	After       Duration `json:"after,omitempty"` // Clock advance before the action
+	Action      Action   `json:"action"`
+	Player      string   `json:"player,omitempty"`       // ID of the acting player
+	Category    string   `json:"category,omitempty"`     // For select_category
+	Answer      string   `json:"answer,omitempty"`       // Answer text, or the text voted for
+	Force       bool     `json:"force,omitempty"`        // For start, whether to start before every player is ready
+	ExpectError string   `json:"expect_error,omitempty"` // The step must fail with an error containing this
+}
+
+// Duration is a time.Duration that is written in JSON as a string such as "30s"
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// LoadScript reads a JSON script from a file
+func LoadScript(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	var script Script
+	if err := json.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("failed to parse script: %w", err)
+	}
+	return &script, nil
+}
@@ -0,0 +1,189 @@
+// Package simulation replays scripted games against GameService with a
+// manual clock and a seeded random source, so that the same script always
+// produces the same game state.
+package simulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/clock"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/random"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+)
+
+// DefaultStart is the initial clock time of scripts that do not set one
+var DefaultStart = time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// ErrUnknownAction is returned for steps with an unsupported action
+var ErrUnknownAction = errors.New("unknown action")
+
+// Factory builds the GameService under simulation with the given options,
+// which supply the simulation's clock and random source
+type Factory func(opts ...service.GameServiceOption) *service.GameService
+
+// Result is the outcome of a simulation run
+type Result struct {
+	Game  *domain.Game `json:"game"` // Final game state
+	Steps []StepResult `json:"steps"`
+	OK    bool         `json:"ok"` // Whether every step met its expectation
+}
+
+// StepResult records what happened when a step was replayed
+type StepResult struct {
+	Index  int       `json:"index"`
+	Action Action    `json:"action"`
+	Player string    `json:"player,omitempty"`
+	Time   time.Time `json:"time"`
+	Error  string    `json:"error,omitempty"`
+	OK     bool      `json:"ok"` // Whether the outcome matched the step's expectation
+}
+
+// Driver replays scripts against services built by a factory
+type Driver struct {
+	newService Factory
+}
+
+// NewDriver creates a new simulation driver
+func NewDriver(newService Factory) *Driver {
+	return &Driver{newService: newService}
+}
+
+// Run creates the script's game and replays its steps. Step failures are
+// recorded in the result rather than aborting the run; an error is returned
+// only if the game cannot be set up.
+func (d *Driver) Run(ctx context.Context, script *Script) (*Result, error) {
+	if len(script.Players) == 0 {
+		return nil, errors.New("script needs at least one player")
+	}
+
+	start := script.Start
+	if start.IsZero() {
+		start = DefaultStart
+	}
+	clk := clock.NewManual(start)
+	svc := d.newService(service.WithClock(clk), service.WithRandom(random.New(script.Seed)))
+
+	game, _, err := svc.CreateGame(ctx, script.Code, script.Players[0], script.Settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create game: %w", err)
+	}
+
+	players := make(map[string]domain.Player, len(script.Players))
+	for _, p := range script.Players {
+		players[p.ID] = p
+	}
+
+	result := &Result{Steps: make([]StepResult, 0, len(script.Steps)), OK: true}
+	for i, step := range script.Steps {
+		clk.Advance(time.Duration(step.After))
+
+		err := d.apply(ctx, svc, game.ID, players, step)
+		stepResult := StepResult{
+			Index:  i,
+			Action: step.Action,
+			Player: step.Player,
+			Time:   clk.Now(),
+		}
+		if err != nil {
+			stepResult.Error = err.Error()
+		}
+		if step.ExpectError == "" {
+			stepResult.OK = err == nil
+		} else {
+			stepResult.OK = err != nil && strings.Contains(err.Error(), step.ExpectError)
+		}
+		result.OK = result.OK && stepResult.OK
+		result.Steps = append(result.Steps, stepResult)
+	}
+
+	// Ended games are removed from the session store, so fall back to the last
+	// known state if the game can no longer be read
+	if final, err := svc.GetGame(ctx, game.ID); err == nil {
+		game = final
+	}
+	result.Game = game
+
+	return result, nil
+}
+
+// apply performs a single step. Games are addressed by ID, which every
+// storage backend can resolve.
+func (d *Driver) apply(ctx context.Context, svc *service.GameService, gameID string, players map[string]domain.Player, step Step) error {
+	switch step.Action {
+	case ActionJoin:
+		player, ok := players[step.Player]
+		if !ok {
+			player = domain.Player{ID: step.Player, Name: step.Player}
+		}
+		_, err := svc.JoinGame(ctx, gameID, player)
+		return err
+	case ActionStart:
+		return svc.StartGame(ctx, gameID, step.Player, step.Force)
+	case ActionReady, ActionUnready:
+		return svc.SetReady(ctx, gameID, step.Player, step.Action == ActionReady)
+	case ActionCancelCountdown:
+		return svc.CancelCountdown(ctx, gameID, step.Player)
+	case ActionCountdown:
+		return svc.AdvanceCountdowns(ctx)
+	case ActionIntermission:
+		return svc.AdvanceIntermissions(ctx)
+	case ActionStartTurn:
+		return svc.StartTurn(ctx, gameID, step.Player)
+	case ActionSelectCategory:
+		return svc.SelectCategory(ctx, gameID, step.Player, step.Category)
+	case ActionAnswer:
+		return svc.SubmitAnswer(ctx, gameID, step.Player, step.Answer)
+	case ActionVote:
+		answerID, err := findAnswerID(ctx, svc, gameID, step.Answer)
+		if err != nil {
+			return err
+		}
+		return svc.SubmitVote(ctx, gameID, step.Player, answerID)
+	case ActionEndRound:
+		return svc.EndRound(ctx, gameID, step.Player)
+	case ActionEndGame:
+		return svc.EndGame(ctx, gameID, step.Player)
+	case ActionDisconnect:
+		return svc.HandlePlayerDisconnection(ctx, gameID, step.Player)
+	case ActionReconnect:
+		return svc.HandlePlayerReconnection(ctx, gameID, step.Player)
+	case ActionCleanup:
+		return svc.CleanupInactiveGames(ctx)
+	case ActionRemoveAway:
+		return svc.RemoveDisconnectedPlayers(ctx)
+	case ActionWait:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownAction, step.Action)
+	}
+}
+
+// findAnswerID resolves the text of an answer in the current round to its ID.
+// Scripts refer to answers by text because IDs depend on the random source.
+func findAnswerID(ctx context.Context, svc *service.GameService, gameID string, text string) (string, error) {
+	game, err := svc.GetGame(ctx, gameID)
+	if err != nil {
+		return "", err
+	}
+	if len(game.Rounds) == 0 {
+		return "", domain.ErrGameNotStarted
+	}
+
+	pool := game.Rounds[len(game.Rounds)-1].AnswerPool
+	if pool.CorrectAnswerID != "" && text == pool.CorrectAnswer {
+		return pool.CorrectAnswerID, nil
+	}
+	for _, answers := range [][]domain.Answer{pool.FakeAnswers, pool.FillerAnswers} {
+		for _, answer := range answers {
+			if answer.Text == text {
+				return answer.ID, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w: no answer %q", domain.ErrInvalidVote, text)
+}
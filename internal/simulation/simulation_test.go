@@ -0,0 +1,133 @@
+package simulation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/random"
+	"github.com/zizouhuweidi/dahaa/internal/repository/memory"
+	"github.com/zizouhuweidi/dahaa/internal/service"
+	"github.com/zizouhuweidi/dahaa/internal/websocket"
+)
+
+// memoryFactory returns a factory of game services backed by fresh
+// in-memory stores, with a released pack of questions in each category
+func memoryFactory(t *testing.T, categories ...string) Factory {
+	t.Helper()
+	ctx := context.Background()
+
+	var seeded []*domain.Question
+	for _, category := range categories {
+		for i := 0; i < 5; i++ {
+			seeded = append(seeded, &domain.Question{
+				Text:          category + " question",
+				Answer:        category + " answer",
+				Category:      category,
+				FillerAnswers: []string{"filler one", "filler two", "filler three"},
+			})
+		}
+	}
+	questions := memory.NewQuestionRepository(random.New(1))
+	if err := questions.SeedQuestions(ctx, seeded...); err != nil {
+		t.Fatal(err)
+	}
+
+	return func(opts ...service.GameServiceOption) *service.GameService {
+		games := memory.NewGameRepository()
+		events := memory.NewGameEventRepository()
+		changes := memory.NewGameChangeStore(games, events, memory.NewOutboxRepository(), memory.NewGameResultRepository(),
+			memory.NewCategoryStatsRepository(), memory.NewPartyRepository(), memory.NewHallOfFameRepository())
+		return service.NewGameService(games, events, changes, questions, websocket.NewHub(), memory.NewGameSessionStore(), opts...)
+	}
+}
+
+// fullGameScript plays a game of two rounds between three players, from its
+// lobby to its end
+func fullGameScript() *Script {
+	settings := domain.DefaultGameSettings()
+	settings.Rounds = 2
+	settings.SelectedCategories = []string{"history"}
+	return &Script{
+		Seed:     42,
+		Settings: settings,
+		Players: []domain.Player{
+			{ID: "alice", Name: "Alice"},
+			{ID: "bob", Name: "Bob"},
+			{ID: "carol", Name: "Carol"},
+		},
+		Steps: []Step{
+			{Action: ActionJoin, Player: "bob"},
+			{Action: ActionJoin, Player: "carol"},
+			{Action: ActionStart, Player: "bob", ExpectError: "host"},
+			{Action: ActionStart, Player: "alice", Force: true},
+			{Action: ActionStartTurn, Player: "alice"},
+			{After: Duration(5 * time.Second), Action: ActionSelectCategory, Player: "alice", Category: "history"},
+			{Action: ActionVote, Player: "bob", Answer: "history answer", ExpectError: "invalid vote"},
+			{Action: ActionAnswer, Player: "alice", Answer: "alice lie"},
+			{Action: ActionAnswer, Player: "bob", Answer: "bob lie"},
+			{Action: ActionAnswer, Player: "carol", Answer: "carol lie"},
+			{Action: ActionVote, Player: "alice", Answer: "history answer"},
+			{Action: ActionVote, Player: "bob", Answer: "alice lie"},
+			{Action: ActionVote, Player: "carol", Answer: "history answer"},
+			{After: Duration(10 * time.Second), Action: ActionIntermission},
+			{Action: ActionStartTurn, Player: "bob"},
+			{Action: ActionSelectCategory, Player: "alice", Category: "history", ExpectError: "another player"},
+			{Action: ActionSelectCategory, Player: "bob", Category: "history"},
+			{Action: ActionAnswer, Player: "alice", Answer: "alice lie"},
+			{Action: ActionAnswer, Player: "bob", Answer: "bob lie"},
+			{Action: ActionAnswer, Player: "carol", Answer: "carol lie"},
+			{Action: ActionVote, Player: "alice", Answer: "bob lie"},
+			{Action: ActionVote, Player: "bob", Answer: "history answer"},
+			{Action: ActionVote, Player: "carol", Answer: "history answer"},
+		},
+	}
+}
+
+func TestRunFullGame(t *testing.T) {
+	result, err := NewDriver(memoryFactory(t, "history")).Run(context.Background(), fullGameScript())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range result.Steps {
+		if !step.OK {
+			t.Errorf("step %d (%s by %q): error %q", step.Index, step.Action, step.Player, step.Error)
+		}
+	}
+	if !result.OK {
+		t.Fatal("the script did not run as expected")
+	}
+
+	game := result.Game
+	if state := game.State(); state != domain.GameStateFinished {
+		t.Errorf("game state = %s, want %s", state, domain.GameStateFinished)
+	}
+	if len(game.Rounds) != 2 {
+		t.Errorf("game played %d rounds, want 2", len(game.Rounds))
+	}
+	want := map[string]int{"alice": 3, "bob": 3, "carol": 4}
+	for _, player := range game.Players {
+		if player.Score != want[player.ID] {
+			t.Errorf("%s scored %d, want %d", player.ID, player.Score, want[player.ID])
+		}
+	}
+}
+
+func TestRunIsDeterministic(t *testing.T) {
+	var games [2][]byte
+	for i := range games {
+		result, err := NewDriver(memoryFactory(t, "history")).Run(context.Background(), fullGameScript())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if games[i], err = json.Marshal(result.Game); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(games[0], games[1]) {
+		t.Errorf("the same script produced different games:\n%s\n%s", games[0], games[1])
+	}
+}
@@ -0,0 +1,62 @@
+// Package stream reads the chat of live streams, so viewers watching a
+// streamer play can vote along by typing an option's number in chat.
+package stream
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrChatClosed is returned when a platform ends a chat connection
+	ErrChatClosed = errors.New("chat connection closed")
+
+	// ErrNotLive is returned when a stream has no live chat to read
+	ErrNotLive = errors.New("stream has no live chat")
+)
+
+// maxVoteNumber is the highest option number read as a vote
+const maxVoteNumber = 99
+
+// ChatMessage is a message a viewer sent to a stream's chat
+type ChatMessage struct {
+	Viewer string // Platform ID or login of the viewer, unique within the stream
+	Text   string
+}
+
+// ChatSource reads a stream's chat
+type ChatSource interface {
+	// Read delivers chat messages until the context is done or the
+	// connection fails, returning why it stopped
+	Read(ctx context.Context, messages chan<- ChatMessage) error
+}
+
+// ParseVote reads a vote from a chat message: an option number on its own,
+// optionally after "!vote" or "#", such as "2", "#2" or "!vote 2"
+func ParseVote(text string) (int, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 2 && strings.EqualFold(fields[0], "!vote") {
+		fields = fields[1:]
+	}
+	if len(fields) != 1 {
+		return 0, false
+	}
+
+	number, err := strconv.Atoi(strings.TrimPrefix(fields[0], "#"))
+	if err != nil || number < 1 || number > maxVoteNumber {
+		return 0, false
+	}
+	return number, true
+}
+
+// deliver passes a message on unless the context is done first
+func deliver(ctx context.Context, messages chan<- ChatMessage, msg ChatMessage) error {
+	select {
+	case messages <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
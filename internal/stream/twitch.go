@@ -0,0 +1,96 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"github.com/zizouhuweidi/dahaa/internal/random"
+)
+
+// twitchAddr is the address of Twitch's chat IRC server over TLS
+const twitchAddr = "irc.chat.twitch.tv:6697"
+
+// TwitchChat reads a Twitch channel's chat over IRC. It logs in anonymously,
+// which Twitch allows for reading chat, so streamers need not grant access.
+type TwitchChat struct {
+	channel string
+	addr    string
+}
+
+// NewTwitchChat creates a reader of a Twitch channel's chat
+func NewTwitchChat(channel string) *TwitchChat {
+	return &TwitchChat{
+		channel: strings.ToLower(strings.TrimPrefix(channel, "#")),
+		addr:    twitchAddr,
+	}
+}
+
+// Read joins the channel's chat and delivers its messages
+func (t *TwitchChat) Read(ctx context.Context, messages chan<- ChatMessage) error {
+	var dialer tls.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", t.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to twitch chat: %w", err)
+	}
+	defer conn.Close()
+
+	// Closing the connection unblocks the scanner once the context is done
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	// Anonymous logins are justinfan followed by any number
+	nick := fmt.Sprintf("justinfan%d", 10000+random.Global().Intn(90000))
+	if _, err := fmt.Fprintf(conn, "PASS SCHMOOPIIE\r\nNICK %s\r\nJOIN #%s\r\n", nick, t.channel); err != nil {
+		return fmt.Errorf("failed to join twitch chat: %w", err)
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if server, ok := strings.CutPrefix(line, "PING "); ok {
+			if _, err := fmt.Fprintf(conn, "PONG %s\r\n", server); err != nil {
+				return fmt.Errorf("failed to answer twitch ping: %w", err)
+			}
+			continue
+		}
+
+		msg, ok := parseTwitchMessage(line)
+		if !ok {
+			continue
+		}
+		if err := deliver(ctx, messages, msg); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read twitch chat: %w", err)
+	}
+	return ErrChatClosed
+}
+
+// parseTwitchMessage reads a chat message from an IRC line of the form
+// ":login!login@login.tmi.twitch.tv PRIVMSG #channel :text"
+func parseTwitchMessage(line string) (ChatMessage, bool) {
+	prefix, rest, ok := strings.Cut(line, " ")
+	if !ok || !strings.HasPrefix(prefix, ":") {
+		return ChatMessage{}, false
+	}
+	command, rest, ok := strings.Cut(rest, " ")
+	if !ok || command != "PRIVMSG" {
+		return ChatMessage{}, false
+	}
+	_, text, ok := strings.Cut(rest, " :")
+	if !ok {
+		return ChatMessage{}, false
+	}
+
+	login, _, _ := strings.Cut(strings.TrimPrefix(prefix, ":"), "!")
+	return ChatMessage{Viewer: login, Text: text}, true
+}
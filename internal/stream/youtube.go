@@ -0,0 +1,140 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// youtubeAPI is the base URL of the YouTube Data API
+	youtubeAPI = "https://www.googleapis.com/youtube/v3"
+
+	// minYouTubePoll bounds how often chat is polled when YouTube suggests
+	// polling sooner
+	minYouTubePoll = time.Second
+)
+
+// YouTubeChat reads a YouTube live stream's chat by polling the Data API,
+// which needs an API key
+type YouTubeChat struct {
+	apiKey  string
+	videoID string
+	baseURL string
+	client  *http.Client
+}
+
+// NewYouTubeChat creates a reader of the live chat of the stream with the
+// given video ID
+func NewYouTubeChat(apiKey string, videoID string) *YouTubeChat {
+	return &YouTubeChat{
+		apiKey:  apiKey,
+		videoID: videoID,
+		baseURL: youtubeAPI,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Read polls the stream's chat and delivers the messages sent after it
+// started reading
+func (y *YouTubeChat) Read(ctx context.Context, messages chan<- ChatMessage) error {
+	chatID, err := y.liveChatID(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pageToken string
+	backlog := true
+	for {
+		var page struct {
+			NextPageToken         string `json:"nextPageToken"`
+			PollingIntervalMillis int    `json:"pollingIntervalMillis"`
+			Items                 []struct {
+				Snippet struct {
+					DisplayMessage string `json:"displayMessage"`
+				} `json:"snippet"`
+				AuthorDetails struct {
+					ChannelID string `json:"channelId"`
+				} `json:"authorDetails"`
+			} `json:"items"`
+		}
+		params := url.Values{
+			"liveChatId": {chatID},
+			"part":       {"snippet,authorDetails"},
+		}
+		if pageToken != "" {
+			params.Set("pageToken", pageToken)
+		}
+		if err := y.get(ctx, "/liveChat/messages", params, &page); err != nil {
+			return err
+		}
+
+		// The first page is the chat from before reading started
+		if !backlog {
+			for _, item := range page.Items {
+				msg := ChatMessage{Viewer: item.AuthorDetails.ChannelID, Text: item.Snippet.DisplayMessage}
+				if err := deliver(ctx, messages, msg); err != nil {
+					return err
+				}
+			}
+		}
+		backlog = false
+		pageToken = page.NextPageToken
+
+		wait := max(time.Duration(page.PollingIntervalMillis)*time.Millisecond, minYouTubePoll)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// liveChatID looks up the ID of the stream's active live chat
+func (y *YouTubeChat) liveChatID(ctx context.Context) (string, error) {
+	var videos struct {
+		Items []struct {
+			LiveStreamingDetails struct {
+				ActiveLiveChatID string `json:"activeLiveChatId"`
+			} `json:"liveStreamingDetails"`
+		} `json:"items"`
+	}
+	if err := y.get(ctx, "/videos", url.Values{
+		"id":   {y.videoID},
+		"part": {"liveStreamingDetails"},
+	}, &videos); err != nil {
+		return "", err
+	}
+
+	if len(videos.Items) == 0 || videos.Items[0].LiveStreamingDetails.ActiveLiveChatID == "" {
+		return "", fmt.Errorf("%w: video %s", ErrNotLive, y.videoID)
+	}
+	return videos.Items[0].LiveStreamingDetails.ActiveLiveChatID, nil
+}
+
+// get calls an API endpoint and decodes its JSON response
+func (y *YouTubeChat) get(ctx context.Context, path string, params url.Values, v any) error {
+	params.Set("key", y.apiKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, y.baseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := y.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call youtube api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("youtube api returned %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode youtube api response: %w", err)
+	}
+	return nil
+}
@@ -3,35 +3,108 @@ package validation
 import (
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
-// NormalizeAnswer normalizes an answer for comparison
-func NormalizeAnswer(answer string) string {
+// Normalizer normalizes answers for comparison using the rules of one language
+type Normalizer struct {
+	// prefixes are stripped from the start of the answer, such as articles
+	prefixes []string
+
+	// wordPrefixes are stripped from the start of each word, such as the
+	// Arabic definite article, as long as minWordLength runes remain
+	wordPrefixes []string
+
+	// replacer folds letter variants that players use interchangeably
+	replacer *strings.Replacer
+
+	// ignore reports runes dropped before comparison, such as diacritics
+	ignore func(r rune) bool
+}
+
+// minWordLength is the shortest word left after stripping a word prefix
+const minWordLength = 2
+
+var normalizers = map[string]*Normalizer{
+	"en": {
+		prefixes: []string{"the ", "a ", "an "},
+	},
+	"ar": {
+		wordPrefixes: []string{"ال"},
+		replacer: strings.NewReplacer(
+			"أ", "ا", "إ", "ا", "آ", "ا", "ٱ", "ا",
+			"ة", "ه",
+			"ى", "ي",
+			"٠", "0", "١", "1", "٢", "2", "٣", "3", "٤", "4",
+			"٥", "5", "٦", "6", "٧", "7", "٨", "8", "٩", "9",
+		),
+		ignore: isArabicMark,
+	},
+}
+
+// ForLanguage returns the normalizer of a language, falling back to English
+// for languages without their own rules
+func ForLanguage(language string) *Normalizer {
+	if n, ok := normalizers[language]; ok {
+		return n
+	}
+	// Region and script subtags share their language's rules
+	if base, _, ok := strings.Cut(language, "-"); ok {
+		if n, ok := normalizers[strings.ToLower(base)]; ok {
+			return n
+		}
+	}
+	return normalizers["en"]
+}
+
+// isArabicMark reports whether a rune is an Arabic diacritic (tashkeel), a
+// combining hamza or madda, the superscript alef or the tatweel used to
+// stretch words
+func isArabicMark(r rune) bool {
+	return (r >= '\u064B' && r <= '\u0655') || r == '\u0670' || r == '\u0640'
+}
+
+// Normalize normalizes an answer for comparison
+func (n *Normalizer) Normalize(answer string) string {
 	// Convert to lowercase
 	answer = strings.ToLower(answer)
 
+	// Fold letter variants
+	if n.replacer != nil {
+		answer = n.replacer.Replace(answer)
+	}
+
 	// Remove common prefixes
-	prefixes := []string{"the ", "a ", "an "}
-	for _, prefix := range prefixes {
+	for _, prefix := range n.prefixes {
 		answer = strings.TrimPrefix(answer, prefix)
 	}
 
-	// Remove punctuation and extra spaces
+	// Remove punctuation and ignored marks
 	var result strings.Builder
 	for _, r := range answer {
-		if !unicode.IsPunct(r) {
-			result.WriteRune(r)
+		if unicode.IsPunct(r) || (n.ignore != nil && n.ignore(r)) {
+			continue
 		}
+		result.WriteRune(r)
 	}
 
-	// Trim spaces and normalize internal spaces
-	return strings.Join(strings.Fields(result.String()), " ")
+	// Trim spaces, normalize internal spaces and strip word prefixes
+	words := strings.Fields(result.String())
+	for i, word := range words {
+		for _, prefix := range n.wordPrefixes {
+			if rest, ok := strings.CutPrefix(word, prefix); ok && utf8.RuneCountInString(rest) >= minWordLength {
+				words[i] = rest
+				break
+			}
+		}
+	}
+	return strings.Join(words, " ")
 }
 
-// IsSimilarAnswer checks if two answers are similar enough to be considered the same
-func IsSimilarAnswer(answer1, answer2 string) bool {
-	normalized1 := NormalizeAnswer(answer1)
-	normalized2 := NormalizeAnswer(answer2)
+// IsSimilar checks if two answers are similar enough to be considered the same
+func (n *Normalizer) IsSimilar(answer1, answer2 string) bool {
+	normalized1 := n.Normalize(answer1)
+	normalized2 := n.Normalize(answer2)
 
 	// Exact match after normalization
 	if normalized1 == normalized2 {
@@ -44,15 +117,27 @@ func IsSimilarAnswer(answer1, answer2 string) bool {
 	}
 
 	// Calculate Levenshtein distance for close matches
-	distance := levenshteinDistance(normalized1, normalized2)
-	maxLen := max(len(normalized1), len(normalized2))
+	runes1, runes2 := []rune(normalized1), []rune(normalized2)
+	distance := levenshteinDistance(runes1, runes2)
+	maxLen := max(len(runes1), len(runes2))
 
 	// If the distance is less than 20% of the longer string length, consider it similar
 	return float64(distance)/float64(maxLen) < 0.2
 }
 
+// NormalizeAnswer normalizes an answer for comparison using English rules
+func NormalizeAnswer(answer string) string {
+	return normalizers["en"].Normalize(answer)
+}
+
+// IsSimilarAnswer checks if two answers are similar enough to be considered
+// the same using English rules
+func IsSimilarAnswer(answer1, answer2 string) bool {
+	return normalizers["en"].IsSimilar(answer1, answer2)
+}
+
 // levenshteinDistance calculates the Levenshtein distance between two strings
-func levenshteinDistance(s1, s2 string) int {
+func levenshteinDistance(s1, s2 []rune) int {
 	if len(s1) == 0 {
 		return len(s2)
 	}
@@ -0,0 +1,240 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Naming policy errors
+var (
+	ErrNameTooShort          = errors.New("name is too short")
+	ErrNameTooLong           = errors.New("name is too long")
+	ErrNameInvalidCharacters = errors.New("name contains characters that are not allowed")
+	ErrNameReserved          = errors.New("name is reserved")
+	ErrNameProfane           = errors.New("name contains offensive words")
+	ErrNameConfusable        = errors.New("name imitates a reserved name or mixes lookalike scripts")
+)
+
+// Name length limits, in runes
+const (
+	MinUsernameLength    = 3
+	MaxUsernameLength    = 32
+	MinDisplayNameLength = 2
+	MaxDisplayNameLength = 50
+)
+
+// maxCombiningMarks is the most combining marks allowed on one letter, enough
+// for Arabic shadda with a vowel but not for stacked "zalgo" text
+const maxCombiningMarks = 3
+
+// reservedNames cannot be used as a username or display name, nor as a word
+// of one, so that nobody can pass for staff or the system
+var reservedNames = []string{
+	"admin", "administrator", "root", "system", "moderator", "mod", "staff",
+	"support", "help", "security", "official", "dahaa", "api", "null",
+	"undefined", "everyone", "deleted player",
+	"مشرف", "المشرف", "الدعم", "دعم", "ادارة", "الادارة", "دهاء", "النظام",
+}
+
+// confusables fold letters and digits to the Latin letter they pass for, so
+// that "аdmin" with a Cyrillic a or "adm1n" is seen as "admin". I and l are
+// both folded to i as they look alike in many fonts.
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h',
+	'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'і': 'i',
+	'ї': 'i', 'ј': 'j', 'ѕ': 's', 'ԁ': 'd', 'һ': 'h', 'ӏ': 'i', 'ԛ': 'q',
+	'ԝ': 'w',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v',
+	'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'ζ': 'z', 'μ': 'u',
+	// Digits and symbols
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b',
+	'@': 'a', '$': 's', '!': 'i', '|': 'i',
+	// Latin
+	'l': 'i',
+}
+
+// multiConfusables fold letter sequences that pass for a single letter
+var multiConfusables = strings.NewReplacer("rn", "m", "vv", "w")
+
+// usernameSeparators may join the letters and digits of a username
+const usernameSeparators = "_.-"
+
+// displayNamePunctuation is allowed in display names besides letters,
+// combining marks, digits and spaces
+const displayNamePunctuation = "'’-._"
+
+// CheckUsername checks a username against the naming policy. Usernames are
+// ASCII letters and digits, optionally joined by single underscores, dots or
+// hyphens.
+func CheckUsername(username string) error {
+	length := utf8.RuneCountInString(username)
+	if length < MinUsernameLength {
+		return ErrNameTooShort
+	}
+	if length > MaxUsernameLength {
+		return ErrNameTooLong
+	}
+
+	prev := '_'
+	for _, r := range username {
+		switch {
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+		case strings.ContainsRune(usernameSeparators, r):
+			// Separators go between letters and digits, one at a time
+			if strings.ContainsRune(usernameSeparators, prev) {
+				return ErrNameInvalidCharacters
+			}
+		default:
+			return ErrNameInvalidCharacters
+		}
+		prev = r
+	}
+	if strings.ContainsRune(usernameSeparators, prev) {
+		return ErrNameInvalidCharacters
+	}
+
+	return checkWords(username, strings.FieldsFunc(username, func(r rune) bool {
+		return strings.ContainsRune(usernameSeparators, r)
+	}))
+}
+
+// CleanDisplayName checks a display name against the naming policy and
+// returns it in the form to store: NFC-normalized, with surrounding spaces
+// trimmed and runs of spaces collapsed
+func CleanDisplayName(name string) (string, error) {
+	name = strings.Join(strings.Fields(norm.NFC.String(name)), " ")
+
+	length := utf8.RuneCountInString(name)
+	if length < MinDisplayNameLength {
+		return "", ErrNameTooShort
+	}
+	if length > MaxDisplayNameLength {
+		return "", ErrNameTooLong
+	}
+
+	marks := 0
+	for _, r := range name {
+		switch {
+		case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r):
+			// Marks must sit on a letter, and not too many of them
+			marks++
+			if marks > maxCombiningMarks {
+				return "", ErrNameInvalidCharacters
+			}
+			continue
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == ' ', strings.ContainsRune(displayNamePunctuation, r):
+		default:
+			// Control and format characters, such as zero-width joiners
+			// and bidi overrides, are rejected here too
+			return "", ErrNameInvalidCharacters
+		}
+		marks = 0
+	}
+	if first, _ := utf8.DecodeRuneInString(name); !unicode.IsLetter(first) && !unicode.IsDigit(first) {
+		return "", ErrNameInvalidCharacters
+	}
+
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || strings.ContainsRune(displayNamePunctuation, r)
+	})
+	for _, word := range words {
+		if mixesScripts(word) {
+			return "", ErrNameConfusable
+		}
+	}
+
+	if err := checkWords(name, words); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// checkWords checks a name and its words against the reserved names and
+// profane words. A name whose words only match once folded for lookalike
+// characters is confusable with a reserved name.
+func checkWords(name string, words []string) error {
+	candidates := append([]string{name}, words...)
+	for _, candidate := range candidates {
+		folded := strings.ToLower(candidate)
+		lookalike := skeleton(candidate)
+		for _, reserved := range reservedNames {
+			if folded == reserved {
+				return ErrNameReserved
+			}
+			if lookalike == skeleton(reserved) {
+				return ErrNameConfusable
+			}
+		}
+		for _, word := range profaneWords {
+			if lookalike == skeleton(word) {
+				return ErrNameProfane
+			}
+		}
+	}
+	return nil
+}
+
+// skeleton reduces a name to the letters it looks like: compatibility
+// forms, case, lookalike letters, Arabic letter variants and marks are
+// folded and everything but letters is dropped, so names that look alike
+// share a skeleton
+func skeleton(name string) string {
+	name = strings.ToLower(norm.NFKC.String(name))
+	name = normalizers["ar"].replacer.Replace(name)
+
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		if folded, ok := confusables[r]; ok {
+			r = folded
+		}
+		if unicode.IsLetter(r) {
+			b.WriteRune(r)
+		}
+	}
+	return multiConfusables.Replace(b.String())
+}
+
+// scripts are the writing systems told apart when checking for mixed-script
+// words. Japanese mixes Han, Hiragana and Katakana, so they count as one.
+var scripts = [][]*unicode.RangeTable{
+	{unicode.Latin},
+	{unicode.Cyrillic},
+	{unicode.Greek},
+	{unicode.Arabic},
+	{unicode.Hebrew},
+	{unicode.Armenian},
+	{unicode.Georgian},
+	{unicode.Devanagari},
+	{unicode.Thai},
+	{unicode.Hangul},
+	{unicode.Han, unicode.Hiragana, unicode.Katakana},
+}
+
+// mixesScripts reports whether a word has letters from more than one script,
+// as in "Pаypal" spelled with a Cyrillic a
+func mixesScripts(word string) bool {
+	found := -1
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		script := len(scripts) // Any other script
+		for i, tables := range scripts {
+			if unicode.IsOneOf(tables, r) {
+				script = i
+				break
+			}
+		}
+		if found >= 0 && script != found {
+			return true
+		}
+		found = script
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"strings"
+	"unicode"
+)
+
+// profaneWords cannot appear as a word of a name or a fake answer. Words are
+// matched whole so that innocent words containing them are not caught, so
+// inflections are listed separately.
+var profaneWords = []string{
+	"fuck", "fucker", "fucking", "motherfucker", "shit", "bullshit", "bitch",
+	"cunt", "asshole", "bastard", "dickhead", "pussy", "whore",
+	"slut", "nigger", "nigga", "faggot", "fag", "retard", "rapist",
+	"كس", "كسمك", "زب", "شرموط", "شرموطة", "عاهر", "عاهرة", "منيوك", "خول", "قحبة", "متناك",
+}
+
+// ContainsProfanity reports whether any word of a text, folded for lookalike
+// characters like a name, is a profane word
+func ContainsProfanity(text string) bool {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		_, lookalike := confusables[r]
+		return !lookalike && !unicode.IsLetter(r) && !unicode.IsMark(r)
+	})
+	for _, word := range words {
+		// Symbols passing for letters also end sentences, as in "shit!"
+		trimmed := strings.TrimFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, profane := range profaneWords {
+			if target := skeleton(profane); skeleton(word) == target || skeleton(trimmed) == target {
+				return true
+			}
+		}
+	}
+	return false
+}
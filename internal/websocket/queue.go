@@ -0,0 +1,142 @@
+package websocket
+
+import "sync"
+
+// clientQueueSize bounds how many messages may wait for a client
+const clientQueueSize = 256
+
+// Priority ranks messages by how much clients need them, deciding which are
+// shed first when a client falls behind
+type Priority int
+
+const (
+	PriorityCosmetic Priority = iota // Chat, reactions and ticking timers, shed first
+	PriorityState                    // Game state and score updates
+	PriorityPhase                    // Phase changes, which clients cannot do without
+)
+
+// phaseTypes are the messages announcing that a game moved to another phase
+var phaseTypes = map[string]bool{
+	"game_created":         true,
+	"countdown_started":    true,
+	"countdown_canceled":   true,
+	"game_started":         true,
+	"round_started":        true,
+	"timer_started":        true,
+	"timer_ended":          true,
+	"round_ended":          true,
+	"intermission_started": true,
+	"phase_skipped":        true,
+	"timer_extended":       true,
+	"player_eliminated":    true,
+	"final_duel":           true,
+	"game_ended":           true,
+	"game_expired":         true,
+	"game_deleted":         true,
+}
+
+// cosmeticTypes are the messages a client misses nothing lasting without
+var cosmeticTypes = map[string]bool{
+	"timer_update":   true,
+	"countdown_tick": true,
+	"audience_voted": true,
+}
+
+// PriorityOf returns the priority of a type of message sent by the server
+func PriorityOf(messageType string) Priority {
+	switch {
+	case phaseTypes[messageType]:
+		return PriorityPhase
+	case cosmeticTypes[messageType]:
+		return PriorityCosmetic
+	default:
+		return PriorityState
+	}
+}
+
+// pushResult is the outcome of queueing a message to a client
+type pushResult int
+
+const (
+	pushQueued   pushResult = iota // The message was queued
+	pushShed                       // The message or a less important one was shed to make room
+	pushOverflow                   // The queue is full of messages at least as important
+)
+
+// queuedMessage is a message waiting for a client
+type queuedMessage struct {
+	priority Priority
+	data     []byte
+}
+
+// outboundQueue holds the messages waiting for a client, in the order they
+// were queued. Once full, the oldest of the least important messages makes
+// room for a more important one, and cosmetic messages are shed. Its zero
+// value is an empty queue.
+type outboundQueue struct {
+	mu       sync.Mutex
+	messages []queuedMessage
+	ready    chan struct{} // Signalled when messages are queued
+}
+
+// readyChan returns the channel signalled when messages are queued. The
+// caller must hold the lock.
+func (q *outboundQueue) readyChan() chan struct{} {
+	if q.ready == nil {
+		q.ready = make(chan struct{}, 1)
+	}
+	return q.ready
+}
+
+// push queues a message, shedding a less important one if the queue is full
+func (q *outboundQueue) push(priority Priority, data []byte) pushResult {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	result := pushQueued
+	if len(q.messages) >= clientQueueSize {
+		if priority == PriorityCosmetic {
+			return pushShed
+		}
+		victim := -1
+		for i, m := range q.messages {
+			if m.priority < priority && (victim < 0 || m.priority < q.messages[victim].priority) {
+				victim = i
+			}
+		}
+		if victim < 0 {
+			return pushOverflow
+		}
+		q.messages = append(q.messages[:victim], q.messages[victim+1:]...)
+		result = pushShed
+	}
+
+	q.messages = append(q.messages, queuedMessage{priority: priority, data: data})
+	select {
+	case q.readyChan() <- struct{}{}:
+	default:
+		// A signal is already pending
+	}
+	return result
+}
+
+// drain removes and returns the queued messages, oldest first
+func (q *outboundQueue) drain() [][]byte {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	data := make([][]byte, len(q.messages))
+	for i, m := range q.messages {
+		data[i] = m.data
+	}
+	q.messages = q.messages[:0]
+	return data
+}
+
+// wait returns a channel that receives once messages have been queued
+func (q *outboundQueue) wait() <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.readyChan()
+}
@@ -0,0 +1,115 @@
+package websocket
+
+import (
+	"fmt"
+	"testing"
+)
+
+// fill queues messages of a priority until the queue is full
+func fill(q *outboundQueue, priority Priority) {
+	for i := 0; i < clientQueueSize; i++ {
+		q.push(priority, []byte(fmt.Sprintf("%d-%d", priority, i)))
+	}
+}
+
+func TestOutboundQueueOrder(t *testing.T) {
+	var q outboundQueue
+	for i, priority := range []Priority{PriorityState, PriorityCosmetic, PriorityPhase, PriorityState} {
+		if result := q.push(priority, []byte{byte(i)}); result != pushQueued {
+			t.Errorf("push %d = %v, want queued", i, result)
+		}
+	}
+
+	select {
+	case <-q.wait():
+	default:
+		t.Fatal("queue not ready after pushes")
+	}
+	drained := q.drain()
+	if len(drained) != 4 {
+		t.Fatalf("drained %d messages, want 4", len(drained))
+	}
+	for i, data := range drained {
+		if data[0] != byte(i) {
+			t.Errorf("message %d = %v, want %d", i, data, i)
+		}
+	}
+	if drained := q.drain(); len(drained) != 0 {
+		t.Errorf("drained %d messages from an empty queue, want 0", len(drained))
+	}
+}
+
+func TestOutboundQueueFull(t *testing.T) {
+	tests := []struct {
+		name       string
+		queued     Priority
+		pushed     Priority
+		want       pushResult
+		wantQueued bool // Whether the new message was queued in place of the oldest queued one
+	}{
+		{"cosmetic into cosmetic", PriorityCosmetic, PriorityCosmetic, pushShed, false},
+		{"cosmetic into state", PriorityState, PriorityCosmetic, pushShed, false},
+		{"state into cosmetic", PriorityCosmetic, PriorityState, pushShed, true},
+		{"phase into state", PriorityState, PriorityPhase, pushShed, true},
+		{"state into state", PriorityState, PriorityState, pushOverflow, false},
+		{"phase into phase", PriorityPhase, PriorityPhase, pushOverflow, false},
+	}
+	for _, tt := range tests {
+		var q outboundQueue
+		fill(&q, tt.queued)
+
+		if result := q.push(tt.pushed, []byte("new")); result != tt.want {
+			t.Errorf("%s: push = %v, want %v", tt.name, result, tt.want)
+		}
+		drained := q.drain()
+		if len(drained) != clientQueueSize {
+			t.Errorf("%s: queue holds %d messages, want %d", tt.name, len(drained), clientQueueSize)
+			continue
+		}
+
+		first, last := string(drained[0]), string(drained[len(drained)-1])
+		if tt.wantQueued && (first != fmt.Sprintf("%d-1", tt.queued) || last != "new") {
+			t.Errorf("%s: queue runs %s to %s, want the oldest shed and the new message last", tt.name, first, last)
+		}
+		if !tt.wantQueued && (first != fmt.Sprintf("%d-0", tt.queued) || last == "new") {
+			t.Errorf("%s: queue runs %s to %s, want the new message dropped", tt.name, first, last)
+		}
+	}
+}
+
+func TestDeliverClosedClient(t *testing.T) {
+	s := newShard()
+	client := &Client{GameID: "game-1", done: make(chan struct{})}
+
+	// A client whose queue fills with messages too important to shed is closed
+	fill(&client.queue, PriorityPhase)
+	s.deliver(client, PriorityPhase, []byte("overflow"))
+	select {
+	case <-client.Done():
+	default:
+		t.Fatal("client left open after its queue overflowed")
+	}
+	if stats := s.stats(); stats.Dropped != 1 {
+		t.Errorf("dropped = %d, want 1", stats.Dropped)
+	}
+
+	// Nothing more is queued to a closed client
+	client.Drain()
+	s.deliver(client, PriorityPhase, []byte("after close"))
+	if drained := client.Drain(); len(drained) != 0 {
+		t.Errorf("queued %d messages to a closed client, want 0", len(drained))
+	}
+
+	// Closing the game closes its clients and removes them
+	other := &Client{GameID: "game-2", done: make(chan struct{})}
+	s.add(other)
+	s.closeGame("game-2")
+	select {
+	case <-other.Done():
+	default:
+		t.Fatal("client left open after its game closed")
+	}
+	if clients := s.clients("game-2"); len(clients) != 0 {
+		t.Errorf("closed game has %d clients, want 0", len(clients))
+	}
+}
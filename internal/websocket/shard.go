@@ -0,0 +1,209 @@
+package websocket
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// shard registers the clients of some of the hub's games and relays the
+// messages they send. Its rooms are changed under a lock but read without
+// one, so broadcasts never wait on clients coming and going.
+type shard struct {
+	// Rooms of the games with clients connected, by game ID
+	rooms sync.Map
+
+	// Inbound messages from the clients
+	broadcast chan inbound
+
+	// Register requests from the clients
+	register chan *Client
+
+	// Unregister requests from clients
+	unregister chan *Client
+
+	// Serializes changes to rooms and mutes
+	mu sync.Mutex
+
+	// Players whose inbound messages are dropped, by game ID
+	muted map[string]map[string]bool
+
+	// Counters for diagnostics
+	games   atomic.Int64
+	members atomic.Int64
+	sent    atomic.Int64
+	shed    atomic.Int64
+	dropped atomic.Int64
+}
+
+// room holds the clients connected to a game. Its client list is replaced
+// rather than changed, so a list once loaded can be read without locking.
+type room struct {
+	clients atomic.Pointer[[]*Client]
+}
+
+// newShard creates an empty shard
+func newShard() *shard {
+	return &shard{
+		broadcast:  make(chan inbound),
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		muted:      make(map[string]map[string]bool),
+	}
+}
+
+// run registers clients and relays their messages until the program exits
+func (s *shard) run() {
+	for {
+		select {
+		case client := <-s.register:
+			s.add(client)
+		case client := <-s.unregister:
+			s.remove(client)
+		case in := <-s.broadcast:
+			s.relay(in)
+		}
+	}
+}
+
+// clients returns the clients connected to a game, which must not be modified
+func (s *shard) clients(gameID string) []*Client {
+	r, ok := s.rooms.Load(gameID)
+	if !ok {
+		return nil
+	}
+	return *r.(*room).clients.Load()
+}
+
+// add adds a client to its game's room
+func (s *shard) add(client *Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, loaded := s.rooms.Load(client.GameID)
+	if !loaded {
+		r = &room{}
+		r.(*room).clients.Store(&[]*Client{})
+		s.rooms.Store(client.GameID, r)
+		s.games.Add(1)
+	}
+	clients := append(slices.Clone(*r.(*room).clients.Load()), client)
+	r.(*room).clients.Store(&clients)
+	s.members.Add(1)
+}
+
+// remove removes a client from its game's room and closes it, dropping the
+// room once it is empty. Clients no longer registered are ignored.
+func (s *shard) remove(client *Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.rooms.Load(client.GameID)
+	if !ok {
+		return
+	}
+	current := *r.(*room).clients.Load()
+	i := slices.Index(current, client)
+	if i < 0 {
+		return
+	}
+
+	if len(current) == 1 {
+		s.rooms.Delete(client.GameID)
+		s.games.Add(-1)
+	} else {
+		clients := slices.Delete(slices.Clone(current), i, i+1)
+		r.(*room).clients.Store(&clients)
+	}
+	s.members.Add(-1)
+	client.close()
+}
+
+// closeGame drops a game's room and closes its clients
+func (s *shard) closeGame(gameID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.muted, gameID)
+	r, ok := s.rooms.LoadAndDelete(gameID)
+	if !ok {
+		return
+	}
+	clients := *r.(*room).clients.Load()
+	for _, client := range clients {
+		client.close()
+	}
+	s.games.Add(-1)
+	s.members.Add(-int64(len(clients)))
+}
+
+// mute stops or resumes relaying a player's messages to their game
+func (s *shard) mute(gameID string, playerID string, muted bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !muted {
+		delete(s.muted[gameID], playerID)
+		if len(s.muted[gameID]) == 0 {
+			delete(s.muted, gameID)
+		}
+		return
+	}
+
+	if s.muted[gameID] == nil {
+		s.muted[gameID] = make(map[string]bool)
+	}
+	s.muted[gameID][playerID] = true
+}
+
+// relay passes a client's message on to the clients in its game. Displays
+// only show the game, and muted players' chat is dropped.
+func (s *shard) relay(in inbound) {
+	if in.client.Display {
+		return
+	}
+	if in.client.PlayerID != "" {
+		s.mu.Lock()
+		muted := s.muted[in.client.GameID][in.client.PlayerID]
+		s.mu.Unlock()
+		if muted {
+			return
+		}
+	}
+
+	for _, client := range s.clients(in.client.GameID) {
+		s.deliver(client, PriorityCosmetic, in.message)
+	}
+}
+
+// deliver queues a message to a client. Clients that fall so far behind
+// that their queue fills with messages too important to shed are closed.
+func (s *shard) deliver(client *Client, priority Priority, data []byte) {
+	select {
+	case <-client.done:
+		return // Closed clients are only waiting to be unregistered
+	default:
+	}
+
+	switch client.queue.push(priority, data) {
+	case pushQueued:
+		s.sent.Add(1)
+	case pushShed:
+		s.sent.Add(1)
+		s.shed.Add(1)
+	case pushOverflow:
+		s.dropped.Add(1)
+		client.close()
+	}
+}
+
+// stats returns a snapshot of the shard's counters
+func (s *shard) stats() ShardStats {
+	return ShardStats{
+		Games:   int(s.games.Load()),
+		Clients: int(s.members.Load()),
+		Sent:    s.sent.Load(),
+		Shed:    s.shed.Load(),
+		Dropped: s.dropped.Load(),
+	}
+}
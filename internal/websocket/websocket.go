@@ -2,13 +2,19 @@ package websocket
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"hash/fnv"
 	"log"
 	"net/http"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/zizouhuweidi/dahaa/internal/domain"
+	"github.com/zizouhuweidi/dahaa/internal/i18n"
 )
 
 const (
@@ -23,6 +29,9 @@ const (
 
 	// Maximum message size allowed from peer
 	maxMessageSize = 512
+
+	// Time allowed to run a command sent by the peer
+	commandTimeout = 10 * time.Second
 )
 
 var upgrader = websocket.Upgrader{
@@ -37,6 +46,39 @@ var upgrader = websocket.Upgrader{
 type Message struct {
 	Type    string          `json:"type"`
 	Payload json.RawMessage `json:"payload"`
+	Text    string          `json:"text,omitempty"` // Human-readable description of system events in the client's language
+}
+
+// EncodeMessage encodes a message, copying its payload in as it is where
+// json.Marshal would validate and compact it again. Game snapshots are
+// broadcast on every change, so this saves rescanning them for every
+// broadcast. The payload must be valid JSON.
+func EncodeMessage(message Message) ([]byte, error) {
+	messageType, err := json.Marshal(message.Type)
+	if err != nil {
+		return nil, err
+	}
+	payload := []byte(message.Payload)
+	if len(payload) == 0 {
+		payload = []byte("null")
+	}
+	var text []byte
+	if message.Text != "" {
+		if text, err = json.Marshal(message.Text); err != nil {
+			return nil, err
+		}
+	}
+
+	data := make([]byte, 0, len(`{"type":,"payload":,"text":}`)+len(messageType)+len(payload)+len(text))
+	data = append(data, `{"type":`...)
+	data = append(data, messageType...)
+	data = append(data, `,"payload":`...)
+	data = append(data, payload...)
+	if text != nil {
+		data = append(data, `,"text":`...)
+		data = append(data, text...)
+	}
+	return append(data, '}'), nil
 }
 
 // TimerMessage represents a timer update message
@@ -47,126 +89,275 @@ type TimerMessage struct {
 	Duration  int       `json:"duration"`
 }
 
+// CommandFunc runs a command a client sent, such as liking an answer, with
+// the message's payload. The error's text is sent back to the client.
+type CommandFunc func(ctx context.Context, client *Client, payload json.RawMessage) error
+
 // Client is a middleman between the websocket connection and the hub
 type Client struct {
-	Hub    *Hub
-	Conn   *websocket.Conn
-	GameID string
-	Send   chan []byte
+	Hub       *Hub
+	Conn      *websocket.Conn
+	GameID    string
+	PlayerID  string                 // Player the client authenticated as, who also receives their private messages
+	Display   bool                   // Whether the client is a shared screen, which receives the game's sanitized view instead of its state
+	Localizer *i18n.Localizer        // Language of event descriptions; nil sends none
+	Commands  map[string]CommandFunc // Commands the client may send by message type, run instead of being relayed
+
+	queue     outboundQueue // Messages waiting to be written
+	done      chan struct{} // Closed once the hub lets go of the client
+	closeOnce sync.Once
+	acked     atomic.Int64 // Version of the last game state the client acknowledged
+}
+
+// ackMessage is sent by clients to acknowledge the game states they applied,
+// so a client resuming its session is only resent what it missed
+type ackMessage struct {
+	Type    string `json:"type"`
+	Payload struct {
+		Version int `json:"version"`
+	} `json:"payload"`
+}
+
+// Queue queues a message to the client. If the client has fallen behind,
+// less important messages are shed to make room.
+func (c *Client) Queue(priority Priority, data []byte) {
+	c.queue.push(priority, data)
+}
+
+// Ready returns a channel that receives once messages are queued for the client
+func (c *Client) Ready() <-chan struct{} {
+	return c.queue.wait()
+}
+
+// Drain removes and returns the messages queued for the client, oldest first
+func (c *Client) Drain() [][]byte {
+	return c.queue.drain()
+}
+
+// Ack records that the client applied a game state. Acknowledgements of
+// older states than the last are ignored.
+func (c *Client) Ack(version int) {
+	for {
+		acked := c.acked.Load()
+		if int64(version) <= acked || c.acked.CompareAndSwap(acked, int64(version)) {
+			return
+		}
+	}
+}
+
+// LastAck returns the version of the last game state the client acknowledged
+func (c *Client) LastAck() int {
+	return int(c.acked.Load())
+}
+
+// Done returns a channel closed once the hub lets go of the client. The
+// client must be registered first.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
 }
 
-// Hub maintains the set of active clients and broadcasts messages
+// close tells the client's write pump to send what is queued and hang up
+func (c *Client) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// Hub maintains the set of active clients and broadcasts messages. Games are
+// spread across shards by a hash of their ID, and each shard registers its
+// games' clients and relays their messages on a goroutine of its own, so
+// busy games only hold up the games on their shard.
 type Hub struct {
-	// Registered clients
-	clients map[*Client]bool
+	shards []*shard
+}
+
+// HubOption configures a hub
+type HubOption func(*Hub)
 
-	// Inbound messages from the clients
-	broadcast chan []byte
+// WithShards sets the number of shards games are spread across, which
+// defaults to GOMAXPROCS
+func WithShards(n int) HubOption {
+	return func(h *Hub) {
+		if n > 0 {
+			h.shards = make([]*shard, n)
+		}
+	}
+}
 
-	// Register requests from the clients
-	register chan *Client
+// inbound is a message read from a client, such as chat or a reaction
+type inbound struct {
+	client  *Client
+	message []byte
+}
 
-	// Unregister requests from clients
-	unregister chan *Client
+// HubStats is a snapshot of the hub's counters
+type HubStats struct {
+	Clients int          `json:"clients"`
+	Sent    int64        `json:"sent"`    // Messages queued to clients
+	Shed    int64        `json:"shed"`    // Less important messages dropped to make room for others
+	Dropped int64        `json:"dropped"` // Messages dropped because a client's queue was full of important ones
+	Shards  []ShardStats `json:"shards"`
+}
 
-	// Mutex for thread-safe operations
-	mu sync.RWMutex
+// ShardStats is a snapshot of one shard's counters
+type ShardStats struct {
+	Games   int   `json:"games"` // Games with clients connected
+	Clients int   `json:"clients"`
+	Sent    int64 `json:"sent"`
+	Shed    int64 `json:"shed"`
+	Dropped int64 `json:"dropped"`
 }
 
 // NewHub creates a new hub instance
-func NewHub() *Hub {
-	return &Hub{
-		broadcast:  make(chan []byte),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		clients:    make(map[*Client]bool),
+func NewHub(opts ...HubOption) *Hub {
+	h := &Hub{shards: make([]*shard, runtime.GOMAXPROCS(0))}
+	for _, opt := range opts {
+		opt(h)
 	}
+	for i := range h.shards {
+		h.shards[i] = newShard()
+	}
+	return h
 }
 
-// Run starts the hub
+// Run starts the hub's shards and blocks
 func (h *Hub) Run() {
-	for {
-		select {
-		case client := <-h.register:
-			h.mu.Lock()
-			h.clients[client] = true
-			h.mu.Unlock()
-
-		case client := <-h.unregister:
-			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.Send)
-			}
-			h.mu.Unlock()
-
-		case message := <-h.broadcast:
-			h.mu.RLock()
-			for client := range h.clients {
-				select {
-				case client.Send <- message:
-				default:
-					close(client.Send)
-					delete(h.clients, client)
-				}
-			}
-			h.mu.RUnlock()
-		}
+	var wg sync.WaitGroup
+	for _, s := range h.shards {
+		wg.Add(1)
+		go func(s *shard) {
+			defer wg.Done()
+			s.run()
+		}(s)
 	}
+	wg.Wait()
+}
+
+// shardFor returns the shard a game's clients are registered with, hashing
+// its ID with FNV-1a
+func (h *Hub) shardFor(gameID string) *shard {
+	hash := fnv.New32a()
+	hash.Write([]byte(gameID))
+	return h.shards[hash.Sum32()%uint32(len(h.shards))]
 }
 
-// BroadcastToGame sends a message to all clients in a specific game
+// BroadcastToGame sends a message to all clients in a specific game. System
+// events are described in each client's language, encoding the message once
+// per language.
 func (h *Hub) BroadcastToGame(gameID string, messageType string, payload []byte) {
+	h.send(gameID, "", domain.AudienceEveryone, messageType, payload)
+}
+
+// BroadcastToAudience sends a message only to the players' or only to the
+// displays' clients in a game
+func (h *Hub) BroadcastToAudience(gameID string, audience domain.MessageAudience, messageType string, payload []byte) {
+	h.send(gameID, "", audience, messageType, payload)
+}
+
+// SendToPlayer sends a message only to the clients of one player in a game
+func (h *Hub) SendToPlayer(gameID string, playerID string, messageType string, payload []byte) {
+	h.send(gameID, playerID, domain.AudienceEveryone, messageType, payload)
+}
+
+// send delivers a message to the clients in a game that are in its audience,
+// or only to those of one player when playerID is set
+func (h *Hub) send(gameID string, playerID string, audience domain.MessageAudience, messageType string, payload []byte) {
+	s := h.shardFor(gameID)
+	clients := s.clients(gameID)
+	if len(clients) == 0 {
+		return
+	}
+
 	message := Message{
 		Type:    messageType,
 		Payload: payload,
 	}
 
-	messageBytes, err := json.Marshal(message)
+	messageBytes, err := EncodeMessage(message)
 	if err != nil {
 		log.Printf("Error marshaling message: %v", err)
 		return
 	}
-
-	h.mu.RLock()
-	for client := range h.clients {
-		if client.GameID == gameID {
-			select {
-			case client.Send <- messageBytes:
-			default:
-				close(client.Send)
-				delete(h.clients, client)
+	localized := make(map[string][]byte)
+	priority := PriorityOf(messageType)
+
+	for _, client := range clients {
+		if (playerID == "" || client.PlayerID == playerID) && client.receives(audience) {
+			data := messageBytes
+			if client.Localizer != nil {
+				lang := client.Localizer.Language()
+				if _, ok := localized[lang]; !ok {
+					localized[lang] = localizeMessage(message, client.Localizer, messageBytes)
+				}
+				data = localized[lang]
 			}
+			s.deliver(client, priority, data)
 		}
 	}
-	h.mu.RUnlock()
+}
+
+// receives reports whether a client is in a message's audience. Only clients
+// that authenticated as one of the game's players are players: anyone else
+// connecting to a game without a token is not.
+func (c *Client) receives(audience domain.MessageAudience) bool {
+	switch audience {
+	case domain.AudiencePlayers:
+		return c.PlayerID != ""
+	case domain.AudienceDisplays:
+		return c.Display
+	default:
+		return true
+	}
+}
+
+// localizeMessage encodes a message with a description of its event, if it
+// has one. Fields of an object payload are available to the description.
+func localizeMessage(message Message, localizer *i18n.Localizer, fallback []byte) []byte {
+	id := "event." + message.Type
+	if !localizer.Has(id) {
+		return fallback
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(message.Payload, &data); err != nil {
+		data = nil
+	}
+	message.Text = localizer.T(id, data)
+
+	messageBytes, err := EncodeMessage(message)
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return fallback
+	}
+	return messageBytes
 }
 
 // GetGameClients returns the number of connected clients for a game
 func (h *Hub) GetGameClients(gameID string) int {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	return len(h.shardFor(gameID).clients(gameID))
+}
 
-	count := 0
-	for client := range h.clients {
-		if client.GameID == gameID {
-			count++
-		}
+// Stats returns a snapshot of the hub's counters, in total and per shard
+func (h *Hub) Stats() HubStats {
+	stats := HubStats{Shards: make([]ShardStats, len(h.shards))}
+	for i, s := range h.shards {
+		shardStats := s.stats()
+		stats.Shards[i] = shardStats
+		stats.Clients += shardStats.Clients
+		stats.Sent += shardStats.Sent
+		stats.Shed += shardStats.Shed
+		stats.Dropped += shardStats.Dropped
 	}
-	return count
+	return stats
 }
 
 // CloseGame closes all connections for a game
 func (h *Hub) CloseGame(gameID string) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
+	h.shardFor(gameID).closeGame(gameID)
+}
 
-	for client := range h.clients {
-		if client.GameID == gameID {
-			close(client.Send)
-			delete(h.clients, client)
-		}
-	}
+// MutePlayer stops or resumes relaying a player's chat and reactions. Mutes
+// apply to all of the player's clients in the game, including later ones.
+func (h *Hub) MutePlayer(gameID string, playerID string, muted bool) {
+	h.shardFor(gameID).mute(gameID, playerID, muted)
 }
 
 // StartTimer starts a timer for a game
@@ -219,9 +410,16 @@ func (h *Hub) StartTimer(gameID string, timerType string, duration int) {
 	}()
 }
 
-// Register registers a new client with the hub
+// Register registers a new client with the hub, which must happen before
+// its pumps are started
 func (h *Hub) Register(client *Client) {
-	h.register <- client
+	client.done = make(chan struct{})
+	h.shardFor(client.GameID).register <- client
+}
+
+// Unregister removes a client from the hub and closes it
+func (h *Hub) Unregister(client *Client) {
+	h.shardFor(client.GameID).unregister <- client
 }
 
 // Handler handles WebSocket connections
@@ -253,11 +451,10 @@ func (h *Handler) ServeWS(w http.ResponseWriter, r *http.Request) {
 	client := &Client{
 		Hub:    h.hub,
 		Conn:   conn,
-		Send:   make(chan []byte, 256),
 		GameID: gameID,
 	}
 
-	client.Hub.register <- client
+	client.Hub.Register(client)
 
 	// Start goroutines for reading and writing
 	go client.WritePump()
@@ -267,7 +464,7 @@ func (h *Handler) ServeWS(w http.ResponseWriter, r *http.Request) {
 // ReadPump pumps messages from the WebSocket connection to the hub
 func (c *Client) ReadPump() {
 	defer func() {
-		c.Hub.unregister <- c
+		c.Hub.Unregister(c)
 		c.Conn.Close()
 	}()
 
@@ -288,8 +485,45 @@ func (c *Client) ReadPump() {
 		}
 
 		message = bytes.TrimSpace(bytes.ReplaceAll(message, newline, space))
-		c.Hub.broadcast <- message
+		var ack ackMessage
+		if json.Unmarshal(message, &ack) == nil && ack.Type == "ack" {
+			c.Ack(ack.Payload.Version)
+			continue
+		}
+		var command Message
+		if json.Unmarshal(message, &command) == nil {
+			if run, ok := c.Commands[command.Type]; ok {
+				c.runCommand(command, run)
+				continue
+			}
+		}
+		c.Hub.shardFor(c.GameID).broadcast <- inbound{client: c, message: message}
+	}
+}
+
+// runCommand runs a command the client sent, telling the client if it failed
+func (c *Client) runCommand(command Message, run CommandFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	err := run(ctx, c, command.Payload)
+	if err == nil {
+		return
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"command": command.Type,
+		"error":   err.Error(),
+	})
+	if err != nil {
+		log.Printf("Error marshaling command failure: %v", err)
+		return
 	}
+	data, err := EncodeMessage(Message{Type: "command_failed", Payload: payload})
+	if err != nil {
+		log.Printf("Error marshaling command failure: %v", err)
+		return
+	}
+	c.Queue(PriorityState, data)
 }
 
 // WritePump pumps messages from the hub to the WebSocket connection
@@ -302,30 +536,26 @@ func (c *Client) WritePump() {
 
 	for {
 		select {
-		case message, ok := <-c.Send:
-			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if !ok {
-				// The hub closed the channel
-				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
+		case <-c.Ready():
+			messages := c.Drain()
+			if len(messages) == 0 {
+				continue
 			}
-
-			w, err := c.Conn.NextWriter(websocket.TextMessage)
-			if err != nil {
+			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.write(messages); err != nil {
 				return
 			}
-			w.Write(message)
-
-			// Add queued messages to the current websocket message
-			n := len(c.Send)
-			for i := 0; i < n; i++ {
-				w.Write(newline)
-				w.Write(<-c.Send)
-			}
 
-			if err := w.Close(); err != nil {
-				return
+		case <-c.done:
+			// The hub let go of the client. Send what it queued before then.
+			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if messages := c.Drain(); len(messages) > 0 {
+				if err := c.write(messages); err != nil {
+					return
+				}
 			}
+			c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+			return
 
 		case <-ticker.C:
 			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
@@ -336,6 +566,22 @@ func (c *Client) WritePump() {
 	}
 }
 
+// write writes queued messages as one WebSocket message, one per line
+func (c *Client) write(messages [][]byte) error {
+	w, err := c.Conn.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return err
+	}
+	for i, message := range messages {
+		if i > 0 {
+			w.Write(newline)
+		}
+		w.Write(message)
+	}
+
+	return w.Close()
+}
+
 var (
 	newline = []byte{'\n'}
 	space   = []byte{' '}
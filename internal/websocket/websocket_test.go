@@ -0,0 +1,94 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// benchPayload generates a game snapshot sized like one of 8 players in its
+// tenth round
+func benchPayload() json.RawMessage {
+	rounds := make([]map[string]any, 10)
+	for r := range rounds {
+		answers := make([]map[string]any, 8)
+		for p := range answers {
+			answers[p] = map[string]any{
+				"id":        fmt.Sprintf("answer-%d-player-%d", r, p),
+				"player_id": fmt.Sprintf("player-%d", p),
+				"text":      fmt.Sprintf("Player %d's made-up answer", p),
+				"votes":     []string{fmt.Sprintf("player-%d", p)},
+			}
+		}
+		rounds[r] = map[string]any{
+			"number":   r + 1,
+			"question": strings.Repeat("What is the answer? ", 3),
+			"answers":  answers,
+		}
+	}
+	payload, err := json.Marshal(map[string]any{"code": "BENCH", "rounds": rounds})
+	if err != nil {
+		panic(err)
+	}
+	return payload
+}
+
+// BenchmarkMessageMarshal encodes a game_updated envelope with json.Marshal,
+// which validates and compacts the snapshot again
+func BenchmarkMessageMarshal(b *testing.B) {
+	message := Message{Type: "game_updated", Payload: benchPayload()}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(message); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEncodeMessage encodes a game_updated envelope with EncodeMessage,
+// copying the snapshot in as it is
+func BenchmarkEncodeMessage(b *testing.B) {
+	message := Message{Type: "game_updated", Payload: benchPayload()}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := EncodeMessage(message); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkHubShards relays chat across 64 games of 4 clients each from
+// parallel senders, with every game on one shard and spread across eight.
+// Each shard relays on a goroutine of its own, so one shard serializes every
+// game's chat.
+func BenchmarkHubShards(b *testing.B) {
+	for _, shards := range []int{1, 8} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			hub := NewHub(WithShards(shards))
+			go hub.Run()
+
+			var clients []*Client
+			for g := 0; g < 64; g++ {
+				for p := 0; p < 4; p++ {
+					client := &Client{GameID: fmt.Sprintf("game-%d", g), PlayerID: fmt.Sprintf("player-%d", p)}
+					hub.Register(client)
+					clients = append(clients, client)
+				}
+			}
+			message := []byte(`{"type":"chat","payload":{"text":"hello"}}`)
+
+			var next atomic.Int64
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					client := clients[next.Add(1)%int64(len(clients))]
+					hub.shardFor(client.GameID).broadcast <- inbound{client: client, message: message}
+					client.Drain()
+				}
+			})
+		})
+	}
+}
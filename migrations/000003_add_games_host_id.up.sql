@@ -0,0 +1,4 @@
+-- Track the host of each game for authorization
+ALTER TABLE games
+ADD COLUMN host_id VARCHAR(36) NOT NULL DEFAULT '';
+COMMENT ON COLUMN games.host_id IS 'ID of the player who created the game';
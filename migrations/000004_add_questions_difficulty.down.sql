@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_questions_difficulty;
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_difficulty_check;
+ALTER TABLE questions DROP COLUMN IF EXISTS difficulty;
@@ -0,0 +1,7 @@
+-- Add difficulty levels to questions
+ALTER TABLE questions
+ADD COLUMN difficulty VARCHAR(20) NOT NULL DEFAULT 'medium';
+ALTER TABLE questions
+ADD CONSTRAINT questions_difficulty_check CHECK (difficulty IN ('easy', 'medium', 'hard'));
+CREATE INDEX idx_questions_difficulty ON questions(difficulty);
+COMMENT ON COLUMN questions.difficulty IS 'Difficulty level: easy, medium or hard';
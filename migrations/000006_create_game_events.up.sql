@@ -0,0 +1,15 @@
+-- Append-only log of game events; game state is a projection of this stream
+CREATE TABLE game_events (
+    game_id UUID NOT NULL,
+    sequence INTEGER NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (game_id, sequence)
+);
+CREATE INDEX idx_game_events_type ON game_events(type);
+-- Track the last event applied to each game snapshot
+ALTER TABLE games
+ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
+COMMENT ON TABLE game_events IS 'Append-only stream of events for each game, kept after games are deleted for audit';
+COMMENT ON COLUMN games.version IS 'Sequence of the last event projected into this row';
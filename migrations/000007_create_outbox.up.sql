@@ -0,0 +1,9 @@
+-- Messages queued in the same transaction as game changes, relayed to clients once committed
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,
+    game_id UUID NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+COMMENT ON TABLE outbox IS 'Game broadcasts awaiting publication; rows are deleted once relayed';
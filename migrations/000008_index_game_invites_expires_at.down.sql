@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_game_invites_expires_at;
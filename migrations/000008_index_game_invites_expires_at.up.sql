@@ -0,0 +1,2 @@
+-- Supports the invite cleanup job, which expires and purges invites by expiry
+CREATE INDEX idx_game_invites_expires_at ON game_invites(expires_at);
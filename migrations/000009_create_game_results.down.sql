@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS game_players;
+DROP TABLE IF EXISTS game_results;
@@ -0,0 +1,28 @@
+-- Final outcome of each game, recorded when it ends
+CREATE TABLE game_results (
+    game_id UUID PRIMARY KEY,
+    code VARCHAR(10) NOT NULL,
+    player_count INTEGER NOT NULL,
+    rounds_played INTEGER NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Each player's performance in a finished game
+CREATE TABLE game_players (
+    game_id UUID NOT NULL REFERENCES game_results(game_id) ON DELETE CASCADE,
+    user_id VARCHAR(36) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    position INTEGER NOT NULL,
+    points INTEGER NOT NULL,
+    players_fooled INTEGER NOT NULL,
+    correct_votes INTEGER NOT NULL,
+    best_round INTEGER NOT NULL,
+    rounds JSONB NOT NULL DEFAULT '[]',
+    PRIMARY KEY (game_id, user_id)
+);
+
+CREATE INDEX idx_game_results_ended_at ON game_results(ended_at);
+CREATE INDEX idx_game_players_user_id ON game_players(user_id);
+
+COMMENT ON COLUMN game_players.user_id IS 'Player ID, which is the user ID for registered players';
+COMMENT ON COLUMN game_players.rounds IS 'Per-round performance as a JSON array';
@@ -0,0 +1,14 @@
+-- Per-user summaries of the games played each week and month
+CREATE TABLE stats_rollups (
+    user_id VARCHAR(36) NOT NULL,
+    period VARCHAR(10) NOT NULL CHECK (period IN ('week', 'month')),
+    period_start DATE NOT NULL,
+    games INTEGER NOT NULL,
+    wins INTEGER NOT NULL,
+    total_points INTEGER NOT NULL,
+    average_score DOUBLE PRECISION NOT NULL,
+    favorite_category VARCHAR(100) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, period, period_start)
+);
+COMMENT ON COLUMN stats_rollups.period_start IS 'First day of the period in UTC; weeks start on Monday';
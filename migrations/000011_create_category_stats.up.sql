@@ -0,0 +1,14 @@
+-- Per-user running totals for each question category, updated as rounds end
+CREATE TABLE category_stats (
+    user_id VARCHAR(36) NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    rounds INTEGER NOT NULL DEFAULT 0,
+    votes_cast INTEGER NOT NULL DEFAULT 0,
+    correct_votes INTEGER NOT NULL DEFAULT 0,
+    players_fooled INTEGER NOT NULL DEFAULT 0,
+    opponent_votes INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, category)
+);
+
+COMMENT ON COLUMN category_stats.opponent_votes IS 'Votes cast by other players in the same rounds, the denominator of the fool rate';
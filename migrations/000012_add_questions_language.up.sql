@@ -0,0 +1,9 @@
+-- Tag questions with the language of their content pack
+ALTER TABLE questions
+ADD COLUMN language VARCHAR(10) NOT NULL DEFAULT 'en';
+-- Questions written in Arabic script belong to the Arabic pack
+UPDATE questions
+SET language = 'ar'
+WHERE text ~ '[؀-ۿ]';
+CREATE INDEX idx_questions_language ON questions(language);
+COMMENT ON COLUMN questions.language IS 'BCP 47 language tag of the question content, such as en or ar';
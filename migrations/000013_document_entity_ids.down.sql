@@ -0,0 +1,3 @@
+COMMENT ON COLUMN games.id IS NULL;
+COMMENT ON COLUMN game_invites.id IS NULL;
+COMMENT ON COLUMN users.id IS NULL;
@@ -0,0 +1,5 @@
+-- Entity IDs are now UUIDv7 strings generated by the application. Existing
+-- users and invites keep their 8-character IDs, which still fit the columns.
+COMMENT ON COLUMN users.id IS 'UUIDv7, or an 8-character ID for users created before UUIDs';
+COMMENT ON COLUMN game_invites.id IS 'UUIDv7, or an 8-character ID for invites created before UUIDs';
+COMMENT ON COLUMN games.id IS 'UUIDv7 generated by the application; older games have database-assigned random UUIDs';
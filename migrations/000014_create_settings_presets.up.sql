@@ -0,0 +1,10 @@
+-- Game settings saved by users under a key, to create games from
+CREATE TABLE settings_presets (
+    user_id VARCHAR(36) NOT NULL,
+    key VARCHAR(32) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    settings JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
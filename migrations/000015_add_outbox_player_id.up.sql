@@ -0,0 +1,4 @@
+-- Private messages are relayed only to the clients of one player
+ALTER TABLE outbox ADD COLUMN player_id VARCHAR(36);
+
+COMMENT ON COLUMN outbox.player_id IS 'Recipient of a private message; NULL for messages to the whole game';
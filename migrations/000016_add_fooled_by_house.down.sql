@@ -0,0 +1,2 @@
+ALTER TABLE category_stats DROP COLUMN IF EXISTS fooled_by_house;
+ALTER TABLE game_players DROP COLUMN IF EXISTS fooled_by_house;
@@ -0,0 +1,6 @@
+-- Votes players gave to the filler answers the system adds to each round
+ALTER TABLE game_players ADD COLUMN fooled_by_house INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE category_stats ADD COLUMN fooled_by_house INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN game_players.fooled_by_house IS 'Votes the player gave to filler answers';
+COMMENT ON COLUMN category_stats.fooled_by_house IS 'Votes the player gave to filler answers';
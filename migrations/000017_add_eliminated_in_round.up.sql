@@ -0,0 +1,4 @@
+-- Elimination mode knocks players out between rounds
+ALTER TABLE game_players ADD COLUMN eliminated_in_round INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN game_players.eliminated_in_round IS 'Round after which the player was eliminated; 0 if they never were';
@@ -0,0 +1,3 @@
+ALTER TABLE game_results DROP COLUMN IF EXISTS party_id;
+DROP TABLE IF EXISTS party_standings;
+DROP TABLE IF EXISTS parties;
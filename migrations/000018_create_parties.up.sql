@@ -0,0 +1,27 @@
+-- Groups of players keeping a running scoreboard across a series of games
+CREATE TABLE parties (
+    id UUID PRIMARY KEY,
+    host_id VARCHAR(36) NOT NULL,
+    games INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Each player's record over a party's games
+CREATE TABLE party_standings (
+    party_id UUID NOT NULL REFERENCES parties(id) ON DELETE CASCADE,
+    player_id VARCHAR(36) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    games INTEGER NOT NULL,
+    wins INTEGER NOT NULL,
+    points INTEGER NOT NULL,
+    streak INTEGER NOT NULL,
+    best_streak INTEGER NOT NULL,
+    PRIMARY KEY (party_id, player_id)
+);
+
+ALTER TABLE game_results ADD COLUMN party_id UUID REFERENCES parties(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_game_results_party_id ON game_results(party_id);
+
+COMMENT ON COLUMN party_standings.streak IS 'Games won in a row, up to the party''s latest game';
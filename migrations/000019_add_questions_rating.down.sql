@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_questions_rating;
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_rating_check;
+ALTER TABLE questions DROP COLUMN IF EXISTS rating;
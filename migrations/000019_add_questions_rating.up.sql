@@ -0,0 +1,7 @@
+-- Rate questions by the audience their content is suitable for
+ALTER TABLE questions
+ADD COLUMN rating VARCHAR(10) NOT NULL DEFAULT 'family';
+ALTER TABLE questions
+ADD CONSTRAINT questions_rating_check CHECK (rating IN ('family', 'teen', 'adult'));
+CREATE INDEX idx_questions_rating ON questions(rating);
+COMMENT ON COLUMN questions.rating IS 'Content rating: family, teen or adult';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_questions_selection;
@@ -0,0 +1,3 @@
+-- Serve random question draws, which count a category's questions in a
+-- language and rating range and skip to a random offset
+CREATE INDEX idx_questions_selection ON questions(category, language, rating);
@@ -0,0 +1,4 @@
+-- Messages to a whole game may be meant only for players or only for displays
+ALTER TABLE outbox ADD COLUMN audience VARCHAR(16);
+
+COMMENT ON COLUMN outbox.audience IS 'Kind of client a message is for, players or displays; NULL for every client';
@@ -0,0 +1,5 @@
+-- Soft-deleted questions cannot be told apart once the column is gone
+DELETE FROM questions WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_questions_selection;
+CREATE INDEX idx_questions_selection ON questions(category, language, rating);
+ALTER TABLE questions DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,8 @@
+-- Soft delete questions so curated content removed by mistake can be restored
+ALTER TABLE questions
+ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+COMMENT ON COLUMN questions.deleted_at IS 'When the question was deleted; deleted questions are never drawn or listed';
+-- Only live questions are drawn, so keep deleted ones out of the selection index
+DROP INDEX IF EXISTS idx_questions_selection;
+CREATE INDEX idx_questions_selection ON questions(category, language, rating)
+WHERE deleted_at IS NULL;
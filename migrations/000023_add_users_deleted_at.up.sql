@@ -0,0 +1,6 @@
+-- Soft delete users, anonymizing them until they are purged after a
+-- retention window. Their game history keeps its rows under "Deleted player".
+ALTER TABLE users
+ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+COMMENT ON COLUMN users.deleted_at IS 'When the user deleted their account; deleted users are anonymized and purged later';
+CREATE INDEX idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
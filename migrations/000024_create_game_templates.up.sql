@@ -0,0 +1,12 @@
+-- Shareable game configurations, owned by a user or global when owner_id is NULL
+CREATE TABLE game_templates (
+    id UUID PRIMARY KEY,
+    owner_id VARCHAR(36),
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(500) NOT NULL DEFAULT '',
+    settings JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_game_templates_owner_id ON game_templates(owner_id);
@@ -0,0 +1,3 @@
+-- Client settings users keep on their account, such as sound and theme.
+-- Keys missing from a user's preferences take their default.
+ALTER TABLE users ADD COLUMN preferences JSONB NOT NULL DEFAULT '{}';
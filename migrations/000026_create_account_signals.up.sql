@@ -0,0 +1,15 @@
+-- Where accounts registered and logged in from, with the abuse heuristics
+-- each sign-in tripped, for moderation
+CREATE TABLE account_signals (
+    id UUID PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    country VARCHAR(2) NOT NULL DEFAULT '',
+    flags TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_account_signals_user_id ON account_signals(user_id, created_at DESC);
+CREATE INDEX idx_account_signals_ip ON account_signals(ip, created_at);
+CREATE INDEX idx_account_signals_created_at ON account_signals(created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS moderation_log;
+DROP TABLE IF EXISTS user_bans;
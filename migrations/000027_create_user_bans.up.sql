@@ -0,0 +1,30 @@
+-- Suspensions and permanent bans keeping users from logging in and playing
+CREATE TABLE user_bans (
+    id UUID PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    reason VARCHAR(500) NOT NULL,
+    appeal_note TEXT NOT NULL DEFAULT '',
+    issued_by VARCHAR(100) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    lifted_at TIMESTAMP WITH TIME ZONE,
+    lifted_by VARCHAR(100) NOT NULL DEFAULT ''
+);
+COMMENT ON COLUMN user_bans.expires_at IS 'When a suspension ends; NULL for permanent bans';
+
+CREATE INDEX idx_user_bans_user_id ON user_bans(user_id, created_at DESC);
+
+-- Audit log of moderators' actions on users
+CREATE TABLE moderation_log (
+    id UUID PRIMARY KEY,
+    action VARCHAR(20) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    ban_id UUID,
+    actor VARCHAR(100) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_moderation_log_created_at ON moderation_log(created_at DESC);
+CREATE INDEX idx_moderation_log_user_id ON moderation_log(user_id, created_at DESC);
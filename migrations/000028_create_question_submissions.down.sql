@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS contributor_trust_thresholds;
+DROP TABLE IF EXISTS question_submissions;
@@ -0,0 +1,28 @@
+-- Questions contributed by users, waiting in the moderation queue or reviewed
+CREATE TABLE question_submissions (
+    id UUID PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    question JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    reviewed_by VARCHAR(100) NOT NULL DEFAULT '',
+    review_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE
+);
+COMMENT ON COLUMN question_submissions.question IS 'The submitted question, with the ID of the question created once approved';
+
+CREATE INDEX idx_question_submissions_status ON question_submissions(status, created_at);
+CREATE INDEX idx_question_submissions_user_id ON question_submissions(user_id, created_at DESC);
+
+-- What contributors need for their submissions to skip the moderation queue,
+-- configured by admins. The table holds a single row.
+CREATE TABLE contributor_trust_thresholds (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    min_approved INTEGER NOT NULL,
+    max_rejected_percent INTEGER NOT NULL,
+    min_account_days INTEGER NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO contributor_trust_thresholds (min_approved, max_rejected_percent, min_account_days)
+VALUES (10, 20, 14);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pack_releases;
+ALTER TABLE questions DROP COLUMN IF EXISTS pack_version;
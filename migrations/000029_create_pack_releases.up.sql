@@ -0,0 +1,21 @@
+-- Questions wait unreleased, at pack version 0, until admins publish the
+-- next version of their language's pack. The questions already asked in
+-- games make up each pack's first release.
+ALTER TABLE questions ADD COLUMN pack_version INTEGER NOT NULL DEFAULT 0;
+UPDATE questions SET pack_version = 1;
+
+CREATE TABLE pack_releases (
+    language VARCHAR(10) NOT NULL,
+    version INTEGER NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    questions INTEGER NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (language, version)
+);
+
+INSERT INTO pack_releases (language, version, notes, questions, published_at)
+SELECT language, 1, 'Initial release', COUNT(*), CURRENT_TIMESTAMP
+FROM questions
+GROUP BY language;
+
+CREATE INDEX idx_pack_releases_published_at ON pack_releases(published_at DESC);
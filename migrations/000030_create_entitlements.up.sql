@@ -0,0 +1,19 @@
+-- Premium packs: sets of categories only entitled players can play
+CREATE TABLE premium_packs (
+    id VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    categories TEXT[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Users' entitlements to premium packs
+CREATE TABLE entitlements (
+    user_id VARCHAR(36) NOT NULL,
+    pack_id VARCHAR(50) NOT NULL REFERENCES premium_packs(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL,
+    granted_by VARCHAR(100) NOT NULL DEFAULT '',
+    granted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, pack_id)
+);
+COMMENT ON COLUMN entitlements.source IS 'How the user came to own the pack, such as an admin grant';
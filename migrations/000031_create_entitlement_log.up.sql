@@ -0,0 +1,14 @@
+-- Audit log of changes to users' entitlements
+CREATE TABLE entitlement_log (
+    id UUID PRIMARY KEY,
+    action VARCHAR(20) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    from_user_id VARCHAR(36) NOT NULL DEFAULT '',
+    pack_id VARCHAR(50) NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+COMMENT ON COLUMN entitlement_log.from_user_id IS 'User who gave up a gifted pack; empty for other actions';
+
+CREATE INDEX idx_entitlement_log_user_id ON entitlement_log(user_id, created_at DESC);
+CREATE INDEX idx_entitlement_log_from_user_id ON entitlement_log(from_user_id, created_at DESC) WHERE from_user_id <> '';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS cosmetic_unlocks;
+DROP TABLE IF EXISTS promo_redemptions;
+DROP TABLE IF EXISTS promo_codes;
+DROP TABLE IF EXISTS promo_batches;
@@ -0,0 +1,42 @@
+-- Promotions' batches of redeemable codes
+CREATE TABLE promo_batches (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    pack_id VARCHAR(50) NOT NULL DEFAULT '',
+    cosmetic VARCHAR(50) NOT NULL DEFAULT '',
+    codes INTEGER NOT NULL,
+    max_redemptions INTEGER NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+COMMENT ON COLUMN promo_batches.max_redemptions IS 'Times each of the batch''s codes may be redeemed';
+
+CREATE TABLE promo_codes (
+    code VARCHAR(32) PRIMARY KEY,
+    batch_id UUID NOT NULL REFERENCES promo_batches(id) ON DELETE CASCADE,
+    redemptions INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_promo_codes_batch_id ON promo_codes(batch_id);
+
+-- Users redeem one code of each batch
+CREATE TABLE promo_redemptions (
+    batch_id UUID NOT NULL REFERENCES promo_batches(id) ON DELETE CASCADE,
+    code VARCHAR(32) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    pack_id VARCHAR(50) NOT NULL DEFAULT '',
+    cosmetic VARCHAR(50) NOT NULL DEFAULT '',
+    redeemed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (batch_id, user_id)
+);
+
+CREATE INDEX idx_promo_redemptions_user_id ON promo_redemptions(user_id, redeemed_at DESC);
+
+-- Cosmetics users have unlocked
+CREATE TABLE cosmetic_unlocks (
+    user_id VARCHAR(36) NOT NULL,
+    cosmetic VARCHAR(50) NOT NULL,
+    unlocked_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, cosmetic)
+);
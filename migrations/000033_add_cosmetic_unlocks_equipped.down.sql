@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_cosmetic_unlocks_equipped;
+ALTER TABLE cosmetic_unlocks DROP COLUMN IF EXISTS equipped;
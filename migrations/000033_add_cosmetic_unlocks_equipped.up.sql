@@ -0,0 +1,4 @@
+ALTER TABLE cosmetic_unlocks ADD COLUMN equipped BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN cosmetic_unlocks.equipped IS 'Whether the user wears the cosmetic; at most one of each kind';
+
+CREATE INDEX idx_cosmetic_unlocks_equipped ON cosmetic_unlocks(user_id) WHERE equipped;
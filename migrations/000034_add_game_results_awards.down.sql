@@ -0,0 +1 @@
+ALTER TABLE game_results DROP COLUMN IF EXISTS awards;
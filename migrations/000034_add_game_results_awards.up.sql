@@ -0,0 +1,4 @@
+-- End-of-game superlatives such as Best Liar, with the players who earned them
+ALTER TABLE game_results ADD COLUMN awards JSONB NOT NULL DEFAULT '[]';
+
+COMMENT ON COLUMN game_results.awards IS 'Awards given at the end of the game and the players who earned them';
@@ -0,0 +1 @@
+ALTER TABLE game_players DROP COLUMN IF EXISTS likes_received;
@@ -0,0 +1,4 @@
+-- Likes other players gave to the player's answers during reveals
+ALTER TABLE game_players ADD COLUMN likes_received INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN game_players.likes_received IS 'Likes other players gave to the player''s answers';
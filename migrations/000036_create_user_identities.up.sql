@@ -0,0 +1,11 @@
+-- Outside accounts, such as Google or Apple accounts, users sign in with
+CREATE TABLE user_identities (
+    provider VARCHAR(20) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, subject)
+);
+COMMENT ON COLUMN user_identities.subject IS 'The provider''s stable ID for the account';
+
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
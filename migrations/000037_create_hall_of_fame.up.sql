@@ -0,0 +1,18 @@
+-- Fake answers players liked enough to keep for the community to browse
+CREATE TABLE hall_of_fame (
+    id UUID PRIMARY KEY,
+    game_id UUID NOT NULL,
+    category VARCHAR(50) NOT NULL,
+    question TEXT NOT NULL,
+    correct_answer TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    author_id VARCHAR(36) NOT NULL,
+    author_name VARCHAR(50) NOT NULL,
+    likes INTEGER NOT NULL,
+    author_consent BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+COMMENT ON COLUMN hall_of_fame.author_consent IS 'Whether the author lets the answer be shown, following their hall_of_fame preference';
+
+CREATE INDEX idx_hall_of_fame_created_at ON hall_of_fame(created_at DESC) WHERE author_consent;
+CREATE INDEX idx_hall_of_fame_author_id ON hall_of_fame(author_id);
@@ -0,0 +1,7 @@
+-- Users verify their email address with a link sent to it before they can
+-- send game invitations. Accounts created before verification existed are
+-- trusted as they are.
+ALTER TABLE users
+ADD COLUMN verified BOOLEAN NOT NULL DEFAULT FALSE;
+COMMENT ON COLUMN users.verified IS 'Whether the user has verified their email address';
+UPDATE users SET verified = TRUE;
@@ -0,0 +1,4 @@
+-- Standout moments of a game such as its best bluff, compiled when it ends
+ALTER TABLE game_results ADD COLUMN highlights JSONB;
+
+COMMENT ON COLUMN game_results.highlights IS 'Biggest swing round, best bluff and closest vote of the game; NULL for games ended before highlights were compiled';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_game_results_share_token;
+ALTER TABLE game_results DROP COLUMN IF EXISTS share_token;
@@ -0,0 +1,5 @@
+-- Share links let anyone view a game's public results, without IDs or emails
+ALTER TABLE game_results ADD COLUMN share_token VARCHAR(64);
+
+COMMENT ON COLUMN game_results.share_token IS 'Token of the link the public results of the game are shared with; NULL for games ended before results could be shared';
+CREATE UNIQUE INDEX idx_game_results_share_token ON game_results(share_token) WHERE share_token IS NOT NULL;
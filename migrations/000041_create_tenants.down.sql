@@ -0,0 +1,5 @@
+ALTER TABLE premium_packs DROP COLUMN IF EXISTS tenant_id;
+DROP INDEX IF EXISTS idx_users_tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_role;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,18 @@
+-- Organizations whose members play among themselves
+CREATE TABLE tenants (
+    id VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Users belong to at most one organization
+ALTER TABLE users ADD COLUMN tenant_id VARCHAR(50) REFERENCES tenants(id);
+ALTER TABLE users ADD COLUMN tenant_role VARCHAR(20) NOT NULL DEFAULT '';
+COMMENT ON COLUMN users.tenant_id IS 'Organization the user belongs to; NULL for users outside any';
+COMMENT ON COLUMN users.tenant_role IS 'The user''s role in their organization, member or admin';
+CREATE INDEX idx_users_tenant_id ON users(tenant_id) WHERE tenant_id IS NOT NULL;
+
+-- Premium packs private to an organization are owned by its members
+ALTER TABLE premium_packs ADD COLUMN tenant_id VARCHAR(50) REFERENCES tenants(id);
+COMMENT ON COLUMN premium_packs.tenant_id IS 'Organization the pack is private to; NULL for packs anyone can own';
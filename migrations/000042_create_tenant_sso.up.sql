@@ -0,0 +1,15 @@
+-- Organizations' OpenID Connect identity providers, which their members sign
+-- in with
+CREATE TABLE tenant_sso (
+    tenant_id VARCHAR(50) PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    issuer VARCHAR(255) NOT NULL,
+    client_id VARCHAR(255) NOT NULL,
+    client_secret TEXT NOT NULL,
+    role_claim VARCHAR(100) NOT NULL DEFAULT '',
+    role_mapping JSONB NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+COMMENT ON COLUMN tenant_sso.role_claim IS 'ID token claim members'' roles follow; empty when roles are managed through the API';
+COMMENT ON COLUMN tenant_sso.role_mapping IS 'Organization roles granted by values of the role claim';
@@ -12,7 +12,8 @@ class QuestionGenerator:
     def __init__(self, api_url: str, headers: Optional[Dict[str, str]] = None):
         self.api_url = api_url
         self.headers = headers or {
-            "Content-Type": "application/json"
+            "Content-Type": "application/json",
+            "Authorization": f"Bearer {os.getenv('ADMIN_TOKEN', '')}"
         }
 
     def generate_questions(
@@ -140,7 +141,7 @@ ONLY return the JSON array, no other text or markdown formatting."""
 
 def main():
     # Configuration
-    API_URL = "http://localhost:8080/api/games/questions/bulk"  # Updated endpoint
+    API_URL = "http://localhost:8080/api/admin/questions/bulk"  # Admin only
     CATEGORIES = ["Science", "History", "Geography", "Sports", "Entertainment"]
     QUESTIONS_PER_CATEGORY = 5
     